@@ -1,5 +1,6 @@
 import React from 'react'
 
+import Button from '@material-ui/core/Button'
 import Table from '@material-ui/core/Table'
 import TableBody from '@material-ui/core/TableBody'
 import TableCell from '@material-ui/core/TableCell'
@@ -8,7 +9,25 @@ import TableRow from '@material-ui/core/TableRow'
 
 import Title from 'components/common/Title'
 
-const InstanceList = ({ instances }) => (
+// the bound apps are only loaded when asked for, each with how many of its units are bound
+const BoundApps = ({ instance, bindings, onShowBindings }) => {
+  const instanceBindings = bindings[instance.name]
+  if (!instanceBindings) {
+    return (
+      <Button size="small" onClick={() => onShowBindings(instance)}>
+        Show
+      </Button>
+    )
+  }
+  if (!instanceBindings.length) {
+    return 'none'
+  }
+  return instanceBindings
+    .map(binding => `${binding.appName} (${binding.unitHosts.length} units)`)
+    .join(', ')
+}
+
+const InstanceList = ({ instances, bindings, hasMore, onLoadMore, onShowBindings, onRotateCredentials }) => (
   <React.Fragment>
     <Title>
       Instances <small>({instances.length})</small>
@@ -21,6 +40,8 @@ const InstanceList = ({ instances }) => (
           <TableCell>Team</TableCell>
           <TableCell>User</TableCell>
           <TableCell>Status</TableCell>
+          <TableCell>Bound apps</TableCell>
+          <TableCell />
         </TableRow>
       </TableHead>
       <TableBody>
@@ -30,11 +51,28 @@ const InstanceList = ({ instances }) => (
             <TableCell>{instance.plan}</TableCell>
             <TableCell>{instance.team}</TableCell>
             <TableCell>{instance.user}</TableCell>
-            <TableCell>{instance.status}</TableCell>
+            <TableCell title={instance.failureReason}>{instance.status}</TableCell>
+            <TableCell>
+              <BoundApps instance={instance} bindings={bindings} onShowBindings={onShowBindings} />
+            </TableCell>
+            <TableCell>
+              <Button
+                size="small"
+                disabled={instance.status !== 'running' && instance.status !== 'degraded'}
+                onClick={() => onRotateCredentials(instance)}
+              >
+                Rotate credentials
+              </Button>
+            </TableCell>
           </TableRow>
         ))}
       </TableBody>
     </Table>
+    {hasMore && (
+      <Button size="small" onClick={onLoadMore}>
+        Load more
+      </Button>
+    )}
   </React.Fragment>
 )
 
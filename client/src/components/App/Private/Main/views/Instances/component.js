@@ -18,6 +18,8 @@ const Instances = (props) => {
   const classes = useStyles()
   const [didLoad, setDidLoad] = useState(false)
   const [instances, setInstances] = useState([])
+  const [nextCursor, setNextCursor] = useState(undefined)
+  const [bindings, setBindings] = useState({})
   const setTitle = useContext(SetTitleContext)
 
   const findSelectedInstanceById = () => {
@@ -31,13 +33,35 @@ const Instances = (props) => {
     setTitle('Persistent Instances')
   }, [setTitle])
 
+  const loadInstances = () => instancesService.getInstances()
+    .then((data) => {
+      setInstances(data.instances)
+      setNextCursor(data.nextCursor)
+      setDidLoad(true)
+    })
+
+  const loadMoreInstances = () => instancesService.getInstances(nextCursor)
+    .then((data) => {
+      setInstances(current => current.concat(data.instances))
+      setNextCursor(data.nextCursor)
+    })
+
   useEffect(() => {
-    instancesService.getInstances()
+    loadInstances()
+  }, [])
+
+  // the instance goes pending while its credentials are rotated
+  const handleRotateCredentials = (instance) => {
+    instancesService.rotateCredentials(instance.name)
+      .then(loadInstances)
+  }
+
+  const handleShowBindings = (instance) => {
+    instancesService.getBindings(instance.name)
       .then((data) => {
-        setInstances(data)
-        setDidLoad(true)
+        setBindings(current => ({ ...current, [instance.name]: data }))
       })
-  }, [])
+  }
 
   const instancesMinHeightPaper = clsx(classes.paper, classes.instancesMinHeightPaper)
 
@@ -51,7 +75,14 @@ const Instances = (props) => {
     <Grid container>
       <Grid item xs={12}>
         <Paper className={instancesMinHeightPaper}>
-          <InstanceList instances={instances} />
+          <InstanceList
+            instances={instances}
+            hasMore={!!nextCursor}
+            onLoadMore={loadMoreInstances}
+            bindings={bindings}
+            onShowBindings={handleShowBindings}
+            onRotateCredentials={handleRotateCredentials}
+          />
         </Paper>
       </Grid>
     </Grid>
@@ -32,7 +32,7 @@ const Login = () => {
     credentialsService.setCredentials(credentials)
 
     authService.checkAuth(credentials)
-      .then(() => setUser({ username }))
+      .then((principal) => setUser(principal))
   }
 
   return (
@@ -49,8 +49,8 @@ const Login = () => {
             margin="normal"
             required
             fullWidth
-            label="Username"
-            autoComplete="email"
+            label="Token name"
+            autoComplete="username"
             autoFocus
           />
           <TextField
@@ -60,7 +60,7 @@ const Login = () => {
             margin="normal"
             required
             fullWidth
-            label="Password"
+            label="Token secret"
             type="password"
             autoComplete="current-password"
           />
@@ -76,7 +76,7 @@ const Login = () => {
           <Grid container>
             <Grid item xs>
               <Typography variant="body2" color="textSecondary" align="center">
-                The credentials are the same Tsuru uses to call the PushaaS API
+                Use an API token created for the admin UI, its secret is shown only when it is created
               </Typography>
             </Grid>
           </Grid>
@@ -31,9 +31,8 @@ const App = () => {
       return
     }
 
-    const { username } = credentials
     authService.checkAuth(credentials)
-      .then(() => setUser({ username }))
+      .then((principal) => setUser(principal))
       .finally(() => setStatus(STATUS_LOADED))
   }, [setStatus, setUser])
 
@@ -1,7 +1,13 @@
 import baseClient from 'clients/baseClient'
 
-const getInstances = () => baseClient.get('/resources/instances')
+const getInstances = (cursor) => baseClient.get('/resources/instances', { params: { cursor } })
+
+const getBindings = (name) => baseClient.get(`/resources/${name}/bindings`)
+
+const rotateCredentials = (name) => baseClient.post(`/resources/${name}/credentials/rotate`)
 
 export default {
   getInstances,
+  getBindings,
+  rotateCredentials,
 }
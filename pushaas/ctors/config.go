@@ -82,10 +82,30 @@ func setupFromDefaults(config *viper.Viper, env string) {
 
 	// api
 	config.SetDefault("api.enable_auth", true)
+	// the credential of tsuru, everyone else (e.g. the admin UI, automation) gets an api token
 	config.SetDefault("api.basic_auth_user", "tsuru")
 	config.SetDefault("api.basic_auth_password", "abc123")
 	config.SetDefault("api.statics_path", "./client/build")
 
+	/*
+		plans, each instance runs a single push-stream, as the subscribers are given its address, so the plans only
+		size it (and push-api, with half of it)
+	*/
+	config.SetDefault("plans", []map[string]interface{}{
+		{
+			"name":        "small",
+			"description": "For apps with few subscribers",
+			"cpu":         512,
+			"memory":      1024,
+		},
+		{
+			"name":        "large",
+			"description": "For high-fanout apps, with a bigger push-stream",
+			"cpu":         1024,
+			"memory":      2048,
+		},
+	})
+
 	// provisioner
 	config.SetDefault("provisioner.provider", "ecs")
 
@@ -99,15 +119,70 @@ func setupFromDefaults(config *viper.Viper, env string) {
 	config.SetDefault("provisioner.ecs.image_push_agent", "pushaas/push-agent:latest")   // TODO pass actual tag
 	config.SetDefault("provisioner.ecs.image_push_stream", "pushaas/push-stream:latest") // TODO pass actual tag
 
+	// provisioner - docker
+	config.SetDefault("provisioner.docker.url", "unix:///var/run/docker.sock")
+	config.SetDefault("provisioner.docker.api_version", "1.40")
+	config.SetDefault("provisioner.docker.network", "pushaas_default")
+	config.SetDefault("provisioner.docker.public_host", "localhost")
+
+	config.SetDefault("provisioner.docker.image_push_api", "pushaas/push-api:latest")
+	config.SetDefault("provisioner.docker.image_push_agent", "pushaas/push-agent:latest")
+	config.SetDefault("provisioner.docker.image_push_redis", "redis:5.0.5-alpine")
+	config.SetDefault("provisioner.docker.image_push_stream", "pushaas/push-stream:latest")
+
+	// provisioner - kubernetes
+	config.SetDefault("provisioner.kubernetes.kubeconfig", "")
+	config.SetDefault("provisioner.kubernetes.namespace", "pushaas")
+	config.SetDefault("provisioner.kubernetes.push_stream_service_type", "LoadBalancer")
+
+	config.SetDefault("provisioner.kubernetes.image_push_api", "pushaas/push-api:latest")
+	config.SetDefault("provisioner.kubernetes.image_push_agent", "pushaas/push-agent:latest")
+	config.SetDefault("provisioner.kubernetes.image_push_redis", "redis:5.0.5-alpine")
+	config.SetDefault("provisioner.kubernetes.image_push_stream", "pushaas/push-stream:latest")
+
+	// push-api of the instances
+	config.SetDefault("push_api.timeout", "10s")
+	config.SetDefault("push_api.credentials_path", "/admin/credentials")
+
+	// tsuru api, to send new env vars to the bound apps (left empty, the apps have to get them by hand)
+	config.SetDefault("tsuru.api_url", "")
+	config.SetDefault("tsuru.token", "")
+	config.SetDefault("tsuru.timeout", "10s")
+
+	// storage of instances and binds: "redis", "sql" (driver "postgres" or "sqlite3") or "memory"
+	config.SetDefault("storage.backend", "redis")
+	config.SetDefault("storage.sql.driver", "postgres")
+	config.SetDefault("storage.sql.dsn", "")
+
 	// redis
 	config.SetDefault("redis.url", "redis://localhost:6379")
+	config.SetDefault("redis.db.instance.index", "instance-index")
 	config.SetDefault("redis.db.instance.prefix", "instance")
 	config.SetDefault("redis.db.instance.vars_prefix", "instance-vars")
+	config.SetDefault("redis.db.instance.history_prefix", "instance-history")
 	config.SetDefault("redis.db.bind_app.prefix", "bind-app")
+	config.SetDefault("redis.db.bind_app.index_prefix", "bind-app-index")
+	config.SetDefault("redis.db.bind_app.app_index_prefix", "app-bind-index")
 	config.SetDefault("redis.db.bind_unit.prefix", "bind-unit")
+	config.SetDefault("redis.db.outbox.key", "outbox")
+	config.SetDefault("redis.db.outbox.index", "outbox-index")
+	config.SetDefault("redis.db.provision_step.prefix", "provision-step")
+	config.SetDefault("redis.db.instance_lock.prefix", "instance-lock")
+	config.SetDefault("redis.db.instance_lock.ttl", "1m")
+	config.SetDefault("redis.db.operation.prefix", "operation")
+	config.SetDefault("redis.db.operation.ttl", "168h")
+	config.SetDefault("redis.db.api_token.key", "api-tokens")
+	config.SetDefault("redis.db.audit.stream", "audit")
+	config.SetDefault("redis.db.dead_letter.key", "dead-letters")
+	config.SetDefault("redis.db.dead_letter.max_length", 1000)
 	config.SetDefault("redis.pubsub.tasks.provision", "provision")
 	config.SetDefault("redis.pubsub.tasks.deprovision", "deprovision")
+	config.SetDefault("redis.pubsub.tasks.update", "update")
+	config.SetDefault("redis.pubsub.tasks.rotate_credentials", "rotate-credentials")
+	config.SetDefault("redis.pubsub.tasks.recreate", "recreate")
 	config.SetDefault("redis.pubsub.tasks.update_instance", "update-instance")
+	config.SetDefault("redis.pubsub.tasks.delete_instance", "delete-instance")
+	config.SetDefault("redis.pubsub.events.prefix", "instance-events")
 
 	// server
 	config.SetDefault("server.port", "9000")
@@ -115,6 +190,40 @@ func setupFromDefaults(config *viper.Viper, env string) {
 	// workers
 	config.SetDefault("workers.enabled", true)
 	config.SetDefault("workers.machinery.enabled", true)
+	config.SetDefault("workers.reconcile.enabled", true)
+	config.SetDefault("workers.reconcile.interval", "1m")
+	config.SetDefault("workers.reconcile.pending_timeout", "30m")
+	config.SetDefault("workers.gc.enabled", true)
+	config.SetDefault("workers.gc.interval", "1h")
+	config.SetDefault("workers.gc.dry_run", true)
+	config.SetDefault("workers.gc.grace_period", "15m")
+	config.SetDefault("workers.outbox.enabled", true)
+	config.SetDefault("workers.outbox.interval", "1s")
+	config.SetDefault("workers.outbox.claim_timeout", "1m")
+	config.SetDefault("workers.outbox.batch_size", 100)
+	config.SetDefault("workers.lock.busy_backoff", "15s")
+	config.SetDefault("workers.retry.provision.max_retries", 3)
+	config.SetDefault("workers.retry.provision.initial_backoff", "30s")
+	config.SetDefault("workers.retry.provision.max_backoff", "5m")
+	config.SetDefault("workers.retry.deprovision.max_retries", 5)
+	config.SetDefault("workers.retry.deprovision.initial_backoff", "30s")
+	config.SetDefault("workers.retry.deprovision.max_backoff", "10m")
+	config.SetDefault("workers.retry.update.max_retries", 3)
+	config.SetDefault("workers.retry.update.initial_backoff", "30s")
+	config.SetDefault("workers.retry.update.max_backoff", "5m")
+	// a rotation tried again would rotate the password of push-api once more
+	config.SetDefault("workers.retry.rotate_credentials.max_retries", 0)
+	config.SetDefault("workers.retry.rotate_credentials.initial_backoff", "30s")
+	config.SetDefault("workers.retry.rotate_credentials.max_backoff", "5m")
+	config.SetDefault("workers.retry.recreate.max_retries", 3)
+	config.SetDefault("workers.retry.recreate.initial_backoff", "30s")
+	config.SetDefault("workers.retry.recreate.max_backoff", "5m")
+	config.SetDefault("workers.retry.update_instance.max_retries", 5)
+	config.SetDefault("workers.retry.update_instance.initial_backoff", "5s")
+	config.SetDefault("workers.retry.update_instance.max_backoff", "2m")
+	config.SetDefault("workers.retry.delete_instance.max_retries", 5)
+	config.SetDefault("workers.retry.delete_instance.initial_backoff", "5s")
+	config.SetDefault("workers.retry.delete_instance.max_backoff", "2m")
 }
 
 func setupFromEnvironment(config *viper.Viper) {
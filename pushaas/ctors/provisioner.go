@@ -8,26 +8,43 @@ import (
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/go-redis/redis"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/provisioners/docker_provisioner"
 	"github.com/pushaas/pushaas/pushaas/provisioners/ecs_provisioner"
+	"github.com/pushaas/pushaas/pushaas/provisioners/kubernetes_provisioner"
 )
 
-func NewPushServiceProvisioner(
-	config *viper.Viper,
-	logger *zap.Logger,
-	provisionerConfig *ecs_provisioner.EcsProvisionerConfig,
-	pushRedisProvisioner ecs_provisioner.EcsPushRedisProvisioner,
-	pushStreamProvisioner ecs_provisioner.EcsPushStreamProvisioner,
-	pushApiProvisioner ecs_provisioner.EcsPushApiProvisioner,
-) (provisioners.PushServiceProvisioner, error) {
+func NewProvisionStepStore(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) provisioners.ProvisionStepStore {
+	return provisioners.NewProvisionStepStore(config, logger, redisClient)
+}
+
+/*
+	the dependencies of each provider are only built when the provider is selected, as they require
+	provider specific configuration (and credentials) to be built
+*/
+func NewPushServiceProvisioner(config *viper.Viper, logger *zap.Logger, stepStore provisioners.ProvisionStepStore) (provisioners.PushServiceProvisioner, error) {
 	provider := config.GetString("provisioner.provider")
 
 	if provider == "ecs" {
 		logger.Info("initializing provisioner with provider", zap.String("provider", provider))
-		return ecs_provisioner.NewEcsPushServiceProvisioner(logger, provisionerConfig, pushRedisProvisioner, pushStreamProvisioner, pushApiProvisioner)
+		return newEcsPushServiceProvisioner(config, logger, stepStore)
+	}
+
+	if provider == "docker" {
+		logger.Info("initializing provisioner with provider", zap.String("provider", provider))
+		return newDockerPushServiceProvisioner(config, logger)
+	}
+
+	if provider == "kubernetes" {
+		logger.Info("initializing provisioner with provider", zap.String("provider", provider))
+		return newKubernetesPushServiceProvisioner(config, logger)
 	}
 
 	return nil, fmt.Errorf("unknown provider: %s", provider)
@@ -36,6 +53,22 @@ func NewPushServiceProvisioner(
 /*
 	aws ecs
 */
+func newEcsPushServiceProvisioner(config *viper.Viper, logger *zap.Logger, stepStore provisioners.ProvisionStepStore) (provisioners.PushServiceProvisioner, error) {
+	provisionerConfig, err := NewEcsProvisionerConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecs_provisioner.NewEcsPushServiceProvisioner(
+		logger,
+		provisionerConfig,
+		NewEcsPushRedisProvisioner(logger, provisionerConfig),
+		NewEcsPushStreamProvisioner(logger, provisionerConfig),
+		NewEcsPushApiProvisioner(logger, provisionerConfig),
+		stepStore,
+	)
+}
+
 func NewEcsProvisionerConfig(config *viper.Viper) (*ecs_provisioner.EcsProvisionerConfig, error) {
 	awsSession := session.Must(session.NewSession())
 	iamSvc := iam.New(awsSession)
@@ -56,3 +89,93 @@ func NewEcsPushStreamProvisioner(logger *zap.Logger, ecsConfig *ecs_provisioner.
 func NewEcsPushApiProvisioner(logger *zap.Logger, ecsConfig *ecs_provisioner.EcsProvisionerConfig) ecs_provisioner.EcsPushApiProvisioner {
 	return ecs_provisioner.NewEcsPushApiProvisioner(logger, ecsConfig)
 }
+
+/*
+	docker
+*/
+func newDockerPushServiceProvisioner(config *viper.Viper, logger *zap.Logger) (provisioners.PushServiceProvisioner, error) {
+	provisionerConfig, err := NewDockerProvisionerConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return docker_provisioner.NewDockerPushServiceProvisioner(
+		logger,
+		provisionerConfig,
+		NewDockerPushRedisProvisioner(logger, provisionerConfig),
+		NewDockerPushStreamProvisioner(logger, provisionerConfig),
+		NewDockerPushApiProvisioner(logger, provisionerConfig),
+	)
+}
+
+func NewDockerProvisionerConfig(config *viper.Viper) (*docker_provisioner.DockerProvisionerConfig, error) {
+	dockerSvc, err := docker_provisioner.NewDockerClient(config.GetString("provisioner.docker.url"), config.GetString("provisioner.docker.api_version"))
+	if err != nil {
+		return nil, err
+	}
+	return docker_provisioner.NewDockerProvisionerConfig(config, dockerSvc)
+}
+
+func NewDockerPushRedisProvisioner(logger *zap.Logger, dockerConfig *docker_provisioner.DockerProvisionerConfig) docker_provisioner.DockerPushRedisProvisioner {
+	return docker_provisioner.NewDockerPushRedisProvisioner(logger, dockerConfig)
+}
+
+func NewDockerPushStreamProvisioner(logger *zap.Logger, dockerConfig *docker_provisioner.DockerProvisionerConfig) docker_provisioner.DockerPushStreamProvisioner {
+	return docker_provisioner.NewDockerPushStreamProvisioner(logger, dockerConfig)
+}
+
+func NewDockerPushApiProvisioner(logger *zap.Logger, dockerConfig *docker_provisioner.DockerProvisionerConfig) docker_provisioner.DockerPushApiProvisioner {
+	return docker_provisioner.NewDockerPushApiProvisioner(logger, dockerConfig)
+}
+
+/*
+	kubernetes
+*/
+func newKubernetesPushServiceProvisioner(config *viper.Viper, logger *zap.Logger) (provisioners.PushServiceProvisioner, error) {
+	provisionerConfig, err := NewKubernetesProvisionerConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes_provisioner.NewKubernetesPushServiceProvisioner(
+		logger,
+		provisionerConfig,
+		NewKubernetesPushRedisProvisioner(logger, provisionerConfig),
+		NewKubernetesPushStreamProvisioner(logger, provisionerConfig),
+		NewKubernetesPushApiProvisioner(logger, provisionerConfig),
+	)
+}
+
+func NewKubernetesProvisionerConfig(config *viper.Viper) (*kubernetes_provisioner.KubernetesProvisionerConfig, error) {
+	var restConfig *rest.Config
+	var err error
+
+	// without a kubeconfig pushaas is expected to be running inside the cluster
+	kubeconfig := config.GetString("provisioner.kubernetes.kubeconfig")
+	if kubeconfig != "" {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	} else {
+		restConfig, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	kubernetesSvc, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes_provisioner.NewKubernetesProvisionerConfig(config, kubernetesSvc)
+}
+
+func NewKubernetesPushRedisProvisioner(logger *zap.Logger, kubernetesConfig *kubernetes_provisioner.KubernetesProvisionerConfig) kubernetes_provisioner.KubernetesPushRedisProvisioner {
+	return kubernetes_provisioner.NewKubernetesPushRedisProvisioner(logger, kubernetesConfig)
+}
+
+func NewKubernetesPushStreamProvisioner(logger *zap.Logger, kubernetesConfig *kubernetes_provisioner.KubernetesProvisionerConfig) kubernetes_provisioner.KubernetesPushStreamProvisioner {
+	return kubernetes_provisioner.NewKubernetesPushStreamProvisioner(logger, kubernetesConfig)
+}
+
+func NewKubernetesPushApiProvisioner(logger *zap.Logger, kubernetesConfig *kubernetes_provisioner.KubernetesProvisionerConfig) kubernetes_provisioner.KubernetesPushApiProvisioner {
+	return kubernetes_provisioner.NewKubernetesPushApiProvisioner(logger, kubernetesConfig)
+}
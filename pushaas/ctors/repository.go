@@ -0,0 +1,58 @@
+package ctors
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/go-redis/redis"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/repositories"
+)
+
+func NewRepository(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) (repositories.Repository, error) {
+	backend := config.GetString("storage.backend")
+
+	if backend == "redis" {
+		logger.Info("initializing repository with backend", zap.String("backend", backend))
+		return repositories.NewRedisRepository(config, logger, redisClient)
+	}
+
+	if backend == "sql" {
+		logger.Info("initializing repository with backend", zap.String("backend", backend))
+		return newSqlRepository(config, logger)
+	}
+
+	if backend == "memory" {
+		logger.Info("initializing repository with backend", zap.String("backend", backend))
+		return repositories.NewMemoryRepository(), nil
+	}
+
+	return nil, fmt.Errorf("unknown storage backend: %s", backend)
+}
+
+func newSqlRepository(config *viper.Viper, logger *zap.Logger) (repositories.Repository, error) {
+	driver := config.GetString("storage.sql.driver")
+	db, err := sql.Open(driver, config.GetString("storage.sql.dsn"))
+	if err != nil {
+		logger.Error("failed to open sql database", zap.String("driver", driver), zap.Error(err))
+		return nil, err
+	}
+
+	return repositories.NewSqlRepository(logger, db, driver)
+}
+
+func NewInstanceRepository(repository repositories.Repository) repositories.InstanceRepository {
+	return repository
+}
+
+func NewBindRepository(repository repositories.Repository) repositories.BindRepository {
+	return repository
+}
+
+func NewTaskOutboxRepository(repository repositories.Repository) repositories.TaskOutboxRepository {
+	return repository
+}
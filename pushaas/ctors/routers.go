@@ -2,6 +2,7 @@ package ctors
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/pushaas/pushaas/pushaas/models"
 	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
 	"github.com/pushaas/pushaas/pushaas/services"
 	"github.com/spf13/viper"
@@ -14,40 +15,37 @@ func g(router gin.IRouter, path string, groupFn func(r gin.IRouter)) {
 	groupFn(router.Group(path))
 }
 
-func getNoAuthMiddleware(config *viper.Viper, logger *zap.Logger) gin.HandlerFunc {
-	logger.Debug("configuring no auth middleware")
-
-	return func(c *gin.Context) {}
-}
-
-func getBasicAuthMiddleware(config *viper.Viper, logger *zap.Logger) gin.HandlerFunc {
-	user := config.GetString("api.basic_auth_user")
-	password := config.GetString("api.basic_auth_password")
-
-	logger.Debug("configuring basic auth middleware", zap.String("user", user), zap.String("password", password))
-
-	return gin.BasicAuth(gin.Accounts{
-		user: password,
-	})
-}
-
-func getAuthMiddleware(config *viper.Viper, logger *zap.Logger) gin.HandlerFunc {
+func getAuthMiddleware(config *viper.Viper, logger *zap.Logger, authService services.AuthService) gin.HandlerFunc {
 	if enableAuth := config.GetBool("api.enable_auth"); enableAuth {
-		return getBasicAuthMiddleware(config, logger)
+		logger.Debug("configuring auth middleware", zap.String("tsuruUser", config.GetString("api.basic_auth_user")))
+		return routers.NewAuthMiddleware(authService)
 	}
 
-	return getNoAuthMiddleware(config, logger)
+	logger.Debug("configuring no auth middleware")
+	return routers.NewNoAuthMiddleware()
 }
 
 func NewGinRouter(
 	config *viper.Viper,
 	logger *zap.Logger,
+	authService services.AuthService,
+	instanceService services.InstanceService,
+	auditService services.AuditService,
 	rootRouter routers.RootRouter,
 	staticRouter routers.StaticRouter,
 	apiRootRouter routers.ApiRootRouter,
 	v1AuthRouter apiV1.AuthRouter,
 	v1InstanceRouter apiV1.InstanceRouter,
 	v1BindRouter apiV1.BindRouter,
+	v1CredentialRouter apiV1.CredentialRouter,
+	v1EventRouter apiV1.EventRouter,
+	v1AppRouter apiV1.AppRouter,
+	v1GcRouter apiV1.GcRouter,
+	v1AdminRouter apiV1.AdminRouter,
+	v1TokenRouter apiV1.TokenRouter,
+	v1AuditRouter apiV1.AuditRouter,
+	v1DeadLetterRouter apiV1.DeadLetterRouter,
+	v1OperationRouter apiV1.OperationRouter,
 ) *gin.Engine {
 	envConfig := config.Get("env")
 	if envConfig == "prod" {
@@ -61,7 +59,8 @@ func NewGinRouter(
 	})
 
 	g(baseRouter, "/api", func(r gin.IRouter) {
-		r.Use(getAuthMiddleware(config, logger))
+		r.Use(routers.NewRequestIdMiddleware())
+		r.Use(getAuthMiddleware(config, logger, authService))
 
 		g(r, "/", func(r gin.IRouter) {
 			apiRootRouter.SetupRoutes(r)
@@ -73,8 +72,40 @@ func NewGinRouter(
 			})
 
 			g(r, "/resources", func(r gin.IRouter) {
+				// refused calls are audited too
+				r.Use(routers.NewAuditMiddleware(auditService, instanceService, r.(*gin.RouterGroup).BasePath()))
+				r.Use(routers.NewInstanceAccessMiddleware(instanceService))
 				v1InstanceRouter.SetupRoutes(r)
 				v1BindRouter.SetupRoutes(r)
+				v1CredentialRouter.SetupRoutes(r)
+				v1EventRouter.SetupRoutes(r)
+			})
+
+			// the operations are narrowed to the team of members by the router itself
+			g(r, "/operations", func(r gin.IRouter) {
+				v1OperationRouter.SetupRoutes(r)
+			})
+
+			g(r, "/apps", func(r gin.IRouter) {
+				r.Use(routers.NewRoleMiddleware(models.RoleAdmin, models.RoleReadOnly))
+				v1AppRouter.SetupRoutes(r)
+			})
+
+			g(r, "/gc", func(r gin.IRouter) {
+				r.Use(routers.NewRoleMiddleware(models.RoleAdmin))
+				v1GcRouter.SetupRoutes(r)
+			})
+
+			g(r, "/audit", func(r gin.IRouter) {
+				r.Use(routers.NewRoleMiddleware(models.RoleAdmin, models.RoleReadOnly))
+				v1AuditRouter.SetupRoutes(r)
+			})
+
+			g(r, "/admin", func(r gin.IRouter) {
+				r.Use(routers.NewRoleMiddleware(models.RoleAdmin))
+				v1AdminRouter.SetupRoutes(r)
+				v1TokenRouter.SetupRoutes(r)
+				v1DeadLetterRouter.SetupRoutes(r)
 			})
 		})
 	})
@@ -110,3 +141,39 @@ func NewInstanceRouter(instanceService services.InstanceService, planService ser
 func NewBindRouter(bindService services.BindService) apiV1.BindRouter {
 	return apiV1.NewBindRouter(bindService)
 }
+
+func NewCredentialRouter(credentialService services.CredentialService) apiV1.CredentialRouter {
+	return apiV1.NewCredentialRouter(credentialService)
+}
+
+func NewEventRouter(instanceService services.InstanceService, eventService services.EventService) apiV1.EventRouter {
+	return apiV1.NewEventRouter(instanceService, eventService)
+}
+
+func NewAppRouter(bindService services.BindService) apiV1.AppRouter {
+	return apiV1.NewAppRouter(bindService)
+}
+
+func NewGcRouter(gcService services.GcService) apiV1.GcRouter {
+	return apiV1.NewGcRouter(gcService)
+}
+
+func NewAdminRouter(instanceService services.InstanceService) apiV1.AdminRouter {
+	return apiV1.NewAdminRouter(instanceService)
+}
+
+func NewTokenRouter(authService services.AuthService) apiV1.TokenRouter {
+	return apiV1.NewTokenRouter(authService)
+}
+
+func NewAuditRouter(auditService services.AuditService) apiV1.AuditRouter {
+	return apiV1.NewAuditRouter(auditService)
+}
+
+func NewDeadLetterRouter(deadLetterService services.DeadLetterService) apiV1.DeadLetterRouter {
+	return apiV1.NewDeadLetterRouter(deadLetterService)
+}
+
+func NewOperationRouter(operationService services.OperationService) apiV1.OperationRouter {
+	return apiV1.NewOperationRouter(operationService)
+}
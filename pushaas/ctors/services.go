@@ -6,21 +6,77 @@ import (
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/repositories"
 	"github.com/pushaas/pushaas/pushaas/services"
 )
 
-func NewPlanService() services.PlanService {
-	return services.NewPlanService()
+func NewPlanService(config *viper.Viper) (services.PlanService, error) {
+	return services.NewPlanService(config)
 }
 
-func NewBindService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient, instanceService services.InstanceService) services.BindService {
-	return services.NewBindService(config, logger, redisClient, instanceService)
+func NewBindService(config *viper.Viper, logger *zap.Logger, bindRepository repositories.BindRepository, instanceService services.InstanceService, pushApiService services.PushApiService, eventService services.EventService) services.BindService {
+	return services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, eventService)
 }
 
-func NewProvisionService(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server) services.ProvisionService {
-	return services.NewProvisionService(config, logger, machineryServer)
+func NewPushApiService(config *viper.Viper, logger *zap.Logger) services.PushApiService {
+	return services.NewPushApiService(config, logger)
 }
 
-func NewInstanceService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient, provisionService services.ProvisionService) services.InstanceService {
-	return services.NewInstanceService(config, logger, redisClient, provisionService)
+func NewProvisionService(config *viper.Viper, logger *zap.Logger, operationService services.OperationService) services.ProvisionService {
+	return services.NewProvisionService(config, logger, operationService)
+}
+
+func NewInstanceService(config *viper.Viper, logger *zap.Logger, instanceRepository repositories.InstanceRepository, bindRepository repositories.BindRepository, planService services.PlanService, provisionService services.ProvisionService, lockService services.InstanceLockService) services.InstanceService {
+	return services.NewInstanceService(config, logger, instanceRepository, bindRepository, planService, provisionService, lockService)
+}
+
+func NewGcService(config *viper.Viper, logger *zap.Logger, instanceService services.InstanceService, provisioner provisioners.PushServiceProvisioner) services.GcService {
+	collector, ok := provisioner.(provisioners.PushServiceResourceCollector)
+	if !ok {
+		logger.Info("provider is not able to list its resources, garbage collection is not supported")
+		return services.NewGcService(config, logger, instanceService, nil)
+	}
+	return services.NewGcService(config, logger, instanceService, collector)
+}
+
+func NewEventService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) services.EventService {
+	return services.NewEventService(config, logger, redisClient)
+}
+
+func NewAuthService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) services.AuthService {
+	return services.NewAuthService(config, logger, redisClient)
+}
+
+func NewAuditService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) services.AuditService {
+	return services.NewAuditService(config, logger, redisClient)
+}
+
+func NewDeadLetterService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient, machineryServer *machinery.Server, instanceService services.InstanceService) services.DeadLetterService {
+	return services.NewDeadLetterService(config, logger, redisClient, machineryServer, instanceService)
+}
+
+func NewInstanceLockService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) services.InstanceLockService {
+	return services.NewInstanceLockService(config, logger, redisClient)
+}
+
+func NewOperationService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) services.OperationService {
+	return services.NewOperationService(config, logger, redisClient)
+}
+
+func NewTaskOutboxService(config *viper.Viper, logger *zap.Logger, outboxRepository repositories.TaskOutboxRepository, machineryServer *machinery.Server) services.TaskOutboxService {
+	return services.NewTaskOutboxService(config, logger, outboxRepository, machineryServer)
+}
+
+func NewTsuruService(config *viper.Viper, logger *zap.Logger) services.TsuruService {
+	return services.NewTsuruService(config, logger)
+}
+
+func NewCredentialService(config *viper.Viper, logger *zap.Logger, instanceService services.InstanceService, provisionService services.ProvisionService, bindService services.BindService, tsuruService services.TsuruService, provisioner provisioners.PushServiceProvisioner) services.CredentialService {
+	rotator, ok := provisioner.(provisioners.PushServiceCredentialRotator)
+	if !ok {
+		logger.Info("provider is not able to rotate credentials, credentials rotation is not supported")
+		return services.NewCredentialService(config, logger, instanceService, provisionService, bindService, tsuruService, nil)
+	}
+	return services.NewCredentialService(config, logger, instanceService, provisionService, bindService, tsuruService, rotator)
 }
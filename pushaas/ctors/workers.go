@@ -10,15 +10,26 @@ import (
 	"github.com/pushaas/pushaas/pushaas/workers"
 )
 
-func NewProvisionWorker(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server, provisioner provisioners.PushServiceProvisioner) workers.ProvisionWorker {
-	return workers.NewProvisionWorker(config, logger, machineryServer, provisioner)
+func NewProvisionWorker(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server, lockService services.InstanceLockService, operationService services.OperationService, instanceService services.InstanceService, credentialService services.CredentialService, eventService services.EventService, provisioner provisioners.PushServiceProvisioner) workers.ProvisionWorker {
+	return workers.NewProvisionWorker(config, logger, machineryServer, lockService, operationService, instanceService, credentialService, eventService, provisioner)
 }
 
-func NewInstanceWorker(config *viper.Viper, logger *zap.Logger, instanceService services.InstanceService) workers.InstanceWorker {
-	return workers.NewInstanceWorker(config, logger, instanceService)
+func NewInstanceWorker(config *viper.Viper, logger *zap.Logger, instanceService services.InstanceService, operationService services.OperationService, eventService services.EventService) workers.InstanceWorker {
+	return workers.NewInstanceWorker(config, logger, instanceService, operationService, eventService)
 }
 
-func NewMachineryWorker(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server, provisionWorker workers.ProvisionWorker, instanceWorker workers.InstanceWorker) workers.MachineryWorker {
-	return workers.NewMachineryWorker(config, logger, machineryServer, provisionWorker, instanceWorker)
+func NewMachineryWorker(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server, provisionWorker workers.ProvisionWorker, instanceWorker workers.InstanceWorker, deadLetterService services.DeadLetterService, operationService services.OperationService) workers.MachineryWorker {
+	return workers.NewMachineryWorker(config, logger, machineryServer, provisionWorker, instanceWorker, deadLetterService, operationService)
 }
 
+func NewReconcileWorker(config *viper.Viper, logger *zap.Logger, instanceService services.InstanceService, lockService services.InstanceLockService, eventService services.EventService, provisioner provisioners.PushServiceProvisioner) workers.ReconcileWorker {
+	return workers.NewReconcileWorker(config, logger, instanceService, lockService, eventService, provisioner)
+}
+
+func NewOutboxWorker(config *viper.Viper, logger *zap.Logger, taskOutboxService services.TaskOutboxService) workers.OutboxWorker {
+	return workers.NewOutboxWorker(config, logger, taskOutboxService)
+}
+
+func NewGcWorker(config *viper.Viper, logger *zap.Logger, gcService services.GcService) workers.GcWorker {
+	return workers.NewGcWorker(config, logger, gcService)
+}
@@ -0,0 +1,485 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/repositories"
+	"sync"
+)
+
+var (
+	lockBindRepositoryMockAddBindUnit         sync.RWMutex
+	lockBindRepositoryMockCreateBindApp       sync.RWMutex
+	lockBindRepositoryMockDelBindApp          sync.RWMutex
+	lockBindRepositoryMockGetBindApp          sync.RWMutex
+	lockBindRepositoryMockGetBindApps         sync.RWMutex
+	lockBindRepositoryMockGetBindUnits        sync.RWMutex
+	lockBindRepositoryMockGetInstancesBoundTo sync.RWMutex
+	lockBindRepositoryMockRemoveBindUnit      sync.RWMutex
+	lockBindRepositoryMockSaveBindApp         sync.RWMutex
+)
+
+// Ensure, that BindRepositoryMock does implement BindRepository.
+// If this is not the case, regenerate this file with moq.
+var _ repositories.BindRepository = &BindRepositoryMock{}
+
+// BindRepositoryMock is a mock implementation of BindRepository.
+//
+//	    func TestSomethingThatUsesBindRepository(t *testing.T) {
+//
+//	        // make and configure a mocked BindRepository
+//	        mockedBindRepository := &BindRepositoryMock{
+//	            AddBindUnitFunc: func(instanceName string, appName string, unitHost string) error {
+//		               panic("mock out the AddBindUnit method")
+//	            },
+//	            CreateBindAppFunc: func(instanceName string, bindApp *models.BindApp) error {
+//		               panic("mock out the CreateBindApp method")
+//	            },
+//	            DelBindAppFunc: func(instanceName string, appName string) error {
+//		               panic("mock out the DelBindApp method")
+//	            },
+//	            GetBindAppFunc: func(instanceName string, appName string) (*models.BindApp, error) {
+//		               panic("mock out the GetBindApp method")
+//	            },
+//	            GetBindAppsFunc: func(instanceName string) ([]*models.BindApp, error) {
+//		               panic("mock out the GetBindApps method")
+//	            },
+//	            GetBindUnitsFunc: func(instanceName string, appName string) ([]string, error) {
+//		               panic("mock out the GetBindUnits method")
+//	            },
+//	            GetInstancesBoundToFunc: func(appName string) ([]string, error) {
+//		               panic("mock out the GetInstancesBoundTo method")
+//	            },
+//	            RemoveBindUnitFunc: func(instanceName string, appName string, unitHost string) error {
+//		               panic("mock out the RemoveBindUnit method")
+//	            },
+//	            SaveBindAppFunc: func(instanceName string, bindApp *models.BindApp) error {
+//		               panic("mock out the SaveBindApp method")
+//	            },
+//	        }
+//
+//	        // use mockedBindRepository in code that requires BindRepository
+//	        // and then make assertions.
+//
+//	    }
+type BindRepositoryMock struct {
+	// AddBindUnitFunc mocks the AddBindUnit method.
+	AddBindUnitFunc func(instanceName string, appName string, unitHost string) error
+
+	// CreateBindAppFunc mocks the CreateBindApp method.
+	CreateBindAppFunc func(instanceName string, bindApp *models.BindApp) error
+
+	// DelBindAppFunc mocks the DelBindApp method.
+	DelBindAppFunc func(instanceName string, appName string) error
+
+	// GetBindAppFunc mocks the GetBindApp method.
+	GetBindAppFunc func(instanceName string, appName string) (*models.BindApp, error)
+
+	// GetBindAppsFunc mocks the GetBindApps method.
+	GetBindAppsFunc func(instanceName string) ([]*models.BindApp, error)
+
+	// GetBindUnitsFunc mocks the GetBindUnits method.
+	GetBindUnitsFunc func(instanceName string, appName string) ([]string, error)
+
+	// GetInstancesBoundToFunc mocks the GetInstancesBoundTo method.
+	GetInstancesBoundToFunc func(appName string) ([]string, error)
+
+	// RemoveBindUnitFunc mocks the RemoveBindUnit method.
+	RemoveBindUnitFunc func(instanceName string, appName string, unitHost string) error
+
+	// SaveBindAppFunc mocks the SaveBindApp method.
+	SaveBindAppFunc func(instanceName string, bindApp *models.BindApp) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddBindUnit holds details about calls to the AddBindUnit method.
+		AddBindUnit []struct {
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+			// AppName is the appName argument value.
+			AppName string
+			// UnitHost is the unitHost argument value.
+			UnitHost string
+		}
+		// CreateBindApp holds details about calls to the CreateBindApp method.
+		CreateBindApp []struct {
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+			// BindApp is the bindApp argument value.
+			BindApp *models.BindApp
+		}
+		// DelBindApp holds details about calls to the DelBindApp method.
+		DelBindApp []struct {
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+			// AppName is the appName argument value.
+			AppName string
+		}
+		// GetBindApp holds details about calls to the GetBindApp method.
+		GetBindApp []struct {
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+			// AppName is the appName argument value.
+			AppName string
+		}
+		// GetBindApps holds details about calls to the GetBindApps method.
+		GetBindApps []struct {
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+		}
+		// GetBindUnits holds details about calls to the GetBindUnits method.
+		GetBindUnits []struct {
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+			// AppName is the appName argument value.
+			AppName string
+		}
+		// GetInstancesBoundTo holds details about calls to the GetInstancesBoundTo method.
+		GetInstancesBoundTo []struct {
+			// AppName is the appName argument value.
+			AppName string
+		}
+		// RemoveBindUnit holds details about calls to the RemoveBindUnit method.
+		RemoveBindUnit []struct {
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+			// AppName is the appName argument value.
+			AppName string
+			// UnitHost is the unitHost argument value.
+			UnitHost string
+		}
+		// SaveBindApp holds details about calls to the SaveBindApp method.
+		SaveBindApp []struct {
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+			// BindApp is the bindApp argument value.
+			BindApp *models.BindApp
+		}
+	}
+}
+
+// AddBindUnit calls AddBindUnitFunc.
+func (mock *BindRepositoryMock) AddBindUnit(instanceName string, appName string, unitHost string) error {
+	if mock.AddBindUnitFunc == nil {
+		panic("BindRepositoryMock.AddBindUnitFunc: method is nil but BindRepository.AddBindUnit was just called")
+	}
+	callInfo := struct {
+		InstanceName string
+		AppName      string
+		UnitHost     string
+	}{
+		InstanceName: instanceName,
+		AppName:      appName,
+		UnitHost:     unitHost,
+	}
+	lockBindRepositoryMockAddBindUnit.Lock()
+	mock.calls.AddBindUnit = append(mock.calls.AddBindUnit, callInfo)
+	lockBindRepositoryMockAddBindUnit.Unlock()
+	return mock.AddBindUnitFunc(instanceName, appName, unitHost)
+}
+
+// AddBindUnitCalls gets all the calls that were made to AddBindUnit.
+// Check the length with:
+//
+//	len(mockedBindRepository.AddBindUnitCalls())
+func (mock *BindRepositoryMock) AddBindUnitCalls() []struct {
+	InstanceName string
+	AppName      string
+	UnitHost     string
+} {
+	var calls []struct {
+		InstanceName string
+		AppName      string
+		UnitHost     string
+	}
+	lockBindRepositoryMockAddBindUnit.RLock()
+	calls = mock.calls.AddBindUnit
+	lockBindRepositoryMockAddBindUnit.RUnlock()
+	return calls
+}
+
+// CreateBindApp calls CreateBindAppFunc.
+func (mock *BindRepositoryMock) CreateBindApp(instanceName string, bindApp *models.BindApp) error {
+	if mock.CreateBindAppFunc == nil {
+		panic("BindRepositoryMock.CreateBindAppFunc: method is nil but BindRepository.CreateBindApp was just called")
+	}
+	callInfo := struct {
+		InstanceName string
+		BindApp      *models.BindApp
+	}{
+		InstanceName: instanceName,
+		BindApp:      bindApp,
+	}
+	lockBindRepositoryMockCreateBindApp.Lock()
+	mock.calls.CreateBindApp = append(mock.calls.CreateBindApp, callInfo)
+	lockBindRepositoryMockCreateBindApp.Unlock()
+	return mock.CreateBindAppFunc(instanceName, bindApp)
+}
+
+// CreateBindAppCalls gets all the calls that were made to CreateBindApp.
+// Check the length with:
+//
+//	len(mockedBindRepository.CreateBindAppCalls())
+func (mock *BindRepositoryMock) CreateBindAppCalls() []struct {
+	InstanceName string
+	BindApp      *models.BindApp
+} {
+	var calls []struct {
+		InstanceName string
+		BindApp      *models.BindApp
+	}
+	lockBindRepositoryMockCreateBindApp.RLock()
+	calls = mock.calls.CreateBindApp
+	lockBindRepositoryMockCreateBindApp.RUnlock()
+	return calls
+}
+
+// DelBindApp calls DelBindAppFunc.
+func (mock *BindRepositoryMock) DelBindApp(instanceName string, appName string) error {
+	if mock.DelBindAppFunc == nil {
+		panic("BindRepositoryMock.DelBindAppFunc: method is nil but BindRepository.DelBindApp was just called")
+	}
+	callInfo := struct {
+		InstanceName string
+		AppName      string
+	}{
+		InstanceName: instanceName,
+		AppName:      appName,
+	}
+	lockBindRepositoryMockDelBindApp.Lock()
+	mock.calls.DelBindApp = append(mock.calls.DelBindApp, callInfo)
+	lockBindRepositoryMockDelBindApp.Unlock()
+	return mock.DelBindAppFunc(instanceName, appName)
+}
+
+// DelBindAppCalls gets all the calls that were made to DelBindApp.
+// Check the length with:
+//
+//	len(mockedBindRepository.DelBindAppCalls())
+func (mock *BindRepositoryMock) DelBindAppCalls() []struct {
+	InstanceName string
+	AppName      string
+} {
+	var calls []struct {
+		InstanceName string
+		AppName      string
+	}
+	lockBindRepositoryMockDelBindApp.RLock()
+	calls = mock.calls.DelBindApp
+	lockBindRepositoryMockDelBindApp.RUnlock()
+	return calls
+}
+
+// GetBindApp calls GetBindAppFunc.
+func (mock *BindRepositoryMock) GetBindApp(instanceName string, appName string) (*models.BindApp, error) {
+	if mock.GetBindAppFunc == nil {
+		panic("BindRepositoryMock.GetBindAppFunc: method is nil but BindRepository.GetBindApp was just called")
+	}
+	callInfo := struct {
+		InstanceName string
+		AppName      string
+	}{
+		InstanceName: instanceName,
+		AppName:      appName,
+	}
+	lockBindRepositoryMockGetBindApp.Lock()
+	mock.calls.GetBindApp = append(mock.calls.GetBindApp, callInfo)
+	lockBindRepositoryMockGetBindApp.Unlock()
+	return mock.GetBindAppFunc(instanceName, appName)
+}
+
+// GetBindAppCalls gets all the calls that were made to GetBindApp.
+// Check the length with:
+//
+//	len(mockedBindRepository.GetBindAppCalls())
+func (mock *BindRepositoryMock) GetBindAppCalls() []struct {
+	InstanceName string
+	AppName      string
+} {
+	var calls []struct {
+		InstanceName string
+		AppName      string
+	}
+	lockBindRepositoryMockGetBindApp.RLock()
+	calls = mock.calls.GetBindApp
+	lockBindRepositoryMockGetBindApp.RUnlock()
+	return calls
+}
+
+// GetBindApps calls GetBindAppsFunc.
+func (mock *BindRepositoryMock) GetBindApps(instanceName string) ([]*models.BindApp, error) {
+	if mock.GetBindAppsFunc == nil {
+		panic("BindRepositoryMock.GetBindAppsFunc: method is nil but BindRepository.GetBindApps was just called")
+	}
+	callInfo := struct {
+		InstanceName string
+	}{
+		InstanceName: instanceName,
+	}
+	lockBindRepositoryMockGetBindApps.Lock()
+	mock.calls.GetBindApps = append(mock.calls.GetBindApps, callInfo)
+	lockBindRepositoryMockGetBindApps.Unlock()
+	return mock.GetBindAppsFunc(instanceName)
+}
+
+// GetBindAppsCalls gets all the calls that were made to GetBindApps.
+// Check the length with:
+//
+//	len(mockedBindRepository.GetBindAppsCalls())
+func (mock *BindRepositoryMock) GetBindAppsCalls() []struct {
+	InstanceName string
+} {
+	var calls []struct {
+		InstanceName string
+	}
+	lockBindRepositoryMockGetBindApps.RLock()
+	calls = mock.calls.GetBindApps
+	lockBindRepositoryMockGetBindApps.RUnlock()
+	return calls
+}
+
+// GetBindUnits calls GetBindUnitsFunc.
+func (mock *BindRepositoryMock) GetBindUnits(instanceName string, appName string) ([]string, error) {
+	if mock.GetBindUnitsFunc == nil {
+		panic("BindRepositoryMock.GetBindUnitsFunc: method is nil but BindRepository.GetBindUnits was just called")
+	}
+	callInfo := struct {
+		InstanceName string
+		AppName      string
+	}{
+		InstanceName: instanceName,
+		AppName:      appName,
+	}
+	lockBindRepositoryMockGetBindUnits.Lock()
+	mock.calls.GetBindUnits = append(mock.calls.GetBindUnits, callInfo)
+	lockBindRepositoryMockGetBindUnits.Unlock()
+	return mock.GetBindUnitsFunc(instanceName, appName)
+}
+
+// GetBindUnitsCalls gets all the calls that were made to GetBindUnits.
+// Check the length with:
+//
+//	len(mockedBindRepository.GetBindUnitsCalls())
+func (mock *BindRepositoryMock) GetBindUnitsCalls() []struct {
+	InstanceName string
+	AppName      string
+} {
+	var calls []struct {
+		InstanceName string
+		AppName      string
+	}
+	lockBindRepositoryMockGetBindUnits.RLock()
+	calls = mock.calls.GetBindUnits
+	lockBindRepositoryMockGetBindUnits.RUnlock()
+	return calls
+}
+
+// GetInstancesBoundTo calls GetInstancesBoundToFunc.
+func (mock *BindRepositoryMock) GetInstancesBoundTo(appName string) ([]string, error) {
+	if mock.GetInstancesBoundToFunc == nil {
+		panic("BindRepositoryMock.GetInstancesBoundToFunc: method is nil but BindRepository.GetInstancesBoundTo was just called")
+	}
+	callInfo := struct {
+		AppName string
+	}{
+		AppName: appName,
+	}
+	lockBindRepositoryMockGetInstancesBoundTo.Lock()
+	mock.calls.GetInstancesBoundTo = append(mock.calls.GetInstancesBoundTo, callInfo)
+	lockBindRepositoryMockGetInstancesBoundTo.Unlock()
+	return mock.GetInstancesBoundToFunc(appName)
+}
+
+// GetInstancesBoundToCalls gets all the calls that were made to GetInstancesBoundTo.
+// Check the length with:
+//
+//	len(mockedBindRepository.GetInstancesBoundToCalls())
+func (mock *BindRepositoryMock) GetInstancesBoundToCalls() []struct {
+	AppName string
+} {
+	var calls []struct {
+		AppName string
+	}
+	lockBindRepositoryMockGetInstancesBoundTo.RLock()
+	calls = mock.calls.GetInstancesBoundTo
+	lockBindRepositoryMockGetInstancesBoundTo.RUnlock()
+	return calls
+}
+
+// RemoveBindUnit calls RemoveBindUnitFunc.
+func (mock *BindRepositoryMock) RemoveBindUnit(instanceName string, appName string, unitHost string) error {
+	if mock.RemoveBindUnitFunc == nil {
+		panic("BindRepositoryMock.RemoveBindUnitFunc: method is nil but BindRepository.RemoveBindUnit was just called")
+	}
+	callInfo := struct {
+		InstanceName string
+		AppName      string
+		UnitHost     string
+	}{
+		InstanceName: instanceName,
+		AppName:      appName,
+		UnitHost:     unitHost,
+	}
+	lockBindRepositoryMockRemoveBindUnit.Lock()
+	mock.calls.RemoveBindUnit = append(mock.calls.RemoveBindUnit, callInfo)
+	lockBindRepositoryMockRemoveBindUnit.Unlock()
+	return mock.RemoveBindUnitFunc(instanceName, appName, unitHost)
+}
+
+// RemoveBindUnitCalls gets all the calls that were made to RemoveBindUnit.
+// Check the length with:
+//
+//	len(mockedBindRepository.RemoveBindUnitCalls())
+func (mock *BindRepositoryMock) RemoveBindUnitCalls() []struct {
+	InstanceName string
+	AppName      string
+	UnitHost     string
+} {
+	var calls []struct {
+		InstanceName string
+		AppName      string
+		UnitHost     string
+	}
+	lockBindRepositoryMockRemoveBindUnit.RLock()
+	calls = mock.calls.RemoveBindUnit
+	lockBindRepositoryMockRemoveBindUnit.RUnlock()
+	return calls
+}
+
+// SaveBindApp calls SaveBindAppFunc.
+func (mock *BindRepositoryMock) SaveBindApp(instanceName string, bindApp *models.BindApp) error {
+	if mock.SaveBindAppFunc == nil {
+		panic("BindRepositoryMock.SaveBindAppFunc: method is nil but BindRepository.SaveBindApp was just called")
+	}
+	callInfo := struct {
+		InstanceName string
+		BindApp      *models.BindApp
+	}{
+		InstanceName: instanceName,
+		BindApp:      bindApp,
+	}
+	lockBindRepositoryMockSaveBindApp.Lock()
+	mock.calls.SaveBindApp = append(mock.calls.SaveBindApp, callInfo)
+	lockBindRepositoryMockSaveBindApp.Unlock()
+	return mock.SaveBindAppFunc(instanceName, bindApp)
+}
+
+// SaveBindAppCalls gets all the calls that were made to SaveBindApp.
+// Check the length with:
+//
+//	len(mockedBindRepository.SaveBindAppCalls())
+func (mock *BindRepositoryMock) SaveBindAppCalls() []struct {
+	InstanceName string
+	BindApp      *models.BindApp
+} {
+	var calls []struct {
+		InstanceName string
+		BindApp      *models.BindApp
+	}
+	lockBindRepositoryMockSaveBindApp.RLock()
+	calls = mock.calls.SaveBindApp
+	lockBindRepositoryMockSaveBindApp.RUnlock()
+	return calls
+}
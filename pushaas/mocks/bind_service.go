@@ -10,10 +10,14 @@ import (
 )
 
 var (
-	lockBindServiceMockBindApp    sync.RWMutex
-	lockBindServiceMockBindUnit   sync.RWMutex
-	lockBindServiceMockUnbindApp  sync.RWMutex
-	lockBindServiceMockUnbindUnit sync.RWMutex
+	lockBindServiceMockBindApp              sync.RWMutex
+	lockBindServiceMockBindUnit             sync.RWMutex
+	lockBindServiceMockGetAppBindings       sync.RWMutex
+	lockBindServiceMockGetBinding           sync.RWMutex
+	lockBindServiceMockGetBindings          sync.RWMutex
+	lockBindServiceMockRotateAppCredentials sync.RWMutex
+	lockBindServiceMockUnbindApp            sync.RWMutex
+	lockBindServiceMockUnbindUnit           sync.RWMutex
 )
 
 // Ensure, that BindServiceMock does implement BindService.
@@ -22,34 +26,58 @@ var _ services.BindService = &BindServiceMock{}
 
 // BindServiceMock is a mock implementation of BindService.
 //
-//     func TestSomethingThatUsesBindService(t *testing.T) {
+//	    func TestSomethingThatUsesBindService(t *testing.T) {
 //
-//         // make and configure a mocked BindService
-//         mockedBindService := &BindServiceMock{
-//             BindAppFunc: func(name string, bindAppForm *models.BindAppForm) (map[string]string, services.BindAppResult) {
-// 	               panic("mock out the BindApp method")
-//             },
-//             BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) services.BindUnitResult {
-// 	               panic("mock out the BindUnit method")
-//             },
-//             UnbindAppFunc: func(name string, bindAppForm *models.BindAppForm) services.UnbindAppResult {
-// 	               panic("mock out the UnbindApp method")
-//             },
-//             UnbindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) services.UnbindUnitResult {
-// 	               panic("mock out the UnbindUnit method")
-//             },
-//         }
+//	        // make and configure a mocked BindService
+//	        mockedBindService := &BindServiceMock{
+//	            BindAppFunc: func(name string, bindAppForm *models.BindAppForm) (map[string]string, services.BindAppResult) {
+//		               panic("mock out the BindApp method")
+//	            },
+//	            BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) (map[string]string, services.BindUnitResult) {
+//		               panic("mock out the BindUnit method")
+//	            },
+//	            GetAppBindingsFunc: func(appName string) ([]string, services.BindAppRetrievalResult) {
+//		               panic("mock out the GetAppBindings method")
+//	            },
+//	            GetBindingFunc: func(name string, appName string) (*models.Binding, services.BindAppRetrievalResult) {
+//		               panic("mock out the GetBinding method")
+//	            },
+//	            GetBindingsFunc: func(name string) ([]*models.Binding, services.BindAppRetrievalResult) {
+//		               panic("mock out the GetBindings method")
+//	            },
+//	            RotateAppCredentialsFunc: func(name string, instanceVars map[string]string) (map[string]map[string]string, services.RotateAppCredentialsResult) {
+//		               panic("mock out the RotateAppCredentials method")
+//	            },
+//	            UnbindAppFunc: func(name string, bindAppForm *models.BindAppForm) services.UnbindAppResult {
+//		               panic("mock out the UnbindApp method")
+//	            },
+//	            UnbindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) services.UnbindUnitResult {
+//		               panic("mock out the UnbindUnit method")
+//	            },
+//	        }
 //
-//         // use mockedBindService in code that requires BindService
-//         // and then make assertions.
+//	        // use mockedBindService in code that requires BindService
+//	        // and then make assertions.
 //
-//     }
+//	    }
 type BindServiceMock struct {
 	// BindAppFunc mocks the BindApp method.
 	BindAppFunc func(name string, bindAppForm *models.BindAppForm) (map[string]string, services.BindAppResult)
 
 	// BindUnitFunc mocks the BindUnit method.
-	BindUnitFunc func(name string, bindUnitForm *models.BindUnitForm) services.BindUnitResult
+	BindUnitFunc func(name string, bindUnitForm *models.BindUnitForm) (map[string]string, services.BindUnitResult)
+
+	// GetAppBindingsFunc mocks the GetAppBindings method.
+	GetAppBindingsFunc func(appName string) ([]string, services.BindAppRetrievalResult)
+
+	// GetBindingFunc mocks the GetBinding method.
+	GetBindingFunc func(name string, appName string) (*models.Binding, services.BindAppRetrievalResult)
+
+	// GetBindingsFunc mocks the GetBindings method.
+	GetBindingsFunc func(name string) ([]*models.Binding, services.BindAppRetrievalResult)
+
+	// RotateAppCredentialsFunc mocks the RotateAppCredentials method.
+	RotateAppCredentialsFunc func(name string, instanceVars map[string]string) (map[string]map[string]string, services.RotateAppCredentialsResult)
 
 	// UnbindAppFunc mocks the UnbindApp method.
 	UnbindAppFunc func(name string, bindAppForm *models.BindAppForm) services.UnbindAppResult
@@ -73,6 +101,30 @@ type BindServiceMock struct {
 			// BindUnitForm is the bindUnitForm argument value.
 			BindUnitForm *models.BindUnitForm
 		}
+		// GetAppBindings holds details about calls to the GetAppBindings method.
+		GetAppBindings []struct {
+			// AppName is the appName argument value.
+			AppName string
+		}
+		// GetBinding holds details about calls to the GetBinding method.
+		GetBinding []struct {
+			// Name is the name argument value.
+			Name string
+			// AppName is the appName argument value.
+			AppName string
+		}
+		// GetBindings holds details about calls to the GetBindings method.
+		GetBindings []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// RotateAppCredentials holds details about calls to the RotateAppCredentials method.
+		RotateAppCredentials []struct {
+			// Name is the name argument value.
+			Name string
+			// InstanceVars is the instanceVars argument value.
+			InstanceVars map[string]string
+		}
 		// UnbindApp holds details about calls to the UnbindApp method.
 		UnbindApp []struct {
 			// Name is the name argument value.
@@ -110,7 +162,8 @@ func (mock *BindServiceMock) BindApp(name string, bindAppForm *models.BindAppFor
 
 // BindAppCalls gets all the calls that were made to BindApp.
 // Check the length with:
-//     len(mockedBindService.BindAppCalls())
+//
+//	len(mockedBindService.BindAppCalls())
 func (mock *BindServiceMock) BindAppCalls() []struct {
 	Name        string
 	BindAppForm *models.BindAppForm
@@ -126,7 +179,7 @@ func (mock *BindServiceMock) BindAppCalls() []struct {
 }
 
 // BindUnit calls BindUnitFunc.
-func (mock *BindServiceMock) BindUnit(name string, bindUnitForm *models.BindUnitForm) services.BindUnitResult {
+func (mock *BindServiceMock) BindUnit(name string, bindUnitForm *models.BindUnitForm) (map[string]string, services.BindUnitResult) {
 	if mock.BindUnitFunc == nil {
 		panic("BindServiceMock.BindUnitFunc: method is nil but BindService.BindUnit was just called")
 	}
@@ -145,7 +198,8 @@ func (mock *BindServiceMock) BindUnit(name string, bindUnitForm *models.BindUnit
 
 // BindUnitCalls gets all the calls that were made to BindUnit.
 // Check the length with:
-//     len(mockedBindService.BindUnitCalls())
+//
+//	len(mockedBindService.BindUnitCalls())
 func (mock *BindServiceMock) BindUnitCalls() []struct {
 	Name         string
 	BindUnitForm *models.BindUnitForm
@@ -160,6 +214,142 @@ func (mock *BindServiceMock) BindUnitCalls() []struct {
 	return calls
 }
 
+// GetAppBindings calls GetAppBindingsFunc.
+func (mock *BindServiceMock) GetAppBindings(appName string) ([]string, services.BindAppRetrievalResult) {
+	if mock.GetAppBindingsFunc == nil {
+		panic("BindServiceMock.GetAppBindingsFunc: method is nil but BindService.GetAppBindings was just called")
+	}
+	callInfo := struct {
+		AppName string
+	}{
+		AppName: appName,
+	}
+	lockBindServiceMockGetAppBindings.Lock()
+	mock.calls.GetAppBindings = append(mock.calls.GetAppBindings, callInfo)
+	lockBindServiceMockGetAppBindings.Unlock()
+	return mock.GetAppBindingsFunc(appName)
+}
+
+// GetAppBindingsCalls gets all the calls that were made to GetAppBindings.
+// Check the length with:
+//
+//	len(mockedBindService.GetAppBindingsCalls())
+func (mock *BindServiceMock) GetAppBindingsCalls() []struct {
+	AppName string
+} {
+	var calls []struct {
+		AppName string
+	}
+	lockBindServiceMockGetAppBindings.RLock()
+	calls = mock.calls.GetAppBindings
+	lockBindServiceMockGetAppBindings.RUnlock()
+	return calls
+}
+
+// GetBinding calls GetBindingFunc.
+func (mock *BindServiceMock) GetBinding(name string, appName string) (*models.Binding, services.BindAppRetrievalResult) {
+	if mock.GetBindingFunc == nil {
+		panic("BindServiceMock.GetBindingFunc: method is nil but BindService.GetBinding was just called")
+	}
+	callInfo := struct {
+		Name    string
+		AppName string
+	}{
+		Name:    name,
+		AppName: appName,
+	}
+	lockBindServiceMockGetBinding.Lock()
+	mock.calls.GetBinding = append(mock.calls.GetBinding, callInfo)
+	lockBindServiceMockGetBinding.Unlock()
+	return mock.GetBindingFunc(name, appName)
+}
+
+// GetBindingCalls gets all the calls that were made to GetBinding.
+// Check the length with:
+//
+//	len(mockedBindService.GetBindingCalls())
+func (mock *BindServiceMock) GetBindingCalls() []struct {
+	Name    string
+	AppName string
+} {
+	var calls []struct {
+		Name    string
+		AppName string
+	}
+	lockBindServiceMockGetBinding.RLock()
+	calls = mock.calls.GetBinding
+	lockBindServiceMockGetBinding.RUnlock()
+	return calls
+}
+
+// GetBindings calls GetBindingsFunc.
+func (mock *BindServiceMock) GetBindings(name string) ([]*models.Binding, services.BindAppRetrievalResult) {
+	if mock.GetBindingsFunc == nil {
+		panic("BindServiceMock.GetBindingsFunc: method is nil but BindService.GetBindings was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockBindServiceMockGetBindings.Lock()
+	mock.calls.GetBindings = append(mock.calls.GetBindings, callInfo)
+	lockBindServiceMockGetBindings.Unlock()
+	return mock.GetBindingsFunc(name)
+}
+
+// GetBindingsCalls gets all the calls that were made to GetBindings.
+// Check the length with:
+//
+//	len(mockedBindService.GetBindingsCalls())
+func (mock *BindServiceMock) GetBindingsCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockBindServiceMockGetBindings.RLock()
+	calls = mock.calls.GetBindings
+	lockBindServiceMockGetBindings.RUnlock()
+	return calls
+}
+
+// RotateAppCredentials calls RotateAppCredentialsFunc.
+func (mock *BindServiceMock) RotateAppCredentials(name string, instanceVars map[string]string) (map[string]map[string]string, services.RotateAppCredentialsResult) {
+	if mock.RotateAppCredentialsFunc == nil {
+		panic("BindServiceMock.RotateAppCredentialsFunc: method is nil but BindService.RotateAppCredentials was just called")
+	}
+	callInfo := struct {
+		Name         string
+		InstanceVars map[string]string
+	}{
+		Name:         name,
+		InstanceVars: instanceVars,
+	}
+	lockBindServiceMockRotateAppCredentials.Lock()
+	mock.calls.RotateAppCredentials = append(mock.calls.RotateAppCredentials, callInfo)
+	lockBindServiceMockRotateAppCredentials.Unlock()
+	return mock.RotateAppCredentialsFunc(name, instanceVars)
+}
+
+// RotateAppCredentialsCalls gets all the calls that were made to RotateAppCredentials.
+// Check the length with:
+//
+//	len(mockedBindService.RotateAppCredentialsCalls())
+func (mock *BindServiceMock) RotateAppCredentialsCalls() []struct {
+	Name         string
+	InstanceVars map[string]string
+} {
+	var calls []struct {
+		Name         string
+		InstanceVars map[string]string
+	}
+	lockBindServiceMockRotateAppCredentials.RLock()
+	calls = mock.calls.RotateAppCredentials
+	lockBindServiceMockRotateAppCredentials.RUnlock()
+	return calls
+}
+
 // UnbindApp calls UnbindAppFunc.
 func (mock *BindServiceMock) UnbindApp(name string, bindAppForm *models.BindAppForm) services.UnbindAppResult {
 	if mock.UnbindAppFunc == nil {
@@ -180,7 +370,8 @@ func (mock *BindServiceMock) UnbindApp(name string, bindAppForm *models.BindAppF
 
 // UnbindAppCalls gets all the calls that were made to UnbindApp.
 // Check the length with:
-//     len(mockedBindService.UnbindAppCalls())
+//
+//	len(mockedBindService.UnbindAppCalls())
 func (mock *BindServiceMock) UnbindAppCalls() []struct {
 	Name        string
 	BindAppForm *models.BindAppForm
@@ -215,7 +406,8 @@ func (mock *BindServiceMock) UnbindUnit(name string, bindUnitForm *models.BindUn
 
 // UnbindUnitCalls gets all the calls that were made to UnbindUnit.
 // Check the length with:
-//     len(mockedBindService.UnbindUnitCalls())
+//
+//	len(mockedBindService.UnbindUnitCalls())
 func (mock *BindServiceMock) UnbindUnitCalls() []struct {
 	Name         string
 	BindUnitForm *models.BindUnitForm
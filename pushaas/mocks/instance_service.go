@@ -10,10 +10,28 @@ import (
 )
 
 var (
-	lockInstanceServiceMockCreate          sync.RWMutex
-	lockInstanceServiceMockDelete          sync.RWMutex
-	lockInstanceServiceMockGetByName       sync.RWMutex
-	lockInstanceServiceMockGetStatusByName sync.RWMutex
+	lockInstanceServiceMockCreate               sync.RWMutex
+	lockInstanceServiceMockDelInstanceVars      sync.RWMutex
+	lockInstanceServiceMockDelete               sync.RWMutex
+	lockInstanceServiceMockFinishPending        sync.RWMutex
+	lockInstanceServiceMockGetAll               sync.RWMutex
+	lockInstanceServiceMockGetByName            sync.RWMutex
+	lockInstanceServiceMockGetInfo              sync.RWMutex
+	lockInstanceServiceMockGetInstanceVars      sync.RWMutex
+	lockInstanceServiceMockGetStatusByName      sync.RWMutex
+	lockInstanceServiceMockGetStatusHistory     sync.RWMutex
+	lockInstanceServiceMockIsBusy               sync.RWMutex
+	lockInstanceServiceMockList                 sync.RWMutex
+	lockInstanceServiceMockRecreate             sync.RWMutex
+	lockInstanceServiceMockRemove               sync.RWMutex
+	lockInstanceServiceMockRevertChange         sync.RWMutex
+	lockInstanceServiceMockSetInstanceVars      sync.RWMutex
+	lockInstanceServiceMockUpdate               sync.RWMutex
+	lockInstanceServiceMockUpdateResources      sync.RWMutex
+	lockInstanceServiceMockUpdateRollback       sync.RWMutex
+	lockInstanceServiceMockUpdateStatus         sync.RWMutex
+	lockInstanceServiceMockUpdateStatusFrom     sync.RWMutex
+	lockInstanceServiceMockUpdateStatusWithTask sync.RWMutex
 )
 
 // Ensure, that InstanceServiceMock does implement InstanceService.
@@ -22,41 +40,149 @@ var _ services.InstanceService = &InstanceServiceMock{}
 
 // InstanceServiceMock is a mock implementation of InstanceService.
 //
-//     func TestSomethingThatUsesInstanceService(t *testing.T) {
-//
-//         // make and configure a mocked InstanceService
-//         mockedInstanceService := &InstanceServiceMock{
-//             CreateFunc: func(instanceForm *models.InstanceForm) services.InstanceCreationResult {
-// 	               panic("mock out the Create method")
-//             },
-//             DeleteFunc: func(name string) services.InstanceDeletionResult {
-// 	               panic("mock out the Delete method")
-//             },
-//             GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
-// 	               panic("mock out the GetByName method")
-//             },
-//             GetStatusByNameFunc: func(name string) services.InstanceStatusResult {
-// 	               panic("mock out the GetStatusByName method")
-//             },
-//         }
-//
-//         // use mockedInstanceService in code that requires InstanceService
-//         // and then make assertions.
-//
-//     }
+//	    func TestSomethingThatUsesInstanceService(t *testing.T) {
+//
+//	        // make and configure a mocked InstanceService
+//	        mockedInstanceService := &InstanceServiceMock{
+//	            CreateFunc: func(instanceForm *models.InstanceForm) (*models.Operation, services.InstanceCreationResult) {
+//		               panic("mock out the Create method")
+//	            },
+//	            DelInstanceVarsFunc: func(name string) error {
+//		               panic("mock out the DelInstanceVars method")
+//	            },
+//	            DeleteFunc: func(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult) {
+//		               panic("mock out the Delete method")
+//	            },
+//	            FinishPendingFunc: func(name string, status models.InstanceStatus, reason string, rollback *models.InstanceRollback, vars map[string]string) services.InstanceUpdateResult {
+//		               panic("mock out the FinishPending method")
+//	            },
+//	            GetAllFunc: func() ([]*models.Instance, services.InstanceRetrievalResult) {
+//		               panic("mock out the GetAll method")
+//	            },
+//	            GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+//		               panic("mock out the GetByName method")
+//	            },
+//	            GetInfoFunc: func(name string) ([]*models.InstanceInfoItem, services.InstanceRetrievalResult) {
+//		               panic("mock out the GetInfo method")
+//	            },
+//	            GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+//		               panic("mock out the GetInstanceVars method")
+//	            },
+//	            GetStatusByNameFunc: func(name string) services.InstanceStatusResult {
+//		               panic("mock out the GetStatusByName method")
+//	            },
+//	            GetStatusHistoryFunc: func(name string) ([]*models.InstanceStatusTransition, services.InstanceRetrievalResult) {
+//		               panic("mock out the GetStatusHistory method")
+//	            },
+//	            IsBusyFunc: func(name string) (bool, error) {
+//		               panic("mock out the IsBusy method")
+//	            },
+//	            ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, services.InstanceRetrievalResult) {
+//		               panic("mock out the List method")
+//	            },
+//	            RecreateFunc: func(instance *models.Instance, reason string) (*models.Operation, services.InstanceUpdateResult) {
+//		               panic("mock out the Recreate method")
+//	            },
+//	            RemoveFunc: func(name string) services.InstanceDeletionResult {
+//		               panic("mock out the Remove method")
+//	            },
+//	            RevertChangeFunc: func(name string, previous *models.InstancePrevious, reason string, vars map[string]string) services.InstanceUpdateResult {
+//		               panic("mock out the RevertChange method")
+//	            },
+//	            SetInstanceVarsFunc: func(name string, envVars map[string]string) error {
+//		               panic("mock out the SetInstanceVars method")
+//	            },
+//	            UpdateFunc: func(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult) {
+//		               panic("mock out the Update method")
+//	            },
+//	            UpdateResourcesFunc: func(name string, resources map[string]string) services.InstanceUpdateResult {
+//		               panic("mock out the UpdateResources method")
+//	            },
+//	            UpdateRollbackFunc: func(name string, rollback models.InstanceRollback) services.InstanceUpdateResult {
+//		               panic("mock out the UpdateRollback method")
+//	            },
+//	            UpdateStatusFunc: func(name string, status models.InstanceStatus, reason string) services.InstanceUpdateResult {
+//		               panic("mock out the UpdateStatus method")
+//	            },
+//	            UpdateStatusFromFunc: func(name string, from models.InstanceStatus, status models.InstanceStatus, reason string) services.InstanceUpdateResult {
+//		               panic("mock out the UpdateStatusFrom method")
+//	            },
+//	            UpdateStatusWithTaskFunc: func(name string, from models.InstanceStatus, status models.InstanceStatus, reason string, task *models.PendingTask) services.InstanceUpdateResult {
+//		               panic("mock out the UpdateStatusWithTask method")
+//	            },
+//	        }
+//
+//	        // use mockedInstanceService in code that requires InstanceService
+//	        // and then make assertions.
+//
+//	    }
 type InstanceServiceMock struct {
 	// CreateFunc mocks the Create method.
-	CreateFunc func(instanceForm *models.InstanceForm) services.InstanceCreationResult
+	CreateFunc func(instanceForm *models.InstanceForm) (*models.Operation, services.InstanceCreationResult)
+
+	// DelInstanceVarsFunc mocks the DelInstanceVars method.
+	DelInstanceVarsFunc func(name string) error
 
 	// DeleteFunc mocks the Delete method.
-	DeleteFunc func(name string) services.InstanceDeletionResult
+	DeleteFunc func(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult)
+
+	// FinishPendingFunc mocks the FinishPending method.
+	FinishPendingFunc func(name string, status models.InstanceStatus, reason string, rollback *models.InstanceRollback, vars map[string]string) services.InstanceUpdateResult
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func() ([]*models.Instance, services.InstanceRetrievalResult)
 
 	// GetByNameFunc mocks the GetByName method.
 	GetByNameFunc func(name string) (*models.Instance, services.InstanceRetrievalResult)
 
+	// GetInfoFunc mocks the GetInfo method.
+	GetInfoFunc func(name string) ([]*models.InstanceInfoItem, services.InstanceRetrievalResult)
+
+	// GetInstanceVarsFunc mocks the GetInstanceVars method.
+	GetInstanceVarsFunc func(name string) (map[string]string, error)
+
 	// GetStatusByNameFunc mocks the GetStatusByName method.
 	GetStatusByNameFunc func(name string) services.InstanceStatusResult
 
+	// GetStatusHistoryFunc mocks the GetStatusHistory method.
+	GetStatusHistoryFunc func(name string) ([]*models.InstanceStatusTransition, services.InstanceRetrievalResult)
+
+	// IsBusyFunc mocks the IsBusy method.
+	IsBusyFunc func(name string) (bool, error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, services.InstanceRetrievalResult)
+
+	// RecreateFunc mocks the Recreate method.
+	RecreateFunc func(instance *models.Instance, reason string) (*models.Operation, services.InstanceUpdateResult)
+
+	// RemoveFunc mocks the Remove method.
+	RemoveFunc func(name string) services.InstanceDeletionResult
+
+	// RevertChangeFunc mocks the RevertChange method.
+	RevertChangeFunc func(name string, previous *models.InstancePrevious, reason string, vars map[string]string) services.InstanceUpdateResult
+
+	// SetInstanceVarsFunc mocks the SetInstanceVars method.
+	SetInstanceVarsFunc func(name string, envVars map[string]string) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult)
+
+	// UpdateResourcesFunc mocks the UpdateResources method.
+	UpdateResourcesFunc func(name string, resources map[string]string) services.InstanceUpdateResult
+
+	// UpdateRollbackFunc mocks the UpdateRollback method.
+	UpdateRollbackFunc func(name string, rollback models.InstanceRollback) services.InstanceUpdateResult
+
+	// UpdateStatusFunc mocks the UpdateStatus method.
+	UpdateStatusFunc func(name string, status models.InstanceStatus, reason string) services.InstanceUpdateResult
+
+	// UpdateStatusFromFunc mocks the UpdateStatusFrom method.
+	UpdateStatusFromFunc func(name string, from models.InstanceStatus, status models.InstanceStatus, reason string) services.InstanceUpdateResult
+
+	// UpdateStatusWithTaskFunc mocks the UpdateStatusWithTask method.
+	UpdateStatusWithTaskFunc func(name string, from models.InstanceStatus, status models.InstanceStatus, reason string, task *models.PendingTask) services.InstanceUpdateResult
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// Create holds details about calls to the Create method.
@@ -64,26 +190,162 @@ type InstanceServiceMock struct {
 			// InstanceForm is the instanceForm argument value.
 			InstanceForm *models.InstanceForm
 		}
+		// DelInstanceVars holds details about calls to the DelInstanceVars method.
+		DelInstanceVars []struct {
+			// Name is the name argument value.
+			Name string
+		}
 		// Delete holds details about calls to the Delete method.
 		Delete []struct {
 			// Name is the name argument value.
 			Name string
+			// Force is the force argument value.
+			Force bool
+		}
+		// FinishPending holds details about calls to the FinishPending method.
+		FinishPending []struct {
+			// Name is the name argument value.
+			Name string
+			// Status is the status argument value.
+			Status models.InstanceStatus
+			// Reason is the reason argument value.
+			Reason string
+			// Rollback is the rollback argument value.
+			Rollback *models.InstanceRollback
+			// Vars is the vars argument value.
+			Vars map[string]string
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
 		}
 		// GetByName holds details about calls to the GetByName method.
 		GetByName []struct {
 			// Name is the name argument value.
 			Name string
 		}
+		// GetInfo holds details about calls to the GetInfo method.
+		GetInfo []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// GetInstanceVars holds details about calls to the GetInstanceVars method.
+		GetInstanceVars []struct {
+			// Name is the name argument value.
+			Name string
+		}
 		// GetStatusByName holds details about calls to the GetStatusByName method.
 		GetStatusByName []struct {
 			// Name is the name argument value.
 			Name string
 		}
+		// GetStatusHistory holds details about calls to the GetStatusHistory method.
+		GetStatusHistory []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// IsBusy holds details about calls to the IsBusy method.
+		IsBusy []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// List holds details about calls to the List method.
+		List []struct {
+			// Filter is the filter argument value.
+			Filter *models.InstanceFilter
+			// Cursor is the cursor argument value.
+			Cursor string
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// Recreate holds details about calls to the Recreate method.
+		Recreate []struct {
+			// Instance is the instance argument value.
+			Instance *models.Instance
+			// Reason is the reason argument value.
+			Reason string
+		}
+		// Remove holds details about calls to the Remove method.
+		Remove []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// RevertChange holds details about calls to the RevertChange method.
+		RevertChange []struct {
+			// Name is the name argument value.
+			Name string
+			// Previous is the previous argument value.
+			Previous *models.InstancePrevious
+			// Reason is the reason argument value.
+			Reason string
+			// Vars is the vars argument value.
+			Vars map[string]string
+		}
+		// SetInstanceVars holds details about calls to the SetInstanceVars method.
+		SetInstanceVars []struct {
+			// Name is the name argument value.
+			Name string
+			// EnvVars is the envVars argument value.
+			EnvVars map[string]string
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Name is the name argument value.
+			Name string
+			// InstanceUpdateForm is the instanceUpdateForm argument value.
+			InstanceUpdateForm *models.InstanceUpdateForm
+		}
+		// UpdateResources holds details about calls to the UpdateResources method.
+		UpdateResources []struct {
+			// Name is the name argument value.
+			Name string
+			// Resources is the resources argument value.
+			Resources map[string]string
+		}
+		// UpdateRollback holds details about calls to the UpdateRollback method.
+		UpdateRollback []struct {
+			// Name is the name argument value.
+			Name string
+			// Rollback is the rollback argument value.
+			Rollback models.InstanceRollback
+		}
+		// UpdateStatus holds details about calls to the UpdateStatus method.
+		UpdateStatus []struct {
+			// Name is the name argument value.
+			Name string
+			// Status is the status argument value.
+			Status models.InstanceStatus
+			// Reason is the reason argument value.
+			Reason string
+		}
+		// UpdateStatusFrom holds details about calls to the UpdateStatusFrom method.
+		UpdateStatusFrom []struct {
+			// Name is the name argument value.
+			Name string
+			// From is the from argument value.
+			From models.InstanceStatus
+			// Status is the status argument value.
+			Status models.InstanceStatus
+			// Reason is the reason argument value.
+			Reason string
+		}
+		// UpdateStatusWithTask holds details about calls to the UpdateStatusWithTask method.
+		UpdateStatusWithTask []struct {
+			// Name is the name argument value.
+			Name string
+			// From is the from argument value.
+			From models.InstanceStatus
+			// Status is the status argument value.
+			Status models.InstanceStatus
+			// Reason is the reason argument value.
+			Reason string
+			// Task is the task argument value.
+			Task *models.PendingTask
+		}
 	}
 }
 
 // Create calls CreateFunc.
-func (mock *InstanceServiceMock) Create(instanceForm *models.InstanceForm) services.InstanceCreationResult {
+func (mock *InstanceServiceMock) Create(instanceForm *models.InstanceForm) (*models.Operation, services.InstanceCreationResult) {
 	if mock.CreateFunc == nil {
 		panic("InstanceServiceMock.CreateFunc: method is nil but InstanceService.Create was just called")
 	}
@@ -100,7 +362,8 @@ func (mock *InstanceServiceMock) Create(instanceForm *models.InstanceForm) servi
 
 // CreateCalls gets all the calls that were made to Create.
 // Check the length with:
-//     len(mockedInstanceService.CreateCalls())
+//
+//	len(mockedInstanceService.CreateCalls())
 func (mock *InstanceServiceMock) CreateCalls() []struct {
 	InstanceForm *models.InstanceForm
 } {
@@ -113,30 +376,67 @@ func (mock *InstanceServiceMock) CreateCalls() []struct {
 	return calls
 }
 
+// DelInstanceVars calls DelInstanceVarsFunc.
+func (mock *InstanceServiceMock) DelInstanceVars(name string) error {
+	if mock.DelInstanceVarsFunc == nil {
+		panic("InstanceServiceMock.DelInstanceVarsFunc: method is nil but InstanceService.DelInstanceVars was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockInstanceServiceMockDelInstanceVars.Lock()
+	mock.calls.DelInstanceVars = append(mock.calls.DelInstanceVars, callInfo)
+	lockInstanceServiceMockDelInstanceVars.Unlock()
+	return mock.DelInstanceVarsFunc(name)
+}
+
+// DelInstanceVarsCalls gets all the calls that were made to DelInstanceVars.
+// Check the length with:
+//
+//	len(mockedInstanceService.DelInstanceVarsCalls())
+func (mock *InstanceServiceMock) DelInstanceVarsCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockInstanceServiceMockDelInstanceVars.RLock()
+	calls = mock.calls.DelInstanceVars
+	lockInstanceServiceMockDelInstanceVars.RUnlock()
+	return calls
+}
+
 // Delete calls DeleteFunc.
-func (mock *InstanceServiceMock) Delete(name string) services.InstanceDeletionResult {
+func (mock *InstanceServiceMock) Delete(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult) {
 	if mock.DeleteFunc == nil {
 		panic("InstanceServiceMock.DeleteFunc: method is nil but InstanceService.Delete was just called")
 	}
 	callInfo := struct {
-		Name string
+		Name  string
+		Force bool
 	}{
-		Name: name,
+		Name:  name,
+		Force: force,
 	}
 	lockInstanceServiceMockDelete.Lock()
 	mock.calls.Delete = append(mock.calls.Delete, callInfo)
 	lockInstanceServiceMockDelete.Unlock()
-	return mock.DeleteFunc(name)
+	return mock.DeleteFunc(name, force)
 }
 
 // DeleteCalls gets all the calls that were made to Delete.
 // Check the length with:
-//     len(mockedInstanceService.DeleteCalls())
+//
+//	len(mockedInstanceService.DeleteCalls())
 func (mock *InstanceServiceMock) DeleteCalls() []struct {
-	Name string
+	Name  string
+	Force bool
 } {
 	var calls []struct {
-		Name string
+		Name  string
+		Force bool
 	}
 	lockInstanceServiceMockDelete.RLock()
 	calls = mock.calls.Delete
@@ -144,6 +444,81 @@ func (mock *InstanceServiceMock) DeleteCalls() []struct {
 	return calls
 }
 
+// FinishPending calls FinishPendingFunc.
+func (mock *InstanceServiceMock) FinishPending(name string, status models.InstanceStatus, reason string, rollback *models.InstanceRollback, vars map[string]string) services.InstanceUpdateResult {
+	if mock.FinishPendingFunc == nil {
+		panic("InstanceServiceMock.FinishPendingFunc: method is nil but InstanceService.FinishPending was just called")
+	}
+	callInfo := struct {
+		Name     string
+		Status   models.InstanceStatus
+		Reason   string
+		Rollback *models.InstanceRollback
+		Vars     map[string]string
+	}{
+		Name:     name,
+		Status:   status,
+		Reason:   reason,
+		Rollback: rollback,
+		Vars:     vars,
+	}
+	lockInstanceServiceMockFinishPending.Lock()
+	mock.calls.FinishPending = append(mock.calls.FinishPending, callInfo)
+	lockInstanceServiceMockFinishPending.Unlock()
+	return mock.FinishPendingFunc(name, status, reason, rollback, vars)
+}
+
+// FinishPendingCalls gets all the calls that were made to FinishPending.
+// Check the length with:
+//
+//	len(mockedInstanceService.FinishPendingCalls())
+func (mock *InstanceServiceMock) FinishPendingCalls() []struct {
+	Name     string
+	Status   models.InstanceStatus
+	Reason   string
+	Rollback *models.InstanceRollback
+	Vars     map[string]string
+} {
+	var calls []struct {
+		Name     string
+		Status   models.InstanceStatus
+		Reason   string
+		Rollback *models.InstanceRollback
+		Vars     map[string]string
+	}
+	lockInstanceServiceMockFinishPending.RLock()
+	calls = mock.calls.FinishPending
+	lockInstanceServiceMockFinishPending.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *InstanceServiceMock) GetAll() ([]*models.Instance, services.InstanceRetrievalResult) {
+	if mock.GetAllFunc == nil {
+		panic("InstanceServiceMock.GetAllFunc: method is nil but InstanceService.GetAll was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockInstanceServiceMockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	lockInstanceServiceMockGetAll.Unlock()
+	return mock.GetAllFunc()
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedInstanceService.GetAllCalls())
+func (mock *InstanceServiceMock) GetAllCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockInstanceServiceMockGetAll.RLock()
+	calls = mock.calls.GetAll
+	lockInstanceServiceMockGetAll.RUnlock()
+	return calls
+}
+
 // GetByName calls GetByNameFunc.
 func (mock *InstanceServiceMock) GetByName(name string) (*models.Instance, services.InstanceRetrievalResult) {
 	if mock.GetByNameFunc == nil {
@@ -162,7 +537,8 @@ func (mock *InstanceServiceMock) GetByName(name string) (*models.Instance, servi
 
 // GetByNameCalls gets all the calls that were made to GetByName.
 // Check the length with:
-//     len(mockedInstanceService.GetByNameCalls())
+//
+//	len(mockedInstanceService.GetByNameCalls())
 func (mock *InstanceServiceMock) GetByNameCalls() []struct {
 	Name string
 } {
@@ -175,6 +551,70 @@ func (mock *InstanceServiceMock) GetByNameCalls() []struct {
 	return calls
 }
 
+// GetInfo calls GetInfoFunc.
+func (mock *InstanceServiceMock) GetInfo(name string) ([]*models.InstanceInfoItem, services.InstanceRetrievalResult) {
+	if mock.GetInfoFunc == nil {
+		panic("InstanceServiceMock.GetInfoFunc: method is nil but InstanceService.GetInfo was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockInstanceServiceMockGetInfo.Lock()
+	mock.calls.GetInfo = append(mock.calls.GetInfo, callInfo)
+	lockInstanceServiceMockGetInfo.Unlock()
+	return mock.GetInfoFunc(name)
+}
+
+// GetInfoCalls gets all the calls that were made to GetInfo.
+// Check the length with:
+//
+//	len(mockedInstanceService.GetInfoCalls())
+func (mock *InstanceServiceMock) GetInfoCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockInstanceServiceMockGetInfo.RLock()
+	calls = mock.calls.GetInfo
+	lockInstanceServiceMockGetInfo.RUnlock()
+	return calls
+}
+
+// GetInstanceVars calls GetInstanceVarsFunc.
+func (mock *InstanceServiceMock) GetInstanceVars(name string) (map[string]string, error) {
+	if mock.GetInstanceVarsFunc == nil {
+		panic("InstanceServiceMock.GetInstanceVarsFunc: method is nil but InstanceService.GetInstanceVars was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockInstanceServiceMockGetInstanceVars.Lock()
+	mock.calls.GetInstanceVars = append(mock.calls.GetInstanceVars, callInfo)
+	lockInstanceServiceMockGetInstanceVars.Unlock()
+	return mock.GetInstanceVarsFunc(name)
+}
+
+// GetInstanceVarsCalls gets all the calls that were made to GetInstanceVars.
+// Check the length with:
+//
+//	len(mockedInstanceService.GetInstanceVarsCalls())
+func (mock *InstanceServiceMock) GetInstanceVarsCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockInstanceServiceMockGetInstanceVars.RLock()
+	calls = mock.calls.GetInstanceVars
+	lockInstanceServiceMockGetInstanceVars.RUnlock()
+	return calls
+}
+
 // GetStatusByName calls GetStatusByNameFunc.
 func (mock *InstanceServiceMock) GetStatusByName(name string) services.InstanceStatusResult {
 	if mock.GetStatusByNameFunc == nil {
@@ -193,7 +633,8 @@ func (mock *InstanceServiceMock) GetStatusByName(name string) services.InstanceS
 
 // GetStatusByNameCalls gets all the calls that were made to GetStatusByName.
 // Check the length with:
-//     len(mockedInstanceService.GetStatusByNameCalls())
+//
+//	len(mockedInstanceService.GetStatusByNameCalls())
 func (mock *InstanceServiceMock) GetStatusByNameCalls() []struct {
 	Name string
 } {
@@ -205,3 +646,495 @@ func (mock *InstanceServiceMock) GetStatusByNameCalls() []struct {
 	lockInstanceServiceMockGetStatusByName.RUnlock()
 	return calls
 }
+
+// GetStatusHistory calls GetStatusHistoryFunc.
+func (mock *InstanceServiceMock) GetStatusHistory(name string) ([]*models.InstanceStatusTransition, services.InstanceRetrievalResult) {
+	if mock.GetStatusHistoryFunc == nil {
+		panic("InstanceServiceMock.GetStatusHistoryFunc: method is nil but InstanceService.GetStatusHistory was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockInstanceServiceMockGetStatusHistory.Lock()
+	mock.calls.GetStatusHistory = append(mock.calls.GetStatusHistory, callInfo)
+	lockInstanceServiceMockGetStatusHistory.Unlock()
+	return mock.GetStatusHistoryFunc(name)
+}
+
+// GetStatusHistoryCalls gets all the calls that were made to GetStatusHistory.
+// Check the length with:
+//
+//	len(mockedInstanceService.GetStatusHistoryCalls())
+func (mock *InstanceServiceMock) GetStatusHistoryCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockInstanceServiceMockGetStatusHistory.RLock()
+	calls = mock.calls.GetStatusHistory
+	lockInstanceServiceMockGetStatusHistory.RUnlock()
+	return calls
+}
+
+// IsBusy calls IsBusyFunc.
+func (mock *InstanceServiceMock) IsBusy(name string) (bool, error) {
+	if mock.IsBusyFunc == nil {
+		panic("InstanceServiceMock.IsBusyFunc: method is nil but InstanceService.IsBusy was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockInstanceServiceMockIsBusy.Lock()
+	mock.calls.IsBusy = append(mock.calls.IsBusy, callInfo)
+	lockInstanceServiceMockIsBusy.Unlock()
+	return mock.IsBusyFunc(name)
+}
+
+// IsBusyCalls gets all the calls that were made to IsBusy.
+// Check the length with:
+//
+//	len(mockedInstanceService.IsBusyCalls())
+func (mock *InstanceServiceMock) IsBusyCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockInstanceServiceMockIsBusy.RLock()
+	calls = mock.calls.IsBusy
+	lockInstanceServiceMockIsBusy.RUnlock()
+	return calls
+}
+
+// List calls ListFunc.
+func (mock *InstanceServiceMock) List(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, services.InstanceRetrievalResult) {
+	if mock.ListFunc == nil {
+		panic("InstanceServiceMock.ListFunc: method is nil but InstanceService.List was just called")
+	}
+	callInfo := struct {
+		Filter *models.InstanceFilter
+		Cursor string
+		Limit  int
+	}{
+		Filter: filter,
+		Cursor: cursor,
+		Limit:  limit,
+	}
+	lockInstanceServiceMockList.Lock()
+	mock.calls.List = append(mock.calls.List, callInfo)
+	lockInstanceServiceMockList.Unlock()
+	return mock.ListFunc(filter, cursor, limit)
+}
+
+// ListCalls gets all the calls that were made to List.
+// Check the length with:
+//
+//	len(mockedInstanceService.ListCalls())
+func (mock *InstanceServiceMock) ListCalls() []struct {
+	Filter *models.InstanceFilter
+	Cursor string
+	Limit  int
+} {
+	var calls []struct {
+		Filter *models.InstanceFilter
+		Cursor string
+		Limit  int
+	}
+	lockInstanceServiceMockList.RLock()
+	calls = mock.calls.List
+	lockInstanceServiceMockList.RUnlock()
+	return calls
+}
+
+// Recreate calls RecreateFunc.
+func (mock *InstanceServiceMock) Recreate(instance *models.Instance, reason string) (*models.Operation, services.InstanceUpdateResult) {
+	if mock.RecreateFunc == nil {
+		panic("InstanceServiceMock.RecreateFunc: method is nil but InstanceService.Recreate was just called")
+	}
+	callInfo := struct {
+		Instance *models.Instance
+		Reason   string
+	}{
+		Instance: instance,
+		Reason:   reason,
+	}
+	lockInstanceServiceMockRecreate.Lock()
+	mock.calls.Recreate = append(mock.calls.Recreate, callInfo)
+	lockInstanceServiceMockRecreate.Unlock()
+	return mock.RecreateFunc(instance, reason)
+}
+
+// RecreateCalls gets all the calls that were made to Recreate.
+// Check the length with:
+//
+//	len(mockedInstanceService.RecreateCalls())
+func (mock *InstanceServiceMock) RecreateCalls() []struct {
+	Instance *models.Instance
+	Reason   string
+} {
+	var calls []struct {
+		Instance *models.Instance
+		Reason   string
+	}
+	lockInstanceServiceMockRecreate.RLock()
+	calls = mock.calls.Recreate
+	lockInstanceServiceMockRecreate.RUnlock()
+	return calls
+}
+
+// Remove calls RemoveFunc.
+func (mock *InstanceServiceMock) Remove(name string) services.InstanceDeletionResult {
+	if mock.RemoveFunc == nil {
+		panic("InstanceServiceMock.RemoveFunc: method is nil but InstanceService.Remove was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockInstanceServiceMockRemove.Lock()
+	mock.calls.Remove = append(mock.calls.Remove, callInfo)
+	lockInstanceServiceMockRemove.Unlock()
+	return mock.RemoveFunc(name)
+}
+
+// RemoveCalls gets all the calls that were made to Remove.
+// Check the length with:
+//
+//	len(mockedInstanceService.RemoveCalls())
+func (mock *InstanceServiceMock) RemoveCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockInstanceServiceMockRemove.RLock()
+	calls = mock.calls.Remove
+	lockInstanceServiceMockRemove.RUnlock()
+	return calls
+}
+
+// RevertChange calls RevertChangeFunc.
+func (mock *InstanceServiceMock) RevertChange(name string, previous *models.InstancePrevious, reason string, vars map[string]string) services.InstanceUpdateResult {
+	if mock.RevertChangeFunc == nil {
+		panic("InstanceServiceMock.RevertChangeFunc: method is nil but InstanceService.RevertChange was just called")
+	}
+	callInfo := struct {
+		Name     string
+		Previous *models.InstancePrevious
+		Reason   string
+		Vars     map[string]string
+	}{
+		Name:     name,
+		Previous: previous,
+		Reason:   reason,
+		Vars:     vars,
+	}
+	lockInstanceServiceMockRevertChange.Lock()
+	mock.calls.RevertChange = append(mock.calls.RevertChange, callInfo)
+	lockInstanceServiceMockRevertChange.Unlock()
+	return mock.RevertChangeFunc(name, previous, reason, vars)
+}
+
+// RevertChangeCalls gets all the calls that were made to RevertChange.
+// Check the length with:
+//
+//	len(mockedInstanceService.RevertChangeCalls())
+func (mock *InstanceServiceMock) RevertChangeCalls() []struct {
+	Name     string
+	Previous *models.InstancePrevious
+	Reason   string
+	Vars     map[string]string
+} {
+	var calls []struct {
+		Name     string
+		Previous *models.InstancePrevious
+		Reason   string
+		Vars     map[string]string
+	}
+	lockInstanceServiceMockRevertChange.RLock()
+	calls = mock.calls.RevertChange
+	lockInstanceServiceMockRevertChange.RUnlock()
+	return calls
+}
+
+// SetInstanceVars calls SetInstanceVarsFunc.
+func (mock *InstanceServiceMock) SetInstanceVars(name string, envVars map[string]string) error {
+	if mock.SetInstanceVarsFunc == nil {
+		panic("InstanceServiceMock.SetInstanceVarsFunc: method is nil but InstanceService.SetInstanceVars was just called")
+	}
+	callInfo := struct {
+		Name    string
+		EnvVars map[string]string
+	}{
+		Name:    name,
+		EnvVars: envVars,
+	}
+	lockInstanceServiceMockSetInstanceVars.Lock()
+	mock.calls.SetInstanceVars = append(mock.calls.SetInstanceVars, callInfo)
+	lockInstanceServiceMockSetInstanceVars.Unlock()
+	return mock.SetInstanceVarsFunc(name, envVars)
+}
+
+// SetInstanceVarsCalls gets all the calls that were made to SetInstanceVars.
+// Check the length with:
+//
+//	len(mockedInstanceService.SetInstanceVarsCalls())
+func (mock *InstanceServiceMock) SetInstanceVarsCalls() []struct {
+	Name    string
+	EnvVars map[string]string
+} {
+	var calls []struct {
+		Name    string
+		EnvVars map[string]string
+	}
+	lockInstanceServiceMockSetInstanceVars.RLock()
+	calls = mock.calls.SetInstanceVars
+	lockInstanceServiceMockSetInstanceVars.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *InstanceServiceMock) Update(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult) {
+	if mock.UpdateFunc == nil {
+		panic("InstanceServiceMock.UpdateFunc: method is nil but InstanceService.Update was just called")
+	}
+	callInfo := struct {
+		Name               string
+		InstanceUpdateForm *models.InstanceUpdateForm
+	}{
+		Name:               name,
+		InstanceUpdateForm: instanceUpdateForm,
+	}
+	lockInstanceServiceMockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	lockInstanceServiceMockUpdate.Unlock()
+	return mock.UpdateFunc(name, instanceUpdateForm)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedInstanceService.UpdateCalls())
+func (mock *InstanceServiceMock) UpdateCalls() []struct {
+	Name               string
+	InstanceUpdateForm *models.InstanceUpdateForm
+} {
+	var calls []struct {
+		Name               string
+		InstanceUpdateForm *models.InstanceUpdateForm
+	}
+	lockInstanceServiceMockUpdate.RLock()
+	calls = mock.calls.Update
+	lockInstanceServiceMockUpdate.RUnlock()
+	return calls
+}
+
+// UpdateResources calls UpdateResourcesFunc.
+func (mock *InstanceServiceMock) UpdateResources(name string, resources map[string]string) services.InstanceUpdateResult {
+	if mock.UpdateResourcesFunc == nil {
+		panic("InstanceServiceMock.UpdateResourcesFunc: method is nil but InstanceService.UpdateResources was just called")
+	}
+	callInfo := struct {
+		Name      string
+		Resources map[string]string
+	}{
+		Name:      name,
+		Resources: resources,
+	}
+	lockInstanceServiceMockUpdateResources.Lock()
+	mock.calls.UpdateResources = append(mock.calls.UpdateResources, callInfo)
+	lockInstanceServiceMockUpdateResources.Unlock()
+	return mock.UpdateResourcesFunc(name, resources)
+}
+
+// UpdateResourcesCalls gets all the calls that were made to UpdateResources.
+// Check the length with:
+//
+//	len(mockedInstanceService.UpdateResourcesCalls())
+func (mock *InstanceServiceMock) UpdateResourcesCalls() []struct {
+	Name      string
+	Resources map[string]string
+} {
+	var calls []struct {
+		Name      string
+		Resources map[string]string
+	}
+	lockInstanceServiceMockUpdateResources.RLock()
+	calls = mock.calls.UpdateResources
+	lockInstanceServiceMockUpdateResources.RUnlock()
+	return calls
+}
+
+// UpdateRollback calls UpdateRollbackFunc.
+func (mock *InstanceServiceMock) UpdateRollback(name string, rollback models.InstanceRollback) services.InstanceUpdateResult {
+	if mock.UpdateRollbackFunc == nil {
+		panic("InstanceServiceMock.UpdateRollbackFunc: method is nil but InstanceService.UpdateRollback was just called")
+	}
+	callInfo := struct {
+		Name     string
+		Rollback models.InstanceRollback
+	}{
+		Name:     name,
+		Rollback: rollback,
+	}
+	lockInstanceServiceMockUpdateRollback.Lock()
+	mock.calls.UpdateRollback = append(mock.calls.UpdateRollback, callInfo)
+	lockInstanceServiceMockUpdateRollback.Unlock()
+	return mock.UpdateRollbackFunc(name, rollback)
+}
+
+// UpdateRollbackCalls gets all the calls that were made to UpdateRollback.
+// Check the length with:
+//
+//	len(mockedInstanceService.UpdateRollbackCalls())
+func (mock *InstanceServiceMock) UpdateRollbackCalls() []struct {
+	Name     string
+	Rollback models.InstanceRollback
+} {
+	var calls []struct {
+		Name     string
+		Rollback models.InstanceRollback
+	}
+	lockInstanceServiceMockUpdateRollback.RLock()
+	calls = mock.calls.UpdateRollback
+	lockInstanceServiceMockUpdateRollback.RUnlock()
+	return calls
+}
+
+// UpdateStatus calls UpdateStatusFunc.
+func (mock *InstanceServiceMock) UpdateStatus(name string, status models.InstanceStatus, reason string) services.InstanceUpdateResult {
+	if mock.UpdateStatusFunc == nil {
+		panic("InstanceServiceMock.UpdateStatusFunc: method is nil but InstanceService.UpdateStatus was just called")
+	}
+	callInfo := struct {
+		Name   string
+		Status models.InstanceStatus
+		Reason string
+	}{
+		Name:   name,
+		Status: status,
+		Reason: reason,
+	}
+	lockInstanceServiceMockUpdateStatus.Lock()
+	mock.calls.UpdateStatus = append(mock.calls.UpdateStatus, callInfo)
+	lockInstanceServiceMockUpdateStatus.Unlock()
+	return mock.UpdateStatusFunc(name, status, reason)
+}
+
+// UpdateStatusCalls gets all the calls that were made to UpdateStatus.
+// Check the length with:
+//
+//	len(mockedInstanceService.UpdateStatusCalls())
+func (mock *InstanceServiceMock) UpdateStatusCalls() []struct {
+	Name   string
+	Status models.InstanceStatus
+	Reason string
+} {
+	var calls []struct {
+		Name   string
+		Status models.InstanceStatus
+		Reason string
+	}
+	lockInstanceServiceMockUpdateStatus.RLock()
+	calls = mock.calls.UpdateStatus
+	lockInstanceServiceMockUpdateStatus.RUnlock()
+	return calls
+}
+
+// UpdateStatusFrom calls UpdateStatusFromFunc.
+func (mock *InstanceServiceMock) UpdateStatusFrom(name string, from models.InstanceStatus, status models.InstanceStatus, reason string) services.InstanceUpdateResult {
+	if mock.UpdateStatusFromFunc == nil {
+		panic("InstanceServiceMock.UpdateStatusFromFunc: method is nil but InstanceService.UpdateStatusFrom was just called")
+	}
+	callInfo := struct {
+		Name   string
+		From   models.InstanceStatus
+		Status models.InstanceStatus
+		Reason string
+	}{
+		Name:   name,
+		From:   from,
+		Status: status,
+		Reason: reason,
+	}
+	lockInstanceServiceMockUpdateStatusFrom.Lock()
+	mock.calls.UpdateStatusFrom = append(mock.calls.UpdateStatusFrom, callInfo)
+	lockInstanceServiceMockUpdateStatusFrom.Unlock()
+	return mock.UpdateStatusFromFunc(name, from, status, reason)
+}
+
+// UpdateStatusFromCalls gets all the calls that were made to UpdateStatusFrom.
+// Check the length with:
+//
+//	len(mockedInstanceService.UpdateStatusFromCalls())
+func (mock *InstanceServiceMock) UpdateStatusFromCalls() []struct {
+	Name   string
+	From   models.InstanceStatus
+	Status models.InstanceStatus
+	Reason string
+} {
+	var calls []struct {
+		Name   string
+		From   models.InstanceStatus
+		Status models.InstanceStatus
+		Reason string
+	}
+	lockInstanceServiceMockUpdateStatusFrom.RLock()
+	calls = mock.calls.UpdateStatusFrom
+	lockInstanceServiceMockUpdateStatusFrom.RUnlock()
+	return calls
+}
+
+// UpdateStatusWithTask calls UpdateStatusWithTaskFunc.
+func (mock *InstanceServiceMock) UpdateStatusWithTask(name string, from models.InstanceStatus, status models.InstanceStatus, reason string, task *models.PendingTask) services.InstanceUpdateResult {
+	if mock.UpdateStatusWithTaskFunc == nil {
+		panic("InstanceServiceMock.UpdateStatusWithTaskFunc: method is nil but InstanceService.UpdateStatusWithTask was just called")
+	}
+	callInfo := struct {
+		Name   string
+		From   models.InstanceStatus
+		Status models.InstanceStatus
+		Reason string
+		Task   *models.PendingTask
+	}{
+		Name:   name,
+		From:   from,
+		Status: status,
+		Reason: reason,
+		Task:   task,
+	}
+	lockInstanceServiceMockUpdateStatusWithTask.Lock()
+	mock.calls.UpdateStatusWithTask = append(mock.calls.UpdateStatusWithTask, callInfo)
+	lockInstanceServiceMockUpdateStatusWithTask.Unlock()
+	return mock.UpdateStatusWithTaskFunc(name, from, status, reason, task)
+}
+
+// UpdateStatusWithTaskCalls gets all the calls that were made to UpdateStatusWithTask.
+// Check the length with:
+//
+//	len(mockedInstanceService.UpdateStatusWithTaskCalls())
+func (mock *InstanceServiceMock) UpdateStatusWithTaskCalls() []struct {
+	Name   string
+	From   models.InstanceStatus
+	Status models.InstanceStatus
+	Reason string
+	Task   *models.PendingTask
+} {
+	var calls []struct {
+		Name   string
+		From   models.InstanceStatus
+		Status models.InstanceStatus
+		Reason string
+		Task   *models.PendingTask
+	}
+	lockInstanceServiceMockUpdateStatusWithTask.RLock()
+	calls = mock.calls.UpdateStatusWithTask
+	lockInstanceServiceMockUpdateStatusWithTask.RUnlock()
+	return calls
+}
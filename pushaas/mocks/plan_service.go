@@ -10,7 +10,8 @@ import (
 )
 
 var (
-	lockPlanServiceMockGetAll sync.RWMutex
+	lockPlanServiceMockGetAll    sync.RWMutex
+	lockPlanServiceMockGetByName sync.RWMutex
 )
 
 // Ensure, that PlanServiceMock does implement PlanService.
@@ -19,28 +20,39 @@ var _ services.PlanService = &PlanServiceMock{}
 
 // PlanServiceMock is a mock implementation of PlanService.
 //
-//     func TestSomethingThatUsesPlanService(t *testing.T) {
+//	    func TestSomethingThatUsesPlanService(t *testing.T) {
 //
-//         // make and configure a mocked PlanService
-//         mockedPlanService := &PlanServiceMock{
-//             GetAllFunc: func() []models.Plan {
-// 	               panic("mock out the GetAll method")
-//             },
-//         }
+//	        // make and configure a mocked PlanService
+//	        mockedPlanService := &PlanServiceMock{
+//	            GetAllFunc: func() []models.Plan {
+//		               panic("mock out the GetAll method")
+//	            },
+//	            GetByNameFunc: func(name string) (*models.Plan, services.PlanRetrievalResult) {
+//		               panic("mock out the GetByName method")
+//	            },
+//	        }
 //
-//         // use mockedPlanService in code that requires PlanService
-//         // and then make assertions.
+//	        // use mockedPlanService in code that requires PlanService
+//	        // and then make assertions.
 //
-//     }
+//	    }
 type PlanServiceMock struct {
 	// GetAllFunc mocks the GetAll method.
 	GetAllFunc func() []models.Plan
 
+	// GetByNameFunc mocks the GetByName method.
+	GetByNameFunc func(name string) (*models.Plan, services.PlanRetrievalResult)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// GetAll holds details about calls to the GetAll method.
 		GetAll []struct {
 		}
+		// GetByName holds details about calls to the GetByName method.
+		GetByName []struct {
+			// Name is the name argument value.
+			Name string
+		}
 	}
 }
 
@@ -59,7 +71,8 @@ func (mock *PlanServiceMock) GetAll() []models.Plan {
 
 // GetAllCalls gets all the calls that were made to GetAll.
 // Check the length with:
-//     len(mockedPlanService.GetAllCalls())
+//
+//	len(mockedPlanService.GetAllCalls())
 func (mock *PlanServiceMock) GetAllCalls() []struct {
 } {
 	var calls []struct {
@@ -69,3 +82,35 @@ func (mock *PlanServiceMock) GetAllCalls() []struct {
 	lockPlanServiceMockGetAll.RUnlock()
 	return calls
 }
+
+// GetByName calls GetByNameFunc.
+func (mock *PlanServiceMock) GetByName(name string) (*models.Plan, services.PlanRetrievalResult) {
+	if mock.GetByNameFunc == nil {
+		panic("PlanServiceMock.GetByNameFunc: method is nil but PlanService.GetByName was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	lockPlanServiceMockGetByName.Lock()
+	mock.calls.GetByName = append(mock.calls.GetByName, callInfo)
+	lockPlanServiceMockGetByName.Unlock()
+	return mock.GetByNameFunc(name)
+}
+
+// GetByNameCalls gets all the calls that were made to GetByName.
+// Check the length with:
+//
+//	len(mockedPlanService.GetByNameCalls())
+func (mock *PlanServiceMock) GetByNameCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	lockPlanServiceMockGetByName.RLock()
+	calls = mock.calls.GetByName
+	lockPlanServiceMockGetByName.RUnlock()
+	return calls
+}
@@ -10,8 +10,12 @@ import (
 )
 
 var (
-	lockProvisionServiceMockDispatchDeprovision sync.RWMutex
-	lockProvisionServiceMockDispatchProvision   sync.RWMutex
+	lockProvisionServiceMockAbandonOperation         sync.RWMutex
+	lockProvisionServiceMockPrepareDeprovision       sync.RWMutex
+	lockProvisionServiceMockPrepareProvision         sync.RWMutex
+	lockProvisionServiceMockPrepareRecreate          sync.RWMutex
+	lockProvisionServiceMockPrepareRotateCredentials sync.RWMutex
+	lockProvisionServiceMockPrepareUpdate            sync.RWMutex
 )
 
 // Ensure, that ProvisionServiceMock does implement ProvisionService.
@@ -20,102 +24,282 @@ var _ services.ProvisionService = &ProvisionServiceMock{}
 
 // ProvisionServiceMock is a mock implementation of ProvisionService.
 //
-//     func TestSomethingThatUsesProvisionService(t *testing.T) {
+//	    func TestSomethingThatUsesProvisionService(t *testing.T) {
 //
-//         // make and configure a mocked ProvisionService
-//         mockedProvisionService := &ProvisionServiceMock{
-//             DispatchDeprovisionFunc: func(in1 *models.Instance) services.DispatchDeprovisionResult {
-// 	               panic("mock out the DispatchDeprovision method")
-//             },
-//             DispatchProvisionFunc: func(in1 *models.Instance) services.DispatchProvisionResult {
-// 	               panic("mock out the DispatchProvision method")
-//             },
-//         }
+//	        // make and configure a mocked ProvisionService
+//	        mockedProvisionService := &ProvisionServiceMock{
+//	            AbandonOperationFunc: func(operation *models.Operation, reason string)  {
+//		               panic("mock out the AbandonOperation method")
+//	            },
+//	            PrepareDeprovisionFunc: func(in1 *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchDeprovisionResult) {
+//		               panic("mock out the PrepareDeprovision method")
+//	            },
+//	            PrepareProvisionFunc: func(in1 *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchProvisionResult) {
+//		               panic("mock out the PrepareProvision method")
+//	            },
+//	            PrepareRecreateFunc: func(in1 *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchRecreateResult) {
+//		               panic("mock out the PrepareRecreate method")
+//	            },
+//	            PrepareRotateCredentialsFunc: func(in1 *models.InstanceChange) (*models.Operation, *models.PendingTask, services.DispatchRotateCredentialsResult) {
+//		               panic("mock out the PrepareRotateCredentials method")
+//	            },
+//	            PrepareUpdateFunc: func(in1 *models.InstanceChange) (*models.Operation, *models.PendingTask, services.DispatchUpdateResult) {
+//		               panic("mock out the PrepareUpdate method")
+//	            },
+//	        }
 //
-//         // use mockedProvisionService in code that requires ProvisionService
-//         // and then make assertions.
+//	        // use mockedProvisionService in code that requires ProvisionService
+//	        // and then make assertions.
 //
-//     }
+//	    }
 type ProvisionServiceMock struct {
-	// DispatchDeprovisionFunc mocks the DispatchDeprovision method.
-	DispatchDeprovisionFunc func(in1 *models.Instance) services.DispatchDeprovisionResult
+	// AbandonOperationFunc mocks the AbandonOperation method.
+	AbandonOperationFunc func(operation *models.Operation, reason string)
 
-	// DispatchProvisionFunc mocks the DispatchProvision method.
-	DispatchProvisionFunc func(in1 *models.Instance) services.DispatchProvisionResult
+	// PrepareDeprovisionFunc mocks the PrepareDeprovision method.
+	PrepareDeprovisionFunc func(in1 *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchDeprovisionResult)
+
+	// PrepareProvisionFunc mocks the PrepareProvision method.
+	PrepareProvisionFunc func(in1 *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchProvisionResult)
+
+	// PrepareRecreateFunc mocks the PrepareRecreate method.
+	PrepareRecreateFunc func(in1 *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchRecreateResult)
+
+	// PrepareRotateCredentialsFunc mocks the PrepareRotateCredentials method.
+	PrepareRotateCredentialsFunc func(in1 *models.InstanceChange) (*models.Operation, *models.PendingTask, services.DispatchRotateCredentialsResult)
+
+	// PrepareUpdateFunc mocks the PrepareUpdate method.
+	PrepareUpdateFunc func(in1 *models.InstanceChange) (*models.Operation, *models.PendingTask, services.DispatchUpdateResult)
 
 	// calls tracks calls to the methods.
 	calls struct {
-		// DispatchDeprovision holds details about calls to the DispatchDeprovision method.
-		DispatchDeprovision []struct {
+		// AbandonOperation holds details about calls to the AbandonOperation method.
+		AbandonOperation []struct {
+			// Operation is the operation argument value.
+			Operation *models.Operation
+			// Reason is the reason argument value.
+			Reason string
+		}
+		// PrepareDeprovision holds details about calls to the PrepareDeprovision method.
+		PrepareDeprovision []struct {
 			// In1 is the in1 argument value.
 			In1 *models.Instance
 		}
-		// DispatchProvision holds details about calls to the DispatchProvision method.
-		DispatchProvision []struct {
+		// PrepareProvision holds details about calls to the PrepareProvision method.
+		PrepareProvision []struct {
 			// In1 is the in1 argument value.
 			In1 *models.Instance
 		}
+		// PrepareRecreate holds details about calls to the PrepareRecreate method.
+		PrepareRecreate []struct {
+			// In1 is the in1 argument value.
+			In1 *models.Instance
+		}
+		// PrepareRotateCredentials holds details about calls to the PrepareRotateCredentials method.
+		PrepareRotateCredentials []struct {
+			// In1 is the in1 argument value.
+			In1 *models.InstanceChange
+		}
+		// PrepareUpdate holds details about calls to the PrepareUpdate method.
+		PrepareUpdate []struct {
+			// In1 is the in1 argument value.
+			In1 *models.InstanceChange
+		}
+	}
+}
+
+// AbandonOperation calls AbandonOperationFunc.
+func (mock *ProvisionServiceMock) AbandonOperation(operation *models.Operation, reason string) {
+	if mock.AbandonOperationFunc == nil {
+		panic("ProvisionServiceMock.AbandonOperationFunc: method is nil but ProvisionService.AbandonOperation was just called")
+	}
+	callInfo := struct {
+		Operation *models.Operation
+		Reason    string
+	}{
+		Operation: operation,
+		Reason:    reason,
+	}
+	lockProvisionServiceMockAbandonOperation.Lock()
+	mock.calls.AbandonOperation = append(mock.calls.AbandonOperation, callInfo)
+	lockProvisionServiceMockAbandonOperation.Unlock()
+	mock.AbandonOperationFunc(operation, reason)
+}
+
+// AbandonOperationCalls gets all the calls that were made to AbandonOperation.
+// Check the length with:
+//
+//	len(mockedProvisionService.AbandonOperationCalls())
+func (mock *ProvisionServiceMock) AbandonOperationCalls() []struct {
+	Operation *models.Operation
+	Reason    string
+} {
+	var calls []struct {
+		Operation *models.Operation
+		Reason    string
+	}
+	lockProvisionServiceMockAbandonOperation.RLock()
+	calls = mock.calls.AbandonOperation
+	lockProvisionServiceMockAbandonOperation.RUnlock()
+	return calls
+}
+
+// PrepareDeprovision calls PrepareDeprovisionFunc.
+func (mock *ProvisionServiceMock) PrepareDeprovision(in1 *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchDeprovisionResult) {
+	if mock.PrepareDeprovisionFunc == nil {
+		panic("ProvisionServiceMock.PrepareDeprovisionFunc: method is nil but ProvisionService.PrepareDeprovision was just called")
+	}
+	callInfo := struct {
+		In1 *models.Instance
+	}{
+		In1: in1,
+	}
+	lockProvisionServiceMockPrepareDeprovision.Lock()
+	mock.calls.PrepareDeprovision = append(mock.calls.PrepareDeprovision, callInfo)
+	lockProvisionServiceMockPrepareDeprovision.Unlock()
+	return mock.PrepareDeprovisionFunc(in1)
+}
+
+// PrepareDeprovisionCalls gets all the calls that were made to PrepareDeprovision.
+// Check the length with:
+//
+//	len(mockedProvisionService.PrepareDeprovisionCalls())
+func (mock *ProvisionServiceMock) PrepareDeprovisionCalls() []struct {
+	In1 *models.Instance
+} {
+	var calls []struct {
+		In1 *models.Instance
 	}
+	lockProvisionServiceMockPrepareDeprovision.RLock()
+	calls = mock.calls.PrepareDeprovision
+	lockProvisionServiceMockPrepareDeprovision.RUnlock()
+	return calls
 }
 
-// DispatchDeprovision calls DispatchDeprovisionFunc.
-func (mock *ProvisionServiceMock) DispatchDeprovision(in1 *models.Instance) services.DispatchDeprovisionResult {
-	if mock.DispatchDeprovisionFunc == nil {
-		panic("ProvisionServiceMock.DispatchDeprovisionFunc: method is nil but ProvisionService.DispatchDeprovision was just called")
+// PrepareProvision calls PrepareProvisionFunc.
+func (mock *ProvisionServiceMock) PrepareProvision(in1 *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchProvisionResult) {
+	if mock.PrepareProvisionFunc == nil {
+		panic("ProvisionServiceMock.PrepareProvisionFunc: method is nil but ProvisionService.PrepareProvision was just called")
 	}
 	callInfo := struct {
 		In1 *models.Instance
 	}{
 		In1: in1,
 	}
-	lockProvisionServiceMockDispatchDeprovision.Lock()
-	mock.calls.DispatchDeprovision = append(mock.calls.DispatchDeprovision, callInfo)
-	lockProvisionServiceMockDispatchDeprovision.Unlock()
-	return mock.DispatchDeprovisionFunc(in1)
+	lockProvisionServiceMockPrepareProvision.Lock()
+	mock.calls.PrepareProvision = append(mock.calls.PrepareProvision, callInfo)
+	lockProvisionServiceMockPrepareProvision.Unlock()
+	return mock.PrepareProvisionFunc(in1)
 }
 
-// DispatchDeprovisionCalls gets all the calls that were made to DispatchDeprovision.
+// PrepareProvisionCalls gets all the calls that were made to PrepareProvision.
 // Check the length with:
-//     len(mockedProvisionService.DispatchDeprovisionCalls())
-func (mock *ProvisionServiceMock) DispatchDeprovisionCalls() []struct {
+//
+//	len(mockedProvisionService.PrepareProvisionCalls())
+func (mock *ProvisionServiceMock) PrepareProvisionCalls() []struct {
 	In1 *models.Instance
 } {
 	var calls []struct {
 		In1 *models.Instance
 	}
-	lockProvisionServiceMockDispatchDeprovision.RLock()
-	calls = mock.calls.DispatchDeprovision
-	lockProvisionServiceMockDispatchDeprovision.RUnlock()
+	lockProvisionServiceMockPrepareProvision.RLock()
+	calls = mock.calls.PrepareProvision
+	lockProvisionServiceMockPrepareProvision.RUnlock()
 	return calls
 }
 
-// DispatchProvision calls DispatchProvisionFunc.
-func (mock *ProvisionServiceMock) DispatchProvision(in1 *models.Instance) services.DispatchProvisionResult {
-	if mock.DispatchProvisionFunc == nil {
-		panic("ProvisionServiceMock.DispatchProvisionFunc: method is nil but ProvisionService.DispatchProvision was just called")
+// PrepareRecreate calls PrepareRecreateFunc.
+func (mock *ProvisionServiceMock) PrepareRecreate(in1 *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchRecreateResult) {
+	if mock.PrepareRecreateFunc == nil {
+		panic("ProvisionServiceMock.PrepareRecreateFunc: method is nil but ProvisionService.PrepareRecreate was just called")
 	}
 	callInfo := struct {
 		In1 *models.Instance
 	}{
 		In1: in1,
 	}
-	lockProvisionServiceMockDispatchProvision.Lock()
-	mock.calls.DispatchProvision = append(mock.calls.DispatchProvision, callInfo)
-	lockProvisionServiceMockDispatchProvision.Unlock()
-	return mock.DispatchProvisionFunc(in1)
+	lockProvisionServiceMockPrepareRecreate.Lock()
+	mock.calls.PrepareRecreate = append(mock.calls.PrepareRecreate, callInfo)
+	lockProvisionServiceMockPrepareRecreate.Unlock()
+	return mock.PrepareRecreateFunc(in1)
 }
 
-// DispatchProvisionCalls gets all the calls that were made to DispatchProvision.
+// PrepareRecreateCalls gets all the calls that were made to PrepareRecreate.
 // Check the length with:
-//     len(mockedProvisionService.DispatchProvisionCalls())
-func (mock *ProvisionServiceMock) DispatchProvisionCalls() []struct {
+//
+//	len(mockedProvisionService.PrepareRecreateCalls())
+func (mock *ProvisionServiceMock) PrepareRecreateCalls() []struct {
 	In1 *models.Instance
 } {
 	var calls []struct {
 		In1 *models.Instance
 	}
-	lockProvisionServiceMockDispatchProvision.RLock()
-	calls = mock.calls.DispatchProvision
-	lockProvisionServiceMockDispatchProvision.RUnlock()
+	lockProvisionServiceMockPrepareRecreate.RLock()
+	calls = mock.calls.PrepareRecreate
+	lockProvisionServiceMockPrepareRecreate.RUnlock()
+	return calls
+}
+
+// PrepareRotateCredentials calls PrepareRotateCredentialsFunc.
+func (mock *ProvisionServiceMock) PrepareRotateCredentials(in1 *models.InstanceChange) (*models.Operation, *models.PendingTask, services.DispatchRotateCredentialsResult) {
+	if mock.PrepareRotateCredentialsFunc == nil {
+		panic("ProvisionServiceMock.PrepareRotateCredentialsFunc: method is nil but ProvisionService.PrepareRotateCredentials was just called")
+	}
+	callInfo := struct {
+		In1 *models.InstanceChange
+	}{
+		In1: in1,
+	}
+	lockProvisionServiceMockPrepareRotateCredentials.Lock()
+	mock.calls.PrepareRotateCredentials = append(mock.calls.PrepareRotateCredentials, callInfo)
+	lockProvisionServiceMockPrepareRotateCredentials.Unlock()
+	return mock.PrepareRotateCredentialsFunc(in1)
+}
+
+// PrepareRotateCredentialsCalls gets all the calls that were made to PrepareRotateCredentials.
+// Check the length with:
+//
+//	len(mockedProvisionService.PrepareRotateCredentialsCalls())
+func (mock *ProvisionServiceMock) PrepareRotateCredentialsCalls() []struct {
+	In1 *models.InstanceChange
+} {
+	var calls []struct {
+		In1 *models.InstanceChange
+	}
+	lockProvisionServiceMockPrepareRotateCredentials.RLock()
+	calls = mock.calls.PrepareRotateCredentials
+	lockProvisionServiceMockPrepareRotateCredentials.RUnlock()
+	return calls
+}
+
+// PrepareUpdate calls PrepareUpdateFunc.
+func (mock *ProvisionServiceMock) PrepareUpdate(in1 *models.InstanceChange) (*models.Operation, *models.PendingTask, services.DispatchUpdateResult) {
+	if mock.PrepareUpdateFunc == nil {
+		panic("ProvisionServiceMock.PrepareUpdateFunc: method is nil but ProvisionService.PrepareUpdate was just called")
+	}
+	callInfo := struct {
+		In1 *models.InstanceChange
+	}{
+		In1: in1,
+	}
+	lockProvisionServiceMockPrepareUpdate.Lock()
+	mock.calls.PrepareUpdate = append(mock.calls.PrepareUpdate, callInfo)
+	lockProvisionServiceMockPrepareUpdate.Unlock()
+	return mock.PrepareUpdateFunc(in1)
+}
+
+// PrepareUpdateCalls gets all the calls that were made to PrepareUpdate.
+// Check the length with:
+//
+//	len(mockedProvisionService.PrepareUpdateCalls())
+func (mock *ProvisionServiceMock) PrepareUpdateCalls() []struct {
+	In1 *models.InstanceChange
+} {
+	var calls []struct {
+		In1 *models.InstanceChange
+	}
+	lockProvisionServiceMockPrepareUpdate.RLock()
+	calls = mock.calls.PrepareUpdate
+	lockProvisionServiceMockPrepareUpdate.RUnlock()
 	return calls
 }
@@ -0,0 +1,79 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"sync"
+)
+
+var (
+	lockPushServiceCredentialRotatorMockRotateCredentials sync.RWMutex
+)
+
+// Ensure, that PushServiceCredentialRotatorMock does implement PushServiceCredentialRotator.
+// If this is not the case, regenerate this file with moq.
+var _ provisioners.PushServiceCredentialRotator = &PushServiceCredentialRotatorMock{}
+
+// PushServiceCredentialRotatorMock is a mock implementation of PushServiceCredentialRotator.
+//
+//	    func TestSomethingThatUsesPushServiceCredentialRotator(t *testing.T) {
+//
+//	        // make and configure a mocked PushServiceCredentialRotator
+//	        mockedPushServiceCredentialRotator := &PushServiceCredentialRotatorMock{
+//	            RotateCredentialsFunc: func(in1 *models.Instance) *provisioners.PushServiceProvisionResult {
+//		               panic("mock out the RotateCredentials method")
+//	            },
+//	        }
+//
+//	        // use mockedPushServiceCredentialRotator in code that requires PushServiceCredentialRotator
+//	        // and then make assertions.
+//
+//	    }
+type PushServiceCredentialRotatorMock struct {
+	// RotateCredentialsFunc mocks the RotateCredentials method.
+	RotateCredentialsFunc func(in1 *models.Instance) *provisioners.PushServiceProvisionResult
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// RotateCredentials holds details about calls to the RotateCredentials method.
+		RotateCredentials []struct {
+			// In1 is the in1 argument value.
+			In1 *models.Instance
+		}
+	}
+}
+
+// RotateCredentials calls RotateCredentialsFunc.
+func (mock *PushServiceCredentialRotatorMock) RotateCredentials(in1 *models.Instance) *provisioners.PushServiceProvisionResult {
+	if mock.RotateCredentialsFunc == nil {
+		panic("PushServiceCredentialRotatorMock.RotateCredentialsFunc: method is nil but PushServiceCredentialRotator.RotateCredentials was just called")
+	}
+	callInfo := struct {
+		In1 *models.Instance
+	}{
+		In1: in1,
+	}
+	lockPushServiceCredentialRotatorMockRotateCredentials.Lock()
+	mock.calls.RotateCredentials = append(mock.calls.RotateCredentials, callInfo)
+	lockPushServiceCredentialRotatorMockRotateCredentials.Unlock()
+	return mock.RotateCredentialsFunc(in1)
+}
+
+// RotateCredentialsCalls gets all the calls that were made to RotateCredentials.
+// Check the length with:
+//
+//	len(mockedPushServiceCredentialRotator.RotateCredentialsCalls())
+func (mock *PushServiceCredentialRotatorMock) RotateCredentialsCalls() []struct {
+	In1 *models.Instance
+} {
+	var calls []struct {
+		In1 *models.Instance
+	}
+	lockPushServiceCredentialRotatorMockRotateCredentials.RLock()
+	calls = mock.calls.RotateCredentials
+	lockPushServiceCredentialRotatorMockRotateCredentials.RUnlock()
+	return calls
+}
@@ -0,0 +1,211 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"sync"
+)
+
+var (
+	lockPushServiceProvisionerMockDeprovision sync.RWMutex
+	lockPushServiceProvisionerMockInspect     sync.RWMutex
+	lockPushServiceProvisionerMockProvision   sync.RWMutex
+	lockPushServiceProvisionerMockUpdate      sync.RWMutex
+)
+
+// Ensure, that PushServiceProvisionerMock does implement PushServiceProvisioner.
+// If this is not the case, regenerate this file with moq.
+var _ provisioners.PushServiceProvisioner = &PushServiceProvisionerMock{}
+
+// PushServiceProvisionerMock is a mock implementation of PushServiceProvisioner.
+//
+//	    func TestSomethingThatUsesPushServiceProvisioner(t *testing.T) {
+//
+//	        // make and configure a mocked PushServiceProvisioner
+//	        mockedPushServiceProvisioner := &PushServiceProvisionerMock{
+//	            DeprovisionFunc: func(in1 *models.Instance) *provisioners.PushServiceDeprovisionResult {
+//		               panic("mock out the Deprovision method")
+//	            },
+//	            InspectFunc: func(in1 *models.Instance) *provisioners.PushServiceInspectResult {
+//		               panic("mock out the Inspect method")
+//	            },
+//	            ProvisionFunc: func(in1 *models.Instance) *provisioners.PushServiceProvisionResult {
+//		               panic("mock out the Provision method")
+//	            },
+//	            UpdateFunc: func(in1 *models.Instance) *provisioners.PushServiceProvisionResult {
+//		               panic("mock out the Update method")
+//	            },
+//	        }
+//
+//	        // use mockedPushServiceProvisioner in code that requires PushServiceProvisioner
+//	        // and then make assertions.
+//
+//	    }
+type PushServiceProvisionerMock struct {
+	// DeprovisionFunc mocks the Deprovision method.
+	DeprovisionFunc func(in1 *models.Instance) *provisioners.PushServiceDeprovisionResult
+
+	// InspectFunc mocks the Inspect method.
+	InspectFunc func(in1 *models.Instance) *provisioners.PushServiceInspectResult
+
+	// ProvisionFunc mocks the Provision method.
+	ProvisionFunc func(in1 *models.Instance) *provisioners.PushServiceProvisionResult
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(in1 *models.Instance) *provisioners.PushServiceProvisionResult
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Deprovision holds details about calls to the Deprovision method.
+		Deprovision []struct {
+			// In1 is the in1 argument value.
+			In1 *models.Instance
+		}
+		// Inspect holds details about calls to the Inspect method.
+		Inspect []struct {
+			// In1 is the in1 argument value.
+			In1 *models.Instance
+		}
+		// Provision holds details about calls to the Provision method.
+		Provision []struct {
+			// In1 is the in1 argument value.
+			In1 *models.Instance
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// In1 is the in1 argument value.
+			In1 *models.Instance
+		}
+	}
+}
+
+// Deprovision calls DeprovisionFunc.
+func (mock *PushServiceProvisionerMock) Deprovision(in1 *models.Instance) *provisioners.PushServiceDeprovisionResult {
+	if mock.DeprovisionFunc == nil {
+		panic("PushServiceProvisionerMock.DeprovisionFunc: method is nil but PushServiceProvisioner.Deprovision was just called")
+	}
+	callInfo := struct {
+		In1 *models.Instance
+	}{
+		In1: in1,
+	}
+	lockPushServiceProvisionerMockDeprovision.Lock()
+	mock.calls.Deprovision = append(mock.calls.Deprovision, callInfo)
+	lockPushServiceProvisionerMockDeprovision.Unlock()
+	return mock.DeprovisionFunc(in1)
+}
+
+// DeprovisionCalls gets all the calls that were made to Deprovision.
+// Check the length with:
+//
+//	len(mockedPushServiceProvisioner.DeprovisionCalls())
+func (mock *PushServiceProvisionerMock) DeprovisionCalls() []struct {
+	In1 *models.Instance
+} {
+	var calls []struct {
+		In1 *models.Instance
+	}
+	lockPushServiceProvisionerMockDeprovision.RLock()
+	calls = mock.calls.Deprovision
+	lockPushServiceProvisionerMockDeprovision.RUnlock()
+	return calls
+}
+
+// Inspect calls InspectFunc.
+func (mock *PushServiceProvisionerMock) Inspect(in1 *models.Instance) *provisioners.PushServiceInspectResult {
+	if mock.InspectFunc == nil {
+		panic("PushServiceProvisionerMock.InspectFunc: method is nil but PushServiceProvisioner.Inspect was just called")
+	}
+	callInfo := struct {
+		In1 *models.Instance
+	}{
+		In1: in1,
+	}
+	lockPushServiceProvisionerMockInspect.Lock()
+	mock.calls.Inspect = append(mock.calls.Inspect, callInfo)
+	lockPushServiceProvisionerMockInspect.Unlock()
+	return mock.InspectFunc(in1)
+}
+
+// InspectCalls gets all the calls that were made to Inspect.
+// Check the length with:
+//
+//	len(mockedPushServiceProvisioner.InspectCalls())
+func (mock *PushServiceProvisionerMock) InspectCalls() []struct {
+	In1 *models.Instance
+} {
+	var calls []struct {
+		In1 *models.Instance
+	}
+	lockPushServiceProvisionerMockInspect.RLock()
+	calls = mock.calls.Inspect
+	lockPushServiceProvisionerMockInspect.RUnlock()
+	return calls
+}
+
+// Provision calls ProvisionFunc.
+func (mock *PushServiceProvisionerMock) Provision(in1 *models.Instance) *provisioners.PushServiceProvisionResult {
+	if mock.ProvisionFunc == nil {
+		panic("PushServiceProvisionerMock.ProvisionFunc: method is nil but PushServiceProvisioner.Provision was just called")
+	}
+	callInfo := struct {
+		In1 *models.Instance
+	}{
+		In1: in1,
+	}
+	lockPushServiceProvisionerMockProvision.Lock()
+	mock.calls.Provision = append(mock.calls.Provision, callInfo)
+	lockPushServiceProvisionerMockProvision.Unlock()
+	return mock.ProvisionFunc(in1)
+}
+
+// ProvisionCalls gets all the calls that were made to Provision.
+// Check the length with:
+//
+//	len(mockedPushServiceProvisioner.ProvisionCalls())
+func (mock *PushServiceProvisionerMock) ProvisionCalls() []struct {
+	In1 *models.Instance
+} {
+	var calls []struct {
+		In1 *models.Instance
+	}
+	lockPushServiceProvisionerMockProvision.RLock()
+	calls = mock.calls.Provision
+	lockPushServiceProvisionerMockProvision.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *PushServiceProvisionerMock) Update(in1 *models.Instance) *provisioners.PushServiceProvisionResult {
+	if mock.UpdateFunc == nil {
+		panic("PushServiceProvisionerMock.UpdateFunc: method is nil but PushServiceProvisioner.Update was just called")
+	}
+	callInfo := struct {
+		In1 *models.Instance
+	}{
+		In1: in1,
+	}
+	lockPushServiceProvisionerMockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	lockPushServiceProvisionerMockUpdate.Unlock()
+	return mock.UpdateFunc(in1)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedPushServiceProvisioner.UpdateCalls())
+func (mock *PushServiceProvisionerMock) UpdateCalls() []struct {
+	In1 *models.Instance
+} {
+	var calls []struct {
+		In1 *models.Instance
+	}
+	lockPushServiceProvisionerMockUpdate.RLock()
+	calls = mock.calls.Update
+	lockPushServiceProvisionerMockUpdate.RUnlock()
+	return calls
+}
@@ -0,0 +1,71 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"github.com/pushaas/pushaas/pushaas/services"
+	"sync"
+)
+
+var (
+	lockTaskOutboxServiceMockRelay sync.RWMutex
+)
+
+// Ensure, that TaskOutboxServiceMock does implement TaskOutboxService.
+// If this is not the case, regenerate this file with moq.
+var _ services.TaskOutboxService = &TaskOutboxServiceMock{}
+
+// TaskOutboxServiceMock is a mock implementation of TaskOutboxService.
+//
+//	    func TestSomethingThatUsesTaskOutboxService(t *testing.T) {
+//
+//	        // make and configure a mocked TaskOutboxService
+//	        mockedTaskOutboxService := &TaskOutboxServiceMock{
+//	            RelayFunc: func() (int, services.TaskRelayResult) {
+//		               panic("mock out the Relay method")
+//	            },
+//	        }
+//
+//	        // use mockedTaskOutboxService in code that requires TaskOutboxService
+//	        // and then make assertions.
+//
+//	    }
+type TaskOutboxServiceMock struct {
+	// RelayFunc mocks the Relay method.
+	RelayFunc func() (int, services.TaskRelayResult)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Relay holds details about calls to the Relay method.
+		Relay []struct {
+		}
+	}
+}
+
+// Relay calls RelayFunc.
+func (mock *TaskOutboxServiceMock) Relay() (int, services.TaskRelayResult) {
+	if mock.RelayFunc == nil {
+		panic("TaskOutboxServiceMock.RelayFunc: method is nil but TaskOutboxService.Relay was just called")
+	}
+	callInfo := struct {
+	}{}
+	lockTaskOutboxServiceMockRelay.Lock()
+	mock.calls.Relay = append(mock.calls.Relay, callInfo)
+	lockTaskOutboxServiceMockRelay.Unlock()
+	return mock.RelayFunc()
+}
+
+// RelayCalls gets all the calls that were made to Relay.
+// Check the length with:
+//
+//	len(mockedTaskOutboxService.RelayCalls())
+func (mock *TaskOutboxServiceMock) RelayCalls() []struct {
+} {
+	var calls []struct {
+	}
+	lockTaskOutboxServiceMockRelay.RLock()
+	calls = mock.calls.Relay
+	lockTaskOutboxServiceMockRelay.RUnlock()
+	return calls
+}
@@ -0,0 +1,84 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"github.com/pushaas/pushaas/pushaas/services"
+	"sync"
+)
+
+var (
+	lockTsuruServiceMockSetAppEnvVars sync.RWMutex
+)
+
+// Ensure, that TsuruServiceMock does implement TsuruService.
+// If this is not the case, regenerate this file with moq.
+var _ services.TsuruService = &TsuruServiceMock{}
+
+// TsuruServiceMock is a mock implementation of TsuruService.
+//
+//	    func TestSomethingThatUsesTsuruService(t *testing.T) {
+//
+//	        // make and configure a mocked TsuruService
+//	        mockedTsuruService := &TsuruServiceMock{
+//	            SetAppEnvVarsFunc: func(appName string, envVars map[string]string) error {
+//		               panic("mock out the SetAppEnvVars method")
+//	            },
+//	        }
+//
+//	        // use mockedTsuruService in code that requires TsuruService
+//	        // and then make assertions.
+//
+//	    }
+type TsuruServiceMock struct {
+	// SetAppEnvVarsFunc mocks the SetAppEnvVars method.
+	SetAppEnvVarsFunc func(appName string, envVars map[string]string) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// SetAppEnvVars holds details about calls to the SetAppEnvVars method.
+		SetAppEnvVars []struct {
+			// AppName is the appName argument value.
+			AppName string
+			// EnvVars is the envVars argument value.
+			EnvVars map[string]string
+		}
+	}
+}
+
+// SetAppEnvVars calls SetAppEnvVarsFunc.
+func (mock *TsuruServiceMock) SetAppEnvVars(appName string, envVars map[string]string) error {
+	if mock.SetAppEnvVarsFunc == nil {
+		panic("TsuruServiceMock.SetAppEnvVarsFunc: method is nil but TsuruService.SetAppEnvVars was just called")
+	}
+	callInfo := struct {
+		AppName string
+		EnvVars map[string]string
+	}{
+		AppName: appName,
+		EnvVars: envVars,
+	}
+	lockTsuruServiceMockSetAppEnvVars.Lock()
+	mock.calls.SetAppEnvVars = append(mock.calls.SetAppEnvVars, callInfo)
+	lockTsuruServiceMockSetAppEnvVars.Unlock()
+	return mock.SetAppEnvVarsFunc(appName, envVars)
+}
+
+// SetAppEnvVarsCalls gets all the calls that were made to SetAppEnvVars.
+// Check the length with:
+//
+//	len(mockedTsuruService.SetAppEnvVarsCalls())
+func (mock *TsuruServiceMock) SetAppEnvVarsCalls() []struct {
+	AppName string
+	EnvVars map[string]string
+} {
+	var calls []struct {
+		AppName string
+		EnvVars map[string]string
+	}
+	lockTsuruServiceMockSetAppEnvVars.RLock()
+	calls = mock.calls.SetAppEnvVars
+	lockTsuruServiceMockSetAppEnvVars.RUnlock()
+	return calls
+}
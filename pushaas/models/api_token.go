@@ -0,0 +1,58 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+type (
+	ApiTokenFormValidation int
+
+	/*
+		A named credential of the api, used as the user of basic auth with its secret as the password.
+		Only the hash of the secret is kept, so the secret is only known when the token is created.
+		The holder gets the role of the token, scoped to its team when a member.
+	*/
+	ApiToken struct {
+		Name        string    `json:"name"`
+		Description string    `json:"description,omitempty"`
+		Role        Role      `json:"role"`
+		Team        string    `json:"team,omitempty"`
+		CreatedBy   string    `json:"createdBy"`
+		CreatedAt   time.Time `json:"createdAt"`
+	}
+
+	CreatedApiToken struct {
+		*ApiToken
+		Secret string `json:"secret"`
+	}
+
+	ApiTokenForm struct {
+		Name        string
+		Description string
+		Role        Role
+		Team        string
+	}
+)
+
+const (
+	ApiTokenFormValid ApiTokenFormValidation = iota
+	ApiTokenFormInvalid
+)
+
+// the name goes on basic auth, where a colon would end the user
+var apiTokenNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+/*
+	whether the name is taken, by another token or by the tsuru credential, is checked by the service
+*/
+func (f *ApiTokenForm) Validate() ApiTokenFormValidation {
+	if !apiTokenNamePattern.MatchString(f.Name) || !f.Role.IsValid() {
+		return ApiTokenFormInvalid
+	}
+	if f.Role == RoleMember && f.Team == "" {
+		return ApiTokenFormInvalid
+	}
+
+	return ApiTokenFormValid
+}
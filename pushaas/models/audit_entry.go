@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+const (
+	AuditActionCreateInstance    = AuditAction("create-instance")
+	AuditActionUpdateInstance    = AuditAction("update-instance")
+	AuditActionDeleteInstance    = AuditAction("delete-instance")
+	AuditActionBindApp           = AuditAction("bind-app")
+	AuditActionUnbindApp         = AuditAction("unbind-app")
+	AuditActionBindUnit          = AuditAction("bind-unit")
+	AuditActionUnbindUnit        = AuditAction("unbind-unit")
+	AuditActionRotateCredentials = AuditAction("rotate-credentials")
+)
+
+type (
+	AuditAction string
+
+	/*
+		A call to the api that changed (or tried to change) an instance, with who made it and how it went.
+		Team is the team of the instance at the time, so that the entries are still found by it after the
+		instance is gone. Status is the status of the response, and ErrorCode the code of the error on it, if any.
+	*/
+	AuditEntry struct {
+		Id        string      `json:"id"`
+		At        time.Time   `json:"at"`
+		RequestId string      `json:"requestId"`
+		Principal *Principal  `json:"principal"`
+		Action    AuditAction `json:"action"`
+		Instance  string      `json:"instance"`
+		Team      string      `json:"team,omitempty"`
+		App       string      `json:"app,omitempty"`
+		UnitHost  string      `json:"unitHost,omitempty"`
+		Status    int         `json:"status"`
+		ErrorCode int         `json:"errorCode,omitempty"`
+	}
+
+	/*
+		Narrows a listing of audit entries, the fields left empty do not narrow it. From and To are inclusive.
+	*/
+	AuditFilter struct {
+		Instance string
+		Team     string
+		From     *time.Time
+		To       *time.Time
+	}
+)
+
+func (f *AuditFilter) Matches(entry *AuditEntry) bool {
+	if f.Instance != "" && f.Instance != entry.Instance {
+		return false
+	}
+	if f.Team != "" && f.Team != entry.Team {
+		return false
+	}
+	if f.From != nil && entry.At.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && entry.At.After(*f.To) {
+		return false
+	}
+	return true
+}
@@ -2,8 +2,10 @@ package models
 
 type (
 	BindApp struct {
-		AppName    string   `json:"appName"`
-		AppHost    string   `json:"appHost"`
+		AppName  string `json:"appName"`
+		AppHost  string `json:"appHost"`
+		Username string `json:"username"` // credential of the app on push-api, revoked when the app is unbound
+		Password string `json:"-"`
 	}
 )
 
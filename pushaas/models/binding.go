@@ -0,0 +1,13 @@
+package models
+
+type (
+	/*
+		An app bound to an instance as shown to the api, without its credential, with the units of the app
+		currently bound.
+	*/
+	Binding struct {
+		AppName   string   `json:"appName"`
+		AppHost   string   `json:"appHost"`
+		UnitHosts []string `json:"unitHosts"`
+	}
+)
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type (
+	/*
+		A task that kept failing until it had no retries left. It keeps the payload as it was sent, along with the
+		operation it was part of, so that it can be sent once more, and the error of the last attempt.
+	*/
+	DeadLetter struct {
+		Id          string    `json:"id"`
+		TaskName    string    `json:"taskName"`
+		Payload     string    `json:"payload"`
+		OperationId string    `json:"operationId,omitempty"`
+		Error       string    `json:"error"`
+		Attempts    int       `json:"attempts"`
+		FailedAt    time.Time `json:"failedAt"`
+	}
+)
@@ -14,8 +14,9 @@ const (
 	/*
 		instance
 	*/
-	ErrorInstanceRetrievalFailed   = 10
-	ErrorInstanceRetrievalNotFound = 11
+	ErrorInstanceRetrievalFailed       = 10
+	ErrorInstanceRetrievalNotFound     = 11
+	ErrorInstanceRetrievalInvalidQuery = 12
 
 	ErrorInstanceCreateFailed                  = 20
 	ErrorInstanceCreateDispatchProvisionFailed = 21
@@ -25,10 +26,33 @@ const (
 	ErrorInstanceDeleteFailed                    = 30
 	ErrorInstanceDeleteDispatchDeprovisionFailed = 31
 	ErrorInstanceDeleteNotFound                  = 32
+	ErrorInstanceDeleteHasBindings               = 33
+	ErrorInstanceDeleteBusy                      = 34
 
 	ErrorInstanceStatusRetrievalFailed   = 40
 	ErrorInstanceStatusRetrievalNotFound = 41
 	ErrorInstanceStatusInstanceFailed    = 42
+	ErrorInstanceStatusInstanceDegraded  = 43
+
+	ErrorInstanceUpdateFailed               = 50
+	ErrorInstanceUpdateDispatchUpdateFailed = 51
+	ErrorInstanceUpdateNotFound             = 52
+	ErrorInstanceUpdateInvalidData          = 53
+	ErrorInstanceUpdateNotRunning           = 54
+	ErrorInstanceUpdateBusy                 = 55
+
+	ErrorInstanceRotateCredentialsFailed         = 60
+	ErrorInstanceRotateCredentialsDispatchFailed = 61
+	ErrorInstanceRotateCredentialsNotFound       = 62
+	ErrorInstanceRotateCredentialsNotRunning     = 63
+	ErrorInstanceRotateCredentialsNotSupported   = 64
+	ErrorInstanceRotateCredentialsBusy           = 65
+
+	ErrorInstanceEventsFailed   = 70
+	ErrorInstanceEventsNotFound = 71
+
+	ErrorOperationRetrievalFailed   = 80
+	ErrorOperationRetrievalNotFound = 81
 
 	/*
 		bind
@@ -50,4 +74,43 @@ const (
 	ErrorUnbindUnitAppNotBound = 130
 	ErrorUnbindUnitNotBound    = 131
 	ErrorUnbindUnitFailed      = 132
+
+	ErrorBindingRetrievalFailed   = 140
+	ErrorBindingRetrievalNotFound = 141
+
+	/*
+		gc
+	*/
+	ErrorGcFailed       = 200
+	ErrorGcNotSupported = 201
+
+	/*
+		auth
+	*/
+	ErrorAuthFailed    = 300
+	ErrorAuthForbidden = 301
+
+	ErrorTokenRetrievalFailed = 310
+
+	ErrorTokenCreateFailed        = 320
+	ErrorTokenCreateAlreadyExists = 321
+	ErrorTokenCreateInvalidData   = 322
+
+	ErrorTokenRevokeFailed   = 330
+	ErrorTokenRevokeNotFound = 331
+
+	/*
+		audit
+	*/
+	ErrorAuditRetrievalFailed       = 400
+	ErrorAuditRetrievalInvalidQuery = 401
+
+	/*
+		dead letters
+	*/
+	ErrorDeadLetterRetrievalFailed = 500
+
+	ErrorDeadLetterReplayFailed          = 510
+	ErrorDeadLetterReplayNotFound        = 511
+	ErrorDeadLetterReplayInstanceChanged = 512
 )
@@ -0,0 +1,17 @@
+package models
+
+type (
+	// a resource on the provider whose instance is not known anymore
+	GcOrphan struct {
+		Kind         string `json:"kind"`
+		Name         string `json:"name"`
+		InstanceName string `json:"instanceName"`
+		Deleted      bool   `json:"deleted"`
+		Error        string `json:"error,omitempty"`
+	}
+
+	GcReport struct {
+		DryRun  bool        `json:"dryRun"`
+		Orphans []*GcOrphan `json:"orphans"`
+	}
+)
@@ -1,24 +1,68 @@
 package models
 
-import (
-	"encoding/json"
+import "time"
+
+const (
+	InstanceStatusPending        = InstanceStatus("pending")
+	InstanceStatusRunning        = InstanceStatus("running")
+	InstanceStatusFailed         = InstanceStatus("failed")
+	InstanceStatusDegraded       = InstanceStatus("degraded")       // was running, but push-api has no running tasks
+	InstanceStatusDeprovisioning = InstanceStatus("deprovisioning") // deleted, the record is removed when the deprovision finishes
 )
 
+// outcome of undoing what was already created when a provision fails halfway
 const (
-	InstanceStatusPending = InstanceStatus("pending")
-	InstanceStatusRunning = InstanceStatus("running")
-	InstanceStatusFailed  = InstanceStatus("failed")
+	InstanceRollbackNone      = InstanceRollback("")
+	InstanceRollbackCompleted = InstanceRollback("completed")
+	InstanceRollbackFailed    = InstanceRollback("failed")
 )
 
 type (
-	InstanceStatus string
+	InstanceStatus   string
+	InstanceRollback string
 
 	Instance struct {
-		Name               string            `json:"name"`
-		Plan               string            `json:"plan"`
-		Team               string            `json:"team"`
-		User               string            `json:"user"`
-		Status             InstanceStatus    `json:"status"`
+		Name        string           `json:"name"`
+		Plan        string           `json:"plan"`
+		Team        string           `json:"team"`
+		User        string           `json:"user"`
+		Description string           `json:"description,omitempty"`
+		Status      InstanceStatus   `json:"status"`
+		Rollback    InstanceRollback `json:"rollback,omitempty"`
+
+		// recorded by the repositories, absent on the instances created before they were
+		CreatedAt     *time.Time `json:"createdAt,omitempty"`
+		UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
+		ProvisionedAt *time.Time `json:"provisionedAt,omitempty"`
+		// why the instance went to failed, empty while it is not failed
+		FailureReason string `json:"failureReason,omitempty"`
+		// identifiers of what was created for the instance on the provider, by kind (e.g. the ARN of an ECS service)
+		Resources map[string]string `json:"resources,omitempty"`
+	}
+
+	/*
+		An instance along with what it had before the change the workers are applying to it, to go back to when
+		the change fails. The instance is embedded, so a change is decoded from a bare instance as well.
+	*/
+	InstanceChange struct {
+		Instance
+		Previous *InstancePrevious `json:"previous,omitempty"`
+	}
+
+	// what an instance had before a change, the plan being empty when the change keeps it
+	InstancePrevious struct {
+		Plan   string         `json:"plan,omitempty"`
+		Status InstanceStatus `json:"status"`
+	}
+
+	/*
+		An entry of the history of the statuses of an instance, which is only appended to.
+	*/
+	InstanceStatusTransition struct {
+		From   InstanceStatus `json:"from"`
+		To     InstanceStatus `json:"to"`
+		Reason string         `json:"reason,omitempty"`
+		At     time.Time      `json:"at"`
 	}
 )
 
@@ -26,15 +70,26 @@ func (i InstanceStatus) MarshalBinary() ([]byte, error) {
 	return []byte(i), nil
 }
 
-func (i InstanceStatus) UnmarshalBinary(data []byte) error{
-	return json.Unmarshal(data, i)
+func (i *InstanceStatus) UnmarshalBinary(data []byte) error {
+	*i = InstanceStatus(data)
+	return nil
+}
+
+func (i InstanceRollback) MarshalBinary() ([]byte, error) {
+	return []byte(i), nil
+}
+
+func (i *InstanceRollback) UnmarshalBinary(data []byte) error {
+	*i = InstanceRollback(data)
+	return nil
 }
 
 func InstanceFromInstanceForm(instanceForm *InstanceForm) *Instance {
 	return &Instance{
-		Name: instanceForm.Name,
-		Plan: instanceForm.Plan,
-		Team: instanceForm.Team,
-		User: instanceForm.User,
+		Name:        instanceForm.Name,
+		Plan:        instanceForm.Plan,
+		Team:        instanceForm.Team,
+		User:        instanceForm.User,
+		Description: instanceForm.Description,
 	}
 }
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+const (
+	InstanceEventStepStarted   = InstanceEventType("step-started")   // the workers started to provision, update, deprovision or rotate
+	InstanceEventStepFinished  = InstanceEventType("step-finished")  // the provider is done with it, whether it failed or not
+	InstanceEventStatusChanged = InstanceEventType("status-changed") // the instance went to Status
+	InstanceEventAppBound      = InstanceEventType("app-bound")
+	InstanceEventAppUnbound    = InstanceEventType("app-unbound")
+)
+
+type (
+	InstanceEventType string
+
+	/*
+		Something that happened to an instance, published to whoever is following it at the time. Nothing is kept,
+		so the events published while nobody is following are lost.
+	*/
+	InstanceEvent struct {
+		Instance string            `json:"instance"`
+		Type     InstanceEventType `json:"type"`
+		Step     string            `json:"step,omitempty"`
+		Failed   bool              `json:"failed,omitempty"`
+		Status   InstanceStatus    `json:"status,omitempty"`
+		Reason   string            `json:"reason,omitempty"`
+		App      string            `json:"app,omitempty"`
+		At       time.Time         `json:"at"`
+	}
+)
@@ -4,10 +4,11 @@ type (
 	InstanceFormValidation int
 
 	InstanceForm struct {
-		Name string
-		Plan string
-		Team string
-		User string
+		Name        string
+		Plan        string
+		Team        string
+		User        string
+		Description string
 	}
 )
 
@@ -16,12 +17,11 @@ const (
 	InstanceFormInvalid
 )
 
+/*
+	whether the plan is part of the catalog is checked by the service
+*/
 func (i *InstanceForm) Validate() InstanceFormValidation {
-	if i.Plan != PlanSmall {
-		return InstanceFormInvalid
-	}
-
-	if i.Name == "" || i.Team == "" || i.User == "" {
+	if i.Name == "" || i.Plan == "" || i.Team == "" || i.User == "" {
 		return InstanceFormInvalid
 	}
 
@@ -0,0 +1,11 @@
+package models
+
+type (
+	/*
+		An item of what tsuru shows about an instance on service-instance-info.
+	*/
+	InstanceInfoItem struct {
+		Label string `json:"label"`
+		Value string `json:"value"`
+	}
+)
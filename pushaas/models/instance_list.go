@@ -0,0 +1,38 @@
+package models
+
+type (
+	/*
+		Narrows a listing of instances, the fields left empty do not narrow it.
+	*/
+	InstanceFilter struct {
+		Team   string
+		User   string
+		Plan   string
+		Status InstanceStatus
+	}
+
+	/*
+		A page of a listing of instances, in the order they were created. NextCursor is given back to retrieve
+		the next page, and is empty on the last one.
+	*/
+	InstancePage struct {
+		Instances  []*Instance `json:"instances"`
+		NextCursor string      `json:"nextCursor,omitempty"`
+	}
+)
+
+func (f *InstanceFilter) Matches(instance *Instance) bool {
+	if f.Team != "" && f.Team != instance.Team {
+		return false
+	}
+	if f.User != "" && f.User != instance.User {
+		return false
+	}
+	if f.Plan != "" && f.Plan != instance.Plan {
+		return false
+	}
+	if f.Status != "" && f.Status != instance.Status {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,10 @@
+package models
+
+type (
+	// the fields left empty are kept as they are
+	InstanceUpdateForm struct {
+		Plan        string
+		Team        string
+		Description string
+	}
+)
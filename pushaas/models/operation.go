@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	OperationTypeProvision         = OperationType("provision")
+	OperationTypeDeprovision       = OperationType("deprovision")
+	OperationTypeUpdate            = OperationType("update")
+	OperationTypeRotateCredentials = OperationType("rotate-credentials")
+	OperationTypeRecreate          = OperationType("recreate") // started by the workers, when components of an instance went missing
+)
+
+const (
+	OperationStatePending   = OperationState("pending")   // dispatched, waiting for the workers
+	OperationStateRunning   = OperationState("running")   // the workers are on it
+	OperationStateSucceeded = OperationState("succeeded") // done, and the instance shows it
+	OperationStateFailed    = OperationState("failed")    // done, Error tells why
+)
+
+type (
+	OperationType  string
+	OperationState string
+
+	// one attempt of a step of the workers, failed ones are followed by the retry, if any
+	OperationStep struct {
+		Name       string     `json:"name"`
+		Failed     bool       `json:"failed,omitempty"`
+		Reason     string     `json:"reason,omitempty"`
+		StartedAt  time.Time  `json:"startedAt"`
+		FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	}
+
+	/*
+		The work the api dispatched to the workers for an instance, which callers poll to know when and how it ended.
+		Team is the team of the instance at the time, so that the operation can still be checked after the instance
+		is gone.
+	*/
+	Operation struct {
+		Id         string           `json:"id"`
+		Type       OperationType    `json:"type"`
+		Instance   string           `json:"instance"`
+		Team       string           `json:"team,omitempty"`
+		State      OperationState   `json:"state"`
+		Steps      []*OperationStep `json:"steps"`
+		Error      string           `json:"error,omitempty"`
+		CreatedAt  time.Time        `json:"createdAt"`
+		UpdatedAt  time.Time        `json:"updatedAt"`
+		FinishedAt *time.Time       `json:"finishedAt,omitempty"`
+	}
+)
+
+func (o *Operation) IsFinished() bool {
+	return o.State == OperationStateSucceeded || o.State == OperationStateFailed
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type (
+	/*
+		A task written along with the change of the instance it is for, waiting to be sent to the workers. It keeps
+		what goes on the signature of the task, so that it is sent as if it had been sent right away.
+	*/
+	PendingTask struct {
+		Id          string    `json:"id"`
+		TaskName    string    `json:"taskName"`
+		Payload     string    `json:"payload"`
+		OperationId string    `json:"operationId,omitempty"`
+		CreatedAt   time.Time `json:"createdAt"`
+	}
+)
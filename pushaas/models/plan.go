@@ -1,10 +1,10 @@
 package models
 
-const (
-	PlanSmall = "small"
-)
-
 type Plan struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name           string `json:"name" mapstructure:"name"`
+	Description    string `json:"description" mapstructure:"description"`
+	Cpu            int64  `json:"cpu" mapstructure:"cpu"`                                  // cpu units of the push-stream task, push-api gets half
+	Memory         int64  `json:"memory" mapstructure:"memory"`                            // MiB of the push-stream task, push-api gets half
+	MaxChannels    int64  `json:"maxChannels,omitempty" mapstructure:"max_channels"`       // zero means unlimited
+	MaxSubscribers int64  `json:"maxSubscribers,omitempty" mapstructure:"max_subscribers"` // per channel, zero means unlimited
 }
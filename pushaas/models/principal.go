@@ -0,0 +1,47 @@
+package models
+
+type (
+	PrincipalKind string
+	Role          string
+
+	/*
+		Who is calling the api, as told by the credentials of the request, and what they are allowed to do.
+		Team is the team of the instances a member is allowed to see and manage, the other roles are not scoped.
+	*/
+	Principal struct {
+		Name string        `json:"name"`
+		Kind PrincipalKind `json:"kind"`
+		Role Role          `json:"role"`
+		Team string        `json:"team,omitempty"`
+	}
+)
+
+const (
+	PrincipalKindTsuru     PrincipalKind = "tsuru" // the credential from the config, which tsuru is set up with
+	PrincipalKindToken     PrincipalKind = "token"
+	PrincipalKindAnonymous PrincipalKind = "anonymous" // when the auth is disabled
+)
+
+const (
+	RoleAdmin    Role = "admin"     // sees and manages everything, including the api tokens
+	RoleMember   Role = "member"    // sees and manages the instances of their team
+	RoleReadOnly Role = "read-only" // sees every instance, changes none
+)
+
+var roles = map[Role]bool{
+	RoleAdmin:    true,
+	RoleMember:   true,
+	RoleReadOnly: true,
+}
+
+func (r Role) IsValid() bool {
+	return roles[r]
+}
+
+func (p *Principal) CanWrite() bool {
+	return p.Role != RoleReadOnly
+}
+
+func (p *Principal) CanAccessTeam(team string) bool {
+	return p.Role != RoleMember || p.Team == team
+}
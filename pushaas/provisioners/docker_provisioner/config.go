@@ -0,0 +1,56 @@
+package docker_provisioner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+type (
+	DockerProvisionerConfig struct {
+		docker          DockerAPI
+		imagePushApi    string
+		imagePushAgent  string
+		imagePushRedis  string
+		imagePushStream string
+		network         string
+		publicHost      string
+	}
+)
+
+func NewDockerProvisionerConfig(config *viper.Viper, dockerSvc DockerAPI) (*DockerProvisionerConfig, error) {
+	imagePushApi := config.GetString("provisioner.docker.image_push_api")
+	imagePushAgent := config.GetString("provisioner.docker.image_push_agent")
+	imagePushRedis := config.GetString("provisioner.docker.image_push_redis")
+	imagePushStream := config.GetString("provisioner.docker.image_push_stream")
+
+	// the containers of all instances are attached to this network, so they can address each other by name
+	networkKey := "provisioner.docker.network"
+	network := config.GetString(networkKey)
+
+	// host where the published push-stream port is reachable by the clients of push-api
+	publicHostKey := "provisioner.docker.public_host"
+	publicHost := config.GetString(publicHostKey)
+
+	requiredVars := map[string]string{
+		networkKey:    network,
+		publicHostKey: publicHost,
+	}
+
+	for k, v := range requiredVars {
+		if v == "" {
+			return nil, errors.New(fmt.Sprintf("dockerProvisioner config required and not set: %s", k))
+		}
+	}
+
+	return &DockerProvisionerConfig{
+		docker:          dockerSvc,
+		imagePushApi:    imagePushApi,
+		imagePushAgent:  imagePushAgent,
+		imagePushRedis:  imagePushRedis,
+		imagePushStream: imagePushStream,
+		network:         network,
+		publicHost:      publicHost,
+	}, nil
+}
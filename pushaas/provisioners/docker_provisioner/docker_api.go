@@ -0,0 +1,231 @@
+package docker_provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type (
+	DockerAPI interface {
+		ContainerCreate(name string, input *ContainerCreateInput) (*ContainerCreateOutput, error)
+		ContainerStart(id string) error
+		ContainerInspect(name string) (*ContainerInspectOutput, error)
+		ContainerStop(name string) error
+		ContainerRemove(name string) error
+		ImagePull(image string) error
+	}
+
+	ContainerCreateInput struct {
+		Image        string              `json:"Image"`
+		Env          []string            `json:"Env,omitempty"`
+		Labels       map[string]string   `json:"Labels,omitempty"`
+		ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+		HostConfig   *HostConfig         `json:"HostConfig,omitempty"`
+	}
+
+	HostConfig struct {
+		NetworkMode  string                   `json:"NetworkMode,omitempty"`
+		PortBindings map[string][]PortBinding `json:"PortBindings,omitempty"`
+	}
+
+	PortBinding struct {
+		HostIp   string `json:"HostIp"`
+		HostPort string `json:"HostPort"`
+	}
+
+	ContainerCreateOutput struct {
+		Id       string   `json:"Id"`
+		Warnings []string `json:"Warnings"`
+	}
+
+	ContainerState struct {
+		Status   string `json:"Status"`
+		Running  bool   `json:"Running"`
+		ExitCode int    `json:"ExitCode"`
+	}
+
+	ContainerConfig struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	}
+
+	ContainerNetwork struct {
+		IPAddress string `json:"IPAddress"`
+	}
+
+	ContainerNetworkSettings struct {
+		Ports    map[string][]PortBinding    `json:"Ports"`
+		Networks map[string]ContainerNetwork `json:"Networks"`
+	}
+
+	ContainerInspectOutput struct {
+		Id              string                    `json:"Id"`
+		Name            string                    `json:"Name"`
+		State           *ContainerState           `json:"State"`
+		Config          *ContainerConfig          `json:"Config"`
+		NetworkSettings *ContainerNetworkSettings `json:"NetworkSettings"`
+	}
+
+	DockerError struct {
+		StatusCode int
+		Message    string `json:"message"`
+	}
+
+	dockerClient struct {
+		baseUrl    string
+		httpClient *http.Client
+	}
+)
+
+func (e *DockerError) Error() string {
+	return fmt.Sprintf("docker api error (status %d): %s", e.StatusCode, e.Message)
+}
+
+func IsNotFound(err error) bool {
+	var dockerErr *DockerError
+	return errors.As(err, &dockerErr) && dockerErr.StatusCode == http.StatusNotFound
+}
+
+func (c *dockerClient) do(method string, path string, query url.Values, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(bodyBytes)
+	}
+
+	requestUrl := c.baseUrl + path
+	if len(query) > 0 {
+		requestUrl = fmt.Sprintf("%s?%s", requestUrl, query.Encode())
+	}
+
+	req, err := http.NewRequest(method, requestUrl, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// 304 is returned when the container is already in the requested state (e.g. already stopped)
+	if res.StatusCode == http.StatusNotModified {
+		return nil
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		dockerErr := &DockerError{StatusCode: res.StatusCode}
+		resBytes, _ := ioutil.ReadAll(res.Body)
+		if err := json.Unmarshal(resBytes, dockerErr); err != nil {
+			dockerErr.Message = strings.TrimSpace(string(resBytes))
+		}
+		return dockerErr
+	}
+
+	if out == nil {
+		_, err = io.Copy(ioutil.Discard, res.Body)
+		return err
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func (c *dockerClient) ContainerCreate(name string, input *ContainerCreateInput) (*ContainerCreateOutput, error) {
+	var output ContainerCreateOutput
+	err := c.do(http.MethodPost, "/containers/create", url.Values{"name": {name}}, input, &output)
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+func (c *dockerClient) ContainerStart(id string) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/containers/%s/start", id), nil, nil, nil)
+}
+
+func (c *dockerClient) ContainerInspect(name string) (*ContainerInspectOutput, error) {
+	var output ContainerInspectOutput
+	err := c.do(http.MethodGet, fmt.Sprintf("/containers/%s/json", name), nil, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+func (c *dockerClient) ContainerStop(name string) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/containers/%s/stop", name), nil, nil, nil)
+}
+
+func (c *dockerClient) ContainerRemove(name string) error {
+	query := url.Values{
+		"force": {"true"},
+		"v":     {"true"},
+	}
+	return c.do(http.MethodDelete, fmt.Sprintf("/containers/%s", name), query, nil, nil)
+}
+
+func (c *dockerClient) ImagePull(image string) error {
+	// the pull progress is streamed on the body, `do` only returns after it is fully consumed
+	return c.do(http.MethodPost, "/images/create", url.Values{"fromImage": {image}}, nil, nil)
+}
+
+/*
+	Accepts the same formats as the docker CLI `DOCKER_HOST`:
+	- unix:///var/run/docker.sock
+	- tcp://localhost:2375
+	- http://localhost:2375
+*/
+func NewDockerClient(dockerUrl string, apiVersion string) (DockerAPI, error) {
+	parsedUrl, err := url.Parse(dockerUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	versionPath := ""
+	if apiVersion != "" {
+		versionPath = fmt.Sprintf("/v%s", apiVersion)
+	}
+
+	httpClient := &http.Client{}
+
+	var baseUrl string
+	switch parsedUrl.Scheme {
+	case "unix":
+		socketPath := parsedUrl.Path
+		httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				dialer := net.Dialer{Timeout: 30 * time.Second}
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		}
+		// host is ignored when dialing the socket, but is required to build valid requests
+		baseUrl = "http://docker" + versionPath
+	case "tcp", "http":
+		baseUrl = fmt.Sprintf("http://%s%s", parsedUrl.Host, versionPath)
+	case "https":
+		baseUrl = fmt.Sprintf("https://%s%s", parsedUrl.Host, versionPath)
+	default:
+		return nil, fmt.Errorf("unsupported docker url scheme: %s", parsedUrl.Scheme)
+	}
+
+	return &dockerClient{
+		baseUrl:    baseUrl,
+		httpClient: httpClient,
+	}, nil
+}
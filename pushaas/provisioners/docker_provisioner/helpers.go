@@ -0,0 +1,125 @@
+package docker_provisioner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+const labelInstance = "pushaas.instance"
+const labelComponent = "pushaas.component"
+
+/*
+	===========================================================================
+	containers
+	===========================================================================
+*/
+func containerLabels(instance *models.Instance, component string) map[string]string {
+	return map[string]string{
+		labelInstance:  instance.Name,
+		labelComponent: component,
+	}
+}
+
+func createContainer(name string, input *ContainerCreateInput, provisionerConfig *DockerProvisionerConfig) (*ContainerCreateOutput, error) {
+	output, err := provisionerConfig.docker.ContainerCreate(name, input)
+	if err == nil || !IsNotFound(err) {
+		return output, err
+	}
+
+	// image is not present locally, pull it and try again
+	err = provisionerConfig.docker.ImagePull(input.Image)
+	if err != nil {
+		return nil, err
+	}
+	return provisionerConfig.docker.ContainerCreate(name, input)
+}
+
+func createAndStartContainer(name string, input *ContainerCreateInput, provisionerConfig *DockerProvisionerConfig) (*ContainerCreateOutput, error) {
+	output, err := createContainer(name, input, provisionerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	err = provisionerConfig.docker.ContainerStart(output.Id)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func removeContainer(name string, provisionerConfig *DockerProvisionerConfig) (*ContainerInspectOutput, error) {
+	container, err := provisionerConfig.docker.ContainerInspect(name)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, errors.New(fmt.Sprintf("could not find container %s", name))
+		}
+		return nil, err
+	}
+
+	err = provisionerConfig.docker.ContainerStop(container.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = provisionerConfig.docker.ContainerRemove(container.Id)
+	if err != nil {
+		return nil, err
+	}
+	return container, nil
+}
+
+func publishedPort(container *ContainerInspectOutput, containerPort string) (string, error) {
+	if container.NetworkSettings == nil {
+		return "", errors.New(fmt.Sprintf("no network settings for container %s", container.Name))
+	}
+
+	bindings := container.NetworkSettings.Ports[containerPort]
+	if len(bindings) == 0 || bindings[0].HostPort == "" {
+		return "", errors.New(fmt.Sprintf("port %s is not published for container %s", containerPort, container.Name))
+	}
+	return bindings[0].HostPort, nil
+}
+
+/*
+	===========================================================================
+	other
+	===========================================================================
+*/
+const attempts = 30
+const interval = 2 * time.Second
+
+func waitTrue(ch chan bool, evaluationFn func(attempt int) bool) {
+	for i := 0; i < attempts; i++ {
+		isLastAttempt := i+1 == attempts
+		isSuccess := evaluationFn(i)
+
+		if !isSuccess {
+			if isLastAttempt {
+				ch <- false
+				return
+			}
+			time.Sleep(interval)
+			continue
+		}
+		ch <- true
+		return
+	}
+}
+
+func waitContainerUp(logger *zap.Logger, instance *models.Instance, ch chan bool, inspectContainerFunc func(*models.Instance) (*ContainerInspectOutput, error)) {
+	waitTrue(ch, func(attempt int) bool {
+		container, err := inspectContainerFunc(instance)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[waitContainerUp] failed on attempt %d", attempt), zap.Error(err))
+			return false
+		}
+		isContainerUp := container.State != nil && container.State.Running
+		logger.Debug(fmt.Sprintf("[waitContainerUp] attempt %d with result isContainerUp=%t", attempt, isContainerUp))
+		return isContainerUp
+	})
+}
@@ -0,0 +1,219 @@
+package docker_provisioner
+
+import (
+	"fmt"
+
+	"github.com/dchest/uniuri"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+)
+
+type (
+	dockerProvisioner struct {
+		logger                *zap.Logger
+		provisionerConfig     *DockerProvisionerConfig
+		pushRedisProvisioner  DockerPushRedisProvisioner
+		pushStreamProvisioner DockerPushStreamProvisioner
+		pushApiProvisioner    DockerPushApiProvisioner
+	}
+)
+
+/*
+	Runs every instance as a set of containers on a single Docker daemon, intended for local development and CI.
+
+	The containers are attached to a user defined network, so they address each other by container name, and the
+	apps bound to the instance must be attached to the same network to reach push-api.
+*/
+
+func (p *dockerProvisioner) Provision(instance *models.Instance) *provisioners.PushServiceProvisionResult {
+	p.logger.Info("starting provision for instance", zap.Any("instance", instance))
+
+	failureResult := &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceProvisionStatusFailure,
+		EnvVars:  map[string]string{},
+	}
+
+	/*
+		push-redis
+	*/
+	chRedis := make(chan provisionPushRedisResult)
+	go p.pushRedisProvisioner.Provision(instance, chRedis)
+	resultPushRedis := <-chRedis
+	if resultPushRedis.err != nil {
+		p.logger.Error("push-redis: provision failure", zap.Any("instance", instance), zap.Error(resultPushRedis.err))
+		return failureResult
+	}
+	p.logger.Info("push-redis: provision success", zap.Any("instance", instance))
+
+	/*
+		push-stream
+	*/
+	chStream := make(chan provisionPushStreamResult)
+	go p.pushStreamProvisioner.Provision(instance, chStream)
+	resultPushStream := <-chStream
+	if resultPushStream.err != nil {
+		p.logger.Error("push-stream: provision failure", zap.Any("instance", instance), zap.Error(resultPushStream.err))
+		return failureResult
+	}
+	p.logger.Info("push-stream: provision success", zap.Any("instance", instance))
+
+	/*
+		push-api
+	*/
+	chApi := make(chan provisionPushApiResult)
+	pushStreamPublicUrl := fmt.Sprintf("http://%s:%s", p.provisionerConfig.publicHost, resultPushStream.publicPort)
+	username := "app"
+	password := uniuri.New()
+	go p.pushApiProvisioner.Provision(instance, username, password, pushStreamPublicUrl, chApi)
+	resultPushApi := <-chApi
+	if resultPushApi.err != nil {
+		p.logger.Error("push-api: provision failure", zap.Any("instance", instance), zap.Error(resultPushApi.err))
+		return failureResult
+	}
+	p.logger.Info("push-api: provision success", zap.Any("instance", instance))
+
+	p.logger.Info(
+		"finishing provision for instance",
+		zap.Any("instance", instance),
+		zap.Any("resultPushRedis", resultPushRedis),
+		zap.Any("resultPushStream", resultPushStream),
+		zap.Any("resultPushApi", resultPushApi),
+	)
+
+	envVars := map[string]string{
+		provisioners.EnvVarEndpoint: fmt.Sprintf("http://%s:%s", pushApiWithInstance(instance.Name), pushApiPort),
+		provisioners.EnvVarPassword: password,
+		provisioners.EnvVarUsername: username,
+	}
+
+	return &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		EnvVars:  envVars,
+		Status:   provisioners.PushServiceProvisionStatusSuccess,
+		Resources: map[string]string{
+			provisioners.ResourcePushStreamUrl: pushStreamPublicUrl,
+		},
+	}
+}
+
+/*
+	the containers are not limited in size, so every plan runs the same and there is nothing to apply
+*/
+func (p *dockerProvisioner) Update(instance *models.Instance) *provisioners.PushServiceProvisionResult {
+	p.logger.Info("nothing to update for instance", zap.Any("instance", instance))
+	return &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		EnvVars:  map[string]string{},
+		Status:   provisioners.PushServiceProvisionStatusSuccess,
+	}
+}
+
+func (p *dockerProvisioner) Deprovision(instance *models.Instance) *provisioners.PushServiceDeprovisionResult {
+	failureResult := &provisioners.PushServiceDeprovisionResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceDeprovisionStatusFailure,
+	}
+
+	/*
+		push-api
+	*/
+	chApi := make(chan deprovisionPushApiResult)
+	go p.pushApiProvisioner.Deprovision(instance, chApi)
+	resultPushApi := <-chApi
+	if resultPushApi.err != nil {
+		p.logger.Error("push-api: deprovision failure", zap.Any("instance", instance), zap.Error(resultPushApi.err))
+		return failureResult
+	}
+	p.logger.Info("push-api: deprovision success", zap.Any("instance", instance))
+
+	/*
+		push-stream
+	*/
+	chStream := make(chan deprovisionPushStreamResult)
+	go p.pushStreamProvisioner.Deprovision(instance, chStream)
+	resultPushStream := <-chStream
+	if resultPushStream.err != nil {
+		p.logger.Error("push-stream: deprovision failure", zap.Any("instance", instance), zap.Error(resultPushStream.err))
+		return failureResult
+	}
+	p.logger.Info("push-stream: deprovision success", zap.Any("instance", instance))
+
+	/*
+		push-redis
+	*/
+	chRedis := make(chan deprovisionPushRedisResult)
+	go p.pushRedisProvisioner.Deprovision(instance, chRedis)
+	resultPushRedis := <-chRedis
+	if resultPushRedis.err != nil {
+		p.logger.Error("push-redis: deprovision failure", zap.Any("instance", instance), zap.Error(resultPushRedis.err))
+		return failureResult
+	}
+	p.logger.Info("push-redis: deprovision success", zap.Any("instance", instance))
+
+	p.logger.Info(
+		"finishing deprovision for instance",
+		zap.Any("instance", instance),
+		zap.Any("resultPushRedis", resultPushRedis),
+		zap.Any("resultPushStream", resultPushStream),
+		zap.Any("resultPushApi", resultPushApi),
+	)
+
+	return &provisioners.PushServiceDeprovisionResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceDeprovisionStatusSuccess,
+	}
+}
+
+func (p *dockerProvisioner) Inspect(instance *models.Instance) *provisioners.PushServiceInspectResult {
+	containerNames := map[string]string{
+		provisioners.ComponentPushRedis:  pushRedisWithInstance(instance.Name),
+		provisioners.ComponentPushStream: pushStreamWithInstance(instance.Name),
+		provisioners.ComponentPushApi:    pushApiWithInstance(instance.Name),
+	}
+
+	var components []*provisioners.PushServiceComponentState
+	for _, component := range []string{provisioners.ComponentPushRedis, provisioners.ComponentPushStream, provisioners.ComponentPushApi} {
+		state := &provisioners.PushServiceComponentState{Name: component}
+
+		container, err := p.provisionerConfig.docker.ContainerInspect(containerNames[component])
+		if err != nil && !IsNotFound(err) {
+			p.logger.Error("failed to inspect container to inspect instance", zap.String("component", component), zap.Any("instance", instance), zap.Error(err))
+			return &provisioners.PushServiceInspectResult{
+				Instance: instance,
+				Status:   provisioners.PushServiceInspectStatusFailure,
+			}
+		}
+		if err == nil {
+			state.Exists = true
+			if container.State != nil && container.State.Running {
+				state.RunningCount = 1
+			}
+		}
+		components = append(components, state)
+	}
+
+	return &provisioners.PushServiceInspectResult{
+		Instance:   instance,
+		Components: components,
+		Status:     provisioners.PushServiceInspectStatusSuccess,
+	}
+}
+
+func NewDockerPushServiceProvisioner(
+	logger *zap.Logger,
+	provisionerConfig *DockerProvisionerConfig,
+	pushRedisProvisioner DockerPushRedisProvisioner,
+	pushStreamProvisioner DockerPushStreamProvisioner,
+	pushApiProvisioner DockerPushApiProvisioner,
+) (provisioners.PushServiceProvisioner, error) {
+	return &dockerProvisioner{
+		logger:                logger,
+		provisionerConfig:     provisionerConfig,
+		pushRedisProvisioner:  pushRedisProvisioner,
+		pushStreamProvisioner: pushStreamProvisioner,
+		pushApiProvisioner:    pushApiProvisioner,
+	}, nil
+}
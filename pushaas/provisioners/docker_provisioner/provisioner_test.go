@@ -0,0 +1,211 @@
+package docker_provisioner_test
+
+import (
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/provisioners/docker_provisioner"
+)
+
+var _ = Describe("DockerProvisioner", func() {
+	instance := &models.Instance{
+		Name: "instance-1",
+	}
+
+	newConfig := func() *viper.Viper {
+		config := viper.New()
+		config.Set("provisioner.docker.network", "pushaas_default")
+		config.Set("provisioner.docker.public_host", "localhost")
+		return config
+	}
+
+	newProvisioner := func(dockerSvc docker_provisioner.DockerAPI) provisioners.PushServiceProvisioner {
+		provisionerConfig, err := docker_provisioner.NewDockerProvisionerConfig(newConfig(), dockerSvc)
+		Expect(err).NotTo(HaveOccurred())
+
+		provisioner, err := docker_provisioner.NewDockerPushServiceProvisioner(
+			logger,
+			provisionerConfig,
+			docker_provisioner.NewDockerPushRedisProvisioner(logger, provisionerConfig),
+			docker_provisioner.NewDockerPushStreamProvisioner(logger, provisionerConfig),
+			docker_provisioner.NewDockerPushApiProvisioner(logger, provisionerConfig),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		return provisioner
+	}
+
+	runningContainer := func(name string) (*docker_provisioner.ContainerInspectOutput, error) {
+		return &docker_provisioner.ContainerInspectOutput{
+			Id:    name,
+			Name:  "/" + name,
+			State: &docker_provisioner.ContainerState{Running: true},
+			NetworkSettings: &docker_provisioner.ContainerNetworkSettings{
+				Ports: map[string][]docker_provisioner.PortBinding{
+					"9080/tcp": {{HostIp: "0.0.0.0", HostPort: "32768"}},
+				},
+			},
+		}, nil
+	}
+
+	Describe("NewDockerProvisionerConfig", func() {
+		It("fails when network is not configured", func() {
+			// arrange
+			config := newConfig()
+			config.Set("provisioner.docker.network", "")
+
+			// act
+			provisionerConfig, err := docker_provisioner.NewDockerProvisionerConfig(config, &mocks.DockerAPIMock{})
+
+			// assert
+			Expect(err).To(HaveOccurred())
+			Expect(provisionerConfig).To(BeNil())
+		})
+	})
+
+	Describe("Provision", func() {
+		It("creates all containers and returns the env vars", func() {
+			// arrange
+			var pushApiInput *docker_provisioner.ContainerCreateInput
+			dockerSvc := &mocks.DockerAPIMock{
+				ContainerCreateFunc: func(name string, input *docker_provisioner.ContainerCreateInput) (*docker_provisioner.ContainerCreateOutput, error) {
+					if name == "push-api-instance-1" {
+						pushApiInput = input
+					}
+					return &docker_provisioner.ContainerCreateOutput{Id: name}, nil
+				},
+				ContainerStartFunc: func(id string) error {
+					return nil
+				},
+				ContainerInspectFunc: runningContainer,
+			}
+			provisioner := newProvisioner(dockerSvc)
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+			Expect(result.EnvVars[provisioners.EnvVarEndpoint]).To(Equal("http://push-api-instance-1:8080"))
+			Expect(result.EnvVars[provisioners.EnvVarUsername]).To(Equal("app"))
+			Expect(result.EnvVars[provisioners.EnvVarPassword]).NotTo(BeEmpty())
+			Expect(pushApiInput.Env).To(ContainElement("PUSHAPI_PUSH_STREAM__URL=http://localhost:32768"))
+			Expect(pushApiInput.Env).To(ContainElement("PUSHAPI_API__BASIC_AUTH_PASSWORD=" + result.EnvVars[provisioners.EnvVarPassword]))
+
+			calls := dockerSvc.ContainerCreateCalls()
+			Expect(calls).To(HaveLen(4))
+			Expect(calls[0].Name).To(Equal("push-redis-instance-1"))
+			Expect(calls[1].Name).To(Equal("push-stream-instance-1"))
+			Expect(calls[2].Name).To(Equal("push-agent-instance-1"))
+			Expect(calls[2].Input.HostConfig.NetworkMode).To(Equal("container:push-stream-instance-1"))
+			Expect(calls[3].Name).To(Equal("push-api-instance-1"))
+			Expect(dockerSvc.ContainerStartCalls()).To(HaveLen(4))
+			Expect(dockerSvc.ImagePullCalls()).To(HaveLen(0))
+		})
+
+		It("pulls the image when it is not present", func() {
+			// arrange
+			pulled := false
+			dockerSvc := &mocks.DockerAPIMock{
+				ContainerCreateFunc: func(name string, input *docker_provisioner.ContainerCreateInput) (*docker_provisioner.ContainerCreateOutput, error) {
+					if !pulled {
+						return nil, &docker_provisioner.DockerError{StatusCode: http.StatusNotFound, Message: "No such image"}
+					}
+					return &docker_provisioner.ContainerCreateOutput{Id: name}, nil
+				},
+				ImagePullFunc: func(image string) error {
+					pulled = true
+					return nil
+				},
+				ContainerStartFunc: func(id string) error {
+					return nil
+				},
+				ContainerInspectFunc: runningContainer,
+			}
+			provisioner := newProvisioner(dockerSvc)
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+			Expect(dockerSvc.ImagePullCalls()).To(HaveLen(1))
+		})
+
+		It("indicates failure when a container cannot be created", func() {
+			// arrange
+			dockerSvc := &mocks.DockerAPIMock{
+				ContainerCreateFunc: func(name string, input *docker_provisioner.ContainerCreateInput) (*docker_provisioner.ContainerCreateOutput, error) {
+					if name == "push-stream-instance-1" {
+						return nil, errors.New("some error")
+					}
+					return &docker_provisioner.ContainerCreateOutput{Id: name}, nil
+				},
+				ContainerStartFunc: func(id string) error {
+					return nil
+				},
+				ContainerInspectFunc: runningContainer,
+			}
+			provisioner := newProvisioner(dockerSvc)
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusFailure))
+			Expect(result.EnvVars).To(BeEmpty())
+			Expect(dockerSvc.ContainerCreateCalls()).To(HaveLen(2))
+		})
+	})
+
+	Describe("Deprovision", func() {
+		It("removes all containers", func() {
+			// arrange
+			dockerSvc := &mocks.DockerAPIMock{
+				ContainerInspectFunc: runningContainer,
+				ContainerStopFunc: func(name string) error {
+					return nil
+				},
+				ContainerRemoveFunc: func(name string) error {
+					return nil
+				},
+			}
+			provisioner := newProvisioner(dockerSvc)
+
+			// act
+			result := provisioner.Deprovision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceDeprovisionStatusSuccess))
+			calls := dockerSvc.ContainerRemoveCalls()
+			Expect(calls).To(HaveLen(4))
+			Expect(calls[0].Name).To(Equal("push-api-instance-1"))
+			Expect(calls[1].Name).To(Equal("push-agent-instance-1"))
+			Expect(calls[2].Name).To(Equal("push-stream-instance-1"))
+			Expect(calls[3].Name).To(Equal("push-redis-instance-1"))
+		})
+
+		It("indicates failure when a container is not found", func() {
+			// arrange
+			dockerSvc := &mocks.DockerAPIMock{
+				ContainerInspectFunc: func(name string) (*docker_provisioner.ContainerInspectOutput, error) {
+					return nil, &docker_provisioner.DockerError{StatusCode: http.StatusNotFound, Message: "No such container"}
+				},
+			}
+			provisioner := newProvisioner(dockerSvc)
+
+			// act
+			result := provisioner.Deprovision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceDeprovisionStatusFailure))
+			Expect(dockerSvc.ContainerRemoveCalls()).To(HaveLen(0))
+		})
+	})
+})
@@ -0,0 +1,125 @@
+package docker_provisioner
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+const pushApi = "push-api"
+const pushApiPort = "8080"
+
+type (
+	DockerPushApiProvisioner interface {
+		Provision(*models.Instance, string, string, string, chan provisionPushApiResult)
+		Deprovision(*models.Instance, chan deprovisionPushApiResult)
+	}
+
+	dockerPushApiProvisioner struct {
+		logger            *zap.Logger
+		provisionerConfig *DockerProvisionerConfig
+	}
+
+	provisionPushApiResult struct {
+		container *ContainerCreateOutput
+		err       error
+	}
+
+	deprovisionPushApiResult struct {
+		container *ContainerInspectOutput
+		err       error
+	}
+)
+
+func pushApiWithInstance(instanceName string) string {
+	return fmt.Sprintf("%s-%s", pushApi, instanceName)
+}
+
+/*
+	===========================================================================
+	provision
+	===========================================================================
+*/
+func (p *dockerPushApiProvisioner) Provision(instance *models.Instance, username string, password string, pushStreamPublicUrl string, ch chan provisionPushApiResult) {
+	var err error
+
+	// create container
+	container, err := p.createContainer(instance, username, password, pushStreamPublicUrl)
+	if err != nil {
+		ch <- provisionPushApiResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-api] did create container")
+
+	// wait for container to go up
+	waitCh := make(chan bool)
+	go waitContainerUp(p.logger, instance, waitCh, p.inspectContainer)
+	if containerUp := <-waitCh; !containerUp {
+		ch <- provisionPushApiResult{err: errors.New("push-api container did not become available")}
+		return
+	}
+	p.logger.Debug("[push-api] container is up")
+
+	ch <- provisionPushApiResult{
+		container: container,
+	}
+}
+
+func (p *dockerPushApiProvisioner) createContainer(
+	instance *models.Instance,
+	username string,
+	password string,
+	pushStreamPublicUrl string,
+) (*ContainerCreateOutput, error) {
+	return createAndStartContainer(pushApiWithInstance(instance.Name), &ContainerCreateInput{
+		Image:  p.provisionerConfig.imagePushApi,
+		Labels: containerLabels(instance, pushApi),
+		Env: []string{
+			fmt.Sprintf("PUSHAPI_REDIS__URL=redis://%s:%s", pushRedisWithInstance(instance.Name), pushRedisPort),
+			fmt.Sprintf("PUSHAPI_PUSH_STREAM__URL=%s", pushStreamPublicUrl),
+			fmt.Sprintf("PUSHAPI_API__BASIC_AUTH_USER=%s", username),
+			fmt.Sprintf("PUSHAPI_API__BASIC_AUTH_PASSWORD=%s", password),
+		},
+		HostConfig: &HostConfig{
+			NetworkMode: p.provisionerConfig.network,
+		},
+	}, p.provisionerConfig)
+}
+
+/*
+	===========================================================================
+	deprovision
+	===========================================================================
+*/
+func (p *dockerPushApiProvisioner) Deprovision(instance *models.Instance, ch chan deprovisionPushApiResult) {
+	// stop and remove container
+	container, err := removeContainer(pushApiWithInstance(instance.Name), p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushApiResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-api] did remove container")
+
+	ch <- deprovisionPushApiResult{
+		container: container,
+	}
+}
+
+/*
+	===========================================================================
+	other
+	===========================================================================
+*/
+func (p *dockerPushApiProvisioner) inspectContainer(instance *models.Instance) (*ContainerInspectOutput, error) {
+	return p.provisionerConfig.docker.ContainerInspect(pushApiWithInstance(instance.Name))
+}
+
+func NewDockerPushApiProvisioner(logger *zap.Logger, provisionerConfig *DockerProvisionerConfig) DockerPushApiProvisioner {
+	return &dockerPushApiProvisioner{
+		logger:            logger,
+		provisionerConfig: provisionerConfig,
+	}
+}
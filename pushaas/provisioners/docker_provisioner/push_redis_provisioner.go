@@ -0,0 +1,114 @@
+package docker_provisioner
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+const pushRedis = "push-redis"
+const pushRedisPort = "6379"
+
+type (
+	DockerPushRedisProvisioner interface {
+		Provision(*models.Instance, chan provisionPushRedisResult)
+		Deprovision(*models.Instance, chan deprovisionPushRedisResult)
+	}
+
+	dockerPushRedisProvisioner struct {
+		logger            *zap.Logger
+		provisionerConfig *DockerProvisionerConfig
+	}
+
+	provisionPushRedisResult struct {
+		container *ContainerCreateOutput
+		err       error
+	}
+
+	deprovisionPushRedisResult struct {
+		container *ContainerInspectOutput
+		err       error
+	}
+)
+
+func pushRedisWithInstance(instanceName string) string {
+	return fmt.Sprintf("%s-%s", pushRedis, instanceName)
+}
+
+/*
+	===========================================================================
+	provision
+	===========================================================================
+*/
+func (p *dockerPushRedisProvisioner) Provision(instance *models.Instance, ch chan provisionPushRedisResult) {
+	var err error
+
+	// create container
+	container, err := p.createContainer(instance)
+	if err != nil {
+		ch <- provisionPushRedisResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-redis] did create container")
+
+	// wait for container to go up
+	waitCh := make(chan bool)
+	go waitContainerUp(p.logger, instance, waitCh, p.inspectContainer)
+	if containerUp := <-waitCh; !containerUp {
+		ch <- provisionPushRedisResult{err: errors.New("push-redis container did not become available")}
+		return
+	}
+	p.logger.Debug("[push-redis] container is up")
+
+	ch <- provisionPushRedisResult{
+		container: container,
+	}
+}
+
+func (p *dockerPushRedisProvisioner) createContainer(instance *models.Instance) (*ContainerCreateOutput, error) {
+	return createAndStartContainer(pushRedisWithInstance(instance.Name), &ContainerCreateInput{
+		Image:  p.provisionerConfig.imagePushRedis,
+		Labels: containerLabels(instance, pushRedis),
+		HostConfig: &HostConfig{
+			NetworkMode: p.provisionerConfig.network,
+		},
+	}, p.provisionerConfig)
+}
+
+/*
+	===========================================================================
+	deprovision
+	===========================================================================
+*/
+func (p *dockerPushRedisProvisioner) Deprovision(instance *models.Instance, ch chan deprovisionPushRedisResult) {
+	// stop and remove container
+	container, err := removeContainer(pushRedisWithInstance(instance.Name), p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushRedisResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-redis] did remove container")
+
+	ch <- deprovisionPushRedisResult{
+		container: container,
+	}
+}
+
+/*
+	===========================================================================
+	other
+	===========================================================================
+*/
+func (p *dockerPushRedisProvisioner) inspectContainer(instance *models.Instance) (*ContainerInspectOutput, error) {
+	return p.provisionerConfig.docker.ContainerInspect(pushRedisWithInstance(instance.Name))
+}
+
+func NewDockerPushRedisProvisioner(logger *zap.Logger, provisionerConfig *DockerProvisionerConfig) DockerPushRedisProvisioner {
+	return &dockerPushRedisProvisioner{
+		logger:            logger,
+		provisionerConfig: provisionerConfig,
+	}
+}
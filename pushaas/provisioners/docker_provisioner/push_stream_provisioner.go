@@ -0,0 +1,189 @@
+package docker_provisioner
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+const pushAgent = "push-agent"
+const pushStream = "push-stream"
+const pushStreamPort = "9080"
+
+type (
+	DockerPushStreamProvisioner interface {
+		Provision(*models.Instance, chan provisionPushStreamResult)
+		Deprovision(*models.Instance, chan deprovisionPushStreamResult)
+	}
+
+	dockerPushStreamProvisioner struct {
+		logger            *zap.Logger
+		provisionerConfig *DockerProvisionerConfig
+	}
+
+	provisionPushStreamResult struct {
+		container      *ContainerCreateOutput
+		agentContainer *ContainerCreateOutput
+		publicPort     string
+		err            error
+	}
+
+	deprovisionPushStreamResult struct {
+		container      *ContainerInspectOutput
+		agentContainer *ContainerInspectOutput
+		err            error
+	}
+)
+
+func pushStreamWithInstance(instanceName string) string {
+	return fmt.Sprintf("%s-%s", pushStream, instanceName)
+}
+
+func pushAgentWithInstance(instanceName string) string {
+	return fmt.Sprintf("%s-%s", pushAgent, instanceName)
+}
+
+/*
+	===========================================================================
+	provision
+	===========================================================================
+*/
+func (p *dockerPushStreamProvisioner) Provision(instance *models.Instance, ch chan provisionPushStreamResult) {
+	var err error
+
+	// create push-stream container
+	container, err := p.createContainer(instance)
+	if err != nil {
+		ch <- provisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did create container")
+
+	// wait for container to go up
+	waitCh := make(chan bool)
+	go waitContainerUp(p.logger, instance, waitCh, p.inspectContainer)
+	if containerUp := <-waitCh; !containerUp {
+		ch <- provisionPushStreamResult{err: errors.New("push-stream container did not become available")}
+		return
+	}
+	p.logger.Debug("[push-stream] container is up")
+
+	// create push-agent container, sharing the network namespace of push-stream as a sidecar
+	agentContainer, err := p.createAgentContainer(instance)
+	if err != nil {
+		ch <- provisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did create agent container")
+
+	// wait for agent container to go up
+	waitAgentCh := make(chan bool)
+	go waitContainerUp(p.logger, instance, waitAgentCh, p.inspectAgentContainer)
+	if containerUp := <-waitAgentCh; !containerUp {
+		ch <- provisionPushStreamResult{err: errors.New("push-agent container did not become available")}
+		return
+	}
+	p.logger.Debug("[push-stream] agent container is up")
+
+	// get published port
+	inspectedContainer, err := p.inspectContainer(instance)
+	if err != nil {
+		ch <- provisionPushStreamResult{err: err}
+		return
+	}
+	publicPort, err := publishedPort(inspectedContainer, pushStreamPort+"/tcp")
+	if err != nil {
+		ch <- provisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] port is published", zap.String("publicPort", publicPort))
+
+	ch <- provisionPushStreamResult{
+		container:      container,
+		agentContainer: agentContainer,
+		publicPort:     publicPort,
+	}
+}
+
+func (p *dockerPushStreamProvisioner) createContainer(instance *models.Instance) (*ContainerCreateOutput, error) {
+	port := pushStreamPort + "/tcp"
+
+	return createAndStartContainer(pushStreamWithInstance(instance.Name), &ContainerCreateInput{
+		Image:  p.provisionerConfig.imagePushStream,
+		Labels: containerLabels(instance, pushStream),
+		ExposedPorts: map[string]struct{}{
+			port: {},
+		},
+		HostConfig: &HostConfig{
+			NetworkMode: p.provisionerConfig.network,
+			PortBindings: map[string][]PortBinding{
+				// empty HostPort lets docker pick a free port
+				port: {{HostPort: ""}},
+			},
+		},
+	}, p.provisionerConfig)
+}
+
+func (p *dockerPushStreamProvisioner) createAgentContainer(instance *models.Instance) (*ContainerCreateOutput, error) {
+	return createAndStartContainer(pushAgentWithInstance(instance.Name), &ContainerCreateInput{
+		Image:  p.provisionerConfig.imagePushAgent,
+		Labels: containerLabels(instance, pushAgent),
+		Env: []string{
+			fmt.Sprintf("PUSHAGENT_REDIS__URL=redis://%s:%s", pushRedisWithInstance(instance.Name), pushRedisPort),
+			fmt.Sprintf("PUSHAGENT_PUSH_STREAM__URL=http://localhost:%s", pushStreamPort),
+		},
+		HostConfig: &HostConfig{
+			NetworkMode: fmt.Sprintf("container:%s", pushStreamWithInstance(instance.Name)),
+		},
+	}, p.provisionerConfig)
+}
+
+/*
+	===========================================================================
+	deprovision
+	===========================================================================
+*/
+func (p *dockerPushStreamProvisioner) Deprovision(instance *models.Instance, ch chan deprovisionPushStreamResult) {
+	// the agent uses the network of push-stream, so it must go first
+	agentContainer, err := removeContainer(pushAgentWithInstance(instance.Name), p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did remove agent container")
+
+	container, err := removeContainer(pushStreamWithInstance(instance.Name), p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did remove container")
+
+	ch <- deprovisionPushStreamResult{
+		container:      container,
+		agentContainer: agentContainer,
+	}
+}
+
+/*
+	===========================================================================
+	other
+	===========================================================================
+*/
+func (p *dockerPushStreamProvisioner) inspectContainer(instance *models.Instance) (*ContainerInspectOutput, error) {
+	return p.provisionerConfig.docker.ContainerInspect(pushStreamWithInstance(instance.Name))
+}
+
+func (p *dockerPushStreamProvisioner) inspectAgentContainer(instance *models.Instance) (*ContainerInspectOutput, error) {
+	return p.provisionerConfig.docker.ContainerInspect(pushAgentWithInstance(instance.Name))
+}
+
+func NewDockerPushStreamProvisioner(logger *zap.Logger, provisionerConfig *DockerProvisionerConfig) DockerPushStreamProvisioner {
+	return &dockerPushStreamProvisioner{
+		logger:            logger,
+		provisionerConfig: provisionerConfig,
+	}
+}
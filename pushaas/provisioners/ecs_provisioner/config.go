@@ -10,6 +10,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/servicediscovery/servicediscoveryiface"
 	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/models"
 )
 
 type (
@@ -28,6 +30,7 @@ type (
 		securityGroup    *string
 		subnet           *string
 		dnsNamespace     *string
+		plans            map[string]*models.Plan
 	}
 )
 
@@ -65,6 +68,16 @@ func NewEcsProvisionerConfig(config *viper.Viper, iamSvc iamiface.IAMAPI, ecsSvc
 		}
 	}
 
+	// the catalog is validated by the plan service
+	var planList []models.Plan
+	if err := config.UnmarshalKey("plans", &planList); err != nil {
+		return nil, err
+	}
+	plans := make(map[string]*models.Plan, len(planList))
+	for i := range planList {
+		plans[planList[i].Name] = &planList[i]
+	}
+
 	return &EcsProvisionerConfig{
 		iam:              iamSvc,
 		ecs:              ecsSvc,
@@ -80,5 +93,6 @@ func NewEcsProvisionerConfig(config *viper.Viper, iamSvc iamiface.IAMAPI, ecsSvc
 		securityGroup:    aws.String(securityGroup),
 		subnet:           aws.String(subnet),
 		dnsNamespace:     aws.String(dnsNamespace),
+		plans:            plans,
 	}, nil
 }
@@ -0,0 +1,188 @@
+package ecs_provisioner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+)
+
+const resourceKindService = "ecs-service"
+const resourceKindServiceDiscovery = "service-discovery"
+const resourceKindTaskDefinition = "task-definition"
+
+// arns end with the name of the resource, e.g. `arn:aws:ecs:<region>:<account>:service/<cluster>/<name>`
+func nameFromArn(arn string) string {
+	return arn[strings.LastIndex(arn, "/")+1:]
+}
+
+func appendResource(resources []*provisioners.PushServiceResource, kind string, name string, createdAt *time.Time) []*provisioners.PushServiceResource {
+	instanceName, ok := provisioners.InstanceNameFromResourceName(name)
+	if !ok {
+		return resources
+	}
+	return append(resources, &provisioners.PushServiceResource{
+		Kind:         kind,
+		Name:         name,
+		InstanceName: instanceName,
+		CreatedAt:    createdAt,
+	})
+}
+
+/*
+	services come first, as the service discovery services can only be deleted after the services released their instances.
+	Task definition families are shared by the whole account and region, so only the ones tagged with the cluster of this
+	deployment are listed.
+*/
+func (p *ecsProvisioner) ListResources() ([]*provisioners.PushServiceResource, error) {
+	var resources []*provisioners.PushServiceResource
+
+	var describeErr error
+	err := p.provisionerConfig.ecs.ListServicesPages(&ecs.ListServicesInput{
+		Cluster: p.provisionerConfig.cluster,
+	}, func(output *ecs.ListServicesOutput, lastPage bool) bool {
+		if len(output.ServiceArns) == 0 {
+			return true
+		}
+
+		// a page has at most 10 services, as many as can be described at once
+		var describeOutput *ecs.DescribeServicesOutput
+		describeOutput, describeErr = p.provisionerConfig.ecs.DescribeServices(&ecs.DescribeServicesInput{
+			Cluster:  p.provisionerConfig.cluster,
+			Services: output.ServiceArns,
+		})
+		if describeErr != nil {
+			return false
+		}
+		for _, service := range describeOutput.Services {
+			resources = appendResource(resources, resourceKindService, *service.ServiceName, service.CreatedAt)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if describeErr != nil {
+		return nil, describeErr
+	}
+
+	err = p.provisionerConfig.serviceDiscovery.ListServicesPages(&servicediscovery.ListServicesInput{
+		Filters: []*servicediscovery.ServiceFilter{
+			{
+				Name:      aws.String(servicediscovery.ServiceFilterNameNamespaceId),
+				Condition: aws.String(servicediscovery.FilterConditionEq),
+				Values:    []*string{p.provisionerConfig.dnsNamespace},
+			},
+		},
+	}, func(output *servicediscovery.ListServicesOutput, lastPage bool) bool {
+		for _, service := range output.Services {
+			resources = appendResource(resources, resourceKindServiceDiscovery, *service.Name, service.CreateDate)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var families []string
+	err = p.provisionerConfig.ecs.ListTaskDefinitionFamiliesPages(&ecs.ListTaskDefinitionFamiliesInput{
+		Status: aws.String(ecs.TaskDefinitionFamilyStatusActive),
+	}, func(output *ecs.ListTaskDefinitionFamiliesOutput, lastPage bool) bool {
+		for _, family := range output.Families {
+			if _, ok := provisioners.InstanceNameFromResourceName(*family); ok {
+				families = append(families, *family)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, family := range families {
+		owned, err := isTaskDefinitionFamilyOfCluster(family, p.provisionerConfig)
+		if err != nil {
+			return nil, err
+		}
+		if owned {
+			// the api does not tell when a task definition was registered
+			resources = appendResource(resources, resourceKindTaskDefinition, family, nil)
+		}
+	}
+
+	return resources, nil
+}
+
+// the latest revision of the family tells whether it was registered by this deployment
+func isTaskDefinitionFamilyOfCluster(family string, provisionerConfig *EcsProvisionerConfig) (bool, error) {
+	describeOutput, err := provisionerConfig.ecs.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(family),
+		Include:        []*string{aws.String(ecs.TaskDefinitionFieldTags)},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, tag := range describeOutput.Tags {
+		if *tag.Key == clusterTagKey && *tag.Value == *provisionerConfig.cluster {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *ecsProvisioner) DeleteResource(resource *provisioners.PushServiceResource) error {
+	switch resource.Kind {
+	case resourceKindService:
+		instance := &models.Instance{Name: resource.InstanceName}
+		return undoService(p.logger, instance, resource.Name, p.provisionerConfig, func(*models.Instance) (*ecs.DescribeServicesOutput, error) {
+			return describeService(resource.Name, p.provisionerConfig)
+		})
+	case resourceKindServiceDiscovery:
+		return undoServiceDiscovery(resource.Name, p.provisionerConfig)
+	case resourceKindTaskDefinition:
+		return deregisterTaskDefinitionFamily(resource.Name, p.provisionerConfig)
+	}
+	return errors.New(fmt.Sprintf("unknown resource kind %s", resource.Kind))
+}
+
+/*
+	every active revision of the family is deregistered, the prefix filter also matches longer families so they are skipped
+*/
+func deregisterTaskDefinitionFamily(family string, provisionerConfig *EcsProvisionerConfig) error {
+	var taskDefinitionArns []*string
+	err := provisionerConfig.ecs.ListTaskDefinitionsPages(&ecs.ListTaskDefinitionsInput{
+		FamilyPrefix: aws.String(family),
+		Status:       aws.String(ecs.TaskDefinitionStatusActive),
+	}, func(output *ecs.ListTaskDefinitionsOutput, lastPage bool) bool {
+		for _, taskDefinitionArn := range output.TaskDefinitionArns {
+			// `.../task-definition/<family>:<revision>`
+			familyRevision := nameFromArn(*taskDefinitionArn)
+			revisionIndex := strings.LastIndex(familyRevision, ":")
+			if revisionIndex >= 0 && familyRevision[:revisionIndex] == family {
+				taskDefinitionArns = append(taskDefinitionArns, taskDefinitionArn)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, taskDefinitionArn := range taskDefinitionArns {
+		_, err = provisionerConfig.ecs.DeregisterTaskDefinition(&ecs.DeregisterTaskDefinitionInput{
+			TaskDefinition: taskDefinitionArn,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
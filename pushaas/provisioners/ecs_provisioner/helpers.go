@@ -59,6 +59,65 @@ func stopService(describeService *ecs.DescribeServicesOutput, provisionerConfig
 	})
 }
 
+/*
+	task definition families are shared by every cluster of the account and region, the tag tells which deployment
+	registered them
+*/
+const clusterTagKey = "pushaas-cluster"
+
+func clusterTags(provisionerConfig *EcsProvisionerConfig) []*ecs.Tag {
+	return []*ecs.Tag{
+		{
+			Key:   aws.String(clusterTagKey),
+			Value: provisionerConfig.cluster,
+		},
+	}
+}
+
+/*
+	registers a new revision of the task definition family copied from its latest one, as changed by changeFn.
+	Returns the new revision along with the arn of the one it replaces.
+*/
+func registerTaskDefinitionRevision(family string, provisionerConfig *EcsProvisionerConfig, changeFn func(*ecs.RegisterTaskDefinitionInput)) (*ecs.RegisterTaskDefinitionOutput, *string, error) {
+	describeOutput, err := provisionerConfig.ecs.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(family),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := describeOutput.TaskDefinition
+	input := &ecs.RegisterTaskDefinitionInput{
+		Family:                  aws.String(family),
+		ContainerDefinitions:    current.ContainerDefinitions,
+		Cpu:                     current.Cpu,
+		Memory:                  current.Memory,
+		ExecutionRoleArn:        current.ExecutionRoleArn,
+		TaskRoleArn:             current.TaskRoleArn,
+		NetworkMode:             current.NetworkMode,
+		RequiresCompatibilities: current.RequiresCompatibilities,
+		Volumes:                 current.Volumes,
+		Tags:                    clusterTags(provisionerConfig),
+	}
+	changeFn(input)
+
+	registerOutput, err := provisionerConfig.ecs.RegisterTaskDefinition(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return registerOutput, current.TaskDefinitionArn, nil
+}
+
+// replaces the running tasks of the service by tasks of the given task definition
+func rollService(serviceName string, taskDefinitionArn *string, provisionerConfig *EcsProvisionerConfig) (*ecs.UpdateServiceOutput, error) {
+	return provisionerConfig.ecs.UpdateService(&ecs.UpdateServiceInput{
+		Cluster:            provisionerConfig.cluster,
+		Service:            aws.String(serviceName),
+		TaskDefinition:     taskDefinitionArn,
+		ForceNewDeployment: aws.Bool(true),
+	})
+}
+
 func deleteTaskDefinition(describeService *ecs.DescribeServicesOutput, provisionerConfig *EcsProvisionerConfig) (*ecs.DeregisterTaskDefinitionOutput, error) {
 	return provisionerConfig.ecs.DeregisterTaskDefinition(&ecs.DeregisterTaskDefinitionInput{
 		TaskDefinition: describeService.Services[0].TaskDefinition,
@@ -122,6 +181,27 @@ func deleteServiceDiscovery(instanceName string, provisionerConfig *EcsProvision
 	return nil, errors.New(fmt.Sprintf("could not find service discovery service for instance %s", instanceName))
 }
 
+/*
+	the identifiers of what was created for a component, recorded on the instance. The ones missing from the
+	outputs are left out.
+*/
+func recordResources(
+	resources map[string]string,
+	component string,
+	service *ecs.CreateServiceOutput,
+	serviceDiscovery *servicediscovery.CreateServiceOutput,
+	taskDefinition *ecs.RegisterTaskDefinitionOutput,
+) {
+	if service != nil && service.Service != nil && service.Service.ServiceArn != nil {
+		resources[component+".service-arn"] = *service.Service.ServiceArn
+	}
+	if serviceDiscovery != nil && serviceDiscovery.Service != nil && serviceDiscovery.Service.Id != nil {
+		resources[component+".discovery-service-id"] = *serviceDiscovery.Service.Id
+	}
+	if taskDefinition != nil && taskDefinition.TaskDefinition != nil && taskDefinition.TaskDefinition.TaskDefinitionArn != nil {
+		resources[component+".task-definition-arn"] = *taskDefinition.TaskDefinition.TaskDefinitionArn
+	}
+}
 
 /*
 	===========================================================================
@@ -162,6 +242,26 @@ func waitServiceUp(logger *zap.Logger, instance *models.Instance, ch chan bool,
 	})
 }
 
+/*
+	a rolled service is deployed when the previous deployment is gone and the new one has all its tasks running
+*/
+func waitServiceDeployed(logger *zap.Logger, instance *models.Instance, ch chan bool, describeServiceFunc func(*models.Instance) (*ecs.DescribeServicesOutput, error)) {
+	waitTrue(ch, func(attempt int) bool {
+		serviceResult, err := describeServiceFunc(instance)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[waitServiceDeployed] failed on attempt %d", attempt), zap.Error(err))
+			return false
+		}
+		if len(serviceResult.Services) == 0 {
+			return false
+		}
+		service := serviceResult.Services[0]
+		isServiceDeployed := len(service.Deployments) == 1 && *service.RunningCount > 0 && *service.RunningCount == *service.DesiredCount
+		logger.Debug(fmt.Sprintf("[waitServiceDeployed] attempt %d with result isServiceDeployed=%t", attempt, isServiceDeployed), zap.Error(err))
+		return isServiceDeployed
+	})
+}
+
 func waitServiceStopAllTasks(logger *zap.Logger, instance *models.Instance, ch chan bool, describeServiceFunc func(*models.Instance) (*ecs.DescribeServicesOutput, error)) {
 	waitTrue(ch, func(attempt int) bool {
 		serviceResult, err := describeServiceFunc(instance)
@@ -199,6 +299,11 @@ func waitServiceDown(logger *zap.Logger, instance *models.Instance, ch chan bool
 	})
 }
 
+type networkInterfaceResult struct {
+	eni *ec2.DescribeNetworkInterfacesOutput
+	err error
+}
+
 // TODO technical debt
 func waitTaskNetworkInterface(logger *zap.Logger, instance *models.Instance, ch chan bool, describeEniFunc func(instance *models.Instance) (*ec2.DescribeNetworkInterfacesOutput, error)) {
 	waitTrue(ch, func(attempt int) bool {
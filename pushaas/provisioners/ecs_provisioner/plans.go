@@ -0,0 +1,66 @@
+package ecs_provisioner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+type (
+	// cpu in cpu units and memory in MiB, as fargate takes them
+	ecsTaskSize struct {
+		cpu    int64
+		memory int64
+	}
+)
+
+func (c *EcsProvisionerConfig) plan(name string) (*models.Plan, error) {
+	plan, ok := c.plans[name]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("plan %s is not configured", name))
+	}
+	return plan, nil
+}
+
+// push-stream and push-agent share a task the size of the plan
+func planPushStreamTaskSize(plan *models.Plan) ecsTaskSize {
+	return ecsTaskSize{cpu: plan.Cpu, memory: plan.Memory}
+}
+
+// push-api runs alone on a task of half the plan, the same each of the containers of push-stream gets
+func planPushApiTaskSize(plan *models.Plan) ecsTaskSize {
+	return ecsTaskSize{cpu: plan.Cpu / 2, memory: plan.Memory / 2}
+}
+
+/*
+	the limits are left to the push-stream defaults when the plan does not set them
+*/
+func planPushStreamLimits(plan *models.Plan) []*ecs.KeyValuePair {
+	var limits []*ecs.KeyValuePair
+	if plan.MaxChannels > 0 {
+		limits = append(limits, &ecs.KeyValuePair{
+			Name:  aws.String("PUSHSTREAM_MAX_NUMBER_OF_CHANNELS"),
+			Value: aws.String(strconv.FormatInt(plan.MaxChannels, 10)),
+		})
+	}
+	if plan.MaxSubscribers > 0 {
+		limits = append(limits, &ecs.KeyValuePair{
+			Name:  aws.String("PUSHSTREAM_MAX_SUBSCRIBERS_PER_CHANNEL"),
+			Value: aws.String(strconv.FormatInt(plan.MaxSubscribers, 10)),
+		})
+	}
+	return limits
+}
+
+func (s ecsTaskSize) taskCpu() *string {
+	return aws.String(strconv.FormatInt(s.cpu, 10))
+}
+
+func (s ecsTaskSize) taskMemory() *string {
+	return aws.String(strconv.FormatInt(s.memory, 10))
+}
@@ -19,6 +19,7 @@ type (
 		pushRedisProvisioner  EcsPushRedisProvisioner
 		pushStreamProvisioner EcsPushStreamProvisioner
 		pushApiProvisioner    EcsPushApiProvisioner
+		stepStore             provisioners.ProvisionStepStore
 	}
 )
 
@@ -31,82 +32,364 @@ type (
 		  and I just ended up running everything sequentially, but kept the channels in order to change as little as possible.
 		- several points should consider adding load balancers to allow working with multiple instances, and using
 		  names (instead of IPs) to address services
+
+	Every resource created while provisioning is registered on a saga, so when any step fails the resources
+	already created are removed (in reverse order) and the outcome of that rollback is recorded on the instance,
+	along with the reason of the failure. The identifiers of the resources created are recorded on the instance too.
+
+	The steps of a provision are persisted as they start and complete, so when a provision is interrupted (e.g. the
+	process restarts) the redelivered task resumes from the last completed step instead of starting over.
  */
 
+const stepCredentials = "credentials"
+const stepPushRedis = "push-redis"
+const stepPushStream = "push-stream"
+const stepPushStreamEni = "push-stream-eni"
+const stepPushApi = "push-api"
+const stepPushApiEni = "push-api-eni"
+
+/*
+	Runs a step unless a previous attempt already completed it, in which case the value it persisted is reused and
+	the undo of its resources is registered again, so a later failure still removes everything.
+	A step whose resources existed before the provision started is skipped too, but never undone, as it is not
+	this provision that created them.
+	A step that was started but not completed had its attempt interrupted, so whatever it created is removed first.
+*/
+func (p *ecsProvisioner) runStep(
+	instance *models.Instance,
+	steps map[string]*provisioners.ProvisionStep,
+	saga *provisionSaga,
+	name string,
+	registerRollback func(*models.Instance, *provisionSaga),
+	stepFn func() (string, error),
+) (string, error) {
+	step, ok := steps[name]
+	if ok && step.Status == provisioners.ProvisionStepStatusExisting {
+		p.logger.Info("skipping provision step of resources that already exist", zap.String("step", name), zap.Any("instance", instance))
+		return step.Value, nil
+	}
+	if ok && step.Status == provisioners.ProvisionStepStatusCompleted {
+		p.logger.Info("skipping provision step completed by a previous attempt", zap.String("step", name), zap.Any("instance", instance))
+		if registerRollback != nil {
+			registerRollback(instance, saga)
+		}
+		return step.Value, nil
+	}
+
+	if ok && step.Status == provisioners.ProvisionStepStatusStarted && registerRollback != nil {
+		p.logger.Info("cleaning up provision step interrupted on a previous attempt", zap.String("step", name), zap.Any("instance", instance))
+		cleanupSaga := newProvisionSaga(p.logger, instance)
+		registerRollback(instance, cleanupSaga)
+		cleanupSaga.rollback()
+	}
+
+	err := p.stepStore.SetStep(instance.Name, &provisioners.ProvisionStep{Name: name, Status: provisioners.ProvisionStepStatusStarted})
+	if err != nil {
+		return "", err
+	}
+
+	value, err := stepFn()
+	if err != nil {
+		return "", err
+	}
+
+	err = p.stepStore.SetStep(instance.Name, &provisioners.ProvisionStep{Name: name, Status: provisioners.ProvisionStepStatusCompleted, Value: value})
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
 func (p *ecsProvisioner) Provision(instance *models.Instance) *provisioners.PushServiceProvisionResult {
 	p.logger.Info("starting provision for instance", zap.Any("instance", instance))
 
+	steps, err := p.stepStore.GetSteps(instance.Name)
+	if err != nil {
+		p.logger.Error("failed while provisioning instance, failed to get provision steps", zap.Any("instance", instance), zap.Error(err))
+		return &provisioners.PushServiceProvisionResult{
+			Instance:      instance,
+			Status:        provisioners.PushServiceProvisionStatusFailure,
+			EnvVars:       map[string]string{},
+			FailureReason: fmt.Sprintf("provision steps: %s", err),
+		}
+	}
+
+	return p.provision(instance, steps)
+}
+
+func (p *ecsProvisioner) provision(instance *models.Instance, steps map[string]*provisioners.ProvisionStep) *provisioners.PushServiceProvisionResult {
 	var err error
+	resources := map[string]string{}
 	failureResult := &provisioners.PushServiceProvisionResult{
-		Instance: instance,
-		Status: provisioners.PushServiceProvisionStatusFailure,
-		EnvVars: map[string]string{},
+		Instance:  instance,
+		Status:    provisioners.PushServiceProvisionStatusFailure,
+		EnvVars:   map[string]string{},
+		Resources: resources,
+	}
+
+	saga := newProvisionSaga(p.logger, instance)
+	rollback := func(failureReason string) *provisioners.PushServiceProvisionResult {
+		failureResult.FailureReason = failureReason
+		instance.Rollback = saga.rollback()
+		p.logger.Info("finished rollback for instance", zap.Any("instance", instance))
+		// after a rollback there is nothing left to resume
+		_ = p.stepStore.DelSteps(instance.Name)
+		return failureResult
 	}
 
 	role, err := getIamRole(p.provisionerConfig.iam)
 	if err != nil {
 		p.logger.Error("failed while provisioning instance, failed to get iam role", zap.Any("instance", instance), zap.Error(err))
+		failureResult.FailureReason = fmt.Sprintf("iam role: %s", err)
 		return failureResult
 	}
 
+	/*
+		credentials
+	*/
+	username := "app"
+	password, err := p.runStep(instance, steps, saga, stepCredentials, nil, func() (string, error) {
+		return uniuri.New(), nil
+	})
+	if err != nil {
+		p.logger.Error("credentials: provision failure", zap.Any("instance", instance), zap.Error(err))
+		return rollback(fmt.Sprintf("credentials: %s", err))
+	}
+
 	/*
 		push-redis
 	*/
-	chRedis := make(chan provisionPushRedisResult)
-	go p.pushRedisProvisioner.Provision(instance, chRedis)
-	resultPushRedis := <-chRedis
-	if resultPushRedis.err != nil {
-		p.logger.Error("push-redis: provision failure", zap.Any("instance", instance), zap.Error(resultPushRedis.err))
-		// TODO deprovision
-		return failureResult
+	_, err = p.runStep(instance, steps, saga, stepPushRedis, p.pushRedisProvisioner.RegisterRollback, func() (string, error) {
+		chRedis := make(chan provisionPushRedisResult)
+		go p.pushRedisProvisioner.Provision(instance, saga, chRedis)
+		resultPushRedis := <-chRedis
+		recordResources(resources, pushRedis, resultPushRedis.service, resultPushRedis.serviceDiscovery, nil)
+		return "", resultPushRedis.err
+	})
+	if err != nil {
+		p.logger.Error("push-redis: provision failure", zap.Any("instance", instance), zap.Error(err))
+		return rollback(fmt.Sprintf("push-redis: %s", err))
 	}
 	p.logger.Info("push-redis: provision success", zap.Any("instance", instance))
 
 	/*
 		push-stream
 	*/
-	chStream := make(chan provisionPushStreamResult)
-	go p.pushStreamProvisioner.Provision(instance, role, chStream)
+	_, err = p.runStep(instance, steps, saga, stepPushStream, p.pushStreamProvisioner.RegisterRollback, func() (string, error) {
+		chStream := make(chan provisionPushStreamResult)
+		go p.pushStreamProvisioner.Provision(instance, saga, role, chStream)
+		resultPushStream := <-chStream
+		recordResources(resources, pushStream, resultPushStream.service, resultPushStream.serviceDiscovery, resultPushStream.taskDefinition)
+		return "", resultPushStream.err
+	})
+	if err != nil {
+		p.logger.Error("push-stream: provision failure", zap.Any("instance", instance), zap.Error(err))
+		return rollback(fmt.Sprintf("push-stream: %s", err))
+	}
+	p.logger.Info("push-stream: provision success", zap.Any("instance", instance))
+
+	pushStreamPublicIp, err := p.runStep(instance, steps, saga, stepPushStreamEni, nil, func() (string, error) {
+		chEni := make(chan networkInterfaceResult)
+		go p.pushStreamProvisioner.ResolveNetworkInterface(instance, chEni)
+		resultEni := <-chEni
+		if resultEni.err != nil {
+			return "", resultEni.err
+		}
+		// TODO technical debt
+		return *resultEni.eni.NetworkInterfaces[0].Association.PublicIp, nil
+	})
+	if err != nil {
+		p.logger.Error("push-stream: network interface failure", zap.Any("instance", instance), zap.Error(err))
+		return rollback(fmt.Sprintf("push-stream network interface: %s", err))
+	}
+	resources[provisioners.ResourcePushStreamUrl] = pushStreamUrl(pushStreamPublicIp)
+
+	/*
+		push-api
+	*/
+	_, err = p.runStep(instance, steps, saga, stepPushApi, p.pushApiProvisioner.RegisterRollback, func() (string, error) {
+		chApi := make(chan provisionPushApiResult)
+		go p.pushApiProvisioner.Provision(instance, saga, role, username, password, pushStreamPublicIp, chApi)
+		resultPushApi := <-chApi
+		recordResources(resources, pushApi, resultPushApi.service, resultPushApi.serviceDiscovery, resultPushApi.taskDefinition)
+		return "", resultPushApi.err
+	})
+	if err != nil {
+		p.logger.Error("push-api: provision failure", zap.Any("instance", instance), zap.Error(err))
+		return rollback(fmt.Sprintf("push-api: %s", err))
+	}
+	p.logger.Info("push-api: provision success", zap.Any("instance", instance))
+
+	pushApiPrivateIp, err := p.runStep(instance, steps, saga, stepPushApiEni, nil, func() (string, error) {
+		chEni := make(chan networkInterfaceResult)
+		go p.pushApiProvisioner.ResolveNetworkInterface(instance, chEni)
+		resultEni := <-chEni
+		if resultEni.err != nil {
+			return "", resultEni.err
+		}
+		// TODO technical debt
+		return *resultEni.eni.NetworkInterfaces[0].PrivateIpAddress, nil
+	})
+	if err != nil {
+		p.logger.Error("push-api: network interface failure", zap.Any("instance", instance), zap.Error(err))
+		return rollback(fmt.Sprintf("push-api network interface: %s", err))
+	}
+
+	p.logger.Info(
+		"finishing provision for instance",
+		zap.Any("instance", instance),
+		zap.String("pushStreamPublicIp", pushStreamPublicIp),
+		zap.String("pushApiPrivateIp", pushApiPrivateIp),
+	)
+
+	// the provision is done, so there is nothing left to resume
+	_ = p.stepStore.DelSteps(instance.Name)
+
+	envVars := map[string]string{
+		provisioners.EnvVarEndpoint: fmt.Sprintf("http://%s:%s", pushApiPrivateIp, pushApiPort),
+		provisioners.EnvVarPassword: password,
+		provisioners.EnvVarUsername: username,
+	}
+
+	return &provisioners.PushServiceProvisionResult{
+		Instance:  instance,
+		EnvVars:   envVars,
+		Status:    provisioners.PushServiceProvisionStatusSuccess,
+		Resources: resources,
+	}
+}
+
+/*
+	The task definitions are registered again with the size of the plan and the services are rolled to them.
+	An update is not resumed nor rolled back, as it keeps the resources of the instance.
+*/
+func (p *ecsProvisioner) Update(instance *models.Instance) *provisioners.PushServiceProvisionResult {
+	p.logger.Info("starting update for instance", zap.Any("instance", instance))
+
+	failureResult := &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceProvisionStatusFailure,
+		EnvVars:  map[string]string{},
+	}
+
+	/*
+		push-stream
+	*/
+	chStream := make(chan updatePushStreamResult)
+	go p.pushStreamProvisioner.Update(instance, chStream)
 	resultPushStream := <-chStream
 	if resultPushStream.err != nil {
-		p.logger.Error("push-stream: provision failure", zap.Any("instance", instance), zap.Error(resultPushStream.err))
-		// TODO deprovision
+		p.logger.Error("push-stream: update failure", zap.Any("instance", instance), zap.Error(resultPushStream.err))
+		failureResult.FailureReason = fmt.Sprintf("push-stream: %s", resultPushStream.err)
 		return failureResult
 	}
-	p.logger.Info("push-stream: provision success", zap.Any("instance", instance))
+	p.logger.Info("push-stream: update success", zap.Any("instance", instance))
+
+	chStreamEni := make(chan networkInterfaceResult)
+	go p.pushStreamProvisioner.ResolveNetworkInterface(instance, chStreamEni)
+	resultPushStreamEni := <-chStreamEni
+	if resultPushStreamEni.err != nil {
+		p.logger.Error("push-stream: network interface failure", zap.Any("instance", instance), zap.Error(resultPushStreamEni.err))
+		failureResult.FailureReason = fmt.Sprintf("push-stream network interface: %s", resultPushStreamEni.err)
+		return failureResult
+	}
+	// TODO technical debt
+	pushStreamPublicIp := *resultPushStreamEni.eni.NetworkInterfaces[0].Association.PublicIp
 
 	/*
 		push-api
 	*/
-	chApi := make(chan provisionPushApiResult)
-	// TODO technical debt
-	pushStreamPublicIp := *resultPushStream.eni.NetworkInterfaces[0].Association.PublicIp
-	username := "app"
-	password := uniuri.New()
-	go p.pushApiProvisioner.Provision(instance, role, username, password, pushStreamPublicIp, chApi)
+	chApi := make(chan updatePushApiResult)
+	go p.pushApiProvisioner.Update(instance, pushStreamPublicIp, chApi)
 	resultPushApi := <-chApi
 	if resultPushApi.err != nil {
-		p.logger.Error("push-api: provision failure", zap.Any("instance", instance), zap.Error(resultPushApi.err))
-		// TODO deprovision
+		p.logger.Error("push-api: update failure", zap.Any("instance", instance), zap.Error(resultPushApi.err))
+		failureResult.FailureReason = fmt.Sprintf("push-api: %s", resultPushApi.err)
 		return failureResult
 	}
-	p.logger.Info("push-api: provision success", zap.Any("instance", instance))
+	p.logger.Info("push-api: update success", zap.Any("instance", instance))
+
+	chApiEni := make(chan networkInterfaceResult)
+	go p.pushApiProvisioner.ResolveNetworkInterface(instance, chApiEni)
+	resultPushApiEni := <-chApiEni
+	if resultPushApiEni.err != nil {
+		p.logger.Error("push-api: network interface failure", zap.Any("instance", instance), zap.Error(resultPushApiEni.err))
+		failureResult.FailureReason = fmt.Sprintf("push-api network interface: %s", resultPushApiEni.err)
+		return failureResult
+	}
+	// TODO technical debt
+	pushApiPrivateIp := *resultPushApiEni.eni.NetworkInterfaces[0].PrivateIpAddress
 
 	p.logger.Info(
-		"finishing provision for instance",
+		"finishing update for instance",
 		zap.Any("instance", instance),
-		zap.Any("resultPushRedis", resultPushRedis),
-		zap.Any("resultPushStream", resultPushStream),
-		zap.Any("resultPushApi", resultPushApi),
+		zap.String("pushStreamPublicIp", pushStreamPublicIp),
+		zap.String("pushApiPrivateIp", pushApiPrivateIp),
 	)
 
+	// the tasks were replaced, so push-api is on a new address
+	envVars := map[string]string{
+		provisioners.EnvVarEndpoint: fmt.Sprintf("http://%s:%s", pushApiPrivateIp, pushApiPort),
+	}
+
+	return &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		EnvVars:  envVars,
+		Status:   provisioners.PushServiceProvisionStatusSuccess,
+		// push-stream may have come up on another address too
+		Resources: map[string]string{
+			provisioners.ResourcePushStreamUrl: pushStreamUrl(pushStreamPublicIp),
+		},
+	}
+}
+
+/*
+	push-api is rolled to a task definition revision with a new password, which also puts it on a new address
+*/
+func (p *ecsProvisioner) RotateCredentials(instance *models.Instance) *provisioners.PushServiceProvisionResult {
+	p.logger.Info("starting credentials rotation for instance", zap.Any("instance", instance))
+
+	failureResult := &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceProvisionStatusFailure,
+		EnvVars:  map[string]string{},
+	}
+
+	/*
+		once push-api is rolled it may be running with the new password, so the password goes along with the
+		failure, to be kept by the instance, instead of being lost
+	*/
+	password := uniuri.New()
+	chApi := make(chan updatePushApiResult)
+	go p.pushApiProvisioner.RotatePassword(instance, password, chApi)
+	resultPushApi := <-chApi
+	if resultPushApi.err != nil {
+		p.logger.Error("push-api: credentials rotation failure", zap.Any("instance", instance), zap.Bool("rolled", resultPushApi.rolled), zap.Error(resultPushApi.err))
+		failureResult.FailureReason = fmt.Sprintf("push-api: %s", resultPushApi.err)
+		if resultPushApi.rolled {
+			failureResult.EnvVars[provisioners.EnvVarPassword] = password
+		}
+		return failureResult
+	}
+	p.logger.Info("push-api: credentials rotation success", zap.Any("instance", instance))
+
+	chApiEni := make(chan networkInterfaceResult)
+	go p.pushApiProvisioner.ResolveNetworkInterface(instance, chApiEni)
+	resultPushApiEni := <-chApiEni
+	if resultPushApiEni.err != nil {
+		p.logger.Error("push-api: network interface failure", zap.Any("instance", instance), zap.Error(resultPushApiEni.err))
+		failureResult.FailureReason = fmt.Sprintf("push-api network interface: %s", resultPushApiEni.err)
+		failureResult.EnvVars[provisioners.EnvVarPassword] = password
+		return failureResult
+	}
 	// TODO technical debt
-	pushApiPrivateIp := *resultPushApi.eni.NetworkInterfaces[0].PrivateIpAddress
+	pushApiPrivateIp := *resultPushApiEni.eni.NetworkInterfaces[0].PrivateIpAddress
+
+	p.logger.Info("finishing credentials rotation for instance", zap.Any("instance", instance), zap.String("pushApiPrivateIp", pushApiPrivateIp))
 
 	envVars := map[string]string{
 		provisioners.EnvVarEndpoint: fmt.Sprintf("http://%s:%s", pushApiPrivateIp, pushApiPort),
 		provisioners.EnvVarPassword: password,
-		provisioners.EnvVarUsername: username,
 	}
 
 	return &provisioners.PushServiceProvisionResult{
@@ -166,18 +449,114 @@ func (p *ecsProvisioner) Deprovision(instance *models.Instance) *provisioners.Pu
 		zap.Any("resultPushApi", resultPushApi),
 	)
 
+	// a provision interrupted before being deprovisioned must not be resumed
+	_ = p.stepStore.DelSteps(instance.Name)
+
 	return &provisioners.PushServiceDeprovisionResult{
 		Instance: instance,
 		Status:   provisioners.PushServiceDeprovisionStatusSuccess,
 	}
 }
 
+func (p *ecsProvisioner) Inspect(instance *models.Instance) *provisioners.PushServiceInspectResult {
+	failureResult := &provisioners.PushServiceInspectResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceInspectStatusFailure,
+	}
+
+	serviceNames := map[string]string{
+		provisioners.ComponentPushRedis:  pushRedisWithInstance(instance.Name),
+		provisioners.ComponentPushStream: pushStreamWithInstance(instance.Name),
+		provisioners.ComponentPushApi:    pushApiWithInstance(instance.Name),
+	}
+
+	var components []*provisioners.PushServiceComponentState
+	for _, component := range []string{provisioners.ComponentPushRedis, provisioners.ComponentPushStream, provisioners.ComponentPushApi} {
+		describedService, err := describeService(serviceNames[component], p.provisionerConfig)
+		if err != nil {
+			p.logger.Error("failed to describe service to inspect instance", zap.String("component", component), zap.Any("instance", instance), zap.Error(err))
+			return failureResult
+		}
+
+		state := &provisioners.PushServiceComponentState{Name: component}
+		// deleted services are still described for a while, as INACTIVE
+		if len(describedService.Services) > 0 && *describedService.Services[0].Status != "INACTIVE" {
+			state.Exists = true
+			state.RunningCount = *describedService.Services[0].RunningCount
+		}
+		components = append(components, state)
+	}
+
+	return &provisioners.PushServiceInspectResult{
+		Instance:   instance,
+		Components: components,
+		Status:     provisioners.PushServiceInspectStatusSuccess,
+	}
+}
+
+/*
+	The services that still exist are taken as existing steps, so only the missing ones are provisioned, and a
+	failure only rolls back what the recreate itself created.
+	push-api is addressed to the public IP of push-stream, so when push-stream is missing push-api is created again too.
+*/
+func (p *ecsProvisioner) Recreate(instance *models.Instance, envVars map[string]string) *provisioners.PushServiceProvisionResult {
+	p.logger.Info("starting recreate for instance", zap.Any("instance", instance))
+
+	failureResult := &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceProvisionStatusFailure,
+		EnvVars:  map[string]string{},
+	}
+
+	inspectResult := p.Inspect(instance)
+	if inspectResult.Status != provisioners.PushServiceInspectStatusSuccess {
+		failureResult.FailureReason = "failed to inspect the components to recreate"
+		return failureResult
+	}
+
+	stepStatus := func(component string) provisioners.ProvisionStepStatus {
+		if inspectResult.Component(component).Exists {
+			return provisioners.ProvisionStepStatusExisting
+		}
+		return ""
+	}
+
+	steps := map[string]*provisioners.ProvisionStep{
+		stepPushRedis:  {Name: stepPushRedis, Status: stepStatus(provisioners.ComponentPushRedis)},
+		stepPushStream: {Name: stepPushStream, Status: stepStatus(provisioners.ComponentPushStream)},
+		stepPushApi:    {Name: stepPushApi, Status: stepStatus(provisioners.ComponentPushApi)},
+	}
+	if password := envVars[provisioners.EnvVarPassword]; password != "" {
+		steps[stepCredentials] = &provisioners.ProvisionStep{Name: stepCredentials, Status: provisioners.ProvisionStepStatusExisting, Value: password}
+	}
+	if steps[stepPushStream].Status != provisioners.ProvisionStepStatusExisting && steps[stepPushApi].Status == provisioners.ProvisionStepStatusExisting {
+		// taken as interrupted, so the existing push-api is removed before being created again
+		steps[stepPushApi].Status = provisioners.ProvisionStepStatusStarted
+	}
+
+	for name, step := range steps {
+		if step.Status == "" {
+			delete(steps, name)
+			continue
+		}
+		err := p.stepStore.SetStep(instance.Name, step)
+		if err != nil {
+			p.logger.Error("failed while recreating instance, failed to persist provision step", zap.Any("instance", instance), zap.Error(err))
+			failureResult.FailureReason = fmt.Sprintf("provision steps: %s", err)
+			return failureResult
+		}
+	}
+
+	return p.provision(instance, steps)
+}
+
 func NewEcsPushServiceProvisioner(
 	logger *zap.Logger,
 	provisionerConfig *EcsProvisionerConfig,
 	pushRedisProvisioner EcsPushRedisProvisioner,
 	pushStreamProvisioner EcsPushStreamProvisioner,
 	pushApiProvisioner EcsPushApiProvisioner,
+	stepStore provisioners.ProvisionStepStore,
 ) (provisioners.PushServiceProvisioner, error) {
 	return &ecsProvisioner{
 		logger:                logger,
@@ -185,5 +564,6 @@ func NewEcsPushServiceProvisioner(
 		pushRedisProvisioner:  pushRedisProvisioner,
 		pushStreamProvisioner: pushStreamProvisioner,
 		pushApiProvisioner:    pushApiProvisioner,
+		stepStore:             stepStore,
 	}, nil
 }
@@ -0,0 +1,722 @@
+package ecs_provisioner_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/aws/aws-sdk-go/service/servicediscovery/servicediscoveryiface"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/provisioners/ecs_provisioner"
+)
+
+/*
+	the aws interfaces are huge, so the fakes embed them and only implement what the provisioner calls
+*/
+type (
+	fakeIam struct {
+		iamiface.IAMAPI
+		err error
+	}
+
+	fakeEcs struct {
+		ecsiface.ECSAPI
+		calls                    []string
+		taskDefinitions          []*ecs.RegisterTaskDefinitionInput
+		services                 []*ecs.CreateServiceInput
+		failCreateService        string
+		failDeregisterDefinition error
+		deleted                  map[string]bool
+		serviceArns              []string
+		serviceCreatedAt         *time.Time
+		taskDefinitionArns       []string
+		taskDefinitionTags       map[string][]*ecs.Tag
+	}
+
+	fakeEc2 struct {
+		ec2iface.EC2API
+	}
+
+	fakeServiceDiscovery struct {
+		servicediscoveryiface.ServiceDiscoveryAPI
+		calls    []string
+		services []string
+	}
+)
+
+func (f *fakeIam) GetRole(input *iam.GetRoleInput) (*iam.GetRoleOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &iam.GetRoleOutput{Role: &iam.Role{Arn: aws.String("role-arn")}}, nil
+}
+
+func (f *fakeEcs) RegisterTaskDefinition(input *ecs.RegisterTaskDefinitionInput) (*ecs.RegisterTaskDefinitionOutput, error) {
+	f.calls = append(f.calls, "RegisterTaskDefinition "+*input.Family)
+	f.taskDefinitions = append(f.taskDefinitions, input)
+	return &ecs.RegisterTaskDefinitionOutput{TaskDefinition: &ecs.TaskDefinition{TaskDefinitionArn: input.Family}}, nil
+}
+
+// describes the last registered revision of the family, when there is one
+func (f *fakeEcs) DescribeTaskDefinition(input *ecs.DescribeTaskDefinitionInput) (*ecs.DescribeTaskDefinitionOutput, error) {
+	taskDefinition := &ecs.TaskDefinition{TaskDefinitionArn: input.TaskDefinition}
+	tags := f.taskDefinitionTags[*input.TaskDefinition]
+	for _, registered := range f.taskDefinitions {
+		if *registered.Family == *input.TaskDefinition {
+			taskDefinition.ContainerDefinitions = registered.ContainerDefinitions
+			tags = registered.Tags
+		}
+	}
+	return &ecs.DescribeTaskDefinitionOutput{TaskDefinition: taskDefinition, Tags: tags}, nil
+}
+
+func (f *fakeEcs) DeregisterTaskDefinition(input *ecs.DeregisterTaskDefinitionInput) (*ecs.DeregisterTaskDefinitionOutput, error) {
+	f.calls = append(f.calls, "DeregisterTaskDefinition "+*input.TaskDefinition)
+	if f.failDeregisterDefinition != nil {
+		return nil, f.failDeregisterDefinition
+	}
+	return &ecs.DeregisterTaskDefinitionOutput{}, nil
+}
+
+func (f *fakeEcs) CreateService(input *ecs.CreateServiceInput) (*ecs.CreateServiceOutput, error) {
+	f.calls = append(f.calls, "CreateService "+*input.ServiceName)
+	f.services = append(f.services, input)
+	if *input.ServiceName == f.failCreateService {
+		return nil, errors.New("some error")
+	}
+	return &ecs.CreateServiceOutput{Service: &ecs.Service{ServiceName: input.ServiceName, ServiceArn: aws.String("arn/" + *input.ServiceName)}}, nil
+}
+
+func (f *fakeEcs) DeleteService(input *ecs.DeleteServiceInput) (*ecs.DeleteServiceOutput, error) {
+	f.calls = append(f.calls, "DeleteService "+*input.Service)
+	f.deleted[*input.Service] = true
+	return &ecs.DeleteServiceOutput{}, nil
+}
+
+// the services are described either by name or by arn
+func (f *fakeEcs) DescribeServices(input *ecs.DescribeServicesInput) (*ecs.DescribeServicesOutput, error) {
+	output := &ecs.DescribeServicesOutput{}
+	for _, service := range input.Services {
+		name := (*service)[strings.LastIndex(*service, "/")+1:]
+		status := "ACTIVE"
+		if f.deleted[name] {
+			status = "INACTIVE"
+		}
+		output.Services = append(output.Services, &ecs.Service{
+			ServiceName:  aws.String(name),
+			RunningCount: aws.Int64(1),
+			DesiredCount: aws.Int64(1),
+			Status:       aws.String(status),
+			Deployments:  []*ecs.Deployment{{Status: aws.String("PRIMARY")}},
+			CreatedAt:    f.serviceCreatedAt,
+		})
+	}
+	return output, nil
+}
+
+func (f *fakeEcs) UpdateService(input *ecs.UpdateServiceInput) (*ecs.UpdateServiceOutput, error) {
+	f.calls = append(f.calls, "UpdateService "+*input.Service)
+	return &ecs.UpdateServiceOutput{Service: &ecs.Service{ServiceName: input.Service}}, nil
+}
+
+// every service has a single task, attached to a network interface named after the service
+func (f *fakeEcs) ListTasks(input *ecs.ListTasksInput) (*ecs.ListTasksOutput, error) {
+	return &ecs.ListTasksOutput{TaskArns: []*string{aws.String("task/" + *input.ServiceName)}}, nil
+}
+
+func (f *fakeEcs) DescribeTasks(input *ecs.DescribeTasksInput) (*ecs.DescribeTasksOutput, error) {
+	serviceName := strings.TrimPrefix(*input.Tasks[0], "task/")
+	return &ecs.DescribeTasksOutput{
+		Tasks: []*ecs.Task{
+			{
+				Attachments: []*ecs.Attachment{
+					{Details: []*ecs.KeyValuePair{{Name: aws.String("networkInterfaceId"), Value: aws.String("eni/" + serviceName)}}},
+				},
+			},
+		},
+	}, nil
+}
+
+func (f *fakeEc2) DescribeNetworkInterfaces(input *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	privateIp := "10.0.0.1"
+	if strings.HasPrefix(*input.NetworkInterfaceIds[0], "eni/push-api") {
+		privateIp = "10.0.0.2"
+	}
+	return &ec2.DescribeNetworkInterfacesOutput{
+		NetworkInterfaces: []*ec2.NetworkInterface{
+			{
+				PrivateIpAddress: aws.String(privateIp),
+				Association:      &ec2.NetworkInterfaceAssociation{PublicIp: aws.String("1.2.3.4")},
+			},
+		},
+	}, nil
+}
+
+func (f *fakeEcs) ListServicesPages(input *ecs.ListServicesInput, fn func(*ecs.ListServicesOutput, bool) bool) error {
+	fn(&ecs.ListServicesOutput{ServiceArns: aws.StringSlice(f.serviceArns)}, true)
+	return nil
+}
+
+func (f *fakeEcs) ListTaskDefinitionFamiliesPages(input *ecs.ListTaskDefinitionFamiliesInput, fn func(*ecs.ListTaskDefinitionFamiliesOutput, bool) bool) error {
+	output := &ecs.ListTaskDefinitionFamiliesOutput{}
+	for _, arn := range f.taskDefinitionArns {
+		familyRevision := arn[strings.LastIndex(arn, "/")+1:]
+		output.Families = append(output.Families, aws.String(familyRevision[:strings.LastIndex(familyRevision, ":")]))
+	}
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeEcs) ListTaskDefinitionsPages(input *ecs.ListTaskDefinitionsInput, fn func(*ecs.ListTaskDefinitionsOutput, bool) bool) error {
+	output := &ecs.ListTaskDefinitionsOutput{}
+	for _, arn := range f.taskDefinitionArns {
+		if strings.Contains(arn, "/"+*input.FamilyPrefix) {
+			output.TaskDefinitionArns = append(output.TaskDefinitionArns, aws.String(arn))
+		}
+	}
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeServiceDiscovery) ListServicesPages(input *servicediscovery.ListServicesInput, fn func(*servicediscovery.ListServicesOutput, bool) bool) error {
+	output, _ := f.ListServices(input)
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeServiceDiscovery) CreateService(input *servicediscovery.CreateServiceInput) (*servicediscovery.CreateServiceOutput, error) {
+	f.calls = append(f.calls, "CreateService "+*input.Name)
+	f.services = append(f.services, *input.Name)
+	return &servicediscovery.CreateServiceOutput{Service: &servicediscovery.Service{Id: input.Name, Arn: input.Name}}, nil
+}
+
+func (f *fakeServiceDiscovery) ListServices(input *servicediscovery.ListServicesInput) (*servicediscovery.ListServicesOutput, error) {
+	output := &servicediscovery.ListServicesOutput{}
+	for _, name := range f.services {
+		output.Services = append(output.Services, &servicediscovery.ServiceSummary{Id: aws.String(name), Name: aws.String(name)})
+	}
+	return output, nil
+}
+
+func (f *fakeServiceDiscovery) ListInstances(input *servicediscovery.ListInstancesInput) (*servicediscovery.ListInstancesOutput, error) {
+	return &servicediscovery.ListInstancesOutput{
+		Instances: []*servicediscovery.InstanceSummary{{Id: aws.String("task-1")}},
+	}, nil
+}
+
+func (f *fakeServiceDiscovery) DeregisterInstance(input *servicediscovery.DeregisterInstanceInput) (*servicediscovery.DeregisterInstanceOutput, error) {
+	f.calls = append(f.calls, "DeregisterInstance "+*input.ServiceId)
+	return &servicediscovery.DeregisterInstanceOutput{}, nil
+}
+
+func (f *fakeServiceDiscovery) DeleteService(input *servicediscovery.DeleteServiceInput) (*servicediscovery.DeleteServiceOutput, error) {
+	f.calls = append(f.calls, "DeleteService "+*input.Id)
+	return &servicediscovery.DeleteServiceOutput{}, nil
+}
+
+var _ = Describe("EcsProvisioner", func() {
+	newConfig := func() *viper.Viper {
+		config := viper.New()
+		config.Set("provisioner.ecs.cluster", "pushaas-cluster")
+		config.Set("provisioner.ecs.security_group", "sg-1")
+		config.Set("provisioner.ecs.subnet", "subnet-1")
+		config.Set("provisioner.ecs.dns_namespace", "ns-1")
+		config.Set("plans", []map[string]interface{}{
+			{"name": "small", "cpu": 512, "memory": 1024},
+			{"name": "large", "cpu": 1024, "memory": 2048, "max_channels": 100, "max_subscribers": 1000},
+		})
+		return config
+	}
+
+	// keeps the steps in memory, as redis would
+	newStepStore := func(steps map[string]*provisioners.ProvisionStep) *mocks.ProvisionStepStoreMock {
+		return &mocks.ProvisionStepStoreMock{
+			GetStepsFunc: func(instanceName string) (map[string]*provisioners.ProvisionStep, error) {
+				return steps, nil
+			},
+			SetStepFunc: func(instanceName string, step *provisioners.ProvisionStep) error {
+				steps[step.Name] = step
+				return nil
+			},
+			DelStepsFunc: func(instanceName string) error {
+				return nil
+			},
+		}
+	}
+
+	newProvisioner := func(iamSvc iamiface.IAMAPI, ecsSvc ecsiface.ECSAPI, serviceDiscoverySvc servicediscoveryiface.ServiceDiscoveryAPI, stepStore provisioners.ProvisionStepStore) provisioners.PushServiceProvisioner {
+		provisionerConfig, err := ecs_provisioner.NewEcsProvisionerConfig(newConfig(), iamSvc, ecsSvc, &fakeEc2{}, serviceDiscoverySvc)
+		Expect(err).NotTo(HaveOccurred())
+
+		provisioner, err := ecs_provisioner.NewEcsPushServiceProvisioner(
+			logger,
+			provisionerConfig,
+			ecs_provisioner.NewEcsPushRedisProvisioner(logger, provisionerConfig),
+			ecs_provisioner.NewEcsPushStreamProvisioner(logger, provisionerConfig),
+			ecs_provisioner.NewEcsPushApiProvisioner(logger, provisionerConfig),
+			stepStore,
+		)
+		Expect(err).NotTo(HaveOccurred())
+		return provisioner
+	}
+
+	Describe("Provision", func() {
+		It("sizes the tasks by the plan of the instance, running a single push-stream", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "large"}
+			ecsSvc := &fakeEcs{failCreateService: "push-api-instance-1", deleted: map[string]bool{}}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, &fakeServiceDiscovery{}, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			provisioner.Provision(instance)
+
+			// assert
+			pushStreamDefinition := ecsSvc.taskDefinitions[0]
+			Expect(*pushStreamDefinition.Family).To(Equal("push-stream-instance-1"))
+			Expect(*pushStreamDefinition.Cpu).To(Equal("1024"))
+			Expect(*pushStreamDefinition.Memory).To(Equal("2048"))
+			Expect(*pushStreamDefinition.ContainerDefinitions[0].Cpu).To(Equal(int64(512)))
+			Expect(pushStreamDefinition.ContainerDefinitions[0].Environment).To(Equal([]*ecs.KeyValuePair{
+				{Name: aws.String("PUSHSTREAM_MAX_NUMBER_OF_CHANNELS"), Value: aws.String("100")},
+				{Name: aws.String("PUSHSTREAM_MAX_SUBSCRIBERS_PER_CHANNEL"), Value: aws.String("1000")},
+			}))
+
+			pushApiDefinition := ecsSvc.taskDefinitions[1]
+			Expect(*pushApiDefinition.Family).To(Equal("push-api-instance-1"))
+			Expect(pushApiDefinition.Tags).To(Equal([]*ecs.Tag{{Key: aws.String("pushaas-cluster"), Value: aws.String("pushaas-cluster")}}))
+			Expect(*pushApiDefinition.Cpu).To(Equal("512"))
+			Expect(*pushApiDefinition.Memory).To(Equal("1024"))
+			Expect(*pushApiDefinition.ContainerDefinitions[0].Cpu).To(Equal(int64(512)))
+
+			Expect(*ecsSvc.services[1].ServiceName).To(Equal("push-stream-instance-1"))
+			Expect(*ecsSvc.services[1].DesiredCount).To(Equal(int64(1)))
+		})
+
+		It("indicates failure when the plan is not configured", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "unknown"}
+			ecsSvc := &fakeEcs{deleted: map[string]bool{}}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, &fakeServiceDiscovery{}, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusFailure))
+			Expect(result.Instance.Rollback).To(Equal(models.InstanceRollbackCompleted))
+		})
+
+		It("removes what was already created in reverse order when a step fails", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "small"}
+			ecsSvc := &fakeEcs{failCreateService: "push-stream-instance-1", deleted: map[string]bool{}}
+			serviceDiscoverySvc := &fakeServiceDiscovery{}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, serviceDiscoverySvc, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusFailure))
+			Expect(result.EnvVars).To(BeEmpty())
+			Expect(result.Instance.Rollback).To(Equal(models.InstanceRollbackCompleted))
+			Expect(result.FailureReason).To(Equal("push-stream: some error"))
+			Expect(result.Resources).To(Equal(map[string]string{
+				"push-redis.service-arn":          "arn/push-redis-instance-1",
+				"push-redis.discovery-service-id": "push-redis-instance-1",
+			}))
+			Expect(ecsSvc.calls).To(Equal([]string{
+				"CreateService push-redis-instance-1",
+				"RegisterTaskDefinition push-stream-instance-1",
+				"CreateService push-stream-instance-1",
+				"DeregisterTaskDefinition push-stream-instance-1",
+				"DeleteService push-redis-instance-1",
+			}))
+			Expect(serviceDiscoverySvc.calls).To(Equal([]string{
+				"CreateService push-redis-instance-1",
+				"CreateService push-stream-instance-1",
+				"DeregisterInstance push-stream-instance-1",
+				"DeleteService push-stream-instance-1",
+				"DeregisterInstance push-redis-instance-1",
+				"DeleteService push-redis-instance-1",
+			}))
+		})
+
+		It("keeps undoing the remaining steps and records when the rollback fails", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "small"}
+			ecsSvc := &fakeEcs{
+				failCreateService:        "push-stream-instance-1",
+				failDeregisterDefinition: errors.New("some error"),
+				deleted:                  map[string]bool{},
+			}
+			serviceDiscoverySvc := &fakeServiceDiscovery{}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, serviceDiscoverySvc, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusFailure))
+			Expect(result.Instance.Rollback).To(Equal(models.InstanceRollbackFailed))
+			Expect(ecsSvc.calls).To(ContainElement("DeleteService push-redis-instance-1"))
+			Expect(serviceDiscoverySvc.calls).To(ContainElement("DeleteService push-redis-instance-1"))
+		})
+
+		It("resumes from the last completed step of a previous attempt", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "small"}
+			ecsSvc := &fakeEcs{failCreateService: "push-api-instance-1", deleted: map[string]bool{}}
+			serviceDiscoverySvc := &fakeServiceDiscovery{
+				services: []string{"push-redis-instance-1", "push-stream-instance-1", "push-api-instance-1"},
+			}
+			stepStore := newStepStore(map[string]*provisioners.ProvisionStep{
+				"credentials":     {Name: "credentials", Status: provisioners.ProvisionStepStatusCompleted, Value: "some-password"},
+				"push-redis":      {Name: "push-redis", Status: provisioners.ProvisionStepStatusCompleted},
+				"push-stream":     {Name: "push-stream", Status: provisioners.ProvisionStepStatusCompleted},
+				"push-stream-eni": {Name: "push-stream-eni", Status: provisioners.ProvisionStepStatusCompleted, Value: "1.2.3.4"},
+				"push-api":        {Name: "push-api", Status: provisioners.ProvisionStepStatusStarted},
+			})
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, serviceDiscoverySvc, stepStore)
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusFailure))
+			Expect(result.Instance.Rollback).To(Equal(models.InstanceRollbackCompleted))
+			Expect(ecsSvc.calls).To(Equal([]string{
+				// what the interrupted attempt of push-api left behind
+				"DeleteService push-api-instance-1",
+				"DeregisterTaskDefinition push-api-instance-1",
+				// push-api again, with the persisted values
+				"RegisterTaskDefinition push-api-instance-1",
+				"CreateService push-api-instance-1",
+				// rollback
+				"DeregisterTaskDefinition push-api-instance-1",
+				"DeleteService push-stream-instance-1",
+				"DeregisterTaskDefinition push-stream-instance-1",
+				"DeleteService push-redis-instance-1",
+			}))
+
+			environment := map[string]string{}
+			for _, kv := range ecsSvc.taskDefinitions[0].ContainerDefinitions[0].Environment {
+				environment[*kv.Name] = *kv.Value
+			}
+			Expect(environment["PUSHAPI_API__BASIC_AUTH_PASSWORD"]).To(Equal("some-password"))
+			Expect(environment["PUSHAPI_PUSH_STREAM__URL"]).To(Equal("http://1.2.3.4:9080"))
+			Expect(stepStore.DelStepsCalls()).To(HaveLen(1))
+		})
+
+		It("does not roll back when nothing was created", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "small"}
+			ecsSvc := &fakeEcs{deleted: map[string]bool{}}
+			serviceDiscoverySvc := &fakeServiceDiscovery{}
+			provisioner := newProvisioner(&fakeIam{err: errors.New("some error")}, ecsSvc, serviceDiscoverySvc, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusFailure))
+			Expect(result.Instance.Rollback).To(Equal(models.InstanceRollbackNone))
+			Expect(ecsSvc.calls).To(BeEmpty())
+			Expect(serviceDiscoverySvc.calls).To(BeEmpty())
+		})
+	})
+
+	Describe("Update", func() {
+		It("rolls push-stream and then push-api, pointed to the new push-stream address", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "small"}
+			ecsSvc := &fakeEcs{
+				deleted: map[string]bool{},
+				taskDefinitions: []*ecs.RegisterTaskDefinitionInput{
+					{
+						Family: aws.String("push-api-instance-1"),
+						ContainerDefinitions: []*ecs.ContainerDefinition{
+							{
+								Name: aws.String("push-api"),
+								Environment: []*ecs.KeyValuePair{
+									{Name: aws.String("PUSHAPI_PUSH_STREAM__URL"), Value: aws.String("http://9.9.9.9:9080")},
+								},
+							},
+						},
+					},
+				},
+			}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, &fakeServiceDiscovery{}, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.Update(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+			Expect(result.EnvVars).To(Equal(map[string]string{
+				provisioners.EnvVarEndpoint: "http://10.0.0.2:8080",
+			}))
+			Expect(ecsSvc.calls).To(Equal([]string{
+				"RegisterTaskDefinition push-stream-instance-1",
+				"UpdateService push-stream-instance-1",
+				"DeregisterTaskDefinition push-stream-instance-1",
+				"RegisterTaskDefinition push-api-instance-1",
+				"UpdateService push-api-instance-1",
+				"DeregisterTaskDefinition push-api-instance-1",
+			}))
+
+			pushApiRevision := ecsSvc.taskDefinitions[len(ecsSvc.taskDefinitions)-1]
+			Expect(*pushApiRevision.Cpu).To(Equal("256"))
+			Expect(*pushApiRevision.Memory).To(Equal("512"))
+			Expect(*pushApiRevision.ContainerDefinitions[0].Environment[0].Value).To(Equal("http://1.2.3.4:9080"))
+		})
+
+		It("indicates failure when the plan has no size", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "unknown"}
+			ecsSvc := &fakeEcs{deleted: map[string]bool{}}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, &fakeServiceDiscovery{}, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.Update(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusFailure))
+			Expect(ecsSvc.calls).To(BeEmpty())
+		})
+	})
+
+	Describe("RotateCredentials", func() {
+		// push-api is running its first revision
+		newEcsWithPushApi := func(failDeregisterDefinition error) *fakeEcs {
+			return &fakeEcs{
+				deleted:                  map[string]bool{},
+				failDeregisterDefinition: failDeregisterDefinition,
+				taskDefinitions: []*ecs.RegisterTaskDefinitionInput{
+					{
+						Family: aws.String("push-api-instance-1"),
+						ContainerDefinitions: []*ecs.ContainerDefinition{
+							{
+								Name: aws.String("push-api"),
+								Environment: []*ecs.KeyValuePair{
+									{Name: aws.String("PUSHAPI_PUSH_STREAM__URL"), Value: aws.String("http://1.2.3.4:9080")},
+									{Name: aws.String("PUSHAPI_API__BASIC_AUTH_PASSWORD"), Value: aws.String("old-password")},
+								},
+							},
+						},
+					},
+				},
+			}
+		}
+
+		It("rolls push-api with a new password and returns it with the new push-api address", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "small"}
+			ecsSvc := newEcsWithPushApi(nil)
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, &fakeServiceDiscovery{}, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.(provisioners.PushServiceCredentialRotator).RotateCredentials(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+			Expect(result.EnvVars[provisioners.EnvVarEndpoint]).To(Equal("http://10.0.0.2:8080"))
+			Expect(result.EnvVars[provisioners.EnvVarPassword]).NotTo(BeEmpty())
+			Expect(result.EnvVars[provisioners.EnvVarPassword]).NotTo(Equal("old-password"))
+			Expect(ecsSvc.calls).To(Equal([]string{
+				"RegisterTaskDefinition push-api-instance-1",
+				"UpdateService push-api-instance-1",
+				"DeregisterTaskDefinition push-api-instance-1",
+			}))
+
+			environment := ecsSvc.taskDefinitions[len(ecsSvc.taskDefinitions)-1].ContainerDefinitions[0].Environment
+			Expect(*environment[0].Value).To(Equal("http://1.2.3.4:9080"))
+			Expect(*environment[1].Value).To(Equal(result.EnvVars[provisioners.EnvVarPassword]))
+		})
+
+		It("does not fail when the previous revision of push-api fails to be deregistered", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "small"}
+			ecsSvc := newEcsWithPushApi(errors.New("some error"))
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, &fakeServiceDiscovery{}, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.(provisioners.PushServiceCredentialRotator).RotateCredentials(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+			Expect(result.EnvVars[provisioners.EnvVarPassword]).NotTo(BeEmpty())
+			Expect(ecsSvc.calls).To(ContainElement("DeregisterTaskDefinition push-api-instance-1"))
+		})
+	})
+
+	Describe("Recreate", func() {
+		// push-api went missing, the other services are still up
+		newEcsMissingPushApi := func(failCreateService string) *fakeEcs {
+			return &fakeEcs{
+				failCreateService: failCreateService,
+				deleted:           map[string]bool{"push-api-instance-1": true},
+			}
+		}
+		envVars := map[string]string{provisioners.EnvVarPassword: "some-password"}
+
+		It("creates only the missing components, with the credentials of the instance", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "small"}
+			ecsSvc := newEcsMissingPushApi("")
+			serviceDiscoverySvc := &fakeServiceDiscovery{services: []string{"push-redis-instance-1", "push-stream-instance-1"}}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, serviceDiscoverySvc, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.(provisioners.PushServiceRecreator).Recreate(instance, envVars)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+			Expect(result.EnvVars[provisioners.EnvVarPassword]).To(Equal("some-password"))
+			Expect(ecsSvc.calls).To(Equal([]string{
+				"RegisterTaskDefinition push-api-instance-1",
+				"CreateService push-api-instance-1",
+			}))
+		})
+
+		It("does not remove the components that already existed when recreating the missing ones fails", func() {
+			// arrange
+			instance := &models.Instance{Name: "instance-1", Plan: "small"}
+			ecsSvc := newEcsMissingPushApi("push-api-instance-1")
+			serviceDiscoverySvc := &fakeServiceDiscovery{services: []string{"push-redis-instance-1", "push-stream-instance-1"}}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, serviceDiscoverySvc, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			result := provisioner.(provisioners.PushServiceRecreator).Recreate(instance, envVars)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusFailure))
+			Expect(result.Instance.Rollback).To(Equal(models.InstanceRollbackCompleted))
+			Expect(ecsSvc.calls).To(Equal([]string{
+				"RegisterTaskDefinition push-api-instance-1",
+				"CreateService push-api-instance-1",
+				// rollback, of push-api only
+				"DeregisterTaskDefinition push-api-instance-1",
+			}))
+			Expect(serviceDiscoverySvc.calls).NotTo(ContainElement("DeleteService push-redis-instance-1"))
+			Expect(serviceDiscoverySvc.calls).NotTo(ContainElement("DeleteService push-stream-instance-1"))
+		})
+	})
+
+	Describe("ListResources", func() {
+		It("lists the resources of the instances, in an order safe to delete", func() {
+			// arrange
+			createdAt := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+			ecsSvc := &fakeEcs{
+				deleted: map[string]bool{},
+				serviceArns: []string{
+					"arn:aws:ecs:us-east-1:123:service/pushaas/push-api-instance-1",
+					"arn:aws:ecs:us-east-1:123:service/pushaas/other-service",
+				},
+				serviceCreatedAt: &createdAt,
+				taskDefinitionArns: []string{
+					"arn:aws:ecs:us-east-1:123:task-definition/push-api-instance-1:1",
+					"arn:aws:ecs:us-east-1:123:task-definition/push-redis:1",
+				},
+				taskDefinitionTags: map[string][]*ecs.Tag{
+					"push-api-instance-1": {{Key: aws.String("pushaas-cluster"), Value: aws.String("pushaas-cluster")}},
+				},
+			}
+			serviceDiscoverySvc := &fakeServiceDiscovery{services: []string{"push-api-instance-1"}}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, serviceDiscoverySvc, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			resources, err := provisioner.(provisioners.PushServiceResourceCollector).ListResources()
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resources).To(Equal([]*provisioners.PushServiceResource{
+				{Kind: "ecs-service", Name: "push-api-instance-1", InstanceName: "instance-1", CreatedAt: &createdAt},
+				{Kind: "service-discovery", Name: "push-api-instance-1", InstanceName: "instance-1"},
+				{Kind: "task-definition", Name: "push-api-instance-1", InstanceName: "instance-1"},
+			}))
+		})
+
+		It("does not list the task definitions registered by other deployments", func() {
+			// arrange
+			ecsSvc := &fakeEcs{
+				deleted: map[string]bool{},
+				taskDefinitionArns: []string{
+					"arn:aws:ecs:us-east-1:123:task-definition/push-api-instance-1:1",
+					"arn:aws:ecs:us-east-1:123:task-definition/push-api-instance-2:1",
+				},
+				taskDefinitionTags: map[string][]*ecs.Tag{
+					"push-api-instance-1": {{Key: aws.String("pushaas-cluster"), Value: aws.String("other-cluster")}},
+				},
+			}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, &fakeServiceDiscovery{}, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			resources, err := provisioner.(provisioners.PushServiceResourceCollector).ListResources()
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resources).To(BeEmpty())
+		})
+	})
+
+	Describe("DeleteResource", func() {
+		It("deregisters every revision of the task definition family, and only of it", func() {
+			// arrange
+			ecsSvc := &fakeEcs{
+				deleted: map[string]bool{},
+				taskDefinitionArns: []string{
+					"arn:aws:ecs:us-east-1:123:task-definition/push-api-instance-1:1",
+					"arn:aws:ecs:us-east-1:123:task-definition/push-api-instance-1:2",
+					"arn:aws:ecs:us-east-1:123:task-definition/push-api-instance-10:1",
+				},
+			}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, &fakeServiceDiscovery{}, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			err := provisioner.(provisioners.PushServiceResourceCollector).DeleteResource(&provisioners.PushServiceResource{
+				Kind:         "task-definition",
+				Name:         "push-api-instance-1",
+				InstanceName: "instance-1",
+			})
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ecsSvc.calls).To(Equal([]string{
+				"DeregisterTaskDefinition arn:aws:ecs:us-east-1:123:task-definition/push-api-instance-1:1",
+				"DeregisterTaskDefinition arn:aws:ecs:us-east-1:123:task-definition/push-api-instance-1:2",
+			}))
+		})
+
+		It("deletes the service and waits for it to go down", func() {
+			// arrange
+			ecsSvc := &fakeEcs{deleted: map[string]bool{}}
+			provisioner := newProvisioner(&fakeIam{}, ecsSvc, &fakeServiceDiscovery{}, newStepStore(map[string]*provisioners.ProvisionStep{}))
+
+			// act
+			err := provisioner.(provisioners.PushServiceResourceCollector).DeleteResource(&provisioners.PushServiceResource{
+				Kind:         "ecs-service",
+				Name:         "push-api-instance-1",
+				InstanceName: "instance-1",
+			})
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ecsSvc.calls).To(Equal([]string{"DeleteService push-api-instance-1"}))
+		})
+	})
+})
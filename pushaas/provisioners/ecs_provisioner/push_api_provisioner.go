@@ -19,8 +19,12 @@ const pushApiPort = "8080"
 
 type (
 	EcsPushApiProvisioner interface {
-		Provision(*models.Instance, *iam.GetRoleOutput, string, string, string, chan provisionPushApiResult)
+		Provision(*models.Instance, *provisionSaga, *iam.GetRoleOutput, string, string, string, chan provisionPushApiResult)
 		Deprovision(*models.Instance, chan deprovisionPushApiResult)
+		Update(*models.Instance, string, chan updatePushApiResult)
+		RotatePassword(*models.Instance, string, chan updatePushApiResult)
+		ResolveNetworkInterface(*models.Instance, chan networkInterfaceResult)
+		RegisterRollback(*models.Instance, *provisionSaga)
 	}
 
 	ecsPushApiProvisioner struct {
@@ -29,13 +33,20 @@ type (
 	}
 
 	provisionPushApiResult struct {
-		eni              *ec2.DescribeNetworkInterfacesOutput
 		service          *ecs.CreateServiceOutput
 		serviceDiscovery *servicediscovery.CreateServiceOutput
 		taskDefinition   *ecs.RegisterTaskDefinitionOutput
 		err              error
 	}
 
+	updatePushApiResult struct {
+		service        *ecs.UpdateServiceOutput
+		taskDefinition *ecs.RegisterTaskDefinitionOutput
+		// the service was rolled to the new revision, which it may be running even if a later step failed
+		rolled bool
+		err    error
+	}
+
 	deprovisionPushApiResult struct {
 		service          *ecs.DeleteServiceOutput
 		serviceDiscovery *servicediscovery.DeleteServiceOutput
@@ -53,7 +64,7 @@ func pushApiWithInstance(instanceName string) string {
 	provision
 	===========================================================================
 */
-func (p *ecsPushApiProvisioner) Provision(instance *models.Instance, role *iam.GetRoleOutput, username string, password string, pushStreamPublicIp string, ch chan provisionPushApiResult) {
+func (p *ecsPushApiProvisioner) Provision(instance *models.Instance, saga *provisionSaga, role *iam.GetRoleOutput, username string, password string, pushStreamPublicIp string, ch chan provisionPushApiResult) {
 	var err error
 
 	// create task definition
@@ -63,6 +74,7 @@ func (p *ecsPushApiProvisioner) Provision(instance *models.Instance, role *iam.G
 		return
 	}
 	p.logger.Debug("[push-api] did create task definition")
+	p.registerTaskDefinitionRollback(instance, saga)
 
 	// create service discovery
 	serviceDiscovery, err := p.createServiceDiscovery(instance)
@@ -71,6 +83,7 @@ func (p *ecsPushApiProvisioner) Provision(instance *models.Instance, role *iam.G
 		return
 	}
 	p.logger.Debug("[push-api] did create service discovery")
+	p.registerServiceDiscoveryRollback(instance, saga)
 
 	// create service
 	service, err := p.createService(instance, serviceDiscovery)
@@ -79,6 +92,7 @@ func (p *ecsPushApiProvisioner) Provision(instance *models.Instance, role *iam.G
 		return
 	}
 	p.logger.Debug("[push-api] did create service")
+	p.registerServiceRollback(instance, saga)
 
 	// wait for service to go up
 	waitCh := make(chan bool)
@@ -89,27 +103,46 @@ func (p *ecsPushApiProvisioner) Provision(instance *models.Instance, role *iam.G
 	}
 	p.logger.Debug("[push-api] service is up")
 
+	ch <- provisionPushApiResult{
+		service:          service,
+		serviceDiscovery: serviceDiscovery,
+		taskDefinition:   taskDefinition,
+	}
+}
+
+/*
+	the task gets its network interface some time after the service is up
+*/
+func (p *ecsPushApiProvisioner) ResolveNetworkInterface(instance *models.Instance, ch chan networkInterfaceResult) {
 	// wait for network interface
 	eniCh := make(chan bool)
 	go waitTaskNetworkInterface(p.logger, instance, eniCh, p.describeTaskNetworkInterface)
 	if isEniUp := <-eniCh; !isEniUp {
-		ch <- provisionPushApiResult{err: errors.New("push-api ENI failed to become available")}
+		ch <- networkInterfaceResult{err: errors.New("push-api ENI failed to become available")}
 		return
 	}
 
 	// get network interface
 	eni, err := p.describeTaskNetworkInterface(instance)
 	if err != nil {
-		ch <- provisionPushApiResult{err: err}
+		ch <- networkInterfaceResult{err: err}
 		return
 	}
 	p.logger.Debug("[push-api] network interface is up")
 
-	ch <- provisionPushApiResult{
-		eni:              eni,
-		service:          service,
-		serviceDiscovery: serviceDiscovery,
-		taskDefinition:   taskDefinition,
+	ch <- networkInterfaceResult{eni: eni}
+}
+
+func pushStreamUrl(pushStreamPublicIp string) string {
+	return fmt.Sprintf("http://%s:9080", pushStreamPublicIp)
+}
+
+func (p *ecsPushApiProvisioner) applySize(input *ecs.RegisterTaskDefinitionInput, size ecsTaskSize) {
+	input.Cpu = size.taskCpu()
+	input.Memory = size.taskMemory()
+	for _, containerDefinition := range input.ContainerDefinitions {
+		containerDefinition.Cpu = aws.Int64(size.cpu)
+		containerDefinition.MemoryReservation = aws.Int64(size.memory)
 	}
 }
 
@@ -120,19 +153,21 @@ func (p *ecsPushApiProvisioner) createTaskDefinition(
 	password string,
 	pushStreamPublicIp string,
 ) (*ecs.RegisterTaskDefinitionOutput, error) {
-	return p.provisionerConfig.ecs.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+	plan, err := p.provisionerConfig.plan(instance.Plan)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &ecs.RegisterTaskDefinitionInput{
 		Family:                  aws.String(pushApiWithInstance(instance.Name)),
+		Tags:                    clusterTags(p.provisionerConfig),
 		ExecutionRoleArn:        role.Role.Arn,
 		NetworkMode:             aws.String(ecs.NetworkModeAwsvpc),
 		RequiresCompatibilities: []*string{aws.String(ecs.CompatibilityFargate)},
-		Cpu:                     aws.String("256"),
-		Memory:                  aws.String("512"),
 		ContainerDefinitions: []*ecs.ContainerDefinition{
 			{
-				Cpu:               aws.Int64(256),
-				Image:             p.provisionerConfig.imagePushApi,
-				MemoryReservation: aws.Int64(512),
-				Name:              aws.String(pushApi),
+				Image: p.provisionerConfig.imagePushApi,
+				Name:  aws.String(pushApi),
 				LogConfiguration: &ecs.LogConfiguration{
 					LogDriver: aws.String(ecs.LogDriverAwslogs),
 					Options: map[string]*string{
@@ -154,7 +189,7 @@ func (p *ecsPushApiProvisioner) createTaskDefinition(
 					},
 					{
 						Name:  aws.String("PUSHAPI_PUSH_STREAM__URL"),
-						Value: aws.String(fmt.Sprintf("http://%s:9080", pushStreamPublicIp)),
+						Value: aws.String(pushStreamUrl(pushStreamPublicIp)),
 					},
 
 					{
@@ -168,7 +203,10 @@ func (p *ecsPushApiProvisioner) createTaskDefinition(
 				},
 			},
 		},
-	})
+	}
+	p.applySize(input, planPushApiTaskSize(plan))
+
+	return p.provisionerConfig.ecs.RegisterTaskDefinition(input)
 }
 
 func (p *ecsPushApiProvisioner) createServiceDiscovery(instance *models.Instance) (*servicediscovery.CreateServiceOutput, error) {
@@ -211,6 +249,92 @@ func (p *ecsPushApiProvisioner) createService(instance *models.Instance, service
 	})
 }
 
+/*
+	===========================================================================
+	update
+	===========================================================================
+*/
+/*
+	push-stream gets a new public IP when its service is rolled, so push-api is given the new one
+*/
+func (p *ecsPushApiProvisioner) Update(instance *models.Instance, pushStreamPublicIp string, ch chan updatePushApiResult) {
+	plan, err := p.provisionerConfig.plan(instance.Plan)
+	if err != nil {
+		ch <- updatePushApiResult{err: err}
+		return
+	}
+
+	// the revision gets the size of the plan
+	p.rollRevision(instance, ch, func(input *ecs.RegisterTaskDefinitionInput) {
+		p.applySize(input, planPushApiTaskSize(plan))
+		p.replaceEnv(input, "PUSHAPI_PUSH_STREAM__URL", pushStreamUrl(pushStreamPublicIp))
+	})
+}
+
+func (p *ecsPushApiProvisioner) RotatePassword(instance *models.Instance, password string, ch chan updatePushApiResult) {
+	p.rollRevision(instance, ch, func(input *ecs.RegisterTaskDefinitionInput) {
+		p.replaceEnv(input, "PUSHAPI_API__BASIC_AUTH_PASSWORD", password)
+	})
+}
+
+func (p *ecsPushApiProvisioner) replaceEnv(input *ecs.RegisterTaskDefinitionInput, name string, value string) {
+	for _, containerDefinition := range input.ContainerDefinitions {
+		for _, env := range containerDefinition.Environment {
+			if *env.Name == name {
+				env.Value = aws.String(value)
+			}
+		}
+	}
+}
+
+/*
+	registers a revision of the current task definition changed by changeFn and rolls the service to it.
+	The previous revision is only left registered when it fails to be deregistered, which the gc takes care of
+	along with the family.
+*/
+func (p *ecsPushApiProvisioner) rollRevision(instance *models.Instance, ch chan updatePushApiResult, changeFn func(*ecs.RegisterTaskDefinitionInput)) {
+	// register task definition revision
+	taskDefinition, previousTaskDefinitionArn, err := registerTaskDefinitionRevision(pushApiWithInstance(instance.Name), p.provisionerConfig, changeFn)
+	if err != nil {
+		ch <- updatePushApiResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-api] did register task definition revision")
+
+	// roll service
+	service, err := rollService(pushApiWithInstance(instance.Name), taskDefinition.TaskDefinition.TaskDefinitionArn, p.provisionerConfig)
+	if err != nil {
+		ch <- updatePushApiResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-api] did roll service")
+
+	// wait for service to be deployed
+	waitCh := make(chan bool)
+	go waitServiceDeployed(p.logger, instance, waitCh, p.describeService)
+	if serviceDeployed := <-waitCh; !serviceDeployed {
+		ch <- updatePushApiResult{rolled: true, err: errors.New("push-api service did not finish deployment")}
+		return
+	}
+	p.logger.Debug("[push-api] service is deployed")
+
+	// deregister previous task definition revision
+	_, err = p.provisionerConfig.ecs.DeregisterTaskDefinition(&ecs.DeregisterTaskDefinitionInput{
+		TaskDefinition: previousTaskDefinitionArn,
+	})
+	if err != nil {
+		p.logger.Warn("[push-api] failed to deregister previous task definition revision", zap.Any("instance", instance), zap.Error(err))
+	} else {
+		p.logger.Debug("[push-api] did deregister previous task definition revision")
+	}
+
+	ch <- updatePushApiResult{
+		service:        service,
+		taskDefinition: taskDefinition,
+		rolled:         true,
+	}
+}
+
 /*
 	===========================================================================
 	deprovision
@@ -295,6 +419,35 @@ func (p *ecsPushApiProvisioner) Deprovision(instance *models.Instance, ch chan d
 	}
 }
 
+/*
+	===========================================================================
+	rollback
+	===========================================================================
+*/
+func (p *ecsPushApiProvisioner) RegisterRollback(instance *models.Instance, saga *provisionSaga) {
+	p.registerTaskDefinitionRollback(instance, saga)
+	p.registerServiceDiscoveryRollback(instance, saga)
+	p.registerServiceRollback(instance, saga)
+}
+
+func (p *ecsPushApiProvisioner) registerTaskDefinitionRollback(instance *models.Instance, saga *provisionSaga) {
+	saga.register("[push-api] task definition", func() error {
+		return undoTaskDefinition(pushApiWithInstance(instance.Name), p.provisionerConfig)
+	})
+}
+
+func (p *ecsPushApiProvisioner) registerServiceDiscoveryRollback(instance *models.Instance, saga *provisionSaga) {
+	saga.register("[push-api] service discovery", func() error {
+		return undoServiceDiscovery(pushApiWithInstance(instance.Name), p.provisionerConfig)
+	})
+}
+
+func (p *ecsPushApiProvisioner) registerServiceRollback(instance *models.Instance, saga *provisionSaga) {
+	saga.register("[push-api] service", func() error {
+		return undoService(p.logger, instance, pushApiWithInstance(instance.Name), p.provisionerConfig, p.describeService)
+	})
+}
+
 /*
 	===========================================================================
 	other
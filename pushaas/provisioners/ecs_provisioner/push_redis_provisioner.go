@@ -16,8 +16,9 @@ const pushRedis = "push-redis"
 
 type (
 	EcsPushRedisProvisioner interface {
-		Provision(*models.Instance, chan provisionPushRedisResult)
+		Provision(*models.Instance, *provisionSaga, chan provisionPushRedisResult)
 		Deprovision(*models.Instance, chan deprovisionPushRedisResult)
+		RegisterRollback(*models.Instance, *provisionSaga)
 	}
 
 	ecsPushRedisProvisioner struct {
@@ -47,7 +48,7 @@ func pushRedisWithInstance(instanceName string) string {
 	provision
 	===========================================================================
 */
-func (p *ecsPushRedisProvisioner) Provision(instance *models.Instance, ch chan provisionPushRedisResult) {
+func (p *ecsPushRedisProvisioner) Provision(instance *models.Instance, saga *provisionSaga, ch chan provisionPushRedisResult) {
 	var err error
 
 	// create service discovery
@@ -58,6 +59,7 @@ func (p *ecsPushRedisProvisioner) Provision(instance *models.Instance, ch chan p
 		return
 	}
 	p.logger.Debug("[push-redis] did create service discovery")
+	p.registerServiceDiscoveryRollback(instance, saga)
 
 	// create service
 	service, err := p.createService(instance, serviceDiscovery)
@@ -66,6 +68,7 @@ func (p *ecsPushRedisProvisioner) Provision(instance *models.Instance, ch chan p
 		return
 	}
 	p.logger.Debug("[push-redis] did create service")
+	p.registerServiceRollback(instance, saga)
 
 	// wait for service to go up
 	waitCh := make(chan bool)
@@ -197,6 +200,28 @@ func (p *ecsPushRedisProvisioner) Deprovision(instance *models.Instance, ch chan
 	}
 }
 
+/*
+	===========================================================================
+	rollback
+	===========================================================================
+*/
+func (p *ecsPushRedisProvisioner) RegisterRollback(instance *models.Instance, saga *provisionSaga) {
+	p.registerServiceDiscoveryRollback(instance, saga)
+	p.registerServiceRollback(instance, saga)
+}
+
+func (p *ecsPushRedisProvisioner) registerServiceDiscoveryRollback(instance *models.Instance, saga *provisionSaga) {
+	saga.register("[push-redis] service discovery", func() error {
+		return undoServiceDiscovery(pushRedisWithInstance(instance.Name), p.provisionerConfig)
+	})
+}
+
+func (p *ecsPushRedisProvisioner) registerServiceRollback(instance *models.Instance, saga *provisionSaga) {
+	saga.register("[push-redis] service", func() error {
+		return undoService(p.logger, instance, pushRedisWithInstance(instance.Name), p.provisionerConfig, p.describeService)
+	})
+}
+
 /*
 	===========================================================================
 	other
@@ -19,8 +19,11 @@ const pushStream = "push-stream"
 
 type (
 	EcsPushStreamProvisioner interface {
-		Provision(*models.Instance, *iam.GetRoleOutput, chan provisionPushStreamResult)
+		Provision(*models.Instance, *provisionSaga, *iam.GetRoleOutput, chan provisionPushStreamResult)
 		Deprovision(*models.Instance, chan deprovisionPushStreamResult)
+		Update(*models.Instance, chan updatePushStreamResult)
+		ResolveNetworkInterface(*models.Instance, chan networkInterfaceResult)
+		RegisterRollback(*models.Instance, *provisionSaga)
 	}
 
 	ecsPushStreamProvisioner struct{
@@ -29,13 +32,18 @@ type (
 	}
 
 	provisionPushStreamResult struct {
-		eni              *ec2.DescribeNetworkInterfacesOutput
 		service          *ecs.CreateServiceOutput
 		serviceDiscovery *servicediscovery.CreateServiceOutput
 		taskDefinition   *ecs.RegisterTaskDefinitionOutput
 		err              error
 	}
 
+	updatePushStreamResult struct {
+		service        *ecs.UpdateServiceOutput
+		taskDefinition *ecs.RegisterTaskDefinitionOutput
+		err            error
+	}
+
 	deprovisionPushStreamResult struct {
 		service          *ecs.DeleteServiceOutput
 		serviceDiscovery *servicediscovery.DeleteServiceOutput
@@ -53,7 +61,7 @@ func pushStreamWithInstance(instanceName string) string {
 	provision
 	===========================================================================
 */
-func (p *ecsPushStreamProvisioner) Provision(instance *models.Instance, role *iam.GetRoleOutput, ch chan provisionPushStreamResult) {
+func (p *ecsPushStreamProvisioner) Provision(instance *models.Instance, saga *provisionSaga, role *iam.GetRoleOutput, ch chan provisionPushStreamResult) {
 	var err error
 
 	// create task definition
@@ -63,6 +71,7 @@ func (p *ecsPushStreamProvisioner) Provision(instance *models.Instance, role *ia
 		return
 	}
 	p.logger.Debug("[push-stream] did create task definition")
+	p.registerTaskDefinitionRollback(instance, saga)
 
 	// create service discovery
 	serviceDiscovery, err := p.createServiceDiscovery(instance)
@@ -71,6 +80,7 @@ func (p *ecsPushStreamProvisioner) Provision(instance *models.Instance, role *ia
 		return
 	}
 	p.logger.Debug("[push-stream] did create service discovery")
+	p.registerServiceDiscoveryRollback(instance, saga)
 
 	// create service
 	service, err := p.createService(instance, serviceDiscovery)
@@ -79,6 +89,7 @@ func (p *ecsPushStreamProvisioner) Provision(instance *models.Instance, role *ia
 		return
 	}
 	p.logger.Debug("[push-stream] did create service")
+	p.registerServiceRollback(instance, saga)
 
 	// wait for service to go up
 	waitCh := make(chan bool)
@@ -89,44 +100,84 @@ func (p *ecsPushStreamProvisioner) Provision(instance *models.Instance, role *ia
 	}
 	p.logger.Debug("[push-stream] service is up")
 
+	ch <- provisionPushStreamResult{
+		service:          service,
+		serviceDiscovery: serviceDiscovery,
+		taskDefinition:   taskDefinition,
+	}
+}
+
+/*
+	the task gets its network interface some time after the service is up
+*/
+func (p *ecsPushStreamProvisioner) ResolveNetworkInterface(instance *models.Instance, ch chan networkInterfaceResult) {
 	// wait for network interface
 	eniCh := make(chan bool)
 	go waitTaskNetworkInterface(p.logger, instance, eniCh, p.describeTaskNetworkInterface)
 	if isEniUp := <-eniCh; !isEniUp {
-		ch <- provisionPushStreamResult{err: errors.New("push-stream ENI failed to become available")}
+		ch <- networkInterfaceResult{err: errors.New("push-stream ENI failed to become available")}
 		return
 	}
 
 	// get network interface
 	eni, err := p.describeTaskNetworkInterface(instance)
 	if err != nil {
-		ch <- provisionPushStreamResult{err: err}
+		ch <- networkInterfaceResult{err: err}
 		return
 	}
 	p.logger.Debug("[push-stream] network interface is up")
 
-	ch <- provisionPushStreamResult{
-		eni:              eni,
-		service:          service,
-		serviceDiscovery: serviceDiscovery,
-		taskDefinition:   taskDefinition,
+	ch <- networkInterfaceResult{eni: eni}
+}
+
+/*
+	push-stream and push-agent share the task evenly
+*/
+func (p *ecsPushStreamProvisioner) applySize(input *ecs.RegisterTaskDefinitionInput, size ecsTaskSize) {
+	input.Cpu = size.taskCpu()
+	input.Memory = size.taskMemory()
+	for _, containerDefinition := range input.ContainerDefinitions {
+		containerDefinition.Cpu = aws.Int64(size.cpu / 2)
+		containerDefinition.MemoryReservation = aws.Int64(size.memory / 2)
+	}
+}
+
+func (p *ecsPushStreamProvisioner) applyPlan(input *ecs.RegisterTaskDefinitionInput, plan *models.Plan) {
+	p.applySize(input, planPushStreamTaskSize(plan))
+
+	limits := planPushStreamLimits(plan)
+	for _, containerDefinition := range input.ContainerDefinitions {
+		if *containerDefinition.Name != pushStream {
+			continue
+		}
+
+		// the limits of a previous revision are replaced
+		var environment []*ecs.KeyValuePair
+		for _, env := range containerDefinition.Environment {
+			if *env.Name != "PUSHSTREAM_MAX_NUMBER_OF_CHANNELS" && *env.Name != "PUSHSTREAM_MAX_SUBSCRIBERS_PER_CHANNEL" {
+				environment = append(environment, env)
+			}
+		}
+		containerDefinition.Environment = append(environment, limits...)
 	}
 }
 
 func (p *ecsPushStreamProvisioner) createTaskDefinition(instance *models.Instance, role *iam.GetRoleOutput) (*ecs.RegisterTaskDefinitionOutput, error) {
-	return p.provisionerConfig.ecs.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+	plan, err := p.provisionerConfig.plan(instance.Plan)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &ecs.RegisterTaskDefinitionInput{
 		Family:                  aws.String(pushStreamWithInstance(instance.Name)),
+		Tags:                    clusterTags(p.provisionerConfig),
 		ExecutionRoleArn:        role.Role.Arn,
 		NetworkMode:             aws.String(ecs.NetworkModeAwsvpc),
 		RequiresCompatibilities: []*string{aws.String(ecs.CompatibilityFargate)},
-		Cpu:                     aws.String("512"),
-		Memory:                  aws.String("1024"),
 		ContainerDefinitions: []*ecs.ContainerDefinition{
 			{
-				Name:              aws.String(pushStream),
-				Cpu:               aws.Int64(256),
-				Image:             p.provisionerConfig.imagePushStream,
-				MemoryReservation: aws.Int64(512),
+				Name:  aws.String(pushStream),
+				Image: p.provisionerConfig.imagePushStream,
 				LogConfiguration: &ecs.LogConfiguration{
 					LogDriver: aws.String(ecs.LogDriverAwslogs),
 					Options: map[string]*string{
@@ -144,7 +195,6 @@ func (p *ecsPushStreamProvisioner) createTaskDefinition(instance *models.Instanc
 			},
 			{
 				Name:  aws.String(pushAgent),
-				Cpu:   aws.Int64(256),
 				Image: p.provisionerConfig.imagePushAgent,
 				DependsOn: []*ecs.ContainerDependency{
 					{
@@ -152,7 +202,6 @@ func (p *ecsPushStreamProvisioner) createTaskDefinition(instance *models.Instanc
 						ContainerName: aws.String(pushStream),
 					},
 				},
-				MemoryReservation: aws.Int64(512),
 				LogConfiguration: &ecs.LogConfiguration{
 					LogDriver: aws.String(ecs.LogDriverAwslogs),
 					Options: map[string]*string{
@@ -167,13 +216,17 @@ func (p *ecsPushStreamProvisioner) createTaskDefinition(instance *models.Instanc
 						Value: aws.String("redis://" + pushRedisWithInstance(instance.Name) + ".tsuru:6379"),
 					},
 					{
+						// the containers of a task share the network
 						Name:  aws.String("PUSHAGENT_PUSH_STREAM__URL"),
-						Value: aws.String("http://" + pushStreamWithInstance(instance.Name) + ".tsuru:9080"),
+						Value: aws.String("http://localhost:9080"),
 					},
 				},
 			},
 		},
-	})
+	}
+	p.applyPlan(input, plan)
+
+	return p.provisionerConfig.ecs.RegisterTaskDefinition(input)
 }
 
 func (p *ecsPushStreamProvisioner) createServiceDiscovery(instance *models.Instance) (*servicediscovery.CreateServiceOutput, error) {
@@ -194,6 +247,9 @@ func (p *ecsPushStreamProvisioner) createServiceDiscovery(instance *models.Insta
 	})
 }
 
+/*
+	a single task, as the subscribers are given the public IP of that one
+*/
 func (p *ecsPushStreamProvisioner) createService(instance *models.Instance, pushStreamDiscovery *servicediscovery.CreateServiceOutput) (*ecs.CreateServiceOutput, error) {
 	return p.provisionerConfig.ecs.CreateService(&ecs.CreateServiceInput{
 		Cluster:        p.provisionerConfig.cluster,
@@ -216,6 +272,61 @@ func (p *ecsPushStreamProvisioner) createService(instance *models.Instance, push
 	})
 }
 
+/*
+	===========================================================================
+	update
+	===========================================================================
+*/
+func (p *ecsPushStreamProvisioner) Update(instance *models.Instance, ch chan updatePushStreamResult) {
+	plan, err := p.provisionerConfig.plan(instance.Plan)
+	if err != nil {
+		ch <- updatePushStreamResult{err: err}
+		return
+	}
+
+	// register task definition revision with the size and limits of the plan
+	taskDefinition, previousTaskDefinitionArn, err := registerTaskDefinitionRevision(pushStreamWithInstance(instance.Name), p.provisionerConfig, func(input *ecs.RegisterTaskDefinitionInput) {
+		p.applyPlan(input, plan)
+	})
+	if err != nil {
+		ch <- updatePushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did register task definition revision")
+
+	// roll service
+	service, err := rollService(pushStreamWithInstance(instance.Name), taskDefinition.TaskDefinition.TaskDefinitionArn, p.provisionerConfig)
+	if err != nil {
+		ch <- updatePushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did roll service")
+
+	// wait for service to be deployed
+	waitCh := make(chan bool)
+	go waitServiceDeployed(p.logger, instance, waitCh, p.describeService)
+	if serviceDeployed := <-waitCh; !serviceDeployed {
+		ch <- updatePushStreamResult{err: errors.New("push-stream service did not finish deployment")}
+		return
+	}
+	p.logger.Debug("[push-stream] service is deployed")
+
+	// deregister previous task definition revision
+	_, err = p.provisionerConfig.ecs.DeregisterTaskDefinition(&ecs.DeregisterTaskDefinitionInput{
+		TaskDefinition: previousTaskDefinitionArn,
+	})
+	if err != nil {
+		ch <- updatePushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did deregister previous task definition revision")
+
+	ch <- updatePushStreamResult{
+		service:        service,
+		taskDefinition: taskDefinition,
+	}
+}
+
 /*
 	===========================================================================
 	deprovision
@@ -300,6 +411,35 @@ func (p *ecsPushStreamProvisioner) Deprovision(instance *models.Instance, ch cha
 	}
 }
 
+/*
+	===========================================================================
+	rollback
+	===========================================================================
+*/
+func (p *ecsPushStreamProvisioner) RegisterRollback(instance *models.Instance, saga *provisionSaga) {
+	p.registerTaskDefinitionRollback(instance, saga)
+	p.registerServiceDiscoveryRollback(instance, saga)
+	p.registerServiceRollback(instance, saga)
+}
+
+func (p *ecsPushStreamProvisioner) registerTaskDefinitionRollback(instance *models.Instance, saga *provisionSaga) {
+	saga.register("[push-stream] task definition", func() error {
+		return undoTaskDefinition(pushStreamWithInstance(instance.Name), p.provisionerConfig)
+	})
+}
+
+func (p *ecsPushStreamProvisioner) registerServiceDiscoveryRollback(instance *models.Instance, saga *provisionSaga) {
+	saga.register("[push-stream] service discovery", func() error {
+		return undoServiceDiscovery(pushStreamWithInstance(instance.Name), p.provisionerConfig)
+	})
+}
+
+func (p *ecsPushStreamProvisioner) registerServiceRollback(instance *models.Instance, saga *provisionSaga) {
+	saga.register("[push-stream] service", func() error {
+		return undoService(p.logger, instance, pushStreamWithInstance(instance.Name), p.provisionerConfig, p.describeService)
+	})
+}
+
 /*
 	===========================================================================
 	other
@@ -0,0 +1,145 @@
+package ecs_provisioner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+type (
+	/*
+		Keeps track of what was created while provisioning an instance.
+		Every step that creates a resource registers how to undo it, so when a later step fails everything
+		that was already created is torn down in the reverse order it was created.
+	*/
+	provisionSaga struct {
+		logger   *zap.Logger
+		instance *models.Instance
+		steps    []sagaStep
+	}
+
+	sagaStep struct {
+		name string
+		undo func() error
+	}
+)
+
+func (s *provisionSaga) register(name string, undo func() error) {
+	s.steps = append(s.steps, sagaStep{name: name, undo: undo})
+}
+
+/*
+	the rollback keeps going when a step fails to be undone, so that as much as possible is cleaned up
+*/
+func (s *provisionSaga) rollback() models.InstanceRollback {
+	if len(s.steps) == 0 {
+		return models.InstanceRollbackNone
+	}
+
+	rollback := models.InstanceRollbackCompleted
+	for i := len(s.steps) - 1; i >= 0; i-- {
+		step := s.steps[i]
+		err := step.undo()
+		if err != nil {
+			s.logger.Error("failed to undo provision step", zap.String("step", step.name), zap.Any("instance", s.instance), zap.Error(err))
+			rollback = models.InstanceRollbackFailed
+			continue
+		}
+		s.logger.Info("did undo provision step", zap.String("step", step.name), zap.Any("instance", s.instance))
+	}
+	return rollback
+}
+
+func newProvisionSaga(logger *zap.Logger, instance *models.Instance) *provisionSaga {
+	return &provisionSaga{
+		logger:   logger,
+		instance: instance,
+	}
+}
+
+/*
+	===========================================================================
+	undo
+	===========================================================================
+*/
+/*
+	the undo actions only rely on names, so they can also be registered for steps completed by a previous attempt
+*/
+func undoTaskDefinition(family string, provisionerConfig *EcsProvisionerConfig) error {
+	describeOutput, err := provisionerConfig.ecs.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(family),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = provisionerConfig.ecs.DeregisterTaskDefinition(&ecs.DeregisterTaskDefinitionInput{
+		TaskDefinition: describeOutput.TaskDefinition.TaskDefinitionArn,
+	})
+	return err
+}
+
+func undoService(logger *zap.Logger, instance *models.Instance, serviceName string, provisionerConfig *EcsProvisionerConfig, describeServiceFunc func(*models.Instance) (*ecs.DescribeServicesOutput, error)) error {
+	// a forced delete does not need the service to be scaled down first
+	_, err := provisionerConfig.ecs.DeleteService(&ecs.DeleteServiceInput{
+		Cluster: provisionerConfig.cluster,
+		Force:   aws.Bool(true),
+		Service: aws.String(serviceName),
+	})
+	if err != nil {
+		return err
+	}
+
+	// the service discovery can only be deleted after the service released its instances
+	waitCh := make(chan bool)
+	go waitServiceDown(logger, instance, waitCh, describeServiceFunc)
+	if serviceDown := <-waitCh; !serviceDown {
+		return errors.New(fmt.Sprintf("service %s did not go down", serviceName))
+	}
+	return nil
+}
+
+func undoServiceDiscovery(serviceName string, provisionerConfig *EcsProvisionerConfig) error {
+	listServicesOutput, err := listServiceDiscoveryServices(provisionerConfig)
+	if err != nil {
+		return err
+	}
+
+	var serviceId *string
+	for _, service := range listServicesOutput.Services {
+		if *service.Name == serviceName {
+			serviceId = service.Id
+		}
+	}
+	if serviceId == nil {
+		return errors.New(fmt.Sprintf("could not find service discovery service %s", serviceName))
+	}
+
+	instancesOutput, err := provisionerConfig.serviceDiscovery.ListInstances(&servicediscovery.ListInstancesInput{
+		ServiceId: serviceId,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, serviceDiscoveryInstance := range instancesOutput.Instances {
+		_, err = provisionerConfig.serviceDiscovery.DeregisterInstance(&servicediscovery.DeregisterInstanceInput{
+			ServiceId:  serviceId,
+			InstanceId: serviceDiscoveryInstance.Id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = provisionerConfig.serviceDiscovery.DeleteService(&servicediscovery.DeleteServiceInput{
+		Id: serviceId,
+	})
+	return err
+}
@@ -0,0 +1,39 @@
+package provisioners
+
+import (
+	"strings"
+	"time"
+)
+
+type (
+	// a resource on the provider that belongs to an instance, recognized by the naming convention of the components
+	PushServiceResource struct {
+		Kind         string `json:"kind"`
+		Name         string `json:"name"`
+		InstanceName string `json:"instanceName"`
+		// nil when the provider does not tell when the resource was created
+		CreatedAt *time.Time `json:"createdAt,omitempty"`
+	}
+
+	/*
+		Implemented by the provisioners able to list the resources they created, so the ones left behind by
+		failed deprovisions can be found and removed. ListResources returns them in an order safe to delete.
+	*/
+	PushServiceResourceCollector interface {
+		ListResources() ([]*PushServiceResource, error)
+		DeleteResource(resource *PushServiceResource) error
+	}
+)
+
+/*
+	the resources of the components are named `<component>-<instance name>`
+*/
+func InstanceNameFromResourceName(resourceName string) (string, bool) {
+	for _, component := range []string{ComponentPushRedis, ComponentPushStream, ComponentPushApi} {
+		prefix := component + "-"
+		if strings.HasPrefix(resourceName, prefix) && len(resourceName) > len(prefix) {
+			return strings.TrimPrefix(resourceName, prefix), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,31 @@
+package provisioners_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+)
+
+var _ = Describe("InstanceNameFromResourceName", func() {
+	It("extracts the instance name from the resources of every component", func() {
+		for _, resourceName := range []string{"push-redis-instance-1", "push-stream-instance-1", "push-api-instance-1"} {
+			// act
+			instanceName, ok := provisioners.InstanceNameFromResourceName(resourceName)
+
+			// assert
+			Expect(ok).To(BeTrue())
+			Expect(instanceName).To(Equal("instance-1"))
+		}
+	})
+
+	It("does not recognize resources out of the naming convention", func() {
+		for _, resourceName := range []string{"push-redis", "push-api-", "other-instance-1"} {
+			// act
+			_, ok := provisioners.InstanceNameFromResourceName(resourceName)
+
+			// assert
+			Expect(ok).To(BeFalse())
+		}
+	})
+})
@@ -0,0 +1,57 @@
+package kubernetes_provisioner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+	"k8s.io/client-go/kubernetes"
+)
+
+type (
+	KubernetesProvisionerConfig struct {
+		kubernetes            kubernetes.Interface
+		imagePushApi          string
+		imagePushAgent        string
+		imagePushRedis        string
+		imagePushStream       string
+		namespace             string
+		pushStreamServiceType string
+	}
+)
+
+func NewKubernetesProvisionerConfig(config *viper.Viper, kubernetesSvc kubernetes.Interface) (*KubernetesProvisionerConfig, error) {
+	imagePushApi := config.GetString("provisioner.kubernetes.image_push_api")
+	imagePushAgent := config.GetString("provisioner.kubernetes.image_push_agent")
+	imagePushRedis := config.GetString("provisioner.kubernetes.image_push_redis")
+	imagePushStream := config.GetString("provisioner.kubernetes.image_push_stream")
+
+	// all the resources of all instances are created on this namespace
+	namespaceKey := "provisioner.kubernetes.namespace"
+	namespace := config.GetString(namespaceKey)
+
+	// push-stream is reached directly by the clients of push-api, so it must be exposed outside the cluster
+	pushStreamServiceTypeKey := "provisioner.kubernetes.push_stream_service_type"
+	pushStreamServiceType := config.GetString(pushStreamServiceTypeKey)
+
+	requiredVars := map[string]string{
+		namespaceKey:             namespace,
+		pushStreamServiceTypeKey: pushStreamServiceType,
+	}
+
+	for k, v := range requiredVars {
+		if v == "" {
+			return nil, errors.New(fmt.Sprintf("kubernetesProvisioner config required and not set: %s", k))
+		}
+	}
+
+	return &KubernetesProvisionerConfig{
+		kubernetes:            kubernetesSvc,
+		imagePushApi:          imagePushApi,
+		imagePushAgent:        imagePushAgent,
+		imagePushRedis:        imagePushRedis,
+		imagePushStream:       imagePushStream,
+		namespace:             namespace,
+		pushStreamServiceType: pushStreamServiceType,
+	}, nil
+}
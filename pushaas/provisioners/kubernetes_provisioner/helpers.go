@@ -0,0 +1,195 @@
+package kubernetes_provisioner
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+const labelName = "app.kubernetes.io/name"
+const labelInstance = "app.kubernetes.io/instance"
+const labelManagedBy = "app.kubernetes.io/managed-by"
+const managedBy = "pushaas"
+
+/*
+	===========================================================================
+	objects
+	===========================================================================
+*/
+func selectorLabels(instance *models.Instance, component string) map[string]string {
+	return map[string]string{
+		labelName:     component,
+		labelInstance: instance.Name,
+	}
+}
+
+func objectLabels(instance *models.Instance, component string) map[string]string {
+	labels := selectorLabels(instance, component)
+	labels[labelManagedBy] = managedBy
+	return labels
+}
+
+func newDeployment(name string, instance *models.Instance, component string, namespace string, containers []corev1.Container) *appsv1.Deployment {
+	replicas := int32(1)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    objectLabels(instance, component),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorLabels(instance, component),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: objectLabels(instance, component),
+				},
+				Spec: corev1.PodSpec{
+					Containers: containers,
+				},
+			},
+		},
+	}
+}
+
+func newService(name string, instance *models.Instance, component string, namespace string, serviceType corev1.ServiceType, port int32) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    objectLabels(instance, component),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     serviceType,
+			Selector: selectorLabels(instance, component),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       component,
+					Port:       port,
+					TargetPort: intstr.FromInt(int(port)),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
+
+func serviceDns(name string, namespace string) string {
+	return fmt.Sprintf("%s.%s", name, namespace)
+}
+
+/*
+	===========================================================================
+	deployments
+	===========================================================================
+*/
+func getDeployment(name string, provisionerConfig *KubernetesProvisionerConfig) (*appsv1.Deployment, error) {
+	return provisionerConfig.kubernetes.AppsV1().Deployments(provisionerConfig.namespace).Get(name, metav1.GetOptions{})
+}
+
+func deleteDeployment(name string, provisionerConfig *KubernetesProvisionerConfig) error {
+	propagationPolicy := metav1.DeletePropagationForeground
+	return provisionerConfig.kubernetes.AppsV1().Deployments(provisionerConfig.namespace).Delete(name, &metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	})
+}
+
+/*
+	===========================================================================
+	services
+	===========================================================================
+*/
+func getService(name string, provisionerConfig *KubernetesProvisionerConfig) (*corev1.Service, error) {
+	return provisionerConfig.kubernetes.CoreV1().Services(provisionerConfig.namespace).Get(name, metav1.GetOptions{})
+}
+
+func deleteService(name string, provisionerConfig *KubernetesProvisionerConfig) error {
+	return provisionerConfig.kubernetes.CoreV1().Services(provisionerConfig.namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+/*
+	===========================================================================
+	other
+	===========================================================================
+*/
+const attempts = 60
+const interval = 5 * time.Second
+
+func waitTrue(ch chan bool, evaluationFn func(attempt int) bool) {
+	for i := 0; i < attempts; i++ {
+		isLastAttempt := i+1 == attempts
+		isSuccess := evaluationFn(i)
+
+		if !isSuccess {
+			if isLastAttempt {
+				ch <- false
+				return
+			}
+			time.Sleep(interval)
+			continue
+		}
+		ch <- true
+		return
+	}
+}
+
+func waitDeploymentUp(logger *zap.Logger, instance *models.Instance, ch chan bool, getDeploymentFunc func(*models.Instance) (*appsv1.Deployment, error)) {
+	waitTrue(ch, func(attempt int) bool {
+		deployment, err := getDeploymentFunc(instance)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[waitDeploymentUp] failed on attempt %d", attempt), zap.Error(err))
+			return false
+		}
+		isDeploymentUp := deployment.Status.ReadyReplicas > 0
+		logger.Debug(fmt.Sprintf("[waitDeploymentUp] attempt %d with result isDeploymentUp=%t", attempt, isDeploymentUp))
+		return isDeploymentUp
+	})
+}
+
+func waitDeploymentDown(logger *zap.Logger, instance *models.Instance, ch chan bool, getDeploymentFunc func(*models.Instance) (*appsv1.Deployment, error)) {
+	waitTrue(ch, func(attempt int) bool {
+		_, err := getDeploymentFunc(instance)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			logger.Error(fmt.Sprintf("[waitDeploymentDown] failed on attempt %d", attempt), zap.Error(err))
+			return false
+		}
+		isDeploymentDown := k8serrors.IsNotFound(err)
+		logger.Debug(fmt.Sprintf("[waitDeploymentDown] attempt %d with result isDeploymentDown=%t", attempt, isDeploymentDown))
+		return isDeploymentDown
+	})
+}
+
+func waitServiceIngress(logger *zap.Logger, instance *models.Instance, ch chan bool, getServiceFunc func(*models.Instance) (*corev1.Service, error)) {
+	waitTrue(ch, func(attempt int) bool {
+		service, err := getServiceFunc(instance)
+		if err != nil {
+			logger.Error(fmt.Sprintf("[waitServiceIngress] failed on attempt %d", attempt), zap.Error(err))
+			return false
+		}
+		isIngressUp := serviceIngressHost(service) != ""
+		logger.Debug(fmt.Sprintf("[waitServiceIngress] attempt %d with result isIngressUp=%t", attempt, isIngressUp))
+		return isIngressUp
+	})
+}
+
+func serviceIngressHost(service *corev1.Service) string {
+	ingresses := service.Status.LoadBalancer.Ingress
+	if len(ingresses) == 0 {
+		return ""
+	}
+	if ingresses[0].IP != "" {
+		return ingresses[0].IP
+	}
+	return ingresses[0].Hostname
+}
@@ -0,0 +1,215 @@
+package kubernetes_provisioner
+
+import (
+	"fmt"
+
+	"github.com/dchest/uniuri"
+	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+)
+
+type (
+	kubernetesProvisioner struct {
+		logger                *zap.Logger
+		provisionerConfig     *KubernetesProvisionerConfig
+		pushRedisProvisioner  KubernetesPushRedisProvisioner
+		pushStreamProvisioner KubernetesPushStreamProvisioner
+		pushApiProvisioner    KubernetesPushApiProvisioner
+	}
+)
+
+/*
+	Runs every instance as a set of Deployments and Services on a single namespace, mirroring the ECS services:
+	push-redis, push-stream (with push-agent as a sidecar on the same pod) and push-api.
+*/
+
+func (p *kubernetesProvisioner) Provision(instance *models.Instance) *provisioners.PushServiceProvisionResult {
+	p.logger.Info("starting provision for instance", zap.Any("instance", instance))
+
+	failureResult := &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceProvisionStatusFailure,
+		EnvVars:  map[string]string{},
+	}
+
+	/*
+		push-redis
+	*/
+	chRedis := make(chan provisionPushRedisResult)
+	go p.pushRedisProvisioner.Provision(instance, chRedis)
+	resultPushRedis := <-chRedis
+	if resultPushRedis.err != nil {
+		p.logger.Error("push-redis: provision failure", zap.Any("instance", instance), zap.Error(resultPushRedis.err))
+		return failureResult
+	}
+	p.logger.Info("push-redis: provision success", zap.Any("instance", instance))
+
+	/*
+		push-stream
+	*/
+	chStream := make(chan provisionPushStreamResult)
+	go p.pushStreamProvisioner.Provision(instance, chStream)
+	resultPushStream := <-chStream
+	if resultPushStream.err != nil {
+		p.logger.Error("push-stream: provision failure", zap.Any("instance", instance), zap.Error(resultPushStream.err))
+		return failureResult
+	}
+	p.logger.Info("push-stream: provision success", zap.Any("instance", instance))
+
+	/*
+		push-api
+	*/
+	chApi := make(chan provisionPushApiResult)
+	username := "app"
+	password := uniuri.New()
+	go p.pushApiProvisioner.Provision(instance, username, password, resultPushStream.publicHost, chApi)
+	resultPushApi := <-chApi
+	if resultPushApi.err != nil {
+		p.logger.Error("push-api: provision failure", zap.Any("instance", instance), zap.Error(resultPushApi.err))
+		return failureResult
+	}
+	p.logger.Info("push-api: provision success", zap.Any("instance", instance))
+
+	p.logger.Info(
+		"finishing provision for instance",
+		zap.Any("instance", instance),
+		zap.Any("resultPushRedis", resultPushRedis),
+		zap.Any("resultPushStream", resultPushStream),
+		zap.Any("resultPushApi", resultPushApi),
+	)
+
+	envVars := map[string]string{
+		provisioners.EnvVarEndpoint: fmt.Sprintf("http://%s:%d", serviceDns(pushApiWithInstance(instance.Name), p.provisionerConfig.namespace), pushApiPort),
+		provisioners.EnvVarPassword: password,
+		provisioners.EnvVarUsername: username,
+	}
+
+	return &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		EnvVars:  envVars,
+		Status:   provisioners.PushServiceProvisionStatusSuccess,
+		Resources: map[string]string{
+			provisioners.ResourcePushStreamUrl: fmt.Sprintf("http://%s:%d", resultPushStream.publicHost, pushStreamPort),
+		},
+	}
+}
+
+/*
+	every plan runs with the same resources, so there is nothing to apply
+*/
+func (p *kubernetesProvisioner) Update(instance *models.Instance) *provisioners.PushServiceProvisionResult {
+	p.logger.Info("nothing to update for instance", zap.Any("instance", instance))
+	return &provisioners.PushServiceProvisionResult{
+		Instance: instance,
+		EnvVars:  map[string]string{},
+		Status:   provisioners.PushServiceProvisionStatusSuccess,
+	}
+}
+
+func (p *kubernetesProvisioner) Deprovision(instance *models.Instance) *provisioners.PushServiceDeprovisionResult {
+	failureResult := &provisioners.PushServiceDeprovisionResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceDeprovisionStatusFailure,
+	}
+
+	/*
+		push-api
+	*/
+	chApi := make(chan deprovisionPushApiResult)
+	go p.pushApiProvisioner.Deprovision(instance, chApi)
+	resultPushApi := <-chApi
+	if resultPushApi.err != nil {
+		p.logger.Error("push-api: deprovision failure", zap.Any("instance", instance), zap.Error(resultPushApi.err))
+		return failureResult
+	}
+	p.logger.Info("push-api: deprovision success", zap.Any("instance", instance))
+
+	/*
+		push-stream
+	*/
+	chStream := make(chan deprovisionPushStreamResult)
+	go p.pushStreamProvisioner.Deprovision(instance, chStream)
+	resultPushStream := <-chStream
+	if resultPushStream.err != nil {
+		p.logger.Error("push-stream: deprovision failure", zap.Any("instance", instance), zap.Error(resultPushStream.err))
+		return failureResult
+	}
+	p.logger.Info("push-stream: deprovision success", zap.Any("instance", instance))
+
+	/*
+		push-redis
+	*/
+	chRedis := make(chan deprovisionPushRedisResult)
+	go p.pushRedisProvisioner.Deprovision(instance, chRedis)
+	resultPushRedis := <-chRedis
+	if resultPushRedis.err != nil {
+		p.logger.Error("push-redis: deprovision failure", zap.Any("instance", instance), zap.Error(resultPushRedis.err))
+		return failureResult
+	}
+	p.logger.Info("push-redis: deprovision success", zap.Any("instance", instance))
+
+	p.logger.Info(
+		"finishing deprovision for instance",
+		zap.Any("instance", instance),
+		zap.Any("resultPushRedis", resultPushRedis),
+		zap.Any("resultPushStream", resultPushStream),
+		zap.Any("resultPushApi", resultPushApi),
+	)
+
+	return &provisioners.PushServiceDeprovisionResult{
+		Instance: instance,
+		Status:   provisioners.PushServiceDeprovisionStatusSuccess,
+	}
+}
+
+func (p *kubernetesProvisioner) Inspect(instance *models.Instance) *provisioners.PushServiceInspectResult {
+	deploymentNames := map[string]string{
+		provisioners.ComponentPushRedis:  pushRedisWithInstance(instance.Name),
+		provisioners.ComponentPushStream: pushStreamWithInstance(instance.Name),
+		provisioners.ComponentPushApi:    pushApiWithInstance(instance.Name),
+	}
+
+	var components []*provisioners.PushServiceComponentState
+	for _, component := range []string{provisioners.ComponentPushRedis, provisioners.ComponentPushStream, provisioners.ComponentPushApi} {
+		state := &provisioners.PushServiceComponentState{Name: component}
+
+		deployment, err := getDeployment(deploymentNames[component], p.provisionerConfig)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			p.logger.Error("failed to get deployment to inspect instance", zap.String("component", component), zap.Any("instance", instance), zap.Error(err))
+			return &provisioners.PushServiceInspectResult{
+				Instance: instance,
+				Status:   provisioners.PushServiceInspectStatusFailure,
+			}
+		}
+		if err == nil {
+			state.Exists = true
+			state.RunningCount = int64(deployment.Status.ReadyReplicas)
+		}
+		components = append(components, state)
+	}
+
+	return &provisioners.PushServiceInspectResult{
+		Instance:   instance,
+		Components: components,
+		Status:     provisioners.PushServiceInspectStatusSuccess,
+	}
+}
+
+func NewKubernetesPushServiceProvisioner(
+	logger *zap.Logger,
+	provisionerConfig *KubernetesProvisionerConfig,
+	pushRedisProvisioner KubernetesPushRedisProvisioner,
+	pushStreamProvisioner KubernetesPushStreamProvisioner,
+	pushApiProvisioner KubernetesPushApiProvisioner,
+) (provisioners.PushServiceProvisioner, error) {
+	return &kubernetesProvisioner{
+		logger:                logger,
+		provisionerConfig:     provisionerConfig,
+		pushRedisProvisioner:  pushRedisProvisioner,
+		pushStreamProvisioner: pushStreamProvisioner,
+		pushApiProvisioner:    pushApiProvisioner,
+	}, nil
+}
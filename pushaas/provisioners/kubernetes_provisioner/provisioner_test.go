@@ -0,0 +1,233 @@
+package kubernetes_provisioner_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/provisioners/kubernetes_provisioner"
+)
+
+var _ = Describe("KubernetesProvisioner", func() {
+	instance := &models.Instance{
+		Name: "instance-1",
+	}
+
+	newConfig := func() *viper.Viper {
+		config := viper.New()
+		config.Set("provisioner.kubernetes.namespace", "pushaas")
+		config.Set("provisioner.kubernetes.push_stream_service_type", "LoadBalancer")
+		return config
+	}
+
+	newProvisioner := func(config *viper.Viper, kubernetesSvc kubernetes.Interface) provisioners.PushServiceProvisioner {
+		provisionerConfig, err := kubernetes_provisioner.NewKubernetesProvisionerConfig(config, kubernetesSvc)
+		Expect(err).NotTo(HaveOccurred())
+
+		provisioner, err := kubernetes_provisioner.NewKubernetesPushServiceProvisioner(
+			logger,
+			provisionerConfig,
+			kubernetes_provisioner.NewKubernetesPushRedisProvisioner(logger, provisionerConfig),
+			kubernetes_provisioner.NewKubernetesPushStreamProvisioner(logger, provisionerConfig),
+			kubernetes_provisioner.NewKubernetesPushApiProvisioner(logger, provisionerConfig),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		return provisioner
+	}
+
+	// the fake clientset has no controllers, so deployments and load balancers are made ready as they are created
+	newClientset := func(objects ...runtime.Object) *fake.Clientset {
+		clientset := fake.NewSimpleClientset(objects...)
+		clientset.PrependReactor("create", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			deployment := action.(k8stesting.CreateAction).GetObject().(*appsv1.Deployment)
+			deployment.Status.ReadyReplicas = 1
+			return false, nil, nil
+		})
+		clientset.PrependReactor("create", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			service := action.(k8stesting.CreateAction).GetObject().(*corev1.Service)
+			if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+				service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}
+			}
+			return false, nil, nil
+		})
+		return clientset
+	}
+
+	envValue := func(container corev1.Container, name string) string {
+		for _, env := range container.Env {
+			if env.Name == name {
+				return env.Value
+			}
+		}
+		return ""
+	}
+
+	Describe("NewKubernetesProvisionerConfig", func() {
+		It("fails when namespace is not configured", func() {
+			// arrange
+			config := newConfig()
+			config.Set("provisioner.kubernetes.namespace", "")
+
+			// act
+			provisionerConfig, err := kubernetes_provisioner.NewKubernetesProvisionerConfig(config, fake.NewSimpleClientset())
+
+			// assert
+			Expect(err).To(HaveOccurred())
+			Expect(provisionerConfig).To(BeNil())
+		})
+	})
+
+	Describe("Provision", func() {
+		It("creates all deployments and services and returns the env vars", func() {
+			// arrange
+			clientset := newClientset()
+			provisioner := newProvisioner(newConfig(), clientset)
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+			Expect(result.EnvVars[provisioners.EnvVarEndpoint]).To(Equal("http://push-api-instance-1.pushaas:8080"))
+			Expect(result.EnvVars[provisioners.EnvVarUsername]).To(Equal("app"))
+			Expect(result.EnvVars[provisioners.EnvVarPassword]).NotTo(BeEmpty())
+
+			deployments, err := clientset.AppsV1().Deployments("pushaas").List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployments.Items).To(HaveLen(3))
+
+			services, err := clientset.CoreV1().Services("pushaas").List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(services.Items).To(HaveLen(3))
+
+			pushStream, err := clientset.AppsV1().Deployments("pushaas").Get("push-stream-instance-1", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pushStream.Spec.Template.Spec.Containers).To(HaveLen(2))
+			Expect(pushStream.Spec.Template.Spec.Containers[1].Name).To(Equal("push-agent"))
+
+			pushApi, err := clientset.AppsV1().Deployments("pushaas").Get("push-api-instance-1", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			container := pushApi.Spec.Template.Spec.Containers[0]
+			Expect(envValue(container, "PUSHAPI_REDIS__URL")).To(Equal("redis://push-redis-instance-1.pushaas:6379"))
+			Expect(envValue(container, "PUSHAPI_PUSH_STREAM__URL")).To(Equal("http://lb.example.com:9080"))
+			Expect(envValue(container, "PUSHAPI_API__BASIC_AUTH_PASSWORD")).To(Equal(result.EnvVars[provisioners.EnvVarPassword]))
+		})
+
+		It("addresses push-stream by its DNS when it is not a load balancer", func() {
+			// arrange
+			config := newConfig()
+			config.Set("provisioner.kubernetes.push_stream_service_type", "ClusterIP")
+			clientset := newClientset()
+			provisioner := newProvisioner(config, clientset)
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+			pushApi, err := clientset.AppsV1().Deployments("pushaas").Get("push-api-instance-1", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envValue(pushApi.Spec.Template.Spec.Containers[0], "PUSHAPI_PUSH_STREAM__URL")).To(Equal("http://push-stream-instance-1.pushaas:9080"))
+		})
+
+		It("indicates failure when a deployment cannot be created", func() {
+			// arrange
+			clientset := newClientset()
+			clientset.PrependReactor("create", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				deployment := action.(k8stesting.CreateAction).GetObject().(*appsv1.Deployment)
+				if deployment.Name == "push-stream-instance-1" {
+					return true, nil, errors.New("some error")
+				}
+				return false, nil, nil
+			})
+			provisioner := newProvisioner(newConfig(), clientset)
+
+			// act
+			result := provisioner.Provision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceProvisionStatusFailure))
+			Expect(result.EnvVars).To(BeEmpty())
+			_, err := clientset.AppsV1().Deployments("pushaas").Get("push-api-instance-1", metav1.GetOptions{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Inspect", func() {
+		It("reports every component with its ready replicas", func() {
+			// arrange
+			clientset := newClientset()
+			provisioner := newProvisioner(newConfig(), clientset)
+			Expect(provisioner.Provision(instance).Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+
+			// act
+			result := provisioner.Inspect(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceInspectStatusSuccess))
+			Expect(result.MissingComponents()).To(BeEmpty())
+			Expect(result.Component(provisioners.ComponentPushApi).RunningCount).To(Equal(int64(1)))
+		})
+
+		It("reports the components that are missing", func() {
+			// arrange
+			clientset := newClientset()
+			provisioner := newProvisioner(newConfig(), clientset)
+			Expect(provisioner.Provision(instance).Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+			Expect(clientset.AppsV1().Deployments("pushaas").Delete("push-api-instance-1", &metav1.DeleteOptions{})).To(Succeed())
+
+			// act
+			result := provisioner.Inspect(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceInspectStatusSuccess))
+			Expect(result.MissingComponents()).To(Equal([]string{provisioners.ComponentPushApi}))
+		})
+	})
+
+	Describe("Deprovision", func() {
+		It("removes all deployments and services", func() {
+			// arrange
+			clientset := newClientset()
+			provisioner := newProvisioner(newConfig(), clientset)
+			Expect(provisioner.Provision(instance).Status).To(Equal(provisioners.PushServiceProvisionStatusSuccess))
+
+			// act
+			result := provisioner.Deprovision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceDeprovisionStatusSuccess))
+
+			deployments, err := clientset.AppsV1().Deployments("pushaas").List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployments.Items).To(BeEmpty())
+
+			services, err := clientset.CoreV1().Services("pushaas").List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(services.Items).To(BeEmpty())
+		})
+
+		It("indicates failure when a deployment is not found", func() {
+			// arrange
+			clientset := newClientset()
+			provisioner := newProvisioner(newConfig(), clientset)
+
+			// act
+			result := provisioner.Deprovision(instance)
+
+			// assert
+			Expect(result.Status).To(Equal(provisioners.PushServiceDeprovisionStatusFailure))
+		})
+	})
+})
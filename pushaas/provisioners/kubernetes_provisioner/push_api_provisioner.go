@@ -0,0 +1,184 @@
+package kubernetes_provisioner
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+const pushApi = "push-api"
+const pushApiPort = 8080
+
+type (
+	KubernetesPushApiProvisioner interface {
+		Provision(*models.Instance, string, string, string, chan provisionPushApiResult)
+		Deprovision(*models.Instance, chan deprovisionPushApiResult)
+	}
+
+	kubernetesPushApiProvisioner struct {
+		logger            *zap.Logger
+		provisionerConfig *KubernetesProvisionerConfig
+	}
+
+	provisionPushApiResult struct {
+		deployment *appsv1.Deployment
+		service    *corev1.Service
+		err        error
+	}
+
+	deprovisionPushApiResult struct {
+		err error
+	}
+)
+
+func pushApiWithInstance(instanceName string) string {
+	return fmt.Sprintf("%s-%s", pushApi, instanceName)
+}
+
+/*
+	===========================================================================
+	provision
+	===========================================================================
+*/
+func (p *kubernetesPushApiProvisioner) Provision(instance *models.Instance, username string, password string, pushStreamPublicHost string, ch chan provisionPushApiResult) {
+	var err error
+
+	// create deployment
+	deployment, err := p.createDeployment(instance, username, password, pushStreamPublicHost)
+	if err != nil {
+		ch <- provisionPushApiResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-api] did create deployment")
+
+	// create service
+	service, err := p.createService(instance)
+	if err != nil {
+		ch <- provisionPushApiResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-api] did create service")
+
+	// wait for deployment to go up
+	waitCh := make(chan bool)
+	go waitDeploymentUp(p.logger, instance, waitCh, p.getDeployment)
+	if deploymentUp := <-waitCh; !deploymentUp {
+		ch <- provisionPushApiResult{err: errors.New("push-api deployment did not become available")}
+		return
+	}
+	p.logger.Debug("[push-api] deployment is up")
+
+	ch <- provisionPushApiResult{
+		deployment: deployment,
+		service:    service,
+	}
+}
+
+func (p *kubernetesPushApiProvisioner) createDeployment(
+	instance *models.Instance,
+	username string,
+	password string,
+	pushStreamPublicHost string,
+) (*appsv1.Deployment, error) {
+	deployment := newDeployment(pushApiWithInstance(instance.Name), instance, pushApi, p.provisionerConfig.namespace, []corev1.Container{
+		{
+			Name:  pushApi,
+			Image: p.provisionerConfig.imagePushApi,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("512Mi"),
+				},
+			},
+			Ports: []corev1.ContainerPort{
+				{
+					ContainerPort: pushApiPort,
+				},
+			},
+			Env: []corev1.EnvVar{
+				{
+					Name:  "PUSHAPI_REDIS__URL",
+					Value: fmt.Sprintf("redis://%s:%d", serviceDns(pushRedisWithInstance(instance.Name), p.provisionerConfig.namespace), pushRedisPort),
+				},
+				{
+					Name:  "PUSHAPI_PUSH_STREAM__URL",
+					Value: fmt.Sprintf("http://%s:%d", pushStreamPublicHost, pushStreamPort),
+				},
+				{
+					Name:  "PUSHAPI_API__BASIC_AUTH_USER",
+					Value: username,
+				},
+				{
+					Name:  "PUSHAPI_API__BASIC_AUTH_PASSWORD",
+					Value: password,
+				},
+			},
+		},
+	})
+
+	return p.provisionerConfig.kubernetes.AppsV1().Deployments(p.provisionerConfig.namespace).Create(deployment)
+}
+
+func (p *kubernetesPushApiProvisioner) createService(instance *models.Instance) (*corev1.Service, error) {
+	service := newService(pushApiWithInstance(instance.Name), instance, pushApi, p.provisionerConfig.namespace, corev1.ServiceTypeClusterIP, pushApiPort)
+	return p.provisionerConfig.kubernetes.CoreV1().Services(p.provisionerConfig.namespace).Create(service)
+}
+
+/*
+	===========================================================================
+	deprovision
+	===========================================================================
+*/
+func (p *kubernetesPushApiProvisioner) Deprovision(instance *models.Instance, ch chan deprovisionPushApiResult) {
+	var err error
+	name := pushApiWithInstance(instance.Name)
+
+	// delete service
+	err = deleteService(name, p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushApiResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-api] did delete service")
+
+	// delete deployment
+	err = deleteDeployment(name, p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushApiResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-api] did delete deployment")
+
+	// wait deployment to go away
+	waitCh := make(chan bool)
+	go waitDeploymentDown(p.logger, instance, waitCh, p.getDeployment)
+	if deploymentDown := <-waitCh; !deploymentDown {
+		ch <- deprovisionPushApiResult{err: errors.New("[push-api] deployment did not go down")}
+		return
+	}
+	p.logger.Debug("[push-api] deployment is down")
+
+	ch <- deprovisionPushApiResult{}
+}
+
+/*
+	===========================================================================
+	other
+	===========================================================================
+*/
+func (p *kubernetesPushApiProvisioner) getDeployment(instance *models.Instance) (*appsv1.Deployment, error) {
+	return getDeployment(pushApiWithInstance(instance.Name), p.provisionerConfig)
+}
+
+func NewKubernetesPushApiProvisioner(logger *zap.Logger, provisionerConfig *KubernetesProvisionerConfig) KubernetesPushApiProvisioner {
+	return &kubernetesPushApiProvisioner{
+		logger:            logger,
+		provisionerConfig: provisionerConfig,
+	}
+}
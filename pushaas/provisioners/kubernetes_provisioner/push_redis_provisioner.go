@@ -0,0 +1,154 @@
+package kubernetes_provisioner
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+const pushRedis = "push-redis"
+const pushRedisPort = 6379
+
+type (
+	KubernetesPushRedisProvisioner interface {
+		Provision(*models.Instance, chan provisionPushRedisResult)
+		Deprovision(*models.Instance, chan deprovisionPushRedisResult)
+	}
+
+	kubernetesPushRedisProvisioner struct {
+		logger            *zap.Logger
+		provisionerConfig *KubernetesProvisionerConfig
+	}
+
+	provisionPushRedisResult struct {
+		deployment *appsv1.Deployment
+		service    *corev1.Service
+		err        error
+	}
+
+	deprovisionPushRedisResult struct {
+		err error
+	}
+)
+
+func pushRedisWithInstance(instanceName string) string {
+	return fmt.Sprintf("%s-%s", pushRedis, instanceName)
+}
+
+/*
+	===========================================================================
+	provision
+	===========================================================================
+*/
+func (p *kubernetesPushRedisProvisioner) Provision(instance *models.Instance, ch chan provisionPushRedisResult) {
+	var err error
+
+	// create deployment
+	deployment, err := p.createDeployment(instance)
+	if err != nil {
+		ch <- provisionPushRedisResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-redis] did create deployment")
+
+	// create service
+	service, err := p.createService(instance)
+	if err != nil {
+		ch <- provisionPushRedisResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-redis] did create service")
+
+	// wait for deployment to go up
+	waitCh := make(chan bool)
+	go waitDeploymentUp(p.logger, instance, waitCh, p.getDeployment)
+	if deploymentUp := <-waitCh; !deploymentUp {
+		ch <- provisionPushRedisResult{err: errors.New("push-redis deployment did not become available")}
+		return
+	}
+	p.logger.Debug("[push-redis] deployment is up")
+
+	ch <- provisionPushRedisResult{
+		deployment: deployment,
+		service:    service,
+	}
+}
+
+func (p *kubernetesPushRedisProvisioner) createDeployment(instance *models.Instance) (*appsv1.Deployment, error) {
+	deployment := newDeployment(pushRedisWithInstance(instance.Name), instance, pushRedis, p.provisionerConfig.namespace, []corev1.Container{
+		{
+			Name:  pushRedis,
+			Image: p.provisionerConfig.imagePushRedis,
+			Ports: []corev1.ContainerPort{
+				{
+					ContainerPort: pushRedisPort,
+				},
+			},
+		},
+	})
+
+	return p.provisionerConfig.kubernetes.AppsV1().Deployments(p.provisionerConfig.namespace).Create(deployment)
+}
+
+func (p *kubernetesPushRedisProvisioner) createService(instance *models.Instance) (*corev1.Service, error) {
+	service := newService(pushRedisWithInstance(instance.Name), instance, pushRedis, p.provisionerConfig.namespace, corev1.ServiceTypeClusterIP, pushRedisPort)
+	return p.provisionerConfig.kubernetes.CoreV1().Services(p.provisionerConfig.namespace).Create(service)
+}
+
+/*
+	===========================================================================
+	deprovision
+	===========================================================================
+*/
+func (p *kubernetesPushRedisProvisioner) Deprovision(instance *models.Instance, ch chan deprovisionPushRedisResult) {
+	var err error
+	name := pushRedisWithInstance(instance.Name)
+
+	// delete service
+	err = deleteService(name, p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushRedisResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-redis] did delete service")
+
+	// delete deployment
+	err = deleteDeployment(name, p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushRedisResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-redis] did delete deployment")
+
+	// wait deployment to go away
+	waitCh := make(chan bool)
+	go waitDeploymentDown(p.logger, instance, waitCh, p.getDeployment)
+	if deploymentDown := <-waitCh; !deploymentDown {
+		ch <- deprovisionPushRedisResult{err: errors.New("[push-redis] deployment did not go down")}
+		return
+	}
+	p.logger.Debug("[push-redis] deployment is down")
+
+	ch <- deprovisionPushRedisResult{}
+}
+
+/*
+	===========================================================================
+	other
+	===========================================================================
+*/
+func (p *kubernetesPushRedisProvisioner) getDeployment(instance *models.Instance) (*appsv1.Deployment, error) {
+	return getDeployment(pushRedisWithInstance(instance.Name), p.provisionerConfig)
+}
+
+func NewKubernetesPushRedisProvisioner(logger *zap.Logger, provisionerConfig *KubernetesProvisionerConfig) KubernetesPushRedisProvisioner {
+	return &kubernetesPushRedisProvisioner{
+		logger:            logger,
+		provisionerConfig: provisionerConfig,
+	}
+}
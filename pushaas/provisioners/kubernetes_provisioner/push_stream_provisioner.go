@@ -0,0 +1,213 @@
+package kubernetes_provisioner
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+const pushAgent = "push-agent"
+const pushStream = "push-stream"
+const pushStreamPort = 9080
+
+type (
+	KubernetesPushStreamProvisioner interface {
+		Provision(*models.Instance, chan provisionPushStreamResult)
+		Deprovision(*models.Instance, chan deprovisionPushStreamResult)
+	}
+
+	kubernetesPushStreamProvisioner struct {
+		logger            *zap.Logger
+		provisionerConfig *KubernetesProvisionerConfig
+	}
+
+	provisionPushStreamResult struct {
+		deployment *appsv1.Deployment
+		service    *corev1.Service
+		publicHost string
+		err        error
+	}
+
+	deprovisionPushStreamResult struct {
+		err error
+	}
+)
+
+func pushStreamWithInstance(instanceName string) string {
+	return fmt.Sprintf("%s-%s", pushStream, instanceName)
+}
+
+/*
+	===========================================================================
+	provision
+	===========================================================================
+*/
+func (p *kubernetesPushStreamProvisioner) Provision(instance *models.Instance, ch chan provisionPushStreamResult) {
+	var err error
+
+	// create deployment
+	deployment, err := p.createDeployment(instance)
+	if err != nil {
+		ch <- provisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did create deployment")
+
+	// create service
+	service, err := p.createService(instance)
+	if err != nil {
+		ch <- provisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did create service")
+
+	// wait for deployment to go up
+	waitCh := make(chan bool)
+	go waitDeploymentUp(p.logger, instance, waitCh, p.getDeployment)
+	if deploymentUp := <-waitCh; !deploymentUp {
+		ch <- provisionPushStreamResult{err: errors.New("push-stream deployment did not become available")}
+		return
+	}
+	p.logger.Debug("[push-stream] deployment is up")
+
+	// only load balancers get an address outside the cluster, other types are addressed by their DNS
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		ch <- provisionPushStreamResult{
+			deployment: deployment,
+			service:    service,
+			publicHost: serviceDns(service.Name, p.provisionerConfig.namespace),
+		}
+		return
+	}
+
+	// wait for load balancer ingress
+	ingressCh := make(chan bool)
+	go waitServiceIngress(p.logger, instance, ingressCh, p.getService)
+	if isIngressUp := <-ingressCh; !isIngressUp {
+		ch <- provisionPushStreamResult{err: errors.New("push-stream load balancer failed to become available")}
+		return
+	}
+
+	// get load balancer ingress
+	service, err = p.getService(instance)
+	if err != nil {
+		ch <- provisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] load balancer is up")
+
+	ch <- provisionPushStreamResult{
+		deployment: deployment,
+		service:    service,
+		publicHost: serviceIngressHost(service),
+	}
+}
+
+func (p *kubernetesPushStreamProvisioner) createDeployment(instance *models.Instance) (*appsv1.Deployment, error) {
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("250m"),
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	}
+
+	deployment := newDeployment(pushStreamWithInstance(instance.Name), instance, pushStream, p.provisionerConfig.namespace, []corev1.Container{
+		{
+			Name:      pushStream,
+			Image:     p.provisionerConfig.imagePushStream,
+			Resources: resources,
+			Ports: []corev1.ContainerPort{
+				{
+					ContainerPort: pushStreamPort,
+				},
+			},
+		},
+		{
+			Name:      pushAgent,
+			Image:     p.provisionerConfig.imagePushAgent,
+			Resources: resources,
+			Env: []corev1.EnvVar{
+				{
+					Name:  "PUSHAGENT_REDIS__URL",
+					Value: fmt.Sprintf("redis://%s:%d", serviceDns(pushRedisWithInstance(instance.Name), p.provisionerConfig.namespace), pushRedisPort),
+				},
+				{
+					// containers on the same pod share the network
+					Name:  "PUSHAGENT_PUSH_STREAM__URL",
+					Value: fmt.Sprintf("http://localhost:%d", pushStreamPort),
+				},
+			},
+		},
+	})
+
+	return p.provisionerConfig.kubernetes.AppsV1().Deployments(p.provisionerConfig.namespace).Create(deployment)
+}
+
+func (p *kubernetesPushStreamProvisioner) createService(instance *models.Instance) (*corev1.Service, error) {
+	serviceType := corev1.ServiceType(p.provisionerConfig.pushStreamServiceType)
+	service := newService(pushStreamWithInstance(instance.Name), instance, pushStream, p.provisionerConfig.namespace, serviceType, pushStreamPort)
+	return p.provisionerConfig.kubernetes.CoreV1().Services(p.provisionerConfig.namespace).Create(service)
+}
+
+/*
+	===========================================================================
+	deprovision
+	===========================================================================
+*/
+func (p *kubernetesPushStreamProvisioner) Deprovision(instance *models.Instance, ch chan deprovisionPushStreamResult) {
+	var err error
+	name := pushStreamWithInstance(instance.Name)
+
+	// delete service
+	err = deleteService(name, p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did delete service")
+
+	// delete deployment
+	err = deleteDeployment(name, p.provisionerConfig)
+	if err != nil {
+		ch <- deprovisionPushStreamResult{err: err}
+		return
+	}
+	p.logger.Debug("[push-stream] did delete deployment")
+
+	// wait deployment to go away
+	waitCh := make(chan bool)
+	go waitDeploymentDown(p.logger, instance, waitCh, p.getDeployment)
+	if deploymentDown := <-waitCh; !deploymentDown {
+		ch <- deprovisionPushStreamResult{err: errors.New("[push-stream] deployment did not go down")}
+		return
+	}
+	p.logger.Debug("[push-stream] deployment is down")
+
+	ch <- deprovisionPushStreamResult{}
+}
+
+/*
+	===========================================================================
+	other
+	===========================================================================
+*/
+func (p *kubernetesPushStreamProvisioner) getDeployment(instance *models.Instance) (*appsv1.Deployment, error) {
+	return getDeployment(pushStreamWithInstance(instance.Name), p.provisionerConfig)
+}
+
+func (p *kubernetesPushStreamProvisioner) getService(instance *models.Instance) (*corev1.Service, error) {
+	return getService(pushStreamWithInstance(instance.Name), p.provisionerConfig)
+}
+
+func NewKubernetesPushStreamProvisioner(logger *zap.Logger, provisionerConfig *KubernetesProvisionerConfig) KubernetesPushStreamProvisioner {
+	return &kubernetesPushStreamProvisioner{
+		logger:            logger,
+		provisionerConfig: provisionerConfig,
+	}
+}
@@ -7,11 +7,20 @@ import (
 type (
 	PushServiceProvisionStatus   int
 	PushServiceDeprovisionStatus int
+	PushServiceInspectStatus     int
 
+	/*
+		Resources are the identifiers of what was created on the provider, by kind, recorded on the instance.
+		FailureReason tells why a failed provision failed, recorded on the instance too.
+	*/
 	PushServiceProvisionResult struct {
-		Instance *models.Instance
-		EnvVars  map[string]string
-		Status   PushServiceProvisionStatus
+		Instance      *models.Instance
+		EnvVars       map[string]string
+		Status        PushServiceProvisionStatus
+		Resources     map[string]string
+		FailureReason string
+		// what the instance had before the change, set by the workers on the changes that are undone when they fail
+		Previous *models.InstancePrevious
 	}
 
 	PushServiceDeprovisionResult struct {
@@ -19,9 +28,43 @@ type (
 		Status   PushServiceDeprovisionStatus
 	}
 
+	// the actual state of a component of an instance on the provider
+	PushServiceComponentState struct {
+		Name         string
+		Exists       bool
+		RunningCount int64
+	}
+
+	PushServiceInspectResult struct {
+		Instance   *models.Instance
+		Components []*PushServiceComponentState
+		Status     PushServiceInspectStatus
+	}
+
+	/*
+		Update applies the plan of an already provisioned instance. Its result only carries the env vars that changed.
+	*/
 	PushServiceProvisioner interface {
 		Provision(*models.Instance) *PushServiceProvisionResult
+		Update(*models.Instance) *PushServiceProvisionResult
 		Deprovision(*models.Instance) *PushServiceDeprovisionResult
+		Inspect(*models.Instance) *PushServiceInspectResult
+	}
+
+	/*
+		Implemented by the provisioners able to create again the components of an instance that went missing,
+		keeping the ones that still exist. It receives the current env vars of the instance so the credentials are kept.
+	*/
+	PushServiceRecreator interface {
+		Recreate(*models.Instance, map[string]string) *PushServiceProvisionResult
+	}
+
+	/*
+		Implemented by the provisioners able to replace the instance-wide password of push-api with a new one.
+		Its result only carries the env vars that changed.
+	*/
+	PushServiceCredentialRotator interface {
+		RotateCredentials(*models.Instance) *PushServiceProvisionResult
 	}
 )
 
@@ -35,6 +78,36 @@ const (
 	PushServiceDeprovisionStatusFailure
 )
 
+const (
+	PushServiceInspectStatusSuccess PushServiceInspectStatus = iota
+	PushServiceInspectStatusFailure
+)
+
+const ComponentPushRedis = "push-redis"
+const ComponentPushStream = "push-stream"
+const ComponentPushApi = "push-api"
+
+func (r *PushServiceInspectResult) Component(name string) *PushServiceComponentState {
+	for _, component := range r.Components {
+		if component.Name == name {
+			return component
+		}
+	}
+	return nil
+}
+
+func (r *PushServiceInspectResult) MissingComponents() []string {
+	var missing []string
+	for _, component := range r.Components {
+		if !component.Exists {
+			missing = append(missing, component.Name)
+		}
+	}
+	return missing
+}
+
 const EnvVarEndpoint = "PUSHAAS_ENDPOINT" // client apps use this var as the push-api endpoint
 const EnvVarPassword = "PUSHAAS_PASSWORD" // client apps use this var as password to authenticate to push-api
 const EnvVarUsername = "PUSHAAS_USERNAME" // client apps use this var as username to authenticate to push-api
+
+const ResourcePushStreamUrl = "push-stream.public-url" // the clients of the apps subscribe to push-stream on this url
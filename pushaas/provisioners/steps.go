@@ -0,0 +1,100 @@
+package provisioners
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	ProvisionStepStatusStarted   = ProvisionStepStatus("started")
+	ProvisionStepStatusCompleted = ProvisionStepStatus("completed")
+	// found to exist already when the provision started, so it is kept even when the provision is rolled back
+	ProvisionStepStatusExisting = ProvisionStepStatus("existing")
+)
+
+type (
+	ProvisionStepStatus string
+
+	/*
+		A step of a provision, persisted so that a provision interrupted midway (e.g. by a restart) can resume from
+		the last completed step when its task is redelivered. Value keeps whatever later steps need from it.
+	*/
+	ProvisionStep struct {
+		Name   string              `json:"name"`
+		Status ProvisionStepStatus `json:"status"`
+		Value  string              `json:"value"`
+	}
+
+	ProvisionStepStore interface {
+		GetSteps(instanceName string) (map[string]*ProvisionStep, error)
+		SetStep(instanceName string, step *ProvisionStep) error
+		DelSteps(instanceName string) error
+	}
+
+	provisionStepStore struct {
+		logger                 *zap.Logger
+		provisionStepKeyPrefix string
+		redisClient            redis.UniversalClient
+	}
+)
+
+func (s *provisionStepStore) provisionStepKey(instanceName string) string {
+	return fmt.Sprintf("%s:%s", s.provisionStepKeyPrefix, instanceName)
+}
+
+func (s *provisionStepStore) GetSteps(instanceName string) (map[string]*ProvisionStep, error) {
+	stepsMap, err := s.redisClient.HGetAll(s.provisionStepKey(instanceName)).Result()
+	if err != nil {
+		s.logger.Error("failed to retrieve provision steps", zap.String("instanceName", instanceName), zap.Error(err))
+		return nil, err
+	}
+
+	steps := make(map[string]*ProvisionStep, len(stepsMap))
+	for name, stepJson := range stepsMap {
+		var step ProvisionStep
+		err = json.Unmarshal([]byte(stepJson), &step)
+		if err != nil {
+			s.logger.Error("failed to decode provision step", zap.String("instanceName", instanceName), zap.String("step", name), zap.Error(err))
+			return nil, err
+		}
+		steps[name] = &step
+	}
+
+	return steps, nil
+}
+
+func (s *provisionStepStore) SetStep(instanceName string, step *ProvisionStep) error {
+	bytes, err := json.Marshal(step)
+	if err != nil {
+		s.logger.Error("failed to encode provision step", zap.String("instanceName", instanceName), zap.Any("step", step), zap.Error(err))
+		return err
+	}
+
+	err = s.redisClient.HSet(s.provisionStepKey(instanceName), step.Name, string(bytes)).Err()
+	if err != nil {
+		s.logger.Error("failed to persist provision step", zap.String("instanceName", instanceName), zap.Any("step", step), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *provisionStepStore) DelSteps(instanceName string) error {
+	err := s.redisClient.Del(s.provisionStepKey(instanceName)).Err()
+	if err != nil {
+		s.logger.Error("failed to delete provision steps", zap.String("instanceName", instanceName), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func NewProvisionStepStore(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) ProvisionStepStore {
+	return &provisionStepStore{
+		logger:                 logger.Named("provisionStepStore"),
+		provisionStepKeyPrefix: config.GetString("redis.db.provision_step.prefix"),
+		redisClient:            redisClient,
+	}
+}
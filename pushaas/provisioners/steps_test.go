@@ -0,0 +1,83 @@
+package provisioners_test
+
+import (
+	"errors"
+
+	"github.com/go-redis/redis"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+)
+
+var _ = Describe("ProvisionStepStore", func() {
+	config := viper.New()
+	config.Set("redis.db.provision_step.prefix", "provision-step")
+	instanceName := "instance-1"
+
+	Describe("GetSteps", func() {
+		It("decodes the persisted steps", func() {
+			// arrange
+			redisClient := &mocks.UniversalClientMock{
+				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
+					return redis.NewStringStringMapResult(map[string]string{
+						"push-stream-eni": `{"name":"push-stream-eni","status":"completed","value":"1.2.3.4"}`,
+					}, nil)
+				},
+			}
+			stepStore := provisioners.NewProvisionStepStore(config, logger, redisClient)
+
+			// act
+			steps, err := stepStore.GetSteps(instanceName)
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(steps).To(Equal(map[string]*provisioners.ProvisionStep{
+				"push-stream-eni": {Name: "push-stream-eni", Status: provisioners.ProvisionStepStatusCompleted, Value: "1.2.3.4"},
+			}))
+			Expect(redisClient.HGetAllCalls()[0].Key).To(Equal("provision-step:instance-1"))
+		})
+
+		It("indicates when fails to retrieve the steps", func() {
+			// arrange
+			redisClient := &mocks.UniversalClientMock{
+				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
+					return redis.NewStringStringMapResult(nil, errors.New("some error"))
+				},
+			}
+			stepStore := provisioners.NewProvisionStepStore(config, logger, redisClient)
+
+			// act
+			steps, err := stepStore.GetSteps(instanceName)
+
+			// assert
+			Expect(err).To(HaveOccurred())
+			Expect(steps).To(BeNil())
+		})
+	})
+
+	Describe("SetStep", func() {
+		It("persists the step under its name", func() {
+			// arrange
+			redisClient := &mocks.UniversalClientMock{
+				HSetFunc: func(key string, field string, value interface{}) *redis.BoolCmd {
+					return redis.NewBoolResult(true, nil)
+				},
+			}
+			stepStore := provisioners.NewProvisionStepStore(config, logger, redisClient)
+
+			// act
+			err := stepStore.SetStep(instanceName, &provisioners.ProvisionStep{Name: "push-redis", Status: provisioners.ProvisionStepStatusStarted})
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			calls := redisClient.HSetCalls()
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Key).To(Equal("provision-step:instance-1"))
+			Expect(calls[0].Field).To(Equal("push-redis"))
+			Expect(calls[0].Value).To(Equal(`{"name":"push-redis","status":"started","value":""}`))
+		})
+	})
+})
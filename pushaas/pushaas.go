@@ -17,10 +17,16 @@ func runApp(
 	router *gin.Engine,
 	config *viper.Viper,
 	machineryWorker workers.MachineryWorker,
+	reconcileWorker workers.ReconcileWorker,
+	gcWorker workers.GcWorker,
+	outboxWorker workers.OutboxWorker,
 ) error {
 	log := logger.Named("runApp")
 
 	machineryWorker.DispatchWorker()
+	reconcileWorker.DispatchWorker()
+	gcWorker.DispatchWorker()
+	outboxWorker.DispatchWorker()
 
 	err := router.Run(fmt.Sprintf(":%s", config.GetString("server.port")))
 	if err != nil {
@@ -47,26 +53,50 @@ func Run() {
 			ctors.NewAuthRouter,
 			ctors.NewInstanceRouter,
 			ctors.NewBindRouter,
+			ctors.NewCredentialRouter,
+			ctors.NewEventRouter,
+			ctors.NewAppRouter,
+			ctors.NewGcRouter,
+			ctors.NewAdminRouter,
+			ctors.NewTokenRouter,
+			ctors.NewAuditRouter,
+			ctors.NewDeadLetterRouter,
+			ctors.NewOperationRouter,
 
 			// services
 			ctors.NewInstanceService,
 			ctors.NewBindService,
 			ctors.NewPlanService,
 			ctors.NewProvisionService,
+			ctors.NewGcService,
+			ctors.NewPushApiService,
+			ctors.NewTsuruService,
+			ctors.NewCredentialService,
+			ctors.NewEventService,
+			ctors.NewAuthService,
+			ctors.NewAuditService,
+			ctors.NewDeadLetterService,
+			ctors.NewInstanceLockService,
+			ctors.NewOperationService,
+			ctors.NewTaskOutboxService,
+
+			// repositories
+			ctors.NewRepository,
+			ctors.NewInstanceRepository,
+			ctors.NewBindRepository,
+			ctors.NewTaskOutboxRepository,
 
 			// provisioners
+			ctors.NewProvisionStepStore,
 			ctors.NewPushServiceProvisioner,
 
-			// provisioner - ecs
-			ctors.NewEcsProvisionerConfig,
-			ctors.NewEcsPushRedisProvisioner,
-			ctors.NewEcsPushStreamProvisioner,
-			ctors.NewEcsPushApiProvisioner,
-
 			// workers
 			ctors.NewInstanceWorker,
 			ctors.NewProvisionWorker,
 			ctors.NewMachineryWorker,
+			ctors.NewReconcileWorker,
+			ctors.NewGcWorker,
+			ctors.NewOutboxWorker,
 		),
 		fx.Invoke(runApp),
 	)
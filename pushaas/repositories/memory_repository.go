@@ -0,0 +1,501 @@
+package repositories
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+type (
+	/*
+		Keeps everything in memory, so it is lost on restart and not shared between replicas.
+		Meant for tests and for running locally. Records are copied in and out, so callers never share them.
+	*/
+	memoryRepository struct {
+		mutex     sync.RWMutex
+		instances map[string]models.Instance
+		history   map[string][]models.InstanceStatusTransition
+		vars      map[string]map[string]string
+		bindApps  map[string]map[string]models.BindApp
+		bindUnits map[string]map[string]bool
+		outbox    map[string]memoryPendingTask
+	}
+
+	// a pending task and until when it is claimed
+	memoryPendingTask struct {
+		task         models.PendingTask
+		claimedUntil time.Time
+	}
+)
+
+func (r *memoryRepository) bindUnitKey(instanceName, appName string) string {
+	return instanceName + ":" + appName
+}
+
+/*
+	===========================================================================
+	instances
+	===========================================================================
+*/
+// the resources are a map, so they are copied too
+func copyInstance(instance models.Instance) *models.Instance {
+	if instance.Resources != nil {
+		resources := make(map[string]string, len(instance.Resources))
+		for kind, id := range instance.Resources {
+			resources[kind] = id
+		}
+		instance.Resources = resources
+	}
+	return &instance
+}
+
+func (r *memoryRepository) created(name string) int64 {
+	return millis(*r.instances[name].CreatedAt)
+}
+
+// the names of the instances in the order they were created
+func (r *memoryRepository) sortedNames() []string {
+	names := make([]string, 0, len(r.instances))
+	for name := range r.instances {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if r.created(names[i]) != r.created(names[j]) {
+			return r.created(names[i]) < r.created(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func (r *memoryRepository) GetAll() ([]*models.Instance, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	instances := make([]*models.Instance, 0, len(r.instances))
+	for _, name := range r.sortedNames() {
+		instances = append(instances, copyInstance(r.instances[name]))
+	}
+	return instances, nil
+}
+
+func (r *memoryRepository) List(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, error) {
+	after, err := decodeInstanceCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	page := &models.InstancePage{Instances: []*models.Instance{}}
+	for _, name := range r.sortedNames() {
+		instance := r.instances[name]
+		if !after.precedes(r.created(name), name) || !filter.Matches(&instance) {
+			continue
+		}
+		if len(page.Instances) == limit {
+			last := page.Instances[limit-1].Name
+			page.NextCursor = (&instanceCursor{created: r.created(last), name: last}).encode()
+			break
+		}
+		page.Instances = append(page.Instances, copyInstance(instance))
+	}
+	return page, nil
+}
+
+func (r *memoryRepository) Get(name string) (*models.Instance, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	instance, ok := r.instances[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyInstance(instance), nil
+}
+
+func (r *memoryRepository) Create(instance *models.Instance) error {
+	return r.CreateWithTask(instance, nil)
+}
+
+func (r *memoryRepository) CreateWithTask(instance *models.Instance, task *models.PendingTask) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.instances[instance.Name]; ok {
+		return ErrAlreadyExists
+	}
+	created := copyInstance(*instance)
+	transition := stampCreation(created, now())
+	r.instances[instance.Name] = *created
+	r.history[instance.Name] = []models.InstanceStatusTransition{*transition}
+	r.addPendingTask(task)
+	return nil
+}
+
+func (r *memoryRepository) Update(name string, update *InstanceUpdate) error {
+	return r.UpdateWithTask(name, update, nil)
+}
+
+func (r *memoryRepository) UpdateWithTask(name string, update *InstanceUpdate, task *models.PendingTask) error {
+	if len(update.fields()) == 0 {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	instance, ok := r.instances[name]
+	if !ok {
+		return ErrNotFound
+	}
+	if update.Status != "" && update.From != "" && instance.Status != update.From {
+		return ErrStatusChanged
+	}
+
+	if update.Plan != "" {
+		instance.Plan = update.Plan
+	}
+	if update.Team != "" {
+		instance.Team = update.Team
+	}
+	if update.Description != "" {
+		instance.Description = update.Description
+	}
+	updated := now()
+	instance.UpdatedAt = &updated
+	if update.Status != "" {
+		transition := transitStatus(&instance, update.Status, update.Reason, updated)
+		r.history[name] = append(r.history[name], *transition)
+		if update.Rollback != nil {
+			instance.Rollback = *update.Rollback
+		}
+		r.setVars(name, update.Vars)
+		r.addPendingTask(task)
+	}
+	r.instances[name] = instance
+	return nil
+}
+
+func (r *memoryRepository) UpdateStatus(name string, status models.InstanceStatus, reason string) error {
+	return r.UpdateStatusWithTask(name, status, reason, nil)
+}
+
+func (r *memoryRepository) UpdateStatusWithTask(name string, status models.InstanceStatus, reason string, task *models.PendingTask) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	instance, ok := r.instances[name]
+	if !ok {
+		return ErrNotFound
+	}
+	transition := transitStatus(&instance, status, reason, now())
+	r.instances[name] = instance
+	r.history[name] = append(r.history[name], *transition)
+	r.addPendingTask(task)
+	return nil
+}
+
+func (r *memoryRepository) UpdateRollback(name string, rollback models.InstanceRollback) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	instance, ok := r.instances[name]
+	if !ok {
+		return ErrNotFound
+	}
+	instance.Rollback = rollback
+	updated := now()
+	instance.UpdatedAt = &updated
+	r.instances[name] = instance
+	return nil
+}
+
+func (r *memoryRepository) UpdateResources(name string, resources map[string]string) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, ok := r.instances[name]
+	if !ok {
+		return ErrNotFound
+	}
+	instance := copyInstance(stored)
+	if instance.Resources == nil {
+		instance.Resources = map[string]string{}
+	}
+	for kind, id := range resources {
+		instance.Resources[kind] = id
+	}
+	updated := now()
+	instance.UpdatedAt = &updated
+	r.instances[name] = *instance
+	return nil
+}
+
+func (r *memoryRepository) Delete(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.instances[name]; !ok {
+		return ErrNotFound
+	}
+	delete(r.instances, name)
+	delete(r.history, name)
+	delete(r.bindApps, name)
+	for key := range r.bindUnits {
+		if strings.HasPrefix(key, r.bindUnitKey(name, "")) {
+			delete(r.bindUnits, key)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetStatusHistory(name string) ([]*models.InstanceStatusTransition, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if _, ok := r.instances[name]; !ok {
+		return nil, ErrNotFound
+	}
+	history := make([]*models.InstanceStatusTransition, 0, len(r.history[name]))
+	for _, transition := range r.history[name] {
+		transition := transition
+		history = append(history, &transition)
+	}
+	return history, nil
+}
+
+/*
+	===========================================================================
+	vars
+	===========================================================================
+*/
+func (r *memoryRepository) GetVars(name string) (map[string]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	vars := map[string]string{}
+	for k, v := range r.vars[name] {
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+func (r *memoryRepository) SetVars(name string, vars map[string]string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.setVars(name, vars)
+	return nil
+}
+
+// the caller holds the mutex
+func (r *memoryRepository) setVars(name string, vars map[string]string) {
+	if len(vars) == 0 {
+		return
+	}
+	if _, ok := r.vars[name]; !ok {
+		r.vars[name] = map[string]string{}
+	}
+	for k, v := range vars {
+		r.vars[name][k] = v
+	}
+}
+
+func (r *memoryRepository) DelVars(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.vars, name)
+	return nil
+}
+
+/*
+	===========================================================================
+	binds
+	===========================================================================
+*/
+func (r *memoryRepository) GetBindApps(instanceName string) ([]*models.BindApp, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	bindApps := make([]*models.BindApp, 0, len(r.bindApps[instanceName]))
+	for _, bindApp := range r.bindApps[instanceName] {
+		bindApp := bindApp
+		bindApps = append(bindApps, &bindApp)
+	}
+	return bindApps, nil
+}
+
+func (r *memoryRepository) GetBindApp(instanceName, appName string) (*models.BindApp, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	bindApp, ok := r.bindApps[instanceName][appName]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &bindApp, nil
+}
+
+func (r *memoryRepository) CreateBindApp(instanceName string, bindApp *models.BindApp) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.bindApps[instanceName][bindApp.AppName]; ok {
+		return ErrAlreadyExists
+	}
+	if _, ok := r.bindApps[instanceName]; !ok {
+		r.bindApps[instanceName] = map[string]models.BindApp{}
+	}
+	r.bindApps[instanceName][bindApp.AppName] = *bindApp
+	return nil
+}
+
+func (r *memoryRepository) SaveBindApp(instanceName string, bindApp *models.BindApp) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.bindApps[instanceName]; !ok {
+		r.bindApps[instanceName] = map[string]models.BindApp{}
+	}
+	r.bindApps[instanceName][bindApp.AppName] = *bindApp
+	return nil
+}
+
+func (r *memoryRepository) DelBindApp(instanceName, appName string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.bindApps[instanceName][appName]; !ok {
+		return ErrNotFound
+	}
+	delete(r.bindApps[instanceName], appName)
+	delete(r.bindUnits, r.bindUnitKey(instanceName, appName))
+	return nil
+}
+
+func (r *memoryRepository) GetInstancesBoundTo(appName string) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	instanceNames := []string{}
+	for instanceName, bindApps := range r.bindApps {
+		if _, ok := bindApps[appName]; ok {
+			instanceNames = append(instanceNames, instanceName)
+		}
+	}
+	sort.Strings(instanceNames)
+	return instanceNames, nil
+}
+
+func (r *memoryRepository) GetBindUnits(instanceName, appName string) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	unitHosts := make([]string, 0, len(r.bindUnits[r.bindUnitKey(instanceName, appName)]))
+	for unitHost := range r.bindUnits[r.bindUnitKey(instanceName, appName)] {
+		unitHosts = append(unitHosts, unitHost)
+	}
+	sort.Strings(unitHosts)
+	return unitHosts, nil
+}
+
+func (r *memoryRepository) AddBindUnit(instanceName, appName, unitHost string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := r.bindUnitKey(instanceName, appName)
+	if _, ok := r.bindUnits[key]; !ok {
+		r.bindUnits[key] = map[string]bool{}
+	}
+	if r.bindUnits[key][unitHost] {
+		return ErrAlreadyExists
+	}
+	r.bindUnits[key][unitHost] = true
+	return nil
+}
+
+func (r *memoryRepository) RemoveBindUnit(instanceName, appName, unitHost string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := r.bindUnitKey(instanceName, appName)
+	if !r.bindUnits[key][unitHost] {
+		return ErrNotFound
+	}
+	delete(r.bindUnits[key], unitHost)
+	return nil
+}
+
+/*
+	===========================================================================
+	outbox
+	===========================================================================
+*/
+// the mutex is held by the caller
+func (r *memoryRepository) addPendingTask(task *models.PendingTask) {
+	if task == nil {
+		return
+	}
+	r.outbox[task.Id] = memoryPendingTask{task: *task}
+}
+
+func (r *memoryRepository) ClaimPendingTasks(until time.Time, limit int) ([]*models.PendingTask, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	claimable := []*models.PendingTask{}
+	claimedAt := time.Now()
+	for _, pending := range r.outbox {
+		if pending.claimedUntil.After(claimedAt) {
+			continue
+		}
+		task := pending.task
+		claimable = append(claimable, &task)
+	}
+	sort.Slice(claimable, func(i, j int) bool {
+		if !claimable[i].CreatedAt.Equal(claimable[j].CreatedAt) {
+			return claimable[i].CreatedAt.Before(claimable[j].CreatedAt)
+		}
+		return claimable[i].Id < claimable[j].Id
+	})
+	if len(claimable) > limit {
+		claimable = claimable[:limit]
+	}
+
+	for _, task := range claimable {
+		r.outbox[task.Id] = memoryPendingTask{task: *task, claimedUntil: until}
+	}
+	return claimable, nil
+}
+
+func (r *memoryRepository) DeletePendingTask(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.outbox[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.outbox, id)
+	return nil
+}
+
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		instances: map[string]models.Instance{},
+		history:   map[string][]models.InstanceStatusTransition{},
+		vars:      map[string]map[string]string{},
+		bindApps:  map[string]map[string]models.BindApp{},
+		bindUnits: map[string]map[string]bool{},
+		outbox:    map[string]memoryPendingTask{},
+	}
+}
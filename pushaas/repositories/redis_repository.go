@@ -0,0 +1,989 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/structs"
+	"github.com/go-redis/redis"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+type (
+	/*
+		Keeps every record on its own key: instances and bound apps as hashes of their fields,
+		the vars of an instance as a hash, the history of an instance as a list and the units of a bound app as a set.
+		The names of the instances are also kept on a sorted set, scored by when they were created,
+		so that they are listed without scanning the keys, and the names of the apps bound to each instance on a set,
+		as well as the names of the instances each app is bound to.
+		The pending tasks are kept on a single hash, by their ids, and their ids on a sorted set scored by when
+		they can be claimed, which is when they were written until they are claimed, and then when the claim expires.
+	*/
+	redisRepository struct {
+		logger                *zap.Logger
+		redisClient           redis.UniversalClient
+		instanceIndexKey      string
+		instanceKeyPrefix     string
+		instanceVarsKeyPrefix string
+		historyKeyPrefix      string
+		bindAppKeyPrefix      string
+		bindAppIndexKeyPrefix string
+		appIndexKeyPrefix     string
+		bindUnitKeyPrefix     string
+		outboxKey             string
+		outboxIndexKey        string
+	}
+)
+
+// how many times a transaction is tried when its key is written concurrently
+const watchAttempts = 3
+
+// how many names are read from the index at a time when listing instances
+const listBatchSize = 100
+
+// each resource of an instance is kept on a field of its hash, so that they are added without being read first
+const resourceFieldPrefix = "Resources."
+
+const timeLayout = time.RFC3339Nano
+
+/*
+	runs the transaction watching the key, so that it is discarded when the key is written meanwhile,
+	in which case it is run again from the start
+*/
+func (r *redisRepository) watch(key string, transaction func(tx *redis.Tx) error) error {
+	var err error
+	for attempt := 0; attempt < watchAttempts; attempt++ {
+		err = r.redisClient.Watch(transaction, key)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return err
+}
+
+/*
+	writes the hash only if the key does not exist, watching the key so that the write is discarded when
+	the key is written between the check and the write. Only one of concurrent creations succeeds, the others
+	try again and find the key. alsoWrite, when given, writes along with the hash.
+*/
+func (r *redisRepository) createHash(key string, fields map[string]interface{}, alsoWrite func(pipe redis.Pipeliner)) error {
+	create := func(tx *redis.Tx) error {
+		exists, err := tx.Exists(key).Result()
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			return ErrAlreadyExists
+		}
+
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.HMSet(key, fields)
+			if alsoWrite != nil {
+				alsoWrite(pipe)
+			}
+			return nil
+		})
+		return err
+	}
+
+	return r.watch(key, create)
+}
+
+func (r *redisRepository) instanceKey(name string) string {
+	return fmt.Sprintf("%s:%s", r.instanceKeyPrefix, name)
+}
+
+func (r *redisRepository) instanceVarsKey(name string) string {
+	return fmt.Sprintf("%s:%s", r.instanceVarsKeyPrefix, name)
+}
+
+func (r *redisRepository) historyKey(name string) string {
+	return fmt.Sprintf("%s:%s", r.historyKeyPrefix, name)
+}
+
+func (r *redisRepository) bindAppKey(instanceName, appName string) string {
+	return fmt.Sprintf("%s:%s:%s", r.bindAppKeyPrefix, instanceName, appName)
+}
+
+func (r *redisRepository) bindAppIndexKey(instanceName string) string {
+	return fmt.Sprintf("%s:%s", r.bindAppIndexKeyPrefix, instanceName)
+}
+
+func (r *redisRepository) appIndexKey(appName string) string {
+	return fmt.Sprintf("%s:%s", r.appIndexKeyPrefix, appName)
+}
+
+func (r *redisRepository) bindUnitKey(instanceName, appName string) string {
+	return fmt.Sprintf("%s:%s:%s", r.bindUnitKeyPrefix, instanceName, appName)
+}
+
+/*
+	===========================================================================
+	instances
+	===========================================================================
+*/
+/*
+	a hash only holds strings, so the times are kept formatted
+*/
+func instanceFields(instance *models.Instance) map[string]interface{} {
+	fields := map[string]interface{}{
+		"Name":          instance.Name,
+		"Plan":          instance.Plan,
+		"Team":          instance.Team,
+		"User":          instance.User,
+		"Description":   instance.Description,
+		"Status":        instance.Status,
+		"Rollback":      instance.Rollback,
+		"FailureReason": instance.FailureReason,
+	}
+	for field, t := range map[string]*time.Time{
+		"CreatedAt":     instance.CreatedAt,
+		"UpdatedAt":     instance.UpdatedAt,
+		"ProvisionedAt": instance.ProvisionedAt,
+	} {
+		if t != nil {
+			fields[field] = t.Format(timeLayout)
+		}
+	}
+	for kind, id := range instance.Resources {
+		fields[resourceFieldPrefix+kind] = id
+	}
+	return fields
+}
+
+func decodeInstance(instanceMap map[string]string) (*models.Instance, error) {
+	fields := make(map[string]interface{}, len(instanceMap))
+	resources := map[string]string{}
+	for field, value := range instanceMap {
+		if strings.HasPrefix(field, resourceFieldPrefix) {
+			resources[strings.TrimPrefix(field, resourceFieldPrefix)] = value
+			continue
+		}
+		fields[field] = value
+	}
+	if len(resources) > 0 {
+		fields["Resources"] = resources
+	}
+
+	var instance models.Instance
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeHookFunc(timeLayout),
+		Result:     &instance,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.Decode(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+/*
+	instances created before the index existed are added to it as if they were created before all others
+*/
+func (r *redisRepository) indexExistingInstances() error {
+	patternAllInstanceKeys := r.instanceKey("*")
+	keyPrefixLength := len(r.instanceKey(""))
+	var cursor uint64
+	for {
+		keys, nextCursor, err := r.redisClient.Scan(cursor, patternAllInstanceKeys, listBatchSize).Result()
+		if err != nil {
+			r.logger.Error("failed to scan instance keys", zap.String("patternAllInstanceKeys", patternAllInstanceKeys), zap.Error(err))
+			return err
+		}
+
+		members := make([]redis.Z, 0, len(keys))
+		for _, key := range keys {
+			members = append(members, redis.Z{Score: 0, Member: key[keyPrefixLength:]})
+		}
+		if len(members) > 0 {
+			err = r.redisClient.ZAddNX(r.instanceIndexKey, members...).Err()
+			if err != nil {
+				r.logger.Error("failed to index instances", zap.Error(err))
+				return err
+			}
+		}
+
+		if nextCursor == 0 {
+			return nil
+		}
+		cursor = nextCursor
+	}
+}
+
+/*
+	retrieves the instances in the order of the names, leaving out those removed after the names were read
+*/
+func (r *redisRepository) getInstances(names []string) ([]*models.Instance, error) {
+	if len(names) == 0 {
+		return []*models.Instance{}, nil
+	}
+
+	pipeline := r.redisClient.Pipeline()
+	defer func() {
+		err := pipeline.Close()
+		if err != nil {
+			r.logger.Error("failed to close pipeline", zap.Error(err))
+		}
+	}()
+
+	for _, name := range names {
+		pipeline.HGetAll(r.instanceKey(name))
+	}
+
+	results, err := pipeline.Exec()
+	if err != nil {
+		r.logger.Error("failed to execute pipeline to retrieve instances", zap.Error(err))
+		return nil, err
+	}
+
+	instances := make([]*models.Instance, 0, len(names))
+	for i, cmd := range results {
+		instanceMap, err := cmd.(*redis.StringStringMapCmd).Result()
+		if err != nil {
+			r.logger.Error("failed to retrieve instance", zap.String("name", names[i]), zap.Error(err))
+			return nil, err
+		}
+		if len(instanceMap) == 0 {
+			continue
+		}
+
+		instance, err := decodeInstance(instanceMap)
+		if err != nil {
+			r.logger.Error("failed to decode instance", zap.String("name", names[i]), zap.Error(err))
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+
+	return instances, nil
+}
+
+func (r *redisRepository) GetAll() ([]*models.Instance, error) {
+	names, err := r.redisClient.ZRange(r.instanceIndexKey, 0, -1).Result()
+	if err != nil {
+		r.logger.Error("failed to retrieve instance names", zap.Error(err))
+		return nil, err
+	}
+	return r.getInstances(names)
+}
+
+/*
+	the index is read in batches from the position of the cursor, and the filter is applied to the instances
+	of each batch until the page is filled. The position is found by score, the instances created at the same
+	time as the one on the cursor are ordered by name, like on the index, and skipped up to the cursor.
+*/
+func (r *redisRepository) List(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, error) {
+	after, err := decodeInstanceCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var start int64
+	if after != nil {
+		start, err = r.redisClient.ZCount(r.instanceIndexKey, "-inf", fmt.Sprintf("(%d", after.created)).Result()
+		if err != nil {
+			r.logger.Error("failed to find the position of the cursor", zap.String("cursor", cursor), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	page := &models.InstancePage{Instances: []*models.Instance{}}
+	var last *instanceCursor
+	for {
+		entries, err := r.redisClient.ZRangeWithScores(r.instanceIndexKey, start, start+listBatchSize-1).Result()
+		if err != nil {
+			r.logger.Error("failed to retrieve instance names", zap.Error(err))
+			return nil, err
+		}
+		start += int64(len(entries))
+
+		names := make([]string, 0, len(entries))
+		created := make(map[string]int64, len(entries))
+		for _, entry := range entries {
+			name := entry.Member.(string)
+			created[name] = int64(entry.Score)
+			if after.precedes(created[name], name) {
+				names = append(names, name)
+			}
+		}
+
+		instances, err := r.getInstances(names)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			if !filter.Matches(instance) {
+				continue
+			}
+			if len(page.Instances) == limit {
+				page.NextCursor = last.encode()
+				return page, nil
+			}
+			page.Instances = append(page.Instances, instance)
+			last = &instanceCursor{created: created[instance.Name], name: instance.Name}
+		}
+
+		if len(entries) < listBatchSize {
+			return page, nil
+		}
+	}
+}
+
+func (r *redisRepository) Get(name string) (*models.Instance, error) {
+	instanceMap, err := r.redisClient.HGetAll(r.instanceKey(name)).Result()
+	if err != nil {
+		r.logger.Error("failed to retrieve instance", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+	if len(instanceMap) == 0 {
+		return nil, ErrNotFound
+	}
+
+	instance, err := decodeInstance(instanceMap)
+	if err != nil {
+		r.logger.Error("failed to decode instance", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+func (r *redisRepository) instanceExists(name string) (bool, error) {
+	exists, err := r.redisClient.Exists(r.instanceKey(name)).Result()
+	if err != nil {
+		r.logger.Error("failed to check instance existence", zap.String("name", name), zap.Error(err))
+		return false, err
+	}
+	return exists > 0, nil
+}
+
+/*
+	a hash is created by writing any of its fields, so the instance is checked to exist before it is changed,
+	watching it so that an instance removed between the check and the write is not left behind as a partial record
+*/
+func (r *redisRepository) updateInstance(name string, fields map[string]interface{}) error {
+	key := r.instanceKey(name)
+	update := func(tx *redis.Tx) error {
+		exists, err := tx.Exists(key).Result()
+		if err != nil {
+			return err
+		}
+		if exists == 0 {
+			return ErrNotFound
+		}
+
+		fields["UpdatedAt"] = now().Format(timeLayout)
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.HMSet(key, fields)
+			return nil
+		})
+		return err
+	}
+
+	err := r.watch(key, update)
+	if err == ErrNotFound {
+		return err
+	} else if err != nil {
+		r.logger.Error("failed to update instance", zap.String("name", name), zap.Any("fields", fields), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *redisRepository) Create(instance *models.Instance) error {
+	return r.CreateWithTask(instance, nil)
+}
+
+func (r *redisRepository) CreateWithTask(instance *models.Instance, task *models.PendingTask) error {
+	created := *instance
+	transition := stampCreation(&created, now())
+	entry, err := json.Marshal(transition)
+	if err != nil {
+		return err
+	}
+	encodedTask, err := encodePendingTask(task)
+	if err != nil {
+		return err
+	}
+
+	indexAndStartHistory := func(pipe redis.Pipeliner) {
+		pipe.ZAdd(r.instanceIndexKey, redis.Z{Score: float64(millis(*created.CreatedAt)), Member: instance.Name})
+		pipe.Del(r.historyKey(instance.Name))
+		pipe.RPush(r.historyKey(instance.Name), entry)
+		r.addPendingTask(pipe, task, encodedTask)
+	}
+	err = r.createHash(r.instanceKey(instance.Name), instanceFields(&created), indexAndStartHistory)
+	if err == ErrAlreadyExists {
+		return err
+	} else if err != nil {
+		r.logger.Error("failed to create instance", zap.Any("instance", instance), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *redisRepository) Update(name string, update *InstanceUpdate) error {
+	return r.UpdateWithTask(name, update, nil)
+}
+
+func (r *redisRepository) UpdateWithTask(name string, update *InstanceUpdate, task *models.PendingTask) error {
+	fields := update.fields()
+	if len(fields) == 0 {
+		return nil
+	}
+
+	interfaceMap := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		interfaceMap[k] = v
+	}
+	if update.Status == "" {
+		return r.updateInstance(name, interfaceMap)
+	}
+
+	delete(interfaceMap, "Status")
+	if update.Rollback != nil {
+		interfaceMap["Rollback"] = string(*update.Rollback)
+	}
+	return r.transitInstance(name, update.From, update.Status, update.Reason, interfaceMap, update.Vars, task)
+}
+
+func (r *redisRepository) UpdateStatus(name string, status models.InstanceStatus, reason string) error {
+	return r.transitInstance(name, "", status, reason, map[string]interface{}{}, nil, nil)
+}
+
+func (r *redisRepository) UpdateStatusWithTask(name string, status models.InstanceStatus, reason string, task *models.PendingTask) error {
+	return r.transitInstance(name, "", status, reason, map[string]interface{}{}, nil, task)
+}
+
+/*
+	the current status is read to be recorded on the history, and checked against the expected one, if any,
+	watching the instance so that the status is not changed by someone else between the read and the write.
+	The other fields given, the vars and the task, if any, are written along.
+*/
+func (r *redisRepository) transitInstance(name string, from, status models.InstanceStatus, reason string, otherFields map[string]interface{}, vars map[string]string, task *models.PendingTask) error {
+	encodedTask, err := encodePendingTask(task)
+	if err != nil {
+		return err
+	}
+
+	key := r.instanceKey(name)
+	transit := func(tx *redis.Tx) error {
+		instanceMap, err := tx.HGetAll(key).Result()
+		if err != nil {
+			return err
+		}
+		if len(instanceMap) == 0 {
+			return ErrNotFound
+		}
+		instance, err := decodeInstance(instanceMap)
+		if err != nil {
+			return err
+		}
+		if from != "" && instance.Status != from {
+			return ErrStatusChanged
+		}
+
+		transition := transitStatus(instance, status, reason, now())
+		entry, err := json.Marshal(transition)
+		if err != nil {
+			return err
+		}
+
+		// only what a transition changes is written
+		fields := instanceFields(instance)
+		changed := map[string]interface{}{}
+		for field, value := range otherFields {
+			changed[field] = value
+		}
+		for _, field := range []string{"Status", "UpdatedAt", "ProvisionedAt", "FailureReason"} {
+			if value, ok := fields[field]; ok {
+				changed[field] = value
+			}
+		}
+
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.HMSet(key, changed)
+			if len(vars) > 0 {
+				pipe.HMSet(r.instanceVarsKey(name), varsFields(vars))
+			}
+			pipe.RPush(r.historyKey(name), entry)
+			r.addPendingTask(pipe, task, encodedTask)
+			return nil
+		})
+		return err
+	}
+
+	err = r.watch(key, transit)
+	if err == ErrNotFound || err == ErrStatusChanged {
+		return err
+	} else if err != nil {
+		r.logger.Error("failed to update instance status", zap.String("name", name), zap.String("status", string(status)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *redisRepository) UpdateRollback(name string, rollback models.InstanceRollback) error {
+	return r.updateInstance(name, map[string]interface{}{"Rollback": rollback})
+}
+
+func (r *redisRepository) UpdateResources(name string, resources map[string]string) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(resources))
+	for kind, id := range resources {
+		fields[resourceFieldPrefix+kind] = id
+	}
+	return r.updateInstance(name, fields)
+}
+
+/*
+	the bindings still recorded for the instance go along with it, watching its index so that an app bound
+	meanwhile is not left behind
+*/
+func (r *redisRepository) Delete(name string) error {
+	indexKey := r.bindAppIndexKey(name)
+	var deleted int64
+	remove := func(tx *redis.Tx) error {
+		appNames, err := tx.SMembers(indexKey).Result()
+		if err != nil {
+			return err
+		}
+
+		var del *redis.IntCmd
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			del = pipe.Del(r.instanceKey(name))
+			pipe.Del(r.historyKey(name))
+			pipe.ZRem(r.instanceIndexKey, name)
+			for _, appName := range appNames {
+				pipe.Del(r.bindAppKey(name, appName), r.bindUnitKey(name, appName))
+				pipe.SRem(r.appIndexKey(appName), name)
+			}
+			pipe.Del(indexKey)
+			return nil
+		})
+		deleted = del.Val()
+		return err
+	}
+
+	err := r.watch(indexKey, remove)
+	if err != nil {
+		r.logger.Error("failed to delete instance", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *redisRepository) GetStatusHistory(name string) ([]*models.InstanceStatusTransition, error) {
+	exists, err := r.instanceExists(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	entries, err := r.redisClient.LRange(r.historyKey(name), 0, -1).Result()
+	if err != nil {
+		r.logger.Error("failed to retrieve instance status history", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+
+	history := make([]*models.InstanceStatusTransition, 0, len(entries))
+	for _, entry := range entries {
+		var transition models.InstanceStatusTransition
+		err = json.Unmarshal([]byte(entry), &transition)
+		if err != nil {
+			r.logger.Error("failed to decode instance status transition", zap.String("name", name), zap.String("entry", entry), zap.Error(err))
+			return nil, err
+		}
+		history = append(history, &transition)
+	}
+	return history, nil
+}
+
+/*
+	===========================================================================
+	vars
+	===========================================================================
+*/
+func varsFields(vars map[string]string) map[string]interface{} {
+	fields := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		fields[k] = v
+	}
+	return fields
+}
+
+func (r *redisRepository) GetVars(name string) (map[string]string, error) {
+	vars, err := r.redisClient.HGetAll(r.instanceVarsKey(name)).Result()
+	if err != nil {
+		r.logger.Error("failed to retrieve instance vars", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+	return vars, nil
+}
+
+func (r *redisRepository) SetVars(name string, vars map[string]string) error {
+	if len(vars) == 0 {
+		return nil
+	}
+
+	err := r.redisClient.HMSet(r.instanceVarsKey(name), varsFields(vars)).Err()
+	if err != nil {
+		r.logger.Error("failed to set instance vars", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *redisRepository) DelVars(name string) error {
+	err := r.redisClient.Del(r.instanceVarsKey(name)).Err()
+	if err != nil {
+		r.logger.Error("failed to delete instance vars", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+/*
+	===========================================================================
+	binds
+	===========================================================================
+*/
+/*
+	bindings made before the index existed are added to it
+*/
+func (r *redisRepository) indexExistingBindApps() error {
+	patternAllBindAppKeys := r.bindAppKey("*", "*")
+	keyPrefixLength := len(r.bindAppKeyPrefix) + 1
+	var cursor uint64
+	for {
+		keys, nextCursor, err := r.redisClient.Scan(cursor, patternAllBindAppKeys, listBatchSize).Result()
+		if err != nil {
+			r.logger.Error("failed to scan bindApp keys", zap.String("patternAllBindAppKeys", patternAllBindAppKeys), zap.Error(err))
+			return err
+		}
+
+		for _, key := range keys {
+			// instance names never have a colon, app names may
+			names := strings.SplitN(key[keyPrefixLength:], ":", 2)
+			_, err = r.redisClient.Pipelined(func(pipe redis.Pipeliner) error {
+				pipe.SAdd(r.bindAppIndexKey(names[0]), names[1])
+				pipe.SAdd(r.appIndexKey(names[1]), names[0])
+				return nil
+			})
+			if err != nil {
+				r.logger.Error("failed to index bindApp", zap.String("key", key), zap.Error(err))
+				return err
+			}
+		}
+
+		if nextCursor == 0 {
+			return nil
+		}
+		cursor = nextCursor
+	}
+}
+
+func (r *redisRepository) GetBindApps(instanceName string) ([]*models.BindApp, error) {
+	appNames, err := r.redisClient.SMembers(r.bindAppIndexKey(instanceName)).Result()
+	if err != nil {
+		r.logger.Error("failed to retrieve bindApp index", zap.String("instanceName", instanceName), zap.Error(err))
+		return nil, err
+	}
+	sort.Strings(appNames)
+
+	bindApps := make([]*models.BindApp, 0, len(appNames))
+	for _, appName := range appNames {
+		bindApp, err := r.GetBindApp(instanceName, appName)
+		// removed after the index was read
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		bindApps = append(bindApps, bindApp)
+	}
+
+	return bindApps, nil
+}
+
+func (r *redisRepository) GetBindApp(instanceName, appName string) (*models.BindApp, error) {
+	bindAppMap, err := r.redisClient.HGetAll(r.bindAppKey(instanceName, appName)).Result()
+	if err != nil {
+		r.logger.Error("failed to retrieve bindApp", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.Error(err))
+		return nil, err
+	}
+	if len(bindAppMap) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var bindApp models.BindApp
+	err = mapstructure.Decode(bindAppMap, &bindApp)
+	if err != nil {
+		r.logger.Error("failed to decode bindApp", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.Error(err))
+		return nil, err
+	}
+
+	return &bindApp, nil
+}
+
+func (r *redisRepository) CreateBindApp(instanceName string, bindApp *models.BindApp) error {
+	indexBindApp := func(pipe redis.Pipeliner) {
+		pipe.SAdd(r.bindAppIndexKey(instanceName), bindApp.AppName)
+		pipe.SAdd(r.appIndexKey(bindApp.AppName), instanceName)
+	}
+	err := r.createHash(r.bindAppKey(instanceName, bindApp.AppName), structs.Map(bindApp), indexBindApp)
+	if err == ErrAlreadyExists {
+		return err
+	} else if err != nil {
+		r.logger.Error("failed to create bindApp", zap.String("instanceName", instanceName), zap.Any("bindApp", bindApp), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *redisRepository) SaveBindApp(instanceName string, bindApp *models.BindApp) error {
+	_, err := r.redisClient.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HMSet(r.bindAppKey(instanceName, bindApp.AppName), structs.Map(bindApp))
+		pipe.SAdd(r.bindAppIndexKey(instanceName), bindApp.AppName)
+		pipe.SAdd(r.appIndexKey(bindApp.AppName), instanceName)
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to save bindApp", zap.String("instanceName", instanceName), zap.Any("bindApp", bindApp), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *redisRepository) DelBindApp(instanceName, appName string) error {
+	var del *redis.IntCmd
+	_, err := r.redisClient.TxPipelined(func(pipe redis.Pipeliner) error {
+		del = pipe.Del(r.bindAppKey(instanceName, appName))
+		pipe.Del(r.bindUnitKey(instanceName, appName))
+		pipe.SRem(r.bindAppIndexKey(instanceName), appName)
+		pipe.SRem(r.appIndexKey(appName), instanceName)
+		return nil
+	})
+	deleted := del.Val()
+	if err != nil {
+		r.logger.Error("failed to delete bindApp", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.Error(err))
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *redisRepository) GetInstancesBoundTo(appName string) ([]string, error) {
+	instanceNames, err := r.redisClient.SMembers(r.appIndexKey(appName)).Result()
+	if err != nil {
+		r.logger.Error("failed to retrieve instances bound to app", zap.String("appName", appName), zap.Error(err))
+		return nil, err
+	}
+	sort.Strings(instanceNames)
+	return instanceNames, nil
+}
+
+func (r *redisRepository) GetBindUnits(instanceName, appName string) ([]string, error) {
+	unitHosts, err := r.redisClient.SMembers(r.bindUnitKey(instanceName, appName)).Result()
+	if err != nil {
+		r.logger.Error("failed to retrieve bindUnits", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.Error(err))
+		return nil, err
+	}
+	sort.Strings(unitHosts)
+	return unitHosts, nil
+}
+
+func (r *redisRepository) AddBindUnit(instanceName, appName, unitHost string) error {
+	added, err := r.redisClient.SAdd(r.bindUnitKey(instanceName, appName), unitHost).Result()
+	if err != nil {
+		r.logger.Error("failed to add bindUnit", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.String("unitHost", unitHost), zap.Error(err))
+		return err
+	}
+	if added == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+func (r *redisRepository) RemoveBindUnit(instanceName, appName, unitHost string) error {
+	removed, err := r.redisClient.SRem(r.bindUnitKey(instanceName, appName), unitHost).Result()
+	if err != nil {
+		r.logger.Error("failed to remove bindUnit", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.String("unitHost", unitHost), zap.Error(err))
+		return err
+	}
+	if removed == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+/*
+	===========================================================================
+	outbox
+	===========================================================================
+*/
+func encodePendingTask(task *models.PendingTask) (string, error) {
+	if task == nil {
+		return "", nil
+	}
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func (r *redisRepository) addPendingTask(pipe redis.Pipeliner, task *models.PendingTask, encodedTask string) {
+	if task == nil {
+		return
+	}
+	pipe.HSet(r.outboxKey, task.Id, encodedTask)
+	pipe.ZAdd(r.outboxIndexKey, redis.Z{Score: float64(millis(task.CreatedAt)), Member: task.Id})
+}
+
+/*
+	the claim pushes the score of the tasks to when it expires, watching the index so that concurrent claims do
+	not take the same tasks
+*/
+func (r *redisRepository) ClaimPendingTasks(until time.Time, limit int) ([]*models.PendingTask, error) {
+	var ids []string
+	claim := func(tx *redis.Tx) error {
+		var err error
+		ids, err = tx.ZRangeByScore(r.outboxIndexKey, redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   strconv.FormatInt(millis(now()), 10),
+			Count: int64(limit),
+		}).Result()
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			for _, id := range ids {
+				pipe.ZAdd(r.outboxIndexKey, redis.Z{Score: float64(millis(until)), Member: id})
+			}
+			return nil
+		})
+		return err
+	}
+
+	err := r.watch(r.outboxIndexKey, claim)
+	if err != nil {
+		r.logger.Error("failed to claim pending tasks", zap.Error(err))
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*models.PendingTask{}, nil
+	}
+
+	values, err := r.redisClient.HMGet(r.outboxKey, ids...).Result()
+	if err != nil {
+		r.logger.Error("failed to retrieve claimed pending tasks", zap.Strings("ids", ids), zap.Error(err))
+		return nil, err
+	}
+
+	tasks := make([]*models.PendingTask, 0, len(values))
+	undecodable := []string{}
+	for i, value := range values {
+		// deleted since it was claimed
+		encoded, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var task models.PendingTask
+		err = json.Unmarshal([]byte(encoded), &task)
+		if err != nil {
+			r.logger.Error("failed to decode pending task, dropping it from the outbox", zap.String("id", ids[i]), zap.String("task", encoded), zap.Error(err))
+			undecodable = append(undecodable, ids[i])
+			continue
+		}
+		tasks = append(tasks, &task)
+	}
+
+	/*
+		a task that cannot be decoded can never be sent, and would otherwise be claimed again on every relay,
+		so it is dropped, having been logged above
+	*/
+	if len(undecodable) > 0 {
+		_, err = r.redisClient.TxPipelined(func(pipe redis.Pipeliner) error {
+			for _, id := range undecodable {
+				pipe.HDel(r.outboxKey, id)
+				pipe.ZRem(r.outboxIndexKey, id)
+			}
+			return nil
+		})
+		if err != nil {
+			r.logger.Error("failed to drop undecodable pending tasks", zap.Strings("ids", undecodable), zap.Error(err))
+		}
+	}
+	return tasks, nil
+}
+
+func (r *redisRepository) DeletePendingTask(id string) error {
+	var rem *redis.IntCmd
+	_, err := r.redisClient.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HDel(r.outboxKey, id)
+		rem = pipe.ZRem(r.outboxIndexKey, id)
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to delete pending task", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if rem.Val() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+/*
+	the instances and bindings that are not on the indexes yet are indexed, so that they are listed
+*/
+func NewRedisRepository(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) (Repository, error) {
+	repository := &redisRepository{
+		logger:                logger.Named("redisRepository"),
+		redisClient:           redisClient,
+		instanceIndexKey:      config.GetString("redis.db.instance.index"),
+		instanceKeyPrefix:     config.GetString("redis.db.instance.prefix"),
+		instanceVarsKeyPrefix: config.GetString("redis.db.instance.vars_prefix"),
+		historyKeyPrefix:      config.GetString("redis.db.instance.history_prefix"),
+		bindAppKeyPrefix:      config.GetString("redis.db.bind_app.prefix"),
+		bindAppIndexKeyPrefix: config.GetString("redis.db.bind_app.index_prefix"),
+		appIndexKeyPrefix:     config.GetString("redis.db.bind_app.app_index_prefix"),
+		bindUnitKeyPrefix:     config.GetString("redis.db.bind_unit.prefix"),
+		outboxKey:             config.GetString("redis.db.outbox.key"),
+		outboxIndexKey:        config.GetString("redis.db.outbox.index"),
+	}
+
+	err := repository.indexExistingInstances()
+	if err != nil {
+		return nil, err
+	}
+	err = repository.indexExistingBindApps()
+	if err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
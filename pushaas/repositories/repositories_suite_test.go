@@ -0,0 +1,19 @@
+package repositories_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+var logger *zap.Logger
+
+func TestRepositories(t *testing.T) {
+	logger = zaptest.NewLogger(t)
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Repositories Suite")
+}
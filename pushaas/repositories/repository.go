@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrStatusChanged = errors.New("status changed")
+)
+
+type (
+	/*
+		Only the fields that are set are changed, so that a change made by the API does not overwrite
+		what the workers recorded meanwhile (and the other way around).
+		A change of status is recorded on the history along with its reason. When From is set, the status is only
+		changed while the instance still has that status, and ErrStatusChanged is returned otherwise.
+		Rollback, when not nil, and Vars are only written along with a change of status, so that the outcome of an
+		operation is written whole or not at all.
+	*/
+	InstanceUpdate struct {
+		Plan        string
+		Team        string
+		Description string
+		Status      models.InstanceStatus
+		Reason      string
+		From        models.InstanceStatus
+		Rollback    *models.InstanceRollback
+		Vars        map[string]string
+	}
+
+	/*
+		Keeps the instances and their vars (the env vars given to the apps bound to them).
+		Create checks and writes at once, so that only one of concurrent creations of the same name succeeds.
+		List pages through the instances in the order they were created, the cursor being opaque to the callers.
+		Every change of status, starting from the creation, is appended to the history of the instance, which is
+		removed along with it. UpdateResources adds to the resources already recorded, replacing those of the same kind.
+		CreateWithTask, UpdateWithTask and UpdateStatusWithTask write a task for the workers along with the change,
+		so that neither is kept without the other. UpdateWithTask only writes the task along with a change of status.
+	*/
+	InstanceRepository interface {
+		GetAll() ([]*models.Instance, error)
+		List(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, error)
+		Get(name string) (*models.Instance, error)
+		Create(instance *models.Instance) error
+		CreateWithTask(instance *models.Instance, task *models.PendingTask) error
+		Update(name string, update *InstanceUpdate) error
+		UpdateWithTask(name string, update *InstanceUpdate, task *models.PendingTask) error
+		UpdateStatus(name string, status models.InstanceStatus, reason string) error
+		UpdateStatusWithTask(name string, status models.InstanceStatus, reason string, task *models.PendingTask) error
+		UpdateRollback(name string, rollback models.InstanceRollback) error
+		UpdateResources(name string, resources map[string]string) error
+		Delete(name string) error
+
+		GetStatusHistory(name string) ([]*models.InstanceStatusTransition, error)
+
+		GetVars(name string) (map[string]string, error)
+		SetVars(name string, vars map[string]string) error
+		DelVars(name string) error
+	}
+
+	/*
+		Keeps the apps bound to the instances and the units of each of these apps.
+		CreateBindApp checks and writes at once, like Create on instances, while SaveBindApp overwrites.
+		The units of an app are removed along with it, and whatever is still bound to an instance along with the instance.
+	*/
+	BindRepository interface {
+		GetBindApps(instanceName string) ([]*models.BindApp, error)
+		GetBindApp(instanceName, appName string) (*models.BindApp, error)
+		CreateBindApp(instanceName string, bindApp *models.BindApp) error
+		SaveBindApp(instanceName string, bindApp *models.BindApp) error
+		DelBindApp(instanceName, appName string) error
+		// the names of the instances the app is bound to
+		GetInstancesBoundTo(appName string) ([]string, error)
+
+		GetBindUnits(instanceName, appName string) ([]string, error)
+		AddBindUnit(instanceName, appName, unitHost string) error
+		RemoveBindUnit(instanceName, appName, unitHost string) error
+	}
+
+	/*
+		Keeps the tasks written along with the changes of the instances until they are sent to the workers.
+		ClaimPendingTasks takes, in the order they were written, the tasks that are not claimed or whose claim
+		expired, and keeps them from being claimed again until the given time, so that concurrent relays do not
+		send the same task. A task is deleted once sent, and one whose relay went away before that is claimed again
+		when its claim expires, so a task may be sent more than once.
+	*/
+	TaskOutboxRepository interface {
+		ClaimPendingTasks(until time.Time, limit int) ([]*models.PendingTask, error)
+		DeletePendingTask(id string) error
+	}
+
+	/*
+		A storage backend, able to keep everything.
+	*/
+	Repository interface {
+		InstanceRepository
+		BindRepository
+		TaskOutboxRepository
+	}
+)
+
+/*
+	the position after the last instance of a page: when that instance was created and its name, which breaks the
+	ties between instances created at the same time
+*/
+type instanceCursor struct {
+	created int64
+	name    string
+}
+
+// to the millisecond, so that the times are kept by every backend without losing precision
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// fits the scores of redis sorted sets without losing precision
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+}
+
+/*
+	stamps a new instance, returning the first entry of its history
+*/
+func stampCreation(instance *models.Instance, at time.Time) *models.InstanceStatusTransition {
+	instance.CreatedAt = &at
+	instance.UpdatedAt = &at
+	return &models.InstanceStatusTransition{To: instance.Status, At: at}
+}
+
+/*
+	changes the status of the instance, returning the entry for its history. The failure reason is only kept
+	while the instance is failed, and the instance is provisioned the first time it gets running.
+*/
+func transitStatus(instance *models.Instance, status models.InstanceStatus, reason string, at time.Time) *models.InstanceStatusTransition {
+	transition := &models.InstanceStatusTransition{From: instance.Status, To: status, Reason: reason, At: at}
+
+	instance.Status = status
+	instance.UpdatedAt = &at
+	instance.FailureReason = ""
+	if status == models.InstanceStatusFailed {
+		instance.FailureReason = reason
+	}
+	if status == models.InstanceStatusRunning && instance.ProvisionedAt == nil {
+		instance.ProvisionedAt = &at
+	}
+	return transition
+}
+
+func (c *instanceCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", c.created, c.name)))
+}
+
+// an empty cursor is the start of the listing, and decodes to nil
+func decodeInstanceCursor(cursor string) (*instanceCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, ErrInvalidCursor
+	}
+	created, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &instanceCursor{created: created, name: parts[1]}, nil
+}
+
+// whether the instance comes after the cursor, and so belongs to the following pages
+func (c *instanceCursor) precedes(created int64, name string) bool {
+	if c == nil {
+		return true
+	}
+	return created > c.created || (created == c.created && name > c.name)
+}
+
+/*
+	the fields of the update that are set, by the name of the fields of models.Instance
+*/
+func (u *InstanceUpdate) fields() map[string]string {
+	fields := map[string]string{}
+	if u.Plan != "" {
+		fields["Plan"] = u.Plan
+	}
+	if u.Team != "" {
+		fields["Team"] = u.Team
+	}
+	if u.Description != "" {
+		fields["Description"] = u.Description
+	}
+	if u.Status != "" {
+		fields["Status"] = string(u.Status)
+	}
+	return fields
+}
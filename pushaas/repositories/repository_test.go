@@ -0,0 +1,954 @@
+package repositories_test
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/repositories"
+)
+
+/*
+	runs the same operation from many goroutines at once, returning what each of them got
+*/
+func concurrently(times int, operation func() error) []error {
+	errs := make([]error, times)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < times; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = operation()
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+func countSucceeded(errs []error) int {
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			Expect(err).To(Equal(repositories.ErrAlreadyExists))
+		}
+	}
+	return succeeded
+}
+
+/*
+	every backend has to behave the same, so the same specs run against all of them
+*/
+func describeRepository(backend string, newRepository func() (repositories.Repository, func())) {
+	Describe(backend, func() {
+		var repository repositories.Repository
+		var cleanup func()
+
+		instance := &models.Instance{
+			Name:   "instance-1",
+			Plan:   "small",
+			Team:   "pushaas-team",
+			User:   "rafael",
+			Status: models.InstanceStatusPending,
+		}
+
+		BeforeEach(func() {
+			repository, cleanup = newRepository()
+		})
+
+		AfterEach(func() {
+			cleanup()
+		})
+
+		Describe("instances", func() {
+			It("creates and retrieves an instance", func() {
+				// act
+				err := repository.Create(instance)
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				retrieved, err := repository.Get("instance-1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retrieved.CreatedAt).NotTo(BeNil())
+				Expect(retrieved.UpdatedAt).To(Equal(retrieved.CreatedAt))
+				retrieved.CreatedAt, retrieved.UpdatedAt = nil, nil
+				Expect(retrieved).To(Equal(instance))
+			})
+
+			It("indicates when the instance already exists", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+
+				// act
+				err := repository.Create(&models.Instance{Name: "instance-1", Plan: "large"})
+
+				// assert
+				Expect(err).To(Equal(repositories.ErrAlreadyExists))
+				retrieved, _ := repository.Get("instance-1")
+				Expect(retrieved.Plan).To(Equal("small"))
+			})
+
+			It("lets only one of concurrent creations of the same instance succeed", func() {
+				// act
+				errs := concurrently(20, func() error {
+					return repository.Create(instance)
+				})
+
+				// assert
+				Expect(countSucceeded(errs)).To(Equal(1))
+			})
+
+			It("indicates when the instance is not found", func() {
+				// act
+				retrieved, err := repository.Get("instance-1")
+
+				// assert
+				Expect(err).To(Equal(repositories.ErrNotFound))
+				Expect(retrieved).To(BeNil())
+			})
+
+			It("retrieves all instances", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+				Expect(repository.Create(&models.Instance{Name: "instance-2"})).To(Succeed())
+
+				// act
+				instances, err := repository.GetAll()
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instances).To(HaveLen(2))
+			})
+
+			Describe("listing", func() {
+				// named against the order of creation, which is the order of the listing
+				names := []string{"instance-e", "instance-d", "instance-c", "instance-b", "instance-a"}
+
+				createInstances := func() {
+					for i, name := range names {
+						team := "team-even"
+						if i%2 == 1 {
+							team = "team-odd"
+						}
+						Expect(repository.Create(&models.Instance{Name: name, Team: team, Plan: "small", Status: models.InstanceStatusRunning})).To(Succeed())
+						// so that each one is created at a different time
+						time.Sleep(2 * time.Millisecond)
+					}
+				}
+
+				listNames := func(page *models.InstancePage) []string {
+					listed := []string{}
+					for _, instance := range page.Instances {
+						listed = append(listed, instance.Name)
+					}
+					return listed
+				}
+
+				It("lists the instances in the order they were created, page by page", func() {
+					// arrange
+					createInstances()
+
+					// act
+					first, errFirst := repository.List(&models.InstanceFilter{}, "", 2)
+					second, errSecond := repository.List(&models.InstanceFilter{}, first.NextCursor, 2)
+					third, errThird := repository.List(&models.InstanceFilter{}, second.NextCursor, 2)
+
+					// assert
+					Expect(errFirst).NotTo(HaveOccurred())
+					Expect(errSecond).NotTo(HaveOccurred())
+					Expect(errThird).NotTo(HaveOccurred())
+					Expect(listNames(first)).To(Equal([]string{"instance-e", "instance-d"}))
+					Expect(listNames(second)).To(Equal([]string{"instance-c", "instance-b"}))
+					Expect(listNames(third)).To(Equal([]string{"instance-a"}))
+					Expect(first.NextCursor).NotTo(BeEmpty())
+					Expect(third.NextCursor).To(BeEmpty())
+				})
+
+				It("lists only the instances that match the filter", func() {
+					// arrange
+					createInstances()
+
+					// act
+					first, errFirst := repository.List(&models.InstanceFilter{Team: "team-even", Status: models.InstanceStatusRunning}, "", 2)
+					second, errSecond := repository.List(&models.InstanceFilter{Team: "team-even", Status: models.InstanceStatusRunning}, first.NextCursor, 2)
+					none, errNone := repository.List(&models.InstanceFilter{Plan: "large"}, "", 2)
+
+					// assert
+					Expect(errFirst).NotTo(HaveOccurred())
+					Expect(errSecond).NotTo(HaveOccurred())
+					Expect(errNone).NotTo(HaveOccurred())
+					Expect(listNames(first)).To(Equal([]string{"instance-e", "instance-c"}))
+					Expect(listNames(second)).To(Equal([]string{"instance-a"}))
+					Expect(second.NextCursor).To(BeEmpty())
+					Expect(none.Instances).To(BeEmpty())
+				})
+
+				It("keeps its place when the last listed instance is deleted", func() {
+					// arrange
+					createInstances()
+					first, _ := repository.List(&models.InstanceFilter{}, "", 2)
+					Expect(repository.Delete("instance-d")).To(Succeed())
+
+					// act
+					second, err := repository.List(&models.InstanceFilter{}, first.NextCursor, 2)
+
+					// assert
+					Expect(err).NotTo(HaveOccurred())
+					Expect(listNames(second)).To(Equal([]string{"instance-c", "instance-b"}))
+				})
+
+				It("indicates when the cursor is invalid", func() {
+					// act
+					page, err := repository.List(&models.InstanceFilter{}, "not-a-cursor", 2)
+
+					// assert
+					Expect(err).To(Equal(repositories.ErrInvalidCursor))
+					Expect(page).To(BeNil())
+				})
+			})
+
+			It("retrieves no instances when there are none", func() {
+				// act
+				instances, err := repository.GetAll()
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instances).To(BeEmpty())
+			})
+
+			It("only changes the fields that are set", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+
+				// act
+				err := repository.Update("instance-1", &repositories.InstanceUpdate{Team: "other-team", Status: models.InstanceStatusRunning})
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				retrieved, _ := repository.Get("instance-1")
+				Expect(retrieved.Team).To(Equal("other-team"))
+				Expect(retrieved.Status).To(Equal(models.InstanceStatusRunning))
+				Expect(retrieved.Plan).To(Equal("small"))
+				Expect(retrieved.User).To(Equal("rafael"))
+			})
+
+			It("updates status and rollback", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+
+				// act
+				errStatus := repository.UpdateStatus("instance-1", models.InstanceStatusFailed, "some reason")
+				errRollback := repository.UpdateRollback("instance-1", models.InstanceRollbackCompleted)
+
+				// assert
+				Expect(errStatus).NotTo(HaveOccurred())
+				Expect(errRollback).NotTo(HaveOccurred())
+				retrieved, _ := repository.Get("instance-1")
+				Expect(retrieved.Status).To(Equal(models.InstanceStatusFailed))
+				Expect(retrieved.Rollback).To(Equal(models.InstanceRollbackCompleted))
+			})
+
+			It("records every change of status on the history", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+
+				// act
+				errFailed := repository.UpdateStatus("instance-1", models.InstanceStatusFailed, "some reason")
+				failed, _ := repository.Get("instance-1")
+				errRunning := repository.UpdateStatus("instance-1", models.InstanceStatusRunning, "")
+				running, _ := repository.Get("instance-1")
+				errPending := repository.Update("instance-1", &repositories.InstanceUpdate{Plan: "large", Status: models.InstanceStatusPending, Reason: "plan change"})
+
+				// assert
+				Expect(errFailed).NotTo(HaveOccurred())
+				Expect(errRunning).NotTo(HaveOccurred())
+				Expect(errPending).NotTo(HaveOccurred())
+				Expect(failed.FailureReason).To(Equal("some reason"))
+				Expect(failed.ProvisionedAt).To(BeNil())
+				Expect(running.FailureReason).To(BeEmpty())
+				Expect(running.ProvisionedAt).NotTo(BeNil())
+				Expect(running.UpdatedAt).To(Equal(running.ProvisionedAt))
+
+				history, err := repository.GetStatusHistory("instance-1")
+				Expect(err).NotTo(HaveOccurred())
+				transitions := []models.InstanceStatusTransition{}
+				for _, transition := range history {
+					Expect(transition.At.IsZero()).To(BeFalse())
+					transitions = append(transitions, models.InstanceStatusTransition{From: transition.From, To: transition.To, Reason: transition.Reason})
+				}
+				Expect(transitions).To(Equal([]models.InstanceStatusTransition{
+					{From: "", To: models.InstanceStatusPending},
+					{From: models.InstanceStatusPending, To: models.InstanceStatusFailed, Reason: "some reason"},
+					{From: models.InstanceStatusFailed, To: models.InstanceStatusRunning},
+					{From: models.InstanceStatusRunning, To: models.InstanceStatusPending, Reason: "plan change"},
+				}))
+			})
+
+			It("changes the status only while the instance still has the expected one", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+				Expect(repository.UpdateStatus("instance-1", models.InstanceStatusDeprovisioning, "deleted")).To(Succeed())
+
+				// act
+				errChanged := repository.Update("instance-1", &repositories.InstanceUpdate{Status: models.InstanceStatusRunning, From: models.InstanceStatusPending})
+				errExpected := repository.Update("instance-1", &repositories.InstanceUpdate{Status: models.InstanceStatusFailed, Reason: "gone", From: models.InstanceStatusDeprovisioning})
+
+				// assert
+				Expect(errChanged).To(Equal(repositories.ErrStatusChanged))
+				Expect(errExpected).NotTo(HaveOccurred())
+				history, _ := repository.GetStatusHistory("instance-1")
+				Expect(history).To(HaveLen(3))
+				Expect(history[2].From).To(Equal(models.InstanceStatusDeprovisioning))
+				Expect(history[2].To).To(Equal(models.InstanceStatusFailed))
+			})
+
+			It("writes the rollback and the vars along with the status, only while the instance still has the expected one", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+				Expect(repository.SetVars("instance-1", map[string]string{"PUSHAAS_PASSWORD": "password-1"})).To(Succeed())
+				rollback := models.InstanceRollbackCompleted
+
+				// act
+				errChanged := repository.Update("instance-1", &repositories.InstanceUpdate{
+					Status:   models.InstanceStatusRunning,
+					From:     models.InstanceStatusRunning,
+					Rollback: &rollback,
+					Vars:     map[string]string{"PUSHAAS_PASSWORD": "stale-password"},
+				})
+				errExpected := repository.Update("instance-1", &repositories.InstanceUpdate{
+					Status:   models.InstanceStatusFailed,
+					From:     models.InstanceStatusPending,
+					Rollback: &rollback,
+					Vars:     map[string]string{"PUSHAAS_ENDPOINT": "endpoint-1"},
+				})
+
+				// assert
+				Expect(errChanged).To(Equal(repositories.ErrStatusChanged))
+				Expect(errExpected).NotTo(HaveOccurred())
+				updated, _ := repository.Get("instance-1")
+				Expect(updated.Status).To(Equal(models.InstanceStatusFailed))
+				Expect(updated.Rollback).To(Equal(models.InstanceRollbackCompleted))
+				vars, _ := repository.GetVars("instance-1")
+				Expect(vars).To(Equal(map[string]string{"PUSHAAS_PASSWORD": "password-1", "PUSHAAS_ENDPOINT": "endpoint-1"}))
+			})
+
+			It("keeps the time the instance was first provisioned", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+				Expect(repository.UpdateStatus("instance-1", models.InstanceStatusRunning, "")).To(Succeed())
+				provisioned, _ := repository.Get("instance-1")
+				time.Sleep(2 * time.Millisecond)
+
+				// act
+				err := repository.UpdateStatus("instance-1", models.InstanceStatusDegraded, "some reason")
+				Expect(repository.UpdateStatus("instance-1", models.InstanceStatusRunning, "")).To(Succeed())
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				retrieved, _ := repository.Get("instance-1")
+				Expect(retrieved.ProvisionedAt).To(Equal(provisioned.ProvisionedAt))
+				Expect(retrieved.UpdatedAt.After(*provisioned.UpdatedAt)).To(BeTrue())
+			})
+
+			It("adds to the resources of the instance", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+				Expect(repository.UpdateResources("instance-1", map[string]string{"a": "1", "b": "2"})).To(Succeed())
+
+				// act
+				err := repository.UpdateResources("instance-1", map[string]string{"b": "3", "c": "4"})
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				retrieved, _ := repository.Get("instance-1")
+				Expect(retrieved.Resources).To(Equal(map[string]string{"a": "1", "b": "3", "c": "4"}))
+				instances, _ := repository.GetAll()
+				Expect(instances[0].Resources).To(Equal(retrieved.Resources))
+			})
+
+			It("removes the history along with the instance", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+				Expect(repository.UpdateStatus("instance-1", models.InstanceStatusRunning, "")).To(Succeed())
+
+				// act
+				err := repository.Delete("instance-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				_, err = repository.GetStatusHistory("instance-1")
+				Expect(err).To(Equal(repositories.ErrNotFound))
+				Expect(repository.Create(instance)).To(Succeed())
+				history, _ := repository.GetStatusHistory("instance-1")
+				Expect(history).To(HaveLen(1))
+			})
+
+			It("does not create the instance when updating one that does not exist", func() {
+				// act
+				errUpdate := repository.Update("instance-1", &repositories.InstanceUpdate{Team: "other-team"})
+				errStatus := repository.UpdateStatus("instance-1", models.InstanceStatusFailed, "some reason")
+				errResources := repository.UpdateResources("instance-1", map[string]string{"a": "1"})
+
+				// assert
+				Expect(errUpdate).To(Equal(repositories.ErrNotFound))
+				Expect(errStatus).To(Equal(repositories.ErrNotFound))
+				Expect(errResources).To(Equal(repositories.ErrNotFound))
+				_, err := repository.Get("instance-1")
+				Expect(err).To(Equal(repositories.ErrNotFound))
+			})
+
+			It("does not leave a partial instance behind when it is deleted while being changed", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+				var calls int32
+
+				// act
+				concurrently(20, func() error {
+					if atomic.AddInt32(&calls, 1) == 1 {
+						return repository.Delete("instance-1")
+					}
+					return repository.UpdateResources("instance-1", map[string]string{"a": "1"})
+				})
+
+				// assert
+				_, err := repository.Get("instance-1")
+				Expect(err).To(Equal(repositories.ErrNotFound))
+			})
+
+			It("deletes an instance", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+
+				// act
+				err := repository.Delete("instance-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repository.Delete("instance-1")).To(Equal(repositories.ErrNotFound))
+			})
+		})
+
+		Describe("vars", func() {
+			It("merges the vars that are set", func() {
+				// arrange
+				Expect(repository.SetVars("instance-1", map[string]string{"A": "1", "B": "2"})).To(Succeed())
+
+				// act
+				err := repository.SetVars("instance-1", map[string]string{"B": "3"})
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				vars, err := repository.GetVars("instance-1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vars).To(Equal(map[string]string{"A": "1", "B": "3"}))
+			})
+
+			It("retrieves no vars when there are none", func() {
+				// act
+				vars, err := repository.GetVars("instance-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vars).To(BeEmpty())
+			})
+
+			It("deletes the vars", func() {
+				// arrange
+				Expect(repository.SetVars("instance-1", map[string]string{"A": "1"})).To(Succeed())
+
+				// act
+				err := repository.DelVars("instance-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				vars, _ := repository.GetVars("instance-1")
+				Expect(vars).To(BeEmpty())
+			})
+		})
+
+		Describe("binds", func() {
+			bindApp := &models.BindApp{AppName: "app-1", AppHost: "app-1.example.com", Username: "app-app-1", Password: "password"}
+
+			It("saves and retrieves a bound app", func() {
+				// act
+				err := repository.SaveBindApp("instance-1", bindApp)
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				retrieved, err := repository.GetBindApp("instance-1", "app-1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retrieved).To(Equal(bindApp))
+			})
+
+			It("creates a bound app only if it is not bound yet", func() {
+				// act
+				err := repository.CreateBindApp("instance-1", bindApp)
+				errAgain := repository.CreateBindApp("instance-1", &models.BindApp{AppName: "app-1", Password: "new-password"})
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(errAgain).To(Equal(repositories.ErrAlreadyExists))
+				retrieved, _ := repository.GetBindApp("instance-1", "app-1")
+				Expect(retrieved).To(Equal(bindApp))
+			})
+
+			It("lets only one of concurrent creations of the same bound app succeed", func() {
+				// act
+				errs := concurrently(20, func() error {
+					return repository.CreateBindApp("instance-1", bindApp)
+				})
+
+				// assert
+				Expect(countSucceeded(errs)).To(Equal(1))
+			})
+
+			It("replaces a bound app when saved again", func() {
+				// arrange
+				Expect(repository.SaveBindApp("instance-1", bindApp)).To(Succeed())
+
+				// act
+				err := repository.SaveBindApp("instance-1", &models.BindApp{AppName: "app-1", AppHost: "app-1.example.com", Username: "app-app-1", Password: "new-password"})
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				retrieved, _ := repository.GetBindApp("instance-1", "app-1")
+				Expect(retrieved.Password).To(Equal("new-password"))
+			})
+
+			It("retrieves only the apps bound to the instance", func() {
+				// arrange
+				Expect(repository.SaveBindApp("instance-1", bindApp)).To(Succeed())
+				Expect(repository.SaveBindApp("instance-1", &models.BindApp{AppName: "app-2"})).To(Succeed())
+				Expect(repository.SaveBindApp("instance-2", &models.BindApp{AppName: "app-3"})).To(Succeed())
+
+				// act
+				bindApps, err := repository.GetBindApps("instance-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bindApps).To(HaveLen(2))
+			})
+
+			It("indicates when the app is not bound", func() {
+				// act
+				_, errGet := repository.GetBindApp("instance-1", "app-1")
+				errDel := repository.DelBindApp("instance-1", "app-1")
+
+				// assert
+				Expect(errGet).To(Equal(repositories.ErrNotFound))
+				Expect(errDel).To(Equal(repositories.ErrNotFound))
+			})
+
+			It("deletes a bound app", func() {
+				// arrange
+				Expect(repository.SaveBindApp("instance-1", bindApp)).To(Succeed())
+
+				// act
+				err := repository.DelBindApp("instance-1", "app-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				_, err = repository.GetBindApp("instance-1", "app-1")
+				Expect(err).To(Equal(repositories.ErrNotFound))
+				bindApps, err := repository.GetBindApps("instance-1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bindApps).To(BeEmpty())
+			})
+
+			It("removes the units along with the bound app, so that binding it again starts with none", func() {
+				// arrange
+				Expect(repository.CreateBindApp("instance-1", bindApp)).To(Succeed())
+				Expect(repository.AddBindUnit("instance-1", "app-1", "unit-1")).To(Succeed())
+
+				// act
+				err := repository.DelBindApp("instance-1", "app-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repository.CreateBindApp("instance-1", bindApp)).To(Succeed())
+				unitHosts, err := repository.GetBindUnits("instance-1", "app-1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(unitHosts).To(BeEmpty())
+			})
+
+			It("removes the bound apps and their units along with the instance", func() {
+				// arrange
+				Expect(repository.Create(&models.Instance{Name: "instance-1"})).To(Succeed())
+				Expect(repository.CreateBindApp("instance-1", bindApp)).To(Succeed())
+				Expect(repository.AddBindUnit("instance-1", "app-1", "unit-1")).To(Succeed())
+				Expect(repository.CreateBindApp("instance-2", bindApp)).To(Succeed())
+				Expect(repository.AddBindUnit("instance-2", "app-1", "unit-2")).To(Succeed())
+
+				// act
+				err := repository.Delete("instance-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				bindApps, _ := repository.GetBindApps("instance-1")
+				Expect(bindApps).To(BeEmpty())
+				unitHosts, _ := repository.GetBindUnits("instance-1", "app-1")
+				Expect(unitHosts).To(BeEmpty())
+				instanceNames, _ := repository.GetInstancesBoundTo("app-1")
+				Expect(instanceNames).To(Equal([]string{"instance-2"}))
+				unitHosts, _ = repository.GetBindUnits("instance-2", "app-1")
+				Expect(unitHosts).To(Equal([]string{"unit-2"}))
+			})
+
+			It("retrieves the instances the app is bound to", func() {
+				// arrange
+				Expect(repository.CreateBindApp("instance-2", bindApp)).To(Succeed())
+				Expect(repository.CreateBindApp("instance-1", bindApp)).To(Succeed())
+				Expect(repository.CreateBindApp("instance-3", &models.BindApp{AppName: "app-2"})).To(Succeed())
+				Expect(repository.DelBindApp("instance-2", "app-1")).To(Succeed())
+
+				// act
+				instanceNames, err := repository.GetInstancesBoundTo("app-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instanceNames).To(Equal([]string{"instance-1"}))
+			})
+
+			It("retrieves the units of a bound app", func() {
+				// arrange
+				Expect(repository.AddBindUnit("instance-1", "app-1", "unit-2")).To(Succeed())
+				Expect(repository.AddBindUnit("instance-1", "app-1", "unit-1")).To(Succeed())
+				Expect(repository.AddBindUnit("instance-1", "app-2", "unit-3")).To(Succeed())
+
+				// act
+				unitHosts, err := repository.GetBindUnits("instance-1", "app-1")
+				noUnitHosts, errNone := repository.GetBindUnits("instance-2", "app-1")
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(unitHosts).To(Equal([]string{"unit-1", "unit-2"}))
+				Expect(errNone).NotTo(HaveOccurred())
+				Expect(noUnitHosts).To(BeEmpty())
+			})
+
+			It("adds and removes units", func() {
+				// act
+				errAdd := repository.AddBindUnit("instance-1", "app-1", "unit-1")
+				errAddAgain := repository.AddBindUnit("instance-1", "app-1", "unit-1")
+				errRemove := repository.RemoveBindUnit("instance-1", "app-1", "unit-1")
+				errRemoveAgain := repository.RemoveBindUnit("instance-1", "app-1", "unit-1")
+
+				// assert
+				Expect(errAdd).NotTo(HaveOccurred())
+				Expect(errAddAgain).To(Equal(repositories.ErrAlreadyExists))
+				Expect(errRemove).NotTo(HaveOccurred())
+				Expect(errRemoveAgain).To(Equal(repositories.ErrNotFound))
+			})
+		})
+
+		Describe("outbox", func() {
+			written := time.Now().UTC().Truncate(time.Millisecond).Add(-time.Minute)
+
+			pendingTask := func(id string, createdAt time.Time) *models.PendingTask {
+				return &models.PendingTask{
+					Id:          id,
+					TaskName:    "provision",
+					Payload:     `{"name":"instance-1"}`,
+					OperationId: "operation-1",
+					CreatedAt:   createdAt,
+				}
+			}
+
+			claim := func() []*models.PendingTask {
+				tasks, err := repository.ClaimPendingTasks(time.Now().Add(time.Minute), 10)
+				Expect(err).NotTo(HaveOccurred())
+				return tasks
+			}
+
+			It("writes the task along with the instance created", func() {
+				// act
+				err := repository.CreateWithTask(instance, pendingTask("task-1", written))
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				retrieved, _ := repository.Get("instance-1")
+				Expect(retrieved.Status).To(Equal(models.InstanceStatusPending))
+				Expect(claim()).To(Equal([]*models.PendingTask{pendingTask("task-1", written)}))
+			})
+
+			It("does not write the task when the instance already exists", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+
+				// act
+				err := repository.CreateWithTask(instance, pendingTask("task-1", written))
+
+				// assert
+				Expect(err).To(Equal(repositories.ErrAlreadyExists))
+				Expect(claim()).To(BeEmpty())
+			})
+
+			It("writes the task along with the change of status", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+
+				// act
+				err := repository.UpdateStatusWithTask("instance-1", models.InstanceStatusDeprovisioning, "deleted", pendingTask("task-1", written))
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				retrieved, _ := repository.Get("instance-1")
+				Expect(retrieved.Status).To(Equal(models.InstanceStatusDeprovisioning))
+				Expect(claim()).To(HaveLen(1))
+			})
+
+			It("writes the task along with the change of plan and status", func() {
+				// arrange
+				Expect(repository.Create(instance)).To(Succeed())
+				update := &repositories.InstanceUpdate{Plan: "large", Status: models.InstanceStatusPending, Reason: "plan changed"}
+
+				// act
+				err := repository.UpdateWithTask("instance-1", update, pendingTask("task-1", written))
+
+				// assert
+				Expect(err).NotTo(HaveOccurred())
+				retrieved, _ := repository.Get("instance-1")
+				Expect(retrieved.Plan).To(Equal("large"))
+				Expect(claim()).To(HaveLen(1))
+			})
+
+			It("does not write the task when the instance to change is not found", func() {
+				// act
+				err := repository.UpdateStatusWithTask("instance-1", models.InstanceStatusDeprovisioning, "deleted", pendingTask("task-1", written))
+
+				// assert
+				Expect(err).To(Equal(repositories.ErrNotFound))
+				Expect(claim()).To(BeEmpty())
+			})
+
+			It("claims the tasks in the order they were written, up to the limit, and only once", func() {
+				// arrange
+				for i, id := range []string{"task-3", "task-1", "task-2"} {
+					name := fmt.Sprintf("instance-%d", i)
+					Expect(repository.CreateWithTask(&models.Instance{Name: name}, pendingTask(id, written.Add(time.Duration(i)*time.Second)))).To(Succeed())
+				}
+
+				// act
+				first, errFirst := repository.ClaimPendingTasks(time.Now().Add(time.Minute), 2)
+				second, errSecond := repository.ClaimPendingTasks(time.Now().Add(time.Minute), 2)
+				third, errThird := repository.ClaimPendingTasks(time.Now().Add(time.Minute), 2)
+
+				// assert
+				Expect(errFirst).NotTo(HaveOccurred())
+				Expect(errSecond).NotTo(HaveOccurred())
+				Expect(errThird).NotTo(HaveOccurred())
+				Expect(first).To(HaveLen(2))
+				Expect(first[0].Id).To(Equal("task-3"))
+				Expect(first[1].Id).To(Equal("task-1"))
+				Expect(second).To(HaveLen(1))
+				Expect(second[0].Id).To(Equal("task-2"))
+				Expect(third).To(BeEmpty())
+			})
+
+			It("claims again the tasks whose claim expired", func() {
+				// arrange
+				Expect(repository.CreateWithTask(instance, pendingTask("task-1", written))).To(Succeed())
+				_, err := repository.ClaimPendingTasks(time.Now().Add(-time.Second), 10)
+				Expect(err).NotTo(HaveOccurred())
+
+				// act
+				tasks := claim()
+
+				// assert
+				Expect(tasks).To(HaveLen(1))
+				Expect(tasks[0].Id).To(Equal("task-1"))
+			})
+
+			It("lets only one of concurrent claims take each task", func() {
+				// arrange
+				for i := 0; i < 5; i++ {
+					name := fmt.Sprintf("instance-%d", i)
+					Expect(repository.CreateWithTask(&models.Instance{Name: name}, pendingTask(name, written))).To(Succeed())
+				}
+
+				// act
+				var mutex sync.Mutex
+				claimed := map[string]int{}
+				concurrently(5, func() error {
+					tasks, err := repository.ClaimPendingTasks(time.Now().Add(time.Minute), 10)
+					mutex.Lock()
+					defer mutex.Unlock()
+					for _, task := range tasks {
+						claimed[task.Id]++
+					}
+					return err
+				})
+
+				// assert
+				for id, times := range claimed {
+					Expect(times).To(Equal(1), id)
+				}
+			})
+
+			It("deletes a task once sent", func() {
+				// arrange
+				Expect(repository.CreateWithTask(instance, pendingTask("task-1", written))).To(Succeed())
+				_, err := repository.ClaimPendingTasks(time.Now().Add(-time.Second), 10)
+				Expect(err).NotTo(HaveOccurred())
+
+				// act
+				errDelete := repository.DeletePendingTask("task-1")
+				errDeleteAgain := repository.DeletePendingTask("task-1")
+
+				// assert
+				Expect(errDelete).NotTo(HaveOccurred())
+				Expect(errDeleteAgain).To(Equal(repositories.ErrNotFound))
+				Expect(claim()).To(BeEmpty())
+			})
+		})
+	})
+}
+
+var _ = Describe("Repository", func() {
+	describeRepository("memory", func() (repositories.Repository, func()) {
+		return repositories.NewMemoryRepository(), func() {}
+	})
+
+	describeRepository("redis", func() (repositories.Repository, func()) {
+		server, err := miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+		config := viper.New()
+		config.Set("redis.db.instance.index", "instance-index")
+		config.Set("redis.db.instance.prefix", "instance")
+		config.Set("redis.db.instance.vars_prefix", "instance-vars")
+		config.Set("redis.db.instance.history_prefix", "instance-history")
+		config.Set("redis.db.bind_app.prefix", "bind-app")
+		config.Set("redis.db.bind_app.index_prefix", "bind-app-index")
+		config.Set("redis.db.bind_app.app_index_prefix", "app-bind-index")
+		config.Set("redis.db.bind_unit.prefix", "bind-unit")
+		config.Set("redis.db.outbox.key", "outbox")
+		config.Set("redis.db.outbox.index", "outbox-index")
+
+		repository, err := repositories.NewRedisRepository(config, logger, redisClient)
+		Expect(err).NotTo(HaveOccurred())
+		return repository, func() {
+			_ = redisClient.Close()
+			server.Close()
+		}
+	})
+
+	describeRepository("sql", func() (repositories.Repository, func()) {
+		db, err := sql.Open(repositories.SqlDriverSqlite, ":memory:")
+		Expect(err).NotTo(HaveOccurred())
+		// every connection would get its own in memory database
+		db.SetMaxOpenConns(1)
+
+		repository, err := repositories.NewSqlRepository(logger, db, repositories.SqlDriverSqlite)
+		Expect(err).NotTo(HaveOccurred())
+		return repository, func() {
+			_ = db.Close()
+		}
+	})
+
+	It("indexes the instances created before the index existed on redis", func() {
+		// arrange
+		server, err := miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		defer server.Close()
+		redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer redisClient.Close()
+		Expect(redisClient.HMSet("instance:instance-1", map[string]interface{}{"Name": "instance-1"}).Err()).To(Succeed())
+
+		config := viper.New()
+		config.Set("redis.db.instance.index", "instance-index")
+		config.Set("redis.db.instance.prefix", "instance")
+
+		// act
+		repository, err := repositories.NewRedisRepository(config, logger, redisClient)
+
+		// assert
+		Expect(err).NotTo(HaveOccurred())
+		page, err := repository.List(&models.InstanceFilter{}, "", 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.Instances).To(HaveLen(1))
+		Expect(page.Instances[0].Name).To(Equal("instance-1"))
+	})
+
+	It("indexes the apps bound before the index existed on redis", func() {
+		// arrange
+		server, err := miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		defer server.Close()
+		redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer redisClient.Close()
+		Expect(redisClient.HMSet("bind-app:instance-1:app-1", map[string]interface{}{"AppName": "app-1"}).Err()).To(Succeed())
+
+		config := viper.New()
+		config.Set("redis.db.bind_app.prefix", "bind-app")
+		config.Set("redis.db.bind_app.index_prefix", "bind-app-index")
+		config.Set("redis.db.bind_app.app_index_prefix", "app-bind-index")
+
+		// act
+		repository, err := repositories.NewRedisRepository(config, logger, redisClient)
+
+		// assert
+		Expect(err).NotTo(HaveOccurred())
+		bindApps, err := repository.GetBindApps("instance-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bindApps).To(HaveLen(1))
+		Expect(bindApps[0].AppName).To(Equal("app-1"))
+		instanceNames, err := repository.GetInstancesBoundTo("app-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instanceNames).To(Equal([]string{"instance-1"}))
+	})
+
+	It("drops the pending tasks that cannot be decoded, claiming the others", func() {
+		// arrange
+		server, err := miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		defer server.Close()
+		redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer redisClient.Close()
+
+		config := viper.New()
+		config.Set("redis.db.instance.index", "instance-index")
+		config.Set("redis.db.instance.prefix", "instance")
+		config.Set("redis.db.outbox.key", "outbox")
+		config.Set("redis.db.outbox.index", "outbox-index")
+		repository, err := repositories.NewRedisRepository(config, logger, redisClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repository.CreateWithTask(&models.Instance{Name: "instance-1"}, &models.PendingTask{Id: "task-1", TaskName: "provision"})).To(Succeed())
+		Expect(redisClient.HSet("outbox", "task-0", "{not json").Err()).To(Succeed())
+		Expect(redisClient.ZAdd("outbox-index", redis.Z{Score: 0, Member: "task-0"}).Err()).To(Succeed())
+
+		// act
+		tasks, err := repository.ClaimPendingTasks(time.Now(), 10)
+
+		// assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tasks).To(HaveLen(1))
+		Expect(tasks[0].Id).To(Equal("task-1"))
+		Expect(redisClient.HExists("outbox", "task-0").Val()).To(BeFalse())
+		Expect(redisClient.ZScore("outbox-index", "task-0").Err()).To(Equal(redis.Nil))
+	})
+
+	It("refuses an unsupported sql driver", func() {
+		// act
+		repository, err := repositories.NewSqlRepository(logger, &sql.DB{}, "mysql")
+
+		// assert
+		Expect(err).To(HaveOccurred())
+		Expect(repository).To(BeNil())
+	})
+})
@@ -0,0 +1,957 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+const (
+	SqlDriverPostgres = "postgres"
+	SqlDriverSqlite   = "sqlite3"
+)
+
+type (
+	/*
+		Keeps everything on a relational database, so that the instances can live along with the rest of our data.
+		Only SQL understood by both Postgres and SQLite is used, the queries are written with `?` placeholders and
+		rebound to `$n` for Postgres. The times are kept as milliseconds, 0 being absent, and the resources of an
+		instance as JSON.
+	*/
+	sqlRepository struct {
+		logger *zap.Logger
+		db     *sql.DB
+		driver string
+	}
+)
+
+var sqlSchema = []string{
+	`CREATE TABLE IF NOT EXISTS instances (
+		name VARCHAR(255) PRIMARY KEY,
+		plan VARCHAR(255) NOT NULL DEFAULT '',
+		team VARCHAR(255) NOT NULL DEFAULT '',
+		user_name VARCHAR(255) NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		status VARCHAR(32) NOT NULL DEFAULT '',
+		rollback_status VARCHAR(32) NOT NULL DEFAULT '',
+		created_at BIGINT NOT NULL DEFAULT 0,
+		updated_at BIGINT NOT NULL DEFAULT 0,
+		provisioned_at BIGINT NOT NULL DEFAULT 0,
+		failure_reason TEXT NOT NULL DEFAULT '',
+		resources TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS instances_created_at ON instances (created_at, name)`,
+	`CREATE TABLE IF NOT EXISTS instance_status_history (
+		instance_name VARCHAR(255) NOT NULL,
+		seq INTEGER NOT NULL,
+		from_status VARCHAR(32) NOT NULL DEFAULT '',
+		to_status VARCHAR(32) NOT NULL DEFAULT '',
+		reason TEXT NOT NULL DEFAULT '',
+		transitioned_at BIGINT NOT NULL,
+		PRIMARY KEY (instance_name, seq)
+	)`,
+	`CREATE TABLE IF NOT EXISTS instance_vars (
+		instance_name VARCHAR(255) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (instance_name, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS bind_apps (
+		instance_name VARCHAR(255) NOT NULL,
+		app_name VARCHAR(255) NOT NULL,
+		app_host VARCHAR(255) NOT NULL DEFAULT '',
+		username VARCHAR(255) NOT NULL DEFAULT '',
+		password VARCHAR(255) NOT NULL DEFAULT '',
+		PRIMARY KEY (instance_name, app_name)
+	)`,
+	`CREATE INDEX IF NOT EXISTS bind_apps_app_name ON bind_apps (app_name, instance_name)`,
+	`CREATE TABLE IF NOT EXISTS bind_units (
+		instance_name VARCHAR(255) NOT NULL,
+		app_name VARCHAR(255) NOT NULL,
+		unit_host VARCHAR(255) NOT NULL,
+		PRIMARY KEY (instance_name, app_name, unit_host)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pending_tasks (
+		id VARCHAR(255) PRIMARY KEY,
+		task_name VARCHAR(255) NOT NULL,
+		payload TEXT NOT NULL,
+		operation_id VARCHAR(255) NOT NULL DEFAULT '',
+		created_at BIGINT NOT NULL,
+		claimed_until BIGINT NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS pending_tasks_claimed_until ON pending_tasks (claimed_until, created_at)`,
+}
+
+const instanceColumns = "name, plan, team, user_name, description, status, rollback_status, created_at, updated_at, provisioned_at, failure_reason, resources"
+
+// how many times a change is tried when the record it is based on is changed concurrently
+const changeAttempts = 3
+
+var errChangedConcurrently = errors.New("changed concurrently")
+
+func (r *sqlRepository) rebind(query string) string {
+	if r.driver != SqlDriverPostgres {
+		return query
+	}
+
+	var builder strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			builder.WriteString(fmt.Sprintf("$%d", n))
+			continue
+		}
+		builder.WriteRune(c)
+	}
+	return builder.String()
+}
+
+func (r *sqlRepository) exec(query string, args ...interface{}) (int64, error) {
+	result, err := r.db.Exec(r.rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// the first column of every row
+func (r *sqlRepository) queryStrings(query string, args ...interface{}) ([]string, error) {
+	rows, err := r.db.Query(r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+/*
+	runs the change in a transaction, which is rolled back when the change fails. The change is tried again
+	when what it read was changed meanwhile.
+*/
+func (r *sqlRepository) change(fn func(tx *sql.Tx) error) error {
+	var err error
+	for attempt := 0; attempt < changeAttempts; attempt++ {
+		err = r.changeOnce(fn)
+		if err != errChangedConcurrently {
+			return err
+		}
+	}
+	return err
+}
+
+func (r *sqlRepository) changeOnce(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqlRepository) migrate() error {
+	for _, statement := range sqlSchema {
+		_, err := r.db.Exec(statement)
+		if err != nil {
+			r.logger.Error("failed to create schema", zap.String("statement", statement), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	===========================================================================
+	instances
+	===========================================================================
+*/
+func millisOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return millis(*t)
+}
+
+func timeOrNil(ms int64) *time.Time {
+	if ms == 0 {
+		return nil
+	}
+	t := fromMillis(ms)
+	return &t
+}
+
+func encodeResources(resources map[string]string) (string, error) {
+	if len(resources) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(resources)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func scanInstance(scanner interface{ Scan(...interface{}) error }) (*models.Instance, error) {
+	var instance models.Instance
+	var created, updated, provisioned int64
+	var resources string
+	err := scanner.Scan(
+		&instance.Name, &instance.Plan, &instance.Team, &instance.User, &instance.Description, &instance.Status, &instance.Rollback,
+		&created, &updated, &provisioned, &instance.FailureReason, &resources,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	instance.CreatedAt = timeOrNil(created)
+	instance.UpdatedAt = timeOrNil(updated)
+	instance.ProvisionedAt = timeOrNil(provisioned)
+	if resources != "" {
+		err = json.Unmarshal([]byte(resources), &instance.Resources)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &instance, nil
+}
+
+func (r *sqlRepository) GetAll() ([]*models.Instance, error) {
+	rows, err := r.db.Query(fmt.Sprintf("SELECT %s FROM instances ORDER BY created_at, name", instanceColumns))
+	if err != nil {
+		r.logger.Error("failed to retrieve instances", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	instances := []*models.Instance{}
+	for rows.Next() {
+		instance, err := scanInstance(rows)
+		if err != nil {
+			r.logger.Error("failed to scan instance", zap.Error(err))
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+	return instances, rows.Err()
+}
+
+/*
+	one more instance than the limit is retrieved, to know whether there is a next page
+*/
+func (r *sqlRepository) List(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, error) {
+	after, err := decodeInstanceCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []string{}
+	args := []interface{}{}
+	if after != nil {
+		conditions = append(conditions, "(created_at > ? OR (created_at = ? AND name > ?))")
+		args = append(args, after.created, after.created, after.name)
+	}
+	for column, value := range map[string]string{
+		"team":      filter.Team,
+		"user_name": filter.User,
+		"plan":      filter.Plan,
+		"status":    string(filter.Status),
+	} {
+		if value != "" {
+			conditions = append(conditions, fmt.Sprintf("%s = ?", column))
+			args = append(args, value)
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM instances", instanceColumns)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at, name LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := r.db.Query(r.rebind(query), args...)
+	if err != nil {
+		r.logger.Error("failed to list instances", zap.Any("filter", filter), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &models.InstancePage{Instances: []*models.Instance{}}
+	for rows.Next() {
+		if len(page.Instances) == limit {
+			last := page.Instances[limit-1]
+			page.NextCursor = (&instanceCursor{created: millisOrZero(last.CreatedAt), name: last.Name}).encode()
+			break
+		}
+
+		instance, err := scanInstance(rows)
+		if err != nil {
+			r.logger.Error("failed to scan instance", zap.Error(err))
+			return nil, err
+		}
+		page.Instances = append(page.Instances, instance)
+	}
+	return page, rows.Err()
+}
+
+func (r *sqlRepository) Get(name string) (*models.Instance, error) {
+	row := r.db.QueryRow(r.rebind(fmt.Sprintf("SELECT %s FROM instances WHERE name = ?", instanceColumns)), name)
+	instance, err := scanInstance(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.Error("failed to retrieve instance", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+	return instance, nil
+}
+
+func (r *sqlRepository) appendStatusHistory(tx *sql.Tx, name string, transition *models.InstanceStatusTransition) error {
+	var last int64
+	err := tx.QueryRow(r.rebind("SELECT COALESCE(MAX(seq), 0) FROM instance_status_history WHERE instance_name = ?"), name).Scan(&last)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		r.rebind("INSERT INTO instance_status_history (instance_name, seq, from_status, to_status, reason, transitioned_at) VALUES (?, ?, ?, ?, ?, ?)"),
+		name, last+1, transition.From, transition.To, transition.Reason, millis(transition.At),
+	)
+	return err
+}
+
+func (r *sqlRepository) Create(instance *models.Instance) error {
+	return r.CreateWithTask(instance, nil)
+}
+
+func (r *sqlRepository) CreateWithTask(instance *models.Instance, task *models.PendingTask) error {
+	created := *instance
+	transition := stampCreation(&created, now())
+	resources, err := encodeResources(created.Resources)
+	if err != nil {
+		return err
+	}
+
+	err = r.changeOnce(func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			r.rebind(fmt.Sprintf("INSERT INTO instances (%s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (name) DO NOTHING", instanceColumns)),
+			created.Name, created.Plan, created.Team, created.User, created.Description, created.Status, created.Rollback,
+			millisOrZero(created.CreatedAt), millisOrZero(created.UpdatedAt), millisOrZero(created.ProvisionedAt), created.FailureReason, resources,
+		)
+		if err != nil {
+			return err
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if inserted == 0 {
+			return ErrAlreadyExists
+		}
+
+		// the history of a removed instance of the same name may have been left behind
+		_, err = tx.Exec(r.rebind("DELETE FROM instance_status_history WHERE instance_name = ?"), created.Name)
+		if err != nil {
+			return err
+		}
+		err = r.appendStatusHistory(tx, created.Name, transition)
+		if err != nil {
+			return err
+		}
+		return r.insertPendingTask(tx, task)
+	})
+	if err == ErrAlreadyExists {
+		return err
+	} else if err != nil {
+		r.logger.Error("failed to create instance", zap.Any("instance", instance), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+var instanceUpdateColumns = map[string]string{
+	"Plan":        "plan",
+	"Team":        "team",
+	"Description": "description",
+}
+
+func (r *sqlRepository) updateInstance(name string, columns []string, args []interface{}) error {
+	columns = append(columns, "updated_at = ?")
+	args = append(args, millis(now()))
+
+	query := fmt.Sprintf("UPDATE instances SET %s WHERE name = ?", strings.Join(columns, ", "))
+	updated, err := r.exec(query, append(args, name)...)
+	if err != nil {
+		r.logger.Error("failed to update instance", zap.String("name", name), zap.Strings("columns", columns), zap.Error(err))
+		return err
+	}
+	if updated == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqlRepository) Update(name string, update *InstanceUpdate) error {
+	return r.UpdateWithTask(name, update, nil)
+}
+
+func (r *sqlRepository) UpdateWithTask(name string, update *InstanceUpdate, task *models.PendingTask) error {
+	fields := update.fields()
+	if len(fields) == 0 {
+		return nil
+	}
+
+	columns := make([]string, 0, len(fields))
+	args := make([]interface{}, 0, len(fields))
+	for field, value := range fields {
+		if field == "Status" {
+			continue
+		}
+		columns = append(columns, fmt.Sprintf("%s = ?", instanceUpdateColumns[field]))
+		args = append(args, value)
+	}
+	if update.Status == "" {
+		return r.updateInstance(name, columns, args)
+	}
+	if update.Rollback != nil {
+		columns = append(columns, "rollback_status = ?")
+		args = append(args, *update.Rollback)
+	}
+	return r.transitInstance(name, update.From, update.Status, update.Reason, columns, args, update.Vars, task)
+}
+
+func (r *sqlRepository) UpdateStatus(name string, status models.InstanceStatus, reason string) error {
+	return r.transitInstance(name, "", status, reason, nil, nil, nil, nil)
+}
+
+func (r *sqlRepository) UpdateStatusWithTask(name string, status models.InstanceStatus, reason string, task *models.PendingTask) error {
+	return r.transitInstance(name, "", status, reason, nil, nil, nil, task)
+}
+
+/*
+	the instance is only changed while it still has the status that was read, so the history is not told
+	a transition from a status the instance no longer had, nor one from other than the expected status, if any.
+	The other columns given are set along, and the vars and the task, if any, are written along.
+*/
+func (r *sqlRepository) transitInstance(name string, from, status models.InstanceStatus, reason string, otherColumns []string, otherArgs []interface{}, vars map[string]string, task *models.PendingTask) error {
+	err := r.change(func(tx *sql.Tx) error {
+		instance, err := scanInstance(tx.QueryRow(r.rebind(fmt.Sprintf("SELECT %s FROM instances WHERE name = ?", instanceColumns)), name))
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if from != "" && instance.Status != from {
+			return ErrStatusChanged
+		}
+
+		previousStatus := instance.Status
+		transition := transitStatus(instance, status, reason, now())
+		columns := append([]string{"status = ?", "updated_at = ?", "provisioned_at = ?", "failure_reason = ?"}, otherColumns...)
+		args := append([]interface{}{instance.Status, millisOrZero(instance.UpdatedAt), millisOrZero(instance.ProvisionedAt), instance.FailureReason}, otherArgs...)
+		result, err := tx.Exec(
+			r.rebind(fmt.Sprintf("UPDATE instances SET %s WHERE name = ? AND status = ?", strings.Join(columns, ", "))),
+			append(args, name, previousStatus)...,
+		)
+		if err != nil {
+			return err
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return errChangedConcurrently
+		}
+
+		err = r.appendStatusHistory(tx, name, transition)
+		if err != nil {
+			return err
+		}
+		err = r.upsertVars(tx, name, vars)
+		if err != nil {
+			return err
+		}
+		return r.insertPendingTask(tx, task)
+	})
+	if err == ErrNotFound || err == ErrStatusChanged {
+		return err
+	} else if err != nil {
+		r.logger.Error("failed to update instance status", zap.String("name", name), zap.String("status", string(status)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqlRepository) UpdateRollback(name string, rollback models.InstanceRollback) error {
+	return r.updateInstance(name, []string{"rollback_status = ?"}, []interface{}{rollback})
+}
+
+// like the status, the resources are only changed while they are still the ones that were read
+func (r *sqlRepository) UpdateResources(name string, resources map[string]string) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	err := r.change(func(tx *sql.Tx) error {
+		var previous string
+		err := tx.QueryRow(r.rebind("SELECT resources FROM instances WHERE name = ?"), name).Scan(&previous)
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		merged := map[string]string{}
+		if previous != "" {
+			err = json.Unmarshal([]byte(previous), &merged)
+			if err != nil {
+				return err
+			}
+		}
+		for kind, id := range resources {
+			merged[kind] = id
+		}
+		encoded, err := encodeResources(merged)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(
+			r.rebind("UPDATE instances SET resources = ?, updated_at = ? WHERE name = ? AND resources = ?"),
+			encoded, millis(now()), name, previous,
+		)
+		if err != nil {
+			return err
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return errChangedConcurrently
+		}
+		return nil
+	})
+	if err == ErrNotFound {
+		return err
+	} else if err != nil {
+		r.logger.Error("failed to update instance resources", zap.String("name", name), zap.Any("resources", resources), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqlRepository) Delete(name string) error {
+	err := r.changeOnce(func(tx *sql.Tx) error {
+		result, err := tx.Exec(r.rebind("DELETE FROM instances WHERE name = ?"), name)
+		if err != nil {
+			return err
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrNotFound
+		}
+
+		for _, query := range []string{
+			"DELETE FROM instance_status_history WHERE instance_name = ?",
+			"DELETE FROM bind_apps WHERE instance_name = ?",
+			"DELETE FROM bind_units WHERE instance_name = ?",
+		} {
+			_, err = tx.Exec(r.rebind(query), name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == ErrNotFound {
+		return err
+	} else if err != nil {
+		r.logger.Error("failed to delete instance", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqlRepository) GetStatusHistory(name string) ([]*models.InstanceStatusTransition, error) {
+	_, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		r.rebind("SELECT from_status, to_status, reason, transitioned_at FROM instance_status_history WHERE instance_name = ? ORDER BY seq"),
+		name,
+	)
+	if err != nil {
+		r.logger.Error("failed to retrieve instance status history", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []*models.InstanceStatusTransition{}
+	for rows.Next() {
+		var transition models.InstanceStatusTransition
+		var at int64
+		err = rows.Scan(&transition.From, &transition.To, &transition.Reason, &at)
+		if err != nil {
+			r.logger.Error("failed to scan instance status transition", zap.String("name", name), zap.Error(err))
+			return nil, err
+		}
+		transition.At = fromMillis(at)
+		history = append(history, &transition)
+	}
+	return history, rows.Err()
+}
+
+/*
+	===========================================================================
+	vars
+	===========================================================================
+*/
+func (r *sqlRepository) GetVars(name string) (map[string]string, error) {
+	rows, err := r.db.Query(r.rebind("SELECT name, value FROM instance_vars WHERE instance_name = ?"), name)
+	if err != nil {
+		r.logger.Error("failed to retrieve instance vars", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	vars := map[string]string{}
+	for rows.Next() {
+		var k, v string
+		err = rows.Scan(&k, &v)
+		if err != nil {
+			r.logger.Error("failed to scan instance var", zap.String("name", name), zap.Error(err))
+			return nil, err
+		}
+		vars[k] = v
+	}
+	return vars, rows.Err()
+}
+
+func (r *sqlRepository) SetVars(name string, vars map[string]string) error {
+	if len(vars) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("failed to begin transaction to set instance vars", zap.String("name", name), zap.Error(err))
+		return err
+	}
+
+	err = r.upsertVars(tx, name, vars)
+	if err != nil {
+		_ = tx.Rollback()
+		r.logger.Error("failed to set instance vars", zap.String("name", name), zap.Error(err))
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.logger.Error("failed to commit instance vars", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqlRepository) upsertVars(tx *sql.Tx, name string, vars map[string]string) error {
+	query := r.rebind("INSERT INTO instance_vars (instance_name, name, value) VALUES (?, ?, ?) ON CONFLICT (instance_name, name) DO UPDATE SET value = excluded.value")
+	for k, v := range vars {
+		_, err := tx.Exec(query, name, k, v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqlRepository) DelVars(name string) error {
+	_, err := r.exec("DELETE FROM instance_vars WHERE instance_name = ?", name)
+	if err != nil {
+		r.logger.Error("failed to delete instance vars", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+/*
+	===========================================================================
+	binds
+	===========================================================================
+*/
+func (r *sqlRepository) GetBindApps(instanceName string) ([]*models.BindApp, error) {
+	rows, err := r.db.Query(r.rebind("SELECT app_name, app_host, username, password FROM bind_apps WHERE instance_name = ? ORDER BY app_name"), instanceName)
+	if err != nil {
+		r.logger.Error("failed to retrieve bindApps", zap.String("instanceName", instanceName), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	bindApps := []*models.BindApp{}
+	for rows.Next() {
+		var bindApp models.BindApp
+		err = rows.Scan(&bindApp.AppName, &bindApp.AppHost, &bindApp.Username, &bindApp.Password)
+		if err != nil {
+			r.logger.Error("failed to scan bindApp", zap.String("instanceName", instanceName), zap.Error(err))
+			return nil, err
+		}
+		bindApps = append(bindApps, &bindApp)
+	}
+	return bindApps, rows.Err()
+}
+
+func (r *sqlRepository) GetBindApp(instanceName, appName string) (*models.BindApp, error) {
+	row := r.db.QueryRow(r.rebind("SELECT app_name, app_host, username, password FROM bind_apps WHERE instance_name = ? AND app_name = ?"), instanceName, appName)
+
+	var bindApp models.BindApp
+	err := row.Scan(&bindApp.AppName, &bindApp.AppHost, &bindApp.Username, &bindApp.Password)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		r.logger.Error("failed to retrieve bindApp", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.Error(err))
+		return nil, err
+	}
+	return &bindApp, nil
+}
+
+func (r *sqlRepository) CreateBindApp(instanceName string, bindApp *models.BindApp) error {
+	created, err := r.exec(
+		"INSERT INTO bind_apps (instance_name, app_name, app_host, username, password) VALUES (?, ?, ?, ?, ?) "+
+			"ON CONFLICT (instance_name, app_name) DO NOTHING",
+		instanceName, bindApp.AppName, bindApp.AppHost, bindApp.Username, bindApp.Password,
+	)
+	if err != nil {
+		r.logger.Error("failed to create bindApp", zap.String("instanceName", instanceName), zap.Any("bindApp", bindApp), zap.Error(err))
+		return err
+	}
+	if created == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+func (r *sqlRepository) SaveBindApp(instanceName string, bindApp *models.BindApp) error {
+	_, err := r.exec(
+		"INSERT INTO bind_apps (instance_name, app_name, app_host, username, password) VALUES (?, ?, ?, ?, ?) "+
+			"ON CONFLICT (instance_name, app_name) DO UPDATE SET app_host = excluded.app_host, username = excluded.username, password = excluded.password",
+		instanceName, bindApp.AppName, bindApp.AppHost, bindApp.Username, bindApp.Password,
+	)
+	if err != nil {
+		r.logger.Error("failed to save bindApp", zap.String("instanceName", instanceName), zap.Any("bindApp", bindApp), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *sqlRepository) DelBindApp(instanceName, appName string) error {
+	var deleted int64
+	err := r.changeOnce(func(tx *sql.Tx) error {
+		result, err := tx.Exec(r.rebind("DELETE FROM bind_apps WHERE instance_name = ? AND app_name = ?"), instanceName, appName)
+		if err != nil {
+			return err
+		}
+		deleted, err = result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(r.rebind("DELETE FROM bind_units WHERE instance_name = ? AND app_name = ?"), instanceName, appName)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to delete bindApp", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.Error(err))
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqlRepository) GetInstancesBoundTo(appName string) ([]string, error) {
+	instanceNames, err := r.queryStrings("SELECT instance_name FROM bind_apps WHERE app_name = ? ORDER BY instance_name", appName)
+	if err != nil {
+		r.logger.Error("failed to retrieve instances bound to app", zap.String("appName", appName), zap.Error(err))
+		return nil, err
+	}
+	return instanceNames, nil
+}
+
+func (r *sqlRepository) GetBindUnits(instanceName, appName string) ([]string, error) {
+	unitHosts, err := r.queryStrings("SELECT unit_host FROM bind_units WHERE instance_name = ? AND app_name = ? ORDER BY unit_host", instanceName, appName)
+	if err != nil {
+		r.logger.Error("failed to retrieve bindUnits", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.Error(err))
+		return nil, err
+	}
+	return unitHosts, nil
+}
+
+func (r *sqlRepository) AddBindUnit(instanceName, appName, unitHost string) error {
+	added, err := r.exec(
+		"INSERT INTO bind_units (instance_name, app_name, unit_host) VALUES (?, ?, ?) ON CONFLICT (instance_name, app_name, unit_host) DO NOTHING",
+		instanceName, appName, unitHost,
+	)
+	if err != nil {
+		r.logger.Error("failed to add bindUnit", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.String("unitHost", unitHost), zap.Error(err))
+		return err
+	}
+	if added == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+func (r *sqlRepository) RemoveBindUnit(instanceName, appName, unitHost string) error {
+	removed, err := r.exec("DELETE FROM bind_units WHERE instance_name = ? AND app_name = ? AND unit_host = ?", instanceName, appName, unitHost)
+	if err != nil {
+		r.logger.Error("failed to remove bindUnit", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.String("unitHost", unitHost), zap.Error(err))
+		return err
+	}
+	if removed == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+/*
+	===========================================================================
+	outbox
+	===========================================================================
+*/
+func (r *sqlRepository) insertPendingTask(tx *sql.Tx, task *models.PendingTask) error {
+	if task == nil {
+		return nil
+	}
+	_, err := tx.Exec(
+		r.rebind("INSERT INTO pending_tasks (id, task_name, payload, operation_id, created_at) VALUES (?, ?, ?, ?, ?)"),
+		task.Id, task.TaskName, task.Payload, task.OperationId, millis(task.CreatedAt),
+	)
+	return err
+}
+
+/*
+	a task is only claimed while its claim is still the one that was read, so that, of concurrent claims, only
+	one takes it
+*/
+func (r *sqlRepository) ClaimPendingTasks(until time.Time, limit int) ([]*models.PendingTask, error) {
+	var claimed []*models.PendingTask
+	err := r.change(func(tx *sql.Tx) error {
+		claimable, err := r.queryClaimablePendingTasks(tx, limit)
+		if err != nil {
+			return err
+		}
+
+		claimed = make([]*models.PendingTask, 0, len(claimable))
+		for _, task := range claimable {
+			result, err := tx.Exec(
+				r.rebind("UPDATE pending_tasks SET claimed_until = ? WHERE id = ? AND claimed_until <= ?"),
+				millis(until), task.Id, millis(now()),
+			)
+			if err != nil {
+				return err
+			}
+			updated, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if updated > 0 {
+				claimed = append(claimed, task)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to claim pending tasks", zap.Error(err))
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (r *sqlRepository) queryClaimablePendingTasks(tx *sql.Tx, limit int) ([]*models.PendingTask, error) {
+	rows, err := tx.Query(
+		r.rebind("SELECT id, task_name, payload, operation_id, created_at FROM pending_tasks WHERE claimed_until <= ? ORDER BY created_at, id LIMIT ?"),
+		millis(now()), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.PendingTask{}
+	for rows.Next() {
+		var task models.PendingTask
+		var created int64
+		err = rows.Scan(&task.Id, &task.TaskName, &task.Payload, &task.OperationId, &created)
+		if err != nil {
+			return nil, err
+		}
+		task.CreatedAt = fromMillis(created)
+		tasks = append(tasks, &task)
+	}
+	return tasks, rows.Err()
+}
+
+func (r *sqlRepository) DeletePendingTask(id string) error {
+	deleted, err := r.exec("DELETE FROM pending_tasks WHERE id = ?", id)
+	if err != nil {
+		r.logger.Error("failed to delete pending task", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+/*
+	the tables are created when missing, so a new database only has to exist
+*/
+func NewSqlRepository(logger *zap.Logger, db *sql.DB, driver string) (Repository, error) {
+	if driver != SqlDriverPostgres && driver != SqlDriverSqlite {
+		return nil, fmt.Errorf("unsupported sql driver: %s", driver)
+	}
+
+	repository := &sqlRepository{
+		logger: logger.Named("sqlRepository"),
+		db:     db,
+		driver: driver,
+	}
+
+	err := repository.migrate()
+	if err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
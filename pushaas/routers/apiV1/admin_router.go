@@ -0,0 +1,56 @@
+package apiV1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	AdminRouter interface {
+		routers.Router
+	}
+
+	adminRouter struct {
+		instanceService services.InstanceService
+	}
+)
+
+/*
+	the instance as it is stored, which tsuru does not get since it is shown the info items instead
+*/
+func (r *adminRouter) getInstance(c *gin.Context) {
+	instance, result := r.instanceService.GetByName(nameFromPath(c))
+
+	if result == services.InstanceRetrievalNotFound {
+		c.JSON(http.StatusNotFound, models.Error{
+			Code:    models.ErrorInstanceRetrievalNotFound,
+			Message: "Instance not found",
+		})
+		return
+	}
+
+	if result == services.InstanceRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorInstanceRetrievalFailed,
+			Message: "Failed to retrieve instance",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, instance)
+}
+
+func (r *adminRouter) SetupRoutes(router gin.IRouter) {
+	router.GET("/instances/:name", r.getInstance)
+}
+
+func NewAdminRouter(instanceService services.InstanceService) AdminRouter {
+	return &adminRouter{
+		instanceService: instanceService,
+	}
+}
@@ -0,0 +1,97 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("AdminRouter", func() {
+	prepareGinRouter := func(instanceService services.InstanceService) *gin.Engine {
+		ginRouter := gin.New()
+		router := apiV1.NewAdminRouter(instanceService)
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	bodyToError := func(recorder *httptest.ResponseRecorder) *models.Error {
+		var body *models.Error
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	_ = Describe("GET instance", func() {
+		_ = It("returns the instance as it is stored", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return &models.Instance{Name: name, Plan: "small", Status: models.InstanceStatusRunning}, services.InstanceRetrievalSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instances/instance-1", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(`{"name":"instance-1","plan":"small","team":"","user":"","status":"running"}`))
+			Expect(instanceService.GetByNameCalls()[0].Name).To(Equal("instance-1"))
+		})
+
+		_ = It("returns 404 when the instance is not found", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return nil, services.InstanceRetrievalNotFound
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instances/instance-1", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(404))
+			Expect(bodyToError(recorder)).To(Equal(&models.Error{
+				Code:    models.ErrorInstanceRetrievalNotFound,
+				Message: "Instance not found",
+			}))
+		})
+
+		_ = It("returns 500 when fails to retrieve the instance", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return nil, services.InstanceRetrievalFailure
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instances/instance-1", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder)).To(Equal(&models.Error{
+				Code:    models.ErrorInstanceRetrievalFailed,
+				Message: "Failed to retrieve instance",
+			}))
+		})
+	})
+})
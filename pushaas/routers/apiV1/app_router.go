@@ -0,0 +1,48 @@
+package apiV1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	AppRouter interface {
+		routers.Router
+	}
+
+	appRouter struct {
+		bindService services.BindService
+	}
+)
+
+/*
+	the names of the instances the app is bound to
+*/
+func (r *appRouter) getAppBindings(c *gin.Context) {
+	instanceNames, result := r.bindService.GetAppBindings(c.Param("app"))
+
+	if result == services.BindAppRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorBindingRetrievalFailed,
+			Message: "Failed to retrieve bindings of app",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, instanceNames)
+}
+
+func (r *appRouter) SetupRoutes(router gin.IRouter) {
+	router.GET("/:app/bindings", r.getAppBindings)
+}
+
+func NewAppRouter(bindService services.BindService) AppRouter {
+	return &appRouter{
+		bindService: bindService,
+	}
+}
@@ -0,0 +1,71 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("AppRouter", func() {
+	prepareGinRouter := func(bindService services.BindService) *gin.Engine {
+		ginRouter := gin.New()
+		router := apiV1.NewAppRouter(bindService)
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	_ = Describe("GET app bindings", func() {
+		_ = It("returns 200 with the instances the app is bound to", func() {
+			// arrange
+			bindService := &mocks.BindServiceMock{
+				GetAppBindingsFunc: func(appName string) ([]string, services.BindAppRetrievalResult) {
+					return []string{"instance-1", "instance-2"}, services.BindAppRetrievalSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(bindService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/app-1/bindings", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			var body []string
+			_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(body).To(Equal([]string{"instance-1", "instance-2"}))
+			Expect(bindService.GetAppBindingsCalls()[0].AppName).To(Equal("app-1"))
+		})
+
+		_ = It("returns 500 when fails to retrieve the instances", func() {
+			// arrange
+			bindService := &mocks.BindServiceMock{
+				GetAppBindingsFunc: func(appName string) ([]string, services.BindAppRetrievalResult) {
+					return nil, services.BindAppRetrievalFailure
+				},
+			}
+			ginRouter := prepareGinRouter(bindService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/app-1/bindings", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			var body *models.Error
+			_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+			Expect(recorder.Code).To(Equal(500))
+			Expect(body.Code).To(Equal(models.ErrorBindingRetrievalFailed))
+			Expect(body.Message).To(Equal("Failed to retrieve bindings of app"))
+		})
+	})
+})
@@ -0,0 +1,97 @@
+package apiV1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	AuditRouter interface {
+		routers.Router
+	}
+
+	auditRouter struct {
+		auditService services.AuditService
+	}
+)
+
+func timeFromQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+/*
+	lists the audit entries, the most recent first, narrowed by the instance, team, from and to query params,
+	the times being RFC 3339. Older entries are listed by moving the to param back.
+*/
+func (r *auditRouter) getAudit(c *gin.Context) {
+	invalidQuery := func() {
+		c.JSON(http.StatusBadRequest, models.Error{
+			Code:    models.ErrorAuditRetrievalInvalidQuery,
+			Message: "Invalid from, to or limit",
+		})
+	}
+
+	from, err := timeFromQuery(c, "from")
+	if err != nil {
+		invalidQuery()
+		return
+	}
+	to, err := timeFromQuery(c, "to")
+	if err != nil {
+		invalidQuery()
+		return
+	}
+
+	var limit int
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			invalidQuery()
+			return
+		}
+	}
+
+	filter := &models.AuditFilter{
+		Instance: c.Query("instance"),
+		Team:     c.Query("team"),
+		From:     from,
+		To:       to,
+	}
+	entries, result := r.auditService.Query(filter, limit)
+
+	if result == services.AuditRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorAuditRetrievalFailed,
+			Message: "Failed to retrieve audit entries",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (r *auditRouter) SetupRoutes(router gin.IRouter) {
+	router.GET("", r.getAudit)
+}
+
+func NewAuditRouter(auditService services.AuditService) AuditRouter {
+	return &auditRouter{
+		auditService: auditService,
+	}
+}
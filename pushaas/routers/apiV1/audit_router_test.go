@@ -0,0 +1,95 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("AuditRouter", func() {
+	prepareGinRouter := func(auditService services.AuditService) *gin.Engine {
+		ginRouter := gin.New()
+		router := apiV1.NewAuditRouter(auditService)
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	bodyToError := func(recorder *httptest.ResponseRecorder) *models.Error {
+		var body *models.Error
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	_ = Describe("GET audit", func() {
+		_ = It("returns the entries, narrowed by the query", func() {
+			// arrange
+			at := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+			auditService := &mocks.AuditServiceMock{
+				QueryFunc: func(filter *models.AuditFilter, limit int) ([]*models.AuditEntry, services.AuditRetrievalResult) {
+					return []*models.AuditEntry{{Id: "1559390400000-0", At: at, Action: models.AuditActionDeleteInstance, Instance: "instance-1", Status: 200}}, services.AuditRetrievalSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(auditService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/?instance=instance-1&team=team-1&from=2019-06-01T00:00:00Z&to=2019-06-02T00:00:00Z&limit=10", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			from := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+			to := time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(`[{"id":"1559390400000-0","at":"2019-06-01T12:00:00Z","requestId":"","principal":null,"action":"delete-instance","instance":"instance-1","status":200}]`))
+			Expect(auditService.QueryCalls()[0].Filter).To(Equal(&models.AuditFilter{Instance: "instance-1", Team: "team-1", From: &from, To: &to}))
+			Expect(auditService.QueryCalls()[0].Limit).To(Equal(10))
+		})
+
+		_ = It("returns 400 when the query is invalid", func() {
+			for _, query := range []string{"from=yesterday", "to=2019-06-01", "limit=0", "limit=many"} {
+				// arrange
+				auditService := &mocks.AuditServiceMock{}
+				ginRouter := prepareGinRouter(auditService)
+				recorder := httptest.NewRecorder()
+				req, _ := http.NewRequest("GET", "/?"+query, nil)
+
+				// act
+				ginRouter.ServeHTTP(recorder, req)
+
+				// assert
+				Expect(recorder.Code).To(Equal(400), query)
+				Expect(bodyToError(recorder).Code).To(Equal(models.ErrorAuditRetrievalInvalidQuery))
+				Expect(auditService.QueryCalls()).To(BeEmpty())
+			}
+		})
+
+		_ = It("returns 500 when fails to retrieve the entries", func() {
+			// arrange
+			auditService := &mocks.AuditServiceMock{
+				QueryFunc: func(filter *models.AuditFilter, limit int) ([]*models.AuditEntry, services.AuditRetrievalResult) {
+					return nil, services.AuditRetrievalFailure
+				},
+			}
+			ginRouter := prepareGinRouter(auditService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorAuditRetrievalFailed))
+		})
+	})
+})
@@ -1,6 +1,8 @@
 package apiV1
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/pushaas/pushaas/pushaas/routers"
@@ -11,10 +13,15 @@ type (
 		routers.Router
 	}
 
-	authRouter struct {}
+	authRouter struct{}
 )
 
-func (r *authRouter) checkAuth(c *gin.Context) {}
+/*
+	the auth middleware already refused the request when the credentials are not valid
+*/
+func (r *authRouter) checkAuth(c *gin.Context) {
+	c.JSON(http.StatusOK, routers.PrincipalFromContext(c))
+}
 
 func (r *authRouter) SetupRoutes(router gin.IRouter) {
 	router.GET("", r.checkAuth)
@@ -0,0 +1,110 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("AuthRouter", func() {
+	prepareGinRouter := func(authService services.AuthService) *gin.Engine {
+		ginRouter := gin.New()
+		ginRouter.Use(routers.NewAuthMiddleware(authService))
+		router := apiV1.NewAuthRouter()
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	_ = Describe("GET auth", func() {
+		_ = It("returns the authenticated principal", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				AuthenticateFunc: func(username string, password string) (*models.Principal, services.AuthenticationResult) {
+					return &models.Principal{Name: username, Kind: models.PrincipalKindToken}, services.AuthenticationSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.SetBasicAuth("automation", "some-secret")
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			var body *models.Principal
+			_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(body).To(Equal(&models.Principal{Name: "automation", Kind: models.PrincipalKindToken}))
+			Expect(authService.AuthenticateCalls()[0].Username).To(Equal("automation"))
+			Expect(authService.AuthenticateCalls()[0].Password).To(Equal("some-secret"))
+		})
+
+		_ = It("returns 401 when the credentials are not valid", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				AuthenticateFunc: func(username string, password string) (*models.Principal, services.AuthenticationResult) {
+					return nil, services.AuthenticationInvalidCredentials
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.SetBasicAuth("automation", "other-secret")
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(401))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal(`Basic realm="pushaas"`))
+		})
+
+		_ = It("returns 401 without credentials", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(401))
+			Expect(authService.AuthenticateCalls()).To(BeEmpty())
+		})
+
+		_ = It("returns 500 when fails to authenticate", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				AuthenticateFunc: func(username string, password string) (*models.Principal, services.AuthenticationResult) {
+					return nil, services.AuthenticationFailure
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.SetBasicAuth("automation", "some-secret")
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			var body *models.Error
+			_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+			Expect(recorder.Code).To(Equal(500))
+			Expect(body.Code).To(Equal(models.ErrorAuthFailed))
+		})
+	})
+})
@@ -220,6 +220,52 @@ func (r *bindRouter) deleteUnitBind(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+func (r *bindRouter) getBindings(c *gin.Context) {
+	name := nameFromPath(c)
+	bindings, result := r.bindService.GetBindings(name)
+
+	if result == services.BindAppRetrievalNotFound {
+		c.JSON(http.StatusNotFound, models.Error{
+			Code:    models.ErrorBindingRetrievalNotFound,
+			Message: "Instance not found",
+		})
+		return
+	}
+
+	if result == services.BindAppRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorBindingRetrievalFailed,
+			Message: "Failed to retrieve bindings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, bindings)
+}
+
+func (r *bindRouter) getBinding(c *gin.Context) {
+	name := nameFromPath(c)
+	binding, result := r.bindService.GetBinding(name, c.Param("app"))
+
+	if result == services.BindAppRetrievalNotFound {
+		c.JSON(http.StatusNotFound, models.Error{
+			Code:    models.ErrorBindingRetrievalNotFound,
+			Message: "App is not bound to instance",
+		})
+		return
+	}
+
+	if result == services.BindAppRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorBindingRetrievalFailed,
+			Message: "Failed to retrieve binding",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, binding)
+}
+
 func (r *bindRouter) SetupRoutes(router gin.IRouter) {
 	// app bind
 	router.POST("/:name/bind-app", r.postBindApp)
@@ -228,6 +274,10 @@ func (r *bindRouter) SetupRoutes(router gin.IRouter) {
 	// unit bind
 	router.POST("/:name/bind", r.postUnitBind)
 	router.DELETE("/:name/bind", r.deleteUnitBind)
+
+	// bindings
+	router.GET("/:name/bindings", r.getBindings)
+	router.GET("/:name/bindings/:app", r.getBinding)
 }
 
 func NewBindRouter(bindService services.BindService) routers.Router {
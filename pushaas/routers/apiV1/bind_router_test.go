@@ -352,8 +352,8 @@ var _ = Describe("BindRouter", func() {
 		_ = It("returns 201 when creates successfully", func() {
 			// arrange
 			bindService := &mocks.BindServiceMock{
-				BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) services.BindUnitResult {
-					return services.BindUnitSuccess
+				BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) (map[string]string, services.BindUnitResult) {
+					return nil, services.BindUnitSuccess
 				},
 			}
 
@@ -383,8 +383,8 @@ var _ = Describe("BindRouter", func() {
 			}
 
 			bindService := &mocks.BindServiceMock{
-				BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) services.BindUnitResult {
-					return services.BindUnitFailure
+				BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) (map[string]string, services.BindUnitResult) {
+					return nil, services.BindUnitFailure
 				},
 			}
 
@@ -415,8 +415,8 @@ var _ = Describe("BindRouter", func() {
 			}
 
 			bindService := &mocks.BindServiceMock{
-				BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) services.BindUnitResult {
-					return services.BindUnitAppNotBound
+				BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) (map[string]string, services.BindUnitResult) {
+					return nil, services.BindUnitAppNotBound
 				},
 			}
 
@@ -447,8 +447,8 @@ var _ = Describe("BindRouter", func() {
 			}
 
 			bindService := &mocks.BindServiceMock{
-				BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) services.BindUnitResult {
-					return services.BindUnitAlreadyBound
+				BindUnitFunc: func(name string, bindUnitForm *models.BindUnitForm) (map[string]string, services.BindUnitResult) {
+					return nil, services.BindUnitAlreadyBound
 				},
 			}
 
@@ -595,4 +595,131 @@ var _ = Describe("BindRouter", func() {
 			Expect(bindService.UnbindUnitCalls()).To(HaveLen(1))
 		})
 	})
+
+	_ = Describe("GET bindings", func() {
+		_ = It("returns 200 with the bound apps", func() {
+			// arrange
+			expected := []*models.Binding{
+				{AppName: "app-1", AppHost: "app-host-1", UnitHosts: []string{"unit-host-1"}},
+			}
+			bindService := &mocks.BindServiceMock{
+				GetBindingsFunc: func(name string) ([]*models.Binding, services.BindAppRetrievalResult) {
+					return expected, services.BindAppRetrievalSuccess
+				},
+			}
+
+			ginRouter := prepareGinRouter(bindService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/%s/bindings", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			var actual []*models.Binding
+			_ = json.Unmarshal([]byte(recorder.Body.String()), &actual)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(actual).To(Equal(expected))
+			Expect(bindService.GetBindingsCalls()[0].Name).To(Equal(instanceName))
+		})
+
+		_ = It("returns 404 when instance is not found", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorBindingRetrievalNotFound,
+				Message: "Instance not found",
+			}
+			bindService := &mocks.BindServiceMock{
+				GetBindingsFunc: func(name string) ([]*models.Binding, services.BindAppRetrievalResult) {
+					return nil, services.BindAppRetrievalNotFound
+				},
+			}
+
+			ginRouter := prepareGinRouter(bindService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/%s/bindings", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(bodyToError(recorder)).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(404))
+		})
+
+		_ = It("returns 500 when fails to retrieve the bound apps", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorBindingRetrievalFailed,
+				Message: "Failed to retrieve bindings",
+			}
+			bindService := &mocks.BindServiceMock{
+				GetBindingsFunc: func(name string) ([]*models.Binding, services.BindAppRetrievalResult) {
+					return nil, services.BindAppRetrievalFailure
+				},
+			}
+
+			ginRouter := prepareGinRouter(bindService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/%s/bindings", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(bodyToError(recorder)).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(500))
+		})
+	})
+
+	_ = Describe("GET binding", func() {
+		_ = It("returns 200 with the bound app", func() {
+			// arrange
+			expected := &models.Binding{AppName: "app-1", AppHost: "app-host-1", UnitHosts: []string{}}
+			bindService := &mocks.BindServiceMock{
+				GetBindingFunc: func(name, appName string) (*models.Binding, services.BindAppRetrievalResult) {
+					return expected, services.BindAppRetrievalSuccess
+				},
+			}
+
+			ginRouter := prepareGinRouter(bindService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/%s/bindings/app-1", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			var actual *models.Binding
+			_ = json.Unmarshal([]byte(recorder.Body.String()), &actual)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(actual).To(Equal(expected))
+			Expect(bindService.GetBindingCalls()[0].Name).To(Equal(instanceName))
+			Expect(bindService.GetBindingCalls()[0].AppName).To(Equal("app-1"))
+		})
+
+		_ = It("returns 404 when the app is not bound", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorBindingRetrievalNotFound,
+				Message: "App is not bound to instance",
+			}
+			bindService := &mocks.BindServiceMock{
+				GetBindingFunc: func(name, appName string) (*models.Binding, services.BindAppRetrievalResult) {
+					return nil, services.BindAppRetrievalNotFound
+				},
+			}
+
+			ginRouter := prepareGinRouter(bindService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/%s/bindings/app-1", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(bodyToError(recorder)).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(404))
+		})
+	})
 })
@@ -0,0 +1,90 @@
+package apiV1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	CredentialRouter interface {
+		routers.Router
+	}
+
+	credentialRouter struct {
+		credentialService services.CredentialService
+	}
+)
+
+/*
+	the rotation happens on the workers, the instance stays pending until it is done
+*/
+func (r *credentialRouter) postRotate(c *gin.Context) {
+	name := nameFromPath(c)
+	operation, result := r.credentialService.Rotate(name)
+
+	if result == services.CredentialRotationNotFound {
+		c.JSON(http.StatusNotFound, models.Error{
+			Code:    models.ErrorInstanceRotateCredentialsNotFound,
+			Message: "Instance not found",
+		})
+		return
+	}
+
+	if result == services.CredentialRotationNotRunning {
+		c.JSON(http.StatusPreconditionFailed, models.Error{
+			Code:    models.ErrorInstanceRotateCredentialsNotRunning,
+			Message: "The credentials can only be rotated while the instance is running",
+		})
+		return
+	}
+
+	if result == services.CredentialRotationNotSupported {
+		c.JSON(http.StatusNotImplemented, models.Error{
+			Code:    models.ErrorInstanceRotateCredentialsNotSupported,
+			Message: "Credentials rotation is not supported by the provider",
+		})
+		return
+	}
+
+	if result == services.CredentialRotationBusy {
+		c.JSON(http.StatusConflict, models.Error{
+			Code:    models.ErrorInstanceRotateCredentialsBusy,
+			Message: "Instance is busy with another operation, try again later",
+		})
+		return
+	}
+
+	if result == services.CredentialRotationFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorInstanceRotateCredentialsFailed,
+			Message: "Failed to rotate instance credentials",
+		})
+		return
+	}
+
+	if result == services.CredentialRotationDispatchFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorInstanceRotateCredentialsDispatchFailed,
+			Message: "Unable to dispatch credentials rotation, credentials were not rotated",
+		})
+		return
+	}
+
+	setOperationLocation(c, operation)
+	c.Status(http.StatusAccepted)
+}
+
+func (r *credentialRouter) SetupRoutes(router gin.IRouter) {
+	router.POST("/:name/credentials/rotate", r.postRotate)
+}
+
+func NewCredentialRouter(credentialService services.CredentialService) CredentialRouter {
+	return &credentialRouter{
+		credentialService: credentialService,
+	}
+}
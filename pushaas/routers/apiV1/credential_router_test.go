@@ -0,0 +1,145 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("CredentialRouter", func() {
+	prepareGinRouter := func(credentialService services.CredentialService) *gin.Engine {
+		ginRouter := gin.New()
+		router := apiV1.NewCredentialRouter(credentialService)
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	bodyToError := func(recorder *httptest.ResponseRecorder) *models.Error {
+		var body *models.Error
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	newCredentialService := func(result services.CredentialRotationResult) *mocks.CredentialServiceMock {
+		return &mocks.CredentialServiceMock{
+			RotateFunc: func(name string) (*models.Operation, services.CredentialRotationResult) {
+				if result != services.CredentialRotationSuccess {
+					return nil, result
+				}
+				return &models.Operation{Id: "operation-1"}, result
+			},
+		}
+	}
+
+	_ = Describe("POST rotate", func() {
+		_ = It("returns 202 when the rotation is dispatched", func() {
+			// arrange
+			credentialService := newCredentialService(services.CredentialRotationSuccess)
+			ginRouter := prepareGinRouter(credentialService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/instance-1/credentials/rotate", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(202))
+			Expect(recorder.Header().Get("Location")).To(Equal("/api/v1/operations/operation-1"))
+			Expect(credentialService.RotateCalls()).To(HaveLen(1))
+			Expect(credentialService.RotateCalls()[0].Name).To(Equal("instance-1"))
+		})
+
+		_ = It("returns 404 when the instance is not found", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newCredentialService(services.CredentialRotationNotFound))
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/instance-1/credentials/rotate", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(404))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorInstanceRotateCredentialsNotFound))
+		})
+
+		_ = It("returns 412 when the instance is not running", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newCredentialService(services.CredentialRotationNotRunning))
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/instance-1/credentials/rotate", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(412))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorInstanceRotateCredentialsNotRunning))
+		})
+
+		_ = It("returns 409 when the instance is busy with another operation", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newCredentialService(services.CredentialRotationBusy))
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/instance-1/credentials/rotate", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(409))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorInstanceRotateCredentialsBusy))
+		})
+
+		_ = It("returns 501 when the provider does not support it", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newCredentialService(services.CredentialRotationNotSupported))
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/instance-1/credentials/rotate", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(501))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorInstanceRotateCredentialsNotSupported))
+		})
+
+		_ = It("returns 500 when fails to rotate", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newCredentialService(services.CredentialRotationFailure))
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/instance-1/credentials/rotate", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorInstanceRotateCredentialsFailed))
+		})
+
+		_ = It("returns 500 when fails to dispatch the rotation", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newCredentialService(services.CredentialRotationDispatchFailure))
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/instance-1/credentials/rotate", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorInstanceRotateCredentialsDispatchFailed))
+		})
+	})
+})
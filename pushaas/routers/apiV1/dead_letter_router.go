@@ -0,0 +1,80 @@
+package apiV1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	DeadLetterRouter interface {
+		routers.Router
+	}
+
+	deadLetterRouter struct {
+		deadLetterService services.DeadLetterService
+	}
+)
+
+func (r *deadLetterRouter) getDeadLetters(c *gin.Context) {
+	deadLetters, result := r.deadLetterService.GetAll()
+
+	if result == services.DeadLetterRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorDeadLetterRetrievalFailed,
+			Message: "Failed to retrieve dead letters",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, deadLetters)
+}
+
+/*
+	the task is only sent again, its outcome is followed as of any other task. It is refused when its instance went on
+	meanwhile
+*/
+func (r *deadLetterRouter) postReplay(c *gin.Context) {
+	deadLetter, result := r.deadLetterService.Replay(c.Param("id"))
+
+	if result == services.DeadLetterReplayNotFound {
+		c.JSON(http.StatusNotFound, models.Error{
+			Code:    models.ErrorDeadLetterReplayNotFound,
+			Message: "Dead letter not found",
+		})
+		return
+	}
+
+	if result == services.DeadLetterReplayInstanceChanged {
+		c.JSON(http.StatusConflict, models.Error{
+			Code:    models.ErrorDeadLetterReplayInstanceChanged,
+			Message: "Instance of the dead letter is no longer in the status its task expects",
+		})
+		return
+	}
+
+	if result == services.DeadLetterReplayFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorDeadLetterReplayFailed,
+			Message: "Failed to replay dead letter",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, deadLetter)
+}
+
+func (r *deadLetterRouter) SetupRoutes(router gin.IRouter) {
+	router.GET("/dead-letters", r.getDeadLetters)
+	router.POST("/dead-letters/:id/replay", r.postReplay)
+}
+
+func NewDeadLetterRouter(deadLetterService services.DeadLetterService) DeadLetterRouter {
+	return &deadLetterRouter{
+		deadLetterService: deadLetterService,
+	}
+}
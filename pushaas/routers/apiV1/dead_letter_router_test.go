@@ -0,0 +1,159 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("DeadLetterRouter", func() {
+	prepareGinRouter := func(deadLetterService services.DeadLetterService) *gin.Engine {
+		ginRouter := gin.New()
+		router := apiV1.NewDeadLetterRouter(deadLetterService)
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	bodyToError := func(recorder *httptest.ResponseRecorder) *models.Error {
+		var body *models.Error
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	_ = Describe("GET dead letters", func() {
+		_ = It("returns the dead letters", func() {
+			// arrange
+			failedAt := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+			deadLetterService := &mocks.DeadLetterServiceMock{
+				GetAllFunc: func() ([]*models.DeadLetter, services.DeadLetterRetrievalResult) {
+					return []*models.DeadLetter{{Id: "id-1", TaskName: "provision", Payload: "{}", Error: "some error", Attempts: 4, FailedAt: failedAt}}, services.DeadLetterRetrievalSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(deadLetterService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/dead-letters", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(`[{"id":"id-1","taskName":"provision","payload":"{}","error":"some error","attempts":4,"failedAt":"2019-06-01T12:00:00Z"}]`))
+		})
+
+		_ = It("returns 500 when fails to retrieve the dead letters", func() {
+			// arrange
+			deadLetterService := &mocks.DeadLetterServiceMock{
+				GetAllFunc: func() ([]*models.DeadLetter, services.DeadLetterRetrievalResult) {
+					return nil, services.DeadLetterRetrievalFailure
+				},
+			}
+			ginRouter := prepareGinRouter(deadLetterService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/dead-letters", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			body := bodyToError(recorder)
+			Expect(recorder.Code).To(Equal(500))
+			Expect(body.Code).To(Equal(models.ErrorDeadLetterRetrievalFailed))
+			Expect(body.Message).To(Equal("Failed to retrieve dead letters"))
+		})
+	})
+
+	_ = Describe("POST replay", func() {
+		_ = It("replays the dead letter", func() {
+			// arrange
+			deadLetterService := &mocks.DeadLetterServiceMock{
+				ReplayFunc: func(id string) (*models.DeadLetter, services.DeadLetterReplayResult) {
+					return &models.DeadLetter{Id: id, TaskName: "provision"}, services.DeadLetterReplaySuccess
+				},
+			}
+			ginRouter := prepareGinRouter(deadLetterService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/dead-letters/id-1/replay", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(202))
+			Expect(deadLetterService.ReplayCalls()).To(HaveLen(1))
+			Expect(deadLetterService.ReplayCalls()[0].ID).To(Equal("id-1"))
+		})
+
+		_ = It("returns 404 when the dead letter is not found", func() {
+			// arrange
+			deadLetterService := &mocks.DeadLetterServiceMock{
+				ReplayFunc: func(id string) (*models.DeadLetter, services.DeadLetterReplayResult) {
+					return nil, services.DeadLetterReplayNotFound
+				},
+			}
+			ginRouter := prepareGinRouter(deadLetterService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/dead-letters/id-1/replay", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			body := bodyToError(recorder)
+			Expect(recorder.Code).To(Equal(404))
+			Expect(body.Code).To(Equal(models.ErrorDeadLetterReplayNotFound))
+			Expect(body.Message).To(Equal("Dead letter not found"))
+		})
+
+		_ = It("returns 409 when the instance of the dead letter is no longer in the status its task expects", func() {
+			// arrange
+			deadLetterService := &mocks.DeadLetterServiceMock{
+				ReplayFunc: func(id string) (*models.DeadLetter, services.DeadLetterReplayResult) {
+					return nil, services.DeadLetterReplayInstanceChanged
+				},
+			}
+			ginRouter := prepareGinRouter(deadLetterService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/dead-letters/id-1/replay", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			body := bodyToError(recorder)
+			Expect(recorder.Code).To(Equal(409))
+			Expect(body.Code).To(Equal(models.ErrorDeadLetterReplayInstanceChanged))
+		})
+
+		_ = It("returns 500 when fails to replay the dead letter", func() {
+			// arrange
+			deadLetterService := &mocks.DeadLetterServiceMock{
+				ReplayFunc: func(id string) (*models.DeadLetter, services.DeadLetterReplayResult) {
+					return nil, services.DeadLetterReplayFailure
+				},
+			}
+			ginRouter := prepareGinRouter(deadLetterService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/dead-letters/id-1/replay", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			body := bodyToError(recorder)
+			Expect(recorder.Code).To(Equal(500))
+			Expect(body.Code).To(Equal(models.ErrorDeadLetterReplayFailed))
+			Expect(body.Message).To(Equal("Failed to replay dead letter"))
+		})
+	})
+})
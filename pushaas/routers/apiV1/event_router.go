@@ -0,0 +1,92 @@
+package apiV1
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	EventRouter interface {
+		routers.Router
+	}
+
+	eventRouter struct {
+		instanceService services.InstanceService
+		eventService    services.EventService
+	}
+)
+
+// sent while nothing happens, so that the proxies in between do not close the stream for being idle
+const eventsKeepAliveInterval = 15 * time.Second
+
+/*
+	Streams the events of the instance as Server-Sent Events, named after their type, until the client goes away.
+	Only what happens after the stream is open is sent, the current status is on the instance itself.
+*/
+func (r *eventRouter) getEvents(c *gin.Context) {
+	name := nameFromPath(c)
+	_, result := r.instanceService.GetByName(name)
+
+	if result == services.InstanceRetrievalNotFound {
+		c.JSON(http.StatusNotFound, models.Error{
+			Code:    models.ErrorInstanceEventsNotFound,
+			Message: "Instance not found",
+		})
+		return
+	}
+
+	if result == services.InstanceRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorInstanceEventsFailed,
+			Message: "Failed to retrieve instance",
+		})
+		return
+	}
+
+	events, err := r.eventService.Subscribe(name, c.Request.Context().Done())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorInstanceEventsFailed,
+			Message: "Failed to follow instance events",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	keepAlive := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+		case <-keepAlive.C:
+			_, _ = io.WriteString(w, ": keep-alive\n\n")
+		}
+		return true
+	})
+}
+
+func (r *eventRouter) SetupRoutes(router gin.IRouter) {
+	router.GET("/:name/events", r.getEvents)
+}
+
+func NewEventRouter(instanceService services.InstanceService, eventService services.EventService) EventRouter {
+	return &eventRouter{
+		instanceService: instanceService,
+		eventService:    eventService,
+	}
+}
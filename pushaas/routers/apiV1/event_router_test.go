@@ -0,0 +1,117 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+// streaming requires the writer to notify when the client goes away, which the recorder does not
+type streamRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r *streamRecorder) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+var _ = Describe("EventRouter", func() {
+	prepareGinRouter := func(instanceService services.InstanceService, eventService services.EventService) *gin.Engine {
+		ginRouter := gin.New()
+		router := apiV1.NewEventRouter(instanceService, eventService)
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	bodyToError := func(recorder *httptest.ResponseRecorder) *models.Error {
+		var body *models.Error
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	newInstanceService := func(result services.InstanceRetrievalResult) *mocks.InstanceServiceMock {
+		return &mocks.InstanceServiceMock{
+			GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+				return &models.Instance{Name: name}, result
+			},
+		}
+	}
+
+	_ = Describe("GET events", func() {
+		_ = It("streams the events of the instance until they end", func() {
+			// arrange
+			events := make(chan *models.InstanceEvent, 2)
+			events <- &models.InstanceEvent{Instance: "instance-1", Type: models.InstanceEventStepStarted, Step: "provision"}
+			events <- &models.InstanceEvent{Instance: "instance-1", Type: models.InstanceEventStatusChanged, Status: models.InstanceStatusRunning}
+			close(events)
+			eventService := &mocks.EventServiceMock{
+				SubscribeFunc: func(instanceName string, done <-chan struct{}) (<-chan *models.InstanceEvent, error) {
+					return events, nil
+				},
+			}
+			ginRouter := prepareGinRouter(newInstanceService(services.InstanceRetrievalSuccess), eventService)
+			recorder := &streamRecorder{httptest.NewRecorder()}
+			req, _ := http.NewRequest("GET", "/instance-1/events", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/event-stream"))
+			Expect(recorder.Body.String()).To(ContainSubstring("event:step-started\ndata:{\"instance\":\"instance-1\",\"type\":\"step-started\",\"step\":\"provision\""))
+			Expect(recorder.Body.String()).To(ContainSubstring("event:status-changed\ndata:{\"instance\":\"instance-1\",\"type\":\"status-changed\",\"status\":\"running\""))
+			Expect(eventService.SubscribeCalls()).To(HaveLen(1))
+			Expect(eventService.SubscribeCalls()[0].InstanceName).To(Equal("instance-1"))
+		})
+
+		_ = It("returns 404 when the instance is not found", func() {
+			// arrange
+			eventService := &mocks.EventServiceMock{}
+			ginRouter := prepareGinRouter(newInstanceService(services.InstanceRetrievalNotFound), eventService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instance-1/events", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			body := bodyToError(recorder)
+			Expect(recorder.Code).To(Equal(404))
+			Expect(body.Code).To(Equal(models.ErrorInstanceEventsNotFound))
+			Expect(body.Message).To(Equal("Instance not found"))
+			Expect(eventService.SubscribeCalls()).To(BeEmpty())
+		})
+
+		_ = It("returns 500 when fails to follow the events", func() {
+			// arrange
+			eventService := &mocks.EventServiceMock{
+				SubscribeFunc: func(instanceName string, done <-chan struct{}) (<-chan *models.InstanceEvent, error) {
+					return nil, errors.New("some error")
+				},
+			}
+			ginRouter := prepareGinRouter(newInstanceService(services.InstanceRetrievalSuccess), eventService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instance-1/events", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			body := bodyToError(recorder)
+			Expect(recorder.Code).To(Equal(500))
+			Expect(body.Code).To(Equal(models.ErrorInstanceEventsFailed))
+			Expect(body.Message).To(Equal("Failed to follow instance events"))
+		})
+	})
+})
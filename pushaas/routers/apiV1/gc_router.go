@@ -0,0 +1,57 @@
+package apiV1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	GcRouter interface {
+		routers.Router
+	}
+
+	gcRouter struct {
+		gcService services.GcService
+	}
+)
+
+/*
+	orphans are only reported unless `dry_run=false` is explicitly passed
+*/
+func (r *gcRouter) postGc(c *gin.Context) {
+	dryRun := c.Query("dry_run") != "false"
+	report, result := r.gcService.Collect(dryRun)
+
+	if result == services.GcNotSupported {
+		c.JSON(http.StatusNotImplemented, models.Error{
+			Code:    models.ErrorGcNotSupported,
+			Message: "Garbage collection is not supported by the provider",
+		})
+		return
+	}
+
+	if result == services.GcFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorGcFailed,
+			Message: "Failed to collect orphan resources",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (r *gcRouter) SetupRoutes(router gin.IRouter) {
+	router.POST("", r.postGc)
+}
+
+func NewGcRouter(gcService services.GcService) GcRouter {
+	return &gcRouter{
+		gcService: gcService,
+	}
+}
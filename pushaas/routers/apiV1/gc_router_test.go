@@ -0,0 +1,135 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("GcRouter", func() {
+	prepareGinRouter := func(gcService services.GcService) *gin.Engine {
+		ginRouter := gin.New()
+		router := apiV1.NewGcRouter(gcService)
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	bodyToError := func(recorder *httptest.ResponseRecorder) *models.Error {
+		var body *models.Error
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	bodyToReport := func(recorder *httptest.ResponseRecorder) *models.GcReport {
+		var body *models.GcReport
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	_ = Describe("POST gc", func() {
+		_ = It("returns 200 with the report, on dry run by default", func() {
+			// arrange
+			expected := &models.GcReport{
+				DryRun: true,
+				Orphans: []*models.GcOrphan{
+					{Kind: "ecs-service", Name: "push-api-instance-1", InstanceName: "instance-1"},
+				},
+			}
+			gcService := &mocks.GcServiceMock{
+				CollectFunc: func(dryRun bool) (*models.GcReport, services.GcResult) {
+					return expected, services.GcSuccess
+				},
+			}
+
+			ginRouter := prepareGinRouter(gcService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(bodyToReport(recorder)).To(Equal(expected))
+			Expect(gcService.CollectCalls()).To(HaveLen(1))
+			Expect(gcService.CollectCalls()[0].DryRun).To(BeTrue())
+		})
+
+		_ = It("deletes the orphans when dry run is disabled", func() {
+			// arrange
+			gcService := &mocks.GcServiceMock{
+				CollectFunc: func(dryRun bool) (*models.GcReport, services.GcResult) {
+					return &models.GcReport{DryRun: dryRun, Orphans: []*models.GcOrphan{}}, services.GcSuccess
+				},
+			}
+
+			ginRouter := prepareGinRouter(gcService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/?dry_run=false", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(gcService.CollectCalls()[0].DryRun).To(BeFalse())
+		})
+
+		_ = It("returns 501 when the provider does not support it", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorGcNotSupported,
+				Message: "Garbage collection is not supported by the provider",
+			}
+			gcService := &mocks.GcServiceMock{
+				CollectFunc: func(dryRun bool) (*models.GcReport, services.GcResult) {
+					return nil, services.GcNotSupported
+				},
+			}
+
+			ginRouter := prepareGinRouter(gcService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(501))
+			Expect(bodyToError(recorder)).To(Equal(expected))
+		})
+
+		_ = It("returns 500 when fails to collect", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorGcFailed,
+				Message: "Failed to collect orphan resources",
+			}
+			gcService := &mocks.GcServiceMock{
+				CollectFunc: func(dryRun bool) (*models.GcReport, services.GcResult) {
+					return nil, services.GcFailure
+				},
+			}
+
+			ginRouter := prepareGinRouter(gcService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder)).To(Equal(expected))
+		})
+	})
+})
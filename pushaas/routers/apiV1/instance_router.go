@@ -1,7 +1,10 @@
 package apiV1
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -31,12 +34,26 @@ func instanceFormFromContext(c *gin.Context) *models.InstanceForm {
 	plan := c.PostForm("plan")
 	team := c.PostForm("team")
 	user := c.PostForm("user")
+	description := c.PostForm("description")
 
 	return &models.InstanceForm{
-		Name: name,
-		Plan: plan,
-		Team: team,
-		User: user,
+		Name:        name,
+		Plan:        plan,
+		Team:        team,
+		User:        user,
+		Description: description,
+	}
+}
+
+func instanceUpdateFormFromContext(c *gin.Context) *models.InstanceUpdateForm {
+	plan := c.PostForm("plan")
+	team := c.PostForm("team")
+	description := c.PostForm("description")
+
+	return &models.InstanceUpdateForm{
+		Plan:        plan,
+		Team:        team,
+		Description: description,
 	}
 }
 
@@ -56,8 +73,79 @@ func (r *instanceRouter) getPlans(c *gin.Context) {
 	c.JSON(http.StatusOK, plans)
 }
 
+var listableInstanceStatuses = map[models.InstanceStatus]bool{
+	models.InstanceStatusPending:        true,
+	models.InstanceStatusRunning:        true,
+	models.InstanceStatusFailed:         true,
+	models.InstanceStatusDegraded:       true,
+	models.InstanceStatusDeprovisioning: true,
+}
+
+// members only list the instances of their team, whatever they ask
+func instanceFilterFromContext(c *gin.Context) *models.InstanceFilter {
+	team := c.Query("team")
+	if principal := routers.PrincipalFromContext(c); principal != nil && principal.Role == models.RoleMember {
+		team = principal.Team
+	}
+
+	return &models.InstanceFilter{
+		Team:   team,
+		User:   c.Query("user"),
+		Plan:   c.Query("plan"),
+		Status: models.InstanceStatus(c.Query("status")),
+	}
+}
+
+/*
+	lists a page of instances, narrowed by the team, user, plan and status query params. The nextCursor of a page
+	is given back as the cursor query param to retrieve the next one.
+*/
 func (r *instanceRouter) getInstances(c *gin.Context) {
-	instances, result := r.instanceService.GetAll()
+	invalidQuery := func() {
+		c.JSON(http.StatusBadRequest, models.Error{
+			Code:    models.ErrorInstanceRetrievalInvalidQuery,
+			Message: "Invalid cursor, limit or status",
+		})
+	}
+
+	filter := instanceFilterFromContext(c)
+	if filter.Status != "" && !listableInstanceStatuses[filter.Status] {
+		invalidQuery()
+		return
+	}
+
+	var limit int
+	if limitParam := c.Query("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			invalidQuery()
+			return
+		}
+	}
+
+	page, result := r.instanceService.List(filter, c.Query("cursor"), limit)
+
+	if result == services.InstanceRetrievalInvalidCursor {
+		invalidQuery()
+		return
+	}
+
+	if result == services.InstanceRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorInstanceRetrievalFailed,
+			Message: "Failed to retrieve instances",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// the info items are what tsuru shows on `tsuru service-instance-info`, the raw instance is on the admin api
+func (r *instanceRouter) getInstance(c *gin.Context) {
+	name := nameFromPath(c)
+	info, result := r.instanceService.GetInfo(name)
 
 	if result == services.InstanceRetrievalNotFound {
 		c.JSON(http.StatusNotFound, models.Error{
@@ -75,12 +163,12 @@ func (r *instanceRouter) getInstances(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, instances)
+	c.JSON(http.StatusOK, info)
 }
 
-func (r *instanceRouter) getInstance(c *gin.Context) {
+func (r *instanceRouter) getInstanceHistory(c *gin.Context) {
 	name := nameFromPath(c)
-	instance, result := r.instanceService.GetByName(name)
+	history, result := r.instanceService.GetStatusHistory(name)
 
 	if result == services.InstanceRetrievalNotFound {
 		c.JSON(http.StatusNotFound, models.Error{
@@ -93,17 +181,17 @@ func (r *instanceRouter) getInstance(c *gin.Context) {
 	if result == services.InstanceRetrievalFailure {
 		c.JSON(http.StatusInternalServerError, models.Error{
 			Code:    models.ErrorInstanceRetrievalFailed,
-			Message: "Failed to retrieve instance",
+			Message: "Failed to retrieve instance history",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, []*models.Instance{instance})
+	c.JSON(http.StatusOK, history)
 }
 
 func (r *instanceRouter) postInstance(c *gin.Context) {
 	instanceForm := instanceFormFromContext(c)
-	result := r.instanceService.Create(instanceForm)
+	operation, result := r.instanceService.Create(instanceForm)
 
 	if result == services.InstanceCreationAlreadyExist {
 		c.JSON(http.StatusConflict, models.Error{
@@ -132,22 +220,80 @@ func (r *instanceRouter) postInstance(c *gin.Context) {
 	if result == services.InstanceCreationProvisionFailure {
 		c.JSON(http.StatusInternalServerError, models.Error{
 			Code:    models.ErrorInstanceCreateDispatchProvisionFailed,
-			Message: "Instance created, but unable to dispatch provision. Please remove it manually",
+			Message: "Unable to dispatch provision, the instance was not created",
 		})
 		return
 	}
 
+	setOperationLocation(c, operation)
 	c.Status(http.StatusCreated)
 }
 
 func (r *instanceRouter) putInstance(c *gin.Context) {
-	// this endpoint is optional, we return 404 to signal Tsuru that is not implemented
-	c.Status(http.StatusNotFound)
+	name := nameFromPath(c)
+	instanceUpdateForm := instanceUpdateFormFromContext(c)
+	operation, result := r.instanceService.Update(name, instanceUpdateForm)
+
+	if result == services.InstanceUpdateNotFound {
+		c.JSON(http.StatusNotFound, models.Error{
+			Code:    models.ErrorInstanceUpdateNotFound,
+			Message: "Instance not found",
+		})
+		return
+	}
+
+	if result == services.InstanceUpdateInvalidData {
+		c.JSON(http.StatusBadRequest, models.Error{
+			Code:    models.ErrorInstanceUpdateInvalidData,
+			Message: "Invalid instance data",
+		})
+		return
+	}
+
+	if result == services.InstanceUpdateNotRunning {
+		c.JSON(http.StatusPreconditionFailed, models.Error{
+			Code:    models.ErrorInstanceUpdateNotRunning,
+			Message: "The plan can only be changed while the instance is running",
+		})
+		return
+	}
+
+	if result == services.InstanceUpdateBusy {
+		c.JSON(http.StatusConflict, models.Error{
+			Code:    models.ErrorInstanceUpdateBusy,
+			Message: "Instance is busy with another operation, try again later",
+		})
+		return
+	}
+
+	if result == services.InstanceUpdateFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorInstanceUpdateFailed,
+			Message: "Failed to update instance",
+		})
+		return
+	}
+
+	if result == services.InstanceUpdateDispatchUpdateFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorInstanceUpdateDispatchUpdateFailed,
+			Message: "Unable to dispatch update, instance was not updated",
+		})
+		return
+	}
+
+	setOperationLocation(c, operation)
+	c.Status(http.StatusOK)
 }
 
+/*
+	refused while apps are bound to the instance, unless ?force=true is given, which unbinds them first
+*/
 func (r *instanceRouter) deleteInstance(c *gin.Context) {
 	name := nameFromPath(c)
-	result := r.instanceService.Delete(name)
+	// anything but a true value is taken as not forced
+	force, _ := strconv.ParseBool(c.Query("force"))
+	appNames, operation, result := r.instanceService.Delete(name, force)
 
 	if result == services.InstanceDeletionNotFound {
 		c.JSON(http.StatusNotFound, models.Error{
@@ -157,6 +303,22 @@ func (r *instanceRouter) deleteInstance(c *gin.Context) {
 		return
 	}
 
+	if result == services.InstanceDeletionHasBindings {
+		c.JSON(http.StatusConflict, models.Error{
+			Code:    models.ErrorInstanceDeleteHasBindings,
+			Message: fmt.Sprintf("Instance still has apps bound, unbind them first: %s", strings.Join(appNames, ", ")),
+		})
+		return
+	}
+
+	if result == services.InstanceDeletionBusy {
+		c.JSON(http.StatusConflict, models.Error{
+			Code:    models.ErrorInstanceDeleteBusy,
+			Message: "Instance is busy with another operation, try again later",
+		})
+		return
+	}
+
 	if result == services.InstanceDeletionFailure {
 		c.JSON(http.StatusInternalServerError, models.Error{
 			Code:    models.ErrorInstanceDeleteFailed,
@@ -168,11 +330,12 @@ func (r *instanceRouter) deleteInstance(c *gin.Context) {
 	if result == services.InstanceDeletionDeprovisionFailure {
 		c.JSON(http.StatusInternalServerError, models.Error{
 			Code:    models.ErrorInstanceDeleteDispatchDeprovisionFailed,
-			Message: "Instance deleted, but unable to dispatch deprovision. Please deprovision it manually",
+			Message: "Unable to dispatch deprovision, instance was not deleted",
 		})
 		return
 	}
 
+	setOperationLocation(c, operation)
 	c.Status(http.StatusOK)
 }
 
@@ -210,6 +373,14 @@ func (r *instanceRouter) getInstanceStatus(c *gin.Context) {
 		return
 	}
 
+	if result == services.InstanceStatusDegradedStatus {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorInstanceStatusInstanceDegraded,
+			Message: "Instance is in degraded status",
+		})
+		return
+	}
+
 	if result == services.InstanceStatusPendingStatus {
 		c.Status(http.StatusAccepted)
 		return
@@ -227,6 +398,7 @@ func (r *instanceRouter) SetupRoutes(router gin.IRouter) {
 	router.PUT("/:name", r.putInstance)
 	router.DELETE("/:name", r.deleteInstance)
 	router.GET("/:name/status", r.getInstanceStatus)
+	router.GET("/:name/history", r.getInstanceHistory)
 }
 
 func NewInstanceRouter(instanceService services.InstanceService, planService services.PlanService) routers.Router {
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/pushaas/pushaas/pushaas/mocks"
 	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
 	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
 	"github.com/pushaas/pushaas/pushaas/services"
 )
@@ -40,12 +42,6 @@ var _ = Describe("InstanceRouter", func() {
 		return body
 	}
 
-	bodyToInstance := func(recorder *httptest.ResponseRecorder) *models.Instance {
-		var body *models.Instance
-		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
-		return body
-	}
-
 	bodyToPlans := func(recorder *httptest.ResponseRecorder) []models.Plan {
 		var body []models.Plan
 		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
@@ -57,8 +53,10 @@ var _ = Describe("InstanceRouter", func() {
 			// arrange
 			expected := []models.Plan{
 				{
-					Name:        models.PlanSmall,
-					Description: "The only plan",
+					Name:        "small",
+					Description: "For apps with few subscribers",
+					Cpu:         512,
+					Memory:      1024,
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{}
@@ -84,11 +82,52 @@ var _ = Describe("InstanceRouter", func() {
 	})
 
 	_ = Describe("PUT instance", func() {
-		_ = It("always returns 404", func() {
+		_ = It("returns 200 when updates successfully", func() {
 			// arrange
-			instanceService := &mocks.InstanceServiceMock{}
-			planService := &mocks.PlanServiceMock{}
-			ginRouter := prepareGinRouter(instanceService, planService)
+			expected := &models.InstanceUpdateForm{
+				Plan:        "plan",
+				Team:        "team",
+				Description: "description",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				UpdateFunc: func(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult) {
+					return &models.Operation{Id: "operation-1"}, services.InstanceUpdateSuccess
+				},
+			}
+
+			data := url.Values{}
+			data.Set("plan", expected.Plan)
+			data.Set("team", expected.Team)
+			data.Set("description", expected.Description)
+
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", fmt.Sprintf("/%s", instanceName), strings.NewReader(data.Encode()))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Header().Get("Location")).To(Equal("/api/v1/operations/operation-1"))
+			Expect(instanceService.UpdateCalls()).To(HaveLen(1))
+			Expect(instanceService.UpdateCalls()[0].Name).To(Equal(instanceName))
+			Expect(instanceService.UpdateCalls()[0].InstanceUpdateForm).To(Equal(expected))
+		})
+
+		_ = It("returns 404 when instance is not found", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceUpdateNotFound,
+				Message: "Instance not found",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				UpdateFunc: func(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult) {
+					return nil, services.InstanceUpdateNotFound
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
 			recorder := httptest.NewRecorder()
 			req, _ := http.NewRequest("PUT", fmt.Sprintf("/%s", instanceName), nil)
 
@@ -96,18 +135,295 @@ var _ = Describe("InstanceRouter", func() {
 			ginRouter.ServeHTTP(recorder, req)
 
 			// assert
+			actual := bodyToError(recorder)
+			Expect(actual).To(Equal(expected))
 			Expect(recorder.Code).To(Equal(404))
+			Expect(instanceService.UpdateCalls()).To(HaveLen(1))
+		})
+
+		_ = It("returns 400 when data is invalid", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceUpdateInvalidData,
+				Message: "Invalid instance data",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				UpdateFunc: func(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult) {
+					return nil, services.InstanceUpdateInvalidData
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", fmt.Sprintf("/%s", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			actual := bodyToError(recorder)
+			Expect(actual).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(400))
+			Expect(instanceService.UpdateCalls()).To(HaveLen(1))
+		})
+
+		_ = It("returns 412 when changing the plan of an instance that is not running", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceUpdateNotRunning,
+				Message: "The plan can only be changed while the instance is running",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				UpdateFunc: func(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult) {
+					return nil, services.InstanceUpdateNotRunning
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", fmt.Sprintf("/%s", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			actual := bodyToError(recorder)
+			Expect(actual).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(412))
+			Expect(instanceService.UpdateCalls()).To(HaveLen(1))
+		})
+
+		_ = It("returns 409 when the instance is busy with another operation", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceUpdateBusy,
+				Message: "Instance is busy with another operation, try again later",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				UpdateFunc: func(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult) {
+					return nil, services.InstanceUpdateBusy
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", fmt.Sprintf("/%s", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			actual := bodyToError(recorder)
+			Expect(actual).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(409))
+		})
+
+		_ = It("returns 500 when fails to update", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceUpdateFailed,
+				Message: "Failed to update instance",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				UpdateFunc: func(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult) {
+					return nil, services.InstanceUpdateFailure
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", fmt.Sprintf("/%s", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			actual := bodyToError(recorder)
+			Expect(actual).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(500))
+			Expect(instanceService.UpdateCalls()).To(HaveLen(1))
+		})
+
+		_ = It("returns 500 when fails to dispatch update", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceUpdateDispatchUpdateFailed,
+				Message: "Unable to dispatch update, instance was not updated",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				UpdateFunc: func(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, services.InstanceUpdateResult) {
+					return nil, services.InstanceUpdateDispatchUpdateFailure
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", fmt.Sprintf("/%s", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			actual := bodyToError(recorder)
+			Expect(actual).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(500))
+			Expect(instanceService.UpdateCalls()).To(HaveLen(1))
+		})
+	})
+
+	_ = Describe("GET instances", func() {
+		_ = It("returns a page of the instances, narrowed by the query", func() {
+			// arrange
+			expected := &models.InstancePage{
+				Instances:  []*models.Instance{{Name: instanceName}},
+				NextCursor: "next-cursor",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, services.InstanceRetrievalResult) {
+					return expected, services.InstanceRetrievalSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instances?team=team-1&user=user-1&plan=small&status=running&cursor=some-cursor&limit=10", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(`{"instances":[{"name":"instance-1","plan":"","team":"","user":"","status":""}],"nextCursor":"next-cursor"}`))
+			Expect(instanceService.ListCalls()).To(HaveLen(1))
+			Expect(instanceService.ListCalls()[0].Filter).To(Equal(&models.InstanceFilter{
+				Team:   "team-1",
+				User:   "user-1",
+				Plan:   "small",
+				Status: models.InstanceStatusRunning,
+			}))
+			Expect(instanceService.ListCalls()[0].Cursor).To(Equal("some-cursor"))
+			Expect(instanceService.ListCalls()[0].Limit).To(Equal(10))
+		})
+
+		_ = It("narrows the page to the team of a member", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, services.InstanceRetrievalResult) {
+					return &models.InstancePage{Instances: []*models.Instance{}}, services.InstanceRetrievalSuccess
+				},
+			}
+			authService := &mocks.AuthServiceMock{
+				AuthenticateFunc: func(username string, password string) (*models.Principal, services.AuthenticationResult) {
+					return &models.Principal{Name: username, Role: models.RoleMember, Team: "team-1"}, services.AuthenticationSuccess
+				},
+			}
+			ginRouter := gin.New()
+			ginRouter.Use(routers.NewAuthMiddleware(authService))
+			apiV1.NewInstanceRouter(instanceService, nil).SetupRoutes(ginRouter)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instances?plan=small", nil)
+			req.SetBasicAuth("team-1-token", "some-secret")
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(instanceService.ListCalls()[0].Filter).To(Equal(&models.InstanceFilter{
+				Team: "team-1",
+				Plan: "small",
+			}))
+		})
+
+		_ = It("returns an empty page when there are no instances", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, services.InstanceRetrievalResult) {
+					return &models.InstancePage{Instances: []*models.Instance{}}, services.InstanceRetrievalSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instances", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(`{"instances":[]}`))
+			Expect(instanceService.ListCalls()[0].Limit).To(Equal(0))
+		})
+
+		for _, query := range []string{"limit=abc", "limit=0", "status=unknown"} {
+			query := query
+			_ = It(fmt.Sprintf("returns error for invalid query %s", query), func() {
+				// arrange
+				expected := &models.Error{
+					Code:    models.ErrorInstanceRetrievalInvalidQuery,
+					Message: "Invalid cursor, limit or status",
+				}
+				instanceService := &mocks.InstanceServiceMock{}
+				ginRouter := prepareGinRouter(instanceService, nil)
+				recorder := httptest.NewRecorder()
+				req, _ := http.NewRequest("GET", "/instances?"+query, nil)
+
+				// act
+				ginRouter.ServeHTTP(recorder, req)
+
+				// assert
+				Expect(bodyToError(recorder)).To(Equal(expected))
+				Expect(recorder.Code).To(Equal(400))
+				Expect(instanceService.ListCalls()).To(HaveLen(0))
+			})
+		}
+
+		_ = It("returns error for invalid cursor", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, services.InstanceRetrievalResult) {
+					return nil, services.InstanceRetrievalInvalidCursor
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instances?cursor=bad", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorInstanceRetrievalInvalidQuery))
+			Expect(recorder.Code).To(Equal(400))
+		})
+
+		_ = It("returns error when failure occurs", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceRetrievalFailed,
+				Message: "Failed to retrieve instances",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, services.InstanceRetrievalResult) {
+					return nil, services.InstanceRetrievalFailure
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/instances", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(bodyToError(recorder)).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(500))
 		})
 	})
 
 	_ = Describe("GET instance", func() {
-		_ = It("returns the instance if found", func() {
+		_ = It("returns the info of the instance if found", func() {
 			// arrange
-			expected := &models.Instance{
-				Name: instanceName,
+			expected := []*models.InstanceInfoItem{
+				{Label: "Plan", Value: "small"},
+				{Label: "Status", Value: "running"},
 			}
 			instanceService := &mocks.InstanceServiceMock{
-				GetByNameFunc: func(name string) (instance *models.Instance, result services.InstanceRetrievalResult) {
+				GetInfoFunc: func(name string) ([]*models.InstanceInfoItem, services.InstanceRetrievalResult) {
 					return expected, services.InstanceRetrievalSuccess
 				},
 			}
@@ -120,11 +436,10 @@ var _ = Describe("InstanceRouter", func() {
 			ginRouter.ServeHTTP(recorder, req)
 
 			// assert
-			actual := bodyToInstance(recorder)
-			Expect(actual).To(Equal(expected))
-			Expect(recorder.Body.String()).To(Equal(`{"name":"instance-1","plan":"","team":"","user":"","status":""}`))
+			Expect(recorder.Body.String()).To(Equal(`[{"label":"Plan","value":"small"},{"label":"Status","value":"running"}]`))
 			Expect(recorder.Code).To(Equal(200))
-			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
+			Expect(instanceService.GetInfoCalls()).To(HaveLen(1))
+			Expect(instanceService.GetInfoCalls()[0].Name).To(Equal(instanceName))
 			Expect(planService.GetAllCalls()).To(HaveLen(0))
 		})
 
@@ -135,7 +450,7 @@ var _ = Describe("InstanceRouter", func() {
 				Message: "Instance not found",
 			}
 			instanceService := &mocks.InstanceServiceMock{
-				GetByNameFunc: func(name string) (instance *models.Instance, result services.InstanceRetrievalResult) {
+				GetInfoFunc: func(name string) ([]*models.InstanceInfoItem, services.InstanceRetrievalResult) {
 					return nil, services.InstanceRetrievalNotFound
 				},
 			}
@@ -150,7 +465,7 @@ var _ = Describe("InstanceRouter", func() {
 			actual := bodyToError(recorder)
 			Expect(actual).To(Equal(expected))
 			Expect(recorder.Code).To(Equal(404))
-			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
+			Expect(instanceService.GetInfoCalls()).To(HaveLen(1))
 		})
 
 		_ = It("returns error when failure occurs", func() {
@@ -160,7 +475,7 @@ var _ = Describe("InstanceRouter", func() {
 				Message: "Failed to retrieve instance",
 			}
 			instanceService := &mocks.InstanceServiceMock{
-				GetByNameFunc: func(name string) (instance *models.Instance, result services.InstanceRetrievalResult) {
+				GetInfoFunc: func(name string) ([]*models.InstanceInfoItem, services.InstanceRetrievalResult) {
 					return nil, services.InstanceRetrievalFailure
 				},
 			}
@@ -175,7 +490,82 @@ var _ = Describe("InstanceRouter", func() {
 			actual := bodyToError(recorder)
 			Expect(actual).To(Equal(expected))
 			Expect(recorder.Code).To(Equal(500))
-			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
+			Expect(instanceService.GetInfoCalls()).To(HaveLen(1))
+		})
+	})
+
+	_ = Describe("GET instance history", func() {
+		_ = It("returns the history of the statuses of the instance", func() {
+			// arrange
+			at := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+			instanceService := &mocks.InstanceServiceMock{
+				GetStatusHistoryFunc: func(name string) ([]*models.InstanceStatusTransition, services.InstanceRetrievalResult) {
+					return []*models.InstanceStatusTransition{
+						{To: models.InstanceStatusPending, At: at},
+						{From: models.InstanceStatusPending, To: models.InstanceStatusFailed, Reason: "push-redis: some error", At: at},
+					}, services.InstanceRetrievalSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/%s/history", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(`[` +
+				`{"from":"","to":"pending","at":"2019-06-01T12:00:00Z"},` +
+				`{"from":"pending","to":"failed","reason":"push-redis: some error","at":"2019-06-01T12:00:00Z"}` +
+				`]`))
+			Expect(instanceService.GetStatusHistoryCalls()[0].Name).To(Equal(instanceName))
+		})
+
+		_ = It("returns 404 when instance is not found", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceRetrievalNotFound,
+				Message: "Instance not found",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				GetStatusHistoryFunc: func(name string) ([]*models.InstanceStatusTransition, services.InstanceRetrievalResult) {
+					return nil, services.InstanceRetrievalNotFound
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/%s/history", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(bodyToError(recorder)).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(404))
+		})
+
+		_ = It("returns error when failure occurs", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceRetrievalFailed,
+				Message: "Failed to retrieve instance history",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				GetStatusHistoryFunc: func(name string) ([]*models.InstanceStatusTransition, services.InstanceRetrievalResult) {
+					return nil, services.InstanceRetrievalFailure
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/%s/history", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(bodyToError(recorder)).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(500))
 		})
 	})
 
@@ -274,6 +664,31 @@ var _ = Describe("InstanceRouter", func() {
 			Expect(instanceService.GetStatusByNameCalls()).To(HaveLen(1))
 		})
 
+		_ = It("returns 500 when instance is in degraded status", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceStatusInstanceDegraded,
+				Message: "Instance is in degraded status",
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				GetStatusByNameFunc: func(name string) services.InstanceStatusResult {
+					return services.InstanceStatusDegradedStatus
+				},
+			}
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/%s/status", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			actual := bodyToError(recorder)
+			Expect(actual).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(500))
+			Expect(instanceService.GetStatusByNameCalls()).To(HaveLen(1))
+		})
+
 		_ = It("returns 202 when instance is in pending status", func() {
 			// arrange
 			instanceService := &mocks.InstanceServiceMock{
@@ -298,8 +713,8 @@ var _ = Describe("InstanceRouter", func() {
 		_ = It("returns 201 when creates successfully", func() {
 			// arrange
 			instanceService := &mocks.InstanceServiceMock{
-				CreateFunc: func(instanceForm *models.InstanceForm) services.InstanceCreationResult {
-					return services.InstanceCreationSuccess
+				CreateFunc: func(instanceForm *models.InstanceForm) (*models.Operation, services.InstanceCreationResult) {
+					return &models.Operation{Id: "operation-1"}, services.InstanceCreationSuccess
 				},
 			}
 
@@ -319,6 +734,7 @@ var _ = Describe("InstanceRouter", func() {
 
 			// assert
 			Expect(recorder.Code).To(Equal(201))
+			Expect(recorder.Header().Get("Location")).To(Equal("/api/v1/operations/operation-1"))
 			Expect(instanceService.CreateCalls()).To(HaveLen(1))
 			Expect(instanceService.CreateCalls()[0].InstanceForm).To(Equal(instanceForm))
 		})
@@ -331,8 +747,8 @@ var _ = Describe("InstanceRouter", func() {
 			}
 
 			instanceService := &mocks.InstanceServiceMock{
-				CreateFunc: func(instanceForm *models.InstanceForm) services.InstanceCreationResult {
-					return services.InstanceCreationAlreadyExist
+				CreateFunc: func(instanceForm *models.InstanceForm) (*models.Operation, services.InstanceCreationResult) {
+					return nil, services.InstanceCreationAlreadyExist
 				},
 			}
 
@@ -358,8 +774,8 @@ var _ = Describe("InstanceRouter", func() {
 			}
 
 			instanceService := &mocks.InstanceServiceMock{
-				CreateFunc: func(instanceForm *models.InstanceForm) services.InstanceCreationResult {
-					return services.InstanceCreationInvalidData
+				CreateFunc: func(instanceForm *models.InstanceForm) (*models.Operation, services.InstanceCreationResult) {
+					return nil, services.InstanceCreationInvalidData
 				},
 			}
 
@@ -385,8 +801,8 @@ var _ = Describe("InstanceRouter", func() {
 			}
 
 			instanceService := &mocks.InstanceServiceMock{
-				CreateFunc: func(instanceForm *models.InstanceForm) services.InstanceCreationResult {
-					return services.InstanceCreationFailure
+				CreateFunc: func(instanceForm *models.InstanceForm) (*models.Operation, services.InstanceCreationResult) {
+					return nil, services.InstanceCreationFailure
 				},
 			}
 
@@ -404,16 +820,16 @@ var _ = Describe("InstanceRouter", func() {
 			Expect(instanceService.CreateCalls()).To(HaveLen(1))
 		})
 
-		_ = It("returns 500 when creates successfully but fails to dispatch provision", func() {
+		_ = It("returns 500 when fails to dispatch provision", func() {
 			// arrange
 			expected := &models.Error{
 				Code:    models.ErrorInstanceCreateDispatchProvisionFailed,
-				Message: "Instance created, but unable to dispatch provision. Please remove it manually",
+				Message: "Unable to dispatch provision, the instance was not created",
 			}
 
 			instanceService := &mocks.InstanceServiceMock{
-				CreateFunc: func(instanceForm *models.InstanceForm) services.InstanceCreationResult {
-					return services.InstanceCreationProvisionFailure
+				CreateFunc: func(instanceForm *models.InstanceForm) (*models.Operation, services.InstanceCreationResult) {
+					return nil, services.InstanceCreationProvisionFailure
 				},
 			}
 
@@ -436,8 +852,8 @@ var _ = Describe("InstanceRouter", func() {
 		_ = It("returns 201 when creates successfully", func() {
 			// arrange
 			instanceService := &mocks.InstanceServiceMock{
-				DeleteFunc: func(name string) services.InstanceDeletionResult {
-					return services.InstanceDeletionSuccess
+				DeleteFunc: func(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult) {
+					return nil, &models.Operation{Id: "operation-1"}, services.InstanceDeletionSuccess
 				},
 			}
 
@@ -450,6 +866,82 @@ var _ = Describe("InstanceRouter", func() {
 
 			// assert
 			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Header().Get("Location")).To(Equal("/api/v1/operations/operation-1"))
+			Expect(instanceService.DeleteCalls()).To(HaveLen(1))
+			Expect(instanceService.DeleteCalls()[0].Force).To(BeFalse())
+		})
+
+		_ = It("forces the deletion when told", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				DeleteFunc: func(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult) {
+					return nil, &models.Operation{Id: "operation-1"}, services.InstanceDeletionSuccess
+				},
+			}
+
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", fmt.Sprintf("/%s?force=true", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(instanceService.DeleteCalls()).To(HaveLen(1))
+			Expect(instanceService.DeleteCalls()[0].Force).To(BeTrue())
+		})
+
+		_ = It("returns 409 when the instance is busy with another operation", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceDeleteBusy,
+				Message: "Instance is busy with another operation, try again later",
+			}
+
+			instanceService := &mocks.InstanceServiceMock{
+				DeleteFunc: func(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult) {
+					return nil, nil, services.InstanceDeletionBusy
+				},
+			}
+
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", fmt.Sprintf("/%s", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			actual := bodyToError(recorder)
+			Expect(actual).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(409))
+		})
+
+		_ = It("returns 409 listing the bound apps when apps are still bound", func() {
+			// arrange
+			expected := &models.Error{
+				Code:    models.ErrorInstanceDeleteHasBindings,
+				Message: "Instance still has apps bound, unbind them first: app-1, app-2",
+			}
+
+			instanceService := &mocks.InstanceServiceMock{
+				DeleteFunc: func(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult) {
+					return []string{"app-1", "app-2"}, nil, services.InstanceDeletionHasBindings
+				},
+			}
+
+			ginRouter := prepareGinRouter(instanceService, nil)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", fmt.Sprintf("/%s", instanceName), nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			actual := bodyToError(recorder)
+			Expect(actual).To(Equal(expected))
+			Expect(recorder.Code).To(Equal(409))
 			Expect(instanceService.DeleteCalls()).To(HaveLen(1))
 		})
 
@@ -461,8 +953,8 @@ var _ = Describe("InstanceRouter", func() {
 			}
 
 			instanceService := &mocks.InstanceServiceMock{
-				DeleteFunc: func(name string) services.InstanceDeletionResult {
-					return services.InstanceDeletionNotFound
+				DeleteFunc: func(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult) {
+					return nil, nil, services.InstanceDeletionNotFound
 				},
 			}
 
@@ -488,8 +980,8 @@ var _ = Describe("InstanceRouter", func() {
 			}
 
 			instanceService := &mocks.InstanceServiceMock{
-				DeleteFunc: func(name string) services.InstanceDeletionResult {
-					return services.InstanceDeletionFailure
+				DeleteFunc: func(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult) {
+					return nil, nil, services.InstanceDeletionFailure
 				},
 			}
 
@@ -511,12 +1003,12 @@ var _ = Describe("InstanceRouter", func() {
 			// arrange
 			expected := &models.Error{
 				Code:    models.ErrorInstanceDeleteDispatchDeprovisionFailed,
-				Message: "Instance deleted, but unable to dispatch deprovision. Please deprovision it manually",
+				Message: "Unable to dispatch deprovision, instance was not deleted",
 			}
 
 			instanceService := &mocks.InstanceServiceMock{
-				DeleteFunc: func(name string) services.InstanceDeletionResult {
-					return services.InstanceDeletionDeprovisionFailure
+				DeleteFunc: func(name string, force bool) ([]string, *models.Operation, services.InstanceDeletionResult) {
+					return nil, nil, services.InstanceDeletionDeprovisionFailure
 				},
 			}
 
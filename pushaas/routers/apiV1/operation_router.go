@@ -0,0 +1,78 @@
+package apiV1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	OperationRouter interface {
+		routers.Router
+	}
+
+	operationRouter struct {
+		operationService services.OperationService
+	}
+)
+
+// where the operations router is mounted
+const operationsPath = "/api/v1/operations"
+
+/*
+	tells the caller where to follow the operation it started, if it started one
+*/
+func setOperationLocation(c *gin.Context, operation *models.Operation) {
+	if operation == nil {
+		return
+	}
+	c.Header("Location", fmt.Sprintf("%s/%s", operationsPath, operation.Id))
+}
+
+/*
+	members only see the operations on the instances of their team
+*/
+func (r *operationRouter) getOperation(c *gin.Context) {
+	operation, result := r.operationService.Get(c.Param("id"))
+
+	if result == services.OperationRetrievalNotFound {
+		c.JSON(http.StatusNotFound, models.Error{
+			Code:    models.ErrorOperationRetrievalNotFound,
+			Message: "Operation not found",
+		})
+		return
+	}
+
+	if result == services.OperationRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorOperationRetrievalFailed,
+			Message: "Failed to retrieve operation",
+		})
+		return
+	}
+
+	if !routers.PrincipalFromContext(c).CanAccessTeam(operation.Team) {
+		c.JSON(http.StatusForbidden, models.Error{
+			Code:    models.ErrorAuthForbidden,
+			Message: "Not allowed for the team",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, operation)
+}
+
+func (r *operationRouter) SetupRoutes(router gin.IRouter) {
+	router.GET("/:id", r.getOperation)
+}
+
+func NewOperationRouter(operationService services.OperationService) OperationRouter {
+	return &operationRouter{
+		operationService: operationService,
+	}
+}
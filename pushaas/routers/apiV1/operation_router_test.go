@@ -0,0 +1,135 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("OperationRouter", func() {
+	prepareGinRouter := func(operationService services.OperationService, authMiddleware gin.HandlerFunc) *gin.Engine {
+		ginRouter := gin.New()
+		ginRouter.Use(authMiddleware)
+		router := apiV1.NewOperationRouter(operationService)
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	bodyToError := func(recorder *httptest.ResponseRecorder) *models.Error {
+		var body *models.Error
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	newOperationService := func(operation *models.Operation, result services.OperationRetrievalResult) *mocks.OperationServiceMock {
+		return &mocks.OperationServiceMock{
+			GetFunc: func(id string) (*models.Operation, services.OperationRetrievalResult) {
+				return operation, result
+			},
+		}
+	}
+
+	memberOf := func(team string) gin.HandlerFunc {
+		return routers.NewAuthMiddleware(&mocks.AuthServiceMock{
+			AuthenticateFunc: func(username string, password string) (*models.Principal, services.AuthenticationResult) {
+				return &models.Principal{Name: username, Role: models.RoleMember, Team: team}, services.AuthenticationSuccess
+			},
+		})
+	}
+
+	operation := &models.Operation{
+		Id:       "operation-1",
+		Type:     models.OperationTypeProvision,
+		Instance: "instance-1",
+		Team:     "team-1",
+		State:    models.OperationStateRunning,
+		Steps:    []*models.OperationStep{{Name: "provision"}},
+	}
+
+	_ = Describe("GET operation", func() {
+		_ = It("returns the operation", func() {
+			// arrange
+			operationService := newOperationService(operation, services.OperationRetrievalSuccess)
+			ginRouter := prepareGinRouter(operationService, routers.NewNoAuthMiddleware())
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/operation-1", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			var body *models.Operation
+			_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+			Expect(recorder.Code).To(Equal(200))
+			Expect(body).To(Equal(operation))
+			Expect(operationService.GetCalls()[0].ID).To(Equal("operation-1"))
+		})
+
+		_ = It("returns the operation to the members of its team", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newOperationService(operation, services.OperationRetrievalSuccess), memberOf("team-1"))
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/operation-1", nil)
+			req.SetBasicAuth("team-1-token", "some-secret")
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+		})
+
+		_ = It("returns 403 to the members of another team", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newOperationService(operation, services.OperationRetrievalSuccess), memberOf("team-2"))
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/operation-1", nil)
+			req.SetBasicAuth("team-2-token", "some-secret")
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(403))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorAuthForbidden))
+		})
+
+		_ = It("returns 404 when the operation is not found", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newOperationService(nil, services.OperationRetrievalNotFound), routers.NewNoAuthMiddleware())
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/operation-1", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(404))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorOperationRetrievalNotFound))
+		})
+
+		_ = It("returns 500 when fails to retrieve the operation", func() {
+			// arrange
+			ginRouter := prepareGinRouter(newOperationService(nil, services.OperationRetrievalFailure), routers.NewNoAuthMiddleware())
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/operation-1", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorOperationRetrievalFailed))
+		})
+	})
+})
@@ -0,0 +1,112 @@
+package apiV1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	TokenRouter interface {
+		routers.Router
+	}
+
+	tokenRouter struct {
+		authService services.AuthService
+	}
+)
+
+func tokenFormFromContext(c *gin.Context) *models.ApiTokenForm {
+	return &models.ApiTokenForm{
+		Name:        c.PostForm("name"),
+		Description: c.PostForm("description"),
+		Role:        models.Role(c.PostForm("role")),
+		Team:        c.PostForm("team"),
+	}
+}
+
+func (r *tokenRouter) getTokens(c *gin.Context) {
+	tokens, result := r.authService.GetTokens()
+
+	if result == services.TokenRetrievalFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorTokenRetrievalFailed,
+			Message: "Failed to retrieve tokens",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+/*
+	the secret is on the response only, it cannot be retrieved afterwards
+*/
+func (r *tokenRouter) postToken(c *gin.Context) {
+	tokenForm := tokenFormFromContext(c)
+	token, result := r.authService.CreateToken(tokenForm, routers.PrincipalFromContext(c))
+
+	if result == services.TokenCreationInvalidData {
+		c.JSON(http.StatusBadRequest, models.Error{
+			Code:    models.ErrorTokenCreateInvalidData,
+			Message: "Invalid data",
+		})
+		return
+	}
+
+	if result == services.TokenCreationAlreadyExist {
+		c.JSON(http.StatusConflict, models.Error{
+			Code:    models.ErrorTokenCreateAlreadyExists,
+			Message: "Token already exists with this name",
+		})
+		return
+	}
+
+	if result == services.TokenCreationFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorTokenCreateFailed,
+			Message: "Failed to create token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+func (r *tokenRouter) deleteToken(c *gin.Context) {
+	result := r.authService.RevokeToken(c.Param("token"))
+
+	if result == services.TokenRevocationNotFound {
+		c.JSON(http.StatusNotFound, models.Error{
+			Code:    models.ErrorTokenRevokeNotFound,
+			Message: "Token not found",
+		})
+		return
+	}
+
+	if result == services.TokenRevocationFailure {
+		c.JSON(http.StatusInternalServerError, models.Error{
+			Code:    models.ErrorTokenRevokeFailed,
+			Message: "Failed to revoke token",
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (r *tokenRouter) SetupRoutes(router gin.IRouter) {
+	router.GET("/tokens", r.getTokens)
+	router.POST("/tokens", r.postToken)
+	router.DELETE("/tokens/:token", r.deleteToken)
+}
+
+func NewTokenRouter(authService services.AuthService) TokenRouter {
+	return &tokenRouter{
+		authService: authService,
+	}
+}
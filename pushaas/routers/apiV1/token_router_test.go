@@ -0,0 +1,229 @@
+package apiV1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/routers/apiV1"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("TokenRouter", func() {
+	prepareGinRouter := func(authService services.AuthService) *gin.Engine {
+		ginRouter := gin.New()
+		ginRouter.Use(routers.NewNoAuthMiddleware())
+		router := apiV1.NewTokenRouter(authService)
+		router.SetupRoutes(ginRouter)
+		return ginRouter
+	}
+
+	bodyToError := func(recorder *httptest.ResponseRecorder) *models.Error {
+		var body *models.Error
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	newTokenRequest := func(name string) *http.Request {
+		form := url.Values{}
+		form.Add("name", name)
+		form.Add("description", "some description")
+		form.Add("role", "member")
+		form.Add("team", "team-1")
+		req, _ := http.NewRequest("POST", "/tokens", strings.NewReader(form.Encode()))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	_ = Describe("GET tokens", func() {
+		_ = It("returns the tokens", func() {
+			// arrange
+			createdAt := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+			authService := &mocks.AuthServiceMock{
+				GetTokensFunc: func() ([]*models.ApiToken, services.TokenRetrievalResult) {
+					return []*models.ApiToken{{Name: "automation", Role: models.RoleAdmin, CreatedBy: "tsuru", CreatedAt: createdAt}}, services.TokenRetrievalSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/tokens", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(recorder.Body.String()).To(Equal(`[{"name":"automation","role":"admin","createdBy":"tsuru","createdAt":"2019-06-01T12:00:00Z"}]`))
+		})
+
+		_ = It("returns 500 when fails to retrieve the tokens", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				GetTokensFunc: func() ([]*models.ApiToken, services.TokenRetrievalResult) {
+					return nil, services.TokenRetrievalFailure
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/tokens", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorTokenRetrievalFailed))
+		})
+	})
+
+	_ = Describe("POST token", func() {
+		_ = It("returns 201 with the secret of the token", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				CreateTokenFunc: func(tokenForm *models.ApiTokenForm, createdBy *models.Principal) (*models.CreatedApiToken, services.TokenCreationResult) {
+					token := &models.ApiToken{Name: tokenForm.Name, Description: tokenForm.Description, Role: tokenForm.Role, Team: tokenForm.Team, CreatedBy: createdBy.Name}
+					return &models.CreatedApiToken{ApiToken: token, Secret: "some-secret"}, services.TokenCreationSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+
+			// act
+			ginRouter.ServeHTTP(recorder, newTokenRequest("automation"))
+
+			// assert
+			var body map[string]interface{}
+			_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+			Expect(recorder.Code).To(Equal(201))
+			Expect(body["name"]).To(Equal("automation"))
+			Expect(body["description"]).To(Equal("some description"))
+			Expect(body["role"]).To(Equal("member"))
+			Expect(body["team"]).To(Equal("team-1"))
+			Expect(body["secret"]).To(Equal("some-secret"))
+			Expect(authService.CreateTokenCalls()[0].CreatedBy.Kind).To(Equal(models.PrincipalKindAnonymous))
+		})
+
+		_ = It("returns 400 when the data is invalid", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				CreateTokenFunc: func(tokenForm *models.ApiTokenForm, createdBy *models.Principal) (*models.CreatedApiToken, services.TokenCreationResult) {
+					return nil, services.TokenCreationInvalidData
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+
+			// act
+			ginRouter.ServeHTTP(recorder, newTokenRequest(""))
+
+			// assert
+			Expect(recorder.Code).To(Equal(400))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorTokenCreateInvalidData))
+		})
+
+		_ = It("returns 409 when the token already exists", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				CreateTokenFunc: func(tokenForm *models.ApiTokenForm, createdBy *models.Principal) (*models.CreatedApiToken, services.TokenCreationResult) {
+					return nil, services.TokenCreationAlreadyExist
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+
+			// act
+			ginRouter.ServeHTTP(recorder, newTokenRequest("automation"))
+
+			// assert
+			Expect(recorder.Code).To(Equal(409))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorTokenCreateAlreadyExists))
+		})
+
+		_ = It("returns 500 when fails to create the token", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				CreateTokenFunc: func(tokenForm *models.ApiTokenForm, createdBy *models.Principal) (*models.CreatedApiToken, services.TokenCreationResult) {
+					return nil, services.TokenCreationFailure
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+
+			// act
+			ginRouter.ServeHTTP(recorder, newTokenRequest("automation"))
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorTokenCreateFailed))
+		})
+	})
+
+	_ = Describe("DELETE token", func() {
+		_ = It("returns 200 when the token is revoked", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				RevokeTokenFunc: func(name string) services.TokenRevocationResult {
+					return services.TokenRevocationSuccess
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/tokens/automation", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(authService.RevokeTokenCalls()[0].Name).To(Equal("automation"))
+		})
+
+		_ = It("returns 404 when the token is not found", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				RevokeTokenFunc: func(name string) services.TokenRevocationResult {
+					return services.TokenRevocationNotFound
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/tokens/automation", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(404))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorTokenRevokeNotFound))
+		})
+
+		_ = It("returns 500 when fails to revoke the token", func() {
+			// arrange
+			authService := &mocks.AuthServiceMock{
+				RevokeTokenFunc: func(name string) services.TokenRevocationResult {
+					return services.TokenRevocationFailure
+				},
+			}
+			ginRouter := prepareGinRouter(authService)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/tokens/automation", nil)
+
+			// act
+			ginRouter.ServeHTTP(recorder, req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorTokenRevokeFailed))
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package routers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	// keeps what is written on the response, to find the code of the error on it
+	auditResponseWriter struct {
+		gin.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// the calls audited, by method and by the path after the name of the instance
+var auditActions = map[string]models.AuditAction{
+	"POST ":                    models.AuditActionCreateInstance,
+	"PUT ":                     models.AuditActionUpdateInstance,
+	"DELETE ":                  models.AuditActionDeleteInstance,
+	"POST /bind-app":           models.AuditActionBindApp,
+	"DELETE /bind-app":         models.AuditActionUnbindApp,
+	"POST /bind":               models.AuditActionBindUnit,
+	"DELETE /bind":             models.AuditActionUnbindUnit,
+	"POST /credentials/rotate": models.AuditActionRotateCredentials,
+}
+
+func auditActionOf(c *gin.Context, basePath string) (models.AuditAction, bool) {
+	path := strings.TrimSuffix(strings.TrimPrefix(c.Request.URL.Path, basePath), "/")
+	rest := ""
+	if segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2); len(segments) == 2 {
+		rest = "/" + segments[1]
+	}
+
+	action, ok := auditActions[c.Request.Method+" "+rest]
+	return action, ok
+}
+
+/*
+	tsuru sends the form on the body even on DELETE, which is read here and put back for the route to read it again
+*/
+func peekForm(c *gin.Context) url.Values {
+	if c.Request.Body == nil {
+		return url.Values{}
+	}
+
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return url.Values{}
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	values, _ := url.ParseQuery(string(body))
+	return values
+}
+
+/*
+	Records on the audit the calls that change the instances, to be used after the auth and the request id
+	middlewares on the routes of the instances, mounted on basePath. The team is taken before the call, while the
+	instance still exists.
+*/
+func NewAuditMiddleware(auditService services.AuditService, instanceService services.InstanceService, basePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		action, ok := auditActionOf(c, basePath)
+		if !ok {
+			return
+		}
+
+		form := peekForm(c)
+		entry := &models.AuditEntry{
+			RequestId: RequestIdFromContext(c),
+			Principal: PrincipalFromContext(c),
+			Action:    action,
+			Instance:  c.Param("name"),
+			App:       form.Get("app-name"),
+			UnitHost:  form.Get("unit-host"),
+		}
+		if action == models.AuditActionCreateInstance {
+			entry.Instance = form.Get("name")
+			entry.Team = form.Get("team")
+		} else if instance, result := instanceService.GetByName(entry.Instance); result == services.InstanceRetrievalSuccess {
+			entry.Team = instance.Team
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		entry.Status = writer.Status()
+		if entry.Status >= 400 {
+			var body models.Error
+			if json.Unmarshal(writer.body.Bytes(), &body) == nil {
+				entry.ErrorCode = body.Code
+			}
+		}
+		auditService.Record(entry)
+	}
+}
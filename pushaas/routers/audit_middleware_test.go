@@ -0,0 +1,168 @@
+package routers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("Audit", func() {
+	principal := &models.Principal{Name: "tsuru", Kind: models.PrincipalKindTsuru, Role: models.RoleAdmin}
+
+	prepareGinRouter := func(auditService services.AuditService, instanceService services.InstanceService, status int, body gin.H) *gin.Engine {
+		authService := &mocks.AuthServiceMock{
+			AuthenticateFunc: func(username string, password string) (*models.Principal, services.AuthenticationResult) {
+				return principal, services.AuthenticationSuccess
+			},
+		}
+
+		ginRouter := gin.New()
+		ginRouter.Use(routers.NewRequestIdMiddleware())
+		ginRouter.Use(routers.NewAuthMiddleware(authService))
+		resources := ginRouter.Group("/resources")
+		resources.Use(routers.NewAuditMiddleware(auditService, instanceService, "/resources"))
+		respond := func(c *gin.Context) {
+			if body == nil {
+				c.Status(status)
+				return
+			}
+			c.JSON(status, body)
+		}
+		resources.POST("", respond)
+		resources.GET("/:name", respond)
+		resources.DELETE("/:name", respond)
+		resources.POST("/:name/bind", respond)
+		resources.DELETE("/:name/bind-app", func(c *gin.Context) {
+			// the route reads the body too
+			vs, _ := routers.ParseBody(c)
+			Expect(vs.Get("app-name")).To(Equal("app-1"))
+			respond(c)
+		})
+		return ginRouter
+	}
+
+	instanceServiceOf := func(team string) *mocks.InstanceServiceMock {
+		return &mocks.InstanceServiceMock{
+			GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+				return &models.Instance{Name: name, Team: team}, services.InstanceRetrievalSuccess
+			},
+		}
+	}
+
+	serve := func(ginRouter *gin.Engine, method, path string, form url.Values) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Add("X-Request-Id", "request-1")
+		req.SetBasicAuth("tsuru", "some-password")
+		recorder := httptest.NewRecorder()
+		ginRouter.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("records the creation of an instance, with the team on the form", func() {
+		// arrange
+		auditService := &mocks.AuditServiceMock{RecordFunc: func(entry *models.AuditEntry) {}}
+		instanceService := instanceServiceOf("")
+		ginRouter := prepareGinRouter(auditService, instanceService, 201, nil)
+
+		// act
+		recorder := serve(ginRouter, "POST", "/resources", url.Values{"name": {"instance-1"}, "team": {"team-1"}})
+
+		// assert
+		Expect(recorder.Code).To(Equal(201))
+		Expect(recorder.Header().Get("X-Request-Id")).To(Equal("request-1"))
+		Expect(auditService.RecordCalls()).To(HaveLen(1))
+		Expect(auditService.RecordCalls()[0].Entry).To(Equal(&models.AuditEntry{
+			RequestId: "request-1",
+			Principal: principal,
+			Action:    models.AuditActionCreateInstance,
+			Instance:  "instance-1",
+			Team:      "team-1",
+			Status:    201,
+		}))
+		Expect(instanceService.GetByNameCalls()).To(BeEmpty())
+	})
+
+	It("records the deletion of an instance, with the team it had", func() {
+		// arrange
+		auditService := &mocks.AuditServiceMock{RecordFunc: func(entry *models.AuditEntry) {}}
+		ginRouter := prepareGinRouter(auditService, instanceServiceOf("team-1"), 200, nil)
+
+		// act
+		serve(ginRouter, "DELETE", "/resources/instance-1", url.Values{})
+
+		// assert
+		entry := auditService.RecordCalls()[0].Entry
+		Expect(entry.Action).To(Equal(models.AuditActionDeleteInstance))
+		Expect(entry.Instance).To(Equal("instance-1"))
+		Expect(entry.Team).To(Equal("team-1"))
+	})
+
+	It("records the unbinding of an app, leaving the body for the route", func() {
+		// arrange
+		auditService := &mocks.AuditServiceMock{RecordFunc: func(entry *models.AuditEntry) {}}
+		ginRouter := prepareGinRouter(auditService, instanceServiceOf("team-1"), 200, nil)
+
+		// act
+		serve(ginRouter, "DELETE", "/resources/instance-1/bind-app", url.Values{"app-name": {"app-1"}})
+
+		// assert
+		entry := auditService.RecordCalls()[0].Entry
+		Expect(entry.Action).To(Equal(models.AuditActionUnbindApp))
+		Expect(entry.App).To(Equal("app-1"))
+	})
+
+	It("records the code of the error of a failed call", func() {
+		// arrange
+		auditService := &mocks.AuditServiceMock{RecordFunc: func(entry *models.AuditEntry) {}}
+		ginRouter := prepareGinRouter(auditService, instanceServiceOf("team-1"), 409, gin.H{"code": models.ErrorBindUnitAlreadyBound, "message": "Unit already bound"})
+
+		// act
+		serve(ginRouter, "POST", "/resources/instance-1/bind", url.Values{"app-name": {"app-1"}, "unit-host": {"unit-host-1"}})
+
+		// assert
+		entry := auditService.RecordCalls()[0].Entry
+		Expect(entry.Action).To(Equal(models.AuditActionBindUnit))
+		Expect(entry.UnitHost).To(Equal("unit-host-1"))
+		Expect(entry.Status).To(Equal(409))
+		Expect(entry.ErrorCode).To(Equal(models.ErrorBindUnitAlreadyBound))
+	})
+
+	It("does not record the calls that only read", func() {
+		// arrange
+		auditService := &mocks.AuditServiceMock{}
+		ginRouter := prepareGinRouter(auditService, instanceServiceOf("team-1"), 200, nil)
+
+		// act
+		serve(ginRouter, "GET", "/resources/instance-1", url.Values{})
+
+		// assert
+		Expect(auditService.RecordCalls()).To(BeEmpty())
+	})
+
+	It("gives an id to the requests without one", func() {
+		// arrange
+		auditService := &mocks.AuditServiceMock{RecordFunc: func(entry *models.AuditEntry) {}}
+		ginRouter := prepareGinRouter(auditService, instanceServiceOf("team-1"), 200, nil)
+		req, _ := http.NewRequest("DELETE", "/resources/instance-1", nil)
+		req.SetBasicAuth("tsuru", "some-password")
+		recorder := httptest.NewRecorder()
+
+		// act
+		ginRouter.ServeHTTP(recorder, req)
+
+		// assert
+		Expect(recorder.Header().Get("X-Request-Id")).NotTo(BeEmpty())
+		Expect(auditService.RecordCalls()[0].Entry.RequestId).To(Equal(recorder.Header().Get("X-Request-Id")))
+	})
+})
@@ -0,0 +1,60 @@
+package routers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+const principalKey = "principal"
+
+/*
+	Authenticates the request with the basic auth credentials, telling the principal to the handlers after it.
+	Tsuru only speaks basic auth, so the api tokens go on it too, the name as the user and the secret as the password.
+*/
+func NewAuthMiddleware(authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			c.Header("WWW-Authenticate", `Basic realm="pushaas"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		principal, result := authService.Authenticate(username, password)
+
+		if result == services.AuthenticationInvalidCredentials {
+			c.Header("WWW-Authenticate", `Basic realm="pushaas"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		if result == services.AuthenticationFailure {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Error{
+				Code:    models.ErrorAuthFailed,
+				Message: "Failed to authenticate",
+			})
+			return
+		}
+
+		c.Set(principalKey, principal)
+	}
+}
+
+func NewNoAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(principalKey, &models.Principal{Name: string(models.PrincipalKindAnonymous), Kind: models.PrincipalKindAnonymous, Role: models.RoleAdmin})
+	}
+}
+
+// who the auth middleware told the request comes from
+func PrincipalFromContext(c *gin.Context) *models.Principal {
+	principal, ok := c.Get(principalKey)
+	if !ok {
+		return nil
+	}
+	return principal.(*models.Principal)
+}
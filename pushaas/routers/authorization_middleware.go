@@ -0,0 +1,102 @@
+package routers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+func forbidden(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusForbidden, models.Error{
+		Code:    models.ErrorAuthForbidden,
+		Message: message,
+	})
+}
+
+func isReadRequest(c *gin.Context) bool {
+	return c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+}
+
+// the team the request takes the instances to, or narrows the listing to
+func requestedTeam(c *gin.Context) string {
+	if isReadRequest(c) {
+		return c.Query("team")
+	}
+	if c.Request.Method == http.MethodPost || c.Request.Method == http.MethodPut {
+		return c.PostForm("team")
+	}
+	return ""
+}
+
+/*
+	lets through only the principals with one of the roles, to be used after the auth middleware
+*/
+func NewRoleMiddleware(roles ...models.Role) gin.HandlerFunc {
+	allowed := make(map[models.Role]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *gin.Context) {
+		principal := PrincipalFromContext(c)
+		if principal == nil || !allowed[principal.Role] {
+			forbidden(c, "Not allowed for the role")
+			return
+		}
+	}
+}
+
+/*
+	Guards the instances by the role of the principal, to be used after the auth middleware on the routes of
+	the instances, which are told apart by the name param:
+	- read-only principals can only read;
+	- members can only reach the instances of their team, and create or move instances only to it. An instance
+	not found is let through, so that the route tells so (or is not about an instance at all, like the plans);
+	- the listing is narrowed to the team of members by the route itself.
+*/
+func NewInstanceAccessMiddleware(instanceService services.InstanceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFromContext(c)
+		if principal == nil {
+			forbidden(c, "Not allowed for the role")
+			return
+		}
+
+		if !isReadRequest(c) && !principal.CanWrite() {
+			forbidden(c, "Not allowed for the role")
+			return
+		}
+
+		if principal.Role != models.RoleMember {
+			return
+		}
+
+		if team := requestedTeam(c); team != "" && !principal.CanAccessTeam(team) {
+			forbidden(c, "Not allowed for the team")
+			return
+		}
+
+		name := c.Param("name")
+		if name == "" {
+			return
+		}
+
+		instance, result := instanceService.GetByName(name)
+
+		if result == services.InstanceRetrievalFailure {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Error{
+				Code:    models.ErrorAuthFailed,
+				Message: "Failed to retrieve instance",
+			})
+			return
+		}
+
+		if result == services.InstanceRetrievalSuccess && !principal.CanAccessTeam(instance.Team) {
+			forbidden(c, "Not allowed for the team")
+			return
+		}
+	}
+}
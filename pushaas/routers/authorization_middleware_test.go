@@ -0,0 +1,234 @@
+package routers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/routers"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("Authorization", func() {
+	admin := &models.Principal{Name: "tsuru", Role: models.RoleAdmin}
+	member := &models.Principal{Name: "team-1-ui", Role: models.RoleMember, Team: "team-1"}
+	readOnly := &models.Principal{Name: "monitoring", Role: models.RoleReadOnly}
+
+	authServiceFor := func(principal *models.Principal) services.AuthService {
+		return &mocks.AuthServiceMock{
+			AuthenticateFunc: func(username string, password string) (*models.Principal, services.AuthenticationResult) {
+				return principal, services.AuthenticationSuccess
+			},
+		}
+	}
+
+	instanceServiceWith := func(team string, result services.InstanceRetrievalResult) *mocks.InstanceServiceMock {
+		return &mocks.InstanceServiceMock{
+			GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+				if result != services.InstanceRetrievalSuccess {
+					return nil, result
+				}
+				return &models.Instance{Name: name, Team: team}, result
+			},
+		}
+	}
+
+	serve := func(ginRouter *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+		req.SetBasicAuth("some-user", "some-secret")
+		recorder := httptest.NewRecorder()
+		ginRouter.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	bodyToError := func(recorder *httptest.ResponseRecorder) *models.Error {
+		var body *models.Error
+		_ = json.Unmarshal([]byte(recorder.Body.String()), &body)
+		return body
+	}
+
+	formRequest := func(method, path, team string) *http.Request {
+		form := url.Values{}
+		form.Add("team", team)
+		req, _ := http.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	Describe("role middleware", func() {
+		prepareGinRouter := func(principal *models.Principal) *gin.Engine {
+			ginRouter := gin.New()
+			ginRouter.Use(routers.NewAuthMiddleware(authServiceFor(principal)))
+			ginRouter.Use(routers.NewRoleMiddleware(models.RoleAdmin))
+			ginRouter.GET("/tokens", func(c *gin.Context) { c.Status(http.StatusOK) })
+			return ginRouter
+		}
+
+		It("lets the allowed roles through", func() {
+			// arrange
+			req, _ := http.NewRequest("GET", "/tokens", nil)
+
+			// act
+			recorder := serve(prepareGinRouter(admin), req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+		})
+
+		It("refuses the other roles", func() {
+			for _, principal := range []*models.Principal{member, readOnly} {
+				// arrange
+				req, _ := http.NewRequest("GET", "/tokens", nil)
+
+				// act
+				recorder := serve(prepareGinRouter(principal), req)
+
+				// assert
+				Expect(recorder.Code).To(Equal(403))
+				Expect(bodyToError(recorder).Code).To(Equal(models.ErrorAuthForbidden))
+			}
+		})
+	})
+
+	Describe("instance access middleware", func() {
+		prepareGinRouter := func(principal *models.Principal, instanceService services.InstanceService) *gin.Engine {
+			ginRouter := gin.New()
+			ginRouter.Use(routers.NewAuthMiddleware(authServiceFor(principal)))
+			ginRouter.Use(routers.NewInstanceAccessMiddleware(instanceService))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			ginRouter.POST("/", ok)
+			ginRouter.GET("/:name", ok)
+			ginRouter.PUT("/:name", ok)
+			ginRouter.DELETE("/:name", ok)
+			return ginRouter
+		}
+
+		It("lets admins do anything on any instance", func() {
+			// arrange
+			instanceService := instanceServiceWith("team-2", services.InstanceRetrievalSuccess)
+			req, _ := http.NewRequest("DELETE", "/instance-1", nil)
+
+			// act
+			recorder := serve(prepareGinRouter(admin, instanceService), req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(instanceService.GetByNameCalls()).To(BeEmpty())
+		})
+
+		It("lets read-only principals read any instance", func() {
+			// arrange
+			req, _ := http.NewRequest("GET", "/instance-1", nil)
+
+			// act
+			recorder := serve(prepareGinRouter(readOnly, instanceServiceWith("team-2", services.InstanceRetrievalSuccess)), req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+		})
+
+		It("refuses changes by read-only principals", func() {
+			// arrange
+			req, _ := http.NewRequest("DELETE", "/instance-1", nil)
+
+			// act
+			recorder := serve(prepareGinRouter(readOnly, instanceServiceWith("team-2", services.InstanceRetrievalSuccess)), req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(403))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorAuthForbidden))
+		})
+
+		It("lets members manage the instances of their team", func() {
+			// arrange
+			instanceService := instanceServiceWith("team-1", services.InstanceRetrievalSuccess)
+			req, _ := http.NewRequest("DELETE", "/instance-1", nil)
+
+			// act
+			recorder := serve(prepareGinRouter(member, instanceService), req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+			Expect(instanceService.GetByNameCalls()[0].Name).To(Equal("instance-1"))
+		})
+
+		It("refuses members on the instances of other teams", func() {
+			for _, method := range []string{"GET", "DELETE"} {
+				// arrange
+				req, _ := http.NewRequest(method, "/instance-1", nil)
+
+				// act
+				recorder := serve(prepareGinRouter(member, instanceServiceWith("team-2", services.InstanceRetrievalSuccess)), req)
+
+				// assert
+				Expect(recorder.Code).To(Equal(403))
+				Expect(bodyToError(recorder).Code).To(Equal(models.ErrorAuthForbidden))
+			}
+		})
+
+		It("lets members through when the instance is not found, for the route to tell so", func() {
+			// arrange
+			req, _ := http.NewRequest("GET", "/plans", nil)
+
+			// act
+			recorder := serve(prepareGinRouter(member, instanceServiceWith("", services.InstanceRetrievalNotFound)), req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(200))
+		})
+
+		It("returns 500 when fails to retrieve the instance", func() {
+			// arrange
+			req, _ := http.NewRequest("GET", "/instance-1", nil)
+
+			// act
+			recorder := serve(prepareGinRouter(member, instanceServiceWith("", services.InstanceRetrievalFailure)), req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(500))
+			Expect(bodyToError(recorder).Code).To(Equal(models.ErrorAuthFailed))
+		})
+
+		It("lets members create instances for their team only", func() {
+			// arrange
+			ginRouter := prepareGinRouter(member, instanceServiceWith("", services.InstanceRetrievalNotFound))
+
+			// act
+			own := serve(ginRouter, formRequest("POST", "/", "team-1"))
+			other := serve(ginRouter, formRequest("POST", "/", "team-2"))
+
+			// assert
+			Expect(own.Code).To(Equal(200))
+			Expect(other.Code).To(Equal(403))
+		})
+
+		It("refuses members moving instances to other teams", func() {
+			// arrange
+			ginRouter := prepareGinRouter(member, instanceServiceWith("team-1", services.InstanceRetrievalSuccess))
+
+			// act
+			recorder := serve(ginRouter, formRequest("PUT", "/instance-1", "team-2"))
+
+			// assert
+			Expect(recorder.Code).To(Equal(403))
+		})
+
+		It("refuses members listing the instances of other teams", func() {
+			// arrange
+			req, _ := http.NewRequest("GET", "/instances?team=team-2", nil)
+
+			// act
+			recorder := serve(prepareGinRouter(member, instanceServiceWith("", services.InstanceRetrievalNotFound)), req)
+
+			// assert
+			Expect(recorder.Code).To(Equal(403))
+		})
+	})
+})
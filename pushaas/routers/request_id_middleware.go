@@ -0,0 +1,30 @@
+package routers
+
+import (
+	"github.com/dchest/uniuri"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestIdHeader = "X-Request-Id"
+	requestIdKey    = "requestId"
+)
+
+/*
+	keeps the id the caller gave to the request (tsuru can be set up to), or gives it one, telling it back on the response
+*/
+func NewRequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(requestIdHeader)
+		if requestId == "" {
+			requestId = uniuri.New()
+		}
+
+		c.Set(requestIdKey, requestId)
+		c.Header(requestIdHeader, requestId)
+	}
+}
+
+func RequestIdFromContext(c *gin.Context) string {
+	return c.GetString(requestIdKey)
+}
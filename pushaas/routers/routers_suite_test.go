@@ -0,0 +1,13 @@
+package routers_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRouters(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Routers Suite")
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+type (
+	AuditRetrievalResult int
+
+	/*
+		Keeps the audit entries on a redis stream, which is only ever appended to. The id of the entries is the
+		one given by the stream, which also tells when they were recorded, so that a range of time is a range of ids.
+	*/
+	AuditService interface {
+		Record(entry *models.AuditEntry)
+		Query(filter *models.AuditFilter, limit int) ([]*models.AuditEntry, AuditRetrievalResult)
+	}
+
+	auditService struct {
+		logger      *zap.Logger
+		streamKey   string
+		redisClient redis.UniversalClient
+	}
+)
+
+const (
+	AuditRetrievalSuccess AuditRetrievalResult = iota
+	AuditRetrievalFailure
+)
+
+// how many entries are listed when not told, and at most
+const (
+	auditQueryDefaultLimit = 100
+	auditQueryMaxLimit     = 1000
+)
+
+// how many entries are read from the stream at a time while filling a listing
+const auditReadBatchSize = 100
+
+func streamIdOf(t time.Time, seq uint64) string {
+	return fmt.Sprintf("%d-%d", t.UnixNano()/int64(time.Millisecond), seq)
+}
+
+// the id right before the given one, to go on reading the stream backwards
+func streamIdBefore(id string) string {
+	parts := strings.SplitN(id, "-", 2)
+	millis, _ := strconv.ParseUint(parts[0], 10, 64)
+	seq, _ := strconv.ParseUint(parts[1], 10, 64)
+	if seq > 0 {
+		return fmt.Sprintf("%d-%d", millis, seq-1)
+	}
+	return fmt.Sprintf("%d-%d", millis-1, uint64(math.MaxUint64))
+}
+
+func timeOfStreamId(id string) time.Time {
+	millis, _ := strconv.ParseInt(strings.SplitN(id, "-", 2)[0], 10, 64)
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC()
+}
+
+func decodeAuditEntry(message redis.XMessage) (*models.AuditEntry, error) {
+	value, _ := message.Values["entry"].(string)
+
+	var entry models.AuditEntry
+	err := json.Unmarshal([]byte(value), &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Id = message.ID
+	entry.At = timeOfStreamId(message.ID)
+	return &entry, nil
+}
+
+/*
+	the audit is a side effect of the call, which already happened, so a failure to record it is only logged
+*/
+func (s *auditService) Record(entry *models.AuditEntry) {
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		s.logger.Error("failed to encode audit entry", zap.Any("entry", entry), zap.Error(err))
+		return
+	}
+
+	err = s.redisClient.XAdd(&redis.XAddArgs{
+		Stream: s.streamKey,
+		Values: map[string]interface{}{"entry": string(bytes)},
+	}).Err()
+	if err != nil {
+		s.logger.Error("failed to record audit entry", zap.Any("entry", entry), zap.Error(err))
+	}
+}
+
+/*
+	lists the entries that match the filter, the most recent first. The stream is read backwards in batches, from
+	the end of the range of time of the filter, until the listing is filled or the start of the range is reached.
+*/
+func (s *auditService) Query(filter *models.AuditFilter, limit int) ([]*models.AuditEntry, AuditRetrievalResult) {
+	if limit <= 0 {
+		limit = auditQueryDefaultLimit
+	} else if limit > auditQueryMaxLimit {
+		limit = auditQueryMaxLimit
+	}
+
+	start := "-"
+	if filter.From != nil {
+		start = streamIdOf(*filter.From, 0)
+	}
+	end := "+"
+	if filter.To != nil {
+		end = streamIdOf(*filter.To, math.MaxUint64)
+	}
+
+	entries := []*models.AuditEntry{}
+	for {
+		messages, err := s.redisClient.XRevRangeN(s.streamKey, end, start, auditReadBatchSize).Result()
+		if err != nil {
+			s.logger.Error("failed to read audit entries", zap.Error(err))
+			return nil, AuditRetrievalFailure
+		}
+
+		for _, message := range messages {
+			entry, err := decodeAuditEntry(message)
+			if err != nil {
+				s.logger.Error("failed to decode audit entry", zap.String("id", message.ID), zap.Error(err))
+				return nil, AuditRetrievalFailure
+			}
+			if !filter.Matches(entry) {
+				continue
+			}
+
+			entries = append(entries, entry)
+			if len(entries) == limit {
+				return entries, AuditRetrievalSuccess
+			}
+		}
+
+		if len(messages) < auditReadBatchSize {
+			return entries, AuditRetrievalSuccess
+		}
+		end = streamIdBefore(messages[len(messages)-1].ID)
+	}
+}
+
+func NewAuditService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) AuditService {
+	return &auditService{
+		logger:      logger.Named("auditService"),
+		streamKey:   config.GetString("redis.db.audit.stream"),
+		redisClient: redisClient,
+	}
+}
@@ -0,0 +1,155 @@
+package services_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("AuditService", func() {
+	var (
+		server      *miniredis.Miniredis
+		redisClient *redis.Client
+		service     services.AuditService
+	)
+
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// records an entry at each minute after start
+	record := func(entries ...*models.AuditEntry) {
+		for i, entry := range entries {
+			server.SetTime(start.Add(time.Duration(i) * time.Minute))
+			service.Record(entry)
+		}
+	}
+
+	instances := func(entries []*models.AuditEntry) []string {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Instance)
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		var err error
+		server, err = miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		redisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+		config := viper.New()
+		config.Set("redis.db.audit.stream", "audit")
+		service = services.NewAuditService(config, logger, redisClient)
+	})
+
+	AfterEach(func() {
+		_ = redisClient.Close()
+		server.Close()
+	})
+
+	Describe("Query", func() {
+		It("lists the entries recorded, the most recent first", func() {
+			// arrange
+			principal := &models.Principal{Name: "tsuru", Kind: models.PrincipalKindTsuru, Role: models.RoleAdmin}
+			record(
+				&models.AuditEntry{RequestId: "request-1", Principal: principal, Action: models.AuditActionCreateInstance, Instance: "instance-1", Team: "team-1", Status: 201},
+				&models.AuditEntry{RequestId: "request-2", Principal: principal, Action: models.AuditActionBindApp, Instance: "instance-1", Team: "team-1", App: "app-1", Status: 409, ErrorCode: 101},
+			)
+
+			// act
+			entries, result := service.Query(&models.AuditFilter{}, 0)
+
+			// assert
+			Expect(result).To(Equal(services.AuditRetrievalSuccess))
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].Id).NotTo(BeEmpty())
+			Expect(entries[0].At).To(Equal(start.Add(time.Minute)))
+			Expect(entries[0].RequestId).To(Equal("request-2"))
+			Expect(entries[0].Principal).To(Equal(principal))
+			Expect(entries[0].Action).To(Equal(models.AuditActionBindApp))
+			Expect(entries[0].App).To(Equal("app-1"))
+			Expect(entries[0].Status).To(Equal(409))
+			Expect(entries[0].ErrorCode).To(Equal(101))
+			Expect(entries[1].RequestId).To(Equal("request-1"))
+			Expect(entries[1].At).To(Equal(start))
+		})
+
+		It("narrows the entries by instance and team", func() {
+			// arrange
+			record(
+				&models.AuditEntry{Instance: "instance-1", Team: "team-1"},
+				&models.AuditEntry{Instance: "instance-2", Team: "team-1"},
+				&models.AuditEntry{Instance: "instance-3", Team: "team-2"},
+			)
+
+			// act
+			byTeam, _ := service.Query(&models.AuditFilter{Team: "team-1"}, 0)
+			byInstance, _ := service.Query(&models.AuditFilter{Instance: "instance-3"}, 0)
+
+			// assert
+			Expect(instances(byTeam)).To(Equal([]string{"instance-2", "instance-1"}))
+			Expect(instances(byInstance)).To(Equal([]string{"instance-3"}))
+		})
+
+		It("narrows the entries by time, inclusively", func() {
+			// arrange
+			record(
+				&models.AuditEntry{Instance: "instance-1"},
+				&models.AuditEntry{Instance: "instance-2"},
+				&models.AuditEntry{Instance: "instance-3"},
+				&models.AuditEntry{Instance: "instance-4"},
+			)
+			from := start.Add(time.Minute)
+			to := start.Add(2 * time.Minute)
+
+			// act
+			entries, result := service.Query(&models.AuditFilter{From: &from, To: &to}, 0)
+
+			// assert
+			Expect(result).To(Equal(services.AuditRetrievalSuccess))
+			Expect(instances(entries)).To(Equal([]string{"instance-3", "instance-2"}))
+		})
+
+		It("reads the stream until the listing is filled", func() {
+			// arrange
+			var entries []*models.AuditEntry
+			for i := 0; i < 250; i++ {
+				team := "team-1"
+				if i%10 != 0 {
+					team = "team-2"
+				}
+				entries = append(entries, &models.AuditEntry{Instance: fmt.Sprintf("instance-%d", i), Team: team})
+			}
+			record(entries...)
+
+			// act
+			listed, result := service.Query(&models.AuditFilter{Team: "team-1"}, 20)
+
+			// assert
+			Expect(result).To(Equal(services.AuditRetrievalSuccess))
+			Expect(listed).To(HaveLen(20))
+			Expect(listed[0].Instance).To(Equal("instance-240"))
+			Expect(listed[19].Instance).To(Equal("instance-50"))
+		})
+
+		It("indicates when fails to read the entries", func() {
+			// arrange
+			server.Close()
+
+			// act
+			entries, result := service.Query(&models.AuditFilter{}, 0)
+
+			// assert
+			Expect(result).To(Equal(services.AuditRetrievalFailure))
+			Expect(entries).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/go-redis/redis"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+type (
+	AuthenticationResult  int
+	TokenRetrievalResult  int
+	TokenCreationResult   int
+	TokenRevocationResult int
+
+	/*
+		Tells who is behind the credentials of a request: tsuru, with the credential from the config, or the holder
+		of one of the api tokens, which are kept on redis with the hash of their secrets, all on a single hash.
+		Tsuru is an admin, the holders of the tokens get the role of their token.
+	*/
+	AuthService interface {
+		Authenticate(username, password string) (*models.Principal, AuthenticationResult)
+		GetTokens() ([]*models.ApiToken, TokenRetrievalResult)
+		CreateToken(tokenForm *models.ApiTokenForm, createdBy *models.Principal) (*models.CreatedApiToken, TokenCreationResult)
+		RevokeToken(name string) TokenRevocationResult
+	}
+
+	authService struct {
+		logger        *zap.Logger
+		tsuruUser     string
+		tsuruPassword string
+		tokensKey     string
+		redisClient   redis.UniversalClient
+	}
+
+	apiTokenRecord struct {
+		*models.ApiToken
+		SecretHash string `json:"secretHash"`
+	}
+)
+
+const (
+	AuthenticationSuccess AuthenticationResult = iota
+	AuthenticationInvalidCredentials
+	AuthenticationFailure
+)
+
+const (
+	TokenRetrievalSuccess TokenRetrievalResult = iota
+	TokenRetrievalFailure
+)
+
+const (
+	TokenCreationSuccess TokenCreationResult = iota
+	TokenCreationAlreadyExist
+	TokenCreationInvalidData
+	TokenCreationFailure
+)
+
+const (
+	TokenRevocationSuccess TokenRevocationResult = iota
+	TokenRevocationNotFound
+	TokenRevocationFailure
+)
+
+const apiTokenSecretLength = 40
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func secretsMatch(given, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(expected)) == 1
+}
+
+func (s *authService) Authenticate(username, password string) (*models.Principal, AuthenticationResult) {
+	if username == s.tsuruUser {
+		if !secretsMatch(password, s.tsuruPassword) {
+			return nil, AuthenticationInvalidCredentials
+		}
+		return &models.Principal{Name: username, Kind: models.PrincipalKindTsuru, Role: models.RoleAdmin}, AuthenticationSuccess
+	}
+
+	value, err := s.redisClient.HGet(s.tokensKey, username).Result()
+	if err == redis.Nil {
+		return nil, AuthenticationInvalidCredentials
+	} else if err != nil {
+		s.logger.Error("failed to retrieve token to authenticate", zap.String("name", username), zap.Error(err))
+		return nil, AuthenticationFailure
+	}
+
+	var record apiTokenRecord
+	err = json.Unmarshal([]byte(value), &record)
+	if err != nil {
+		s.logger.Error("failed to decode token", zap.String("name", username), zap.Error(err))
+		return nil, AuthenticationFailure
+	}
+
+	if !secretsMatch(hashSecret(password), record.SecretHash) {
+		return nil, AuthenticationInvalidCredentials
+	}
+
+	// the tokens created before there were roles are given the least of them
+	role := record.Role
+	if role == "" {
+		role = models.RoleReadOnly
+	}
+	return &models.Principal{Name: username, Kind: models.PrincipalKindToken, Role: role, Team: record.Team}, AuthenticationSuccess
+}
+
+func (s *authService) GetTokens() ([]*models.ApiToken, TokenRetrievalResult) {
+	values, err := s.redisClient.HGetAll(s.tokensKey).Result()
+	if err != nil {
+		s.logger.Error("failed to retrieve tokens", zap.Error(err))
+		return nil, TokenRetrievalFailure
+	}
+
+	tokens := make([]*models.ApiToken, 0, len(values))
+	for name, value := range values {
+		var record apiTokenRecord
+		err := json.Unmarshal([]byte(value), &record)
+		if err != nil {
+			s.logger.Error("failed to decode token", zap.String("name", name), zap.Error(err))
+			return nil, TokenRetrievalFailure
+		}
+		tokens = append(tokens, record.ApiToken)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].Name < tokens[j].Name
+	})
+	return tokens, TokenRetrievalSuccess
+}
+
+/*
+	the token is written only if the name is free, so only one of concurrent creations of the same name succeeds
+*/
+func (s *authService) CreateToken(tokenForm *models.ApiTokenForm, createdBy *models.Principal) (*models.CreatedApiToken, TokenCreationResult) {
+	if tokenForm.Validate() != models.ApiTokenFormValid {
+		return nil, TokenCreationInvalidData
+	}
+	// the team only narrows what members see
+	team := tokenForm.Team
+	if tokenForm.Role != models.RoleMember {
+		team = ""
+	}
+	if tokenForm.Name == s.tsuruUser {
+		return nil, TokenCreationAlreadyExist
+	}
+
+	token := &models.ApiToken{
+		Name:        tokenForm.Name,
+		Description: tokenForm.Description,
+		Role:        tokenForm.Role,
+		Team:        team,
+		CreatedBy:   createdBy.Name,
+		CreatedAt:   time.Now().UTC(),
+	}
+	secret := uniuri.NewLen(apiTokenSecretLength)
+
+	bytes, err := json.Marshal(&apiTokenRecord{ApiToken: token, SecretHash: hashSecret(secret)})
+	if err != nil {
+		s.logger.Error("failed to encode token", zap.String("name", token.Name), zap.Error(err))
+		return nil, TokenCreationFailure
+	}
+
+	created, err := s.redisClient.HSetNX(s.tokensKey, token.Name, string(bytes)).Result()
+	if err != nil {
+		s.logger.Error("failed to create token", zap.String("name", token.Name), zap.Error(err))
+		return nil, TokenCreationFailure
+	}
+	if !created {
+		return nil, TokenCreationAlreadyExist
+	}
+
+	s.logger.Info("token created", zap.String("name", token.Name), zap.String("createdBy", token.CreatedBy))
+	return &models.CreatedApiToken{ApiToken: token, Secret: secret}, TokenCreationSuccess
+}
+
+func (s *authService) RevokeToken(name string) TokenRevocationResult {
+	deleted, err := s.redisClient.HDel(s.tokensKey, name).Result()
+	if err != nil {
+		s.logger.Error("failed to revoke token", zap.String("name", name), zap.Error(err))
+		return TokenRevocationFailure
+	}
+	if deleted == 0 {
+		return TokenRevocationNotFound
+	}
+
+	s.logger.Info("token revoked", zap.String("name", name))
+	return TokenRevocationSuccess
+}
+
+func NewAuthService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) AuthService {
+	return &authService{
+		logger:        logger.Named("authService"),
+		tsuruUser:     config.GetString("api.basic_auth_user"),
+		tsuruPassword: config.GetString("api.basic_auth_password"),
+		tokensKey:     config.GetString("redis.db.api_token.key"),
+		redisClient:   redisClient,
+	}
+}
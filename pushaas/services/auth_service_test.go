@@ -0,0 +1,243 @@
+package services_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("AuthService", func() {
+	var (
+		server      *miniredis.Miniredis
+		redisClient *redis.Client
+		service     services.AuthService
+	)
+
+	admin := &models.Principal{Name: "tsuru", Kind: models.PrincipalKindTsuru, Role: models.RoleAdmin}
+
+	hashOf := func(secret string) string {
+		sum := sha256.Sum256([]byte(secret))
+		return hex.EncodeToString(sum[:])
+	}
+
+	BeforeEach(func() {
+		var err error
+		server, err = miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		redisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+		config := viper.New()
+		config.Set("api.basic_auth_user", "tsuru")
+		config.Set("api.basic_auth_password", "tsuru-password")
+		config.Set("redis.db.api_token.key", "api-tokens")
+		service = services.NewAuthService(config, logger, redisClient)
+	})
+
+	AfterEach(func() {
+		_ = redisClient.Close()
+		server.Close()
+	})
+
+	Describe("Authenticate", func() {
+		It("authenticates tsuru with the credential from the config", func() {
+			// act
+			principal, result := service.Authenticate("tsuru", "tsuru-password")
+
+			// assert
+			Expect(result).To(Equal(services.AuthenticationSuccess))
+			Expect(principal).To(Equal(&models.Principal{Name: "tsuru", Kind: models.PrincipalKindTsuru, Role: models.RoleAdmin}))
+		})
+
+		It("refuses tsuru with another password", func() {
+			// act
+			principal, result := service.Authenticate("tsuru", "other-password")
+
+			// assert
+			Expect(result).To(Equal(services.AuthenticationInvalidCredentials))
+			Expect(principal).To(BeNil())
+		})
+
+		It("authenticates the holder of a token with its secret, giving the role of the token", func() {
+			// arrange
+			token, _ := service.CreateToken(&models.ApiTokenForm{Name: "team-1-ui", Role: models.RoleMember, Team: "team-1"}, admin)
+
+			// act
+			principal, result := service.Authenticate("team-1-ui", token.Secret)
+
+			// assert
+			Expect(result).To(Equal(services.AuthenticationSuccess))
+			Expect(principal).To(Equal(&models.Principal{Name: "team-1-ui", Kind: models.PrincipalKindToken, Role: models.RoleMember, Team: "team-1"}))
+		})
+
+		It("gives the tokens created before there were roles the read-only role", func() {
+			// arrange
+			server.HSet("api-tokens", "automation", `{"name":"automation","secretHash":"`+hashOf("some-secret")+`"}`)
+
+			// act
+			principal, result := service.Authenticate("automation", "some-secret")
+
+			// assert
+			Expect(result).To(Equal(services.AuthenticationSuccess))
+			Expect(principal.Role).To(Equal(models.RoleReadOnly))
+		})
+
+		It("refuses a token with another secret", func() {
+			// arrange
+			_, _ = service.CreateToken(&models.ApiTokenForm{Name: "automation", Role: models.RoleAdmin}, admin)
+
+			// act
+			principal, result := service.Authenticate("automation", "other-secret")
+
+			// assert
+			Expect(result).To(Equal(services.AuthenticationInvalidCredentials))
+			Expect(principal).To(BeNil())
+		})
+
+		It("refuses a token that does not exist", func() {
+			// act
+			principal, result := service.Authenticate("automation", "some-secret")
+
+			// assert
+			Expect(result).To(Equal(services.AuthenticationInvalidCredentials))
+			Expect(principal).To(BeNil())
+		})
+
+		It("indicates when fails to retrieve the token", func() {
+			// arrange
+			server.Close()
+
+			// act
+			principal, result := service.Authenticate("automation", "some-secret")
+
+			// assert
+			Expect(result).To(Equal(services.AuthenticationFailure))
+			Expect(principal).To(BeNil())
+		})
+	})
+
+	Describe("CreateToken", func() {
+		It("creates the token keeping only the hash of its secret", func() {
+			// act
+			token, result := service.CreateToken(&models.ApiTokenForm{Name: "admin-ui", Description: "the admin UI", Role: models.RoleAdmin}, admin)
+
+			// assert
+			Expect(result).To(Equal(services.TokenCreationSuccess))
+			Expect(token.Name).To(Equal("admin-ui"))
+			Expect(token.Description).To(Equal("the admin UI"))
+			Expect(token.Role).To(Equal(models.RoleAdmin))
+			Expect(token.CreatedBy).To(Equal("tsuru"))
+			Expect(token.CreatedAt).NotTo(BeZero())
+			Expect(token.Secret).To(HaveLen(40))
+			Expect(server.HGet("api-tokens", "admin-ui")).NotTo(ContainSubstring(token.Secret))
+		})
+
+		It("indicates when the token already exists", func() {
+			// arrange
+			_, _ = service.CreateToken(&models.ApiTokenForm{Name: "admin-ui", Role: models.RoleAdmin}, admin)
+
+			// act
+			token, result := service.CreateToken(&models.ApiTokenForm{Name: "admin-ui", Role: models.RoleAdmin}, admin)
+
+			// assert
+			Expect(result).To(Equal(services.TokenCreationAlreadyExist))
+			Expect(token).To(BeNil())
+		})
+
+		It("refuses the name of the tsuru credential", func() {
+			// act
+			token, result := service.CreateToken(&models.ApiTokenForm{Name: "tsuru", Role: models.RoleAdmin}, admin)
+
+			// assert
+			Expect(result).To(Equal(services.TokenCreationAlreadyExist))
+			Expect(token).To(BeNil())
+		})
+
+		It("keeps the team only for members", func() {
+			// act
+			token, result := service.CreateToken(&models.ApiTokenForm{Name: "monitoring", Role: models.RoleReadOnly, Team: "team-1"}, admin)
+
+			// assert
+			Expect(result).To(Equal(services.TokenCreationSuccess))
+			Expect(token.Team).To(BeEmpty())
+		})
+
+		It("refuses invalid data", func() {
+			forms := []*models.ApiTokenForm{
+				{Name: "", Role: models.RoleAdmin},
+				{Name: "with:colon", Role: models.RoleAdmin},
+				{Name: "with space", Role: models.RoleAdmin},
+				{Name: "no-role"},
+				{Name: "unknown-role", Role: "owner"},
+				{Name: "member-without-team", Role: models.RoleMember},
+			}
+			for _, form := range forms {
+				// act
+				token, result := service.CreateToken(form, admin)
+
+				// assert
+				Expect(result).To(Equal(services.TokenCreationInvalidData), form.Name)
+				Expect(token).To(BeNil())
+			}
+		})
+	})
+
+	Describe("GetTokens", func() {
+		It("retrieves the tokens ordered by name, without their secrets", func() {
+			// arrange
+			_, _ = service.CreateToken(&models.ApiTokenForm{Name: "automation", Role: models.RoleAdmin}, admin)
+			_, _ = service.CreateToken(&models.ApiTokenForm{Name: "admin-ui", Role: models.RoleAdmin}, admin)
+
+			// act
+			tokens, result := service.GetTokens()
+
+			// assert
+			Expect(result).To(Equal(services.TokenRetrievalSuccess))
+			Expect(tokens).To(HaveLen(2))
+			Expect(tokens[0].Name).To(Equal("admin-ui"))
+			Expect(tokens[1].Name).To(Equal("automation"))
+		})
+
+		It("indicates when fails to retrieve the tokens", func() {
+			// arrange
+			server.Close()
+
+			// act
+			tokens, result := service.GetTokens()
+
+			// assert
+			Expect(result).To(Equal(services.TokenRetrievalFailure))
+			Expect(tokens).To(BeNil())
+		})
+	})
+
+	Describe("RevokeToken", func() {
+		It("revokes the token, which does not authenticate anymore", func() {
+			// arrange
+			token, _ := service.CreateToken(&models.ApiTokenForm{Name: "automation", Role: models.RoleAdmin}, admin)
+
+			// act
+			result := service.RevokeToken("automation")
+
+			// assert
+			Expect(result).To(Equal(services.TokenRevocationSuccess))
+			_, resultAuth := service.Authenticate("automation", token.Secret)
+			Expect(resultAuth).To(Equal(services.AuthenticationInvalidCredentials))
+		})
+
+		It("indicates when the token is not found", func() {
+			// act
+			result := service.RevokeToken("automation")
+
+			// assert
+			Expect(result).To(Equal(services.TokenRevocationNotFound))
+		})
+	})
+})
@@ -3,13 +3,13 @@ package services
 import (
 	"fmt"
 
-	"github.com/fatih/structs"
-	"github.com/go-redis/redis"
-	"github.com/mitchellh/mapstructure"
+	"github.com/dchest/uniuri"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
 	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/repositories"
 )
 
 type (
@@ -17,22 +17,28 @@ type (
 	BindAppResult          int
 	UnbindAppResult        int
 
-	BindUnitResult int
+	BindUnitResult   int
 	UnbindUnitResult int
 
+	RotateAppCredentialsResult int
+
 	BindService interface {
 		BindApp(name string, bindAppForm *models.BindAppForm) (map[string]string, BindAppResult)
 		UnbindApp(name string, bindAppForm *models.BindAppForm) UnbindAppResult
 		BindUnit(name string, bindUnitForm *models.BindUnitForm) (map[string]string, BindUnitResult)
 		UnbindUnit(name string, bindUnitForm *models.BindUnitForm) UnbindUnitResult
+		RotateAppCredentials(name string, instanceVars map[string]string) (map[string]map[string]string, RotateAppCredentialsResult)
+		GetBindings(name string) ([]*models.Binding, BindAppRetrievalResult)
+		GetBinding(name, appName string) (*models.Binding, BindAppRetrievalResult)
+		GetAppBindings(appName string) ([]string, BindAppRetrievalResult)
 	}
 
 	bindService struct {
-		bindAppPrefix   string
-		bindUnitPrefix  string
+		bindRepository  repositories.BindRepository
+		eventService    EventService
 		instanceService InstanceService
 		logger          *zap.Logger
-		redisClient     redis.UniversalClient
+		pushApiService  PushApiService
 	}
 )
 
@@ -73,46 +79,47 @@ const (
 	UnbindUnitFailure
 )
 
-func (s *bindService) bindAppKey(instanceName, appName string) string {
-	return fmt.Sprintf("%s:%s:%s", s.bindAppPrefix, instanceName, appName)
+const (
+	RotateAppCredentialsSuccess RotateAppCredentialsResult = iota
+	RotateAppCredentialsFailure
+)
+
+// prefixed so that it never clashes with the instance-wide username
+func appUsername(appName string) string {
+	return fmt.Sprintf("app-%s", appName)
 }
 
-func (s *bindService) bindUnitKey(instanceName, appName string) string {
-	return fmt.Sprintf("%s:%s:%s", s.bindUnitPrefix, instanceName, appName)
+/*
+	the apps get the instance vars with their own credential instead of the instance-wide one
+*/
+func appEnvVars(instanceVars map[string]string, bindApp *models.BindApp) map[string]string {
+	envVars := make(map[string]string, len(instanceVars))
+	for k, v := range instanceVars {
+		envVars[k] = v
+	}
+	envVars[provisioners.EnvVarUsername] = bindApp.Username
+	envVars[provisioners.EnvVarPassword] = bindApp.Password
+	return envVars
 }
 
 func (s *bindService) getBindApp(instanceName, appName string) (*models.BindApp, BindAppRetrievalResult) {
-	var err error
-	bindAppKey := s.bindAppKey(instanceName, appName)
-
-	// retrieve
-	cmd := s.redisClient.HGetAll(bindAppKey)
-	bindAppMap, err := cmd.Result()
-	if err != nil {
-		s.logger.Error("failed to retrieve bindApp", zap.Error(err), zap.String("bindAppKey", bindAppKey))
-		return nil, BindAppRetrievalFailure
-	}
-	if len(bindAppMap) == 0 {
+	bindApp, err := s.bindRepository.GetBindApp(instanceName, appName)
+	if err == repositories.ErrNotFound {
 		return nil, BindAppRetrievalNotFound
-	}
-
-	// decode
-	var bindApp models.BindApp
-	err = mapstructure.Decode(bindAppMap, &bindApp)
-	if err != nil {
-		s.logger.Error("failed to decode bindApp", zap.Error(err), zap.String("instance", instanceName))
+	} else if err != nil {
+		s.logger.Error("failed to retrieve bindApp", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.Error(err))
 		return nil, BindAppRetrievalFailure
 	}
 
-	return &bindApp, BindAppRetrievalSuccess
+	return bindApp, BindAppRetrievalSuccess
 }
 
 func (s *bindService) doBindApp(instance *models.Instance, bindApp *models.BindApp) BindAppResult {
-	bindAppKey := s.bindAppKey(instance.Name, bindApp.AppName)
-	bindAppMap := structs.Map(bindApp)
-
-	err := s.redisClient.HMSet(bindAppKey, bindAppMap).Err()
-	if err != nil {
+	err := s.bindRepository.CreateBindApp(instance.Name, bindApp)
+	if err == repositories.ErrAlreadyExists {
+		s.logger.Error("instance already bound to app", zap.String("instanceName", instance.Name), zap.String("appName", bindApp.AppName))
+		return BindAppAlreadyBound
+	} else if err != nil {
 		s.logger.Error("failed to create bindApp", zap.Error(err), zap.Any("instance", instance), zap.Any("bindApp", bindApp))
 		return BindAppFailure
 	}
@@ -132,6 +139,9 @@ func (s *bindService) BindApp(instanceName string, bindAppForm *models.BindAppFo
 		return nil, BindAppInstancePending
 	} else if instance.Status == models.InstanceStatusFailed {
 		return nil, BindAppInstanceFailed
+	} else if instance.Status == models.InstanceStatusDeprovisioning {
+		s.logger.Error("instance being deprovisioned for bindApp", zap.String("instanceName", instanceName), zap.Any("bindAppForm", bindAppForm))
+		return nil, BindAppNotFound
 	}
 
 	// check binding existence
@@ -143,37 +153,50 @@ func (s *bindService) BindApp(instanceName string, bindAppForm *models.BindAppFo
 		return nil, BindAppFailure
 	}
 
+	// get instance variables
+	var instanceVars map[string]string
+	instanceVars, err := s.instanceService.GetInstanceVars(instance.Name)
+	if err != nil {
+		s.logger.Error("could not retrieve env vars for instance", zap.String("instanceName", instanceName), zap.Any("bindAppForm", bindAppForm), zap.Error(err))
+		return nil, BindAppFailure
+	}
+
 	bindApp := models.BindAppFromForm(bindAppForm)
+	bindApp.Username = appUsername(bindApp.AppName)
+	bindApp.Password = uniuri.New()
 
-	// bind
+	// bind, before issuing the credential, so that when the same app is bound concurrently only the one that
+	// creates the binding touches the credential
 	resultBind := s.doBindApp(instance, bindApp)
 	if resultBind != BindAppSuccess {
 		return nil, resultBind
 	}
 
-	// get instance variables
-	var envVars map[string]string
-	envVars, err := s.instanceService.GetInstanceVars(instance.Name)
+	// issue credential
+	err = s.pushApiService.AddCredential(instanceVars, bindApp.Username, bindApp.Password)
 	if err != nil {
-		s.logger.Error("could not retrieve env vars for instance", zap.String("instanceName", instanceName), zap.Any("bindAppForm", bindAppForm), zap.Error(err))
+		s.logger.Error("could not issue credential for app", zap.String("instanceName", instanceName), zap.Any("bindAppForm", bindAppForm), zap.Error(err))
+		// the binding would be left behind without a credential
+		_ = s.bindRepository.DelBindApp(instance.Name, bindApp.AppName)
 		return nil, BindAppFailure
 	}
 
-	return envVars, BindAppSuccess
+	s.eventService.Publish(&models.InstanceEvent{
+		Instance: instance.Name,
+		Type:     models.InstanceEventAppBound,
+		App:      bindApp.AppName,
+	})
+	return appEnvVars(instanceVars, bindApp), BindAppSuccess
 }
 
 func (s *bindService) doUnbindApp(instance *models.Instance, bindApp *models.BindApp) UnbindAppResult {
-	bindAppKey := s.bindAppKey(instance.Name, bindApp.AppName)
-
-	value, err := s.redisClient.Del(bindAppKey).Result()
-	if err != nil {
-		s.logger.Error("failed to delete bindApp", zap.Error(err), zap.Any("instance", instance), zap.Any("bindApp", bindApp))
-		return UnbindAppFailure
-	}
-
-	if value == 0 {
+	err := s.bindRepository.DelBindApp(instance.Name, bindApp.AppName)
+	if err == repositories.ErrNotFound {
 		s.logger.Error("bindApp not found to be deleted", zap.String("name", instance.Name))
 		return UnbindAppNotBound
+	} else if err != nil {
+		s.logger.Error("failed to delete bindApp", zap.Error(err), zap.Any("instance", instance), zap.Any("bindApp", bindApp))
+		return UnbindAppFailure
 	}
 
 	return UnbindAppSuccess
@@ -188,7 +211,7 @@ func (s *bindService) UnbindApp(instanceName string, bindAppForm *models.BindApp
 	}
 
 	// check binding existence
-	_, resultBindAppGet := s.getBindApp(instance.Name, bindAppForm.AppName)
+	bindApp, resultBindAppGet := s.getBindApp(instance.Name, bindAppForm.AppName)
 	if resultBindAppGet == BindAppRetrievalNotFound {
 		s.logger.Error("instance not bound to app", zap.String("instanceName", instanceName), zap.Any("bindAppForm", bindAppForm))
 		return UnbindAppNotBound
@@ -196,24 +219,42 @@ func (s *bindService) UnbindApp(instanceName string, bindAppForm *models.BindApp
 		return UnbindAppFailure
 	}
 
-	bindApp := models.BindAppFromForm(bindAppForm)
+	// revoke credential, keeping the binding when it fails so that the unbind can be retried
+	if bindApp.Username != "" {
+		instanceVars, err := s.instanceService.GetInstanceVars(instance.Name)
+		if err != nil {
+			s.logger.Error("could not retrieve env vars for instance", zap.String("instanceName", instanceName), zap.Any("bindAppForm", bindAppForm), zap.Error(err))
+			return UnbindAppFailure
+		}
+
+		err = s.pushApiService.RevokeCredential(instanceVars, bindApp.Username)
+		if err != nil {
+			s.logger.Error("could not revoke credential of app", zap.String("instanceName", instanceName), zap.Any("bindAppForm", bindAppForm), zap.Error(err))
+			return UnbindAppFailure
+		}
+	}
 
 	// unbind
-	return s.doUnbindApp(instance, bindApp)
+	result := s.doUnbindApp(instance, bindApp)
+	if result == UnbindAppSuccess {
+		s.eventService.Publish(&models.InstanceEvent{
+			Instance: instance.Name,
+			Type:     models.InstanceEventAppUnbound,
+			App:      bindApp.AppName,
+		})
+	}
+	return result
 }
 
 func (s *bindService) doBindUnit(instanceName string, bindUnitForm *models.BindUnitForm) BindUnitResult {
-	bindUnitKey := s.bindUnitKey(instanceName, bindUnitForm.AppName)
-	unitHost := bindUnitForm.UnitHost
-
-	added, err := s.redisClient.SAdd(bindUnitKey, unitHost).Result()
-	if err != nil {
-		s.logger.Error("failed to create bindUnit", zap.Error(err), zap.Any("instanceName", instanceName), zap.Any("bindUnitForm", bindUnitForm))
-		return BindUnitFailure
-	} else if added == 0 {
+	err := s.bindRepository.AddBindUnit(instanceName, bindUnitForm.AppName, bindUnitForm.UnitHost)
+	if err == repositories.ErrAlreadyExists {
 		//return BindUnitAlreadyBound
 		// TODO returning this because was having trouble
 		return BindUnitSuccess
+	} else if err != nil {
+		s.logger.Error("failed to create bindUnit", zap.Error(err), zap.Any("instanceName", instanceName), zap.Any("bindUnitForm", bindUnitForm))
+		return BindUnitFailure
 	}
 	return BindUnitSuccess
 }
@@ -227,7 +268,7 @@ func (s *bindService) BindUnit(instanceName string, bindUnitForm *models.BindUni
 	}
 
 	// check binding existence
-	_, resultBindAppGet := s.getBindApp(instanceName, bindUnitForm.AppName)
+	bindApp, resultBindAppGet := s.getBindApp(instanceName, bindUnitForm.AppName)
 	if resultBindAppGet == BindAppRetrievalNotFound {
 		s.logger.Error("instance not bound to app", zap.String("instanceName", instanceName), zap.Any("bindUnitForm", bindUnitForm))
 		return nil, BindUnitAppNotBound
@@ -243,20 +284,22 @@ func (s *bindService) BindUnit(instanceName string, bindUnitForm *models.BindUni
 		return nil, BindUnitFailure
 	}
 
+	// the units get the credential of their app, apps bound before the credentials were per app keep the instance-wide one
+	if bindApp.Username != "" {
+		envVars = appEnvVars(envVars, bindApp)
+	}
+
 	// bind
 	return envVars, s.doBindUnit(instanceName, bindUnitForm)
 }
 
 func (s *bindService) doUnbindUnit(instanceName string, bindUnitForm *models.BindUnitForm) UnbindUnitResult {
-	bindUnitKey := s.bindUnitKey(instanceName, bindUnitForm.AppName)
-	unitHost := bindUnitForm.UnitHost
-
-	removed, err := s.redisClient.SRem(bindUnitKey, unitHost).Result()
-	if err != nil {
+	err := s.bindRepository.RemoveBindUnit(instanceName, bindUnitForm.AppName, bindUnitForm.UnitHost)
+	if err == repositories.ErrNotFound {
+		return UnbindUnitNotBound
+	} else if err != nil {
 		s.logger.Error("failed to remove bindUnit", zap.Error(err), zap.Any("instanceName", instanceName), zap.Any("bindUnitForm", bindUnitForm))
 		return UnbindUnitFailure
-	} else if removed == 0 {
-		return UnbindUnitNotBound
 	}
 	return UnbindUnitSuccess
 }
@@ -275,15 +318,110 @@ func (s *bindService) UnbindUnit(instanceName string, bindUnitForm *models.BindU
 	return s.doUnbindUnit(instanceName, bindUnitForm)
 }
 
-func NewBindService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient, instanceService InstanceService) BindService {
-	bindAppPrefix := config.GetString("redis.db.bind_app.prefix")
-	bindUnitPrefix := config.GetString("redis.db.bind_unit.prefix")
+/*
+	Issues a new credential to every app bound to the instance, on the push-api given by instanceVars, and returns
+	the env vars of each app by its name. The apps bound before the credentials were per app get their first one.
+	It keeps going when an app fails, so that as many apps as possible are rotated.
+*/
+func (s *bindService) RotateAppCredentials(instanceName string, instanceVars map[string]string) (map[string]map[string]string, RotateAppCredentialsResult) {
+	bindApps, err := s.bindRepository.GetBindApps(instanceName)
+	if err != nil {
+		s.logger.Error("failed to retrieve bindApps", zap.String("instanceName", instanceName), zap.Error(err))
+		return nil, RotateAppCredentialsFailure
+	}
+
+	result := RotateAppCredentialsSuccess
+	envVarsByApp := make(map[string]map[string]string, len(bindApps))
+	for _, bindApp := range bindApps {
+		bindApp.Username = appUsername(bindApp.AppName)
+		bindApp.Password = uniuri.New()
+
+		err = s.pushApiService.AddCredential(instanceVars, bindApp.Username, bindApp.Password)
+		if err != nil {
+			s.logger.Error("could not issue new credential for app", zap.String("instanceName", instanceName), zap.String("appName", bindApp.AppName), zap.Error(err))
+			result = RotateAppCredentialsFailure
+			continue
+		}
+
+		err = s.bindRepository.SaveBindApp(instanceName, bindApp)
+		if err != nil {
+			s.logger.Error("failed to save new credential for app", zap.String("instanceName", instanceName), zap.String("appName", bindApp.AppName), zap.Error(err))
+			result = RotateAppCredentialsFailure
+			continue
+		}
+
+		envVarsByApp[bindApp.AppName] = appEnvVars(instanceVars, bindApp)
+	}
+
+	return envVarsByApp, result
+}
+
+func (s *bindService) bindingOf(instanceName string, bindApp *models.BindApp) (*models.Binding, BindAppRetrievalResult) {
+	unitHosts, err := s.bindRepository.GetBindUnits(instanceName, bindApp.AppName)
+	if err != nil {
+		s.logger.Error("failed to retrieve bindUnits", zap.String("instanceName", instanceName), zap.String("appName", bindApp.AppName), zap.Error(err))
+		return nil, BindAppRetrievalFailure
+	}
+
+	return &models.Binding{
+		AppName:   bindApp.AppName,
+		AppHost:   bindApp.AppHost,
+		UnitHosts: unitHosts,
+	}, BindAppRetrievalSuccess
+}
+
+func (s *bindService) GetBindings(instanceName string) ([]*models.Binding, BindAppRetrievalResult) {
+	// check instance existence
+	_, resultInstanceGet := s.instanceService.GetByName(instanceName)
+	if resultInstanceGet == InstanceRetrievalNotFound {
+		return nil, BindAppRetrievalNotFound
+	} else if resultInstanceGet == InstanceRetrievalFailure {
+		return nil, BindAppRetrievalFailure
+	}
+
+	bindApps, err := s.bindRepository.GetBindApps(instanceName)
+	if err != nil {
+		s.logger.Error("failed to retrieve bindApps", zap.String("instanceName", instanceName), zap.Error(err))
+		return nil, BindAppRetrievalFailure
+	}
+
+	bindings := make([]*models.Binding, 0, len(bindApps))
+	for _, bindApp := range bindApps {
+		binding, result := s.bindingOf(instanceName, bindApp)
+		if result != BindAppRetrievalSuccess {
+			return nil, result
+		}
+		bindings = append(bindings, binding)
+	}
+	return bindings, BindAppRetrievalSuccess
+}
+
+func (s *bindService) GetBinding(instanceName, appName string) (*models.Binding, BindAppRetrievalResult) {
+	bindApp, result := s.getBindApp(instanceName, appName)
+	if result != BindAppRetrievalSuccess {
+		return nil, result
+	}
+	return s.bindingOf(instanceName, bindApp)
+}
+
+/*
+	the names of the instances the app is bound to, none when the app is not bound at all
+*/
+func (s *bindService) GetAppBindings(appName string) ([]string, BindAppRetrievalResult) {
+	instanceNames, err := s.bindRepository.GetInstancesBoundTo(appName)
+	if err != nil {
+		s.logger.Error("failed to retrieve instances bound to app", zap.String("appName", appName), zap.Error(err))
+		return nil, BindAppRetrievalFailure
+	}
+	return instanceNames, BindAppRetrievalSuccess
+}
 
+func NewBindService(config *viper.Viper, logger *zap.Logger, bindRepository repositories.BindRepository, instanceService InstanceService, pushApiService PushApiService, eventService EventService) BindService {
 	return &bindService{
-		bindAppPrefix:   bindAppPrefix,
-		bindUnitPrefix:  bindUnitPrefix,
+		bindRepository:  bindRepository,
+		eventService:    eventService,
 		instanceService: instanceService,
 		logger:          logger,
-		redisClient:     redisClient,
+		pushApiService:  pushApiService,
 	}
 }
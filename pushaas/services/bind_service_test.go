@@ -2,14 +2,15 @@ package services_test
 
 import (
 	"errors"
+	"sync"
 
-	"github.com/go-redis/redis"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/viper"
 
 	"github.com/pushaas/pushaas/pushaas/mocks"
 	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/repositories"
 	"github.com/pushaas/pushaas/pushaas/services"
 )
 
@@ -21,17 +22,24 @@ var _ = Describe("BindService", func() {
 	appName := "app-1"
 	appHost := "app-host-1"
 
+	newEventService := func() *mocks.EventServiceMock {
+		return &mocks.EventServiceMock{
+			PublishFunc: func(event *models.InstanceEvent) {},
+		}
+	}
+
 	_ = Describe("BindApp", func() {
 		_ = It("indicates when instance is not found", func() {
 			// arrange
 			var expected map[string]string
-			redisClient := &mocks.UniversalClientMock{}
+			bindRepository := &mocks.BindRepositoryMock{}
 			instanceService := &mocks.InstanceServiceMock{
 				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
 					return nil, services.InstanceRetrievalNotFound
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			varsMap, result := bindService.BindApp(instanceName, bindAppForm)
@@ -48,13 +56,14 @@ var _ = Describe("BindService", func() {
 			instance := &models.Instance{
 				Status: models.InstanceStatusPending,
 			}
-			redisClient := &mocks.UniversalClientMock{}
+			bindRepository := &mocks.BindRepositoryMock{}
 			instanceService := &mocks.InstanceServiceMock{
 				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
 					return instance, services.InstanceRetrievalSuccess
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			varsMap, result := bindService.BindApp(instanceName, bindAppForm)
@@ -71,13 +80,14 @@ var _ = Describe("BindService", func() {
 			instance := &models.Instance{
 				Status: models.InstanceStatusFailed,
 			}
-			redisClient := &mocks.UniversalClientMock{}
+			bindRepository := &mocks.BindRepositoryMock{}
 			instanceService := &mocks.InstanceServiceMock{
 				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
 					return instance, services.InstanceRetrievalSuccess
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			varsMap, result := bindService.BindApp(instanceName, bindAppForm)
@@ -86,8 +96,8 @@ var _ = Describe("BindService", func() {
 			Expect(result).To(Equal(services.BindAppInstanceFailed))
 			Expect(varsMap).To(Equal(expected))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(0))
-			Expect(redisClient.HMSetCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(0))
+			Expect(bindRepository.CreateBindAppCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when instance is already bound to an app", func() {
@@ -96,12 +106,12 @@ var _ = Describe("BindService", func() {
 			instance := &models.Instance{
 				Status: models.InstanceStatusRunning,
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string {
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName: appName,
+						AppHost: appHost,
+					}, nil
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{
@@ -109,7 +119,8 @@ var _ = Describe("BindService", func() {
 					return instance, services.InstanceRetrievalSuccess
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			varsMap, result := bindService.BindApp(instanceName, bindAppForm)
@@ -118,8 +129,8 @@ var _ = Describe("BindService", func() {
 			Expect(result).To(Equal(services.BindAppAlreadyBound))
 			Expect(varsMap).To(Equal(expected))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HMSetCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.CreateBindAppCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when fails to check existing bind", func() {
@@ -128,9 +139,9 @@ var _ = Describe("BindService", func() {
 			instance := &models.Instance{
 				Status: models.InstanceStatusRunning,
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, errors.New("some error"))
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, errors.New("some error")
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{
@@ -138,7 +149,8 @@ var _ = Describe("BindService", func() {
 					return instance, services.InstanceRetrievalSuccess
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			varsMap, result := bindService.BindApp(instanceName, bindAppForm)
@@ -147,8 +159,8 @@ var _ = Describe("BindService", func() {
 			Expect(result).To(Equal(services.BindAppFailure))
 			Expect(varsMap).To(Equal(expected))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HMSetCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.CreateBindAppCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when fails to create new bind", func() {
@@ -157,20 +169,24 @@ var _ = Describe("BindService", func() {
 			instance := &models.Instance{
 				Status: models.InstanceStatusRunning,
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string {}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, repositories.ErrNotFound
 				},
-				HMSetFunc: func(key string, fields map[string]interface{}) *redis.StatusCmd {
-					return redis.NewStatusResult("", errors.New("some error"))
+				CreateBindAppFunc: func(instanceName string, bindApp *models.BindApp) error {
+					return errors.New("some error")
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{
 				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
 					return instance, services.InstanceRetrievalSuccess
 				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			varsMap, result := bindService.BindApp(instanceName, bindAppForm)
@@ -179,13 +195,142 @@ var _ = Describe("BindService", func() {
 			Expect(result).To(Equal(services.BindAppFailure))
 			Expect(varsMap).To(Equal(expected))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HMSetCalls()).To(HaveLen(1))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.CreateBindAppCalls()).To(HaveLen(1))
+			Expect(pushApiService.AddCredentialCalls()).To(HaveLen(0))
+		})
+
+		_ = It("indicates when the app is bound concurrently, leaving the credential alone", func() {
+			// arrange
+			var expected map[string]string
+			instance := &models.Instance{
+				Status: models.InstanceStatusRunning,
+			}
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, repositories.ErrNotFound
+				},
+				CreateBindAppFunc: func(instanceName string, bindApp *models.BindApp) error {
+					return repositories.ErrAlreadyExists
+				},
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return instance, services.InstanceRetrievalSuccess
+				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
+
+			// act
+			varsMap, result := bindService.BindApp(instanceName, bindAppForm)
+
+			// assert
+			Expect(result).To(Equal(services.BindAppAlreadyBound))
+			Expect(varsMap).To(Equal(expected))
+			Expect(pushApiService.AddCredentialCalls()).To(HaveLen(0))
+			Expect(pushApiService.RevokeCredentialCalls()).To(HaveLen(0))
+		})
+
+		_ = It("lets only one of concurrent bindings of the same app succeed", func() {
+			// arrange
+			bindRepository, cleanup := newRedisRepository()
+			defer cleanup()
+			instance := &models.Instance{
+				Name:   instanceName,
+				Status: models.InstanceStatusRunning,
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return instance, services.InstanceRetrievalSuccess
+				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{
+				AddCredentialFunc: func(instanceVars map[string]string, username string, password string) error {
+					return nil
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
+
+			// act
+			results := make([]services.BindAppResult, 20)
+			var wg sync.WaitGroup
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, results[i] = bindService.BindApp(instanceName, &models.BindAppForm{AppName: appName, AppHost: appHost})
+				}(i)
+			}
+			wg.Wait()
+
+			// assert
+			succeeded := 0
+			for _, result := range results {
+				if result == services.BindAppSuccess {
+					succeeded++
+				} else {
+					Expect(result).To(Equal(services.BindAppAlreadyBound))
+				}
+			}
+			Expect(succeeded).To(Equal(1))
+			Expect(pushApiService.AddCredentialCalls()).To(HaveLen(1))
 		})
 
-		_ = It("indicates when creates new bind successfully", func() {
+		_ = It("indicates when fails to issue the credential of the app, removing the binding", func() {
 			// arrange
-			expected := map[string]string{
+			var expected map[string]string
+			instance := &models.Instance{
+				Name:   instanceName,
+				Status: models.InstanceStatusRunning,
+			}
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, repositories.ErrNotFound
+				},
+				CreateBindAppFunc: func(instanceName string, bindApp *models.BindApp) error {
+					return nil
+				},
+				DelBindAppFunc: func(instanceName, appName string) error {
+					return nil
+				},
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return instance, services.InstanceRetrievalSuccess
+				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{
+				AddCredentialFunc: func(instanceVars map[string]string, username string, password string) error {
+					return errors.New("some error")
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
+
+			// act
+			varsMap, result := bindService.BindApp(instanceName, &models.BindAppForm{AppName: appName, AppHost: appHost})
+
+			// assert
+			Expect(result).To(Equal(services.BindAppFailure))
+			Expect(varsMap).To(Equal(expected))
+			Expect(bindRepository.CreateBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.DelBindAppCalls()[0].InstanceName).To(Equal(instanceName))
+			Expect(bindRepository.DelBindAppCalls()[0].AppName).To(Equal(appName))
+		})
+
+		_ = It("indicates when creates new bind successfully, with a credential of the app", func() {
+			// arrange
+			instanceVars := map[string]string{
 				"PUSHAAS_ENDPOINT": "the-endpoint",
 				"PUSHAAS_USERNAME": "the-username",
 				"PUSHAAS_PASSWORD": "the-password",
@@ -193,30 +338,51 @@ var _ = Describe("BindService", func() {
 			instance := &models.Instance{
 				Status: models.InstanceStatusRunning,
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string {}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, repositories.ErrNotFound
 				},
-				HMSetFunc: func(key string, fields map[string]interface{}) *redis.StatusCmd {
-					return redis.NewStatusResult("ok", nil)
+				CreateBindAppFunc: func(instanceName string, bindApp *models.BindApp) error {
+					return nil
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{
 				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
 					return instance, services.InstanceRetrievalSuccess
 				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return instanceVars, nil
+				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{
+				AddCredentialFunc: func(instanceVars map[string]string, username string, password string) error {
+					return nil
+				},
+			}
+			eventService := newEventService()
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, eventService)
 
 			// act
-			varsMap, result := bindService.BindApp(instanceName, bindAppForm)
+			varsMap, result := bindService.BindApp(instanceName, &models.BindAppForm{AppName: appName, AppHost: appHost})
 
 			// assert
 			Expect(result).To(Equal(services.BindAppSuccess))
-			Expect(varsMap).To(Equal(expected))
+			Expect(varsMap["PUSHAAS_ENDPOINT"]).To(Equal("the-endpoint"))
+			Expect(varsMap["PUSHAAS_USERNAME"]).To(Equal("app-app-1"))
+			Expect(varsMap["PUSHAAS_PASSWORD"]).NotTo(BeEmpty())
+			Expect(varsMap["PUSHAAS_PASSWORD"]).NotTo(Equal("the-password"))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HMSetCalls()).To(HaveLen(1))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.CreateBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.CreateBindAppCalls()[0].BindApp.Username).To(Equal("app-app-1"))
+			Expect(bindRepository.CreateBindAppCalls()[0].BindApp.Password).To(Equal(varsMap["PUSHAAS_PASSWORD"]))
+			Expect(pushApiService.AddCredentialCalls()).To(HaveLen(1))
+			Expect(pushApiService.AddCredentialCalls()[0].InstanceVars).To(Equal(instanceVars))
+			Expect(pushApiService.AddCredentialCalls()[0].Username).To(Equal("app-app-1"))
+			Expect(pushApiService.AddCredentialCalls()[0].Password).To(Equal(varsMap["PUSHAAS_PASSWORD"]))
+			Expect(eventService.PublishCalls()).To(HaveLen(1))
+			Expect(eventService.PublishCalls()[0].Event.Type).To(Equal(models.InstanceEventAppBound))
+			Expect(eventService.PublishCalls()[0].Event.App).To(Equal(appName))
 		})
 	})
 
@@ -228,8 +394,9 @@ var _ = Describe("BindService", func() {
 					return nil, services.InstanceRetrievalNotFound
 				},
 			}
-			redisClient := &mocks.UniversalClientMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			bindRepository := &mocks.BindRepositoryMock{}
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindApp(instanceName, bindAppForm)
@@ -237,8 +404,8 @@ var _ = Describe("BindService", func() {
 			// assert
 			Expect(result).To(Equal(services.UnbindAppInstanceNotFound))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(0))
-			Expect(redisClient.DelCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(0))
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when fails to check existing app binding", func() {
@@ -252,12 +419,13 @@ var _ = Describe("BindService", func() {
 					return instance, services.InstanceRetrievalSuccess
 				},
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, errors.New("some error"))
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, errors.New("some error")
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindApp(instanceName, bindAppForm)
@@ -265,8 +433,8 @@ var _ = Describe("BindService", func() {
 			// assert
 			Expect(result).To(Equal(services.UnbindAppFailure))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when can't find instance binding to app", func() {
@@ -280,12 +448,13 @@ var _ = Describe("BindService", func() {
 					return instance, services.InstanceRetrievalSuccess
 				},
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, repositories.ErrNotFound
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindApp(instanceName, bindAppForm)
@@ -293,8 +462,8 @@ var _ = Describe("BindService", func() {
 			// assert
 			Expect(result).To(Equal(services.UnbindAppNotBound))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when fails to remove binding", func() {
@@ -308,18 +477,19 @@ var _ = Describe("BindService", func() {
 					return instance, services.InstanceRetrievalSuccess
 				},
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName: appName,
+						AppHost: appHost,
+					}, nil
 				},
-				DelFunc: func(keys ...string) *redis.IntCmd {
-					return redis.NewIntResult(0, errors.New("some error"))
+				DelBindAppFunc: func(instanceName, appName string) error {
+					return errors.New("some error")
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindApp(instanceName, bindAppForm)
@@ -327,8 +497,8 @@ var _ = Describe("BindService", func() {
 			// assert
 			Expect(result).To(Equal(services.UnbindAppFailure))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(1))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(1))
 		})
 
 		_ = It("indicates when does not find the binding when tries to remove it", func() {
@@ -342,18 +512,19 @@ var _ = Describe("BindService", func() {
 					return instance, services.InstanceRetrievalSuccess
 				},
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName: appName,
+						AppHost: appHost,
+					}, nil
 				},
-				DelFunc: func(keys ...string) *redis.IntCmd {
-					return redis.NewIntResult(0, nil)
+				DelBindAppFunc: func(instanceName, appName string) error {
+					return repositories.ErrNotFound
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindApp(instanceName, bindAppForm)
@@ -361,11 +532,11 @@ var _ = Describe("BindService", func() {
 			// assert
 			Expect(result).To(Equal(services.UnbindAppNotBound))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(1))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(1))
 		})
 
-		_ = It("indicates when removes the binding successfully", func() {
+		_ = It("indicates when fails to revoke the credential of the app, keeping the binding", func() {
 			// arrange
 			instance := &models.Instance{
 				Name: instanceName,
@@ -375,19 +546,70 @@ var _ = Describe("BindService", func() {
 				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
 					return instance, services.InstanceRetrievalSuccess
 				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName:  appName,
+						AppHost:  appHost,
+						Username: "app-app-1",
+						Password: "app-password",
+					}, nil
 				},
-				DelFunc: func(keys ...string) *redis.IntCmd {
-					return redis.NewIntResult(1, nil)
+			}
+			pushApiService := &mocks.PushApiServiceMock{
+				RevokeCredentialFunc: func(instanceVars map[string]string, username string) error {
+					return errors.New("some error")
 				},
 			}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
+
+			// act
+			result := bindService.UnbindApp(instanceName, bindAppForm)
+
+			// assert
+			Expect(result).To(Equal(services.UnbindAppFailure))
+			Expect(pushApiService.RevokeCredentialCalls()).To(HaveLen(1))
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(0))
+		})
+
+		_ = It("indicates when removes the binding successfully, revoking the credential of the app", func() {
+			// arrange
+			instance := &models.Instance{
+				Name: instanceName,
+				Status: models.InstanceStatusRunning,
+			}
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return instance, services.InstanceRetrievalSuccess
+				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName:  appName,
+						AppHost:  appHost,
+						Username: "app-app-1",
+						Password: "app-password",
+					}, nil
+				},
+				DelBindAppFunc: func(instanceName, appName string) error {
+					return nil
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{
+				RevokeCredentialFunc: func(instanceVars map[string]string, username string) error {
+					return nil
+				},
+			}
+			eventService := newEventService()
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, eventService)
 
 			// act
 			result := bindService.UnbindApp(instanceName, bindAppForm)
@@ -395,238 +617,534 @@ var _ = Describe("BindService", func() {
 			// assert
 			Expect(result).To(Equal(services.UnbindAppSuccess))
 			Expect(instanceService.GetByNameCalls()).To(HaveLen(1))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(1))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(1))
+			Expect(pushApiService.RevokeCredentialCalls()).To(HaveLen(1))
+			Expect(pushApiService.RevokeCredentialCalls()[0].Username).To(Equal("app-app-1"))
+			Expect(eventService.PublishCalls()).To(HaveLen(1))
+			Expect(eventService.PublishCalls()[0].Event.Type).To(Equal(models.InstanceEventAppUnbound))
+			Expect(eventService.PublishCalls()[0].Event.Instance).To(Equal(instanceName))
 		})
 	})
 
 	_ = Describe("BindUnit", func() {
 		_ = It("indicates when fails to check existing app bind", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, errors.New("some error"))
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, errors.New("some error")
 				},
 			}
-			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return &models.Instance{Name: instanceName}, services.InstanceRetrievalSuccess
+				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
-			result := bindService.BindUnit(instanceName, bindUnitForm)
+			_, result := bindService.BindUnit(instanceName, bindUnitForm)
 
 			// assert
 			Expect(result).To(Equal(services.BindUnitFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SAddCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.AddBindUnitCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when app bind is not found", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, repositories.ErrNotFound
 				},
 			}
-			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return &models.Instance{Name: instanceName}, services.InstanceRetrievalSuccess
+				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
-			result := bindService.BindUnit(instanceName, bindUnitForm)
+			_, result := bindService.BindUnit(instanceName, bindUnitForm)
 
 			// assert
 			Expect(result).To(Equal(services.BindUnitAppNotBound))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SAddCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.AddBindUnitCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when fails to bind unit", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName: appName,
+						AppHost: appHost,
+					}, nil
 				},
-				SAddFunc: func(key string, members ...interface{}) *redis.IntCmd {
-					return redis.NewIntResult(0, errors.New("some error"))
+				AddBindUnitFunc: func(instanceName, appName, unitHost string) error {
+					return errors.New("some error")
 				},
 			}
-			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return &models.Instance{Name: instanceName}, services.InstanceRetrievalSuccess
+				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
-			result := bindService.BindUnit(instanceName, bindUnitForm)
+			_, result := bindService.BindUnit(instanceName, bindUnitForm)
 
 			// assert
 			Expect(result).To(Equal(services.BindUnitFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SAddCalls()).To(HaveLen(1))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.AddBindUnitCalls()).To(HaveLen(1))
 		})
 
-		_ = It("indicates when unit is already bound", func() {
+		_ = It("succeeds when unit is already bound", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName: appName,
+						AppHost: appHost,
+					}, nil
 				},
-				SAddFunc: func(key string, members ...interface{}) *redis.IntCmd {
-					return redis.NewIntResult(0, nil)
+				AddBindUnitFunc: func(instanceName, appName, unitHost string) error {
+					return repositories.ErrAlreadyExists
 				},
 			}
-			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return &models.Instance{Name: instanceName}, services.InstanceRetrievalSuccess
+				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
-			result := bindService.BindUnit(instanceName, bindUnitForm)
+			_, result := bindService.BindUnit(instanceName, bindUnitForm)
 
 			// assert
-			Expect(result).To(Equal(services.BindUnitAlreadyBound))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SAddCalls()).To(HaveLen(1))
+			Expect(result).To(Equal(services.BindUnitSuccess))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.AddBindUnitCalls()).To(HaveLen(1))
 		})
 
-		_ = It("indicates when binds unit successfully", func() {
+		_ = It("indicates when binds unit successfully, with the credential of the app", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName:  appName,
+						AppHost:  appHost,
+						Username: "app-app-1",
+						Password: "app-password",
+					}, nil
 				},
-				SAddFunc: func(key string, members ...interface{}) *redis.IntCmd {
-					return redis.NewIntResult(1, nil)
+				AddBindUnitFunc: func(instanceName, appName, unitHost string) error {
+					return nil
 				},
 			}
-			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return &models.Instance{Name: instanceName}, services.InstanceRetrievalSuccess
+				},
+				GetInstanceVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
-			result := bindService.BindUnit(instanceName, bindUnitForm)
+			varsMap, result := bindService.BindUnit(instanceName, bindUnitForm)
 
 			// assert
 			Expect(result).To(Equal(services.BindUnitSuccess))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SAddCalls()).To(HaveLen(1))
+			Expect(varsMap).To(Equal(map[string]string{
+				"PUSHAAS_USERNAME": "app-app-1",
+				"PUSHAAS_PASSWORD": "app-password",
+			}))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.AddBindUnitCalls()).To(HaveLen(1))
 		})
 	})
 
 	_ = Describe("UnbindUnit", func() {
 		_ = It("indicates when fails to check existing app bind", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, errors.New("some error"))
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, errors.New("some error")
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindUnit(instanceName, bindUnitForm)
 
 			// assert
 			Expect(result).To(Equal(services.UnbindUnitFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SRemCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.RemoveBindUnitCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when app bind is not found", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, repositories.ErrNotFound
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindUnit(instanceName, bindUnitForm)
 
 			// assert
 			Expect(result).To(Equal(services.UnbindUnitAppNotBound))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SRemCalls()).To(HaveLen(0))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.RemoveBindUnitCalls()).To(HaveLen(0))
 		})
 
 		_ = It("indicates when fails to unbind unit", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName: appName,
+						AppHost: appHost,
+					}, nil
 				},
-				SRemFunc: func(key string, members ...interface{}) *redis.IntCmd {
-					return redis.NewIntResult(0, errors.New("some error"))
+				RemoveBindUnitFunc: func(instanceName, appName, unitHost string) error {
+					return errors.New("some error")
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindUnit(instanceName, bindUnitForm)
 
 			// assert
 			Expect(result).To(Equal(services.UnbindUnitFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SRemCalls()).To(HaveLen(1))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.RemoveBindUnitCalls()).To(HaveLen(1))
 		})
 
 		_ = It("indicates when unit is not bound", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName: appName,
+						AppHost: appHost,
+					}, nil
 				},
-				SRemFunc: func(key string, members ...interface{}) *redis.IntCmd {
-					return redis.NewIntResult(0, nil)
+				RemoveBindUnitFunc: func(instanceName, appName, unitHost string) error {
+					return repositories.ErrNotFound
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindUnit(instanceName, bindUnitForm)
 
 			// assert
 			Expect(result).To(Equal(services.UnbindUnitNotBound))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SRemCalls()).To(HaveLen(1))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.RemoveBindUnitCalls()).To(HaveLen(1))
 		})
 
 		_ = It("indicates when unbinds unit successfully", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(map[string]string{
-						"AppName": appName,
-						"AppHost": appHost,
-					}, nil)
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{
+						AppName: appName,
+						AppHost: appHost,
+					}, nil
 				},
-				SRemFunc: func(key string, members ...interface{}) *redis.IntCmd {
-					return redis.NewIntResult(1, nil)
+				RemoveBindUnitFunc: func(instanceName, appName, unitHost string) error {
+					return nil
 				},
 			}
 			instanceService := &mocks.InstanceServiceMock{}
-			bindService := services.NewBindService(config, logger, redisClient, instanceService)
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, pushApiService, newEventService())
 
 			// act
 			result := bindService.UnbindUnit(instanceName, bindUnitForm)
 
 			// assert
 			Expect(result).To(Equal(services.UnbindUnitSuccess))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.SRemCalls()).To(HaveLen(1))
+			Expect(bindRepository.GetBindAppCalls()).To(HaveLen(1))
+			Expect(bindRepository.RemoveBindUnitCalls()).To(HaveLen(1))
+		})
+	})
+
+	_ = Describe("RotateAppCredentials", func() {
+		instanceVars := map[string]string{
+			"PUSHAAS_ENDPOINT": "http://10.0.0.3:8080",
+			"PUSHAAS_USERNAME": "app",
+			"PUSHAAS_PASSWORD": "instance-password",
+		}
+
+		newBindRepository := func() *mocks.BindRepositoryMock {
+			return &mocks.BindRepositoryMock{
+				GetBindAppsFunc: func(instanceName string) ([]*models.BindApp, error) {
+					return []*models.BindApp{
+						{AppName: "app-1", Username: "app-app-1", Password: "old-password"},
+						{AppName: "app-2", Username: "app-app-2", Password: "old-password"},
+					}, nil
+				},
+				SaveBindAppFunc: func(instanceName string, bindApp *models.BindApp) error {
+					return nil
+				},
+			}
+		}
+
+		_ = It("issues a new credential to every bound app", func() {
+			// arrange
+			bindRepository := newBindRepository()
+			pushApiService := &mocks.PushApiServiceMock{
+				AddCredentialFunc: func(instanceVars map[string]string, username string, password string) error {
+					return nil
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, &mocks.InstanceServiceMock{}, pushApiService, newEventService())
+
+			// act
+			envVarsByApp, result := bindService.RotateAppCredentials(instanceName, instanceVars)
+
+			// assert
+			Expect(result).To(Equal(services.RotateAppCredentialsSuccess))
+			Expect(envVarsByApp).To(HaveLen(2))
+			Expect(envVarsByApp["app-1"]["PUSHAAS_ENDPOINT"]).To(Equal("http://10.0.0.3:8080"))
+			Expect(envVarsByApp["app-1"]["PUSHAAS_USERNAME"]).To(Equal("app-app-1"))
+			Expect(envVarsByApp["app-1"]["PUSHAAS_PASSWORD"]).NotTo(Equal("old-password"))
+			Expect(pushApiService.AddCredentialCalls()).To(HaveLen(2))
+			Expect(bindRepository.SaveBindAppCalls()).To(HaveLen(2))
+		})
+
+		_ = It("keeps going when an app fails to get a new credential", func() {
+			// arrange
+			bindRepository := newBindRepository()
+			pushApiService := &mocks.PushApiServiceMock{
+				AddCredentialFunc: func(instanceVars map[string]string, username string, password string) error {
+					if username == "app-app-1" {
+						return errors.New("some error")
+					}
+					return nil
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, &mocks.InstanceServiceMock{}, pushApiService, newEventService())
+
+			// act
+			envVarsByApp, result := bindService.RotateAppCredentials(instanceName, instanceVars)
+
+			// assert
+			Expect(result).To(Equal(services.RotateAppCredentialsFailure))
+			Expect(envVarsByApp).To(HaveLen(1))
+			Expect(envVarsByApp).To(HaveKey("app-2"))
+			Expect(bindRepository.SaveBindAppCalls()).To(HaveLen(1))
+		})
+
+		_ = It("indicates when fails to list the bound apps", func() {
+			// arrange
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppsFunc: func(instanceName string) ([]*models.BindApp, error) {
+					return nil, errors.New("some error")
+				},
+			}
+			pushApiService := &mocks.PushApiServiceMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, &mocks.InstanceServiceMock{}, pushApiService, newEventService())
+
+			// act
+			envVarsByApp, result := bindService.RotateAppCredentials(instanceName, instanceVars)
+
+			// assert
+			Expect(result).To(Equal(services.RotateAppCredentialsFailure))
+			Expect(envVarsByApp).To(BeNil())
+			Expect(pushApiService.AddCredentialCalls()).To(HaveLen(0))
+		})
+	})
+
+	_ = Describe("GetBindings", func() {
+		instanceFound := &mocks.InstanceServiceMock{
+			GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+				return &models.Instance{Name: name}, services.InstanceRetrievalSuccess
+			},
+		}
+
+		_ = It("indicates when instance is not found", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return nil, services.InstanceRetrievalNotFound
+				},
+			}
+			bindRepository := &mocks.BindRepositoryMock{}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceService, &mocks.PushApiServiceMock{}, newEventService())
+
+			// act
+			bindings, result := bindService.GetBindings(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.BindAppRetrievalNotFound))
+			Expect(bindings).To(BeNil())
+			Expect(bindRepository.GetBindAppsCalls()).To(HaveLen(0))
+		})
+
+		_ = It("indicates when fails to retrieve the units of a bound app", func() {
+			// arrange
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppsFunc: func(instanceName string) ([]*models.BindApp, error) {
+					return []*models.BindApp{{AppName: appName}}, nil
+				},
+				GetBindUnitsFunc: func(instanceName, appName string) ([]string, error) {
+					return nil, errors.New("some error")
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceFound, &mocks.PushApiServiceMock{}, newEventService())
+
+			// act
+			bindings, result := bindService.GetBindings(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.BindAppRetrievalFailure))
+			Expect(bindings).To(BeNil())
+		})
+
+		_ = It("retrieves the bound apps with their units, leaving their credentials out", func() {
+			// arrange
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppsFunc: func(instanceName string) ([]*models.BindApp, error) {
+					return []*models.BindApp{
+						{AppName: "app-1", AppHost: "app-1.example.com", Username: "app-app-1", Password: "password-1"},
+						{AppName: "app-2", AppHost: "app-2.example.com", Username: "app-app-2", Password: "password-2"},
+					}, nil
+				},
+				GetBindUnitsFunc: func(instanceName, appName string) ([]string, error) {
+					if appName == "app-1" {
+						return []string{"unit-1", "unit-2"}, nil
+					}
+					return []string{}, nil
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, instanceFound, &mocks.PushApiServiceMock{}, newEventService())
+
+			// act
+			bindings, result := bindService.GetBindings(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.BindAppRetrievalSuccess))
+			Expect(bindings).To(Equal([]*models.Binding{
+				{AppName: "app-1", AppHost: "app-1.example.com", UnitHosts: []string{"unit-1", "unit-2"}},
+				{AppName: "app-2", AppHost: "app-2.example.com", UnitHosts: []string{}},
+			}))
+		})
+	})
+
+	_ = Describe("GetBinding", func() {
+		_ = It("indicates when the app is not bound", func() {
+			// arrange
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return nil, repositories.ErrNotFound
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, &mocks.InstanceServiceMock{}, &mocks.PushApiServiceMock{}, newEventService())
+
+			// act
+			binding, result := bindService.GetBinding(instanceName, appName)
+
+			// assert
+			Expect(result).To(Equal(services.BindAppRetrievalNotFound))
+			Expect(binding).To(BeNil())
+		})
+
+		_ = It("retrieves the bound app with its units", func() {
+			// arrange
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppFunc: func(instanceName, appName string) (*models.BindApp, error) {
+					return &models.BindApp{AppName: appName, AppHost: appHost}, nil
+				},
+				GetBindUnitsFunc: func(instanceName, appName string) ([]string, error) {
+					return []string{"unit-1"}, nil
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, &mocks.InstanceServiceMock{}, &mocks.PushApiServiceMock{}, newEventService())
+
+			// act
+			binding, result := bindService.GetBinding(instanceName, appName)
+
+			// assert
+			Expect(result).To(Equal(services.BindAppRetrievalSuccess))
+			Expect(binding).To(Equal(&models.Binding{AppName: appName, AppHost: appHost, UnitHosts: []string{"unit-1"}}))
+			Expect(bindRepository.GetBindUnitsCalls()[0].InstanceName).To(Equal(instanceName))
+		})
+	})
+
+	_ = Describe("GetAppBindings", func() {
+		_ = It("retrieves the instances the app is bound to", func() {
+			// arrange
+			bindRepository := &mocks.BindRepositoryMock{
+				GetInstancesBoundToFunc: func(appName string) ([]string, error) {
+					return []string{"instance-1", "instance-2"}, nil
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, &mocks.InstanceServiceMock{}, &mocks.PushApiServiceMock{}, newEventService())
+
+			// act
+			instanceNames, result := bindService.GetAppBindings(appName)
+
+			// assert
+			Expect(result).To(Equal(services.BindAppRetrievalSuccess))
+			Expect(instanceNames).To(Equal([]string{"instance-1", "instance-2"}))
+			Expect(bindRepository.GetInstancesBoundToCalls()[0].AppName).To(Equal(appName))
+		})
+
+		_ = It("indicates when fails to retrieve the instances", func() {
+			// arrange
+			bindRepository := &mocks.BindRepositoryMock{
+				GetInstancesBoundToFunc: func(appName string) ([]string, error) {
+					return nil, errors.New("some error")
+				},
+			}
+			bindService := services.NewBindService(config, logger, bindRepository, &mocks.InstanceServiceMock{}, &mocks.PushApiServiceMock{}, newEventService())
+
+			// act
+			instanceNames, result := bindService.GetAppBindings(appName)
+
+			// assert
+			Expect(result).To(Equal(services.BindAppRetrievalFailure))
+			Expect(instanceNames).To(BeNil())
 		})
 	})
 })
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+)
+
+type (
+	CredentialRotationResult int
+
+	CredentialService interface {
+		Rotate(name string) (*models.Operation, CredentialRotationResult)
+		RotateAppCredentials(instanceName string, instanceVars map[string]string) error
+	}
+
+	/*
+		Replaces the instance-wide password of push-api with a new one. The rotation itself happens on the workers,
+		and once push-api has the new password every bound app gets a new credential, sent to it through Tsuru.
+	*/
+	credentialService struct {
+		logger           *zap.Logger
+		instanceService  InstanceService
+		provisionService ProvisionService
+		bindService      BindService
+		tsuruService     TsuruService
+		rotator          provisioners.PushServiceCredentialRotator
+	}
+)
+
+const (
+	CredentialRotationSuccess CredentialRotationResult = iota
+	CredentialRotationNotFound
+	CredentialRotationNotRunning
+	CredentialRotationNotSupported
+	CredentialRotationFailure
+	CredentialRotationDispatchFailure
+	CredentialRotationBusy
+)
+
+func (s *credentialService) Rotate(name string) (*models.Operation, CredentialRotationResult) {
+	if s.rotator == nil {
+		return nil, CredentialRotationNotSupported
+	}
+
+	instance, resultGet := s.instanceService.GetByName(name)
+	if resultGet == InstanceRetrievalNotFound {
+		return nil, CredentialRotationNotFound
+	}
+	if resultGet == InstanceRetrievalFailure {
+		return nil, CredentialRotationFailure
+	}
+
+	if instance.Status != models.InstanceStatusRunning && instance.Status != models.InstanceStatusDegraded {
+		return nil, CredentialRotationNotRunning
+	}
+
+	busy, err := s.instanceService.IsBusy(name)
+	if err != nil {
+		return nil, CredentialRotationFailure
+	}
+	if busy {
+		return nil, CredentialRotationBusy
+	}
+
+	previousStatus := instance.Status
+	instance.Status = models.InstanceStatusPending
+	change := &models.InstanceChange{Instance: *instance, Previous: &models.InstancePrevious{Status: previousStatus}}
+	operation, task, resultPrepare := s.provisionService.PrepareRotateCredentials(change)
+	if resultPrepare != DispatchRotateCredentialsResultSuccess {
+		return nil, CredentialRotationDispatchFailure
+	}
+
+	// the rotation is written along with the change of status, to be sent by the relay
+	resultUpdate := s.instanceService.UpdateStatusWithTask(name, previousStatus, models.InstanceStatusPending, "credentials rotation", task)
+	if resultUpdate == InstanceUpdateNotFound {
+		s.provisionService.AbandonOperation(operation, "the instance was not found")
+		return nil, CredentialRotationNotFound
+	} else if resultUpdate == InstanceUpdateStatusChanged {
+		s.provisionService.AbandonOperation(operation, "the instance was changed meanwhile")
+		return nil, CredentialRotationBusy
+	} else if resultUpdate != InstanceUpdateSuccess {
+		s.logger.Error("failed to mark instance as pending for credentials rotation", zap.String("name", name))
+		s.provisionService.AbandonOperation(operation, "failed to mark the instance as pending")
+		return nil, CredentialRotationFailure
+	}
+
+	return operation, CredentialRotationSuccess
+}
+
+/*
+	the apps that fail to get their new env vars through Tsuru still have working credentials, but not the new
+	endpoint, so they are logged to be handled by hand
+*/
+func (s *credentialService) RotateAppCredentials(instanceName string, instanceVars map[string]string) error {
+	envVarsByApp, resultRotate := s.bindService.RotateAppCredentials(instanceName, instanceVars)
+
+	var err error
+	if resultRotate != RotateAppCredentialsSuccess {
+		err = errors.New("failed to issue new credentials to some of the apps")
+	}
+
+	for appName, envVars := range envVarsByApp {
+		errSet := s.tsuruService.SetAppEnvVars(appName, envVars)
+		if errSet == ErrTsuruNotConfigured {
+			s.logger.Warn("tsuru api is not configured, app has to get its new env vars by hand", zap.String("instanceName", instanceName), zap.String("appName", appName))
+			continue
+		}
+		if errSet != nil {
+			s.logger.Error("failed to send new env vars to app", zap.String("instanceName", instanceName), zap.String("appName", appName), zap.Error(errSet))
+			err = errors.New("failed to send new env vars to some of the apps")
+		}
+	}
+
+	return err
+}
+
+func NewCredentialService(config *viper.Viper, logger *zap.Logger, instanceService InstanceService, provisionService ProvisionService, bindService BindService, tsuruService TsuruService, rotator provisioners.PushServiceCredentialRotator) CredentialService {
+	return &credentialService{
+		logger:           logger.Named("credentialService"),
+		instanceService:  instanceService,
+		provisionService: provisionService,
+		bindService:      bindService,
+		tsuruService:     tsuruService,
+		rotator:          rotator,
+	}
+}
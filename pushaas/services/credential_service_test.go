@@ -0,0 +1,244 @@
+package services_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("CredentialService", func() {
+	config := viper.New()
+	rotator := &mocks.PushServiceCredentialRotatorMock{}
+
+	newInstanceService := func(status models.InstanceStatus) *mocks.InstanceServiceMock {
+		return &mocks.InstanceServiceMock{
+			GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+				return &models.Instance{Name: name, Status: status}, services.InstanceRetrievalSuccess
+			},
+			UpdateStatusWithTaskFunc: func(name string, from, status models.InstanceStatus, reason string, task *models.PendingTask) services.InstanceUpdateResult {
+				return services.InstanceUpdateSuccess
+			},
+			IsBusyFunc: func(name string) (bool, error) {
+				return false, nil
+			},
+		}
+	}
+
+	newProvisionService := func(result services.DispatchRotateCredentialsResult) *mocks.ProvisionServiceMock {
+		return &mocks.ProvisionServiceMock{
+			PrepareRotateCredentialsFunc: func(change *models.InstanceChange) (*models.Operation, *models.PendingTask, services.DispatchRotateCredentialsResult) {
+				if result != services.DispatchRotateCredentialsResultSuccess {
+					return nil, nil, result
+				}
+				return &models.Operation{Id: "operation-1"}, &models.PendingTask{Id: "task-1"}, result
+			},
+			AbandonOperationFunc: func(operation *models.Operation, reason string) {},
+		}
+	}
+
+	Describe("Rotate", func() {
+		It("indicates when the provider does not support it", func() {
+			// arrange
+			instanceService := newInstanceService(models.InstanceStatusRunning)
+			credentialService := services.NewCredentialService(config, logger, instanceService, newProvisionService(services.DispatchRotateCredentialsResultSuccess), nil, nil, nil)
+
+			// act
+			_, result := credentialService.Rotate("instance-1")
+
+			// assert
+			Expect(result).To(Equal(services.CredentialRotationNotSupported))
+			Expect(instanceService.GetByNameCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when the instance is not found", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+					return nil, services.InstanceRetrievalNotFound
+				},
+			}
+			credentialService := services.NewCredentialService(config, logger, instanceService, newProvisionService(services.DispatchRotateCredentialsResultSuccess), nil, nil, rotator)
+
+			// act
+			_, result := credentialService.Rotate("instance-1")
+
+			// assert
+			Expect(result).To(Equal(services.CredentialRotationNotFound))
+		})
+
+		It("indicates when the instance is not running", func() {
+			// arrange
+			instanceService := newInstanceService(models.InstanceStatusPending)
+			provisionService := newProvisionService(services.DispatchRotateCredentialsResultSuccess)
+			credentialService := services.NewCredentialService(config, logger, instanceService, provisionService, nil, nil, rotator)
+
+			// act
+			_, result := credentialService.Rotate("instance-1")
+
+			// assert
+			Expect(result).To(Equal(services.CredentialRotationNotRunning))
+			Expect(provisionService.PrepareRotateCredentialsCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when the instance is busy with another operation", func() {
+			// arrange
+			instanceService := newInstanceService(models.InstanceStatusRunning)
+			instanceService.IsBusyFunc = func(name string) (bool, error) {
+				return true, nil
+			}
+			provisionService := newProvisionService(services.DispatchRotateCredentialsResultSuccess)
+			credentialService := services.NewCredentialService(config, logger, instanceService, provisionService, nil, nil, rotator)
+
+			// act
+			_, result := credentialService.Rotate("instance-1")
+
+			// assert
+			Expect(result).To(Equal(services.CredentialRotationBusy))
+			Expect(instanceService.UpdateStatusWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareRotateCredentialsCalls()).To(HaveLen(0))
+		})
+
+		It("marks the instance as pending along with the rotation task", func() {
+			// arrange
+			instanceService := newInstanceService(models.InstanceStatusDegraded)
+			provisionService := newProvisionService(services.DispatchRotateCredentialsResultSuccess)
+			credentialService := services.NewCredentialService(config, logger, instanceService, provisionService, nil, nil, rotator)
+
+			// act
+			operation, result := credentialService.Rotate("instance-1")
+
+			// assert
+			Expect(result).To(Equal(services.CredentialRotationSuccess))
+			Expect(operation.Id).To(Equal("operation-1"))
+			Expect(provisionService.PrepareRotateCredentialsCalls()).To(HaveLen(1))
+			Expect(provisionService.PrepareRotateCredentialsCalls()[0].In1.Name).To(Equal("instance-1"))
+			Expect(provisionService.PrepareRotateCredentialsCalls()[0].In1.Previous).To(Equal(&models.InstancePrevious{Status: models.InstanceStatusDegraded}))
+			Expect(instanceService.UpdateStatusWithTaskCalls()).To(HaveLen(1))
+			Expect(instanceService.UpdateStatusWithTaskCalls()[0].Status).To(Equal(models.InstanceStatusPending))
+			Expect(instanceService.UpdateStatusWithTaskCalls()[0].Task.Id).To(Equal("task-1"))
+		})
+
+		It("leaves the instance as it was when fails to prepare the rotation", func() {
+			// arrange
+			instanceService := newInstanceService(models.InstanceStatusRunning)
+			provisionService := newProvisionService(services.DispatchRotateCredentialsResultFailure)
+			credentialService := services.NewCredentialService(config, logger, instanceService, provisionService, nil, nil, rotator)
+
+			// act
+			_, result := credentialService.Rotate("instance-1")
+
+			// assert
+			Expect(result).To(Equal(services.CredentialRotationDispatchFailure))
+			Expect(instanceService.UpdateStatusWithTaskCalls()).To(HaveLen(0))
+		})
+
+		It("abandons the operation when fails to mark the instance as pending", func() {
+			// arrange
+			instanceService := newInstanceService(models.InstanceStatusRunning)
+			instanceService.UpdateStatusWithTaskFunc = func(name string, from, status models.InstanceStatus, reason string, task *models.PendingTask) services.InstanceUpdateResult {
+				return services.InstanceUpdateFailure
+			}
+			provisionService := newProvisionService(services.DispatchRotateCredentialsResultSuccess)
+			credentialService := services.NewCredentialService(config, logger, instanceService, provisionService, nil, nil, rotator)
+
+			// act
+			_, result := credentialService.Rotate("instance-1")
+
+			// assert
+			Expect(result).To(Equal(services.CredentialRotationFailure))
+			Expect(provisionService.AbandonOperationCalls()).To(HaveLen(1))
+			Expect(provisionService.AbandonOperationCalls()[0].Operation.Id).To(Equal("operation-1"))
+		})
+	})
+
+	Describe("RotateAppCredentials", func() {
+		instanceVars := map[string]string{provisioners.EnvVarEndpoint: "http://10.0.0.3:8080"}
+
+		newBindService := func(result services.RotateAppCredentialsResult) *mocks.BindServiceMock {
+			return &mocks.BindServiceMock{
+				RotateAppCredentialsFunc: func(name string, instanceVars map[string]string) (map[string]map[string]string, services.RotateAppCredentialsResult) {
+					return map[string]map[string]string{
+						"app-1": {provisioners.EnvVarUsername: "app-app-1"},
+						"app-2": {provisioners.EnvVarUsername: "app-app-2"},
+					}, result
+				},
+			}
+		}
+
+		It("sends the new env vars of every app to tsuru", func() {
+			// arrange
+			bindService := newBindService(services.RotateAppCredentialsSuccess)
+			tsuruService := &mocks.TsuruServiceMock{
+				SetAppEnvVarsFunc: func(appName string, envVars map[string]string) error {
+					return nil
+				},
+			}
+			credentialService := services.NewCredentialService(config, logger, nil, nil, bindService, tsuruService, rotator)
+
+			// act
+			err := credentialService.RotateAppCredentials("instance-1", instanceVars)
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bindService.RotateAppCredentialsCalls()[0].InstanceVars).To(Equal(instanceVars))
+			Expect(tsuruService.SetAppEnvVarsCalls()).To(HaveLen(2))
+		})
+
+		It("does not fail when tsuru is not configured", func() {
+			// arrange
+			tsuruService := &mocks.TsuruServiceMock{
+				SetAppEnvVarsFunc: func(appName string, envVars map[string]string) error {
+					return services.ErrTsuruNotConfigured
+				},
+			}
+			credentialService := services.NewCredentialService(config, logger, nil, nil, newBindService(services.RotateAppCredentialsSuccess), tsuruService, rotator)
+
+			// act
+			err := credentialService.RotateAppCredentials("instance-1", instanceVars)
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails when tsuru refuses the env vars of an app", func() {
+			// arrange
+			tsuruService := &mocks.TsuruServiceMock{
+				SetAppEnvVarsFunc: func(appName string, envVars map[string]string) error {
+					return errors.New("some error")
+				},
+			}
+			credentialService := services.NewCredentialService(config, logger, nil, nil, newBindService(services.RotateAppCredentialsSuccess), tsuruService, rotator)
+
+			// act
+			err := credentialService.RotateAppCredentials("instance-1", instanceVars)
+
+			// assert
+			Expect(err).To(HaveOccurred())
+			Expect(tsuruService.SetAppEnvVarsCalls()).To(HaveLen(2))
+		})
+
+		It("still sends the env vars of the apps that got new credentials when some did not", func() {
+			// arrange
+			tsuruService := &mocks.TsuruServiceMock{
+				SetAppEnvVarsFunc: func(appName string, envVars map[string]string) error {
+					return nil
+				},
+			}
+			credentialService := services.NewCredentialService(config, logger, nil, nil, newBindService(services.RotateAppCredentialsFailure), tsuruService, rotator)
+
+			// act
+			err := credentialService.RotateAppCredentials("instance-1", instanceVars)
+
+			// assert
+			Expect(err).To(HaveOccurred())
+			Expect(tsuruService.SetAppEnvVarsCalls()).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/dchest/uniuri"
+	"github.com/go-redis/redis"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+type (
+	DeadLetterRetrievalResult int
+	DeadLetterReplayResult    int
+
+	/*
+		Keeps the tasks that ran out of retries on a redis list, the most recent first, trimmed to a maximum length
+		so that a task failing over and over does not fill redis. Replaying one sends it again with the retries of
+		its policy, and takes it off the list. The tasks that act on the provider are only replayed while their
+		instance is in the status they expect, or failed, the instance being moved to that status along with the task.
+	*/
+	DeadLetterService interface {
+		Record(taskName, payload, operationId string, attempts int, taskErr error)
+		GetAll() ([]*models.DeadLetter, DeadLetterRetrievalResult)
+		Replay(id string) (*models.DeadLetter, DeadLetterReplayResult)
+	}
+
+	deadLetterService struct {
+		logger          *zap.Logger
+		listKey         string
+		maxLength       int64
+		retryPolicies   TaskRetryPolicies
+		expectedStatus  map[string]models.InstanceStatus
+		redisClient     redis.UniversalClient
+		machineryServer *machinery.Server
+		instanceService InstanceService
+	}
+)
+
+const (
+	DeadLetterRetrievalSuccess DeadLetterRetrievalResult = iota
+	DeadLetterRetrievalFailure
+)
+
+const (
+	DeadLetterReplaySuccess DeadLetterReplayResult = iota
+	DeadLetterReplayNotFound
+	DeadLetterReplayFailure
+	DeadLetterReplayInstanceChanged
+)
+
+/*
+	the task already failed for good, so a failure to record it is only logged
+*/
+func (s *deadLetterService) Record(taskName, payload, operationId string, attempts int, taskErr error) {
+	deadLetter := &models.DeadLetter{
+		Id:          uniuri.New(),
+		TaskName:    taskName,
+		Payload:     payload,
+		OperationId: operationId,
+		Error:       taskErr.Error(),
+		Attempts:    attempts,
+		FailedAt:    time.Now().UTC(),
+	}
+
+	bytes, err := json.Marshal(deadLetter)
+	if err != nil {
+		s.logger.Error("failed to encode dead letter", zap.Any("deadLetter", deadLetter), zap.Error(err))
+		return
+	}
+
+	_, err = s.redisClient.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.LPush(s.listKey, string(bytes))
+		pipe.LTrim(s.listKey, 0, s.maxLength-1)
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("failed to record dead letter", zap.Any("deadLetter", deadLetter), zap.Error(err))
+		return
+	}
+
+	s.logger.Warn("task dead lettered", zap.String("id", deadLetter.Id), zap.String("taskName", taskName), zap.Int("attempts", attempts), zap.Error(taskErr))
+}
+
+func (s *deadLetterService) getAllWithValues() ([]*models.DeadLetter, []string, error) {
+	values, err := s.redisClient.LRange(s.listKey, 0, -1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deadLetters := make([]*models.DeadLetter, 0, len(values))
+	for _, value := range values {
+		var deadLetter models.DeadLetter
+		err := json.Unmarshal([]byte(value), &deadLetter)
+		if err != nil {
+			return nil, nil, err
+		}
+		deadLetters = append(deadLetters, &deadLetter)
+	}
+	return deadLetters, values, nil
+}
+
+func (s *deadLetterService) GetAll() ([]*models.DeadLetter, DeadLetterRetrievalResult) {
+	deadLetters, _, err := s.getAllWithValues()
+	if err != nil {
+		s.logger.Error("failed to retrieve dead letters", zap.Error(err))
+		return nil, DeadLetterRetrievalFailure
+	}
+	return deadLetters, DeadLetterRetrievalSuccess
+}
+
+/*
+	the dead letter is taken off the list before being sent, so that only one of concurrent replays sends it.
+	If it cannot be sent, it is put back.
+*/
+func (s *deadLetterService) Replay(id string) (*models.DeadLetter, DeadLetterReplayResult) {
+	deadLetters, values, err := s.getAllWithValues()
+	if err != nil {
+		s.logger.Error("failed to retrieve dead letters to replay", zap.String("id", id), zap.Error(err))
+		return nil, DeadLetterReplayFailure
+	}
+
+	index := -1
+	for i, deadLetter := range deadLetters {
+		if deadLetter.Id == id {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return nil, DeadLetterReplayNotFound
+	}
+	deadLetter := deadLetters[index]
+
+	removed, err := s.redisClient.LRem(s.listKey, 1, values[index]).Result()
+	if err != nil {
+		s.logger.Error("failed to take dead letter to replay", zap.String("id", id), zap.Error(err))
+		return nil, DeadLetterReplayFailure
+	}
+	if removed == 0 {
+		return nil, DeadLetterReplayNotFound
+	}
+
+	result := s.send(deadLetter)
+	if result != DeadLetterReplaySuccess {
+		err = s.redisClient.LPush(s.listKey, values[index]).Err()
+		if err != nil {
+			s.logger.Error("failed to put back dead letter that failed to replay", zap.Any("deadLetter", deadLetter), zap.Error(err))
+		}
+		return nil, result
+	}
+
+	s.logger.Info("dead letter replayed", zap.String("id", id), zap.String("taskName", deadLetter.TaskName))
+	return deadLetter, DeadLetterReplaySuccess
+}
+
+/*
+	the tasks of the instance worker only write their result while the instance is still pending, so they are sent
+	as they are. The ones that act on the provider are written to the outbox along with the change of status of
+	their instance, so that they do not act on an instance that went on meanwhile.
+*/
+func (s *deadLetterService) send(deadLetter *models.DeadLetter) DeadLetterReplayResult {
+	expected, ok := s.expectedStatus[deadLetter.TaskName]
+	if !ok {
+		signature := BuildTaskSignature(deadLetter.TaskName, deadLetter.Payload, deadLetter.OperationId, s.retryPolicies.For(deadLetter.TaskName))
+		_, err := s.machineryServer.SendTask(signature)
+		if err != nil {
+			s.logger.Error("failed to replay dead letter", zap.Any("deadLetter", deadLetter), zap.Error(err))
+			return DeadLetterReplayFailure
+		}
+		return DeadLetterReplaySuccess
+	}
+
+	// the payload is the instance itself, or the change being applied to it, which has the same name field
+	var payload models.Instance
+	err := json.Unmarshal([]byte(deadLetter.Payload), &payload)
+	if err != nil {
+		s.logger.Error("failed to unmarshal instance of dead letter to replay", zap.Any("deadLetter", deadLetter), zap.Error(err))
+		return DeadLetterReplayFailure
+	}
+
+	instance, resultGet := s.instanceService.GetByName(payload.Name)
+	if resultGet == InstanceRetrievalNotFound {
+		return DeadLetterReplayInstanceChanged
+	} else if resultGet != InstanceRetrievalSuccess {
+		return DeadLetterReplayFailure
+	}
+	if instance.Status != expected && instance.Status != models.InstanceStatusFailed {
+		return DeadLetterReplayInstanceChanged
+	}
+
+	task := &models.PendingTask{
+		Id:          uniuri.New(),
+		TaskName:    deadLetter.TaskName,
+		Payload:     deadLetter.Payload,
+		OperationId: deadLetter.OperationId,
+		CreatedAt:   time.Now().UTC(),
+	}
+	resultUpdate := s.instanceService.UpdateStatusWithTask(instance.Name, instance.Status, expected, "replay of failed task", task)
+	if resultUpdate == InstanceUpdateNotFound || resultUpdate == InstanceUpdateStatusChanged {
+		return DeadLetterReplayInstanceChanged
+	} else if resultUpdate != InstanceUpdateSuccess {
+		s.logger.Error("failed to write dead letter to replay along with its instance", zap.Any("deadLetter", deadLetter))
+		return DeadLetterReplayFailure
+	}
+	return DeadLetterReplaySuccess
+}
+
+func NewDeadLetterService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient, machineryServer *machinery.Server, instanceService InstanceService) DeadLetterService {
+	// the status each of the tasks that act on the provider finds its instance in
+	expectedStatus := map[string]models.InstanceStatus{
+		config.GetString("redis.pubsub.tasks.provision"):          models.InstanceStatusPending,
+		config.GetString("redis.pubsub.tasks.deprovision"):        models.InstanceStatusDeprovisioning,
+		config.GetString("redis.pubsub.tasks.update"):             models.InstanceStatusPending,
+		config.GetString("redis.pubsub.tasks.rotate_credentials"): models.InstanceStatusPending,
+		config.GetString("redis.pubsub.tasks.recreate"):           models.InstanceStatusPending,
+	}
+
+	return &deadLetterService{
+		logger:          logger.Named("deadLetterService"),
+		listKey:         config.GetString("redis.db.dead_letter.key"),
+		maxLength:       config.GetInt64("redis.db.dead_letter.max_length"),
+		retryPolicies:   NewTaskRetryPolicies(config),
+		expectedStatus:  expectedStatus,
+		redisClient:     redisClient,
+		machineryServer: machineryServer,
+		instanceService: instanceService,
+	}
+}
@@ -0,0 +1,213 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/RichardKnop/machinery/v1"
+	machineryConfig "github.com/RichardKnop/machinery/v1/config"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("DeadLetterService", func() {
+	var (
+		server          *miniredis.Miniredis
+		redisClient     *redis.Client
+		instanceService *mocks.InstanceServiceMock
+		service         services.DeadLetterService
+	)
+
+	instanceWith := func(status models.InstanceStatus) {
+		instanceService.GetByNameFunc = func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+			return &models.Instance{Name: name, Status: status}, services.InstanceRetrievalSuccess
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		server, err = miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		redisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+		url := "redis://" + server.Addr()
+		machineryServer, err := machinery.NewServer(&machineryConfig.Config{
+			Broker:        url,
+			DefaultQueue:  "machinery_tasks",
+			ResultBackend: url,
+			NoUnixSignals: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		config := viper.New()
+		config.Set("redis.db.dead_letter.key", "dead-letters")
+		config.Set("redis.db.dead_letter.max_length", 2)
+		config.Set("redis.pubsub.tasks.provision", "provision")
+		config.Set("redis.pubsub.tasks.update_instance", "update-instance")
+		config.Set("workers.retry.update_instance.max_retries", 3)
+		instanceService = &mocks.InstanceServiceMock{
+			UpdateStatusWithTaskFunc: func(name string, from models.InstanceStatus, status models.InstanceStatus, reason string, task *models.PendingTask) services.InstanceUpdateResult {
+				return services.InstanceUpdateSuccess
+			},
+		}
+		service = services.NewDeadLetterService(config, logger, redisClient, machineryServer, instanceService)
+	})
+
+	AfterEach(func() {
+		_ = redisClient.Close()
+		server.Close()
+	})
+
+	_ = Describe("Record", func() {
+		_ = It("keeps the most recent dead letters up to the maximum", func() {
+			// arrange
+			service.Record("provision", "payload-1", "", 4, errors.New("error-1"))
+			service.Record("provision", "payload-2", "operation-2", 4, errors.New("error-2"))
+
+			// act
+			service.Record("deprovision", "payload-3", "", 6, errors.New("error-3"))
+
+			// assert
+			deadLetters, result := service.GetAll()
+			Expect(result).To(Equal(services.DeadLetterRetrievalSuccess))
+			Expect(deadLetters).To(HaveLen(2))
+			Expect(deadLetters[0].TaskName).To(Equal("deprovision"))
+			Expect(deadLetters[0].Payload).To(Equal("payload-3"))
+			Expect(deadLetters[0].Error).To(Equal("error-3"))
+			Expect(deadLetters[0].Attempts).To(Equal(6))
+			Expect(deadLetters[0].Id).NotTo(BeEmpty())
+			Expect(deadLetters[0].FailedAt).NotTo(BeZero())
+			Expect(deadLetters[1].Payload).To(Equal("payload-2"))
+			Expect(deadLetters[1].OperationId).To(Equal("operation-2"))
+		})
+	})
+
+	_ = Describe("GetAll", func() {
+		_ = It("indicates when fails to retrieve", func() {
+			// arrange
+			server.Close()
+
+			// act
+			deadLetters, result := service.GetAll()
+
+			// assert
+			Expect(result).To(Equal(services.DeadLetterRetrievalFailure))
+			Expect(deadLetters).To(BeNil())
+		})
+	})
+
+	_ = Describe("Replay", func() {
+		_ = It("sends the task again with the retries of its policy and takes it off the dead letters", func() {
+			// arrange
+			service.Record("update-instance", "payload-1", "", 4, errors.New("error-1"))
+			deadLetters, _ := service.GetAll()
+
+			// act
+			deadLetter, result := service.Replay(deadLetters[0].Id)
+
+			// assert
+			Expect(result).To(Equal(services.DeadLetterReplaySuccess))
+			Expect(deadLetter.Payload).To(Equal("payload-1"))
+			remaining, _ := service.GetAll()
+			Expect(remaining).To(BeEmpty())
+			queued, err := server.List("machinery_tasks")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queued).To(HaveLen(1))
+			Expect(queued[0]).To(ContainSubstring(`"Name":"update-instance"`))
+			Expect(queued[0]).To(ContainSubstring(`"RetryCount":3`))
+			Expect(instanceService.UpdateStatusWithTaskCalls()).To(HaveLen(0))
+		})
+
+		_ = It("moves a failed instance back to the status a task on the provider expects, along with the task", func() {
+			// arrange
+			instanceWith(models.InstanceStatusFailed)
+			service.Record("provision", `{"name":"instance-1"}`, "operation-1", 4, errors.New("error-1"))
+			deadLetters, _ := service.GetAll()
+
+			// act
+			_, result := service.Replay(deadLetters[0].Id)
+
+			// assert
+			Expect(result).To(Equal(services.DeadLetterReplaySuccess))
+			updateCalls := instanceService.UpdateStatusWithTaskCalls()
+			Expect(updateCalls).To(HaveLen(1))
+			Expect(updateCalls[0].Name).To(Equal("instance-1"))
+			Expect(updateCalls[0].From).To(Equal(models.InstanceStatusFailed))
+			Expect(updateCalls[0].Status).To(Equal(models.InstanceStatusPending))
+			Expect(updateCalls[0].Task.TaskName).To(Equal("provision"))
+			Expect(updateCalls[0].Task.Payload).To(Equal(`{"name":"instance-1"}`))
+			Expect(updateCalls[0].Task.OperationId).To(Equal("operation-1"))
+			remaining, _ := service.GetAll()
+			Expect(remaining).To(BeEmpty())
+			queued, _ := server.List("machinery_tasks")
+			Expect(queued).To(BeEmpty())
+		})
+
+		_ = It("refuses a task on the provider whose instance is no longer in the status it expects, keeping it", func() {
+			// arrange
+			instanceWith(models.InstanceStatusRunning)
+			service.Record("provision", `{"name":"instance-1"}`, "operation-1", 4, errors.New("error-1"))
+			deadLetters, _ := service.GetAll()
+
+			// act
+			deadLetter, result := service.Replay(deadLetters[0].Id)
+
+			// assert
+			Expect(result).To(Equal(services.DeadLetterReplayInstanceChanged))
+			Expect(deadLetter).To(BeNil())
+			Expect(instanceService.UpdateStatusWithTaskCalls()).To(HaveLen(0))
+			remaining, _ := service.GetAll()
+			Expect(remaining).To(HaveLen(1))
+		})
+
+		_ = It("keeps the dead letter when its instance changes while it is replayed", func() {
+			// arrange
+			instanceWith(models.InstanceStatusPending)
+			instanceService.UpdateStatusWithTaskFunc = func(name string, from models.InstanceStatus, status models.InstanceStatus, reason string, task *models.PendingTask) services.InstanceUpdateResult {
+				return services.InstanceUpdateStatusChanged
+			}
+			service.Record("provision", `{"name":"instance-1"}`, "operation-1", 4, errors.New("error-1"))
+			deadLetters, _ := service.GetAll()
+
+			// act
+			_, result := service.Replay(deadLetters[0].Id)
+
+			// assert
+			Expect(result).To(Equal(services.DeadLetterReplayInstanceChanged))
+			remaining, _ := service.GetAll()
+			Expect(remaining).To(HaveLen(1))
+		})
+
+		_ = It("indicates when the dead letter is not found", func() {
+			// arrange
+			service.Record("provision", "payload-1", "", 4, errors.New("error-1"))
+
+			// act
+			deadLetter, result := service.Replay("unknown")
+
+			// assert
+			Expect(result).To(Equal(services.DeadLetterReplayNotFound))
+			Expect(deadLetter).To(BeNil())
+			remaining, _ := service.GetAll()
+			Expect(remaining).To(HaveLen(1))
+		})
+
+		_ = It("indicates when fails to replay", func() {
+			// arrange
+			server.Close()
+
+			// act
+			deadLetter, result := service.Replay("some-id")
+
+			// assert
+			Expect(result).To(Equal(services.DeadLetterReplayFailure))
+			Expect(deadLetter).To(BeNil())
+		})
+	})
+})
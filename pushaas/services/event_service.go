@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+type (
+	/*
+		Publishes the events of the instances on redis pub/sub, one channel per instance, so that the events
+		published by the workers reach the followers connected to any replica of the api.
+	*/
+	EventService interface {
+		Publish(event *models.InstanceEvent)
+		Subscribe(instanceName string, done <-chan struct{}) (<-chan *models.InstanceEvent, error)
+	}
+
+	eventService struct {
+		logger        *zap.Logger
+		channelPrefix string
+		redisClient   redis.UniversalClient
+	}
+)
+
+// how many events a slow follower may lag behind before the subscription stops reading from redis
+const eventBufferSize = 16
+
+func (s *eventService) channel(instanceName string) string {
+	return fmt.Sprintf("%s:%s", s.channelPrefix, instanceName)
+}
+
+/*
+	the events are a side effect of what the caller did, so a failure to publish is only logged
+*/
+func (s *eventService) Publish(event *models.InstanceEvent) {
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("failed to encode instance event", zap.Any("event", event), zap.Error(err))
+		return
+	}
+
+	err = s.redisClient.Publish(s.channel(event.Instance), string(bytes)).Err()
+	if err != nil {
+		s.logger.Error("failed to publish instance event", zap.Any("event", event), zap.Error(err))
+	}
+}
+
+/*
+	Follows the events of the instance until done is closed, when the returned channel is closed. The subscription
+	is confirmed before returning, so no event published afterwards is missed.
+*/
+func (s *eventService) Subscribe(instanceName string, done <-chan struct{}) (<-chan *models.InstanceEvent, error) {
+	pubSub := s.redisClient.Subscribe(s.channel(instanceName))
+	_, err := pubSub.Receive()
+	if err != nil {
+		s.logger.Error("failed to subscribe to instance events", zap.String("instanceName", instanceName), zap.Error(err))
+		_ = pubSub.Close()
+		return nil, err
+	}
+
+	events := make(chan *models.InstanceEvent, eventBufferSize)
+	go func() {
+		defer close(events)
+		defer pubSub.Close()
+
+		messages := pubSub.Channel()
+		for {
+			select {
+			case <-done:
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event models.InstanceEvent
+				err := json.Unmarshal([]byte(message.Payload), &event)
+				if err != nil {
+					s.logger.Error("failed to decode instance event", zap.String("payload", message.Payload), zap.Error(err))
+					continue
+				}
+
+				select {
+				case events <- &event:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func NewEventService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) EventService {
+	return &eventService{
+		logger:        logger.Named("eventService"),
+		channelPrefix: config.GetString("redis.pubsub.events.prefix"),
+		redisClient:   redisClient,
+	}
+}
@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("EventService", func() {
+	var (
+		server      *miniredis.Miniredis
+		redisClient *redis.Client
+		service     services.EventService
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		redisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+		config := viper.New()
+		config.Set("redis.pubsub.events.prefix", "instance-events")
+		service = services.NewEventService(config, logger, redisClient)
+	})
+
+	AfterEach(func() {
+		_ = redisClient.Close()
+		server.Close()
+	})
+
+	_ = Describe("Subscribe", func() {
+		_ = It("receives the events published to the instance", func() {
+			// arrange
+			done := make(chan struct{})
+			defer close(done)
+			events, err := service.Subscribe("instance-1", done)
+			Expect(err).NotTo(HaveOccurred())
+
+			// act
+			service.Publish(&models.InstanceEvent{Instance: "instance-2", Type: models.InstanceEventAppBound, App: "app-1"})
+			service.Publish(&models.InstanceEvent{Instance: "instance-1", Type: models.InstanceEventStatusChanged, Status: models.InstanceStatusRunning})
+
+			// assert
+			var event *models.InstanceEvent
+			Eventually(events, time.Second).Should(Receive(&event))
+			Expect(event.Instance).To(Equal("instance-1"))
+			Expect(event.Type).To(Equal(models.InstanceEventStatusChanged))
+			Expect(event.Status).To(Equal(models.InstanceStatusRunning))
+			Expect(event.At).NotTo(BeZero())
+			Consistently(events, 100*time.Millisecond).ShouldNot(Receive())
+		})
+
+		_ = It("closes the events when done", func() {
+			// arrange
+			done := make(chan struct{})
+			events, err := service.Subscribe("instance-1", done)
+			Expect(err).NotTo(HaveOccurred())
+
+			// act
+			close(done)
+
+			// assert
+			Eventually(events, time.Second).Should(BeClosed())
+		})
+
+		_ = It("indicates when fails to subscribe", func() {
+			// arrange
+			server.Close()
+
+			// act
+			events, err := service.Subscribe("instance-1", make(chan struct{}))
+
+			// assert
+			Expect(err).To(HaveOccurred())
+			Expect(events).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+)
+
+type (
+	GcResult int
+
+	GcService interface {
+		Collect(dryRun bool) (*models.GcReport, GcResult)
+	}
+
+	/*
+		Finds the resources on the provider that belong to instances not known anymore (e.g. left behind by a failed
+		deprovision), or to failed instances whose resources were not rolled back (e.g. given up on while pending),
+		and deletes them, or only reports them on a dry run.
+	*/
+	gcService struct {
+		logger          *zap.Logger
+		gracePeriod     time.Duration
+		instanceService InstanceService
+		collector       provisioners.PushServiceResourceCollector
+	}
+)
+
+const (
+	GcSuccess GcResult = iota
+	GcFailure
+	GcNotSupported
+)
+
+/*
+	a failed instance keeps nothing on the provider, so whatever was not rolled back is left over
+*/
+func ownsResources(instance *models.Instance) bool {
+	return instance.Status != models.InstanceStatusFailed || instance.Rollback == models.InstanceRollbackCompleted
+}
+
+func (s *gcService) isRecent(resource *provisioners.PushServiceResource, now time.Time) bool {
+	return resource.CreatedAt != nil && now.Sub(*resource.CreatedAt) < s.gracePeriod
+}
+
+/*
+	the resources are listed before the instances, so the ones of an instance created in between are not taken as
+	orphans. The resources younger than the grace period are also spared, as their instance may still be on its way.
+*/
+func (s *gcService) Collect(dryRun bool) (*models.GcReport, GcResult) {
+	if s.collector == nil {
+		return nil, GcNotSupported
+	}
+
+	resources, err := s.collector.ListResources()
+	if err != nil {
+		s.logger.Error("failed to list provider resources to collect orphans", zap.Error(err))
+		return nil, GcFailure
+	}
+
+	instances, resultGetAll := s.instanceService.GetAll()
+	if resultGetAll == InstanceRetrievalFailure {
+		s.logger.Error("failed to retrieve instances to collect orphans")
+		return nil, GcFailure
+	}
+
+	knownInstances := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		knownInstances[instance.Name] = ownsResources(instance)
+	}
+
+	report := &models.GcReport{
+		DryRun:  dryRun,
+		Orphans: []*models.GcOrphan{},
+	}
+	now := time.Now()
+	for _, resource := range resources {
+		if knownInstances[resource.InstanceName] {
+			continue
+		}
+		if s.isRecent(resource, now) {
+			s.logger.Info("skipping recent resource of unknown instance", zap.Any("resource", resource))
+			continue
+		}
+
+		orphan := &models.GcOrphan{
+			Kind:         resource.Kind,
+			Name:         resource.Name,
+			InstanceName: resource.InstanceName,
+		}
+		report.Orphans = append(report.Orphans, orphan)
+
+		if dryRun {
+			s.logger.Info("found orphan resource", zap.Any("orphan", orphan))
+			continue
+		}
+
+		err = s.collector.DeleteResource(resource)
+		if err != nil {
+			s.logger.Error("failed to delete orphan resource", zap.Any("orphan", orphan), zap.Error(err))
+			orphan.Error = err.Error()
+			continue
+		}
+		orphan.Deleted = true
+		s.logger.Info("deleted orphan resource", zap.Any("orphan", orphan))
+	}
+
+	return report, GcSuccess
+}
+
+/*
+	collector is nil when the provider is not able to list its resources
+*/
+func NewGcService(config *viper.Viper, logger *zap.Logger, instanceService InstanceService, collector provisioners.PushServiceResourceCollector) GcService {
+	return &gcService{
+		logger:          logger.Named("gcService"),
+		gracePeriod:     config.GetDuration("workers.gc.grace_period"),
+		instanceService: instanceService,
+		collector:       collector,
+	}
+}
@@ -0,0 +1,227 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("GcService", func() {
+	config := viper.New()
+
+	newInstanceService := func() *mocks.InstanceServiceMock {
+		return &mocks.InstanceServiceMock{
+			GetAllFunc: func() ([]*models.Instance, services.InstanceRetrievalResult) {
+				return []*models.Instance{{Name: "instance-1"}}, services.InstanceRetrievalSuccess
+			},
+		}
+	}
+
+	newCollector := func(deleteErr error) *mocks.PushServiceResourceCollectorMock {
+		return &mocks.PushServiceResourceCollectorMock{
+			ListResourcesFunc: func() ([]*provisioners.PushServiceResource, error) {
+				return []*provisioners.PushServiceResource{
+					{Kind: "ecs-service", Name: "push-api-instance-1", InstanceName: "instance-1"},
+					{Kind: "ecs-service", Name: "push-api-instance-2", InstanceName: "instance-2"},
+					{Kind: "task-definition", Name: "push-api-instance-2", InstanceName: "instance-2"},
+				}, nil
+			},
+			DeleteResourceFunc: func(resource *provisioners.PushServiceResource) error {
+				return deleteErr
+			},
+		}
+	}
+
+	Describe("Collect", func() {
+		It("indicates when the provider does not support it", func() {
+			// arrange
+			gcService := services.NewGcService(config, logger, newInstanceService(), nil)
+
+			// act
+			report, result := gcService.Collect(true)
+
+			// assert
+			Expect(result).To(Equal(services.GcNotSupported))
+			Expect(report).To(BeNil())
+		})
+
+		It("indicates when fails to retrieve instances", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				GetAllFunc: func() ([]*models.Instance, services.InstanceRetrievalResult) {
+					return nil, services.InstanceRetrievalFailure
+				},
+			}
+			collector := newCollector(nil)
+			gcService := services.NewGcService(config, logger, instanceService, collector)
+
+			// act
+			_, result := gcService.Collect(true)
+
+			// assert
+			Expect(result).To(Equal(services.GcFailure))
+			Expect(collector.DeleteResourceCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when fails to list resources, before retrieving the instances", func() {
+			// arrange
+			instanceService := newInstanceService()
+			collector := &mocks.PushServiceResourceCollectorMock{
+				ListResourcesFunc: func() ([]*provisioners.PushServiceResource, error) {
+					return nil, errors.New("some error")
+				},
+			}
+			gcService := services.NewGcService(config, logger, instanceService, collector)
+
+			// act
+			_, result := gcService.Collect(true)
+
+			// assert
+			Expect(result).To(Equal(services.GcFailure))
+			Expect(instanceService.GetAllCalls()).To(HaveLen(0))
+		})
+
+		It("does not take the resources of an instance created while listing them as orphans", func() {
+			// arrange
+			var instances []*models.Instance
+			instanceService := &mocks.InstanceServiceMock{
+				GetAllFunc: func() ([]*models.Instance, services.InstanceRetrievalResult) {
+					return instances, services.InstanceRetrievalSuccess
+				},
+			}
+			collector := &mocks.PushServiceResourceCollectorMock{
+				ListResourcesFunc: func() ([]*provisioners.PushServiceResource, error) {
+					instances = []*models.Instance{{Name: "instance-2"}}
+					return []*provisioners.PushServiceResource{
+						{Kind: "ecs-service", Name: "push-api-instance-2", InstanceName: "instance-2"},
+					}, nil
+				},
+			}
+			gcService := services.NewGcService(config, logger, instanceService, collector)
+
+			// act
+			report, result := gcService.Collect(false)
+
+			// assert
+			Expect(result).To(Equal(services.GcSuccess))
+			Expect(report.Orphans).To(BeEmpty())
+			Expect(collector.DeleteResourceCalls()).To(HaveLen(0))
+		})
+
+		It("spares the resources younger than the grace period", func() {
+			// arrange
+			graceConfig := viper.New()
+			graceConfig.Set("workers.gc.grace_period", "15m")
+			recent := time.Now().Add(-time.Minute)
+			old := time.Now().Add(-time.Hour)
+			collector := &mocks.PushServiceResourceCollectorMock{
+				ListResourcesFunc: func() ([]*provisioners.PushServiceResource, error) {
+					return []*provisioners.PushServiceResource{
+						{Kind: "ecs-service", Name: "push-api-instance-2", InstanceName: "instance-2", CreatedAt: &recent},
+						{Kind: "ecs-service", Name: "push-api-instance-3", InstanceName: "instance-3", CreatedAt: &old},
+					}, nil
+				},
+				DeleteResourceFunc: func(resource *provisioners.PushServiceResource) error {
+					return nil
+				},
+			}
+			gcService := services.NewGcService(graceConfig, logger, newInstanceService(), collector)
+
+			// act
+			report, result := gcService.Collect(false)
+
+			// assert
+			Expect(result).To(Equal(services.GcSuccess))
+			Expect(report.Orphans).To(HaveLen(1))
+			Expect(report.Orphans[0].InstanceName).To(Equal("instance-3"))
+			calls := collector.DeleteResourceCalls()
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Resource.InstanceName).To(Equal("instance-3"))
+		})
+
+		It("only reports the orphans on dry run", func() {
+			// arrange
+			collector := newCollector(nil)
+			gcService := services.NewGcService(config, logger, newInstanceService(), collector)
+
+			// act
+			report, result := gcService.Collect(true)
+
+			// assert
+			Expect(result).To(Equal(services.GcSuccess))
+			Expect(report).To(Equal(&models.GcReport{
+				DryRun: true,
+				Orphans: []*models.GcOrphan{
+					{Kind: "ecs-service", Name: "push-api-instance-2", InstanceName: "instance-2"},
+					{Kind: "task-definition", Name: "push-api-instance-2", InstanceName: "instance-2"},
+				},
+			}))
+			Expect(collector.DeleteResourceCalls()).To(HaveLen(0))
+		})
+
+		It("deletes the orphans, in the order they were listed", func() {
+			// arrange
+			collector := newCollector(nil)
+			gcService := services.NewGcService(config, logger, newInstanceService(), collector)
+
+			// act
+			report, result := gcService.Collect(false)
+
+			// assert
+			Expect(result).To(Equal(services.GcSuccess))
+			Expect(report.Orphans).To(HaveLen(2))
+			Expect(report.Orphans[0].Deleted).To(BeTrue())
+			Expect(report.Orphans[1].Deleted).To(BeTrue())
+			calls := collector.DeleteResourceCalls()
+			Expect(calls).To(HaveLen(2))
+			Expect(calls[0].Resource.Kind).To(Equal("ecs-service"))
+			Expect(calls[1].Resource.Kind).To(Equal("task-definition"))
+		})
+
+		It("reports the orphans that failed to be deleted", func() {
+			// arrange
+			collector := newCollector(errors.New("some error"))
+			gcService := services.NewGcService(config, logger, newInstanceService(), collector)
+
+			// act
+			report, result := gcService.Collect(false)
+
+			// assert
+			Expect(result).To(Equal(services.GcSuccess))
+			Expect(report.Orphans[0].Deleted).To(BeFalse())
+			Expect(report.Orphans[0].Error).To(Equal("some error"))
+		})
+
+		It("collects the resources of a failed instance that were not rolled back", func() {
+			// arrange
+			instanceService := &mocks.InstanceServiceMock{
+				GetAllFunc: func() ([]*models.Instance, services.InstanceRetrievalResult) {
+					return []*models.Instance{
+						{Name: "instance-1", Status: models.InstanceStatusFailed, Rollback: models.InstanceRollbackCompleted},
+						{Name: "instance-2", Status: models.InstanceStatusFailed},
+					}, services.InstanceRetrievalSuccess
+				},
+			}
+			collector := newCollector(nil)
+			gcService := services.NewGcService(config, logger, instanceService, collector)
+
+			// act
+			report, result := gcService.Collect(true)
+
+			// assert
+			Expect(result).To(Equal(services.GcSuccess))
+			Expect(report.Orphans).To(Equal([]*models.GcOrphan{
+				{Kind: "ecs-service", Name: "push-api-instance-2", InstanceName: "instance-2"},
+				{Kind: "task-definition", Name: "push-api-instance-2", InstanceName: "instance-2"},
+			}))
+		})
+	})
+})
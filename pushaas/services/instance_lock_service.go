@@ -0,0 +1,159 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/go-redis/redis"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+type (
+	InstanceLockResult int
+
+	/*
+		Keeps the lifecycle operations of an instance from running at the same time, through a lease on redis
+		keyed by the name of the instance. The lease expires on its own if its holder goes away, and is renewed
+		for as long as it is held, so that the long waits of the provisioners do not outlive it.
+	*/
+	InstanceLockService interface {
+		Acquire(instanceName string) (InstanceLease, InstanceLockResult)
+		IsHeld(instanceName string) (bool, error)
+	}
+
+	InstanceLease interface {
+		Release()
+	}
+
+	instanceLockService struct {
+		logger        *zap.Logger
+		prefix        string
+		ttl           time.Duration
+		renewInterval time.Duration
+		redisClient   redis.UniversalClient
+	}
+
+	instanceLease struct {
+		service   *instanceLockService
+		key       string
+		token     string
+		done      chan struct{}
+		stopped   chan struct{}
+		closeOnce sync.Once
+	}
+)
+
+const (
+	InstanceLockAcquired InstanceLockResult = iota
+	InstanceLockBusy
+	InstanceLockFailure
+)
+
+// the lease is only renewed or released by who holds it, which is told by its token
+var (
+	renewLeaseScript = redis.NewScript(`
+		if redis.call("get", KEYS[1]) == ARGV[1] then
+			return redis.call("pexpire", KEYS[1], ARGV[2])
+		end
+		return 0
+	`)
+	releaseLeaseScript = redis.NewScript(`
+		if redis.call("get", KEYS[1]) == ARGV[1] then
+			return redis.call("del", KEYS[1])
+		end
+		return 0
+	`)
+)
+
+func (s *instanceLockService) keyFor(instanceName string) string {
+	return fmt.Sprintf("%s:%s", s.prefix, instanceName)
+}
+
+func (s *instanceLockService) Acquire(instanceName string) (InstanceLease, InstanceLockResult) {
+	key := s.keyFor(instanceName)
+	token := uniuri.New()
+
+	acquired, err := s.redisClient.SetNX(key, token, s.ttl).Result()
+	if err != nil {
+		s.logger.Error("failed to acquire instance lease", zap.String("name", instanceName), zap.Error(err))
+		return nil, InstanceLockFailure
+	}
+	if !acquired {
+		return nil, InstanceLockBusy
+	}
+
+	lease := &instanceLease{
+		service: s,
+		key:     key,
+		token:   token,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go lease.keepRenewed()
+	return lease, InstanceLockAcquired
+}
+
+func (s *instanceLockService) IsHeld(instanceName string) (bool, error) {
+	count, err := s.redisClient.Exists(s.keyFor(instanceName)).Result()
+	if err != nil {
+		s.logger.Error("failed to check instance lease", zap.String("name", instanceName), zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+/*
+	a lease that could not be renewed is only logged, the operation that holds it goes on
+*/
+func (l *instanceLease) keepRenewed() {
+	defer close(l.stopped)
+
+	ticker := time.NewTicker(l.service.renewInterval)
+	defer ticker.Stop()
+
+	ttl := int64(l.service.ttl / time.Millisecond)
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			renewed, err := renewLeaseScript.Run(l.service.redisClient, []string{l.key}, l.token, ttl).Int64()
+			if err != nil {
+				l.service.logger.Error("failed to renew instance lease", zap.String("key", l.key), zap.Error(err))
+				continue
+			}
+			if renewed == 0 {
+				l.service.logger.Error("instance lease was lost", zap.String("key", l.key))
+				return
+			}
+		}
+	}
+}
+
+func (l *instanceLease) Release() {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		<-l.stopped
+
+		err := releaseLeaseScript.Run(l.service.redisClient, []string{l.key}, l.token).Err()
+		if err != nil {
+			// it expires on its own anyway
+			l.service.logger.Error("failed to release instance lease", zap.String("key", l.key), zap.Error(err))
+		}
+	})
+}
+
+func NewInstanceLockService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) InstanceLockService {
+	ttl := config.GetDuration("redis.db.instance_lock.ttl")
+
+	return &instanceLockService{
+		logger:        logger.Named("instanceLockService"),
+		prefix:        config.GetString("redis.db.instance_lock.prefix"),
+		ttl:           ttl,
+		renewInterval: ttl / 3,
+		redisClient:   redisClient,
+	}
+}
@@ -0,0 +1,112 @@
+package services_test
+
+import (
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("InstanceLockService", func() {
+	var (
+		server      *miniredis.Miniredis
+		redisClient *redis.Client
+		service     services.InstanceLockService
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		redisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+		config := viper.New()
+		config.Set("redis.db.instance_lock.prefix", "instance-lock")
+		config.Set("redis.db.instance_lock.ttl", "150ms")
+		service = services.NewInstanceLockService(config, logger, redisClient)
+	})
+
+	AfterEach(func() {
+		_ = redisClient.Close()
+		server.Close()
+	})
+
+	_ = Describe("Acquire", func() {
+		_ = It("holds the lease until released", func() {
+			// arrange
+			lease, result := service.Acquire("instance-1")
+			Expect(result).To(Equal(services.InstanceLockAcquired))
+
+			// act
+			held, err := service.IsHeld("instance-1")
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(held).To(BeTrue())
+			lease.Release()
+			held, _ = service.IsHeld("instance-1")
+			Expect(held).To(BeFalse())
+		})
+
+		_ = It("indicates when the instance is busy", func() {
+			// arrange
+			lease, _ := service.Acquire("instance-1")
+			defer lease.Release()
+
+			// act
+			other, result := service.Acquire("instance-1")
+
+			// assert
+			Expect(result).To(Equal(services.InstanceLockBusy))
+			Expect(other).To(BeNil())
+			_, result = service.Acquire("instance-2")
+			Expect(result).To(Equal(services.InstanceLockAcquired))
+		})
+
+		_ = It("renews the lease while it is held", func() {
+			// arrange
+			lease, _ := service.Acquire("instance-1")
+			defer lease.Release()
+
+			// act
+			server.FastForward(100 * time.Millisecond)
+
+			// assert
+			Eventually(func() time.Duration {
+				return server.TTL("instance-lock:instance-1")
+			}, time.Second, 10*time.Millisecond).Should(Equal(150 * time.Millisecond))
+		})
+
+		_ = It("does not release the lease of another holder", func() {
+			// arrange
+			lease, _ := service.Acquire("instance-1")
+			server.Del("instance-lock:instance-1")
+			other, _ := service.Acquire("instance-1")
+			defer other.Release()
+
+			// act
+			lease.Release()
+
+			// assert
+			held, _ := service.IsHeld("instance-1")
+			Expect(held).To(BeTrue())
+		})
+
+		_ = It("indicates when fails to acquire", func() {
+			// arrange
+			server.Close()
+
+			// act
+			lease, result := service.Acquire("instance-1")
+
+			// assert
+			Expect(result).To(Equal(services.InstanceLockFailure))
+			Expect(lease).To(BeNil())
+		})
+	})
+})
@@ -2,14 +2,15 @@ package services
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
-	"github.com/fatih/structs"
-	"github.com/go-redis/redis"
-	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
 	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/repositories"
 )
 
 type (
@@ -20,23 +21,37 @@ type (
 	InstanceUpdateResult    int
 
 	InstanceService interface {
-		Create(instanceForm *models.InstanceForm) InstanceCreationResult
+		Create(instanceForm *models.InstanceForm) (*models.Operation, InstanceCreationResult)
 		GetAll() ([]*models.Instance, InstanceRetrievalResult)
+		List(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, InstanceRetrievalResult)
 		GetByName(name string) (*models.Instance, InstanceRetrievalResult)
-		Delete(name string) InstanceDeletionResult
-		UpdateStatus(name string, status models.InstanceStatus) InstanceUpdateResult
+		Update(name string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, InstanceUpdateResult)
+		Delete(name string, force bool) ([]string, *models.Operation, InstanceDeletionResult)
+		Recreate(instance *models.Instance, reason string) (*models.Operation, InstanceUpdateResult)
+		Remove(name string) InstanceDeletionResult
+		UpdateStatus(name string, status models.InstanceStatus, reason string) InstanceUpdateResult
+		UpdateStatusFrom(name string, from, status models.InstanceStatus, reason string) InstanceUpdateResult
+		UpdateStatusWithTask(name string, from, status models.InstanceStatus, reason string, task *models.PendingTask) InstanceUpdateResult
+		RevertChange(name string, previous *models.InstancePrevious, reason string, vars map[string]string) InstanceUpdateResult
+		FinishPending(name string, status models.InstanceStatus, reason string, rollback *models.InstanceRollback, vars map[string]string) InstanceUpdateResult
+		UpdateRollback(name string, rollback models.InstanceRollback) InstanceUpdateResult
+		UpdateResources(name string, resources map[string]string) InstanceUpdateResult
 		GetStatusByName(name string) InstanceStatusResult
+		GetStatusHistory(name string) ([]*models.InstanceStatusTransition, InstanceRetrievalResult)
+		GetInfo(name string) ([]*models.InstanceInfoItem, InstanceRetrievalResult)
 		GetInstanceVars(name string) (map[string]string, error)
-		SetInstanceVars(name string, envVars map[string]string) (string, error)
-		DelInstanceVars(name string) (int64, error)
+		SetInstanceVars(name string, envVars map[string]string) error
+		DelInstanceVars(name string) error
+		IsBusy(name string) (bool, error)
 	}
 
 	instanceService struct {
-		instanceKeyPrefix     string
-		instanceVarsKeyPrefix string
-		logger                *zap.Logger
-		provisionService      ProvisionService
-		redisClient           redis.UniversalClient
+		logger             *zap.Logger
+		instanceRepository repositories.InstanceRepository
+		bindRepository     repositories.BindRepository
+		planService        PlanService
+		provisionService   ProvisionService
+		lockService        InstanceLockService
 	}
 )
 
@@ -44,6 +59,13 @@ const (
 	InstanceRetrievalSuccess InstanceRetrievalResult = iota
 	InstanceRetrievalNotFound
 	InstanceRetrievalFailure
+	InstanceRetrievalInvalidCursor
+)
+
+// how many instances are listed in a page when not told, and at most
+const (
+	instanceListDefaultLimit = 50
+	instanceListMaxLimit     = 500
 )
 
 const (
@@ -59,11 +81,19 @@ const (
 	InstanceDeletionNotFound
 	InstanceDeletionFailure
 	InstanceDeletionDeprovisionFailure
+	InstanceDeletionHasBindings
+	InstanceDeletionBusy
 )
 
 const (
 	InstanceUpdateSuccess InstanceUpdateResult = iota
 	InstanceUpdateFailure
+	InstanceUpdateNotFound
+	InstanceUpdateInvalidData
+	InstanceUpdateNotRunning
+	InstanceUpdateDispatchUpdateFailure
+	InstanceUpdateBusy
+	InstanceUpdateStatusChanged
 )
 
 const (
@@ -73,6 +103,7 @@ const (
 	InstanceStatusRunningStatus
 	InstanceStatusPendingStatus
 	InstanceStatusFailedStatus
+	InstanceStatusDegradedStatus
 )
 
 /*
@@ -80,199 +111,320 @@ const (
 	instances
 	===========================================================================
 */
-func (s *instanceService) instanceKey(instanceName string) string {
-	return fmt.Sprintf("%s:%s", s.instanceKeyPrefix, instanceName)
-}
-
 func (s *instanceService) GetAll() ([]*models.Instance, InstanceRetrievalResult) {
-	var err error
-	patternAllInstanceKeys := s.instanceKey("*")
-
-	// get keys
-	keys, err := s.redisClient.Keys(patternAllInstanceKeys).Result()
+	instances, err := s.instanceRepository.GetAll()
 	if err != nil {
-		s.logger.Error("failed to retrieve instance keys", zap.Error(err), zap.String("patternAllInstanceKeys", patternAllInstanceKeys))
+		s.logger.Error("failed to retrieve instances", zap.Error(err))
 		return nil, InstanceRetrievalFailure
 	}
-	if len(keys) == 0 {
+	if len(instances) == 0 {
 		return nil, InstanceRetrievalNotFound
 	}
 
-	//s.logger.Info("TODO", zap.Any("keys", keys))
-	//return nil, InstanceRetrievalSuccess
-
-	// init pipeline
-	pipeline := s.redisClient.Pipeline()
-	defer func() {
-		err := pipeline.Close()
-		if err != nil {
-			s.logger.Error("failed to close pipeline", zap.Error(err))
-		}
-	}()
+	return instances, InstanceRetrievalSuccess
+}
 
-	// fill pipeline commands
-	for _, key := range keys {
-		pipeline.HGetAll(key)
+func (s *instanceService) List(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, InstanceRetrievalResult) {
+	if limit <= 0 {
+		limit = instanceListDefaultLimit
+	} else if limit > instanceListMaxLimit {
+		limit = instanceListMaxLimit
 	}
 
-	// exec pipeline
-	results, err := pipeline.Exec()
-	if err != nil {
-		s.logger.Error("failed to execute pipeline to retrieve instances", zap.Error(err))
+	page, err := s.instanceRepository.List(filter, cursor, limit)
+	if err == repositories.ErrInvalidCursor {
+		return nil, InstanceRetrievalInvalidCursor
+	} else if err != nil {
+		s.logger.Error("failed to list instances", zap.Any("filter", filter), zap.String("cursor", cursor), zap.Error(err))
 		return nil, InstanceRetrievalFailure
 	}
 
-	instances := make([]*models.Instance, len(keys))
-	for i, cmd := range results {
-		mapCmd := cmd.(*redis.StringStringMapCmd)
-		instanceMap, err := mapCmd.Result()
-		if err != nil {
-			s.logger.Error("failed to retrieve instance", zap.Error(err), zap.String("key", keys[i]))
-			return nil, InstanceRetrievalFailure
-		}
-		if len(instanceMap) == 0 {
-			return nil, InstanceRetrievalNotFound
-		}
-
-		// decode
-		var instance models.Instance
-		err = mapstructure.Decode(instanceMap, &instance)
-		if err != nil {
-			s.logger.Error("failed to decode instance", zap.Error(err), zap.String("key", keys[i]))
-			return nil, InstanceRetrievalFailure
-		}
-
-		instances[i] = &instance
-	}
-
-	return instances, InstanceRetrievalSuccess
+	return page, InstanceRetrievalSuccess
 }
 
 func (s *instanceService) GetByName(instanceName string) (*models.Instance, InstanceRetrievalResult) {
-	var err error
-	instanceKey := s.instanceKey(instanceName)
-
-	// retrieve
-	cmd := s.redisClient.HGetAll(instanceKey)
-	instanceMap, err := cmd.Result()
-	if err != nil {
-		s.logger.Error("failed to retrieve instance", zap.Error(err), zap.String("instanceName", instanceName))
-		return nil, InstanceRetrievalFailure
-	}
-	if len(instanceMap) == 0 {
+	instance, err := s.instanceRepository.Get(instanceName)
+	if err == repositories.ErrNotFound {
 		return nil, InstanceRetrievalNotFound
-	}
-
-	// decode
-	var instance models.Instance
-	err = mapstructure.Decode(instanceMap, &instance)
-	if err != nil {
-		s.logger.Error("failed to decode instance", zap.Error(err), zap.String("instanceName", instanceName))
+	} else if err != nil {
+		s.logger.Error("failed to retrieve instance", zap.String("instanceName", instanceName), zap.Error(err))
 		return nil, InstanceRetrievalFailure
 	}
 
-	return &instance, InstanceRetrievalSuccess
+	return instance, InstanceRetrievalSuccess
 }
 
-func (s *instanceService) doCreate(instance *models.Instance) InstanceCreationResult {
-	instanceKey := s.instanceKey(instance.Name)
-	instanceMap := structs.Map(instance)
-
-	err := s.redisClient.HMSet(instanceKey, instanceMap).Err()
-	if err != nil {
-		s.logger.Error("failed to create instance", zap.Error(err), zap.Any("instance", instance))
-		return InstanceCreationFailure
-	}
-	return InstanceCreationSuccess
-}
-
-func (s *instanceService) Create(instanceForm *models.InstanceForm) InstanceCreationResult {
+/*
+	the provision is written along with the instance, to be sent by the relay, so that an instance is never kept
+	without its provision on the way
+*/
+func (s *instanceService) Create(instanceForm *models.InstanceForm) (*models.Operation, InstanceCreationResult) {
 	instanceName := instanceForm.Name
 
 	// check existing
 	_, resultGet := s.GetByName(instanceName)
 	if resultGet == InstanceRetrievalSuccess {
-		return InstanceCreationAlreadyExist
+		return nil, InstanceCreationAlreadyExist
 	} else if resultGet == InstanceRetrievalFailure {
-		return InstanceCreationFailure
+		return nil, InstanceCreationFailure
 	}
 
 	// validate
 	validationResult := instanceForm.Validate()
 	if validationResult == models.InstanceFormInvalid {
-		return InstanceCreationInvalidData
+		return nil, InstanceCreationInvalidData
+	}
+	if _, resultPlan := s.planService.GetByName(instanceForm.Plan); resultPlan == PlanRetrievalNotFound {
+		return nil, InstanceCreationInvalidData
 	}
 
 	instance := models.InstanceFromInstanceForm(instanceForm)
 	instance.Status = models.InstanceStatusPending
 
-	// create
-	resultCreate := s.doCreate(instance)
-	if resultCreate != InstanceCreationSuccess {
-		return resultCreate
+	// prepare provision
+	operation, task, prepareProvisionResult := s.provisionService.PrepareProvision(instance)
+	if prepareProvisionResult != DispatchProvisionResultSuccess {
+		s.logger.Error("failed to prepare provision", zap.Any("instance", instance))
+		return nil, InstanceCreationProvisionFailure
 	}
 
-	// dispatch provision
-	dispatchProvisionResult := s.provisionService.DispatchProvision(instance)
-	if dispatchProvisionResult != DispatchProvisionResultSuccess {
-		s.logger.Error("failed to dispatch provision", zap.Any("instance", instance))
-		return InstanceCreationProvisionFailure
+	// create
+	err := s.instanceRepository.CreateWithTask(instance, task)
+	if err == repositories.ErrAlreadyExists {
+		s.provisionService.AbandonOperation(operation, "an instance with this name already exists")
+		return nil, InstanceCreationAlreadyExist
+	} else if err != nil {
+		s.provisionService.AbandonOperation(operation, "failed to create the instance")
+		return nil, InstanceCreationFailure
 	}
 
-	return InstanceCreationSuccess
+	return operation, InstanceCreationSuccess
 }
 
-func (s *instanceService) doDelete(instance *models.Instance) InstanceDeletionResult {
-	instanceKey := s.instanceKey(instance.Name)
+/*
+	team and description are only recorded, while a plan change is applied to the provider by an update task,
+	during which the instance is pending. The update task is written along with the plan change, to be sent by
+	the relay. Only a plan change has an operation to follow.
+*/
+func (s *instanceService) Update(instanceName string, instanceUpdateForm *models.InstanceUpdateForm) (*models.Operation, InstanceUpdateResult) {
+	// validate
+	if instanceUpdateForm.Plan != "" {
+		if _, resultPlan := s.planService.GetByName(instanceUpdateForm.Plan); resultPlan == PlanRetrievalNotFound {
+			return nil, InstanceUpdateInvalidData
+		}
+	}
 
-	value, err := s.redisClient.Del(instanceKey).Result()
-	if err != nil {
-		s.logger.Error("error while trying to delete instance", zap.String("name", instance.Name), zap.Error(err))
-		return InstanceDeletionFailure
+	// check existing
+	instance, resultGet := s.GetByName(instanceName)
+	if resultGet == InstanceRetrievalNotFound {
+		return nil, InstanceUpdateNotFound
+	} else if resultGet == InstanceRetrievalFailure {
+		return nil, InstanceUpdateFailure
 	}
 
-	if value == 0 {
-		s.logger.Error("instance not found to be deleted", zap.String("name", instance.Name))
-		return InstanceDeletionNotFound
+	previousPlan := instance.Plan
+	isPlanChange := instanceUpdateForm.Plan != "" && instanceUpdateForm.Plan != instance.Plan
+	if isPlanChange && instance.Status != models.InstanceStatusRunning && instance.Status != models.InstanceStatusDegraded {
+		return nil, InstanceUpdateNotRunning
+	}
+	if isPlanChange {
+		busy, err := s.IsBusy(instanceName)
+		if err != nil {
+			return nil, InstanceUpdateFailure
+		}
+		if busy {
+			return nil, InstanceUpdateBusy
+		}
 	}
 
-	return InstanceDeletionSuccess
+	update := &repositories.InstanceUpdate{
+		Team:        instanceUpdateForm.Team,
+		Description: instanceUpdateForm.Description,
+	}
+	if update.Team != "" {
+		instance.Team = update.Team
+	}
+	if update.Description != "" {
+		instance.Description = update.Description
+	}
+	if isPlanChange {
+		update.Plan = instanceUpdateForm.Plan
+		update.From = instance.Status
+		update.Status = models.InstanceStatusPending
+		update.Reason = fmt.Sprintf("plan changed from %s to %s", previousPlan, update.Plan)
+		instance.Plan = update.Plan
+		instance.Status = update.Status
+	}
+	if update.Team == "" && update.Description == "" && !isPlanChange {
+		return nil, InstanceUpdateSuccess
+	}
+
+	if !isPlanChange {
+		err := s.instanceRepository.Update(instanceName, update)
+		if err == repositories.ErrNotFound {
+			return nil, InstanceUpdateNotFound
+		} else if err != nil {
+			s.logger.Error("failed to update instance", zap.String("name", instanceName), zap.Any("instanceUpdateForm", instanceUpdateForm), zap.Error(err))
+			return nil, InstanceUpdateFailure
+		}
+		return nil, InstanceUpdateSuccess
+	}
+
+	// prepare plan change
+	change := &models.InstanceChange{
+		Instance: *instance,
+		Previous: &models.InstancePrevious{Plan: previousPlan, Status: update.From},
+	}
+	operation, task, prepareUpdateResult := s.provisionService.PrepareUpdate(change)
+	if prepareUpdateResult != DispatchUpdateResultSuccess {
+		s.logger.Error("failed to prepare update", zap.Any("instance", instance))
+		return nil, InstanceUpdateDispatchUpdateFailure
+	}
+
+	// update
+	err := s.instanceRepository.UpdateWithTask(instanceName, update, task)
+	if err == repositories.ErrNotFound {
+		s.provisionService.AbandonOperation(operation, "the instance was not found")
+		return nil, InstanceUpdateNotFound
+	} else if err == repositories.ErrStatusChanged {
+		s.provisionService.AbandonOperation(operation, "the instance was changed meanwhile")
+		return nil, InstanceUpdateBusy
+	} else if err != nil {
+		s.logger.Error("failed to update instance", zap.String("name", instanceName), zap.Any("instanceUpdateForm", instanceUpdateForm), zap.Error(err))
+		s.provisionService.AbandonOperation(operation, "failed to update the instance")
+		return nil, InstanceUpdateFailure
+	}
+
+	return operation, InstanceUpdateSuccess
 }
 
-func (s *instanceService) Delete(instanceName string) InstanceDeletionResult {
-	// TODO missing: check if bindings exist before deleting
+/*
+	the apps still bound are unbound when forced, only their bindings are removed, as their credentials go away
+	along with push-api
+*/
+func (s *instanceService) unbindAll(instanceName string, force bool) ([]string, InstanceDeletionResult) {
+	bindApps, err := s.bindRepository.GetBindApps(instanceName)
+	if err != nil {
+		s.logger.Error("failed to retrieve bindApps of instance to delete", zap.String("name", instanceName), zap.Error(err))
+		return nil, InstanceDeletionFailure
+	}
+	if len(bindApps) == 0 {
+		return nil, InstanceDeletionSuccess
+	}
+
+	appNames := make([]string, 0, len(bindApps))
+	for _, bindApp := range bindApps {
+		appNames = append(appNames, bindApp.AppName)
+	}
+	if !force {
+		return appNames, InstanceDeletionHasBindings
+	}
+
+	for _, appName := range appNames {
+		err = s.bindRepository.DelBindApp(instanceName, appName)
+		if err != nil && err != repositories.ErrNotFound {
+			s.logger.Error("failed to unbind app of instance to delete", zap.String("name", instanceName), zap.String("appName", appName), zap.Error(err))
+			return nil, InstanceDeletionFailure
+		}
+	}
+	return nil, InstanceDeletionSuccess
+}
 
+/*
+	the record is kept (as deprovisioning) until the deprovision finishes, so the resources of the instance are
+	not taken as orphans by the garbage collector meanwhile. It is removed by the instanceWorker through Remove.
+	It is refused while apps are bound, in which case their names are returned, unless forced.
+	The deprovision is written along with the change of status, to be sent by the relay.
+*/
+func (s *instanceService) Delete(instanceName string, force bool) ([]string, *models.Operation, InstanceDeletionResult) {
 	// check existing
 	instance, resultGet := s.GetByName(instanceName)
 	if resultGet == InstanceRetrievalNotFound {
-		return InstanceDeletionNotFound
+		return nil, nil, InstanceDeletionNotFound
 	} else if resultGet == InstanceRetrievalFailure {
-		return InstanceDeletionFailure
+		return nil, nil, InstanceDeletionFailure
+	}
+
+	// check running operations, a pending instance having one on the way even before it is picked up
+	if instance.Status == models.InstanceStatusPending {
+		return nil, nil, InstanceDeletionBusy
+	}
+	busy, err := s.IsBusy(instance.Name)
+	if err != nil {
+		return nil, nil, InstanceDeletionFailure
+	}
+	if busy {
+		return nil, nil, InstanceDeletionBusy
+	}
+
+	// check bindings
+	appNames, resultUnbind := s.unbindAll(instance.Name, force)
+	if resultUnbind != InstanceDeletionSuccess {
+		return appNames, nil, resultUnbind
+	}
+
+	// prepare deprovision
+	operation, task, prepareDeprovisionResult := s.provisionService.PrepareDeprovision(instance)
+	if prepareDeprovisionResult != DispatchDeprovisionResultSuccess {
+		s.logger.Error("failed to prepare deprovision", zap.Any("instance", instance))
+		return nil, nil, InstanceDeletionDeprovisionFailure
+	}
+
+	// mark as deprovisioning, unless an operation got on the way meanwhile
+	update := &repositories.InstanceUpdate{Status: models.InstanceStatusDeprovisioning, Reason: "deleted", From: instance.Status}
+	err = s.instanceRepository.UpdateWithTask(instance.Name, update, task)
+	if err == repositories.ErrStatusChanged {
+		s.provisionService.AbandonOperation(operation, "the instance was changed meanwhile")
+		return nil, nil, InstanceDeletionBusy
+	} else if err != nil {
+		s.logger.Error("error while trying to mark instance as deprovisioning", zap.String("name", instance.Name), zap.Error(err))
+		s.provisionService.AbandonOperation(operation, "failed to mark the instance as deprovisioning")
+		return nil, nil, InstanceDeletionFailure
+	}
+
+	return nil, operation, InstanceDeletionSuccess
+}
+
+/*
+	the components of the instance that went missing are created again by a recreate task, written along with
+	the instance becoming pending, as long as the instance still has the status it was found with
+*/
+func (s *instanceService) Recreate(instance *models.Instance, reason string) (*models.Operation, InstanceUpdateResult) {
+	recreated := *instance
+	recreated.Status = models.InstanceStatusPending
+	operation, task, prepareRecreateResult := s.provisionService.PrepareRecreate(&recreated)
+	if prepareRecreateResult != DispatchRecreateResultSuccess {
+		s.logger.Error("failed to prepare recreate", zap.Any("instance", instance))
+		return nil, InstanceUpdateDispatchUpdateFailure
 	}
 
-	// delete
-	resultDelete := s.doDelete(instance)
-	if resultDelete != InstanceDeletionSuccess {
-		return resultDelete
+	updateResult := s.UpdateStatusWithTask(instance.Name, instance.Status, models.InstanceStatusPending, reason, task)
+	if updateResult != InstanceUpdateSuccess {
+		s.provisionService.AbandonOperation(operation, "failed to mark the instance as pending")
+		return nil, updateResult
 	}
 
-	// deprovision
-	dispatchDeprovisionResult := s.provisionService.DispatchDeprovision(instance)
-	if dispatchDeprovisionResult != DispatchDeprovisionResultSuccess {
-		s.logger.Error("failed to dispatch deprovision", zap.Any("instance", instance))
-		return InstanceDeletionDeprovisionFailure
+	return operation, InstanceUpdateSuccess
+}
+
+func (s *instanceService) Remove(instanceName string) InstanceDeletionResult {
+	err := s.instanceRepository.Delete(instanceName)
+	if err == repositories.ErrNotFound {
+		s.logger.Error("instance not found to be deleted", zap.String("name", instanceName))
+		return InstanceDeletionNotFound
+	} else if err != nil {
+		s.logger.Error("error while trying to delete instance", zap.String("name", instanceName), zap.Error(err))
+		return InstanceDeletionFailure
 	}
 
 	// delete env vars
-	_, _ = s.DelInstanceVars(instance.Name)
+	_ = s.DelInstanceVars(instanceName)
 
 	return InstanceDeletionSuccess
 }
 
-func (s *instanceService) UpdateStatus(name string, status models.InstanceStatus) InstanceUpdateResult {
-	instanceKey := s.instanceKey(name)
-
-	_, err := s.redisClient.HSet(instanceKey, "Status", status).Result()
+func (s *instanceService) UpdateStatus(name string, status models.InstanceStatus, reason string) InstanceUpdateResult {
+	err := s.instanceRepository.UpdateStatus(name, status, reason)
 	if err != nil {
 		s.logger.Error("error while trying to update instance", zap.String("name", name), zap.Error(err))
 		return InstanceUpdateFailure
@@ -281,6 +433,157 @@ func (s *instanceService) UpdateStatus(name string, status models.InstanceStatus
 	return InstanceUpdateSuccess
 }
 
+/*
+	the status is only changed while the instance still has the status it is changed from, so that a change made
+	meanwhile, like a deletion, is not overwritten
+*/
+func (s *instanceService) UpdateStatusFrom(name string, from, status models.InstanceStatus, reason string) InstanceUpdateResult {
+	return s.UpdateStatusWithTask(name, from, status, reason, nil)
+}
+
+func (s *instanceService) UpdateStatusWithTask(name string, from, status models.InstanceStatus, reason string, task *models.PendingTask) InstanceUpdateResult {
+	err := s.instanceRepository.UpdateWithTask(name, &repositories.InstanceUpdate{Status: status, Reason: reason, From: from}, task)
+	if err == repositories.ErrNotFound {
+		return InstanceUpdateNotFound
+	} else if err == repositories.ErrStatusChanged {
+		return InstanceUpdateStatusChanged
+	} else if err != nil {
+		s.logger.Error("error while trying to update instance", zap.String("name", name), zap.Error(err))
+		return InstanceUpdateFailure
+	}
+
+	return InstanceUpdateSuccess
+}
+
+/*
+	puts back what the instance had before a change that failed, as long as the instance is still pending with it,
+	keeping the vars the change did put in place before failing
+*/
+func (s *instanceService) RevertChange(name string, previous *models.InstancePrevious, reason string, vars map[string]string) InstanceUpdateResult {
+	update := &repositories.InstanceUpdate{
+		Plan:   previous.Plan,
+		Status: previous.Status,
+		Reason: reason,
+		Vars:   vars,
+		From:   models.InstanceStatusPending,
+	}
+	err := s.instanceRepository.Update(name, update)
+	if err == repositories.ErrNotFound {
+		return InstanceUpdateNotFound
+	} else if err == repositories.ErrStatusChanged {
+		return InstanceUpdateStatusChanged
+	} else if err != nil {
+		s.logger.Error("error while trying to revert instance change", zap.String("name", name), zap.Any("previous", previous), zap.Error(err))
+		return InstanceUpdateFailure
+	}
+
+	return InstanceUpdateSuccess
+}
+
+/*
+	writes the outcome of an operation at once with the status it leaves the instance in, as long as the instance
+	is still pending with it, so that a late or repeated outcome does not overwrite what was written meanwhile
+*/
+func (s *instanceService) FinishPending(name string, status models.InstanceStatus, reason string, rollback *models.InstanceRollback, vars map[string]string) InstanceUpdateResult {
+	update := &repositories.InstanceUpdate{
+		Status:   status,
+		Reason:   reason,
+		From:     models.InstanceStatusPending,
+		Rollback: rollback,
+		Vars:     vars,
+	}
+	err := s.instanceRepository.Update(name, update)
+	if err == repositories.ErrNotFound {
+		return InstanceUpdateNotFound
+	} else if err == repositories.ErrStatusChanged {
+		return InstanceUpdateStatusChanged
+	} else if err != nil {
+		s.logger.Error("error while trying to finish pending instance", zap.String("name", name), zap.String("status", string(status)), zap.Error(err))
+		return InstanceUpdateFailure
+	}
+
+	return InstanceUpdateSuccess
+}
+
+func (s *instanceService) UpdateRollback(name string, rollback models.InstanceRollback) InstanceUpdateResult {
+	err := s.instanceRepository.UpdateRollback(name, rollback)
+	if err == repositories.ErrNotFound {
+		return InstanceUpdateNotFound
+	} else if err != nil {
+		s.logger.Error("error while trying to update instance rollback", zap.String("name", name), zap.Error(err))
+		return InstanceUpdateFailure
+	}
+
+	return InstanceUpdateSuccess
+}
+
+func (s *instanceService) UpdateResources(name string, resources map[string]string) InstanceUpdateResult {
+	err := s.instanceRepository.UpdateResources(name, resources)
+	if err == repositories.ErrNotFound {
+		return InstanceUpdateNotFound
+	} else if err != nil {
+		s.logger.Error("error while trying to update instance resources", zap.String("name", name), zap.Error(err))
+		return InstanceUpdateFailure
+	}
+
+	return InstanceUpdateSuccess
+}
+
+func (s *instanceService) GetStatusHistory(name string) ([]*models.InstanceStatusTransition, InstanceRetrievalResult) {
+	history, err := s.instanceRepository.GetStatusHistory(name)
+	if err == repositories.ErrNotFound {
+		return nil, InstanceRetrievalNotFound
+	} else if err != nil {
+		s.logger.Error("failed to retrieve instance status history", zap.String("name", name), zap.Error(err))
+		return nil, InstanceRetrievalFailure
+	}
+
+	return history, InstanceRetrievalSuccess
+}
+
+/*
+	what tsuru shows about the instance, leaving out what is not known yet (e.g. the endpoint of a pending instance)
+*/
+func (s *instanceService) GetInfo(name string) ([]*models.InstanceInfoItem, InstanceRetrievalResult) {
+	instance, resultGet := s.GetByName(name)
+	if resultGet != InstanceRetrievalSuccess {
+		return nil, resultGet
+	}
+
+	instanceVars, err := s.GetInstanceVars(name)
+	if err != nil {
+		s.logger.Error("failed to retrieve instance vars for info", zap.String("name", name), zap.Error(err))
+		return nil, InstanceRetrievalFailure
+	}
+
+	bindApps, err := s.bindRepository.GetBindApps(name)
+	if err != nil {
+		s.logger.Error("failed to retrieve bindApps for info", zap.String("name", name), zap.Error(err))
+		return nil, InstanceRetrievalFailure
+	}
+
+	status := string(instance.Status)
+	if instance.FailureReason != "" {
+		status = fmt.Sprintf("%s (%s)", status, instance.FailureReason)
+	}
+	info := []*models.InstanceInfoItem{
+		{Label: "Plan", Value: instance.Plan},
+		{Label: "Status", Value: status},
+	}
+	if endpoint := instanceVars[provisioners.EnvVarEndpoint]; endpoint != "" {
+		info = append(info, &models.InstanceInfoItem{Label: "push-api endpoint", Value: endpoint})
+	}
+	if pushStreamUrl := instance.Resources[provisioners.ResourcePushStreamUrl]; pushStreamUrl != "" {
+		info = append(info, &models.InstanceInfoItem{Label: "push-stream URL", Value: pushStreamUrl})
+	}
+	info = append(info, &models.InstanceInfoItem{Label: "Bound apps", Value: strconv.Itoa(len(bindApps))})
+	if instance.ProvisionedAt != nil {
+		info = append(info, &models.InstanceInfoItem{Label: "Provisioned at", Value: instance.ProvisionedAt.Format(time.RFC3339)})
+	}
+
+	return info, InstanceRetrievalSuccess
+}
+
 func (s *instanceService) GetStatusByName(name string) InstanceStatusResult {
 	// retrieve
 	instance, resultGet := s.GetByName(name)
@@ -293,10 +596,12 @@ func (s *instanceService) GetStatusByName(name string) InstanceStatusResult {
 	}
 
 	// check status
-	if instance.Status == models.InstanceStatusPending {
+	if instance.Status == models.InstanceStatusPending || instance.Status == models.InstanceStatusDeprovisioning {
 		return InstanceStatusPendingStatus
 	} else if instance.Status == models.InstanceStatusFailed {
 		return InstanceStatusFailedStatus
+	} else if instance.Status == models.InstanceStatusDegraded {
+		return InstanceStatusDegradedStatus
 	}
 	return InstanceStatusRunningStatus
 }
@@ -306,13 +611,8 @@ func (s *instanceService) GetStatusByName(name string) InstanceStatusResult {
 	vars
 	===========================================================================
 */
-func (s *instanceService) instanceVarsKey(instanceName string) string {
-	return fmt.Sprintf("%s:%s", s.instanceVarsKeyPrefix, instanceName)
-}
-
 func (s *instanceService) GetInstanceVars(name string) (map[string]string, error) {
-	instanceKey := s.instanceVarsKey(name)
-	envVars, err := s.redisClient.HGetAll(instanceKey).Result()
+	envVars, err := s.instanceRepository.GetVars(name)
 	if err != nil {
 		s.logger.Error("GetInstanceVars failed", zap.Error(err))
 		return nil, err
@@ -320,43 +620,38 @@ func (s *instanceService) GetInstanceVars(name string) (map[string]string, error
 	return envVars, nil
 }
 
-func (s *instanceService) SetInstanceVars(name string, envVars map[string]string) (string, error) {
-	instanceKey := s.instanceVarsKey(name)
-
-	// convert string to interface{}
-	interfaceMap := make(map[string]interface{}, len(envVars))
-	for k, v := range envVars {
-		interfaceMap[k] = v
-	}
-
-	result, err := s.redisClient.HMSet(instanceKey, interfaceMap).Result()
+func (s *instanceService) SetInstanceVars(name string, envVars map[string]string) error {
+	err := s.instanceRepository.SetVars(name, envVars)
 	if err != nil {
 		s.logger.Error("SetInstanceVars failed", zap.Error(err))
-		return "", err
+		return err
 	}
-	return result, nil
+	return nil
 }
 
-func (s *instanceService) DelInstanceVars(name string) (int64, error) {
-	instanceKey := s.instanceVarsKey(name)
-
-	result, err := s.redisClient.Del(instanceKey).Result()
+func (s *instanceService) DelInstanceVars(name string) error {
+	err := s.instanceRepository.DelVars(name)
 	if err != nil {
 		s.logger.Error("DelInstanceVars failed", zap.Error(err))
-		return 0, err
+		return err
 	}
-	return result, nil
+	return nil
 }
 
-func NewInstanceService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient, provisionService ProvisionService) InstanceService {
-	instanceKeyPrefix := config.GetString("redis.db.instance.prefix")
-	instanceVarsKeyPrefix := config.GetString("redis.db.instance.vars_prefix")
+/*
+	whether a lifecycle operation is running on the instance right now, on the workers
+*/
+func (s *instanceService) IsBusy(name string) (bool, error) {
+	return s.lockService.IsHeld(name)
+}
 
+func NewInstanceService(config *viper.Viper, logger *zap.Logger, instanceRepository repositories.InstanceRepository, bindRepository repositories.BindRepository, planService PlanService, provisionService ProvisionService, lockService InstanceLockService) InstanceService {
 	return &instanceService{
-		instanceKeyPrefix:     instanceKeyPrefix,
-		instanceVarsKeyPrefix: instanceVarsKeyPrefix,
-		logger:                logger,
-		provisionService:      provisionService,
-		redisClient:           redisClient,
+		logger:             logger,
+		instanceRepository: instanceRepository,
+		bindRepository:     bindRepository,
+		planService:        planService,
+		provisionService:   provisionService,
+		lockService:        lockService,
 	}
 }
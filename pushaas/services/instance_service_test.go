@@ -2,25 +2,52 @@ package services_test
 
 import (
 	"errors"
+	"sync"
+	"time"
 
-	"github.com/go-redis/redis"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/viper"
 
 	"github.com/pushaas/pushaas/pushaas/mocks"
 	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/repositories"
 	"github.com/pushaas/pushaas/pushaas/services"
 )
 
 var _ = Describe("InstanceService", func() {
 	config := viper.New()
+	config.Set("plans", []map[string]interface{}{
+		{"name": "small", "cpu": 512, "memory": 1024},
+		{"name": "other-plan", "cpu": 1024, "memory": 2048},
+	})
+	planService, _ := services.NewPlanService(config)
 	instanceName := "instance-1"
 	instanceForm := &models.InstanceForm{
 		Name: instanceName,
 		Team: "pushaas-team",
 		User: "rafael",
-		Plan: string(models.PlanSmall),
+		Plan: "small",
+	}
+
+	instanceWithStatus := func(status models.InstanceStatus) func(name string) (*models.Instance, error) {
+		return func(name string) (*models.Instance, error) {
+			return &models.Instance{Name: name, Plan: "small", Status: status}, nil
+		}
+	}
+	instanceNotFound := func(name string) (*models.Instance, error) {
+		return nil, repositories.ErrNotFound
+	}
+	instanceNotBusy := &mocks.InstanceLockServiceMock{
+		IsHeldFunc: func(instanceName string) (bool, error) {
+			return false, nil
+		},
+	}
+	instanceBusy := &mocks.InstanceLockServiceMock{
+		IsHeldFunc: func(instanceName string) (bool, error) {
+			return true, nil
+		},
 	}
 
 	Describe("GetByName", func() {
@@ -31,18 +58,13 @@ var _ = Describe("InstanceService", func() {
 				Team: "pushaas-team",
 				User: "rafael",
 			}
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					val := map[string]string{
-						"Name": expected.Name,
-						"Team": expected.Team,
-						"User": expected.User,
-					}
-					return redis.NewStringStringMapResult(val, nil)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return expected, nil
 				},
 			}
 
-			instanceService := services.NewInstanceService(config, logger, redisClient, nil)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
 
 			// act
 			instance, result := instanceService.GetByName(instanceName)
@@ -50,17 +72,16 @@ var _ = Describe("InstanceService", func() {
 			// assert
 			Expect(result).To(Equal(services.InstanceRetrievalSuccess))
 			Expect(instance).To(Equal(expected))
+			Expect(instanceRepository.GetCalls()[0].Name).To(Equal(instanceName))
 		})
 
 		It("indicates when instance is not found", func() {
 			// arrange
 			var expected *models.Instance
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, nil)
-				},
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
 			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, nil)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
 
 			// act
 			instance, result := instanceService.GetByName(instanceName)
@@ -73,12 +94,12 @@ var _ = Describe("InstanceService", func() {
 		It("indicates when failure happens in retrieving the instance", func() {
 			// arrange
 			var expected *models.Instance
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, errors.New("some error"))
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return nil, errors.New("some error")
 				},
 			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, nil)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
 
 			// act
 			instance, result := instanceService.GetByName(instanceName)
@@ -89,15 +110,289 @@ var _ = Describe("InstanceService", func() {
 		})
 	})
 
-	Describe("GetStatusByName", func() {
+	Describe("GetAll", func() {
+		It("indicates when there are no instances", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetAllFunc: func() ([]*models.Instance, error) {
+					return []*models.Instance{}, nil
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			instances, result := instanceService.GetAll()
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalNotFound))
+			Expect(instances).To(BeNil())
+		})
+
+		It("indicates when fails to retrieve instances", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetAllFunc: func() ([]*models.Instance, error) {
+					return nil, errors.New("some error")
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			instances, result := instanceService.GetAll()
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalFailure))
+			Expect(instances).To(BeNil())
+		})
+	})
+
+	Describe("List", func() {
+		It("lists a page of the instances", func() {
+			// arrange
+			expected := &models.InstancePage{Instances: []*models.Instance{{Name: instanceName}}, NextCursor: "next-cursor"}
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, error) {
+					return expected, nil
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+			filter := &models.InstanceFilter{Team: "pushaas-team"}
+
+			// act
+			page, result := instanceService.List(filter, "some-cursor", 10)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalSuccess))
+			Expect(page).To(Equal(expected))
+			Expect(instanceRepository.ListCalls()[0].Filter).To(Equal(filter))
+			Expect(instanceRepository.ListCalls()[0].Cursor).To(Equal("some-cursor"))
+			Expect(instanceRepository.ListCalls()[0].Limit).To(Equal(10))
+		})
+
+		It("bounds the size of the page", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, error) {
+					return &models.InstancePage{}, nil
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			_, _ = instanceService.List(&models.InstanceFilter{}, "", 0)
+			_, _ = instanceService.List(&models.InstanceFilter{}, "", 100000)
+
+			// assert
+			Expect(instanceRepository.ListCalls()[0].Limit).To(Equal(50))
+			Expect(instanceRepository.ListCalls()[1].Limit).To(Equal(500))
+		})
+
+		It("indicates when the cursor is invalid", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, error) {
+					return nil, repositories.ErrInvalidCursor
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			page, result := instanceService.List(&models.InstanceFilter{}, "bad", 10)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalInvalidCursor))
+			Expect(page).To(BeNil())
+		})
+
+		It("indicates when fails to list the instances", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				ListFunc: func(filter *models.InstanceFilter, cursor string, limit int) (*models.InstancePage, error) {
+					return nil, errors.New("some error")
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			page, result := instanceService.List(&models.InstanceFilter{}, "", 10)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalFailure))
+			Expect(page).To(BeNil())
+		})
+	})
+
+	Describe("GetStatusHistory", func() {
+		It("retrieves the history of the statuses of the instance", func() {
+			// arrange
+			expected := []*models.InstanceStatusTransition{{To: models.InstanceStatusPending}}
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetStatusHistoryFunc: func(name string) ([]*models.InstanceStatusTransition, error) {
+					return expected, nil
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			history, result := instanceService.GetStatusHistory(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalSuccess))
+			Expect(history).To(Equal(expected))
+		})
+
 		It("indicates when instance is not found", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, nil)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetStatusHistoryFunc: func(name string) ([]*models.InstanceStatusTransition, error) {
+					return nil, repositories.ErrNotFound
 				},
 			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, nil)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			history, result := instanceService.GetStatusHistory(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalNotFound))
+			Expect(history).To(BeNil())
+		})
+
+		It("indicates when fails to retrieve the history", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetStatusHistoryFunc: func(name string) ([]*models.InstanceStatusTransition, error) {
+					return nil, errors.New("some error")
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			history, result := instanceService.GetStatusHistory(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalFailure))
+			Expect(history).To(BeNil())
+		})
+	})
+
+	Describe("GetInfo", func() {
+		bindRepositoryWith := func(appNames ...string) *mocks.BindRepositoryMock {
+			return &mocks.BindRepositoryMock{
+				GetBindAppsFunc: func(instanceName string) ([]*models.BindApp, error) {
+					var bindApps []*models.BindApp
+					for _, appName := range appNames {
+						bindApps = append(bindApps, &models.BindApp{AppName: appName})
+					}
+					return bindApps, nil
+				},
+			}
+		}
+
+		It("retrieves what is known about the instance", func() {
+			// arrange
+			provisionedAt := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return &models.Instance{
+						Name:          name,
+						Plan:          "small",
+						Status:        models.InstanceStatusRunning,
+						ProvisionedAt: &provisionedAt,
+						Resources:     map[string]string{provisioners.ResourcePushStreamUrl: "http://10.0.0.2:9080"},
+					}, nil
+				},
+				GetVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{provisioners.EnvVarEndpoint: "http://10.0.0.1:8080"}, nil
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepositoryWith("app-1", "app-2"), planService, nil, nil)
+
+			// act
+			info, result := instanceService.GetInfo(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalSuccess))
+			Expect(info).To(Equal([]*models.InstanceInfoItem{
+				{Label: "Plan", Value: "small"},
+				{Label: "Status", Value: "running"},
+				{Label: "push-api endpoint", Value: "http://10.0.0.1:8080"},
+				{Label: "push-stream URL", Value: "http://10.0.0.2:9080"},
+				{Label: "Bound apps", Value: "2"},
+				{Label: "Provisioned at", Value: "2019-06-01T12:00:00Z"},
+			}))
+		})
+
+		It("leaves out what is not known yet", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return &models.Instance{Name: name, Plan: "small", Status: models.InstanceStatusFailed, FailureReason: "push-redis: some error"}, nil
+				},
+				GetVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepositoryWith(), planService, nil, nil)
+
+			// act
+			info, result := instanceService.GetInfo(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalSuccess))
+			Expect(info).To(Equal([]*models.InstanceInfoItem{
+				{Label: "Plan", Value: "small"},
+				{Label: "Status", Value: "failed (push-redis: some error)"},
+				{Label: "Bound apps", Value: "0"},
+			}))
+		})
+
+		It("indicates when instance is not found", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepositoryWith(), planService, nil, nil)
+
+			// act
+			info, result := instanceService.GetInfo(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalNotFound))
+			Expect(info).To(BeNil())
+		})
+
+		It("indicates when fails to retrieve the bound apps", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
+				GetVarsFunc: func(name string) (map[string]string, error) {
+					return map[string]string{}, nil
+				},
+			}
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppsFunc: func(instanceName string) ([]*models.BindApp, error) {
+					return nil, errors.New("some error")
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepository, planService, nil, nil)
+
+			// act
+			info, result := instanceService.GetInfo(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceRetrievalFailure))
+			Expect(info).To(BeNil())
+		})
+	})
+
+	Describe("GetStatusByName", func() {
+		It("indicates when instance is not found", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
 
 			// act
 			result := instanceService.GetStatusByName(instanceName)
@@ -108,12 +403,12 @@ var _ = Describe("InstanceService", func() {
 
 		It("indicates when fails to get instance", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, errors.New("some error"))
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return nil, errors.New("some error")
 				},
 			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, nil)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
 
 			// act
 			result := instanceService.GetStatusByName(instanceName)
@@ -124,14 +419,10 @@ var _ = Describe("InstanceService", func() {
 
 		It("indicates when gets instance and is on status pending", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					val := map[string]string{
-						"Status": string(models.InstanceStatusPending),
-					}
-					return redis.NewStringStringMapResult(val, nil)
-				},			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, nil)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusPending),
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
 
 			// act
 			result := instanceService.GetStatusByName(instanceName)
@@ -142,14 +433,10 @@ var _ = Describe("InstanceService", func() {
 
 		It("indicates when gets instance and is on status failed", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					val := map[string]string{
-						"Status": string(models.InstanceStatusFailed),
-					}
-					return redis.NewStringStringMapResult(val, nil)
-				},			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, nil)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusFailed),
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
 
 			// act
 			result := instanceService.GetStatusByName(instanceName)
@@ -158,17 +445,26 @@ var _ = Describe("InstanceService", func() {
 			Expect(result).To(Equal(services.InstanceStatusFailedStatus))
 		})
 
+		It("indicates when gets instance and is on status degraded", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusDegraded),
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			result := instanceService.GetStatusByName(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceStatusDegradedStatus))
+		})
+
 		It("indicates when gets instance and is on status running", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					val := map[string]string{
-						"Status": string(models.InstanceStatusRunning),
-					}
-					return redis.NewStringStringMapResult(val, nil)
-				},
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
 			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, nil)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
 
 			// act
 			result := instanceService.GetStatusByName(instanceName)
@@ -178,300 +474,899 @@ var _ = Describe("InstanceService", func() {
 		})
 	})
 
-	Describe("Delete", func() {
-		It("indicates when instance is not found at retrieval", func() {
+	Describe("UpdateRollback", func() {
+		It("records the rollback outcome on the instance", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, nil)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				UpdateRollbackFunc: func(name string, rollback models.InstanceRollback) error {
+					return nil
 				},
 			}
-			provisionService := &mocks.ProvisionServiceMock{}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
 
 			// act
-			result := instanceService.Delete(instanceName)
+			result := instanceService.UpdateRollback(instanceName, models.InstanceRollbackCompleted)
 
 			// assert
-			Expect(result).To(Equal(services.InstanceDeletionNotFound))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(0))
-			Expect(provisionService.DispatchDeprovisionCalls()).To(HaveLen(0))
+			Expect(result).To(Equal(services.InstanceUpdateSuccess))
+			calls := instanceRepository.UpdateRollbackCalls()
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Name).To(Equal(instanceName))
+			Expect(calls[0].Rollback).To(Equal(models.InstanceRollbackCompleted))
 		})
 
-		It("indicates when failed to get instance to delete", func() {
+		It("indicates when fails to record the rollback outcome", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				UpdateRollbackFunc: func(name string, rollback models.InstanceRollback) error {
+					return errors.New("some error")
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			result := instanceService.UpdateRollback(instanceName, models.InstanceRollbackFailed)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateFailure))
+		})
+	})
+
+	Describe("RevertChange", func() {
+		It("puts back the previous plan and status, keeping the vars of the change, while the instance is still pending", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				UpdateFunc: func(name string, update *repositories.InstanceUpdate) error {
+					return nil
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			result := instanceService.RevertChange(instanceName, &models.InstancePrevious{Plan: "small", Status: models.InstanceStatusDegraded}, "change failed", map[string]string{"PUSHAAS_PASSWORD": "new-password"})
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateSuccess))
+			Expect(instanceRepository.UpdateCalls()).To(HaveLen(1))
+			Expect(instanceRepository.UpdateCalls()[0].Update).To(Equal(&repositories.InstanceUpdate{
+				Plan:   "small",
+				Status: models.InstanceStatusDegraded,
+				Reason: "change failed",
+				Vars:   map[string]string{"PUSHAAS_PASSWORD": "new-password"},
+				From:   models.InstanceStatusPending,
+			}))
+		})
+
+		It("indicates when the instance is no longer pending", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				UpdateFunc: func(name string, update *repositories.InstanceUpdate) error {
+					return repositories.ErrStatusChanged
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			result := instanceService.RevertChange(instanceName, &models.InstancePrevious{Status: models.InstanceStatusRunning}, "change failed", nil)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateStatusChanged))
+		})
+	})
+
+	Describe("FinishPending", func() {
+		It("writes the status along with the rollback and the vars while the instance is still pending", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				UpdateFunc: func(name string, update *repositories.InstanceUpdate) error {
+					return nil
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+			rollback := models.InstanceRollbackNone
+			vars := map[string]string{"PUSHAAS_ENDPOINT": "endpoint-1"}
+
+			// act
+			result := instanceService.FinishPending(instanceName, models.InstanceStatusRunning, "", &rollback, vars)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateSuccess))
+			Expect(instanceRepository.UpdateCalls()).To(HaveLen(1))
+			Expect(instanceRepository.UpdateCalls()[0].Update).To(Equal(&repositories.InstanceUpdate{
+				Status:   models.InstanceStatusRunning,
+				From:     models.InstanceStatusPending,
+				Rollback: &rollback,
+				Vars:     vars,
+			}))
+		})
+
+		It("indicates when the instance is no longer pending", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, errors.New("some error"))
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				UpdateFunc: func(name string, update *repositories.InstanceUpdate) error {
+					return repositories.ErrStatusChanged
 				},
 			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, nil)
+
+			// act
+			result := instanceService.FinishPending(instanceName, models.InstanceStatusRunning, "", nil, map[string]string{"PUSHAAS_PASSWORD": "stale-password"})
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateStatusChanged))
+		})
+	})
+
+	Describe("Update", func() {
+		updateSucceeds := func(name string, update *repositories.InstanceUpdate) error {
+			return nil
+		}
+		updateWithTaskSucceeds := func(name string, update *repositories.InstanceUpdate, task *models.PendingTask) error {
+			return nil
+		}
+		prepareUpdateSucceeds := func(change *models.InstanceChange) (*models.Operation, *models.PendingTask, services.DispatchUpdateResult) {
+			return &models.Operation{Id: "operation-1"}, &models.PendingTask{Id: "task-1"}, services.DispatchUpdateResultSuccess
+		}
+
+		It("indicates when data is invalid", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, instanceNotBusy)
+
+			// act
+			_, result := instanceService.Update(instanceName, &models.InstanceUpdateForm{Plan: "unknown"})
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateInvalidData))
+			Expect(instanceRepository.GetCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when instance is not found", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, instanceNotBusy)
+
+			// act
+			_, result := instanceService.Update(instanceName, &models.InstanceUpdateForm{Team: "other-team"})
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateNotFound))
+			Expect(instanceRepository.UpdateCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when instance is removed before being updated", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
+				UpdateFunc: func(name string, update *repositories.InstanceUpdate) error {
+					return repositories.ErrNotFound
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, nil, instanceNotBusy)
+
+			// act
+			_, result := instanceService.Update(instanceName, &models.InstanceUpdateForm{Team: "other-team"})
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateNotFound))
+		})
+
+		It("updates team and description without dispatching update", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc:    instanceWithStatus(models.InstanceStatusRunning),
+				UpdateFunc: updateSucceeds,
+			}
 			provisionService := &mocks.ProvisionServiceMock{}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceNotBusy)
 
 			// act
-			result := instanceService.Delete(instanceName)
+			_, result := instanceService.Update(instanceName, &models.InstanceUpdateForm{Team: "other-team", Description: "description"})
 
 			// assert
-			Expect(result).To(Equal(services.InstanceDeletionFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(0))
-			Expect(provisionService.DispatchDeprovisionCalls()).To(HaveLen(0))
+			Expect(result).To(Equal(services.InstanceUpdateSuccess))
+			Expect(instanceRepository.UpdateCalls()).To(HaveLen(1))
+			Expect(instanceRepository.UpdateCalls()[0].Update).To(Equal(&repositories.InstanceUpdate{
+				Team:        "other-team",
+				Description: "description",
+			}))
+			Expect(provisionService.PrepareUpdateCalls()).To(HaveLen(0))
 		})
 
-		It("indicates when failed to delete instance", func() {
+		It("indicates when changing the plan of an instance that is not running", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					val := map[string]string {
-						"Status": string(models.InstanceStatusRunning),
-					}
-					return redis.NewStringStringMapResult(val, nil)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return &models.Instance{Name: name, Plan: "other-plan", Status: models.InstanceStatusPending}, nil
 				},
-				DelFunc: func(keys ...string) *redis.IntCmd {
-					return redis.NewIntResult(0, errors.New("some error"))
+			}
+			provisionService := &mocks.ProvisionServiceMock{}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceNotBusy)
+
+			// act
+			_, result := instanceService.Update(instanceName, &models.InstanceUpdateForm{Plan: "small"})
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateNotRunning))
+			Expect(instanceRepository.UpdateCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareUpdateCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when the plan changes while the instance is busy with another operation", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return &models.Instance{Name: name, Plan: "other-plan", Status: models.InstanceStatusRunning}, nil
 				},
 			}
 			provisionService := &mocks.ProvisionServiceMock{}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceBusy)
 
 			// act
-			result := instanceService.Delete(instanceName)
+			_, result := instanceService.Update(instanceName, &models.InstanceUpdateForm{Plan: "small"})
 
 			// assert
-			Expect(result).To(Equal(services.InstanceDeletionFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(1))
-			Expect(provisionService.DispatchDeprovisionCalls()).To(HaveLen(0))
+			Expect(result).To(Equal(services.InstanceUpdateBusy))
+			Expect(instanceRepository.UpdateCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareUpdateCalls()).To(HaveLen(0))
 		})
 
-		It("indicates when instance is not found at deletion", func() {
+		It("marks instance as pending along with the update task when the plan changes", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					val := map[string]string {
-						"Status": string(models.InstanceStatusRunning),
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return &models.Instance{Name: name, Plan: "other-plan", Status: models.InstanceStatusRunning}, nil
+				},
+				UpdateWithTaskFunc: updateWithTaskSucceeds,
+			}
+			provisionService := &mocks.ProvisionServiceMock{
+				PrepareUpdateFunc: prepareUpdateSucceeds,
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceNotBusy)
+
+			// act
+			operation, result := instanceService.Update(instanceName, &models.InstanceUpdateForm{Plan: "small"})
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateSuccess))
+			Expect(operation.Id).To(Equal("operation-1"))
+			Expect(provisionService.PrepareUpdateCalls()).To(HaveLen(1))
+			Expect(provisionService.PrepareUpdateCalls()[0].In1.Plan).To(Equal("small"))
+			Expect(provisionService.PrepareUpdateCalls()[0].In1.Status).To(Equal(models.InstanceStatusPending))
+			Expect(provisionService.PrepareUpdateCalls()[0].In1.Previous).To(Equal(&models.InstancePrevious{Plan: "other-plan", Status: models.InstanceStatusRunning}))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(1))
+			Expect(instanceRepository.UpdateWithTaskCalls()[0].Update).To(Equal(&repositories.InstanceUpdate{
+				Plan:   "small",
+				Status: models.InstanceStatusPending,
+				Reason: "plan changed from other-plan to small",
+				From:   models.InstanceStatusRunning,
+			}))
+			Expect(instanceRepository.UpdateWithTaskCalls()[0].Task.Id).To(Equal("task-1"))
+		})
+
+		It("indicates when fails to prepare the update, leaving the instance as it was", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return &models.Instance{Name: name, Plan: "other-plan", Status: models.InstanceStatusDegraded}, nil
+				},
+			}
+			provisionService := &mocks.ProvisionServiceMock{
+				PrepareUpdateFunc: func(change *models.InstanceChange) (*models.Operation, *models.PendingTask, services.DispatchUpdateResult) {
+					return nil, nil, services.DispatchUpdateResultFailure
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceNotBusy)
+
+			// act
+			_, result := instanceService.Update(instanceName, &models.InstanceUpdateForm{Plan: "small"})
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateDispatchUpdateFailure))
+			Expect(instanceRepository.UpdateCalls()).To(HaveLen(0))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(0))
+		})
+
+		It("abandons the operation when fails to change the plan", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return &models.Instance{Name: name, Plan: "other-plan", Status: models.InstanceStatusRunning}, nil
+				},
+				UpdateWithTaskFunc: func(name string, update *repositories.InstanceUpdate, task *models.PendingTask) error {
+					return errors.New("failed")
+				},
+			}
+			provisionService := &mocks.ProvisionServiceMock{
+				PrepareUpdateFunc:    prepareUpdateSucceeds,
+				AbandonOperationFunc: func(operation *models.Operation, reason string) {},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceNotBusy)
+
+			// act
+			_, result := instanceService.Update(instanceName, &models.InstanceUpdateForm{Plan: "small"})
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateFailure))
+			Expect(provisionService.AbandonOperationCalls()).To(HaveLen(1))
+			Expect(provisionService.AbandonOperationCalls()[0].Operation.Id).To(Equal("operation-1"))
+		})
+	})
+
+	Describe("Delete", func() {
+		updateWithTaskSucceeds := func(name string, update *repositories.InstanceUpdate, task *models.PendingTask) error {
+			return nil
+		}
+		prepareDeprovisionSucceeds := func(instance *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchDeprovisionResult) {
+			return &models.Operation{Id: "operation-1"}, &models.PendingTask{Id: "task-1"}, services.DispatchDeprovisionResultSuccess
+		}
+		bindRepositoryWith := func(appNames ...string) *mocks.BindRepositoryMock {
+			return &mocks.BindRepositoryMock{
+				GetBindAppsFunc: func(instanceName string) ([]*models.BindApp, error) {
+					bindApps := []*models.BindApp{}
+					for _, appName := range appNames {
+						bindApps = append(bindApps, &models.BindApp{AppName: appName})
 					}
-					return redis.NewStringStringMapResult(val, nil)
+					return bindApps, nil
 				},
-				DelFunc: func(keys ...string) *redis.IntCmd {
-					return redis.NewIntResult(0, nil)
+				DelBindAppFunc: func(instanceName, appName string) error {
+					return nil
 				},
 			}
+		}
+
+		It("indicates when instance is not found at retrieval", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
+			}
 			provisionService := &mocks.ProvisionServiceMock{}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceNotBusy)
 
 			// act
-			result := instanceService.Delete(instanceName)
+			_, _, result := instanceService.Delete(instanceName, false)
 
 			// assert
 			Expect(result).To(Equal(services.InstanceDeletionNotFound))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(1))
-			Expect(provisionService.DispatchDeprovisionCalls()).To(HaveLen(0))
+			Expect(instanceRepository.GetCalls()).To(HaveLen(1))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(0))
+			Expect(instanceRepository.DeleteCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareDeprovisionCalls()).To(HaveLen(0))
 		})
 
-		It("indicates when deletes successfully but fails to dispatch deprovision", func() {
+		It("indicates when failed to get instance to delete", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					val := map[string]string {
-						"Status": string(models.InstanceStatusRunning),
-					}
-					return redis.NewStringStringMapResult(val, nil)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return nil, errors.New("some error")
 				},
-				DelFunc: func(keys ...string) *redis.IntCmd {
-					return redis.NewIntResult(1, nil)
+			}
+			provisionService := &mocks.ProvisionServiceMock{}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceNotBusy)
+
+			// act
+			_, _, result := instanceService.Delete(instanceName, false)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionFailure))
+			Expect(instanceRepository.GetCalls()).To(HaveLen(1))
+			Expect(instanceRepository.DeleteCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareDeprovisionCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when fails to mark instance as deprovisioning and abandons the deprovision", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
+				UpdateWithTaskFunc: func(name string, update *repositories.InstanceUpdate, task *models.PendingTask) error {
+					return errors.New("some error")
 				},
 			}
 			provisionService := &mocks.ProvisionServiceMock{
-				DispatchDeprovisionFunc: func(instance *models.Instance) services.DispatchDeprovisionResult {
-					return services.DispatchDeprovisionResultFailure
+				PrepareDeprovisionFunc: prepareDeprovisionSucceeds,
+				AbandonOperationFunc: func(operation *models.Operation, reason string) {
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepositoryWith(), planService, provisionService, instanceNotBusy)
+
+			// act
+			_, operation, result := instanceService.Delete(instanceName, false)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionFailure))
+			Expect(operation).To(BeNil())
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(1))
+			Expect(instanceRepository.DeleteCalls()).To(HaveLen(0))
+			Expect(provisionService.AbandonOperationCalls()).To(HaveLen(1))
+			Expect(provisionService.AbandonOperationCalls()[0].Operation.Id).To(Equal("operation-1"))
+		})
+
+		It("indicates when fails to prepare deprovision, leaving the instance as it was", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
+			}
+			provisionService := &mocks.ProvisionServiceMock{
+				PrepareDeprovisionFunc: func(instance *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchDeprovisionResult) {
+					return nil, nil, services.DispatchDeprovisionResultFailure
 				},
 			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepositoryWith(), planService, provisionService, instanceNotBusy)
 
 			// act
-			result := instanceService.Delete(instanceName)
+			_, _, result := instanceService.Delete(instanceName, false)
 
 			// assert
 			Expect(result).To(Equal(services.InstanceDeletionDeprovisionFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(1))
-			Expect(provisionService.DispatchDeprovisionCalls()).To(HaveLen(1))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(0))
+			Expect(instanceRepository.DeleteCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareDeprovisionCalls()).To(HaveLen(1))
 		})
 
-		It("indicates when deletes successfully and dispatches deprovision", func() {
+		It("indicates when the instance is busy with another operation", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					val := map[string]string {
-						"Status": string(models.InstanceStatusRunning),
-					}
-					return redis.NewStringStringMapResult(val, nil)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
+			}
+			provisionService := &mocks.ProvisionServiceMock{}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepositoryWith(), planService, provisionService, instanceBusy)
+
+			// act
+			_, _, result := instanceService.Delete(instanceName, false)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionBusy))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareDeprovisionCalls()).To(HaveLen(0))
+		})
+
+		It("refuses to delete a pending instance, as its operation may not have been picked up yet", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusPending),
+			}
+			provisionService := &mocks.ProvisionServiceMock{}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepositoryWith(), planService, provisionService, instanceNotBusy)
+
+			// act
+			_, _, result := instanceService.Delete(instanceName, false)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionBusy))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareDeprovisionCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when the instance is busy, abandoning the deprovision, when its status changed meanwhile", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
+				UpdateWithTaskFunc: func(name string, update *repositories.InstanceUpdate, task *models.PendingTask) error {
+					return repositories.ErrStatusChanged
+				},
+			}
+			provisionService := &mocks.ProvisionServiceMock{
+				PrepareDeprovisionFunc: prepareDeprovisionSucceeds,
+				AbandonOperationFunc:   func(operation *models.Operation, reason string) {},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepositoryWith(), planService, provisionService, instanceNotBusy)
+
+			// act
+			_, operation, result := instanceService.Delete(instanceName, false)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionBusy))
+			Expect(operation).To(BeNil())
+			Expect(provisionService.AbandonOperationCalls()).To(HaveLen(1))
+		})
+
+		It("marks instance as deprovisioning along with the deprovision, keeping the record", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc:            instanceWithStatus(models.InstanceStatusRunning),
+				UpdateWithTaskFunc: updateWithTaskSucceeds,
+			}
+			provisionService := &mocks.ProvisionServiceMock{
+				PrepareDeprovisionFunc: prepareDeprovisionSucceeds,
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepositoryWith(), planService, provisionService, instanceNotBusy)
+
+			// act
+			_, _, result := instanceService.Delete(instanceName, false)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionSuccess))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(1))
+			Expect(instanceRepository.UpdateWithTaskCalls()[0].Update).To(Equal(&repositories.InstanceUpdate{
+				Status: models.InstanceStatusDeprovisioning,
+				Reason: "deleted",
+				From:   models.InstanceStatusRunning,
+			}))
+			Expect(instanceRepository.UpdateWithTaskCalls()[0].Task.Id).To(Equal("task-1"))
+			Expect(instanceRepository.DeleteCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareDeprovisionCalls()).To(HaveLen(1))
+		})
+
+		It("indicates when fails to check the bound apps", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
+			}
+			bindRepository := &mocks.BindRepositoryMock{
+				GetBindAppsFunc: func(instanceName string) ([]*models.BindApp, error) {
+					return nil, errors.New("some error")
 				},
-				DelFunc: func(keys ...string) *redis.IntCmd {
-					return redis.NewIntResult(1, nil)
+			}
+			provisionService := &mocks.ProvisionServiceMock{}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepository, planService, provisionService, instanceNotBusy)
+
+			// act
+			_, _, result := instanceService.Delete(instanceName, false)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionFailure))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareDeprovisionCalls()).To(HaveLen(0))
+		})
+
+		It("refuses to delete while apps are bound, listing them", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
+			}
+			bindRepository := bindRepositoryWith("app-1", "app-2")
+			provisionService := &mocks.ProvisionServiceMock{}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepository, planService, provisionService, instanceNotBusy)
+
+			// act
+			appNames, _, result := instanceService.Delete(instanceName, false)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionHasBindings))
+			Expect(appNames).To(Equal([]string{"app-1", "app-2"}))
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(0))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareDeprovisionCalls()).To(HaveLen(0))
+		})
+
+		It("unbinds the bound apps before deleting when forced", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc:            instanceWithStatus(models.InstanceStatusRunning),
+				UpdateWithTaskFunc: updateWithTaskSucceeds,
+			}
+			bindRepository := bindRepositoryWith("app-1", "app-2")
+			provisionService := &mocks.ProvisionServiceMock{
+				PrepareDeprovisionFunc: prepareDeprovisionSucceeds,
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, bindRepository, planService, provisionService, instanceNotBusy)
+
+			// act
+			appNames, operation, result := instanceService.Delete(instanceName, true)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionSuccess))
+			Expect(operation.Id).To(Equal("operation-1"))
+			Expect(appNames).To(BeEmpty())
+			Expect(bindRepository.DelBindAppCalls()).To(HaveLen(2))
+			Expect(bindRepository.DelBindAppCalls()[0].AppName).To(Equal("app-1"))
+			Expect(bindRepository.DelBindAppCalls()[1].AppName).To(Equal("app-2"))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(1))
+			Expect(provisionService.PrepareDeprovisionCalls()).To(HaveLen(1))
+		})
+	})
+
+	Describe("Recreate", func() {
+		prepareRecreateSucceeds := func(instance *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchRecreateResult) {
+			return &models.Operation{Id: "operation-1"}, &models.PendingTask{Id: "task-1"}, services.DispatchRecreateResultSuccess
+		}
+
+		It("marks the instance as pending along with the recreate task, from the status it was found with", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				UpdateWithTaskFunc: func(name string, update *repositories.InstanceUpdate, task *models.PendingTask) error {
+					return nil
 				},
 			}
 			provisionService := &mocks.ProvisionServiceMock{
-				DispatchDeprovisionFunc: func(instance *models.Instance) services.DispatchDeprovisionResult {
-					return services.DispatchDeprovisionResultSuccess
+				PrepareRecreateFunc: prepareRecreateSucceeds,
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceNotBusy)
+
+			// act
+			operation, result := instanceService.Recreate(&models.Instance{Name: instanceName, Status: models.InstanceStatusDegraded}, "recreating push-api")
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateSuccess))
+			Expect(operation.Id).To(Equal("operation-1"))
+			Expect(provisionService.PrepareRecreateCalls()[0].In1.Status).To(Equal(models.InstanceStatusPending))
+			Expect(instanceRepository.UpdateWithTaskCalls()).To(HaveLen(1))
+			Expect(instanceRepository.UpdateWithTaskCalls()[0].Update).To(Equal(&repositories.InstanceUpdate{
+				Status: models.InstanceStatusPending,
+				Reason: "recreating push-api",
+				From:   models.InstanceStatusDegraded,
+			}))
+			Expect(instanceRepository.UpdateWithTaskCalls()[0].Task.Id).To(Equal("task-1"))
+		})
+
+		It("abandons the operation when the instance changed meanwhile", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				UpdateWithTaskFunc: func(name string, update *repositories.InstanceUpdate, task *models.PendingTask) error {
+					return repositories.ErrStatusChanged
+				},
+			}
+			provisionService := &mocks.ProvisionServiceMock{
+				PrepareRecreateFunc:  prepareRecreateSucceeds,
+				AbandonOperationFunc: func(operation *models.Operation, reason string) {},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, instanceNotBusy)
+
+			// act
+			operation, result := instanceService.Recreate(&models.Instance{Name: instanceName, Status: models.InstanceStatusRunning}, "recreating push-api")
+
+			// assert
+			Expect(result).To(Equal(services.InstanceUpdateStatusChanged))
+			Expect(operation).To(BeNil())
+			Expect(provisionService.AbandonOperationCalls()).To(HaveLen(1))
+		})
+	})
+
+	Describe("Remove", func() {
+		It("indicates when instance is not found", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				DeleteFunc: func(name string) error {
+					return repositories.ErrNotFound
+				},
+			}
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, &mocks.ProvisionServiceMock{}, nil)
+
+			// act
+			result := instanceService.Remove(instanceName)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceDeletionNotFound))
+			Expect(instanceRepository.DeleteCalls()).To(HaveLen(1))
+			Expect(instanceRepository.DelVarsCalls()).To(HaveLen(0))
+		})
+
+		It("removes the instance and its vars", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				DeleteFunc: func(name string) error {
+					return nil
+				},
+				DelVarsFunc: func(name string) error {
+					return nil
 				},
 			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, &mocks.ProvisionServiceMock{}, nil)
 
 			// act
-			result := instanceService.Delete(instanceName)
+			result := instanceService.Remove(instanceName)
 
 			// assert
 			Expect(result).To(Equal(services.InstanceDeletionSuccess))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.DelCalls()).To(HaveLen(1))
-			Expect(provisionService.DispatchDeprovisionCalls()).To(HaveLen(1))
+			Expect(instanceRepository.DeleteCalls()).To(HaveLen(1))
+			Expect(instanceRepository.DelVarsCalls()).To(HaveLen(1))
 		})
 	})
 
 	Describe("Create", func() {
+		newProvisionService := func() *mocks.ProvisionServiceMock {
+			return &mocks.ProvisionServiceMock{
+				PrepareProvisionFunc: func(instance *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchProvisionResult) {
+					return &models.Operation{Id: "operation-1"}, &models.PendingTask{Id: "task-1"}, services.DispatchProvisionResultSuccess
+				},
+				AbandonOperationFunc: func(operation *models.Operation, reason string) {
+				},
+			}
+		}
+
 		It("indicates when instance with same name already exists", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					val := map[string]string {
-						"Status": string(models.InstanceStatusRunning),
-					}
-					return redis.NewStringStringMapResult(val, nil)
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceWithStatus(models.InstanceStatusRunning),
+			}
+			provisionService := newProvisionService()
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, nil)
+
+			// act
+			_, result := instanceService.Create(instanceForm)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceCreationAlreadyExist))
+			Expect(instanceRepository.GetCalls()).To(HaveLen(1))
+			Expect(instanceRepository.CreateWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareProvisionCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when instance with same name is created concurrently", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
+				CreateWithTaskFunc: func(instance *models.Instance, task *models.PendingTask) error {
+					return repositories.ErrAlreadyExists
 				},
 			}
-			provisionService := &mocks.ProvisionServiceMock{}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			provisionService := newProvisionService()
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, nil)
 
 			// act
-			result := instanceService.Create(instanceForm)
+			_, result := instanceService.Create(instanceForm)
 
 			// assert
 			Expect(result).To(Equal(services.InstanceCreationAlreadyExist))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HSetCalls()).To(HaveLen(0))
-			Expect(provisionService.DispatchProvisionCalls()).To(HaveLen(0))
+			Expect(provisionService.AbandonOperationCalls()).To(HaveLen(1))
+			Expect(provisionService.AbandonOperationCalls()[0].Operation.Id).To(Equal("operation-1"))
+		})
+
+		It("lets only one of concurrent creations of the same instance succeed", func() {
+			// arrange
+			instanceRepository, cleanup := newRedisRepository()
+			defer cleanup()
+			provisionService := newProvisionService()
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, nil)
+
+			// act
+			results := make([]services.InstanceCreationResult, 20)
+			var wg sync.WaitGroup
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, results[i] = instanceService.Create(instanceForm)
+				}(i)
+			}
+			wg.Wait()
+
+			// assert
+			succeeded := 0
+			for _, result := range results {
+				if result == services.InstanceCreationSuccess {
+					succeeded++
+				} else {
+					Expect(result).To(Equal(services.InstanceCreationAlreadyExist))
+				}
+			}
+			Expect(succeeded).To(Equal(1))
+			Expect(provisionService.AbandonOperationCalls()).To(HaveLen(len(provisionService.PrepareProvisionCalls()) - 1))
+			pending, err := instanceRepository.ClaimPendingTasks(time.Now().Add(time.Minute), 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(HaveLen(1))
 		})
 
 		It("indicates when fails to check instance existence", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, errors.New("some error"))
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: func(name string) (*models.Instance, error) {
+					return nil, errors.New("some error")
 				},
 			}
-			provisionService := &mocks.ProvisionServiceMock{}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			provisionService := newProvisionService()
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, nil)
 
 			// act
-			result := instanceService.Create(instanceForm)
+			_, result := instanceService.Create(instanceForm)
 
 			// assert
 			Expect(result).To(Equal(services.InstanceCreationFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HSetCalls()).To(HaveLen(0))
-			Expect(provisionService.DispatchProvisionCalls()).To(HaveLen(0))
+			Expect(instanceRepository.GetCalls()).To(HaveLen(1))
+			Expect(instanceRepository.CreateWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareProvisionCalls()).To(HaveLen(0))
 		})
 
-		It("indicates when fails to check instance existence", func() {
+		It("indicates when data is invalid", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, nil)
-				},
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
 			}
-			provisionService := &mocks.ProvisionServiceMock{}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			provisionService := newProvisionService()
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, nil)
 			instanceFormInvalid := &models.InstanceForm{}
 
 			// act
-			result := instanceService.Create(instanceFormInvalid)
+			_, result := instanceService.Create(instanceFormInvalid)
 
 			// assert
 			Expect(result).To(Equal(services.InstanceCreationInvalidData))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HSetCalls()).To(HaveLen(0))
-			Expect(provisionService.DispatchProvisionCalls()).To(HaveLen(0))
+			Expect(instanceRepository.GetCalls()).To(HaveLen(1))
+			Expect(instanceRepository.CreateWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareProvisionCalls()).To(HaveLen(0))
+		})
+
+		It("indicates when the plan is not configured", func() {
+			// arrange
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
+			}
+			provisionService := newProvisionService()
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, nil)
+			instanceFormUnknownPlan := &models.InstanceForm{
+				Name: instanceName,
+				Team: "pushaas-team",
+				User: "rafael",
+				Plan: "unknown",
+			}
+
+			// act
+			_, result := instanceService.Create(instanceFormUnknownPlan)
+
+			// assert
+			Expect(result).To(Equal(services.InstanceCreationInvalidData))
+			Expect(instanceRepository.CreateWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareProvisionCalls()).To(HaveLen(0))
 		})
 
 		It("indicates when fails to create instance", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, nil)
-				},
-				HMSetFunc: func(key string, fields map[string]interface{}) *redis.StatusCmd {
-					return redis.NewStatusResult("", errors.New("some error"))
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
+				CreateWithTaskFunc: func(instance *models.Instance, task *models.PendingTask) error {
+					return errors.New("some error")
 				},
 			}
-			provisionService := &mocks.ProvisionServiceMock{}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			provisionService := newProvisionService()
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, nil)
 
 			// act
-			result := instanceService.Create(instanceForm)
+			_, result := instanceService.Create(instanceForm)
 
 			// assert
 			Expect(result).To(Equal(services.InstanceCreationFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HMSetCalls()).To(HaveLen(1))
-			Expect(provisionService.DispatchProvisionCalls()).To(HaveLen(0))
+			Expect(instanceRepository.GetCalls()).To(HaveLen(1))
+			Expect(instanceRepository.CreateWithTaskCalls()).To(HaveLen(1))
+			Expect(provisionService.AbandonOperationCalls()).To(HaveLen(1))
 		})
 
-		It("indicates when creates instance but fails to dispatch provision", func() {
+		It("indicates when fails to prepare provision, not creating the instance", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, nil)
-				},
-				HMSetFunc: func(key string, fields map[string]interface{}) *redis.StatusCmd {
-					return redis.NewStatusResult("", nil)
-				},
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
 			}
 			provisionService := &mocks.ProvisionServiceMock{
-				DispatchProvisionFunc: func(instance *models.Instance) services.DispatchProvisionResult {
-					return services.DispatchProvisionResultFailure
+				PrepareProvisionFunc: func(instance *models.Instance) (*models.Operation, *models.PendingTask, services.DispatchProvisionResult) {
+					return nil, nil, services.DispatchProvisionResultFailure
 				},
 			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, nil)
 
 			// act
-			result := instanceService.Create(instanceForm)
+			_, result := instanceService.Create(instanceForm)
 
 			// assert
 			Expect(result).To(Equal(services.InstanceCreationProvisionFailure))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HMSetCalls()).To(HaveLen(1))
-			Expect(provisionService.DispatchProvisionCalls()).To(HaveLen(1))
+			Expect(instanceRepository.GetCalls()).To(HaveLen(1))
+			Expect(instanceRepository.CreateWithTaskCalls()).To(HaveLen(0))
+			Expect(provisionService.PrepareProvisionCalls()).To(HaveLen(1))
 		})
 
-		It("indicates when creates instance and dispatches provision successfully", func() {
+		It("creates instance along with its provision", func() {
 			// arrange
-			redisClient := &mocks.UniversalClientMock{
-				HGetAllFunc: func(key string) *redis.StringStringMapCmd {
-					return redis.NewStringStringMapResult(nil, nil)
-				},
-				HMSetFunc: func(key string, fields map[string]interface{}) *redis.StatusCmd {
-					return redis.NewStatusResult("", nil)
-				},
-			}
-			provisionService := &mocks.ProvisionServiceMock{
-				DispatchProvisionFunc: func(instance *models.Instance) services.DispatchProvisionResult {
-					return services.DispatchProvisionResultSuccess
+			instanceRepository := &mocks.InstanceRepositoryMock{
+				GetFunc: instanceNotFound,
+				CreateWithTaskFunc: func(instance *models.Instance, task *models.PendingTask) error {
+					return nil
 				},
 			}
-			instanceService := services.NewInstanceService(config, logger, redisClient, provisionService)
+			provisionService := newProvisionService()
+			instanceService := services.NewInstanceService(config, logger, instanceRepository, nil, planService, provisionService, nil)
 
 			// act
-			result := instanceService.Create(instanceForm)
+			operation, result := instanceService.Create(instanceForm)
 
 			// assert
 			Expect(result).To(Equal(services.InstanceCreationSuccess))
-			Expect(redisClient.HGetAllCalls()).To(HaveLen(1))
-			Expect(redisClient.HMSetCalls()).To(HaveLen(1))
-			Expect(provisionService.DispatchProvisionCalls()).To(HaveLen(1))
+			Expect(operation.Id).To(Equal("operation-1"))
+			Expect(instanceRepository.GetCalls()).To(HaveLen(1))
+			Expect(instanceRepository.CreateWithTaskCalls()).To(HaveLen(1))
+			Expect(instanceRepository.CreateWithTaskCalls()[0].Instance.Status).To(Equal(models.InstanceStatusPending))
+			Expect(instanceRepository.CreateWithTaskCalls()[0].Task.Id).To(Equal("task-1"))
+			Expect(provisionService.PrepareProvisionCalls()).To(HaveLen(1))
+			Expect(provisionService.AbandonOperationCalls()).To(HaveLen(0))
 		})
 	})
 })
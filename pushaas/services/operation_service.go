@@ -0,0 +1,208 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/go-redis/redis"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+)
+
+type (
+	OperationCreationResult  int
+	OperationRetrievalResult int
+
+	/*
+		Keeps the operations on redis, one key each, for a while after they were last touched. The workers
+		record their progress on them as they go, which, as the audit, is a side effect of the work, so the
+		failures to record it are only logged. The tasks that carry no operation (as the ones of the
+		reconciler) are given an empty id, which is ignored.
+	*/
+	OperationService interface {
+		Create(operationType models.OperationType, instance *models.Instance) (*models.Operation, OperationCreationResult)
+		Get(id string) (*models.Operation, OperationRetrievalResult)
+		Start(id string)
+		StartStep(id, step string)
+		FinishStep(id, step string, failed bool, reason string)
+		Finish(id string, failed bool, reason string)
+	}
+
+	operationService struct {
+		logger      *zap.Logger
+		prefix      string
+		ttl         time.Duration
+		redisClient redis.UniversalClient
+	}
+)
+
+const (
+	OperationCreationSuccess OperationCreationResult = iota
+	OperationCreationFailure
+)
+
+const (
+	OperationRetrievalSuccess OperationRetrievalResult = iota
+	OperationRetrievalNotFound
+	OperationRetrievalFailure
+)
+
+// how many times a change is tried when the operation is changed by someone else meanwhile
+const operationUpdateAttempts = 3
+
+func (s *operationService) keyFor(id string) string {
+	return fmt.Sprintf("%s:%s", s.prefix, id)
+}
+
+func (s *operationService) Create(operationType models.OperationType, instance *models.Instance) (*models.Operation, OperationCreationResult) {
+	now := time.Now().UTC()
+	operation := &models.Operation{
+		Id:        uniuri.New(),
+		Type:      operationType,
+		Instance:  instance.Name,
+		Team:      instance.Team,
+		State:     models.OperationStatePending,
+		Steps:     []*models.OperationStep{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	bytes, err := json.Marshal(operation)
+	if err != nil {
+		s.logger.Error("failed to encode operation", zap.Any("operation", operation), zap.Error(err))
+		return nil, OperationCreationFailure
+	}
+
+	err = s.redisClient.Set(s.keyFor(operation.Id), string(bytes), s.ttl).Err()
+	if err != nil {
+		s.logger.Error("failed to create operation", zap.Any("operation", operation), zap.Error(err))
+		return nil, OperationCreationFailure
+	}
+
+	return operation, OperationCreationSuccess
+}
+
+func (s *operationService) Get(id string) (*models.Operation, OperationRetrievalResult) {
+	value, err := s.redisClient.Get(s.keyFor(id)).Result()
+	if err == redis.Nil {
+		return nil, OperationRetrievalNotFound
+	} else if err != nil {
+		s.logger.Error("failed to retrieve operation", zap.String("id", id), zap.Error(err))
+		return nil, OperationRetrievalFailure
+	}
+
+	var operation models.Operation
+	err = json.Unmarshal([]byte(value), &operation)
+	if err != nil {
+		s.logger.Error("failed to decode operation", zap.String("id", id), zap.Error(err))
+		return nil, OperationRetrievalFailure
+	}
+	return &operation, OperationRetrievalSuccess
+}
+
+/*
+	the change is applied on what is on redis at the time, and written only if nobody else wrote it meanwhile
+*/
+func (s *operationService) update(id string, change func(operation *models.Operation, now time.Time)) {
+	if id == "" {
+		return
+	}
+
+	key := s.keyFor(id)
+	apply := func(tx *redis.Tx) error {
+		value, err := tx.Get(key).Result()
+		if err != nil {
+			return err
+		}
+
+		var operation models.Operation
+		err = json.Unmarshal([]byte(value), &operation)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		change(&operation, now)
+		operation.UpdatedAt = now
+
+		bytes, err := json.Marshal(&operation)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.Set(key, string(bytes), s.ttl)
+			return nil
+		})
+		return err
+	}
+
+	var err error
+	for i := 0; i < operationUpdateAttempts; i++ {
+		err = s.redisClient.Watch(apply, key)
+		if err != redis.TxFailedErr {
+			break
+		}
+	}
+
+	if err == redis.Nil {
+		s.logger.Warn("operation not found to be updated, it may have expired", zap.String("id", id))
+	} else if err != nil {
+		s.logger.Error("failed to update operation", zap.String("id", id), zap.Error(err))
+	}
+}
+
+/*
+	a replayed task starts its operation over, even if it had already failed
+*/
+func (s *operationService) Start(id string) {
+	s.update(id, func(operation *models.Operation, now time.Time) {
+		operation.State = models.OperationStateRunning
+		operation.Error = ""
+		operation.FinishedAt = nil
+	})
+}
+
+func (s *operationService) StartStep(id, step string) {
+	s.update(id, func(operation *models.Operation, now time.Time) {
+		operation.Steps = append(operation.Steps, &models.OperationStep{Name: step, StartedAt: now})
+	})
+}
+
+// it is the last attempt of the step that is finished
+func (s *operationService) FinishStep(id, step string, failed bool, reason string) {
+	s.update(id, func(operation *models.Operation, now time.Time) {
+		for i := len(operation.Steps) - 1; i >= 0; i-- {
+			if operation.Steps[i].Name == step {
+				operation.Steps[i].Failed = failed
+				operation.Steps[i].Reason = reason
+				operation.Steps[i].FinishedAt = &now
+				return
+			}
+		}
+	})
+}
+
+func (s *operationService) Finish(id string, failed bool, reason string) {
+	s.update(id, func(operation *models.Operation, now time.Time) {
+		operation.State = models.OperationStateSucceeded
+		if failed {
+			operation.State = models.OperationStateFailed
+			operation.Error = reason
+		}
+		operation.FinishedAt = &now
+	})
+}
+
+func NewOperationService(config *viper.Viper, logger *zap.Logger, redisClient redis.UniversalClient) OperationService {
+	return &operationService{
+		logger:      logger.Named("operationService"),
+		prefix:      config.GetString("redis.db.operation.prefix"),
+		ttl:         config.GetDuration("redis.db.operation.ttl"),
+		redisClient: redisClient,
+	}
+}
@@ -0,0 +1,171 @@
+package services_test
+
+import (
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("OperationService", func() {
+	var (
+		server      *miniredis.Miniredis
+		redisClient *redis.Client
+		service     services.OperationService
+	)
+
+	instance := &models.Instance{Name: "instance-1", Team: "team-1"}
+
+	BeforeEach(func() {
+		var err error
+		server, err = miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		redisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+		config := viper.New()
+		config.Set("redis.db.operation.prefix", "operation")
+		config.Set("redis.db.operation.ttl", "1h")
+		service = services.NewOperationService(config, logger, redisClient)
+	})
+
+	AfterEach(func() {
+		_ = redisClient.Close()
+		server.Close()
+	})
+
+	_ = Describe("Create", func() {
+		_ = It("keeps the operation as pending for a while", func() {
+			// act
+			operation, result := service.Create(models.OperationTypeProvision, instance)
+
+			// assert
+			Expect(result).To(Equal(services.OperationCreationSuccess))
+			Expect(operation.Id).NotTo(BeEmpty())
+			Expect(server.TTL("operation:" + operation.Id)).To(Equal(time.Hour))
+			stored, resultGet := service.Get(operation.Id)
+			Expect(resultGet).To(Equal(services.OperationRetrievalSuccess))
+			Expect(stored.Type).To(Equal(models.OperationTypeProvision))
+			Expect(stored.Instance).To(Equal("instance-1"))
+			Expect(stored.Team).To(Equal("team-1"))
+			Expect(stored.State).To(Equal(models.OperationStatePending))
+			Expect(stored.IsFinished()).To(BeFalse())
+		})
+
+		_ = It("indicates when fails to create", func() {
+			// arrange
+			server.Close()
+
+			// act
+			operation, result := service.Create(models.OperationTypeProvision, instance)
+
+			// assert
+			Expect(result).To(Equal(services.OperationCreationFailure))
+			Expect(operation).To(BeNil())
+		})
+	})
+
+	_ = Describe("Get", func() {
+		_ = It("indicates when the operation is not found", func() {
+			// act
+			_, result := service.Get("unknown")
+
+			// assert
+			Expect(result).To(Equal(services.OperationRetrievalNotFound))
+		})
+
+		_ = It("indicates when fails to retrieve", func() {
+			// arrange
+			server.Close()
+
+			// act
+			_, result := service.Get("operation-1")
+
+			// assert
+			Expect(result).To(Equal(services.OperationRetrievalFailure))
+		})
+	})
+
+	_ = Describe("progress", func() {
+		_ = It("records the steps and the end of a successful operation", func() {
+			// arrange
+			operation, _ := service.Create(models.OperationTypeProvision, instance)
+
+			// act
+			service.Start(operation.Id)
+			service.StartStep(operation.Id, "provision")
+			service.FinishStep(operation.Id, "provision", false, "")
+			service.Finish(operation.Id, false, "")
+
+			// assert
+			stored, _ := service.Get(operation.Id)
+			Expect(stored.State).To(Equal(models.OperationStateSucceeded))
+			Expect(stored.IsFinished()).To(BeTrue())
+			Expect(stored.FinishedAt).NotTo(BeNil())
+			Expect(stored.Steps).To(HaveLen(1))
+			Expect(stored.Steps[0].Name).To(Equal("provision"))
+			Expect(stored.Steps[0].Failed).To(BeFalse())
+			Expect(stored.Steps[0].FinishedAt).NotTo(BeNil())
+		})
+
+		_ = It("finishes the last attempt of a step and tells why the operation failed", func() {
+			// arrange
+			operation, _ := service.Create(models.OperationTypeUpdate, instance)
+			service.Start(operation.Id)
+			service.StartStep(operation.Id, "update")
+			service.FinishStep(operation.Id, "update", true, "first error")
+			service.StartStep(operation.Id, "update")
+
+			// act
+			service.FinishStep(operation.Id, "update", true, "second error")
+			service.Finish(operation.Id, true, "second error")
+
+			// assert
+			stored, _ := service.Get(operation.Id)
+			Expect(stored.State).To(Equal(models.OperationStateFailed))
+			Expect(stored.Error).To(Equal("second error"))
+			Expect(stored.Steps).To(HaveLen(2))
+			Expect(stored.Steps[0].Reason).To(Equal("first error"))
+			Expect(stored.Steps[1].Reason).To(Equal("second error"))
+		})
+
+		_ = It("starts over an operation that had failed", func() {
+			// arrange
+			operation, _ := service.Create(models.OperationTypeDeprovision, instance)
+			service.Finish(operation.Id, true, "some error")
+
+			// act
+			service.Start(operation.Id)
+
+			// assert
+			stored, _ := service.Get(operation.Id)
+			Expect(stored.State).To(Equal(models.OperationStateRunning))
+			Expect(stored.Error).To(BeEmpty())
+			Expect(stored.FinishedAt).To(BeNil())
+		})
+
+		_ = It("ignores the tasks that carry no operation", func() {
+			// act
+			service.Start("")
+			service.Finish("", false, "")
+
+			// assert
+			keys, _ := redisClient.Keys("*").Result()
+			Expect(keys).To(BeEmpty())
+		})
+
+		_ = It("does not bring back an operation that expired", func() {
+			// act
+			service.Finish("expired", false, "")
+
+			// assert
+			_, result := service.Get("expired")
+			Expect(result).To(Equal(services.OperationRetrievalNotFound))
+		})
+	})
+})
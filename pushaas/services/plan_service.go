@@ -1,27 +1,83 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+
 	"github.com/pushaas/pushaas/pushaas/models"
 )
 
 type (
+	PlanRetrievalResult int
+
 	PlanService interface {
 		GetAll() []models.Plan
+		GetByName(name string) (*models.Plan, PlanRetrievalResult)
 	}
 
-	planService struct{}
+	planService struct {
+		plans []models.Plan
+	}
+)
+
+const (
+	PlanRetrievalSuccess PlanRetrievalResult = iota
+	PlanRetrievalNotFound
 )
 
 func (s *planService) GetAll() []models.Plan {
-	result := []models.Plan{
-		{
-			Name:        models.PlanSmall,
-			Description: "The only plan",
-		},
+	return s.plans
+}
+
+func (s *planService) GetByName(name string) (*models.Plan, PlanRetrievalResult) {
+	for i := range s.plans {
+		if s.plans[i].Name == name {
+			return &s.plans[i], PlanRetrievalSuccess
+		}
+	}
+	return nil, PlanRetrievalNotFound
+}
+
+func validatePlans(plans []models.Plan) error {
+	if len(plans) == 0 {
+		return errors.New("no plans configured")
 	}
 
-	return result
+	names := map[string]bool{}
+	for _, plan := range plans {
+		if plan.Name == "" {
+			return errors.New("plan configured without name")
+		}
+		if names[plan.Name] {
+			return errors.New(fmt.Sprintf("plan %s configured more than once", plan.Name))
+		}
+		names[plan.Name] = true
+
+		if plan.Cpu <= 0 || plan.Memory <= 0 {
+			return errors.New(fmt.Sprintf("plan %s must have positive cpu and memory", plan.Name))
+		}
+		if plan.MaxChannels < 0 || plan.MaxSubscribers < 0 {
+			return errors.New(fmt.Sprintf("plan %s must not have negative max_channels or max_subscribers", plan.Name))
+		}
+	}
+	return nil
 }
-func NewPlanService() PlanService {
-	return &planService{}
+
+func NewPlanService(config *viper.Viper) (PlanService, error) {
+	var plans []models.Plan
+	err := config.UnmarshalKey("plans", &plans)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validatePlans(plans)
+	if err != nil {
+		return nil, err
+	}
+
+	return &planService{
+		plans: plans,
+	}, nil
 }
@@ -3,23 +3,117 @@ package services_test
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
 
+	"github.com/pushaas/pushaas/pushaas/models"
 	"github.com/pushaas/pushaas/pushaas/services"
 )
 
 var _ = Describe("PlanService", func() {
+	newConfig := func(plans ...map[string]interface{}) *viper.Viper {
+		config := viper.New()
+		config.Set("plans", plans)
+		return config
+	}
+
+	small := map[string]interface{}{
+		"name":        "small",
+		"description": "For apps with few subscribers",
+		"cpu":         512,
+		"memory":      1024,
+	}
+	large := map[string]interface{}{
+		"name":            "large",
+		"description":     "For high-fanout apps",
+		"cpu":             1024,
+		"memory":          2048,
+		"max_channels":    100,
+		"max_subscribers": 1000,
+	}
+
+	Describe("NewPlanService", func() {
+		It("fails when no plans are configured", func() {
+			// act
+			planService, err := services.NewPlanService(viper.New())
+
+			// assert
+			Expect(err).To(HaveOccurred())
+			Expect(planService).To(BeNil())
+		})
+
+		It("fails when a plan is configured more than once", func() {
+			// act
+			planService, err := services.NewPlanService(newConfig(small, small))
+
+			// assert
+			Expect(err).To(HaveOccurred())
+			Expect(planService).To(BeNil())
+		})
+
+		It("fails when a plan has no size", func() {
+			// act
+			planService, err := services.NewPlanService(newConfig(map[string]interface{}{"name": "empty"}))
+
+			// assert
+			Expect(err).To(HaveOccurred())
+			Expect(planService).To(BeNil())
+		})
+	})
+
 	Describe("GetAll", func() {
-		It("should return the available plans", func() {
+		It("should return the configured plans", func() {
 			// arrange
-			planService := services.NewPlanService()
+			planService, err := services.NewPlanService(newConfig(small, large))
+			Expect(err).NotTo(HaveOccurred())
 
 			// act
 			plans := planService.GetAll()
 
 			// assert
-			Expect(len(plans)).To(Equal(1))
-			Expect(plans[0].Name).To(Equal("small"))
-			Expect(plans[0].Description).To(Equal("The only plan"))
+			Expect(plans).To(Equal([]models.Plan{
+				{
+					Name:        "small",
+					Description: "For apps with few subscribers",
+					Cpu:         512,
+					Memory:      1024,
+				},
+				{
+					Name:           "large",
+					Description:    "For high-fanout apps",
+					Cpu:            1024,
+					Memory:         2048,
+					MaxChannels:    100,
+					MaxSubscribers: 1000,
+				},
+			}))
+		})
+	})
+
+	Describe("GetByName", func() {
+		It("returns the plan with the name", func() {
+			// arrange
+			planService, err := services.NewPlanService(newConfig(small, large))
+			Expect(err).NotTo(HaveOccurred())
+
+			// act
+			plan, result := planService.GetByName("large")
+
+			// assert
+			Expect(result).To(Equal(services.PlanRetrievalSuccess))
+			Expect(plan.Cpu).To(Equal(int64(1024)))
+		})
+
+		It("indicates when the plan is not configured", func() {
+			// arrange
+			planService, err := services.NewPlanService(newConfig(small))
+			Expect(err).NotTo(HaveOccurred())
+
+			// act
+			plan, result := planService.GetByName("large")
+
+			// assert
+			Expect(result).To(Equal(services.PlanRetrievalNotFound))
+			Expect(plan).To(BeNil())
 		})
 	})
 })
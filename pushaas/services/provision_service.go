@@ -2,9 +2,9 @@ package services
 
 import (
 	"encoding/json"
+	"time"
 
-	"github.com/RichardKnop/machinery/v1"
-	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/dchest/uniuri"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
@@ -12,19 +12,34 @@ import (
 )
 
 type (
-	DispatchProvisionResult   int
-	DispatchDeprovisionResult int
-
+	DispatchProvisionResult         int
+	DispatchDeprovisionResult       int
+	DispatchUpdateResult            int
+	DispatchRotateCredentialsResult int
+	DispatchRecreateResult          int
+
+	/*
+		The tasks are not sent from here, but handed back as pending tasks, to be written along with the change of
+		the instance they are for and sent by the relay of the outbox. Their operation is
+		created right away, so it has to be abandoned when the pending task ends up not being written.
+	*/
 	ProvisionService interface {
-		DispatchProvision(*models.Instance) DispatchProvisionResult
-		DispatchDeprovision(*models.Instance) DispatchDeprovisionResult
+		PrepareProvision(*models.Instance) (*models.Operation, *models.PendingTask, DispatchProvisionResult)
+		PrepareDeprovision(*models.Instance) (*models.Operation, *models.PendingTask, DispatchDeprovisionResult)
+		AbandonOperation(operation *models.Operation, reason string)
+		PrepareUpdate(*models.InstanceChange) (*models.Operation, *models.PendingTask, DispatchUpdateResult)
+		PrepareRotateCredentials(*models.InstanceChange) (*models.Operation, *models.PendingTask, DispatchRotateCredentialsResult)
+		PrepareRecreate(*models.Instance) (*models.Operation, *models.PendingTask, DispatchRecreateResult)
 	}
 
 	provisionService struct {
-		logger              *zap.Logger
-		machineryServer     *machinery.Server
-		provisionTaskName   string
-		deprovisionTaskName string
+		logger                    *zap.Logger
+		provisionTaskName         string
+		deprovisionTaskName       string
+		updateTaskName            string
+		rotateCredentialsTaskName string
+		recreateTaskName          string
+		operationService          OperationService
 	}
 )
 
@@ -38,73 +53,96 @@ const (
 	DispatchDeprovisionResultFailure
 )
 
-func (s *provisionService) buildProvisionSignature(messageJson *string) *tasks.Signature {
-	return &tasks.Signature{
-		Name: s.provisionTaskName,
-		Args: []tasks.Arg{
-			{
-				Type:  "string",
-				Value: *messageJson,
-			},
-		},
-	}
-}
+const (
+	DispatchUpdateResultSuccess DispatchUpdateResult = iota
+	DispatchUpdateResultFailure
+)
+
+const (
+	DispatchRotateCredentialsResultSuccess DispatchRotateCredentialsResult = iota
+	DispatchRotateCredentialsResultFailure
+)
+
+const (
+	DispatchRecreateResultSuccess DispatchRecreateResult = iota
+	DispatchRecreateResultFailure
+)
 
-func (s *provisionService) DispatchProvision(instance *models.Instance) DispatchProvisionResult {
-	bytes, err := json.Marshal(instance)
+// the payload is the instance itself, or the change being applied to it
+func (s *provisionService) preparePendingTask(taskName string, operationType models.OperationType, instance *models.Instance, payload interface{}) (*models.Operation, *models.PendingTask, bool) {
+	bytes, err := json.Marshal(payload)
 	if err != nil {
-		s.logger.Error("error marshaling instance", zap.Any("instance", instance), zap.Error(err))
-		return DispatchProvisionResultFailure
+		s.logger.Error("error marshaling instance", zap.Any("payload", payload), zap.Error(err))
+		return nil, nil, false
 	}
 
-	messageJson := string(bytes)
-	signature := s.buildProvisionSignature(&messageJson)
-	_, err = s.machineryServer.SendTask(signature)
-	if err != nil {
-		s.logger.Error("error dispatching provision for instance", zap.Any("instance", instance), zap.Error(err))
-		return DispatchProvisionResultFailure
+	operation, resultOperation := s.operationService.Create(operationType, instance)
+	if resultOperation != OperationCreationSuccess {
+		return nil, nil, false
 	}
 
-	s.logger.Debug("instance provision dispatched", zap.Any("instance", instance))
-	return DispatchProvisionResultSuccess
+	task := &models.PendingTask{
+		Id:          uniuri.New(),
+		TaskName:    taskName,
+		Payload:     string(bytes),
+		OperationId: operation.Id,
+		CreatedAt:   time.Now().UTC(),
+	}
+	return operation, task, true
 }
 
-func (s *provisionService) buildDeprovisionSignature(messageJson string) *tasks.Signature {
-	return &tasks.Signature{
-		Name: s.deprovisionTaskName,
-		Args: []tasks.Arg{
-			{
-				Type:  "string",
-				Value: messageJson,
-			},
-		},
+func (s *provisionService) PrepareProvision(instance *models.Instance) (*models.Operation, *models.PendingTask, DispatchProvisionResult) {
+	operation, task, ok := s.preparePendingTask(s.provisionTaskName, models.OperationTypeProvision, instance, instance)
+	if !ok {
+		return nil, nil, DispatchProvisionResultFailure
 	}
+	return operation, task, DispatchProvisionResultSuccess
 }
 
-func (s *provisionService) DispatchDeprovision(instance *models.Instance) DispatchDeprovisionResult {
-	bytes, err := json.Marshal(instance)
-	if err != nil {
-		s.logger.Error("error marshaling instance", zap.Any("instance", instance), zap.Error(err))
-		return DispatchDeprovisionResultFailure
+func (s *provisionService) PrepareDeprovision(instance *models.Instance) (*models.Operation, *models.PendingTask, DispatchDeprovisionResult) {
+	operation, task, ok := s.preparePendingTask(s.deprovisionTaskName, models.OperationTypeDeprovision, instance, instance)
+	if !ok {
+		return nil, nil, DispatchDeprovisionResultFailure
 	}
+	return operation, task, DispatchDeprovisionResultSuccess
+}
 
-	messageJson := string(bytes)
-	signature := s.buildDeprovisionSignature(messageJson)
-	_, err = s.machineryServer.SendTask(signature)
-	if err != nil {
-		s.logger.Error("error dispatching deprovision for instance", zap.Any("instance", instance), zap.Error(err))
-		return DispatchDeprovisionResultFailure
+func (s *provisionService) AbandonOperation(operation *models.Operation, reason string) {
+	s.operationService.Finish(operation.Id, true, reason)
+}
+
+func (s *provisionService) PrepareUpdate(change *models.InstanceChange) (*models.Operation, *models.PendingTask, DispatchUpdateResult) {
+	operation, task, ok := s.preparePendingTask(s.updateTaskName, models.OperationTypeUpdate, &change.Instance, change)
+	if !ok {
+		return nil, nil, DispatchUpdateResultFailure
 	}
+	return operation, task, DispatchUpdateResultSuccess
+}
 
-	s.logger.Debug("instance deprovision dispatched", zap.Any("instance", instance))
-	return DispatchDeprovisionResultSuccess
+func (s *provisionService) PrepareRotateCredentials(change *models.InstanceChange) (*models.Operation, *models.PendingTask, DispatchRotateCredentialsResult) {
+	operation, task, ok := s.preparePendingTask(s.rotateCredentialsTaskName, models.OperationTypeRotateCredentials, &change.Instance, change)
+	if !ok {
+		return nil, nil, DispatchRotateCredentialsResultFailure
+	}
+	return operation, task, DispatchRotateCredentialsResultSuccess
+}
+
+func (s *provisionService) PrepareRecreate(instance *models.Instance) (*models.Operation, *models.PendingTask, DispatchRecreateResult) {
+	operation, task, ok := s.preparePendingTask(s.recreateTaskName, models.OperationTypeRecreate, instance, instance)
+	if !ok {
+		return nil, nil, DispatchRecreateResultFailure
+	}
+	return operation, task, DispatchRecreateResultSuccess
 }
 
-func NewProvisionService(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server) ProvisionService {
+func NewProvisionService(config *viper.Viper, logger *zap.Logger, operationService OperationService) ProvisionService {
 	return &provisionService{
-		logger:              logger,
-		machineryServer:     machineryServer,
-		provisionTaskName:   config.GetString("redis.pubsub.tasks.provision"),
-		deprovisionTaskName: config.GetString("redis.pubsub.tasks.deprovision"),
+		logger:                    logger,
+		provisionTaskName:         config.GetString("redis.pubsub.tasks.provision"),
+		deprovisionTaskName:       config.GetString("redis.pubsub.tasks.deprovision"),
+		updateTaskName:            config.GetString("redis.pubsub.tasks.update"),
+		rotateCredentialsTaskName: config.GetString("redis.pubsub.tasks.rotate_credentials"),
+		recreateTaskName:          config.GetString("redis.pubsub.tasks.recreate"),
+		operationService:          operationService,
 	}
 }
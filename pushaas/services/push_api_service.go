@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+)
+
+type (
+	/*
+		Talks to the push-api of an instance, authenticated with the instance-wide credentials kept in the instance vars.
+		Adding a credential with a username push-api already knows replaces its password.
+	*/
+	PushApiService interface {
+		AddCredential(instanceVars map[string]string, username string, password string) error
+		RevokeCredential(instanceVars map[string]string, username string) error
+	}
+
+	pushApiService struct {
+		logger          *zap.Logger
+		httpClient      *http.Client
+		credentialsPath string
+	}
+
+	pushApiCredential struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+)
+
+func (s *pushApiService) do(instanceVars map[string]string, method string, path string, body interface{}) (*http.Response, error) {
+	endpoint := instanceVars[provisioners.EnvVarEndpoint]
+	if endpoint == "" {
+		return nil, errors.New("instance has no push-api endpoint")
+	}
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, endpoint+path, &reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(instanceVars[provisioners.EnvVarUsername], instanceVars[provisioners.EnvVarPassword])
+
+	return s.httpClient.Do(req)
+}
+
+func (s *pushApiService) AddCredential(instanceVars map[string]string, username string, password string) error {
+	res, err := s.do(instanceVars, http.MethodPost, s.credentialsPath, &pushApiCredential{
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		s.logger.Error("failed to add credential to push-api", zap.String("username", username), zap.Error(err))
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		s.logger.Error("push-api refused to add credential", zap.String("username", username), zap.Int("status", res.StatusCode))
+		return errors.New(fmt.Sprintf("push-api answered %d when adding credential", res.StatusCode))
+	}
+	return nil
+}
+
+/*
+	a credential that push-api does not know about is already revoked
+*/
+func (s *pushApiService) RevokeCredential(instanceVars map[string]string, username string) error {
+	res, err := s.do(instanceVars, http.MethodDelete, fmt.Sprintf("%s/%s", s.credentialsPath, url.PathEscape(username)), nil)
+	if err != nil {
+		s.logger.Error("failed to revoke credential on push-api", zap.String("username", username), zap.Error(err))
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusNotFound {
+		s.logger.Error("push-api refused to revoke credential", zap.String("username", username), zap.Int("status", res.StatusCode))
+		return errors.New(fmt.Sprintf("push-api answered %d when revoking credential", res.StatusCode))
+	}
+	return nil
+}
+
+func NewPushApiService(config *viper.Viper, logger *zap.Logger) PushApiService {
+	return &pushApiService{
+		logger:          logger.Named("pushApiService"),
+		httpClient:      &http.Client{Timeout: config.GetDuration("push_api.timeout")},
+		credentialsPath: config.GetString("push_api.credentials_path"),
+	}
+}
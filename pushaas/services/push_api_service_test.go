@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("PushApiService", func() {
+	config := viper.New()
+	config.Set("push_api.timeout", "1s")
+	config.Set("push_api.credentials_path", "/admin/credentials")
+
+	type request struct {
+		method   string
+		path     string
+		username string
+		password string
+		body     map[string]string
+	}
+
+	// answers every request with the status, recording what it got
+	newPushApi := func(status int, requests *[]request) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, _ := r.BasicAuth()
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			*requests = append(*requests, request{method: r.Method, path: r.URL.Path, username: username, password: password, body: body})
+			w.WriteHeader(status)
+		}))
+	}
+
+	instanceVarsFor := func(server *httptest.Server) map[string]string {
+		return map[string]string{
+			"PUSHAAS_ENDPOINT": server.URL,
+			"PUSHAAS_USERNAME": "app",
+			"PUSHAAS_PASSWORD": "instance-password",
+		}
+	}
+
+	Describe("AddCredential", func() {
+		It("posts the credential authenticated as the instance", func() {
+			// arrange
+			var requests []request
+			server := newPushApi(http.StatusCreated, &requests)
+			defer server.Close()
+			pushApiService := services.NewPushApiService(config, logger)
+
+			// act
+			err := pushApiService.AddCredential(instanceVarsFor(server), "app-app-1", "app-password")
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(Equal([]request{
+				{
+					method:   "POST",
+					path:     "/admin/credentials",
+					username: "app",
+					password: "instance-password",
+					body:     map[string]string{"username": "app-app-1", "password": "app-password"},
+				},
+			}))
+		})
+
+		It("fails when push-api refuses the credential", func() {
+			// arrange
+			var requests []request
+			server := newPushApi(http.StatusUnauthorized, &requests)
+			defer server.Close()
+			pushApiService := services.NewPushApiService(config, logger)
+
+			// act
+			err := pushApiService.AddCredential(instanceVarsFor(server), "app-app-1", "app-password")
+
+			// assert
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("fails when the instance has no endpoint", func() {
+			// arrange
+			pushApiService := services.NewPushApiService(config, logger)
+
+			// act
+			err := pushApiService.AddCredential(map[string]string{}, "app-app-1", "app-password")
+
+			// assert
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("RevokeCredential", func() {
+		It("deletes the credential authenticated as the instance", func() {
+			// arrange
+			var requests []request
+			server := newPushApi(http.StatusNoContent, &requests)
+			defer server.Close()
+			pushApiService := services.NewPushApiService(config, logger)
+
+			// act
+			err := pushApiService.RevokeCredential(instanceVarsFor(server), "app-app-1")
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].method).To(Equal("DELETE"))
+			Expect(requests[0].path).To(Equal("/admin/credentials/app-app-1"))
+			Expect(requests[0].username).To(Equal("app"))
+		})
+
+		It("succeeds when push-api does not know the credential", func() {
+			// arrange
+			var requests []request
+			server := newPushApi(http.StatusNotFound, &requests)
+			defer server.Close()
+			pushApiService := services.NewPushApiService(config, logger)
+
+			// act
+			err := pushApiService.RevokeCredential(instanceVarsFor(server), "app-app-1")
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails when push-api fails to revoke the credential", func() {
+			// arrange
+			var requests []request
+			server := newPushApi(http.StatusInternalServerError, &requests)
+			defer server.Close()
+			pushApiService := services.NewPushApiService(config, logger)
+
+			// act
+			err := pushApiService.RevokeCredential(instanceVarsFor(server), "app-app-1")
+
+			// assert
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -3,10 +3,15 @@ package services_test
 import (
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+
+	"github.com/pushaas/pushaas/pushaas/repositories"
 )
 
 var logger *zap.Logger
@@ -17,3 +22,31 @@ func TestServices(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Services Suite")
 }
+
+/*
+	a repository on an in-process redis, for the specs that depend on the atomicity of the real storage
+*/
+func newRedisRepository() (repositories.Repository, func()) {
+	server, err := miniredis.Run()
+	Expect(err).NotTo(HaveOccurred())
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	config := viper.New()
+	config.Set("redis.db.instance.index", "instance-index")
+	config.Set("redis.db.instance.prefix", "instance")
+	config.Set("redis.db.instance.vars_prefix", "instance-vars")
+	config.Set("redis.db.instance.history_prefix", "instance-history")
+	config.Set("redis.db.bind_app.prefix", "bind-app")
+	config.Set("redis.db.bind_app.index_prefix", "bind-app-index")
+	config.Set("redis.db.bind_app.app_index_prefix", "app-bind-index")
+	config.Set("redis.db.bind_unit.prefix", "bind-unit")
+	config.Set("redis.db.outbox.key", "outbox")
+	config.Set("redis.db.outbox.index", "outbox-index")
+
+	repository, err := repositories.NewRedisRepository(config, logger, redisClient)
+	Expect(err).NotTo(HaveOccurred())
+	return repository, func() {
+		_ = redisClient.Close()
+		server.Close()
+	}
+}
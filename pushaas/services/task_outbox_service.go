@@ -0,0 +1,77 @@
+package services
+
+import (
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/repositories"
+)
+
+type (
+	TaskRelayResult int
+
+	/*
+		Sends to the workers the tasks written along with the changes of the instances. The tasks are claimed for a
+		while before being sent, and deleted once sent, so the tasks of a relay that goes away meanwhile are sent by
+		another one when the claim expires, as are the ones that failed to be sent. Relay tells how many were sent.
+	*/
+	TaskOutboxService interface {
+		Relay() (int, TaskRelayResult)
+	}
+
+	taskOutboxService struct {
+		logger           *zap.Logger
+		claimTimeout     time.Duration
+		batchSize        int
+		retryPolicies    TaskRetryPolicies
+		outboxRepository repositories.TaskOutboxRepository
+		machineryServer  *machinery.Server
+	}
+)
+
+const (
+	TaskRelaySuccess TaskRelayResult = iota
+	TaskRelayFailure
+)
+
+func (s *taskOutboxService) Relay() (int, TaskRelayResult) {
+	tasks, err := s.outboxRepository.ClaimPendingTasks(time.Now().Add(s.claimTimeout), s.batchSize)
+	if err != nil {
+		s.logger.Error("failed to claim pending tasks", zap.Error(err))
+		return 0, TaskRelayFailure
+	}
+
+	sent := 0
+	result := TaskRelaySuccess
+	for _, task := range tasks {
+		signature := BuildTaskSignature(task.TaskName, task.Payload, task.OperationId, s.retryPolicies.For(task.TaskName))
+		_, err = s.machineryServer.SendTask(signature)
+		if err != nil {
+			s.logger.Error("failed to relay pending task, it is sent again when its claim expires", zap.Any("task", task), zap.Error(err))
+			result = TaskRelayFailure
+			continue
+		}
+		sent++
+
+		// a task left behind is sent again, which the workers are already ready for, as with the retries
+		err = s.outboxRepository.DeletePendingTask(task.Id)
+		if err != nil && err != repositories.ErrNotFound {
+			s.logger.Error("failed to delete relayed pending task, it is sent again when its claim expires", zap.Any("task", task), zap.Error(err))
+		}
+	}
+	return sent, result
+}
+
+func NewTaskOutboxService(config *viper.Viper, logger *zap.Logger, outboxRepository repositories.TaskOutboxRepository, machineryServer *machinery.Server) TaskOutboxService {
+	return &taskOutboxService{
+		logger:           logger.Named("taskOutboxService"),
+		claimTimeout:     config.GetDuration("workers.outbox.claim_timeout"),
+		batchSize:        config.GetInt("workers.outbox.batch_size"),
+		retryPolicies:    NewTaskRetryPolicies(config),
+		outboxRepository: outboxRepository,
+		machineryServer:  machineryServer,
+	}
+}
@@ -0,0 +1,114 @@
+package services_test
+
+import (
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	machineryConfig "github.com/RichardKnop/machinery/v1/config"
+	"github.com/alicebob/miniredis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/repositories"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("TaskOutboxService", func() {
+	var (
+		server     *miniredis.Miniredis
+		repository repositories.Repository
+		service    services.TaskOutboxService
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+
+		url := "redis://" + server.Addr()
+		machineryServer, err := machinery.NewServer(&machineryConfig.Config{
+			Broker:        url,
+			DefaultQueue:  "machinery_tasks",
+			ResultBackend: url,
+			NoUnixSignals: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		config := viper.New()
+		config.Set("redis.pubsub.tasks.provision", "provision")
+		config.Set("workers.retry.provision.max_retries", 3)
+		config.Set("workers.outbox.claim_timeout", "50ms")
+		config.Set("workers.outbox.batch_size", 10)
+		repository = repositories.NewMemoryRepository()
+		service = services.NewTaskOutboxService(config, logger, repository, machineryServer)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	createWithTask := func(name string) {
+		err := repository.CreateWithTask(&models.Instance{Name: name}, &models.PendingTask{
+			Id:          "task-" + name,
+			TaskName:    "provision",
+			Payload:     "payload-" + name,
+			OperationId: "operation-" + name,
+			CreatedAt:   time.Now().UTC(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	_ = Describe("Relay", func() {
+		_ = It("sends the pending tasks with their operation and retries and takes them off the outbox", func() {
+			// arrange
+			createWithTask("instance-1")
+			createWithTask("instance-2")
+
+			// act
+			sent, result := service.Relay()
+
+			// assert
+			Expect(result).To(Equal(services.TaskRelaySuccess))
+			Expect(sent).To(Equal(2))
+			queued, err := server.List("machinery_tasks")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queued).To(HaveLen(2))
+			Expect(queued[0]).To(ContainSubstring(`"Name":"provision"`))
+			Expect(queued[0]).To(ContainSubstring(`"RetryCount":3`))
+			Expect(queued[0]).To(ContainSubstring(`"operation":"operation-instance-1"`))
+			pending, _ := repository.ClaimPendingTasks(time.Now().Add(time.Hour), 10)
+			Expect(pending).To(BeEmpty())
+		})
+
+		_ = It("does nothing when there are no pending tasks", func() {
+			// act
+			sent, result := service.Relay()
+
+			// assert
+			Expect(result).To(Equal(services.TaskRelaySuccess))
+			Expect(sent).To(Equal(0))
+			Expect(server.Exists("machinery_tasks")).To(BeFalse())
+		})
+
+		_ = It("keeps the tasks that fail to be sent, to be sent again when their claim expires", func() {
+			// arrange
+			createWithTask("instance-1")
+			server.Close()
+
+			// act
+			sent, result := service.Relay()
+
+			// assert
+			Expect(result).To(Equal(services.TaskRelayFailure))
+			Expect(sent).To(Equal(0))
+			pending, _ := repository.ClaimPendingTasks(time.Now().Add(time.Hour), 10)
+			Expect(pending).To(BeEmpty())
+			time.Sleep(100 * time.Millisecond)
+			pending, _ = repository.ClaimPendingTasks(time.Now().Add(time.Hour), 10)
+			Expect(pending).To(HaveLen(1))
+			Expect(pending[0].Id).To(Equal("task-instance-1"))
+		})
+	})
+})
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"time"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/spf13/viper"
+)
+
+type (
+	/*
+		How many times a task is tried again after failing, and how long to wait before each time. The wait starts at
+		InitialBackoff and doubles on every retry, up to MaxBackoff.
+	*/
+	TaskRetryPolicy struct {
+		MaxRetries     int
+		InitialBackoff time.Duration
+		MaxBackoff     time.Duration
+	}
+
+	// the retry policy of each task, by the name of the task
+	TaskRetryPolicies map[string]*TaskRetryPolicy
+)
+
+// the header of the tasks that tells the operation they are part of
+const operationTaskHeader = "operation"
+
+// the kinds of tasks, as they are named on the config
+var taskConfigKeys = []string{
+	"provision",
+	"deprovision",
+	"update",
+	"rotate_credentials",
+	"recreate",
+	"update_instance",
+	"delete_instance",
+}
+
+/*
+	the wait before the given retry, counting from zero
+*/
+func (p *TaskRetryPolicy) Backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 0; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return backoff
+}
+
+// the tasks that have no policy are not retried
+func (p TaskRetryPolicies) For(taskName string) *TaskRetryPolicy {
+	policy, ok := p[taskName]
+	if !ok {
+		return &TaskRetryPolicy{}
+	}
+	return policy
+}
+
+/*
+	the signature carries the retries left and the operation the task is part of, if any, so that they go along
+	with the task through the broker
+*/
+func BuildTaskSignature(taskName string, messageJson string, operationId string, policy *TaskRetryPolicy) *tasks.Signature {
+	signature := &tasks.Signature{
+		Name: taskName,
+		Args: []tasks.Arg{
+			{
+				Type:  "string",
+				Value: messageJson,
+			},
+		},
+		RetryCount:   policy.MaxRetries,
+		RetryTimeout: int(policy.InitialBackoff / time.Second),
+	}
+	if operationId != "" {
+		signature.Headers = tasks.Headers{operationTaskHeader: operationId}
+	}
+	return signature
+}
+
+func OperationIdOf(signature *tasks.Signature) string {
+	if signature == nil {
+		return ""
+	}
+	operationId, _ := signature.Headers[operationTaskHeader].(string)
+	return operationId
+}
+
+func NewTaskRetryPolicies(config *viper.Viper) TaskRetryPolicies {
+	policies := TaskRetryPolicies{}
+	for _, key := range taskConfigKeys {
+		policies[config.GetString("redis.pubsub.tasks."+key)] = &TaskRetryPolicy{
+			MaxRetries:     config.GetInt("workers.retry." + key + ".max_retries"),
+			InitialBackoff: config.GetDuration("workers.retry." + key + ".initial_backoff"),
+			MaxBackoff:     config.GetDuration("workers.retry." + key + ".max_backoff"),
+		}
+	}
+	return policies
+}
@@ -0,0 +1,62 @@
+package services_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("TaskRetryPolicy", func() {
+	_ = Describe("Backoff", func() {
+		_ = It("doubles the backoff on every retry up to the maximum", func() {
+			// arrange
+			policy := &services.TaskRetryPolicy{MaxRetries: 5, InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute}
+
+			// act
+			backoffs := []time.Duration{policy.Backoff(0), policy.Backoff(1), policy.Backoff(2), policy.Backoff(3), policy.Backoff(10)}
+
+			// assert
+			Expect(backoffs).To(Equal([]time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}))
+		})
+	})
+
+	_ = Describe("NewTaskRetryPolicies", func() {
+		_ = It("reads the policy of each task by its name", func() {
+			// arrange
+			config := viper.New()
+			config.Set("redis.pubsub.tasks.provision", "provision-task")
+			config.Set("workers.retry.provision.max_retries", 3)
+			config.Set("workers.retry.provision.initial_backoff", "30s")
+			config.Set("workers.retry.provision.max_backoff", "5m")
+
+			// act
+			policies := services.NewTaskRetryPolicies(config)
+
+			// assert
+			Expect(policies.For("provision-task")).To(Equal(&services.TaskRetryPolicy{MaxRetries: 3, InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}))
+			Expect(policies.For("unknown-task").MaxRetries).To(Equal(0))
+		})
+	})
+
+	_ = Describe("BuildTaskSignature", func() {
+		_ = It("carries the payload, the operation and the retries of the policy", func() {
+			// arrange
+			policy := &services.TaskRetryPolicy{MaxRetries: 3, InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+
+			// act
+			signature := services.BuildTaskSignature("provision-task", `{"name":"instance-1"}`, "operation-1", policy)
+
+			// assert
+			Expect(signature.Name).To(Equal("provision-task"))
+			Expect(signature.Args).To(HaveLen(1))
+			Expect(signature.Args[0].Value).To(Equal(`{"name":"instance-1"}`))
+			Expect(signature.RetryCount).To(Equal(3))
+			Expect(signature.RetryTimeout).To(Equal(30))
+			Expect(services.OperationIdOf(signature)).To(Equal("operation-1"))
+		})
+	})
+})
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+type (
+	/*
+		Talks to the Tsuru API, so that the apps bound to an instance get its env vars again when they change.
+	*/
+	TsuruService interface {
+		SetAppEnvVars(appName string, envVars map[string]string) error
+	}
+
+	tsuruService struct {
+		logger     *zap.Logger
+		httpClient *http.Client
+		apiUrl     string
+		token      string
+	}
+)
+
+var ErrTsuruNotConfigured = errors.New("tsuru api is not configured")
+
+/*
+	the vars are private, so they are not shown to the users of the app, and the app is restarted to get them
+*/
+func (s *tsuruService) SetAppEnvVars(appName string, envVars map[string]string) error {
+	if s.apiUrl == "" || s.token == "" {
+		return ErrTsuruNotConfigured
+	}
+
+	form := url.Values{}
+	i := 0
+	for name, value := range envVars {
+		form.Set(fmt.Sprintf("Envs.%d.Name", i), name)
+		form.Set(fmt.Sprintf("Envs.%d.Value", i), value)
+		i++
+	}
+	form.Set("NoRestart", "false")
+	form.Set("Private", "true")
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/1.0/apps/%s/env", s.apiUrl, url.PathEscape(appName)), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token)
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		s.logger.Error("failed to set env vars of app on tsuru", zap.String("appName", appName), zap.Error(err))
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		s.logger.Error("tsuru refused to set env vars of app", zap.String("appName", appName), zap.Int("status", res.StatusCode))
+		return errors.New(fmt.Sprintf("tsuru answered %d when setting env vars of app %s", res.StatusCode, appName))
+	}
+	return nil
+}
+
+func NewTsuruService(config *viper.Viper, logger *zap.Logger) TsuruService {
+	return &tsuruService{
+		logger:     logger.Named("tsuruService"),
+		httpClient: &http.Client{Timeout: config.GetDuration("tsuru.timeout")},
+		apiUrl:     strings.TrimSuffix(config.GetString("tsuru.api_url"), "/"),
+		token:      config.GetString("tsuru.token"),
+	}
+}
@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+var _ = Describe("TsuruService", func() {
+	type request struct {
+		method        string
+		path          string
+		authorization string
+		form          url.Values
+	}
+
+	// answers every request with the status, recording what it got
+	newTsuruApi := func(status int, requests *[]request) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = r.ParseForm()
+			*requests = append(*requests, request{method: r.Method, path: r.URL.Path, authorization: r.Header.Get("Authorization"), form: r.PostForm})
+			w.WriteHeader(status)
+		}))
+	}
+
+	newConfig := func(apiUrl string) *viper.Viper {
+		config := viper.New()
+		config.Set("tsuru.api_url", apiUrl)
+		config.Set("tsuru.token", "some-token")
+		config.Set("tsuru.timeout", "1s")
+		return config
+	}
+
+	Describe("SetAppEnvVars", func() {
+		It("sets the env vars as private and restarts the app", func() {
+			// arrange
+			var requests []request
+			server := newTsuruApi(http.StatusOK, &requests)
+			defer server.Close()
+			tsuruService := services.NewTsuruService(newConfig(server.URL), logger)
+
+			// act
+			err := tsuruService.SetAppEnvVars("app-1", map[string]string{"PUSHAAS_PASSWORD": "new-password"})
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].method).To(Equal(http.MethodPost))
+			Expect(requests[0].path).To(Equal("/1.0/apps/app-1/env"))
+			Expect(requests[0].authorization).To(Equal("bearer some-token"))
+			Expect(requests[0].form.Get("Envs.0.Name")).To(Equal("PUSHAAS_PASSWORD"))
+			Expect(requests[0].form.Get("Envs.0.Value")).To(Equal("new-password"))
+			Expect(requests[0].form.Get("Private")).To(Equal("true"))
+			Expect(requests[0].form.Get("NoRestart")).To(Equal("false"))
+		})
+
+		It("fails when tsuru refuses the env vars", func() {
+			// arrange
+			var requests []request
+			server := newTsuruApi(http.StatusForbidden, &requests)
+			defer server.Close()
+			tsuruService := services.NewTsuruService(newConfig(server.URL), logger)
+
+			// act
+			err := tsuruService.SetAppEnvVars("app-1", map[string]string{"PUSHAAS_PASSWORD": "new-password"})
+
+			// assert
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("indicates when tsuru is not configured", func() {
+			// arrange
+			tsuruService := services.NewTsuruService(newConfig(""), logger)
+
+			// act
+			err := tsuruService.SetAppEnvVars("app-1", map[string]string{"PUSHAAS_PASSWORD": "new-password"})
+
+			// assert
+			Expect(err).To(Equal(services.ErrTsuruNotConfigured))
+		})
+	})
+})
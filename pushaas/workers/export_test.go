@@ -0,0 +1,16 @@
+package workers
+
+/*
+	the specs are on workers_test, these give them the internals the exported workers are built on
+*/
+type TaskHandler = taskHandler
+
+var WithRetries = withRetries
+
+func Reconcile(worker ReconcileWorker) {
+	worker.(*reconcileWorker).reconcile()
+}
+
+func Relay(worker OutboxWorker) {
+	worker.(*outboxWorker).relay()
+}
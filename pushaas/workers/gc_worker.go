@@ -0,0 +1,73 @@
+package workers
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	GcWorker interface {
+		DispatchWorker()
+	}
+
+	/*
+		Periodically collects the orphan resources on the provider. On dry run (the default) they are only reported.
+	*/
+	gcWorker struct {
+		logger    *zap.Logger
+		enabled   bool
+		interval  time.Duration
+		dryRun    bool
+		gcService services.GcService
+	}
+)
+
+func (w *gcWorker) startWorker() {
+	w.logger.Info("starting worker", zap.Duration("interval", w.interval), zap.Bool("dryRun", w.dryRun))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.collect()
+	}
+}
+
+func (w *gcWorker) DispatchWorker() {
+	if w.enabled {
+		go w.startWorker()
+		return
+	}
+	w.logger.Info("worker disabled, not starting")
+}
+
+func (w *gcWorker) collect() {
+	report, result := w.gcService.Collect(w.dryRun)
+	if result == services.GcNotSupported {
+		w.logger.Info("garbage collection not supported by the provider")
+		return
+	}
+	if result == services.GcFailure {
+		w.logger.Error("failed to collect orphan resources")
+		return
+	}
+
+	w.logger.Info("collected orphan resources", zap.Bool("dryRun", report.DryRun), zap.Int("orphans", len(report.Orphans)))
+}
+
+func NewGcWorker(config *viper.Viper, logger *zap.Logger, gcService services.GcService) GcWorker {
+	enabled := config.GetBool("workers.gc.enabled")
+	workersEnabled := config.GetBool("workers.enabled")
+
+	return &gcWorker{
+		logger:    logger.Named("gcWorker"),
+		enabled:   enabled && workersEnabled,
+		interval:  config.GetDuration("workers.gc.interval"),
+		dryRun:    config.GetBool("workers.gc.dry_run"),
+		gcService: gcService,
+	}
+}
@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"encoding/json"
+
+	"github.com/RichardKnop/machinery/v1"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+/*
+	the result of every provision goes through the update instance task, which is handled by the instanceWorker,
+	along with the operation it is part of, which the instanceWorker finishes
+*/
+func sendUpdateInstanceTask(logger *zap.Logger, machineryServer *machinery.Server, updateInstanceTaskName string, retryPolicy *services.TaskRetryPolicy, operationId string, provisionResult *provisioners.PushServiceProvisionResult) error {
+	bytes, err := json.Marshal(provisionResult)
+	if err != nil {
+		logger.Error("error marshaling provisionResult", zap.Any("provisionResult", provisionResult), zap.Error(err))
+		return err
+	}
+
+	messageJson := string(bytes)
+	signature := services.BuildTaskSignature(updateInstanceTaskName, messageJson, operationId, retryPolicy)
+	_, err = machineryServer.SendTask(signature)
+	if err != nil {
+		logger.Error("error dispatching update for instance", zap.Any("provisionResult", provisionResult), zap.Error(err))
+		return err
+	}
+
+	logger.Debug("instance update dispatched", zap.Any("provisionResult", provisionResult))
+	return nil
+}
+
+/*
+	the result of every deprovision goes through the delete instance task, which is handled by the instanceWorker
+*/
+func sendDeleteInstanceTask(logger *zap.Logger, machineryServer *machinery.Server, deleteInstanceTaskName string, retryPolicy *services.TaskRetryPolicy, operationId string, deprovisionResult *provisioners.PushServiceDeprovisionResult) error {
+	bytes, err := json.Marshal(deprovisionResult)
+	if err != nil {
+		logger.Error("error marshaling deprovisionResult", zap.Any("deprovisionResult", deprovisionResult), zap.Error(err))
+		return err
+	}
+
+	messageJson := string(bytes)
+	signature := services.BuildTaskSignature(deleteInstanceTaskName, messageJson, operationId, retryPolicy)
+	_, err = machineryServer.SendTask(signature)
+	if err != nil {
+		logger.Error("error dispatching delete for instance", zap.Any("deprovisionResult", deprovisionResult), zap.Error(err))
+		return err
+	}
+
+	logger.Debug("instance delete dispatched", zap.Any("deprovisionResult", deprovisionResult))
+	return nil
+}
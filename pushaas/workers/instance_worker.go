@@ -1,8 +1,10 @@
 package workers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -14,17 +16,52 @@ import (
 
 type (
 	InstanceWorker interface {
-		HandleUpdateInstance(payload string) error
+		HandleUpdateInstance(ctx context.Context, payload string) error
+		HandleDeleteInstance(ctx context.Context, payload string) error
 	}
 
 	instanceWorker struct {
 		logger                 *zap.Logger
 		updateInstanceTaskName string
 		instanceService        services.InstanceService
+		operationService       services.OperationService
+		eventService           services.EventService
 	}
 )
 
-func (w *instanceWorker) HandleUpdateInstance(payload string) error {
+func (w *instanceWorker) publishStatusChanged(instanceName string, status models.InstanceStatus, reason string) {
+	w.eventService.Publish(&models.InstanceEvent{
+		Instance: instanceName,
+		Type:     models.InstanceEventStatusChanged,
+		Status:   status,
+		Reason:   reason,
+	})
+}
+
+/*
+	the instance was deleted, or changed otherwise, while the operation ran, so the result no longer applies to it
+	and is dropped, instead of overwriting what was written meanwhile. Whatever the operation left behind on the
+	provider is found and removed by the garbage collector.
+*/
+func (w *instanceWorker) dropResult(ctx context.Context, provisionResult *provisioners.PushServiceProvisionResult, updateResult services.InstanceUpdateResult) error {
+	reason := "the instance was changed while the operation ran"
+	if updateResult == services.InstanceUpdateNotFound {
+		reason = "the instance was removed while the operation ran"
+	}
+	w.logger.Warn("dropping result of operation on instance", zap.Any("provisionResult", provisionResult), zap.String("reason", reason))
+	w.operationService.Finish(operationIdFromContext(ctx), true, reason)
+	return nil
+}
+
+func isDropped(updateResult services.InstanceUpdateResult) bool {
+	return updateResult == services.InstanceUpdateNotFound || updateResult == services.InstanceUpdateStatusChanged
+}
+
+/*
+	the instance is the last thing touched by an operation, so the operation is finished here. Every operation
+	leaves the instance pending while it runs, so its status is only written while the instance is still pending.
+*/
+func (w *instanceWorker) HandleUpdateInstance(ctx context.Context, payload string) error {
 	var provisionResult provisioners.PushServiceProvisionResult
 	err := json.Unmarshal([]byte(payload), &provisionResult)
 	if err != nil {
@@ -34,36 +71,119 @@ func (w *instanceWorker) HandleUpdateInstance(payload string) error {
 
 	instanceName := provisionResult.Instance.Name
 
+	// what was created is recorded even on failure, as it may have been left behind by a failed rollback
+	if len(provisionResult.Resources) > 0 {
+		resourcesResult := w.instanceService.UpdateResources(instanceName, provisionResult.Resources)
+		if resourcesResult == services.InstanceUpdateNotFound {
+			return w.dropResult(ctx, &provisionResult, resourcesResult)
+		}
+		if resourcesResult == services.InstanceUpdateFailure {
+			w.logger.Error("failed to update instance resources", zap.Any("provisionResult", provisionResult))
+			return errors.New("failed to update instance resources")
+		}
+	}
+
 	// if failed to provision
 	if provisionResult.Status == provisioners.PushServiceProvisionStatusFailure {
-		updateResult := w.instanceService.UpdateStatus(instanceName, models.InstanceStatusFailed)
+		reason := provisionResult.FailureReason
+		if reason == "" {
+			reason = "provisioner failed without telling why"
+		}
+		if provisionResult.Previous != nil {
+			return w.revertChange(ctx, &provisionResult, reason)
+		}
+
+		// what was already created may have been rolled back by the provisioner, which is written along with the status
+		rollback := provisionResult.Instance.Rollback
+		updateResult := w.instanceService.FinishPending(instanceName, models.InstanceStatusFailed, reason, &rollback, nil)
+		if isDropped(updateResult) {
+			return w.dropResult(ctx, &provisionResult, updateResult)
+		}
 		if updateResult == services.InstanceUpdateFailure {
 			w.logger.Error("failed to update instance status after failure", zap.Any("provisionResult", provisionResult))
 			return errors.New("failed to update instance status after failure")
 		}
+		w.publishStatusChanged(instanceName, models.InstanceStatusFailed, reason)
+		w.operationService.Finish(operationIdFromContext(ctx), true, reason)
 		return nil
 	}
 
-	// if succeeded to provision
-	updateResult := w.instanceService.UpdateStatus(instanceName, models.InstanceStatusRunning)
+	// if succeeded to provision, the vars are written along with the status, an update only carrying the ones that changed, if any
+	rollback := models.InstanceRollbackNone
+	updateResult := w.instanceService.FinishPending(instanceName, models.InstanceStatusRunning, "", &rollback, provisionResult.EnvVars)
+	if isDropped(updateResult) {
+		return w.dropResult(ctx, &provisionResult, updateResult)
+	}
 	if updateResult == services.InstanceUpdateFailure {
 		w.logger.Error("failed to update instance status after success", zap.Any("provisionResult", provisionResult))
 		return errors.New("failed to update instance status after success")
 	}
+	w.publishStatusChanged(instanceName, models.InstanceStatusRunning, "")
 
-	_, err = w.instanceService.SetInstanceVars(instanceName, provisionResult.EnvVars)
+	w.operationService.Finish(operationIdFromContext(ctx), false, "")
+	return nil
+}
+
+/*
+	the record is removed even when the deprovision fails, as the instance is already gone for its users.
+	Whatever was left behind on the provider is found and removed by the garbage collector.
+*/
+func (w *instanceWorker) HandleDeleteInstance(ctx context.Context, payload string) error {
+	var deprovisionResult provisioners.PushServiceDeprovisionResult
+	err := json.Unmarshal([]byte(payload), &deprovisionResult)
 	if err != nil {
-		w.logger.Error("failed to set instance variables after success", zap.Any("provisionResult", provisionResult), zap.Error(err))
-		return errors.New("failed to set instance variables after success")
+		w.logger.Error("failed to unmarshal instance to delete", zap.String("payload", payload), zap.Error(err))
+		return err
+	}
+
+	instanceName := deprovisionResult.Instance.Name
+
+	if deprovisionResult.Status == provisioners.PushServiceDeprovisionStatusFailure {
+		w.logger.Error("failed to deprovision instance, leftover resources will be garbage collected", zap.Any("deprovisionResult", deprovisionResult))
+	}
+
+	removeResult := w.instanceService.Remove(instanceName)
+	if removeResult == services.InstanceDeletionFailure {
+		w.logger.Error("failed to remove instance after deprovision", zap.Any("deprovisionResult", deprovisionResult))
+		return errors.New("failed to remove instance after deprovision")
+	}
+
+	operationId := operationIdFromContext(ctx)
+	if deprovisionResult.Status == provisioners.PushServiceDeprovisionStatusFailure {
+		w.operationService.Finish(operationId, true, "failed to deprovision, leftover resources will be garbage collected")
+	} else {
+		w.operationService.Finish(operationId, false, "")
 	}
+	return nil
+}
 
+/*
+	a change of a running instance that fails, like a plan change or a rotation of credentials, puts back what the
+	instance had before, so that it can be changed again, instead of leaving it failed. The vars the change did put
+	in place before failing, like the password push-api was rolled to, are kept
+*/
+func (w *instanceWorker) revertChange(ctx context.Context, provisionResult *provisioners.PushServiceProvisionResult, reason string) error {
+	instanceName := provisionResult.Instance.Name
+	revertReason := fmt.Sprintf("change failed and was reverted: %s", reason)
+	revertResult := w.instanceService.RevertChange(instanceName, provisionResult.Previous, revertReason, provisionResult.EnvVars)
+	if isDropped(revertResult) {
+		return w.dropResult(ctx, provisionResult, revertResult)
+	}
+	if revertResult == services.InstanceUpdateFailure {
+		w.logger.Error("failed to revert instance after failed change", zap.Any("provisionResult", provisionResult))
+		return errors.New("failed to revert instance after failed change")
+	}
+	w.publishStatusChanged(instanceName, provisionResult.Previous.Status, revertReason)
+	w.operationService.Finish(operationIdFromContext(ctx), true, reason)
 	return nil
 }
 
-func NewInstanceWorker(config *viper.Viper, logger *zap.Logger, instanceService services.InstanceService) InstanceWorker {
+func NewInstanceWorker(config *viper.Viper, logger *zap.Logger, instanceService services.InstanceService, operationService services.OperationService, eventService services.EventService) InstanceWorker {
 	return &instanceWorker{
 		logger:                 logger.Named("instanceWorker"),
 		updateInstanceTaskName: config.GetString("redis.pubsub.tasks.update_instance"),
 		instanceService:        instanceService,
+		operationService:       operationService,
+		eventService:           eventService,
 	}
 }
@@ -14,40 +14,75 @@ type (
 	}
 
 	machineryWorker struct {
-		logger                 *zap.Logger
-		machineryServer        *machinery.Server
-		provisionTaskName      string
-		deprovisionTaskName    string
-		updateInstanceTaskName string
-		instanceService        services.InstanceService
-		enabled                bool
-		provisionWorker        ProvisionWorker
-		instanceWorker         InstanceWorker
+		logger                    *zap.Logger
+		machineryServer           *machinery.Server
+		provisionTaskName         string
+		deprovisionTaskName       string
+		updateTaskName            string
+		rotateCredentialsTaskName string
+		recreateTaskName          string
+		updateInstanceTaskName    string
+		deleteInstanceTaskName    string
+		instanceService           services.InstanceService
+		deadLetterService         services.DeadLetterService
+		operationService          services.OperationService
+		retryPolicies             services.TaskRetryPolicies
+		enabled                   bool
+		provisionWorker           ProvisionWorker
+		instanceWorker            InstanceWorker
 	}
 )
 
+func (w *machineryWorker) registerTask(taskName string, handler taskHandler) error {
+	return w.machineryServer.RegisterTask(taskName, withRetries(taskName, w.retryPolicies.For(taskName), w.deadLetterService, w.operationService, handler))
+}
+
 func (w *machineryWorker) startWorker() {
 	w.logger.Info("starting worker")
 	var err error
 
-	err = w.machineryServer.RegisterTask(w.updateInstanceTaskName, w.instanceWorker.HandleUpdateInstance)
+	err = w.registerTask(w.updateInstanceTaskName, w.instanceWorker.HandleUpdateInstance)
 	if err != nil {
 		w.logger.Error("failed to register update task", zap.Error(err))
 		panic(err)
 	}
 
-	err = w.machineryServer.RegisterTask(w.provisionTaskName, w.provisionWorker.HandleProvisionTask)
+	err = w.registerTask(w.deleteInstanceTaskName, w.instanceWorker.HandleDeleteInstance)
+	if err != nil {
+		w.logger.Error("failed to register delete task", zap.Error(err))
+		panic(err)
+	}
+
+	err = w.registerTask(w.provisionTaskName, w.provisionWorker.HandleProvisionTask)
 	if err != nil {
 		w.logger.Error("failed to register provision task", zap.Error(err))
 		panic(err)
 	}
 
-	err = w.machineryServer.RegisterTask(w.deprovisionTaskName, w.provisionWorker.HandleDeprovisionTask)
+	err = w.registerTask(w.deprovisionTaskName, w.provisionWorker.HandleDeprovisionTask)
 	if err != nil {
 		w.logger.Error("failed to register deprovision task", zap.Error(err))
 		panic(err)
 	}
 
+	err = w.registerTask(w.updateTaskName, w.provisionWorker.HandleUpdateTask)
+	if err != nil {
+		w.logger.Error("failed to register provider update task", zap.Error(err))
+		panic(err)
+	}
+
+	err = w.registerTask(w.rotateCredentialsTaskName, w.provisionWorker.HandleRotateCredentialsTask)
+	if err != nil {
+		w.logger.Error("failed to register rotate credentials task", zap.Error(err))
+		panic(err)
+	}
+
+	err = w.registerTask(w.recreateTaskName, w.provisionWorker.HandleRecreateTask)
+	if err != nil {
+		w.logger.Error("failed to register recreate task", zap.Error(err))
+		panic(err)
+	}
+
 	worker := w.machineryServer.NewWorker("worker", 0)
 	err = worker.Launch()
 	if err != nil {
@@ -64,18 +99,25 @@ func (w *machineryWorker) DispatchWorker() {
 	w.logger.Info("worker disabled, not starting")
 }
 
-func NewMachineryWorker(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server, provisionWorker ProvisionWorker, instanceWorker InstanceWorker) MachineryWorker {
+func NewMachineryWorker(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server, provisionWorker ProvisionWorker, instanceWorker InstanceWorker, deadLetterService services.DeadLetterService, operationService services.OperationService) MachineryWorker {
 	enabled := config.GetBool("workers.machinery.enabled")
 	workersEnabled := config.GetBool("workers.enabled")
 
 	return &machineryWorker{
-		logger:                 logger.Named("machineryWorker"),
-		machineryServer:        machineryServer,
-		provisionTaskName:      config.GetString("redis.pubsub.tasks.provision"),
-		deprovisionTaskName:    config.GetString("redis.pubsub.tasks.deprovision"),
-		updateInstanceTaskName: config.GetString("redis.pubsub.tasks.update_instance"),
-		enabled:                enabled && workersEnabled,
-		provisionWorker:        provisionWorker,
-		instanceWorker:         instanceWorker,
+		logger:                    logger.Named("machineryWorker"),
+		machineryServer:           machineryServer,
+		provisionTaskName:         config.GetString("redis.pubsub.tasks.provision"),
+		deprovisionTaskName:       config.GetString("redis.pubsub.tasks.deprovision"),
+		updateTaskName:            config.GetString("redis.pubsub.tasks.update"),
+		rotateCredentialsTaskName: config.GetString("redis.pubsub.tasks.rotate_credentials"),
+		recreateTaskName:          config.GetString("redis.pubsub.tasks.recreate"),
+		updateInstanceTaskName:    config.GetString("redis.pubsub.tasks.update_instance"),
+		deleteInstanceTaskName:    config.GetString("redis.pubsub.tasks.delete_instance"),
+		deadLetterService:         deadLetterService,
+		operationService:          operationService,
+		retryPolicies:             services.NewTaskRetryPolicies(config),
+		enabled:                   enabled && workersEnabled,
+		provisionWorker:           provisionWorker,
+		instanceWorker:            instanceWorker,
 	}
 }
@@ -0,0 +1,69 @@
+package workers
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	OutboxWorker interface {
+		DispatchWorker()
+	}
+
+	/*
+		Periodically relays to the workers the tasks written along with the changes of the instances. It runs on
+		every replica, as the claims keep the relays from sending the same tasks.
+	*/
+	outboxWorker struct {
+		logger            *zap.Logger
+		enabled           bool
+		interval          time.Duration
+		taskOutboxService services.TaskOutboxService
+	}
+)
+
+func (w *outboxWorker) startWorker() {
+	w.logger.Info("starting worker", zap.Duration("interval", w.interval))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.relay()
+	}
+}
+
+func (w *outboxWorker) DispatchWorker() {
+	if w.enabled {
+		go w.startWorker()
+		return
+	}
+	w.logger.Info("worker disabled, not starting")
+}
+
+func (w *outboxWorker) relay() {
+	sent, result := w.taskOutboxService.Relay()
+	if result == services.TaskRelayFailure {
+		w.logger.Error("failed to relay some of the pending tasks", zap.Int("sent", sent))
+		return
+	}
+	if sent > 0 {
+		w.logger.Debug("relayed pending tasks", zap.Int("sent", sent))
+	}
+}
+
+func NewOutboxWorker(config *viper.Viper, logger *zap.Logger, taskOutboxService services.TaskOutboxService) OutboxWorker {
+	enabled := config.GetBool("workers.outbox.enabled")
+	workersEnabled := config.GetBool("workers.enabled")
+
+	return &outboxWorker{
+		logger:            logger.Named("outboxWorker"),
+		enabled:           enabled && workersEnabled,
+		interval:          config.GetDuration("workers.outbox.interval"),
+		taskOutboxService: taskOutboxService,
+	}
+}
@@ -0,0 +1,55 @@
+package workers_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/services"
+	"github.com/pushaas/pushaas/pushaas/workers"
+)
+
+var _ = Describe("OutboxWorker", func() {
+	newTaskOutboxService := func(sent int, result services.TaskRelayResult) *mocks.TaskOutboxServiceMock {
+		return &mocks.TaskOutboxServiceMock{
+			RelayFunc: func() (int, services.TaskRelayResult) {
+				return sent, result
+			},
+		}
+	}
+
+	It("relays the pending tasks on each round", func() {
+		// arrange
+		config := viper.New()
+		taskOutboxService := newTaskOutboxService(2, services.TaskRelaySuccess)
+		worker := workers.NewOutboxWorker(config, logger, taskOutboxService)
+
+		// act
+		workers.Relay(worker)
+		workers.Relay(worker)
+
+		// assert
+		Expect(taskOutboxService.RelayCalls()).To(HaveLen(2))
+	})
+
+	It("does not start when the workers are disabled", func() {
+		// arrange
+		config := viper.New()
+		config.Set("workers.enabled", false)
+		config.Set("workers.outbox.enabled", true)
+		config.Set("workers.outbox.interval", "1ms")
+		taskOutboxService := newTaskOutboxService(0, services.TaskRelaySuccess)
+		worker := workers.NewOutboxWorker(config, logger, taskOutboxService)
+
+		// act
+		worker.DispatchWorker()
+
+		// assert
+		Consistently(func() int {
+			return len(taskOutboxService.RelayCalls())
+		}, 20*time.Millisecond, time.Millisecond).Should(Equal(0))
+	})
+})
@@ -1,7 +1,10 @@
 package workers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/RichardKnop/machinery/v1"
 	"github.com/RichardKnop/machinery/v1/tasks"
@@ -10,82 +13,318 @@ import (
 
 	"github.com/pushaas/pushaas/pushaas/models"
 	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/services"
 )
 
 type (
 	ProvisionWorker interface {
-		HandleProvisionTask(payload string) error
-		HandleDeprovisionTask(payload string) error
+		HandleProvisionTask(ctx context.Context, payload string) error
+		HandleDeprovisionTask(ctx context.Context, payload string) error
+		HandleUpdateTask(ctx context.Context, payload string) error
+		HandleRotateCredentialsTask(ctx context.Context, payload string) error
+		HandleRecreateTask(ctx context.Context, payload string) error
 	}
 
 	provisionWorker struct {
 		logger                 *zap.Logger
 		machineryServer        *machinery.Server
 		updateInstanceTaskName string
+		deleteInstanceTaskName string
+		updateInstancePolicy   *services.TaskRetryPolicy
+		deleteInstancePolicy   *services.TaskRetryPolicy
+		busyBackoff            time.Duration
+		lockService            services.InstanceLockService
+		operationService       services.OperationService
+		instanceService        services.InstanceService
+		credentialService      services.CredentialService
+		eventService           services.EventService
 		provisioner            provisioners.PushServiceProvisioner
 	}
 )
 
-func (w *provisionWorker) buildUpdateInstanceSignature(messageJson string) *tasks.Signature {
-	return &tasks.Signature{
-		Name: w.updateInstanceTaskName,
-		Args: []tasks.Arg{
-			{
-				Type:  "string",
-				Value: messageJson,
-			},
-		},
+// the steps the followers of an instance see, one for each task, plus the bound apps getting their new credentials
+const (
+	provisionStep            = "provision"
+	deprovisionStep          = "deprovision"
+	updateStep               = "update"
+	rotateCredentialsStep    = "rotate-credentials"
+	rotateAppCredentialsStep = "rotate-app-credentials"
+	recreateStep             = "recreate"
+)
+
+/*
+	the steps are published to the followers of the instance and recorded on the operation the task is part of
+*/
+func (w *provisionWorker) startStep(ctx context.Context, instanceName, step string) {
+	w.operationService.StartStep(operationIdFromContext(ctx), step)
+	w.eventService.Publish(&models.InstanceEvent{
+		Instance: instanceName,
+		Type:     models.InstanceEventStepStarted,
+		Step:     step,
+	})
+}
+
+func (w *provisionWorker) finishStep(ctx context.Context, instanceName, step string, failed bool, reason string) {
+	w.operationService.FinishStep(operationIdFromContext(ctx), step, failed, reason)
+	w.eventService.Publish(&models.InstanceEvent{
+		Instance: instanceName,
+		Type:     models.InstanceEventStepFinished,
+		Step:     step,
+		Failed:   failed,
+		Reason:   reason,
+	})
+}
+
+/*
+	the operations on an instance run one at a time, a task that finds another one running waits for it
+	without spending its retries
+*/
+func (w *provisionWorker) acquireLease(instanceName string) (services.InstanceLease, error) {
+	lease, result := w.lockService.Acquire(instanceName)
+	if result == services.InstanceLockBusy {
+		w.logger.Info("instance is busy, task will wait", zap.String("name", instanceName), zap.Duration("backoff", w.busyBackoff))
+		return nil, tasks.NewErrRetryTaskLater("instance is busy with another operation", w.busyBackoff)
+	}
+	if result == services.InstanceLockFailure {
+		return nil, errors.New("failed to acquire instance lease")
+	}
+	return lease, nil
+}
+
+/*
+	an instance the reconcile worker gave up on while its task was queued is no longer pending, so the task is dropped
+	instead of creating resources for it, the ones it may have left being collected by the gc
+*/
+func (w *provisionWorker) isStillPending(ctx context.Context, instanceName string) (bool, error) {
+	instance, result := w.instanceService.GetByName(instanceName)
+	if result == services.InstanceRetrievalFailure {
+		w.logger.Error("failed to retrieve instance to check it is still pending", zap.String("name", instanceName))
+		return false, errors.New("failed to retrieve instance to check it is still pending")
+	}
+	if result == services.InstanceRetrievalNotFound || instance.Status != models.InstanceStatusPending {
+		w.logger.Info("instance is no longer pending, dropping task", zap.String("name", instanceName))
+		w.operationService.Finish(operationIdFromContext(ctx), true, "instance is no longer pending")
+		return false, nil
 	}
+	return true, nil
 }
 
-func (w *provisionWorker) sendUpdateTask(provisionResult *provisioners.PushServiceProvisionResult) error {
-	bytes, err := json.Marshal(provisionResult)
+/*
+	a failed provision is tried again while the task has retries left, but only when the provisioner tells that what it
+	had created was rolled back. The ones that do not roll back would find their own leftovers on the next attempt.
+	Only the last attempt tells the instance it failed.
+*/
+func shouldRetryProvision(ctx context.Context, result *provisioners.PushServiceProvisionResult) bool {
+	if result.Status != provisioners.PushServiceProvisionStatusFailure || isLastAttempt(ctx) {
+		return false
+	}
+	return result.Instance != nil && result.Instance.Rollback == models.InstanceRollbackCompleted
+}
+
+func (w *provisionWorker) HandleProvisionTask(ctx context.Context, payload string) error {
+	var instance models.Instance
+	err := json.Unmarshal([]byte(payload), &instance)
 	if err != nil {
-		w.logger.Error("error marshaling provisionResult", zap.Any("provisionResult", provisionResult), zap.Error(err))
+		w.logger.Error("failed to unmarshal instance to provision", zap.String("payload", payload), zap.Error(err))
 		return err
 	}
 
-	messageJson := string(bytes)
-	signature := w.buildUpdateInstanceSignature(messageJson)
-	_, err = w.machineryServer.SendTask(signature)
+	lease, err := w.acquireLease(instance.Name)
 	if err != nil {
-		w.logger.Error("error dispatching update for instance", zap.Any("provisionResult", provisionResult), zap.Error(err))
 		return err
 	}
+	defer lease.Release()
+	w.operationService.Start(operationIdFromContext(ctx))
 
-	w.logger.Debug("instance update dispatched", zap.Any("provisionResult", provisionResult))
-	return nil
+	pending, err := w.isStillPending(ctx, instance.Name)
+	if !pending {
+		return err
+	}
+
+	w.startStep(ctx, instance.Name, provisionStep)
+	provisionResult := w.provisioner.Provision(&instance)
+	w.finishStep(ctx, instance.Name, provisionStep, provisionResult.Status == provisioners.PushServiceProvisionStatusFailure, provisionResult.FailureReason)
+	if shouldRetryProvision(ctx, provisionResult) {
+		return errors.New(provisionResult.FailureReason)
+	}
+	return sendUpdateInstanceTask(w.logger, w.machineryServer, w.updateInstanceTaskName, w.updateInstancePolicy, operationIdFromContext(ctx), provisionResult)
 }
 
-func (w *provisionWorker) HandleProvisionTask(payload string) error {
+func (w *provisionWorker) HandleDeprovisionTask(ctx context.Context, payload string) error {
 	var instance models.Instance
 	err := json.Unmarshal([]byte(payload), &instance)
 	if err != nil {
-		w.logger.Error("failed to unmarshal instance to provision", zap.String("payload", payload), zap.Error(err))
+		w.logger.Error("failed to unmarshal instance to deprovision", zap.String("payload", payload), zap.Error(err))
 		return err
 	}
 
-	provisionResult := w.provisioner.Provision(&instance)
-	return w.sendUpdateTask(provisionResult)
+	lease, err := w.acquireLease(instance.Name)
+	if err != nil {
+		return err
+	}
+	defer lease.Release()
+	w.operationService.Start(operationIdFromContext(ctx))
+
+	w.startStep(ctx, instance.Name, deprovisionStep)
+	deprovisionResult := w.provisioner.Deprovision(&instance)
+	w.finishStep(ctx, instance.Name, deprovisionStep, deprovisionResult.Status == provisioners.PushServiceDeprovisionStatusFailure, "")
+	if deprovisionResult.Status == provisioners.PushServiceDeprovisionStatusFailure && !isLastAttempt(ctx) {
+		return errors.New("failed to deprovision instance")
+	}
+	return sendDeleteInstanceTask(w.logger, w.machineryServer, w.deleteInstanceTaskName, w.deleteInstancePolicy, operationIdFromContext(ctx), deprovisionResult)
+}
+
+func (w *provisionWorker) HandleUpdateTask(ctx context.Context, payload string) error {
+	var change models.InstanceChange
+	err := json.Unmarshal([]byte(payload), &change)
+	if err != nil {
+		w.logger.Error("failed to unmarshal instance to update", zap.String("payload", payload), zap.Error(err))
+		return err
+	}
+	instance := change.Instance
+
+	lease, err := w.acquireLease(instance.Name)
+	if err != nil {
+		return err
+	}
+	defer lease.Release()
+	w.operationService.Start(operationIdFromContext(ctx))
+
+	w.startStep(ctx, instance.Name, updateStep)
+	updateResult := w.provisioner.Update(&instance)
+	updateResult.Previous = change.Previous
+	w.finishStep(ctx, instance.Name, updateStep, updateResult.Status == provisioners.PushServiceProvisionStatusFailure, updateResult.FailureReason)
+	if updateResult.Status == provisioners.PushServiceProvisionStatusFailure && !isLastAttempt(ctx) {
+		return errors.New(updateResult.FailureReason)
+	}
+	return sendUpdateInstanceTask(w.logger, w.machineryServer, w.updateInstanceTaskName, w.updateInstancePolicy, operationIdFromContext(ctx), updateResult)
 }
 
-func (w *provisionWorker) HandleDeprovisionTask(payload string) error {
+/*
+	the bound apps only get their new credentials once push-api already has the new password,
+	as they are issued on push-api itself
+*/
+func (w *provisionWorker) HandleRotateCredentialsTask(ctx context.Context, payload string) error {
+	var change models.InstanceChange
+	err := json.Unmarshal([]byte(payload), &change)
+	if err != nil {
+		w.logger.Error("failed to unmarshal instance to rotate credentials", zap.String("payload", payload), zap.Error(err))
+		return err
+	}
+	instance := change.Instance
+
+	lease, err := w.acquireLease(instance.Name)
+	if err != nil {
+		return err
+	}
+	defer lease.Release()
+	w.operationService.Start(operationIdFromContext(ctx))
+
+	rotator, ok := w.provisioner.(provisioners.PushServiceCredentialRotator)
+	if !ok {
+		w.logger.Error("provider is not able to rotate credentials", zap.Any("instance", instance))
+		return errors.New("provider is not able to rotate credentials")
+	}
+
+	w.startStep(ctx, instance.Name, rotateCredentialsStep)
+	rotateResult := rotator.RotateCredentials(&instance)
+	rotateResult.Previous = change.Previous
+	w.finishStep(ctx, instance.Name, rotateCredentialsStep, rotateResult.Status == provisioners.PushServiceProvisionStatusFailure, rotateResult.FailureReason)
+	// a rotation that failed after push-api got the new password still hands it to the bound apps
+	if rotateResult.Status == provisioners.PushServiceProvisionStatusFailure && len(rotateResult.EnvVars) == 0 {
+		return sendUpdateInstanceTask(w.logger, w.machineryServer, w.updateInstanceTaskName, w.updateInstancePolicy, operationIdFromContext(ctx), rotateResult)
+	}
+
+	instanceVars, err := w.instanceService.GetInstanceVars(instance.Name)
+	if err != nil {
+		w.logger.Error("failed to get instance vars to rotate app credentials", zap.Any("instance", instance), zap.Error(err))
+		return sendUpdateInstanceTask(w.logger, w.machineryServer, w.updateInstanceTaskName, w.updateInstancePolicy, operationIdFromContext(ctx), rotateResult)
+	}
+	for k, v := range rotateResult.EnvVars {
+		instanceVars[k] = v
+	}
+
+	err = sendUpdateInstanceTask(w.logger, w.machineryServer, w.updateInstanceTaskName, w.updateInstancePolicy, operationIdFromContext(ctx), rotateResult)
+	if err != nil {
+		return err
+	}
+
+	/*
+		a failure here is not retried, as it would rotate the password of push-api once more, but it is recorded
+		on the operation and told to the followers of the instance, as a step of its own
+	*/
+	w.startStep(ctx, instance.Name, rotateAppCredentialsStep)
+	err = w.credentialService.RotateAppCredentials(instance.Name, instanceVars)
+	if err != nil {
+		w.logger.Error("failed to rotate credentials of the bound apps", zap.Any("instance", instance), zap.Error(err))
+		w.finishStep(ctx, instance.Name, rotateAppCredentialsStep, true, err.Error())
+		return nil
+	}
+	w.finishStep(ctx, instance.Name, rotateAppCredentialsStep, false, "")
+	return nil
+}
+
+/*
+	the components that went missing are created again, keeping the ones that still exist and the credentials,
+	and tried again like a provision
+*/
+func (w *provisionWorker) HandleRecreateTask(ctx context.Context, payload string) error {
 	var instance models.Instance
 	err := json.Unmarshal([]byte(payload), &instance)
 	if err != nil {
-		w.logger.Error("failed to unmarshal instance to deprovision", zap.String("payload", payload), zap.Error(err))
+		w.logger.Error("failed to unmarshal instance to recreate", zap.String("payload", payload), zap.Error(err))
 		return err
 	}
 
-	w.provisioner.Deprovision(&instance)
-	return nil
+	lease, err := w.acquireLease(instance.Name)
+	if err != nil {
+		return err
+	}
+	defer lease.Release()
+	w.operationService.Start(operationIdFromContext(ctx))
+
+	pending, err := w.isStillPending(ctx, instance.Name)
+	if !pending {
+		return err
+	}
+
+	recreator, ok := w.provisioner.(provisioners.PushServiceRecreator)
+	if !ok {
+		w.logger.Error("provider is not able to recreate components", zap.Any("instance", instance))
+		return errors.New("provider is not able to recreate components")
+	}
+
+	envVars, err := w.instanceService.GetInstanceVars(instance.Name)
+	if err != nil {
+		w.logger.Error("failed to get instance vars to recreate instance", zap.Any("instance", instance), zap.Error(err))
+		return errors.New("failed to get instance vars to recreate instance")
+	}
+
+	w.startStep(ctx, instance.Name, recreateStep)
+	recreateResult := recreator.Recreate(&instance, envVars)
+	w.finishStep(ctx, instance.Name, recreateStep, recreateResult.Status == provisioners.PushServiceProvisionStatusFailure, recreateResult.FailureReason)
+	if shouldRetryProvision(ctx, recreateResult) {
+		return errors.New(recreateResult.FailureReason)
+	}
+	return sendUpdateInstanceTask(w.logger, w.machineryServer, w.updateInstanceTaskName, w.updateInstancePolicy, operationIdFromContext(ctx), recreateResult)
 }
 
-func NewProvisionWorker(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server, provisioner provisioners.PushServiceProvisioner) ProvisionWorker {
+func NewProvisionWorker(config *viper.Viper, logger *zap.Logger, machineryServer *machinery.Server, lockService services.InstanceLockService, operationService services.OperationService, instanceService services.InstanceService, credentialService services.CredentialService, eventService services.EventService, provisioner provisioners.PushServiceProvisioner) ProvisionWorker {
+	retryPolicies := services.NewTaskRetryPolicies(config)
+
 	return &provisionWorker{
 		logger:                 logger.Named("provisionWorker"),
 		machineryServer:        machineryServer,
 		updateInstanceTaskName: config.GetString("redis.pubsub.tasks.update_instance"),
+		deleteInstanceTaskName: config.GetString("redis.pubsub.tasks.delete_instance"),
+		updateInstancePolicy:   retryPolicies.For(config.GetString("redis.pubsub.tasks.update_instance")),
+		deleteInstancePolicy:   retryPolicies.For(config.GetString("redis.pubsub.tasks.delete_instance")),
+		busyBackoff:            config.GetDuration("workers.lock.busy_backoff"),
+		lockService:            lockService,
+		operationService:       operationService,
+		instanceService:        instanceService,
+		credentialService:      credentialService,
+		eventService:           eventService,
 		provisioner:            provisioner,
 	}
 }
@@ -0,0 +1,364 @@
+package workers_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/alicebob/miniredis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/services"
+	"github.com/pushaas/pushaas/pushaas/workers"
+)
+
+// a provisioner able to rotate the credentials of an instance
+type rotatingProvisioner struct {
+	*mocks.PushServiceProvisionerMock
+	result *provisioners.PushServiceProvisionResult
+}
+
+func (p *rotatingProvisioner) RotateCredentials(instance *models.Instance) *provisioners.PushServiceProvisionResult {
+	return p.result
+}
+
+var _ = Describe("ProvisionWorker", func() {
+	config := viper.New()
+	config.Set("redis.pubsub.tasks.update_instance", "update_instance")
+	config.Set("workers.retry.update_instance.max_retries", 5)
+	config.Set("workers.lock.busy_backoff", "15s")
+
+	provisionPolicy := &services.TaskRetryPolicy{MaxRetries: 3}
+	instance := &models.Instance{Name: "instance-1", Plan: "small"}
+	payload, _ := json.Marshal(instance)
+
+	var (
+		server            *miniredis.Miniredis
+		worker            workers.ProvisionWorker
+		lockService       *mocks.InstanceLockServiceMock
+		operationService  *mocks.OperationServiceMock
+		instanceService   *mocks.InstanceServiceMock
+		credentialService *mocks.CredentialServiceMock
+		provisioner       provisioners.PushServiceProvisioner
+	)
+
+	BeforeEach(func() {
+		instanceService = &mocks.InstanceServiceMock{
+			GetByNameFunc: func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+				return &models.Instance{Name: name, Status: models.InstanceStatusPending}, services.InstanceRetrievalSuccess
+			},
+		}
+		credentialService = &mocks.CredentialServiceMock{}
+	})
+
+	lockServiceWith := func(result services.InstanceLockResult) *mocks.InstanceLockServiceMock {
+		return &mocks.InstanceLockServiceMock{
+			AcquireFunc: func(instanceName string) (services.InstanceLease, services.InstanceLockResult) {
+				if result != services.InstanceLockAcquired {
+					return nil, result
+				}
+				return &mocks.InstanceLeaseMock{ReleaseFunc: func() {}}, result
+			},
+		}
+	}
+
+	provisionerWith := func(result *provisioners.PushServiceProvisionResult) *mocks.PushServiceProvisionerMock {
+		return &mocks.PushServiceProvisionerMock{
+			ProvisionFunc: func(instance *models.Instance) *provisioners.PushServiceProvisionResult {
+				return result
+			},
+		}
+	}
+
+	failedProvision := func(rollback models.InstanceRollback) *provisioners.PushServiceProvisionResult {
+		return &provisioners.PushServiceProvisionResult{
+			Instance:      &models.Instance{Name: "instance-1", Plan: "small", Rollback: rollback},
+			Status:        provisioners.PushServiceProvisionStatusFailure,
+			FailureReason: "some error",
+		}
+	}
+
+	newWorker := func() {
+		machineryServer, redisServer := newMachineryServer()
+		server = redisServer
+		operationService = &mocks.OperationServiceMock{
+			StartFunc:      func(id string) {},
+			StartStepFunc:  func(id string, step string) {},
+			FinishStepFunc: func(id string, step string, failed bool, reason string) {},
+			FinishFunc:     func(id string, failed bool, reason string) {},
+		}
+		eventService := &mocks.EventServiceMock{
+			PublishFunc: func(event *models.InstanceEvent) {},
+		}
+		worker = workers.NewProvisionWorker(config, logger, machineryServer, lockService, operationService, instanceService, credentialService, eventService, provisioner)
+	}
+
+	queuedTasks := func() []string {
+		queued, err := server.List("machinery_tasks")
+		if err != nil {
+			return nil
+		}
+		return queued
+	}
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("HandleProvisionTask", func() {
+		It("waits for the instance without taking a retry when it is busy with another operation", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockBusy)
+			provisioner = provisionerWith(nil)
+			newWorker()
+			signature := services.BuildTaskSignature("provision", string(payload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleProvisionTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).To(BeAssignableToTypeOf(tasks.ErrRetryTaskLater{}))
+			Expect(err.(tasks.ErrRetryTaskLater).RetryIn()).To(Equal(15 * time.Second))
+			Expect(signature.RetryCount).To(Equal(3))
+			Expect(provisioner.(*mocks.PushServiceProvisionerMock).ProvisionCalls()).To(HaveLen(0))
+			Expect(operationService.StartCalls()).To(HaveLen(0))
+		})
+
+		It("fails when the lease cannot be acquired", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockFailure)
+			provisioner = provisionerWith(nil)
+			newWorker()
+			signature := services.BuildTaskSignature("provision", string(payload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleProvisionTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).To(MatchError("failed to acquire instance lease"))
+			Expect(provisioner.(*mocks.PushServiceProvisionerMock).ProvisionCalls()).To(HaveLen(0))
+		})
+
+		It("sends the result of a successful provision to the instance", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = provisionerWith(&provisioners.PushServiceProvisionResult{
+				Instance: instance,
+				Status:   provisioners.PushServiceProvisionStatusSuccess,
+			})
+			newWorker()
+			signature := services.BuildTaskSignature("provision", string(payload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleProvisionTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operationService.StartCalls()).To(HaveLen(1))
+			queued := queuedTasks()
+			Expect(queued).To(HaveLen(1))
+			Expect(queued[0]).To(ContainSubstring(`"Name":"update_instance"`))
+			Expect(queued[0]).To(ContainSubstring(`"operation":"operation-1"`))
+		})
+
+		It("tries again a failed provision that was rolled back, while the task has retries left", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = provisionerWith(failedProvision(models.InstanceRollbackCompleted))
+			newWorker()
+			signature := services.BuildTaskSignature("provision", string(payload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleProvisionTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).To(MatchError("some error"))
+			Expect(queuedTasks()).To(HaveLen(0))
+		})
+
+		It("does not try again a failed provision whose rollback failed", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = provisionerWith(failedProvision(models.InstanceRollbackFailed))
+			newWorker()
+			signature := services.BuildTaskSignature("provision", string(payload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleProvisionTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			queued := queuedTasks()
+			Expect(queued).To(HaveLen(1))
+			Expect(queued[0]).To(ContainSubstring(`"Name":"update_instance"`))
+		})
+
+		It("does not try again a failed provision of a provider that does not roll back", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = provisionerWith(failedProvision(models.InstanceRollbackNone))
+			newWorker()
+			signature := services.BuildTaskSignature("provision", string(payload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleProvisionTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			queued := queuedTasks()
+			Expect(queued).To(HaveLen(1))
+			Expect(queued[0]).To(ContainSubstring(`"Name":"update_instance"`))
+		})
+
+		It("drops the task of an instance that is no longer pending, without provisioning it", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = provisionerWith(nil)
+			newWorker()
+			instanceService.GetByNameFunc = func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+				return &models.Instance{Name: name, Status: models.InstanceStatusFailed}, services.InstanceRetrievalSuccess
+			}
+			signature := services.BuildTaskSignature("provision", string(payload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleProvisionTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provisioner.(*mocks.PushServiceProvisionerMock).ProvisionCalls()).To(HaveLen(0))
+			Expect(queuedTasks()).To(HaveLen(0))
+			finishCalls := operationService.FinishCalls()
+			Expect(finishCalls).To(HaveLen(1))
+			Expect(finishCalls[0].Failed).To(BeTrue())
+			Expect(finishCalls[0].Reason).To(Equal("instance is no longer pending"))
+		})
+
+		It("fails when the instance cannot be retrieved to check it is still pending", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = provisionerWith(nil)
+			newWorker()
+			instanceService.GetByNameFunc = func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+				return nil, services.InstanceRetrievalFailure
+			}
+			signature := services.BuildTaskSignature("provision", string(payload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleProvisionTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).To(MatchError("failed to retrieve instance to check it is still pending"))
+			Expect(provisioner.(*mocks.PushServiceProvisionerMock).ProvisionCalls()).To(HaveLen(0))
+		})
+
+		It("sends the failure to the instance on the last attempt", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = provisionerWith(failedProvision(models.InstanceRollbackCompleted))
+			newWorker()
+			signature := services.BuildTaskSignature("provision", string(payload), "operation-1", provisionPolicy)
+			signature.RetryCount = 0
+
+			// act
+			err := worker.HandleProvisionTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queuedTasks()).To(HaveLen(1))
+		})
+	})
+
+	Describe("HandleRotateCredentialsTask", func() {
+		change := &models.InstanceChange{Instance: *instance, Previous: &models.InstancePrevious{Status: models.InstanceStatusRunning}}
+		changePayload, _ := json.Marshal(change)
+
+		rotatingWith := func(result *provisioners.PushServiceProvisionResult) *rotatingProvisioner {
+			return &rotatingProvisioner{PushServiceProvisionerMock: &mocks.PushServiceProvisionerMock{}, result: result}
+		}
+
+		BeforeEach(func() {
+			instanceService.GetInstanceVarsFunc = func(name string) (map[string]string, error) {
+				return map[string]string{provisioners.EnvVarEndpoint: "http://10.0.0.2:8080", provisioners.EnvVarPassword: "old-password"}, nil
+			}
+			credentialService.RotateAppCredentialsFunc = func(instanceName string, instanceVars map[string]string) error {
+				return nil
+			}
+		})
+
+		It("hands the new password to the bound apps when the rotation failed after push-api got it", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = rotatingWith(&provisioners.PushServiceProvisionResult{
+				Instance:      instance,
+				Status:        provisioners.PushServiceProvisionStatusFailure,
+				FailureReason: "push-api service did not finish deployment",
+				EnvVars:       map[string]string{provisioners.EnvVarPassword: "new-password"},
+			})
+			newWorker()
+			signature := services.BuildTaskSignature("rotate_credentials", string(changePayload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleRotateCredentialsTask(contextWithSignature(signature), string(changePayload))
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			queued := queuedTasks()
+			Expect(queued).To(HaveLen(1))
+			Expect(queued[0]).To(ContainSubstring(`"Name":"update_instance"`))
+			rotateCalls := credentialService.RotateAppCredentialsCalls()
+			Expect(rotateCalls).To(HaveLen(1))
+			Expect(rotateCalls[0].InstanceVars).To(Equal(map[string]string{
+				provisioners.EnvVarEndpoint: "http://10.0.0.2:8080",
+				provisioners.EnvVarPassword: "new-password",
+			}))
+		})
+
+		It("leaves the bound apps alone when the rotation failed before push-api got the new password", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = rotatingWith(&provisioners.PushServiceProvisionResult{
+				Instance:      instance,
+				Status:        provisioners.PushServiceProvisionStatusFailure,
+				FailureReason: "some error",
+				EnvVars:       map[string]string{},
+			})
+			newWorker()
+			signature := services.BuildTaskSignature("rotate_credentials", string(changePayload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleRotateCredentialsTask(contextWithSignature(signature), string(changePayload))
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queuedTasks()).To(HaveLen(1))
+			Expect(credentialService.RotateAppCredentialsCalls()).To(HaveLen(0))
+		})
+	})
+
+	Describe("HandleRecreateTask", func() {
+		It("drops the task of an instance that is no longer pending, without recreating it", func() {
+			// arrange
+			lockService = lockServiceWith(services.InstanceLockAcquired)
+			provisioner = &recreatingProvisioner{&mocks.PushServiceProvisionerMock{}}
+			newWorker()
+			instanceService.GetByNameFunc = func(name string) (*models.Instance, services.InstanceRetrievalResult) {
+				return &models.Instance{Name: name, Status: models.InstanceStatusFailed}, services.InstanceRetrievalSuccess
+			}
+			signature := services.BuildTaskSignature("recreate", string(payload), "operation-1", provisionPolicy)
+
+			// act
+			err := worker.HandleRecreateTask(contextWithSignature(signature), string(payload))
+
+			// assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instanceService.GetInstanceVarsCalls()).To(HaveLen(0))
+			Expect(queuedTasks()).To(HaveLen(0))
+			Expect(operationService.FinishCalls()).To(HaveLen(1))
+		})
+	})
+})
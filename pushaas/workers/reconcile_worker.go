@@ -0,0 +1,197 @@
+package workers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type (
+	ReconcileWorker interface {
+		DispatchWorker()
+	}
+
+	/*
+		Periodically compares the status of every instance with the actual state of its components on the provider:
+			- running instances whose push-api has no running tasks are marked as degraded (and back to running when it recovers)
+			- components that went missing are created again by a recreate task, when the provider is able to
+			- instances pending for longer than the timeout since they became pending are marked as failed
+		Each instance is reconciled under its lease, so that it is not changed while an operation runs on it, nor by
+		two replicas at once. The instances whose lease is held are left for the next round.
+	*/
+	reconcileWorker struct {
+		logger          *zap.Logger
+		enabled         bool
+		interval        time.Duration
+		pendingTimeout  time.Duration
+		instanceService services.InstanceService
+		lockService     services.InstanceLockService
+		eventService    services.EventService
+		provisioner     provisioners.PushServiceProvisioner
+	}
+)
+
+func (w *reconcileWorker) startWorker() {
+	w.logger.Info("starting worker", zap.Duration("interval", w.interval), zap.Duration("pendingTimeout", w.pendingTimeout))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.reconcile()
+	}
+}
+
+func (w *reconcileWorker) DispatchWorker() {
+	if w.enabled {
+		go w.startWorker()
+		return
+	}
+	w.logger.Info("worker disabled, not starting")
+}
+
+func (w *reconcileWorker) reconcile() {
+	instances, result := w.instanceService.GetAll()
+	if result == services.InstanceRetrievalFailure {
+		w.logger.Error("failed to retrieve instances to reconcile")
+		return
+	}
+
+	for _, instance := range instances {
+		w.reconcileInstance(instance)
+	}
+}
+
+func (w *reconcileWorker) reconcileInstance(instance *models.Instance) {
+	if instance.Status != models.InstanceStatusPending && instance.Status != models.InstanceStatusRunning && instance.Status != models.InstanceStatusDegraded {
+		return
+	}
+
+	lease, result := w.lockService.Acquire(instance.Name)
+	if result == services.InstanceLockBusy {
+		w.logger.Debug("instance is busy with an operation, leaving it for the next round", zap.String("name", instance.Name))
+		return
+	}
+	if result == services.InstanceLockFailure {
+		w.logger.Error("failed to acquire lease of instance to reconcile", zap.String("name", instance.Name))
+		return
+	}
+	defer lease.Release()
+
+	if instance.Status == models.InstanceStatusPending {
+		w.reconcilePending(instance)
+		return
+	}
+	w.reconcileProvisioned(instance)
+}
+
+/*
+	the timeout counts from the last change of status, which is when the instance became pending
+*/
+func (w *reconcileWorker) reconcilePending(instance *models.Instance) {
+	history, result := w.instanceService.GetStatusHistory(instance.Name)
+	if result != services.InstanceRetrievalSuccess || len(history) == 0 {
+		w.logger.Error("failed to retrieve status history of pending instance", zap.Any("instance", instance))
+		return
+	}
+
+	since := history[len(history)-1].At
+	if time.Since(since) < w.pendingTimeout {
+		return
+	}
+
+	w.logger.Info("instance pending beyond timeout, marking as failed", zap.Any("instance", instance), zap.Time("since", since))
+	w.updateStatus(instance, models.InstanceStatusFailed, fmt.Sprintf("still pending after %s", w.pendingTimeout))
+}
+
+func (w *reconcileWorker) reconcileProvisioned(instance *models.Instance) {
+	inspectResult := w.provisioner.Inspect(instance)
+	if inspectResult.Status == provisioners.PushServiceInspectStatusFailure {
+		w.logger.Error("failed to inspect instance to reconcile", zap.Any("instance", instance))
+		return
+	}
+
+	missing := inspectResult.MissingComponents()
+	if len(missing) > 0 {
+		w.recreate(instance, missing)
+		return
+	}
+
+	isPushApiRunning := inspectResult.Component(provisioners.ComponentPushApi).RunningCount > 0
+	if instance.Status == models.InstanceStatusRunning && !isPushApiRunning {
+		w.logger.Info("push-api has no running tasks, marking instance as degraded", zap.Any("instance", instance))
+		w.updateStatus(instance, models.InstanceStatusDegraded, "push-api has no running tasks")
+	} else if instance.Status == models.InstanceStatusDegraded && isPushApiRunning {
+		w.logger.Info("push-api is running again, marking instance as running", zap.Any("instance", instance))
+		w.updateStatus(instance, models.InstanceStatusRunning, "push-api is running again")
+	}
+}
+
+/*
+	the recreation itself runs on the workers, so that a slow provider does not hold up the other instances
+*/
+func (w *reconcileWorker) recreate(instance *models.Instance, missing []string) {
+	reason := fmt.Sprintf("missing components: %s", strings.Join(missing, ", "))
+	if _, ok := w.provisioner.(provisioners.PushServiceRecreator); !ok {
+		w.logger.Error("instance has missing components but provider is not able to recreate them", zap.Any("instance", instance), zap.Strings("missing", missing))
+		if instance.Status == models.InstanceStatusRunning {
+			w.updateStatus(instance, models.InstanceStatusDegraded, reason)
+		}
+		return
+	}
+
+	w.logger.Info("instance has missing components, recreating them", zap.Any("instance", instance), zap.Strings("missing", missing))
+	recreateReason := fmt.Sprintf("recreating %s", reason)
+	_, recreateResult := w.instanceService.Recreate(instance, recreateReason)
+	if recreateResult != services.InstanceUpdateSuccess {
+		w.logger.Error("failed to recreate missing components of instance", zap.Any("instance", instance), zap.Strings("missing", missing))
+		return
+	}
+	w.publishStatusChanged(instance.Name, models.InstanceStatusPending, recreateReason)
+}
+
+func (w *reconcileWorker) publishStatusChanged(instanceName string, status models.InstanceStatus, reason string) {
+	w.eventService.Publish(&models.InstanceEvent{
+		Instance: instanceName,
+		Type:     models.InstanceEventStatusChanged,
+		Status:   status,
+		Reason:   reason,
+	})
+}
+
+// the status is only changed from the one the instance was found with
+func (w *reconcileWorker) updateStatus(instance *models.Instance, status models.InstanceStatus, reason string) {
+	updateResult := w.instanceService.UpdateStatusFrom(instance.Name, instance.Status, status, reason)
+	if updateResult == services.InstanceUpdateStatusChanged || updateResult == services.InstanceUpdateNotFound {
+		w.logger.Info("instance changed while reconciling, leaving it for the next round", zap.String("name", instance.Name))
+		return
+	}
+	if updateResult == services.InstanceUpdateFailure {
+		w.logger.Error("failed to update instance status while reconciling", zap.Any("instance", instance), zap.String("status", string(status)))
+		return
+	}
+	w.publishStatusChanged(instance.Name, status, reason)
+}
+
+func NewReconcileWorker(config *viper.Viper, logger *zap.Logger, instanceService services.InstanceService, lockService services.InstanceLockService, eventService services.EventService, provisioner provisioners.PushServiceProvisioner) ReconcileWorker {
+	enabled := config.GetBool("workers.reconcile.enabled")
+	workersEnabled := config.GetBool("workers.enabled")
+
+	return &reconcileWorker{
+		logger:          logger.Named("reconcileWorker"),
+		enabled:         enabled && workersEnabled,
+		interval:        config.GetDuration("workers.reconcile.interval"),
+		pendingTimeout:  config.GetDuration("workers.reconcile.pending_timeout"),
+		instanceService: instanceService,
+		lockService:     lockService,
+		eventService:    eventService,
+		provisioner:     provisioner,
+	}
+}
@@ -0,0 +1,243 @@
+package workers_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/models"
+	"github.com/pushaas/pushaas/pushaas/provisioners"
+	"github.com/pushaas/pushaas/pushaas/services"
+	"github.com/pushaas/pushaas/pushaas/workers"
+)
+
+// a provisioner able to recreate the components of an instance
+type recreatingProvisioner struct {
+	*mocks.PushServiceProvisionerMock
+}
+
+func (p *recreatingProvisioner) Recreate(instance *models.Instance, envVars map[string]string) *provisioners.PushServiceProvisionResult {
+	return nil
+}
+
+var _ = Describe("ReconcileWorker", func() {
+	config := viper.New()
+	config.Set("workers.reconcile.pending_timeout", "30m")
+
+	var (
+		instanceService *mocks.InstanceServiceMock
+		lockService     *mocks.InstanceLockServiceMock
+		eventService    *mocks.EventServiceMock
+		provisioner     *mocks.PushServiceProvisionerMock
+		updateResult    services.InstanceUpdateResult
+	)
+
+	BeforeEach(func() {
+		updateResult = services.InstanceUpdateSuccess
+		instanceService = &mocks.InstanceServiceMock{
+			UpdateStatusFromFunc: func(name string, from models.InstanceStatus, status models.InstanceStatus, reason string) services.InstanceUpdateResult {
+				return updateResult
+			},
+			RecreateFunc: func(instance *models.Instance, reason string) (*models.Operation, services.InstanceUpdateResult) {
+				return &models.Operation{}, services.InstanceUpdateSuccess
+			},
+		}
+		lockService = &mocks.InstanceLockServiceMock{
+			AcquireFunc: func(instanceName string) (services.InstanceLease, services.InstanceLockResult) {
+				return &mocks.InstanceLeaseMock{ReleaseFunc: func() {}}, services.InstanceLockAcquired
+			},
+		}
+		eventService = &mocks.EventServiceMock{
+			PublishFunc: func(event *models.InstanceEvent) {},
+		}
+	})
+
+	withInstances := func(instances ...*models.Instance) {
+		instanceService.GetAllFunc = func() ([]*models.Instance, services.InstanceRetrievalResult) {
+			return instances, services.InstanceRetrievalSuccess
+		}
+	}
+
+	inspectingAs := func(pushApiExists bool, pushApiRunningCount int64) *mocks.PushServiceProvisionerMock {
+		return &mocks.PushServiceProvisionerMock{
+			InspectFunc: func(instance *models.Instance) *provisioners.PushServiceInspectResult {
+				return &provisioners.PushServiceInspectResult{
+					Instance: instance,
+					Status:   provisioners.PushServiceInspectStatusSuccess,
+					Components: []*provisioners.PushServiceComponentState{
+						{Name: provisioners.ComponentPushRedis, Exists: true, RunningCount: 1},
+						{Name: provisioners.ComponentPushStream, Exists: true, RunningCount: 1},
+						{Name: provisioners.ComponentPushApi, Exists: pushApiExists, RunningCount: pushApiRunningCount},
+					},
+				}
+			},
+		}
+	}
+
+	newWorker := func(provisioner provisioners.PushServiceProvisioner) workers.ReconcileWorker {
+		return workers.NewReconcileWorker(config, logger, instanceService, lockService, eventService, provisioner)
+	}
+
+	It("marks a running instance whose push-api has no running tasks as degraded and publishes it", func() {
+		// arrange
+		withInstances(&models.Instance{Name: "instance-1", Status: models.InstanceStatusRunning})
+		provisioner = inspectingAs(true, 0)
+
+		// act
+		workers.Reconcile(newWorker(provisioner))
+
+		// assert
+		updateCalls := instanceService.UpdateStatusFromCalls()
+		Expect(updateCalls).To(HaveLen(1))
+		Expect(updateCalls[0].Name).To(Equal("instance-1"))
+		Expect(updateCalls[0].From).To(Equal(models.InstanceStatusRunning))
+		Expect(updateCalls[0].Status).To(Equal(models.InstanceStatusDegraded))
+		publishCalls := eventService.PublishCalls()
+		Expect(publishCalls).To(HaveLen(1))
+		Expect(publishCalls[0].Event.Type).To(Equal(models.InstanceEventStatusChanged))
+		Expect(publishCalls[0].Event.Status).To(Equal(models.InstanceStatusDegraded))
+		Expect(lockService.AcquireCalls()).To(HaveLen(1))
+	})
+
+	It("marks a degraded instance whose push-api is running again as running", func() {
+		// arrange
+		withInstances(&models.Instance{Name: "instance-1", Status: models.InstanceStatusDegraded})
+		provisioner = inspectingAs(true, 1)
+
+		// act
+		workers.Reconcile(newWorker(provisioner))
+
+		// assert
+		updateCalls := instanceService.UpdateStatusFromCalls()
+		Expect(updateCalls).To(HaveLen(1))
+		Expect(updateCalls[0].Status).To(Equal(models.InstanceStatusRunning))
+		Expect(eventService.PublishCalls()).To(HaveLen(1))
+	})
+
+	It("does not publish when the instance changed while it was reconciled", func() {
+		// arrange
+		updateResult = services.InstanceUpdateStatusChanged
+		withInstances(&models.Instance{Name: "instance-1", Status: models.InstanceStatusRunning})
+		provisioner = inspectingAs(true, 0)
+
+		// act
+		workers.Reconcile(newWorker(provisioner))
+
+		// assert
+		Expect(instanceService.UpdateStatusFromCalls()).To(HaveLen(1))
+		Expect(eventService.PublishCalls()).To(HaveLen(0))
+	})
+
+	It("leaves the instances busy with an operation for the next round", func() {
+		// arrange
+		withInstances(&models.Instance{Name: "instance-1", Status: models.InstanceStatusRunning})
+		lockService.AcquireFunc = func(instanceName string) (services.InstanceLease, services.InstanceLockResult) {
+			return nil, services.InstanceLockBusy
+		}
+		provisioner = inspectingAs(true, 0)
+
+		// act
+		workers.Reconcile(newWorker(provisioner))
+
+		// assert
+		Expect(provisioner.InspectCalls()).To(HaveLen(0))
+		Expect(instanceService.UpdateStatusFromCalls()).To(HaveLen(0))
+	})
+
+	It("leaves alone the instances that are not provisioned nor pending", func() {
+		// arrange
+		withInstances(
+			&models.Instance{Name: "instance-1", Status: models.InstanceStatusFailed},
+			&models.Instance{Name: "instance-2", Status: models.InstanceStatusDeprovisioning},
+		)
+		provisioner = inspectingAs(true, 0)
+
+		// act
+		workers.Reconcile(newWorker(provisioner))
+
+		// assert
+		Expect(lockService.AcquireCalls()).To(HaveLen(0))
+		Expect(provisioner.InspectCalls()).To(HaveLen(0))
+	})
+
+	It("recreates the missing components through a task when the provider is able to", func() {
+		// arrange
+		instance := &models.Instance{Name: "instance-1", Status: models.InstanceStatusRunning}
+		withInstances(instance)
+		provisioner = inspectingAs(false, 0)
+
+		// act
+		workers.Reconcile(newWorker(&recreatingProvisioner{provisioner}))
+
+		// assert
+		recreateCalls := instanceService.RecreateCalls()
+		Expect(recreateCalls).To(HaveLen(1))
+		Expect(recreateCalls[0].Instance).To(Equal(instance))
+		Expect(recreateCalls[0].Reason).To(Equal("recreating missing components: push-api"))
+		Expect(instanceService.UpdateStatusFromCalls()).To(HaveLen(0))
+		publishCalls := eventService.PublishCalls()
+		Expect(publishCalls).To(HaveLen(1))
+		Expect(publishCalls[0].Event.Status).To(Equal(models.InstanceStatusPending))
+	})
+
+	It("marks a running instance with missing components as degraded when the provider is not able to recreate them", func() {
+		// arrange
+		withInstances(&models.Instance{Name: "instance-1", Status: models.InstanceStatusRunning})
+		provisioner = inspectingAs(false, 0)
+
+		// act
+		workers.Reconcile(newWorker(provisioner))
+
+		// assert
+		Expect(instanceService.RecreateCalls()).To(HaveLen(0))
+		updateCalls := instanceService.UpdateStatusFromCalls()
+		Expect(updateCalls).To(HaveLen(1))
+		Expect(updateCalls[0].Status).To(Equal(models.InstanceStatusDegraded))
+		Expect(updateCalls[0].Reason).To(Equal("missing components: push-api"))
+	})
+
+	It("marks an instance pending for longer than the timeout since it became pending as failed", func() {
+		// arrange
+		withInstances(&models.Instance{Name: "instance-1", Status: models.InstanceStatusPending})
+		instanceService.GetStatusHistoryFunc = func(name string) ([]*models.InstanceStatusTransition, services.InstanceRetrievalResult) {
+			return []*models.InstanceStatusTransition{
+				{To: models.InstanceStatusRunning, At: time.Now().Add(-2 * time.Hour)},
+				{From: models.InstanceStatusRunning, To: models.InstanceStatusPending, At: time.Now().Add(-time.Hour)},
+			}, services.InstanceRetrievalSuccess
+		}
+		provisioner = inspectingAs(true, 1)
+
+		// act
+		workers.Reconcile(newWorker(provisioner))
+
+		// assert
+		updateCalls := instanceService.UpdateStatusFromCalls()
+		Expect(updateCalls).To(HaveLen(1))
+		Expect(updateCalls[0].From).To(Equal(models.InstanceStatusPending))
+		Expect(updateCalls[0].Status).To(Equal(models.InstanceStatusFailed))
+		Expect(eventService.PublishCalls()).To(HaveLen(1))
+		Expect(provisioner.InspectCalls()).To(HaveLen(0))
+	})
+
+	It("counts the pending timeout from the last change of status, not from the creation of the instance", func() {
+		// arrange
+		withInstances(&models.Instance{Name: "instance-1", Status: models.InstanceStatusPending})
+		instanceService.GetStatusHistoryFunc = func(name string) ([]*models.InstanceStatusTransition, services.InstanceRetrievalResult) {
+			return []*models.InstanceStatusTransition{
+				{To: models.InstanceStatusRunning, At: time.Now().Add(-2 * time.Hour)},
+				{From: models.InstanceStatusRunning, To: models.InstanceStatusPending, At: time.Now().Add(-time.Minute)},
+			}, services.InstanceRetrievalSuccess
+		}
+		provisioner = inspectingAs(true, 1)
+
+		// act
+		workers.Reconcile(newWorker(provisioner))
+
+		// assert
+		Expect(instanceService.UpdateStatusFromCalls()).To(HaveLen(0))
+		Expect(eventService.PublishCalls()).To(HaveLen(0))
+	})
+})
@@ -0,0 +1,62 @@
+package workers
+
+import (
+	"context"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+
+	"github.com/pushaas/pushaas/pushaas/services"
+)
+
+type taskHandler func(ctx context.Context, payload string) error
+
+/*
+	the signature of the task, which carries the retries left, comes on the context of its handler
+*/
+func isLastAttempt(ctx context.Context) bool {
+	signature := tasks.SignatureFromContext(ctx)
+	return signature == nil || signature.RetryCount <= 0
+}
+
+// the operation the task is part of, if any
+func operationIdFromContext(ctx context.Context) string {
+	return services.OperationIdOf(tasks.SignatureFromContext(ctx))
+}
+
+/*
+	a task that fails is sent again after the backoff of its policy while it has retries left, and then goes to the
+	dead letters, failing its operation. The retries left are taken here, as machinery only takes them when it
+	picks the backoff itself.
+*/
+func withRetries(taskName string, policy *services.TaskRetryPolicy, deadLetterService services.DeadLetterService, operationService services.OperationService, handler taskHandler) taskHandler {
+	return func(ctx context.Context, payload string) error {
+		err := handler(ctx, payload)
+		if err == nil {
+			return nil
+		}
+		// the handler already told when to try again, which does not count as a retry
+		if _, ok := err.(tasks.ErrRetryTaskLater); ok {
+			return err
+		}
+
+		signature := tasks.SignatureFromContext(ctx)
+		retriesLeft := 0
+		if signature != nil {
+			retriesLeft = signature.RetryCount
+		}
+		retry := policy.MaxRetries - retriesLeft
+		if retry < 0 {
+			retry = 0
+		}
+
+		if retriesLeft <= 0 {
+			operationId := operationIdFromContext(ctx)
+			deadLetterService.Record(taskName, payload, operationId, retry+1, err)
+			operationService.Finish(operationId, true, err.Error())
+			return err
+		}
+
+		signature.RetryCount--
+		return tasks.NewErrRetryTaskLater(err.Error(), policy.Backoff(retry))
+	}
+}
@@ -0,0 +1,129 @@
+package workers_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1/tasks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pushaas/pushaas/pushaas/mocks"
+	"github.com/pushaas/pushaas/pushaas/services"
+	"github.com/pushaas/pushaas/pushaas/workers"
+)
+
+var _ = Describe("WithRetries", func() {
+	policy := &services.TaskRetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     5 * time.Minute,
+	}
+
+	var (
+		deadLetterService *mocks.DeadLetterServiceMock
+		operationService  *mocks.OperationServiceMock
+	)
+
+	BeforeEach(func() {
+		deadLetterService = &mocks.DeadLetterServiceMock{
+			RecordFunc: func(taskName string, payload string, operationId string, attempts int, taskErr error) {},
+		}
+		operationService = &mocks.OperationServiceMock{
+			FinishFunc: func(id string, failed bool, reason string) {},
+		}
+	})
+
+	handlerReturning := func(err error) workers.TaskHandler {
+		return func(ctx context.Context, payload string) error {
+			return err
+		}
+	}
+
+	It("lets the task succeed", func() {
+		// arrange
+		signature := services.BuildTaskSignature("provision", "payload", "operation-1", policy)
+		handler := workers.WithRetries("provision", policy, deadLetterService, operationService, handlerReturning(nil))
+
+		// act
+		err := handler(contextWithSignature(signature), "payload")
+
+		// assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signature.RetryCount).To(Equal(3))
+		Expect(deadLetterService.RecordCalls()).To(HaveLen(0))
+	})
+
+	It("takes one of the retries left and tries again after the backoff of the retry", func() {
+		// arrange
+		signature := services.BuildTaskSignature("provision", "payload", "operation-1", policy)
+		handler := workers.WithRetries("provision", policy, deadLetterService, operationService, handlerReturning(errors.New("some error")))
+		ctx := contextWithSignature(signature)
+
+		// act
+		firstErr := handler(ctx, "payload")
+		secondErr := handler(ctx, "payload")
+
+		// assert
+		Expect(firstErr).To(BeAssignableToTypeOf(tasks.ErrRetryTaskLater{}))
+		Expect(firstErr.(tasks.ErrRetryTaskLater).RetryIn()).To(Equal(30 * time.Second))
+		Expect(secondErr.(tasks.ErrRetryTaskLater).RetryIn()).To(Equal(time.Minute))
+		Expect(signature.RetryCount).To(Equal(1))
+		Expect(deadLetterService.RecordCalls()).To(HaveLen(0))
+		Expect(operationService.FinishCalls()).To(HaveLen(0))
+	})
+
+	It("sends the task to the dead letters and fails its operation when no retries are left", func() {
+		// arrange
+		signature := services.BuildTaskSignature("provision", "payload", "operation-1", policy)
+		signature.RetryCount = 0
+		handler := workers.WithRetries("provision", policy, deadLetterService, operationService, handlerReturning(errors.New("some error")))
+
+		// act
+		err := handler(contextWithSignature(signature), "payload")
+
+		// assert
+		Expect(err).To(MatchError("some error"))
+		recordCalls := deadLetterService.RecordCalls()
+		Expect(recordCalls).To(HaveLen(1))
+		Expect(recordCalls[0].TaskName).To(Equal("provision"))
+		Expect(recordCalls[0].Payload).To(Equal("payload"))
+		Expect(recordCalls[0].OperationId).To(Equal("operation-1"))
+		Expect(recordCalls[0].Attempts).To(Equal(4))
+		finishCalls := operationService.FinishCalls()
+		Expect(finishCalls).To(HaveLen(1))
+		Expect(finishCalls[0].ID).To(Equal("operation-1"))
+		Expect(finishCalls[0].Failed).To(BeTrue())
+		Expect(finishCalls[0].Reason).To(Equal("some error"))
+	})
+
+	It("sends the task to the dead letters right away when it has no policy", func() {
+		// arrange
+		noRetries := &services.TaskRetryPolicy{}
+		signature := services.BuildTaskSignature("other-task", "payload", "", noRetries)
+		handler := workers.WithRetries("other-task", noRetries, deadLetterService, operationService, handlerReturning(errors.New("some error")))
+
+		// act
+		err := handler(contextWithSignature(signature), "payload")
+
+		// assert
+		Expect(err).To(MatchError("some error"))
+		Expect(deadLetterService.RecordCalls()).To(HaveLen(1))
+		Expect(deadLetterService.RecordCalls()[0].Attempts).To(Equal(1))
+	})
+
+	It("does not take a retry when the handler asks to try again later", func() {
+		// arrange
+		signature := services.BuildTaskSignature("provision", "payload", "operation-1", policy)
+		handler := workers.WithRetries("provision", policy, deadLetterService, operationService, handlerReturning(tasks.NewErrRetryTaskLater("busy", 15*time.Second)))
+
+		// act
+		err := handler(contextWithSignature(signature), "payload")
+
+		// assert
+		Expect(err.(tasks.ErrRetryTaskLater).RetryIn()).To(Equal(15 * time.Second))
+		Expect(signature.RetryCount).To(Equal(3))
+		Expect(deadLetterService.RecordCalls()).To(HaveLen(0))
+	})
+})
@@ -0,0 +1,53 @@
+package workers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RichardKnop/machinery/v1"
+	machineryConfig "github.com/RichardKnop/machinery/v1/config"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/alicebob/miniredis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+var logger *zap.Logger
+
+func TestWorkers(t *testing.T) {
+	logger = zaptest.NewLogger(t)
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Workers Suite")
+}
+
+/*
+	the handlers find the signature of their task on the context, as machinery puts it there
+*/
+func contextWithSignature(signature *tasks.Signature) context.Context {
+	task, err := tasks.NewWithSignature(func(ctx context.Context, payload string) error {
+		return nil
+	}, signature)
+	Expect(err).NotTo(HaveOccurred())
+	return task.Context
+}
+
+/*
+	a machinery server on an in-process redis, for the specs of the handlers that send the next task
+*/
+func newMachineryServer() (*machinery.Server, *miniredis.Miniredis) {
+	server, err := miniredis.Run()
+	Expect(err).NotTo(HaveOccurred())
+
+	url := "redis://" + server.Addr()
+	machineryServer, err := machinery.NewServer(&machineryConfig.Config{
+		Broker:        url,
+		DefaultQueue:  "machinery_tasks",
+		ResultBackend: url,
+		NoUnixSignals: true,
+	})
+	Expect(err).NotTo(HaveOccurred())
+	return machineryServer, server
+}